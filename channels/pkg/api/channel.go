@@ -67,6 +67,11 @@ type AddonSpec struct {
 
 	// NeedsPKI determines if channels should provision a CA and a cert-manager issuer for the addon.
 	NeedsPKI bool `json:"needsPKI,omitempty"`
+
+	// RolloutTimeout, if set, makes channels wait for the Deployments, DaemonSets and StatefulSets of the addon
+	// to finish rolling out after applying the manifest. If they do not within the timeout,
+	// the previously installed manifest is re-applied and the failure is recorded.
+	RolloutTimeout *metav1.Duration `json:"rolloutTimeout,omitempty"`
 }
 
 func (a *Addons) Verify() error {
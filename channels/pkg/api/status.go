@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusAnnotationPrefix is the prefix of the namespace annotations recording failed addon updates
+const StatusAnnotationPrefix = "status.addons.k8s.io/"
+
+// AddonStatus records an update of an addon that failed to roll out
+type AddonStatus struct {
+	// FailedId is the id of the version that failed to roll out
+	FailedId string `json:"failedId,omitempty"`
+	// FailedManifestHash is the manifest hash of the version that failed to roll out
+	FailedManifestHash string `json:"failedManifestHash,omitempty"`
+	// Message describes why the update failed
+	Message string `json:"message,omitempty"`
+	// RolledBack is true if the previously installed manifest was re-applied
+	RolledBack bool `json:"rolledBack,omitempty"`
+	// Time is when the update failed
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+// ParseAddonStatus parses the value of an addon status annotation
+func ParseAddonStatus(s string) (*AddonStatus, error) {
+	status := &AddonStatus{}
+	if err := json.Unmarshal([]byte(s), status); err != nil {
+		return nil, fmt.Errorf("error parsing addon status %q: %v", s, err)
+	}
+	return status, nil
+}
+
+// FindAddonStatuses returns the statuses recorded in the annotations of a namespace, by addon name
+func FindAddonStatuses(annotations map[string]string) (map[string]*AddonStatus, error) {
+	statuses := make(map[string]*AddonStatus)
+	for k, v := range annotations {
+		if !strings.HasPrefix(k, StatusAnnotationPrefix) {
+			continue
+		}
+		status, err := ParseAddonStatus(v)
+		if err != nil {
+			return nil, err
+		}
+		statuses[strings.TrimPrefix(k, StatusAnnotationPrefix)] = status
+	}
+	return statuses, nil
+}
+
+// Encode serializes the status as an annotation value
+func (s *AddonStatus) Encode() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("error encoding addon status: %v", err)
+	}
+	return string(data), nil
+}
+
+// Describe returns a human readable description of the failure
+func (s *AddonStatus) Describe() string {
+	if s.RolledBack {
+		return "update failed and was rolled back: " + s.Message
+	}
+	return "update failed: " + s.Message
+}
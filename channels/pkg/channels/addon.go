@@ -33,6 +33,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/channels/pkg/api"
+	"k8s.io/kops/util/pkg/vfs"
 
 	cmv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -53,6 +54,9 @@ type AddonUpdate struct {
 	ExistingVersion *ChannelVersion
 	NewVersion      *ChannelVersion
 	InstallPKI      bool
+
+	// Results holds the outcome of applying the manifest to each object
+	Results []*ApplyResult
 }
 
 // AddonMenu is a collection of addons, with helpers for computing the latest versions
@@ -123,6 +127,17 @@ func (a *Addon) GetRequiredUpdates(ctx context.Context, k8sClient kubernetes.Int
 		newVersion = nil
 	}
 
+	if newVersion != nil {
+		status, err := channel.GetStatus(ctx, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil && status.FailedId == newVersion.Id && status.FailedManifestHash == newVersion.ManifestHash {
+			klog.Infof("Skipping update of addon %q to %s, which previously failed to roll out", a.Name, newVersion)
+			newVersion = nil
+		}
+	}
+
 	if pkiInstalled && newVersion == nil {
 		return nil, nil
 	}
@@ -151,7 +166,7 @@ func (a *Addon) GetManifestFullUrl() (*url.URL, error) {
 	return manifestURL, nil
 }
 
-func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interface, cmClient certmanager.Interface) (*AddonUpdate, error) {
+func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interface, cmClient certmanager.Interface, applier *Applier) (*AddonUpdate, error) {
 	required, err := a.GetRequiredUpdates(ctx, k8sClient, cmClient)
 	if err != nil {
 		return nil, err
@@ -167,8 +182,27 @@ func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interfac
 		}
 		klog.Infof("Applying update from %q", manifestURL)
 
-		err = Apply(manifestURL.String())
+		manifest, err := vfs.Context.ReadFile(manifestURL.String())
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest %q: %v", manifestURL, err)
+		}
+
+		var previousManifest []byte
+		if a.Spec.RolloutTimeout != nil && required.ExistingVersion != nil {
+			previousManifest, err = a.readAppliedManifest(ctx, k8sClient)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		required.Results, err = applier.Apply(ctx, a.Name, manifest)
+		if err == nil && a.Spec.RolloutTimeout != nil {
+			err = waitForRollout(ctx, k8sClient, required.Results, a.Spec.RolloutTimeout.Duration)
+		}
 		if err != nil {
+			if a.Spec.RolloutTimeout != nil {
+				return nil, a.rollback(ctx, k8sClient, applier, required, previousManifest, err)
+			}
 			return nil, fmt.Errorf("error applying update from %q: %v", manifestURL, err)
 		}
 
@@ -181,6 +215,12 @@ func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interfac
 		if err != nil {
 			return nil, fmt.Errorf("error applying annotation to record addon installation: %v", err)
 		}
+
+		if a.Spec.RolloutTimeout != nil {
+			if err := a.writeAppliedManifest(ctx, k8sClient, manifest); err != nil {
+				klog.Warningf("unable to record the applied manifest of addon %q, a failed update will not be rolled back: %v", a.Name, err)
+			}
+		}
 	}
 	if required.InstallPKI {
 		err := a.installPKI(ctx, k8sClient, cmClient)
@@ -191,6 +231,83 @@ func (a *Addon) EnsureUpdated(ctx context.Context, k8sClient kubernetes.Interfac
 	return required, nil
 }
 
+// appliedManifestConfigMapPrefix prefixes the name of the ConfigMaps keeping the manifest of the installed version of an addon
+const appliedManifestConfigMapPrefix = "applied-manifest."
+
+// appliedManifestKey is the key of the manifest in the applied manifest ConfigMap
+const appliedManifestKey = "manifest.yaml"
+
+// appliedManifestName is the name of the ConfigMap, in the namespace of the channel, keeping the manifest of the installed version of the addon.
+// The manifest is kept in the cluster rather than next to the channel, as channels are often served read-only.
+func (a *Addon) appliedManifestName() string {
+	return appliedManifestConfigMapPrefix + a.Name
+}
+
+// readAppliedManifest returns the manifest of the installed version of the addon, or nil if it was not recorded
+func (a *Addon) readAppliedManifest(ctx context.Context, k8sClient kubernetes.Interface) ([]byte, error) {
+	channel := a.buildChannel()
+	configMap, err := k8sClient.CoreV1().ConfigMaps(channel.Namespace).Get(ctx, a.appliedManifestName(), metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Warningf("applied manifest of addon %q not found in configmap %s/%s, a failed update will not be rolled back", a.Name, channel.Namespace, a.appliedManifestName())
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading applied manifest of addon %q: %v", a.Name, err)
+	}
+	manifest, ok := configMap.Data[appliedManifestKey]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(manifest), nil
+}
+
+func (a *Addon) writeAppliedManifest(ctx context.Context, k8sClient kubernetes.Interface, manifest []byte) error {
+	channel := a.buildChannel()
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      a.appliedManifestName(),
+			Namespace: channel.Namespace,
+		},
+		Data: map[string]string{
+			appliedManifestKey: string(manifest),
+		},
+	}
+
+	configMaps := k8sClient.CoreV1().ConfigMaps(channel.Namespace)
+	_, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("error writing applied manifest of addon %q: %v", a.Name, err)
+	}
+	return nil
+}
+
+// rollback re-applies the previously installed manifest after an update failed to roll out, and records the failure
+func (a *Addon) rollback(ctx context.Context, k8sClient kubernetes.Interface, applier *Applier, required *AddonUpdate, previousManifest []byte, cause error) error {
+	status := &api.AddonStatus{
+		FailedId:           required.NewVersion.Id,
+		FailedManifestHash: required.NewVersion.ManifestHash,
+		Message:            cause.Error(),
+		Time:               metav1.Now(),
+	}
+
+	if previousManifest != nil {
+		klog.Warningf("update of addon %q failed, rolling back: %v", a.Name, cause)
+		if _, err := applier.Apply(ctx, a.Name, previousManifest); err != nil {
+			status.Message += fmt.Sprintf("; rollback failed: %v", err)
+		} else {
+			status.RolledBack = true
+		}
+	}
+
+	if err := a.buildChannel().SetStatus(ctx, k8sClient, status); err != nil {
+		return fmt.Errorf("error recording failed update of addon %q (%s): %v", a.Name, status.Describe(), err)
+	}
+	return fmt.Errorf("%s", status.Describe())
+}
+
 func (a *Addon) AddNeedsUpdateLabel(ctx context.Context, k8sClient kubernetes.Interface, required *AddonUpdate) error {
 	if required.ExistingVersion != nil {
 		if a.Spec.NeedsRollingUpdate != "" {
@@ -213,8 +330,9 @@ func (a *Addon) patchNeedsUpdateLabel(ctx context.Context, k8sClient kubernetes.
 		selector = "node-role.kubernetes.io/node="
 	}
 
-	annotationPatch := &annotationPatch{Metadata: annotationPatchMetadata{Annotations: map[string]string{
-		"kops.k8s.io/needs-update": "",
+	needsUpdate := ""
+	annotationPatch := &annotationPatch{Metadata: annotationPatchMetadata{Annotations: map[string]*string{
+		"kops.k8s.io/needs-update": &needsUpdate,
 	}}}
 	annotationPatchJSON, err := json.Marshal(annotationPatch)
 	if err != nil {
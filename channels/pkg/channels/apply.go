@@ -17,60 +17,358 @@ limitations under the License.
 package channels
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"os/exec"
-	"path"
+	"sort"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
-	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kops/pkg/kubemanifest"
 )
 
-// Apply calls kubectl apply to apply the manifest.
-// We will likely in future change this to create things directly (or more likely embed this logic into kubectl itself)
-func Apply(manifest string) error {
-	// We copy the manifest to a temp file because it is likely e.g. an s3 URL, which kubectl can't read
-	data, err := vfs.Context.ReadFile(manifest)
-	if err != nil {
-		return fmt.Errorf("error reading manifest: %v", err)
+// FieldManager is the field manager used when applying addon manifests
+const FieldManager = "kops-channels"
+
+const (
+	// LabelManagedBy is set to "kops" on the objects of addons managed by kOps
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	// LabelAddonName is set to the addon name on the objects of addons managed by kOps
+	LabelAddonName = "addon.kops.k8s.io/name"
+)
+
+// ApplyAction describes what happened to an object when a manifest was applied
+type ApplyAction string
+
+const (
+	ApplyActionCreated    ApplyAction = "created"
+	ApplyActionConfigured ApplyAction = "configured"
+	ApplyActionUnchanged  ApplyAction = "unchanged"
+	ApplyActionPruned     ApplyAction = "pruned"
+	ApplyActionFailed     ApplyAction = "failed"
+)
+
+// ApplyResult is the outcome of applying or pruning a single object
+type ApplyResult struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Action           ApplyAction
+	Error            error
+}
+
+func (r *ApplyResult) String() string {
+	kind := strings.ToLower(r.GroupVersionKind.Kind)
+	if r.GroupVersionKind.Group != "" {
+		kind += "." + r.GroupVersionKind.Group
+	}
+	s := kind + "/" + r.Name
+	if r.Namespace != "" {
+		s += " -n " + r.Namespace
 	}
+	s += " " + string(r.Action)
+	if r.Error != nil {
+		s += ": " + r.Error.Error()
+	}
+	return s
+}
+
+// pruneKinds are checked for objects to prune, in addition to the kinds in the manifest.
+// Namespaces and CustomResourceDefinitions are never pruned, as deleting them deletes every object they hold.
+var pruneKinds = []schema.GroupKind{
+	{Group: "", Kind: "ConfigMap"},
+	{Group: "", Kind: "Secret"},
+	{Group: "", Kind: "Service"},
+	{Group: "", Kind: "ServiceAccount"},
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"},
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"},
+	{Group: "apiregistration.k8s.io", Kind: "APIService"},
+	{Group: "apps", Kind: "DaemonSet"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "StatefulSet"},
+	{Group: "batch", Kind: "CronJob"},
+	{Group: "batch", Kind: "Job"},
+	{Group: "networking.k8s.io", Kind: "Ingress"},
+	{Group: "networking.k8s.io", Kind: "NetworkPolicy"},
+	{Group: "policy", Kind: "PodDisruptionBudget"},
+	{Group: "policy", Kind: "PodSecurityPolicy"},
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"},
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"},
+	{Group: "rbac.authorization.k8s.io", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"},
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"},
+	{Group: "storage.k8s.io", Kind: "CSIDriver"},
+	{Group: "storage.k8s.io", Kind: "StorageClass"},
+}
+
+var neverPrunedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}:                                    true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: true,
+}
+
+// resettableRESTMapper is implemented by REST mappers caching discovery information
+type resettableRESTMapper interface {
+	Reset()
+}
+
+// Applier applies addon manifests using server-side apply
+type Applier struct {
+	Client     dynamic.Interface
+	RESTMapper meta.RESTMapper
+}
+
+// objectKey identifies an object independently of the API version it was read with
+type objectKey struct {
+	GroupKind schema.GroupKind
+	Namespace string
+	Name      string
+}
 
-	tmpDir, err := ioutil.TempDir("", "channel")
+// Apply applies the objects in the manifest to the cluster.
+// Objects labelled as belonging to the addon that are no longer in the manifest are deleted,
+// once all objects in the manifest have been applied successfully.
+func (a *Applier) Apply(ctx context.Context, addonName string, manifest []byte) ([]*ApplyResult, error) {
+	objects, err := parseManifest(manifest)
 	if err != nil {
-		return fmt.Errorf("error creating temp dir: %v", err)
+		return nil, err
 	}
 
-	defer func() {
-		if err := os.RemoveAll(tmpDir); err != nil {
-			klog.Warningf("error deleting temp dir %q: %v", tmpDir, err)
+	var results []*ApplyResult
+	var failed []string
+
+	applied := make(map[objectKey]bool)
+	kinds := make(map[schema.GroupKind]bool)
+	for _, obj := range objects {
+		result := a.applyObject(ctx, obj)
+		results = append(results, result)
+		if result.Error != nil {
+			failed = append(failed, result.String())
+			continue
 		}
-	}()
 
-	localManifestFile := path.Join(tmpDir, "manifest.yaml")
-	if err := ioutil.WriteFile(localManifestFile, data, 0600); err != nil {
-		return fmt.Errorf("error writing temp file: %v", err)
+		gk := obj.GroupVersionKind().GroupKind()
+		kinds[gk] = true
+		applied[objectKey{GroupKind: gk, Namespace: result.Namespace, Name: result.Name}] = true
+	}
+
+	if len(failed) != 0 {
+		return results, fmt.Errorf("error applying objects: %s", strings.Join(failed, "; "))
+	}
+
+	if addonName == "" {
+		return results, nil
 	}
 
-	_, err = execKubectl("apply", "-f", localManifestFile)
-	return err
+	for _, gk := range pruneKinds {
+		kinds[gk] = true
+	}
+	pruneResults, err := a.prune(ctx, addonName, kinds, applied)
+	results = append(results, pruneResults...)
+	return results, err
 }
 
-func execKubectl(args ...string) (string, error) {
-	kubectlPath := "kubectl" // Assume in PATH
-	cmd := exec.Command(kubectlPath, args...)
-	env := os.Environ()
-	cmd.Env = env
+// parseManifest parses the objects in the manifest, ordering namespaces and CRDs first so that the objects using them can be applied
+func parseManifest(manifest []byte) ([]*unstructured.Unstructured, error) {
+	manifestObjects, err := kubemanifest.LoadObjectsFrom(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %v", err)
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, manifestObject := range manifestObjects {
+		if manifestObject.IsEmptyObject() {
+			continue
+		}
+		obj := manifestObject.ToUnstructured()
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				objects = append(objects, item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error parsing list in manifest: %v", err)
+			}
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("object in manifest is missing kind or name: %v", obj.Object)
+		}
+		objects = append(objects, obj)
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return applyPriority(objects[i]) < applyPriority(objects[j])
+	})
+
+	return objects, nil
+}
 
-	human := strings.Join(cmd.Args, " ")
-	klog.V(2).Infof("Running command: %s", human)
-	output, err := cmd.CombinedOutput()
+func applyPriority(obj *unstructured.Unstructured) int {
+	switch obj.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Group: "", Kind: "Namespace"}:
+		return 0
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (a *Applier) applyObject(ctx context.Context, obj *unstructured.Unstructured) *ApplyResult {
+	gvk := obj.GroupVersionKind()
+	result := &ApplyResult{
+		GroupVersionKind: gvk,
+		Namespace:        obj.GetNamespace(),
+		Name:             obj.GetName(),
+		Action:           ApplyActionFailed,
+	}
+
+	mapping, err := a.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// The kind may be defined by a CRD applied earlier in the manifest
+		if resettable, ok := a.RESTMapper.(resettableRESTMapper); ok {
+			resettable.Reset()
+			mapping, err = a.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
 	if err != nil {
-		klog.Infof("error running %s", human)
-		klog.Info(string(output))
-		return string(output), fmt.Errorf("error running kubectl: %v", err)
+		result.Error = fmt.Errorf("error finding resource: %v", err)
+		return result
+	}
+
+	var resource dynamic.ResourceInterface
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if result.Namespace == "" {
+			result.Namespace = metav1.NamespaceDefault
+			obj.SetNamespace(result.Namespace)
+		}
+		resource = a.Client.Resource(mapping.Resource).Namespace(result.Namespace)
+	} else {
+		result.Namespace = ""
+		obj.SetNamespace("")
+		resource = a.Client.Resource(mapping.Resource)
 	}
 
-	return string(output), err
+	existing, err := resource.Get(ctx, result.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			result.Error = fmt.Errorf("error getting object: %v", err)
+			return result
+		}
+		existing = nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		result.Error = fmt.Errorf("error serializing object: %v", err)
+		return result
+	}
+
+	force := true
+	klog.V(2).Infof("applying %s %s/%s", gvk, result.Namespace, result.Name)
+	updated, err := resource.Patch(ctx, result.Name, types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: FieldManager,
+		// Take over fields previously set by kubectl apply
+		Force: &force,
+	})
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	switch {
+	case existing == nil:
+		result.Action = ApplyActionCreated
+	case existing.GetResourceVersion() == updated.GetResourceVersion():
+		result.Action = ApplyActionUnchanged
+	default:
+		result.Action = ApplyActionConfigured
+	}
+	return result
+}
+
+// prune deletes the objects of the given kinds that belong to the addon but were not applied
+func (a *Applier) prune(ctx context.Context, addonName string, kinds map[schema.GroupKind]bool, applied map[objectKey]bool) ([]*ApplyResult, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		LabelManagedBy: "kops",
+		LabelAddonName: addonName,
+	}).String()
+
+	var sortedKinds []schema.GroupKind
+	for gk := range kinds {
+		if !neverPrunedKinds[gk] {
+			sortedKinds = append(sortedKinds, gk)
+		}
+	}
+	sort.Slice(sortedKinds, func(i, j int) bool {
+		return sortedKinds[i].String() < sortedKinds[j].String()
+	})
+
+	var results []*ApplyResult
+	var failed []string
+	for _, gk := range sortedKinds {
+		mapping, err := a.RESTMapper.RESTMapping(gk)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				// The kind is not served by this cluster
+				continue
+			}
+			return results, fmt.Errorf("error finding resource for %v: %v", gk, err)
+		}
+
+		list, err := a.Client.Resource(mapping.Resource).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			if errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+				continue
+			}
+			return results, fmt.Errorf("error listing %v: %v", mapping.Resource, err)
+		}
+
+		sort.Slice(list.Items, func(i, j int) bool {
+			if list.Items[i].GetNamespace() != list.Items[j].GetNamespace() {
+				return list.Items[i].GetNamespace() < list.Items[j].GetNamespace()
+			}
+			return list.Items[i].GetName() < list.Items[j].GetName()
+		})
+		for i := range list.Items {
+			item := &list.Items[i]
+			key := objectKey{GroupKind: gk, Namespace: item.GetNamespace(), Name: item.GetName()}
+			if applied[key] || item.GetDeletionTimestamp() != nil {
+				continue
+			}
+
+			result := &ApplyResult{
+				GroupVersionKind: mapping.GroupVersionKind,
+				Namespace:        item.GetNamespace(),
+				Name:             item.GetName(),
+				Action:           ApplyActionPruned,
+			}
+
+			klog.V(2).Infof("pruning %s %s/%s", mapping.GroupVersionKind, result.Namespace, result.Name)
+			propagation := metav1.DeletePropagationBackground
+			err := a.Client.Resource(mapping.Resource).Namespace(item.GetNamespace()).Delete(ctx, item.GetName(), metav1.DeleteOptions{
+				PropagationPolicy: &propagation,
+			})
+			if err != nil && !errors.IsNotFound(err) {
+				result.Action = ApplyActionFailed
+				result.Error = fmt.Errorf("error pruning object: %v", err)
+				failed = append(failed, result.String())
+			}
+			results = append(results, result)
+		}
+	}
+
+	if len(failed) != 0 {
+		return results, fmt.Errorf("error pruning objects: %s", strings.Join(failed, "; "))
+	}
+	return results, nil
 }
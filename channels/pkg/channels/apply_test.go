@@ -0,0 +1,295 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	namespaceGVR  = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	configMapGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+type testApplier struct {
+	*Applier
+	tracker k8stesting.ObjectTracker
+}
+
+// newTestApplier builds an applier against a fake dynamic client.
+// The fake client does not support server-side apply, so apply patches replace the object,
+// bumping the resourceVersion when the object changes.
+func newTestApplier(t *testing.T, objects ...runtime.Object) *testApplier {
+	scheme := runtime.NewScheme()
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		namespaceGVR:  "NamespaceList",
+		configMapGVR:  "ConfigMapList",
+		deploymentGVR: "DeploymentList",
+	})
+
+	tracker := k8stesting.NewObjectTracker(scheme, serializer.NewCodecFactory(scheme).UniversalDecoder())
+	for _, obj := range objects {
+		if err := tracker.Add(obj); err != nil {
+			t.Fatalf("error adding object: %v", err)
+		}
+	}
+
+	objectReaction := k8stesting.ObjectReaction(tracker)
+	client.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(k8stesting.PatchAction)
+		if !ok || patch.GetPatchType() != types.ApplyPatchType {
+			return objectReaction(action)
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &obj.Object); err != nil {
+			return true, nil, err
+		}
+
+		existing, err := tracker.Get(action.GetResource(), action.GetNamespace(), patch.GetName())
+		if errors.IsNotFound(err) {
+			obj.SetResourceVersion("1")
+			return true, obj, tracker.Create(action.GetResource(), obj, action.GetNamespace())
+		}
+		if err != nil {
+			return true, nil, err
+		}
+
+		current := existing.(*unstructured.Unstructured)
+		resourceVersion, _ := strconv.Atoi(current.GetResourceVersion())
+		current.SetResourceVersion("")
+		if equality.Semantic.DeepEqual(current.Object, obj.Object) {
+			current.SetResourceVersion(strconv.Itoa(resourceVersion))
+			return true, current, nil
+		}
+		obj.SetResourceVersion(strconv.Itoa(resourceVersion + 1))
+		return true, obj, tracker.Update(action.GetResource(), obj, action.GetNamespace())
+	})
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{
+		{Version: "v1"},
+		{Group: "apps", Version: "v1"},
+	})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	return &testApplier{
+		Applier: &Applier{
+			Client:     client,
+			RESTMapper: mapper,
+		},
+		tracker: tracker,
+	}
+}
+
+func resultActions(results []*ApplyResult) []string {
+	var actions []string
+	for _, result := range results {
+		actions = append(actions, result.String())
+	}
+	return actions
+}
+
+func addonObject(gvk schema.GroupVersionKind, namespace, name, addon string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	if addon != "" {
+		obj.SetLabels(map[string]string{
+			LabelManagedBy: "kops",
+			LabelAddonName: addon,
+		})
+	}
+	return obj
+}
+
+func TestApplyCreatesAndUpdates(t *testing.T) {
+	ctx := context.TODO()
+	applier := newTestApplier(t)
+
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: test
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+`
+
+	results, err := applier.Apply(ctx, "test.addons.k8s.io", []byte(manifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"namespace/test created",
+		"deployment.apps/controller -n test created",
+		"configmap/config -n default created",
+	}
+	if actual := resultActions(results); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected results on create, expected %v, got %v", expected, actual)
+	}
+
+	results, err = applier.Apply(ctx, "test.addons.k8s.io", []byte(manifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = []string{
+		"namespace/test unchanged",
+		"deployment.apps/controller -n test unchanged",
+		"configmap/config -n default unchanged",
+	}
+	if actual := resultActions(results); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected results on reapply, expected %v, got %v", expected, actual)
+	}
+
+	results, err = applier.Apply(ctx, "test.addons.k8s.io", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: updated
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = []string{
+		"configmap/config -n default configured",
+	}
+	if actual := resultActions(results); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected results on update, expected %v, got %v", expected, actual)
+	}
+
+	obj, err := applier.tracker.Get(configMapGVR, "default", "config")
+	if err != nil {
+		t.Fatalf("error getting configmap: %v", err)
+	}
+	value, _, _ := unstructured.NestedString(obj.(*unstructured.Unstructured).Object, "data", "key")
+	if value != "updated" {
+		t.Errorf("configmap was not updated, got %q", value)
+	}
+}
+
+func TestApplyPrunesRemovedObjects(t *testing.T) {
+	ctx := context.TODO()
+	configMap := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	applier := newTestApplier(t,
+		addonObject(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, "", "test", "test.addons.k8s.io"),
+		addonObject(configMap, "test", "removed", "test.addons.k8s.io"),
+		addonObject(configMap, "kube-system", "removed-elsewhere", "test.addons.k8s.io"),
+		addonObject(configMap, "test", "other-addon", "other.addons.k8s.io"),
+		addonObject(configMap, "test", "unlabelled", ""),
+		addonObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, "test", "removed-deployment", "test.addons.k8s.io"),
+	)
+
+	results, err := applier.Apply(ctx, "test.addons.k8s.io", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: test
+  labels:
+    app.kubernetes.io/managed-by: kops
+    addon.kops.k8s.io/name: test.addons.k8s.io
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"configmap/kept -n test created",
+		"configmap/removed-elsewhere -n kube-system pruned",
+		"configmap/removed -n test pruned",
+		"deployment.apps/removed-deployment -n test pruned",
+	}
+	if actual := resultActions(results); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected results, expected %v, got %v", expected, actual)
+	}
+
+	for _, remaining := range []struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+		name      string
+	}{
+		{namespaceGVR, "", "test"},
+		{configMapGVR, "test", "kept"},
+		{configMapGVR, "test", "other-addon"},
+		{configMapGVR, "test", "unlabelled"},
+	} {
+		if _, err := applier.tracker.Get(remaining.gvr, remaining.namespace, remaining.name); err != nil {
+			t.Errorf("expected %s %s/%s to remain: %v", remaining.gvr.Resource, remaining.namespace, remaining.name, err)
+		}
+	}
+	if _, err := applier.tracker.Get(configMapGVR, "test", "removed"); !errors.IsNotFound(err) {
+		t.Errorf("expected pruned configmap to be deleted, got %v", err)
+	}
+}
+
+func TestApplyDoesNotPruneOnFailure(t *testing.T) {
+	ctx := context.TODO()
+	applier := newTestApplier(t,
+		addonObject(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, "test", "removed", "test.addons.k8s.io"),
+	)
+
+	results, err := applier.Apply(ctx, "test.addons.k8s.io", []byte(`
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: unknown
+`))
+	if err == nil {
+		t.Fatalf("expected error applying unknown kind")
+	}
+	if len(results) != 1 || results[0].Action != ApplyActionFailed {
+		t.Errorf("unexpected results %v", resultActions(results))
+	}
+
+	if _, err := applier.tracker.Get(configMapGVR, "test", "removed"); err != nil {
+		t.Errorf("expected configmap not to be pruned after a failure: %v", err)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	"k8s.io/kops/channels/pkg/api"
 )
 
 const AnnotationPrefix = "addons.k8s.io/"
@@ -159,22 +160,63 @@ type annotationPatch struct {
 	Metadata annotationPatchMetadata `json:"metadata,omitempty"`
 }
 type annotationPatchMetadata struct {
-	Annotations map[string]string `json:"annotations,omitempty"`
+	Annotations map[string]*string `json:"annotations,omitempty"`
 }
 
 func (c *Channel) SetInstalledVersion(ctx context.Context, k8sClient kubernetes.Interface, version *ChannelVersion) error {
-	// Primarily to check it exists
-	_, err := k8sClient.CoreV1().Namespaces().Get(ctx, c.Namespace, metav1.GetOptions{})
+	value, err := version.Encode()
 	if err != nil {
-		return fmt.Errorf("error querying namespace %q: %v", c.Namespace, err)
+		return err
 	}
 
-	value, err := version.Encode()
+	// A successful update clears any recorded failure
+	return c.patchAnnotations(ctx, k8sClient, map[string]*string{
+		c.AnnotationName():       &value,
+		c.StatusAnnotationName(): nil,
+	})
+}
+
+// StatusAnnotationName is the name of the annotation recording a failed update of the addon
+func (c *Channel) StatusAnnotationName() string {
+	return api.StatusAnnotationPrefix + c.Name
+}
+
+// GetStatus returns the recorded failed update of the addon, or nil if there is none
+func (c *Channel) GetStatus(ctx context.Context, k8sClient kubernetes.Interface) (*api.AddonStatus, error) {
+	ns, err := k8sClient.CoreV1().Namespaces().Get(ctx, c.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error querying namespace %q: %v", c.Namespace, err)
+	}
+
+	annotationValue, ok := ns.Annotations[c.StatusAnnotationName()]
+	if !ok {
+		return nil, nil
+	}
+
+	return api.ParseAddonStatus(annotationValue)
+}
+
+// SetStatus records a failed update of the addon
+func (c *Channel) SetStatus(ctx context.Context, k8sClient kubernetes.Interface, status *api.AddonStatus) error {
+	value, err := status.Encode()
 	if err != nil {
 		return err
 	}
 
-	annotationPatch := &annotationPatch{Metadata: annotationPatchMetadata{Annotations: map[string]string{c.AnnotationName(): value}}}
+	return c.patchAnnotations(ctx, k8sClient, map[string]*string{
+		c.StatusAnnotationName(): &value,
+	})
+}
+
+// patchAnnotations sets annotations on the namespace of the channel; nil values remove the annotation
+func (c *Channel) patchAnnotations(ctx context.Context, k8sClient kubernetes.Interface, annotations map[string]*string) error {
+	// Primarily to check it exists
+	_, err := k8sClient.CoreV1().Namespaces().Get(ctx, c.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error querying namespace %q: %v", c.Namespace, err)
+	}
+
+	annotationPatch := &annotationPatch{Metadata: annotationPatchMetadata{Annotations: annotations}}
 	annotationPatchJSON, err := json.Marshal(annotationPatch)
 	if err != nil {
 		return fmt.Errorf("error building annotation patch: %v", err)
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// rolloutPollInterval is the time between checks of the rollout of an addon's workloads
+var rolloutPollInterval = 5 * time.Second
+
+// waitForRollout waits until the Deployments, DaemonSets and StatefulSets applied from a manifest have rolled out
+func waitForRollout(ctx context.Context, k8sClient kubernetes.Interface, results []*ApplyResult, timeout time.Duration) error {
+	var pending []string
+	err := wait.PollImmediate(rolloutPollInterval, timeout, func() (bool, error) {
+		pending = nil
+		for _, result := range results {
+			if result.Action == ApplyActionPruned || result.GroupVersionKind.Group != "apps" {
+				continue
+			}
+
+			message, err := rolloutStatus(ctx, k8sClient, result)
+			if err != nil {
+				return false, err
+			}
+			if message != "" {
+				pending = append(pending, fmt.Sprintf("%s/%s: %s", strings.ToLower(result.GroupVersionKind.Kind), result.Name, message))
+			}
+		}
+
+		if len(pending) != 0 {
+			klog.Infof("waiting for rollout: %s", strings.Join(pending, "; "))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %v waiting for rollout: %s", timeout, strings.Join(pending, "; "))
+	}
+	return err
+}
+
+// rolloutStatus returns a message describing why the workload has not rolled out, or "" if it has
+func rolloutStatus(ctx context.Context, k8sClient kubernetes.Interface, result *ApplyResult) (string, error) {
+	switch result.GroupVersionKind.Kind {
+	case "Deployment":
+		deployment, err := k8sClient.AppsV1().Deployments(result.Namespace).Get(ctx, result.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error getting deployment %s/%s: %v", result.Namespace, result.Name, err)
+		}
+		return deploymentRolloutStatus(deployment)
+
+	case "DaemonSet":
+		daemonSet, err := k8sClient.AppsV1().DaemonSets(result.Namespace).Get(ctx, result.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error getting daemonset %s/%s: %v", result.Namespace, result.Name, err)
+		}
+		return daemonSetRolloutStatus(daemonSet), nil
+
+	case "StatefulSet":
+		statefulSet, err := k8sClient.AppsV1().StatefulSets(result.Namespace).Get(ctx, result.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error getting statefulset %s/%s: %v", result.Namespace, result.Name, err)
+		}
+		return statefulSetRolloutStatus(statefulSet), nil
+	}
+	return "", nil
+}
+
+func deploymentRolloutStatus(deployment *appsv1.Deployment) (string, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return "waiting for the spec update to be observed", nil
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return "", fmt.Errorf("deployment %s/%s exceeded its progress deadline", deployment.Namespace, deployment.Name)
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	if status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas updated", status.UpdatedReplicas, replicas), nil
+	}
+	if status.Replicas > status.UpdatedReplicas {
+		return fmt.Sprintf("%d old replicas pending termination", status.Replicas-status.UpdatedReplicas), nil
+	}
+	if status.AvailableReplicas < status.UpdatedReplicas {
+		return fmt.Sprintf("%d of %d updated replicas available", status.AvailableReplicas, status.UpdatedReplicas), nil
+	}
+	return "", nil
+}
+
+func daemonSetRolloutStatus(daemonSet *appsv1.DaemonSet) string {
+	if daemonSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return ""
+	}
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		return "waiting for the spec update to be observed"
+	}
+
+	status := daemonSet.Status
+	if status.UpdatedNumberScheduled < status.DesiredNumberScheduled {
+		return fmt.Sprintf("%d of %d pods updated", status.UpdatedNumberScheduled, status.DesiredNumberScheduled)
+	}
+	if status.NumberAvailable < status.DesiredNumberScheduled {
+		return fmt.Sprintf("%d of %d updated pods available", status.NumberAvailable, status.DesiredNumberScheduled)
+	}
+	return ""
+}
+
+func statefulSetRolloutStatus(statefulSet *appsv1.StatefulSet) string {
+	if statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return ""
+	}
+	if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return "waiting for the spec update to be observed"
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	if status.ReadyReplicas < replicas {
+		return fmt.Sprintf("%d of %d pods ready", status.ReadyReplicas, replicas)
+	}
+
+	rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		// Only the pods above the partition are updated
+		if status.UpdatedReplicas < replicas-*rollingUpdate.Partition {
+			return fmt.Sprintf("%d of %d pods updated", status.UpdatedReplicas, replicas-*rollingUpdate.Partition)
+		}
+		return ""
+	}
+	if status.UpdateRevision != status.CurrentRevision {
+		return fmt.Sprintf("%d of %d pods updated", status.UpdatedReplicas, replicas)
+	}
+	return ""
+}
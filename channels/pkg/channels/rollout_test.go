@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package channels
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	fakecertmanager "github.com/jetstack/cert-manager/pkg/client/clientset/versioned/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/channels/pkg/api"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func Test_DeploymentRolloutStatus(t *testing.T) {
+	grid := []struct {
+		Description string
+		Generation  int64
+		Status      appsv1.DeploymentStatus
+		Expected    string
+	}{
+		{
+			Description: "rolled out",
+			Generation:  2,
+			Status:      appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+		},
+		{
+			Description: "not observed",
+			Generation:  3,
+			Status:      appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			Expected:    "waiting for the spec update to be observed",
+		},
+		{
+			Description: "updating",
+			Generation:  2,
+			Status:      appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2},
+			Expected:    "1 of 2 replicas updated",
+		},
+		{
+			Description: "old replicas",
+			Generation:  2,
+			Status:      appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+			Expected:    "1 old replicas pending termination",
+		},
+		{
+			Description: "unavailable",
+			Generation:  2,
+			Status:      appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+			Expected:    "1 of 2 updated replicas available",
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: g.Generation},
+				Spec:       appsv1.DeploymentSpec{Replicas: fi.Int32(2)},
+				Status:     g.Status,
+			}
+			actual, err := deploymentRolloutStatus(deployment)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != g.Expected {
+				t.Errorf("expected %q, got %q", g.Expected, actual)
+			}
+		})
+	}
+}
+
+func Test_DeploymentProgressDeadlineExceeded(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "kube-system"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+			},
+		},
+	}
+	if _, err := deploymentRolloutStatus(deployment); err == nil {
+		t.Errorf("expected error for a deployment exceeding its progress deadline")
+	}
+}
+
+func Test_DaemonSetRolloutStatus(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+	}
+	if actual := daemonSetRolloutStatus(daemonSet); actual != "2 of 3 updated pods available" {
+		t.Errorf("unexpected status %q", actual)
+	}
+
+	daemonSet.Spec.UpdateStrategy.Type = appsv1.OnDeleteDaemonSetStrategyType
+	if actual := daemonSetRolloutStatus(daemonSet); actual != "" {
+		t.Errorf("expected OnDelete daemonset to be rolled out, got %q", actual)
+	}
+}
+
+func Test_EnsureUpdatedRollsBack(t *testing.T) {
+	ctx := context.Background()
+	rolloutPollInterval = time.Millisecond
+
+	dir := t.TempDir()
+	writeFile := func(name, contents string) {
+		p := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatalf("error writing %s: %v", p, err)
+		}
+	}
+	writeFile("v2.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: kube-system
+`)
+
+	channelLocation, err := url.Parse("file://" + filepath.Join(dir, "channel.yaml"))
+	if err != nil {
+		t.Fatalf("error parsing channel location: %v", err)
+	}
+
+	kubeSystem := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "kube-system",
+			Annotations: map[string]string{
+				"addons.k8s.io/test": `{"channel":"test","manifestHash":"v1"}`,
+			},
+		},
+	}
+	// The deployment never becomes available
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "controller", Namespace: "kube-system"},
+		Spec:       appsv1.DeploymentSpec{Replicas: fi.Int32(1)},
+		Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1},
+	}
+	fakek8s := fakekubernetes.NewSimpleClientset(kubeSystem, deployment)
+	fakecm := fakecertmanager.NewSimpleClientset()
+
+	applier := newTestApplier(t)
+
+	addon := &Addon{
+		Name:            "test",
+		ChannelName:     "test",
+		ChannelLocation: *channelLocation,
+		Spec: &api.AddonSpec{
+			Name:           fi.String("test"),
+			Manifest:       fi.String("v2.yaml"),
+			ManifestHash:   "v2",
+			RolloutTimeout: &metav1.Duration{Duration: 10 * time.Millisecond},
+		},
+	}
+
+	if err := addon.writeAppliedManifest(ctx, fakek8s, []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: previous
+  namespace: kube-system
+`)); err != nil {
+		t.Fatalf("error writing applied manifest: %v", err)
+	}
+
+	_, err = addon.EnsureUpdated(ctx, fakek8s, fakecm, applier.Applier)
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("expected rolled back update, got %v", err)
+	}
+
+	if _, err := applier.tracker.Get(configMapGVR, "kube-system", "previous"); err != nil {
+		t.Errorf("expected previous manifest to be re-applied: %v", err)
+	}
+
+	status, err := addon.buildChannel().GetStatus(ctx, fakek8s)
+	if err != nil {
+		t.Fatalf("error getting status: %v", err)
+	}
+	if status == nil || !status.RolledBack || status.FailedManifestHash != "v2" || !strings.Contains(status.Message, "0 of 1 updated replicas available") {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	installed, err := addon.buildChannel().GetInstalledVersion(ctx, fakek8s)
+	if err != nil {
+		t.Fatalf("error getting installed version: %v", err)
+	}
+	if installed.ManifestHash != "v1" {
+		t.Errorf("installed version should not change, got %v", installed)
+	}
+
+	// The failed version is not retried
+	update, err := addon.GetRequiredUpdates(ctx, fakek8s, fakecm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if update != nil {
+		t.Errorf("expected failed version not to be retried, got %+v", update)
+	}
+
+	// A new version is applied, clearing the failure
+	writeFile("v3.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: current
+  namespace: kube-system
+`)
+	addon.Spec.Manifest = fi.String("v3.yaml")
+	addon.Spec.ManifestHash = "v3"
+	if _, err := addon.EnsureUpdated(ctx, fakek8s, fakecm, applier.Applier); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	status, err = addon.buildChannel().GetStatus(ctx, fakek8s)
+	if err != nil {
+		t.Fatalf("error getting status: %v", err)
+	}
+	if status != nil {
+		t.Errorf("expected status to be cleared, got %+v", status)
+	}
+
+	applied, err := addon.readAppliedManifest(ctx, fakek8s)
+	if err != nil {
+		t.Fatalf("error reading applied manifest: %v", err)
+	}
+	if !strings.Contains(string(applied), "name: current") {
+		t.Errorf("expected applied manifest to be recorded, got %q", string(applied))
+	}
+}
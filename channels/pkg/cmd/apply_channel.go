@@ -191,11 +191,29 @@ func RunApplyChannel(ctx context.Context, f Factory, out io.Writer, options *App
 		return nil
 	}
 
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return err
+	}
+
+	restMapper, err := f.RESTMapper()
+	if err != nil {
+		return err
+	}
+
+	applier := &channels.Applier{
+		Client:     dynamicClient,
+		RESTMapper: restMapper,
+	}
+
 	for _, needUpdate := range needUpdates {
-		update, err := needUpdate.EnsureUpdated(ctx, k8sClient, cmClient)
+		update, err := needUpdate.EnsureUpdated(ctx, k8sClient, cmClient, applier)
 		if err != nil {
 			fmt.Printf("error updating %q: %v", needUpdate.Name, err)
 		} else if update != nil {
+			for _, result := range update.Results {
+				fmt.Printf("  %s\n", result)
+			}
 			fmt.Printf("Updated %q\n", update.Name)
 		}
 	}
@@ -19,8 +19,13 @@ package cmd
 import (
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -31,11 +36,15 @@ import (
 type Factory interface {
 	KubernetesClient() (kubernetes.Interface, error)
 	CertManagerClient() (certmanager.Interface, error)
+	DynamicClient() (dynamic.Interface, error)
+	RESTMapper() (meta.RESTMapper, error)
 }
 
 type DefaultFactory struct {
 	kubernetesClient  kubernetes.Interface
 	certManagerClient certmanager.Interface
+	dynamicClient     dynamic.Interface
+	restMapper        meta.RESTMapper
 }
 
 var _ Factory = &DefaultFactory{}
@@ -84,3 +93,35 @@ func (f *DefaultFactory) CertManagerClient() (certmanager.Interface, error) {
 
 	return f.certManagerClient, nil
 }
+
+func (f *DefaultFactory) DynamicClient() (dynamic.Interface, error) {
+	if f.dynamicClient == nil {
+		config, err := loadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("cannot load kubecfg settings: %v", err)
+		}
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("cannot build dynamic client: %v", err)
+		}
+		f.dynamicClient = dynamicClient
+	}
+
+	return f.dynamicClient, nil
+}
+
+func (f *DefaultFactory) RESTMapper() (meta.RESTMapper, error) {
+	if f.restMapper == nil {
+		config, err := loadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("cannot load kubecfg settings: %v", err)
+		}
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("cannot build discovery client: %v", err)
+		}
+		f.restMapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	}
+
+	return f.restMapper, nil
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/channels/pkg/channels"
 	"k8s.io/kops/util/pkg/tables"
 )
@@ -68,6 +69,7 @@ func NewCmdGetAddons(f Factory, out io.Writer) *cobra.Command {
 type addonInfo struct {
 	Name      string
 	Version   *channels.ChannelVersion
+	Status    *api.AddonStatus
 	Namespace *v1.Namespace
 }
 
@@ -87,14 +89,29 @@ func RunGetAddons(ctx context.Context, f Factory, out io.Writer, options *GetAdd
 	for i := range namespaces.Items {
 		ns := &namespaces.Items[i]
 		addons := channels.FindAddons(ns)
+		statuses, err := api.FindAddonStatuses(ns.Annotations)
+		if err != nil {
+			return fmt.Errorf("error reading addon status in namespace %q: %v", ns.Name, err)
+		}
 		for name, version := range addons {
 			i := &addonInfo{
 				Name:      name,
 				Version:   version,
+				Status:    statuses[name],
 				Namespace: ns,
 			}
 			info = append(info, i)
 		}
+		for name, status := range statuses {
+			if addons[name] == nil {
+				// The first install of the addon failed
+				info = append(info, &addonInfo{
+					Name:      name,
+					Status:    status,
+					Namespace: ns,
+				})
+			}
+		}
 	}
 
 	if len(info) == 0 {
@@ -126,13 +143,30 @@ func RunGetAddons(ctx context.Context, f Factory, out io.Writer, options *GetAdd
 			return "?"
 		})
 
-		columns := []string{"NAMESPACE", "NAME", "HASH", "CHANNEL"}
+		t.AddColumn("STATUS", func(r *addonInfo) string {
+			switch {
+			case r.Status == nil:
+				return "Installed"
+			case r.Status.RolledBack:
+				return "RolledBack"
+			default:
+				return "Failed"
+			}
+		})
+
+		columns := []string{"NAMESPACE", "NAME", "HASH", "CHANNEL", "STATUS"}
 		err := t.Render(info, os.Stdout, columns...)
 		if err != nil {
 			return err
 		}
 	}
 
+	for _, i := range info {
+		if i.Status != nil {
+			fmt.Printf("\nAddon %q (hash %s) %s\n", i.Name, i.Status.FailedManifestHash, i.Status.Describe())
+		}
+	}
+
 	fmt.Printf("\n")
 
 	return nil
@@ -0,0 +1,272 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+)
+
+// ec2API adds to the mock the operations describing the region, which the mock leaves to the tests
+type ec2API struct {
+	*mockec2.MockEC2
+	server *Server
+}
+
+func (m *ec2API) DescribeRegions(*ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error) {
+	return &ec2.DescribeRegionsOutput{
+		Regions: []*ec2.Region{
+			{
+				RegionName:  aws.String(m.server.options.Region),
+				OptInStatus: aws.String("opt-in-not-required"),
+			},
+		},
+	}, nil
+}
+
+func (m *ec2API) DescribeAvailabilityZones(*ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	output := &ec2.DescribeAvailabilityZonesOutput{}
+	for i, zone := range m.server.options.Zones {
+		output.AvailabilityZones = append(output.AvailabilityZones, &ec2.AvailabilityZone{
+			RegionName: aws.String(m.server.options.Region),
+			ZoneName:   aws.String(zone),
+			ZoneId:     aws.String(fmt.Sprintf("fake-az%d", i+1)),
+			State:      aws.String(ec2.AvailabilityZoneStateAvailable),
+			ZoneType:   aws.String("availability-zone"),
+		})
+	}
+	return output, nil
+}
+
+// DescribeInstanceTypes describes any instance type as a small instance,
+// with the architecture of the Graviton families when the family ends in "g"
+func (m *ec2API) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	output := &ec2.DescribeInstanceTypesOutput{}
+	for _, instanceType := range aws.StringValueSlice(input.InstanceTypes) {
+		family := strings.Split(instanceType, ".")[0]
+		architecture := ec2.ArchitectureTypeX8664
+		if family == "a1" || (strings.HasSuffix(family, "g") && len(family) > 1) {
+			architecture = ec2.ArchitectureTypeArm64
+		}
+		output.InstanceTypes = append(output.InstanceTypes, &ec2.InstanceTypeInfo{
+			InstanceType:      aws.String(instanceType),
+			CurrentGeneration: aws.Bool(true),
+			MemoryInfo:        &ec2.MemoryInfo{SizeInMiB: aws.Int64(4096)},
+			NetworkInfo: &ec2.NetworkInfo{
+				MaximumNetworkInterfaces:  aws.Int64(3),
+				Ipv4AddressesPerInterface: aws.Int64(10),
+				Ipv6AddressesPerInterface: aws.Int64(10),
+				Ipv6Supported:             aws.Bool(true),
+			},
+			ProcessorInfo: &ec2.ProcessorInfo{
+				SupportedArchitectures: aws.StringSlice([]string{architecture}),
+			},
+			SupportedUsageClasses: aws.StringSlice([]string{ec2.UsageClassTypeOnDemand, ec2.UsageClassTypeSpot}),
+			VCpuInfo: &ec2.VCpuInfo{
+				DefaultVCpus: aws.Int64(2),
+			},
+		})
+	}
+	return output, nil
+}
+
+// DescribeReservedInstancesOfferings offers any instance type in every zone
+func (m *ec2API) DescribeReservedInstancesOfferings(input *ec2.DescribeReservedInstancesOfferingsInput) (*ec2.DescribeReservedInstancesOfferingsOutput, error) {
+	output := &ec2.DescribeReservedInstancesOfferingsOutput{}
+	for _, zone := range m.server.options.Zones {
+		output.ReservedInstancesOfferings = append(output.ReservedInstancesOfferings, &ec2.ReservedInstancesOffering{
+			AvailabilityZone: aws.String(zone),
+			InstanceType:     input.InstanceType,
+		})
+	}
+	return output, nil
+}
+
+// DescribeImages registers the images which are looked up by name or by id before describing them,
+// so that any image is found
+func (m *ec2API) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	for _, id := range aws.StringValueSlice(input.ImageIds) {
+		if m.findImage(func(image *ec2.Image) bool { return aws.StringValue(image.ImageId) == id }) == nil {
+			m.addImage(id, id, "self")
+		}
+	}
+
+	owner := "self"
+	if len(input.Owners) == 1 {
+		owner = aws.StringValue(input.Owners[0])
+	}
+	for _, filter := range input.Filters {
+		if aws.StringValue(filter.Name) != "name" {
+			continue
+		}
+		for _, name := range aws.StringValueSlice(filter.Values) {
+			if strings.Contains(name, "*") {
+				continue
+			}
+			if m.findImage(func(image *ec2.Image) bool { return aws.StringValue(image.Name) == name }) == nil {
+				m.addImage(fmt.Sprintf("ami-%x", sha256.Sum256([]byte(owner+"/"+name)))[:12], name, owner)
+			}
+		}
+	}
+
+	output, err := m.MockEC2.DescribeImages(&ec2.DescribeImagesInput{Filters: input.Filters})
+	if err != nil {
+		return nil, err
+	}
+	if len(input.ImageIds) != 0 {
+		var images []*ec2.Image
+		for _, image := range output.Images {
+			for _, id := range input.ImageIds {
+				if aws.StringValue(image.ImageId) == aws.StringValue(id) {
+					images = append(images, image)
+				}
+			}
+		}
+		output.Images = images
+	}
+	return output, nil
+}
+
+func (m *ec2API) findImage(match func(image *ec2.Image) bool) *ec2.Image {
+	for _, image := range m.Images {
+		if match(image) {
+			return image
+		}
+	}
+	return nil
+}
+
+func (m *ec2API) addImage(id, name, owner string) {
+	klog.Infof("fake cloud: registering image %q as %s", name, id)
+
+	architecture := ec2.ArchitectureValuesX8664
+	if strings.Contains(name, "arm64") || strings.Contains(name, "aarch64") {
+		architecture = ec2.ArchitectureValuesArm64
+	}
+	m.Images = append(m.Images, &ec2.Image{
+		ImageId:        aws.String(id),
+		Name:           aws.String(name),
+		OwnerId:        aws.String(owner),
+		Architecture:   aws.String(architecture),
+		CreationDate:   aws.String(time.Now().UTC().Format(time.RFC3339)),
+		RootDeviceName: aws.String("/dev/xvda"),
+		RootDeviceType: aws.String(ec2.DeviceTypeEbs),
+		State:          aws.String(ec2.ImageStateAvailable),
+		BlockDeviceMappings: []*ec2.BlockDeviceMapping{
+			{
+				DeviceName: aws.String("/dev/xvda"),
+				Ebs: &ec2.EbsBlockDevice{
+					VolumeSize: aws.Int64(8),
+					VolumeType: aws.String(ec2.VolumeTypeGp2),
+				},
+			},
+		},
+	})
+}
+
+// TerminateInstances also removes the instances from their autoscaling group, as AWS does
+func (m *ec2API) TerminateInstances(input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+	if !aws.BoolValue(input.DryRun) {
+		for _, id := range input.InstanceIds {
+			// Instances outside of any group are not found
+			_, _ = m.server.Autoscaling.TerminateInstanceInAutoScalingGroup(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
+				InstanceId:                     id,
+				ShouldDecrementDesiredCapacity: aws.Bool(false),
+			})
+		}
+	}
+	return m.MockEC2.TerminateInstances(input)
+}
+
+// autoscalingAPI terminates the instances which the mock only removes from the groups
+type autoscalingAPI struct {
+	*mockautoscaling.MockAutoscaling
+	server *Server
+}
+
+func (m *autoscalingAPI) TerminateInstanceInAutoScalingGroup(input *autoscaling.TerminateInstanceInAutoScalingGroupInput) (*autoscaling.TerminateInstanceInAutoScalingGroupOutput, error) {
+	group := m.findGroupOfInstance(aws.StringValue(input.InstanceId))
+	output, err := m.MockAutoscaling.TerminateInstanceInAutoScalingGroup(input)
+	if err != nil {
+		return nil, awserr.New("ValidationError", err.Error(), nil)
+	}
+	if group != nil && aws.BoolValue(input.ShouldDecrementDesiredCapacity) {
+		group.DesiredCapacity = aws.Int64(aws.Int64Value(group.DesiredCapacity) - 1)
+	}
+
+	if _, err := m.server.EC2.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: []*string{input.InstanceId}}); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// DeleteAutoScalingGroup terminates the instances of the group, as a forced deletion does
+func (m *autoscalingAPI) DeleteAutoScalingGroup(input *autoscaling.DeleteAutoScalingGroupInput) (*autoscaling.DeleteAutoScalingGroupOutput, error) {
+	var instanceIDs []*string
+	if group := m.Groups[aws.StringValue(input.AutoScalingGroupName)]; group != nil {
+		for _, instance := range group.Instances {
+			instanceIDs = append(instanceIDs, instance.InstanceId)
+		}
+	}
+
+	output, err := m.MockAutoscaling.DeleteAutoScalingGroup(input)
+	if err != nil {
+		return nil, awserr.New("ValidationError", err.Error(), nil)
+	}
+
+	if len(instanceIDs) != 0 {
+		if _, err := m.server.EC2.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: instanceIDs}); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+func (m *autoscalingAPI) findGroupOfInstance(id string) *autoscaling.Group {
+	for _, group := range m.Groups {
+		for _, instance := range group.Instances {
+			if aws.StringValue(instance.InstanceId) == id {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+// stsAPI identifies every caller as a user of the fake account
+type stsAPI struct {
+	accountID string
+}
+
+func (m *stsAPI) GetCallerIdentity(*sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String(m.accountID),
+		Arn:     aws.String(fmt.Sprintf("arn:aws:iam::%s:user/fake-cloud", m.accountID)),
+		UserId:  aws.String("AIDAFAKECLOUD"),
+	}, nil
+}
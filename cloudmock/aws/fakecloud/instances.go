@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// tagAutoscalingGroupName is the tag AWS sets on the instances launched by an autoscaling group
+const tagAutoscalingGroupName = "aws:autoscaling:groupName"
+
+// reconcileInstances launches and terminates instances until every autoscaling group has its desired capacity.
+// It must be called with the mutex held.
+func (s *Server) reconcileInstances() error {
+	var errs []error
+	var names []string
+	for name := range s.Autoscaling.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		g := s.Autoscaling.Groups[name]
+
+		// Forget the instances which were terminated directly
+		var instances []*autoscaling.Instance
+		for _, instance := range g.Instances {
+			if s.EC2.Instances[aws.StringValue(instance.InstanceId)] != nil {
+				instances = append(instances, instance)
+			}
+		}
+		g.Instances = instances
+
+		desired := int(aws.Int64Value(g.DesiredCapacity))
+		for len(g.Instances) < desired {
+			if err := s.launchInstance(g); err != nil {
+				errs = append(errs, fmt.Errorf("error launching instance in autoscaling group %q: %v", name, err))
+				break
+			}
+		}
+		for len(g.Instances) > desired {
+			last := g.Instances[len(g.Instances)-1]
+			g.Instances = g.Instances[:len(g.Instances)-1]
+			klog.Infof("fake cloud: terminating instance %s of autoscaling group %q", aws.StringValue(last.InstanceId), name)
+			if _, err := s.EC2.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: []*string{last.InstanceId}}); err != nil {
+				errs = append(errs, fmt.Errorf("error terminating instance %q: %v", aws.StringValue(last.InstanceId), err))
+			}
+		}
+	}
+
+	return errors.NewAggregate(errs)
+}
+
+// launchInstance launches an instance from the launch template of the group,
+// in the subnet of the group with the fewest instances
+func (s *Server) launchInstance(g *autoscaling.Group) error {
+	spec := g.LaunchTemplate
+	if spec == nil && g.MixedInstancesPolicy != nil && g.MixedInstancesPolicy.LaunchTemplate != nil {
+		spec = g.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification
+	}
+	if spec == nil {
+		return fmt.Errorf("only groups with a launch template are supported")
+	}
+
+	templates, err := s.EC2.DescribeLaunchTemplates(&ec2.DescribeLaunchTemplatesInput{
+		LaunchTemplateIds: []*string{spec.LaunchTemplateId},
+	})
+	if err != nil {
+		return err
+	}
+	if len(templates.LaunchTemplates) != 1 {
+		return fmt.Errorf("launch template %q not found", aws.StringValue(spec.LaunchTemplateId))
+	}
+	template := templates.LaunchTemplates[0]
+
+	subnetInstances := make(map[string]int)
+	var subnets []string
+	for _, subnet := range strings.Split(aws.StringValue(g.VPCZoneIdentifier), ",") {
+		if subnet != "" {
+			subnets = append(subnets, subnet)
+			subnetInstances[subnet] = 0
+		}
+	}
+	if len(subnets) == 0 {
+		return fmt.Errorf("the group has no subnets")
+	}
+	for _, instance := range g.Instances {
+		if i := s.EC2.Instances[aws.StringValue(instance.InstanceId)]; i != nil {
+			subnetInstances[aws.StringValue(i.SubnetId)]++
+		}
+	}
+	subnet := subnets[0]
+	for _, candidate := range subnets {
+		if subnetInstances[candidate] < subnetInstances[subnet] {
+			subnet = candidate
+		}
+	}
+
+	tags := []*ec2.Tag{
+		{Key: aws.String(tagAutoscalingGroupName), Value: g.AutoScalingGroupName},
+	}
+	for _, tag := range g.Tags {
+		if aws.BoolValue(tag.PropagateAtLaunch) {
+			tags = append(tags, &ec2.Tag{Key: tag.Key, Value: tag.Value})
+		}
+	}
+
+	reservation, err := s.EC2.RunInstances(&ec2.RunInstancesInput{
+		LaunchTemplate: &ec2.LaunchTemplateSpecification{
+			LaunchTemplateId: template.LaunchTemplateId,
+		},
+		SubnetId: aws.String(subnet),
+		MinCount: aws.Int64(1),
+		MaxCount: aws.Int64(1),
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeInstance),
+				Tags:         tags,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	instance := reservation.Instances[0]
+	klog.Infof("fake cloud: launched instance %s in autoscaling group %q", aws.StringValue(instance.InstanceId), aws.StringValue(g.AutoScalingGroupName))
+
+	g.Instances = append(g.Instances, &autoscaling.Instance{
+		InstanceId:       instance.InstanceId,
+		InstanceType:     instance.InstanceType,
+		AvailabilityZone: instance.Placement.AvailabilityZone,
+		HealthStatus:     aws.String("Healthy"),
+		LifecycleState:   aws.String(autoscaling.LifecycleStateInService),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateId:   template.LaunchTemplateId,
+			LaunchTemplateName: template.LaunchTemplateName,
+			Version:            aws.String(strconv.FormatInt(aws.Int64Value(template.LatestVersionNumber), 10)),
+		},
+		ProtectedFromScaleIn: aws.Bool(false),
+	})
+	return nil
+}
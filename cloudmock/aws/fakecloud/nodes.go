@@ -0,0 +1,253 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	nodeidentityaws "k8s.io/kops/pkg/nodeidentity/aws"
+)
+
+const (
+	// LabelInstanceID marks the nodes registered by the fake cloud with the id of their instance
+	LabelInstanceID = "fakecloud.kops.k8s.io/instance-id"
+
+	// clusterAutoscalerNodeTemplateTaint is the prefix of the tags holding the taints of the nodes of a group
+	clusterAutoscalerNodeTemplateTaint = "k8s.io/cluster-autoscaler/node-template/taint/"
+)
+
+// controlPlanePods are the static pods which kops validates on the control plane nodes
+var controlPlanePods = []string{
+	"kube-apiserver",
+	"kube-controller-manager",
+	"kube-scheduler",
+}
+
+// buildNodes returns the nodes of the running instances launched at least BootDelay before now.
+// Their labels and taints are read from the tags kops sets for the cluster autoscaler.
+// It must be called with the mutex held.
+func (s *Server) buildNodes(now time.Time) []*v1.Node {
+	var ids []string
+	for id := range s.EC2.Instances {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var nodes []*v1.Node
+	for _, id := range ids {
+		instance := s.EC2.Instances[id]
+		if aws.StringValue(instance.State.Name) != ec2.InstanceStateNameRunning {
+			continue
+		}
+		if instance.LaunchTime != nil && now.Sub(*instance.LaunchTime) < s.options.BootDelay {
+			continue
+		}
+
+		tags, err := s.EC2.DescribeTags(&ec2.DescribeTagsInput{
+			Filters: []*ec2.Filter{
+				{Name: aws.String("resource-id"), Values: []*string{instance.InstanceId}},
+			},
+		})
+		if err != nil {
+			klog.Warningf("error describing the tags of instance %q: %v", id, err)
+			continue
+		}
+
+		name := aws.StringValue(instance.PrivateDnsName)
+		zone := aws.StringValue(instance.Placement.AvailabilityZone)
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					LabelInstanceID:            id,
+					v1.LabelHostname:           name,
+					v1.LabelOSStable:           "linux",
+					v1.LabelInstanceTypeStable: aws.StringValue(instance.InstanceType),
+					v1.LabelTopologyRegion:     s.options.Region,
+					v1.LabelTopologyZone:       zone,
+				},
+			},
+			Spec: v1.NodeSpec{
+				ProviderID: fmt.Sprintf("aws:///%s/%s", zone, id),
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: aws.StringValue(instance.PrivateIpAddress)},
+					{Type: v1.NodeInternalDNS, Address: name},
+					{Type: v1.NodeHostName, Address: name},
+				},
+				Conditions: []v1.NodeCondition{
+					{
+						Type:               v1.NodeReady,
+						Status:             v1.ConditionTrue,
+						Reason:             "KubeletReady",
+						Message:            "the fake cloud reports the node as ready",
+						LastHeartbeatTime:  metav1.NewTime(now),
+						LastTransitionTime: metav1.NewTime(now),
+					},
+				},
+			},
+		}
+		for _, tag := range tags.Tags {
+			key, value := aws.StringValue(tag.Key), aws.StringValue(tag.Value)
+			if strings.HasPrefix(key, nodeidentityaws.ClusterAutoscalerNodeTemplateLabel) {
+				node.Labels[strings.TrimPrefix(key, nodeidentityaws.ClusterAutoscalerNodeTemplateLabel)] = value
+			}
+			if strings.HasPrefix(key, clusterAutoscalerNodeTemplateTaint) {
+				taint := v1.Taint{Key: strings.TrimPrefix(key, clusterAutoscalerNodeTemplateTaint)}
+				if i := strings.LastIndex(value, ":"); i >= 0 {
+					taint.Value = value[:i]
+					taint.Effect = v1.TaintEffect(value[i+1:])
+				} else {
+					taint.Value = value
+					taint.Effect = v1.TaintEffectNoSchedule
+				}
+				node.Spec.Taints = append(node.Spec.Taints, taint)
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// syncNodes registers the nodes which do not exist yet, and deletes the nodes of the fake cloud
+// which are not in nodes any more
+func (s *Server) syncNodes(ctx context.Context, nodes []*v1.Node) error {
+	client := s.options.Nodes
+
+	existing, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: LabelInstanceID})
+	if err != nil {
+		return fmt.Errorf("error listing nodes: %v", err)
+	}
+	registered := make(map[string]bool)
+	for _, node := range existing.Items {
+		registered[node.Name] = true
+	}
+
+	wanted := make(map[string]bool)
+	for _, node := range nodes {
+		wanted[node.Name] = true
+		if registered[node.Name] {
+			continue
+		}
+
+		klog.Infof("fake cloud: registering node %q of instance %s", node.Name, node.Labels[LabelInstanceID])
+		status := node.Status
+		created, err := client.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("error creating node %q: %v", node.Name, err)
+		}
+		created.Status = status
+		if _, err := client.CoreV1().Nodes().UpdateStatus(ctx, created, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error updating the status of node %q: %v", node.Name, err)
+		}
+
+		if node.Labels["kubernetes.io/role"] == "master" {
+			if err := s.createControlPlanePods(ctx, node); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, node := range existing.Items {
+		if wanted[node.Name] {
+			continue
+		}
+		klog.Infof("fake cloud: deleting node %q of instance %s", node.Name, node.Labels[LabelInstanceID])
+		if err := client.CoreV1().Nodes().Delete(ctx, node.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting node %q: %v", node.Name, err)
+		}
+
+		// There is no garbage collection of the pods of deleted nodes without a controller manager
+		pods, err := client.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{
+			LabelSelector: LabelInstanceID + "=" + node.Labels[LabelInstanceID],
+		})
+		if err != nil {
+			return fmt.Errorf("error listing pods of node %q: %v", node.Name, err)
+		}
+		for _, pod := range pods.Items {
+			if err := client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("error deleting pod %q: %v", pod.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// createControlPlanePods creates running mirror pods for the static pods of a control plane node,
+// so that the node passes validation. Being mirror pods, they are not evicted when the node is drained.
+func (s *Server) createControlPlanePods(ctx context.Context, node *v1.Node) error {
+	client := s.options.Nodes
+
+	var hostIP string
+	for _, address := range node.Status.Addresses {
+		if address.Type == v1.NodeInternalIP {
+			hostIP = address.Address
+		}
+	}
+
+	for _, app := range controlPlanePods {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      app + "-" + node.Name,
+				Namespace: metav1.NamespaceSystem,
+				Labels: map[string]string{
+					"k8s-app":       app,
+					LabelInstanceID: node.Labels[LabelInstanceID],
+				},
+				Annotations: map[string]string{
+					"kubernetes.io/config.mirror": "fake-cloud",
+				},
+			},
+			Spec: v1.PodSpec{
+				NodeName:          node.Name,
+				PriorityClassName: "system-cluster-critical",
+				Containers: []v1.Container{
+					{Name: app, Image: "fake-cloud/" + app},
+				},
+			},
+		}
+		created, err := client.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("error creating pod %q: %v", pod.Name, err)
+		}
+		created.Status = v1.PodStatus{
+			Phase:  v1.PodRunning,
+			HostIP: hostIP,
+			PodIP:  hostIP,
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: app, Image: "fake-cloud/" + app, Ready: true},
+			},
+		}
+		if _, err := client.CoreV1().Pods(pod.Namespace).UpdateStatus(ctx, created, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error updating the status of pod %q: %v", pod.Name, err)
+		}
+	}
+	return nil
+}
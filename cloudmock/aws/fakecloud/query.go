@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/private/protocol"
+)
+
+// queryDecoder fills the input shapes of the SDK from the parameters of the query protocol,
+// reversing what the SDK's queryutil does when building requests
+type queryDecoder struct {
+	values url.Values
+	// isEC2 is set for the EC2 flavor of the query protocol, which names and flattens lists differently
+	isEC2 bool
+}
+
+// has returns true if there is a parameter for the value at prefix
+func (d *queryDecoder) has(prefix string) bool {
+	for k := range d.values {
+		if k == prefix || strings.HasPrefix(k, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeStruct fills the fields of the struct v from the parameters under prefix
+func (d *queryDecoder) decodeStruct(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("ignore") != "" {
+			continue
+		}
+
+		var name string
+		if d.isEC2 {
+			name = field.Tag.Get("queryName")
+		}
+		if name == "" {
+			if field.Tag.Get("flattened") != "" && field.Tag.Get("locationNameList") != "" {
+				name = field.Tag.Get("locationNameList")
+			} else if locationName := field.Tag.Get("locationName"); locationName != "" {
+				name = locationName
+			}
+			if name != "" && d.isEC2 {
+				name = strings.ToUpper(name[0:1]) + name[1:]
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if err := d.decodeValue(v.Field(i), name, field.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeValue sets v from the parameters under prefix, leaving it unset if there are none
+func (d *queryDecoder) decodeValue(v reflect.Value, prefix string, tag reflect.StructTag) error {
+	if !d.has(prefix) {
+		return nil
+	}
+
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		p := reflect.New(t.Elem())
+		if err := d.decodeValue(p.Elem(), prefix, tag); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && t != timeType:
+		return d.decodeStruct(v, prefix)
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		if !d.isEC2 && tag.Get("flattened") == "" {
+			if memberName := tag.Get("locationNameList"); memberName != "" {
+				prefix += "." + memberName
+			} else {
+				prefix += ".member"
+			}
+		}
+		list := reflect.MakeSlice(t, 0, 0)
+		for i := 1; d.has(prefix + "." + strconv.Itoa(i)); i++ {
+			item := reflect.New(t.Elem()).Elem()
+			if err := d.decodeValue(item, prefix+"."+strconv.Itoa(i), ""); err != nil {
+				return err
+			}
+			list = reflect.Append(list, item)
+		}
+		v.Set(list)
+		return nil
+
+	case t.Kind() == reflect.Map:
+		if !d.isEC2 && tag.Get("flattened") == "" {
+			prefix += ".entry"
+		}
+		keyName, valueName := "key", "value"
+		if n := tag.Get("locationNameKey"); n != "" {
+			keyName = n
+		}
+		if n := tag.Get("locationNameValue"); n != "" {
+			valueName = n
+		}
+		m := reflect.MakeMap(t)
+		for i := 1; d.has(prefix + "." + strconv.Itoa(i)); i++ {
+			entry := prefix + "." + strconv.Itoa(i)
+			key := reflect.New(t.Key()).Elem()
+			if err := d.decodeValue(key, entry+"."+keyName, ""); err != nil {
+				return err
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := d.decodeValue(value, entry+"."+valueName, ""); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+		return nil
+
+	default:
+		return parseScalar(v, d.values.Get(prefix), tag)
+	}
+}
+
+// parseScalar sets v from the serialized form of a scalar
+func parseScalar(v reflect.Value, s string, tag reflect.StructTag) error {
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(s)
+	case []byte:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("error decoding base64 value %q: %v", s, err)
+		}
+		v.SetBytes(b)
+	case bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("error parsing boolean %q: %v", s, err)
+		}
+		v.SetBool(b)
+	case int64, int:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing integer %q: %v", s, err)
+		}
+		v.SetInt(n)
+	case float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing number %q: %v", s, err)
+		}
+		v.SetFloat(f)
+	default:
+		if v.Type() != timeType {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		format := tag.Get("timestampFormat")
+		if format == "" {
+			format = protocol.ISO8601TimeFormatName
+		}
+		t, err := protocol.ParseTime(format, s)
+		if err != nil {
+			return fmt.Errorf("error parsing timestamp %q: %v", s, err)
+		}
+		v.Set(reflect.ValueOf(t))
+	}
+	return nil
+}
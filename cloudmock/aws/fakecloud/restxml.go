@@ -0,0 +1,300 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/private/protocol/xml/xmlutil"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cloudmock/aws/mockroute53"
+)
+
+const route53Namespace = "https://route53.amazonaws.com/doc/2013-04-01/"
+
+// restOperation is an operation of a REST API, routed by its method and path
+type restOperation struct {
+	name   string
+	method string
+	// path holds the segments of the path of the operation, where "{Name}" is a parameter
+	// and "{Name+}" a parameter matching the rest of the path
+	path []string
+}
+
+// buildRoute53Operations lists the operations of Route53, as described by the SDK.
+// The requests are built with a client that is never sent anything.
+func buildRoute53Operations() ([]*restOperation, error) {
+	sess, err := session.NewSession(aws.NewConfig().WithRegion("us-east-1").WithCredentials(credentials.AnonymousCredentials))
+	if err != nil {
+		return nil, fmt.Errorf("error building AWS session: %v", err)
+	}
+	client := reflect.ValueOf(route53.New(sess))
+	requestType := reflect.TypeOf(&request.Request{})
+
+	var operations []*restOperation
+	for i := 0; i < client.NumMethod(); i++ {
+		if !strings.HasSuffix(client.Type().Method(i).Name, "Request") {
+			continue
+		}
+		method := client.Method(i)
+		t := method.Type()
+		if t.NumIn() != 1 || t.In(0).Kind() != reflect.Ptr || t.NumOut() != 2 || t.Out(0) != requestType {
+			continue
+		}
+
+		req := method.Call([]reflect.Value{reflect.New(t.In(0).Elem())})[0].Interface().(*request.Request)
+		path := req.Operation.HTTPPath
+		if i := strings.Index(path, "?"); i >= 0 {
+			path = path[:i]
+		}
+		operations = append(operations, &restOperation{
+			name:   req.Operation.Name,
+			method: req.Operation.HTTPMethod,
+			path:   splitPath(path),
+		})
+	}
+	return operations, nil
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// match returns the parameters of the path if it is a path of the operation
+func (o *restOperation) match(segments []string) (map[string]string, bool) {
+	params := make(map[string]string)
+	for i, segment := range o.path {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "+}") {
+			if i >= len(segments) {
+				return nil, false
+			}
+			params[strings.Trim(segment, "{+}")] = strings.Join(segments[i:], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[strings.Trim(segment, "{}")] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	if len(o.path) != len(segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+// serveREST serves the REST API of Route53
+func (s *Server) serveREST(w http.ResponseWriter, r *http.Request) {
+	var segments []string
+	for _, segment := range splitPath(r.URL.EscapedPath()) {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			writeError(w, false, awserr.New("InvalidInput", fmt.Sprintf("invalid path %q", r.URL.Path), nil))
+			return
+		}
+		segments = append(segments, unescaped)
+	}
+
+	// Prefer the operation with the most literal segments, e.g. /hostedzonecount over /hostedzone/{Id}
+	var operation *restOperation
+	var params map[string]string
+	bestScore := -1
+	for _, o := range s.route53Operations {
+		if o.method != r.Method {
+			continue
+		}
+		p, ok := o.match(segments)
+		if !ok {
+			continue
+		}
+		score := len(o.path) - len(p)
+		if score > bestScore {
+			operation, params, bestScore = o, p, score
+		}
+	}
+	if operation == nil {
+		writeError(w, false, awserr.New("InvalidAction", fmt.Sprintf("no operation for %s %s", r.Method, r.URL.Path), nil))
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, false, awserr.New("InvalidInput", fmt.Sprintf("error reading request: %v", err), nil))
+		return
+	}
+
+	output, err := s.call(s.route53API, operation.name, func(input reflect.Value) error {
+		return decodeREST(input, params, r, body)
+	}, func(output reflect.Value) ([]byte, error) {
+		return encodeRESTResponse(operation.name, output)
+	})
+	if err != nil {
+		writeError(w, false, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(output); err != nil {
+		klog.Warningf("error writing response: %v", err)
+	}
+}
+
+// decodeREST fills the input of an operation from the path, query, headers and body of the request
+func decodeREST(input reflect.Value, params map[string]string, r *http.Request, body []byte) error {
+	if len(bytes.TrimSpace(body)) != 0 {
+		if err := xmlutil.UnmarshalXML(input.Interface(), xml.NewDecoder(bytes.NewReader(body)), ""); err != nil {
+			return err
+		}
+	}
+
+	v := input.Elem()
+	t := v.Type()
+	query := r.URL.Query()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("locationName")
+
+		var values []string
+		switch field.Tag.Get("location") {
+		case "uri":
+			if value, ok := params[name]; ok {
+				values = []string{value}
+			}
+		case "querystring":
+			values = query[name]
+		case "header":
+			values = r.Header.Values(name)
+		default:
+			continue
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		f := v.Field(i)
+		if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+			list := reflect.MakeSlice(f.Type(), len(values), len(values))
+			for j, value := range values {
+				if err := setScalar(list.Index(j), value, field.Tag); err != nil {
+					return err
+				}
+			}
+			f.Set(list)
+			continue
+		}
+		if err := setScalar(f, values[0], field.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setScalar sets v, which is usually a pointer, from the serialized form of a scalar
+func setScalar(v reflect.Value, s string, tag reflect.StructTag) error {
+	if v.Kind() != reflect.Ptr {
+		return parseScalar(v, s, tag)
+	}
+	p := reflect.New(v.Type().Elem())
+	if err := parseScalar(p.Elem(), s, tag); err != nil {
+		return err
+	}
+	v.Set(p)
+	return nil
+}
+
+// encodeRESTResponse serializes the output of an operation of Route53
+func encodeRESTResponse(operation string, output reflect.Value) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	e := xml.NewEncoder(&b)
+
+	root := xml.StartElement{
+		Name: xml.Name{Local: operation + "Response"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: route53Namespace}},
+	}
+	if err := e.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if err := encodeFields(e, output); err != nil {
+		return nil, err
+	}
+	if err := e.EncodeToken(root.End()); err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// route53API adds the operations used by the SDK's paginators to the mock
+type route53API struct {
+	*mockroute53.MockRoute53
+}
+
+func (m *route53API) ListHostedZones(input *route53.ListHostedZonesInput) (*route53.ListHostedZonesOutput, error) {
+	output := &route53.ListHostedZonesOutput{}
+	err := m.ListHostedZonesPages(input, func(page *route53.ListHostedZonesOutput, lastPage bool) bool {
+		output.HostedZones = append(output.HostedZones, page.HostedZones...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	output.IsTruncated = aws.Bool(false)
+	output.MaxItems = aws.String(fmt.Sprintf("%d", len(output.HostedZones)))
+	return output, nil
+}
+
+func (m *route53API) ListResourceRecordSets(input *route53.ListResourceRecordSetsInput) (*route53.ListResourceRecordSetsOutput, error) {
+	if input.StartRecordIdentifier != nil || input.StartRecordName != nil || input.StartRecordType != nil {
+		return nil, awserr.New("InvalidInput", "listing from a start record is not supported by the fake cloud", nil)
+	}
+	// The mock lists all the records in one page
+	input.MaxItems = nil
+
+	output := &route53.ListResourceRecordSetsOutput{}
+	err := m.ListResourceRecordSetsPages(input, func(page *route53.ListResourceRecordSetsOutput, lastPage bool) bool {
+		output.ResourceRecordSets = append(output.ResourceRecordSets, page.ResourceRecordSets...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	output.IsTruncated = aws.Bool(false)
+	output.MaxItems = aws.String(fmt.Sprintf("%d", len(output.ResourceRecordSets)))
+	return output, nil
+}
@@ -0,0 +1,400 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/cloudmock/aws/mockelb"
+	"k8s.io/kops/cloudmock/aws/mockelbv2"
+	"k8s.io/kops/cloudmock/aws/mockiam"
+	"k8s.io/kops/cloudmock/aws/mockroute53"
+)
+
+const (
+	// elbVersion and elbv2Version are the API versions of the classic and v2 load balancer APIs,
+	// which share their signing name
+	elbVersion   = "2012-06-01"
+	elbv2Version = "2015-12-01"
+)
+
+// Options configures a Server
+type Options struct {
+	// Region is the only region of the fake cloud
+	Region string
+	// Zones are the availability zones of the region
+	Zones []string
+	// AccountID is the account of the caller, as returned by STS
+	AccountID string
+	// HostedZones are the names of public hosted zones to create in Route53
+	HostedZones []string
+
+	// Nodes is the Kubernetes API in which nodes are registered for the running instances, if set
+	Nodes kubernetes.Interface
+	// BootDelay is how long after its launch an instance registers its node
+	BootDelay time.Duration
+}
+
+// Server serves the AWS mocks of cloudmock over the HTTP APIs of AWS, so that the kops binary
+// can run against them. Autoscaling groups launch instances in the mock EC2, and the instances
+// can register fake nodes in a Kubernetes API server.
+type Server struct {
+	options Options
+
+	// mutex serializes the requests and the reconciliation of the instances.
+	// The mocks return their internal objects, which must not change while they are serialized.
+	mutex sync.Mutex
+
+	EC2         *mockec2.MockEC2
+	Autoscaling *mockautoscaling.MockAutoscaling
+	ELB         *mockelb.MockELB
+	ELBV2       *mockelbv2.MockELBV2
+	IAM         *mockiam.MockIAM
+	Route53     *mockroute53.MockRoute53
+
+	// queryAPIs holds the implementations of the APIs using the query protocols, by signing name
+	queryAPIs map[string]interface{}
+	// route53Operations routes the requests of the REST API of Route53
+	route53Operations []*restOperation
+	route53API        interface{}
+}
+
+// NewServer builds a Server with empty mocks
+func NewServer(options Options) (*Server, error) {
+	if options.Region == "" {
+		return nil, fmt.Errorf("region is required")
+	}
+	if len(options.Zones) == 0 {
+		for _, suffix := range []string{"a", "b", "c"} {
+			options.Zones = append(options.Zones, options.Region+suffix)
+		}
+	}
+	for _, zone := range options.Zones {
+		if !strings.HasPrefix(zone, options.Region) {
+			return nil, fmt.Errorf("zone %q is not in region %q", zone, options.Region)
+		}
+	}
+	if options.AccountID == "" {
+		options.AccountID = "123456789012"
+	}
+
+	route53Operations, err := buildRoute53Operations()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		options:           options,
+		EC2:               &mockec2.MockEC2{},
+		Autoscaling:       &mockautoscaling.MockAutoscaling{},
+		ELB:               &mockelb.MockELB{},
+		ELBV2:             &mockelbv2.MockELBV2{},
+		IAM:               &mockiam.MockIAM{},
+		Route53:           &mockroute53.MockRoute53{},
+		route53Operations: route53Operations,
+	}
+	s.queryAPIs = map[string]interface{}{
+		"ec2":         &ec2API{MockEC2: s.EC2, server: s},
+		"autoscaling": &autoscalingAPI{MockAutoscaling: s.Autoscaling, server: s},
+		"iam":         s.IAM,
+		"sts":         &stsAPI{accountID: options.AccountID},
+	}
+	s.route53API = &route53API{MockRoute53: s.Route53}
+
+	for i, name := range options.HostedZones {
+		name = strings.TrimSuffix(name, ".") + "."
+		s.Route53.MockCreateZone(&route53.HostedZone{
+			Id:   aws.String(fmt.Sprintf("/hostedzone/ZFAKE%d", i+1)),
+			Name: aws.String(name),
+			Config: &route53.HostedZoneConfig{
+				PrivateZone: aws.Bool(false),
+			},
+		}, nil)
+	}
+
+	return s, nil
+}
+
+// Run reconciles the instances periodically, until the context is done
+func (s *Server) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Reconcile(ctx); err != nil {
+			klog.Warningf("error reconciling the fake cloud: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile launches and terminates the instances of the autoscaling groups, then registers
+// and deletes the nodes of the instances
+func (s *Server) Reconcile(ctx context.Context) error {
+	s.mutex.Lock()
+	err := s.reconcileInstances()
+	var nodes []*v1.Node
+	if err == nil && s.options.Nodes != nil {
+		nodes = s.buildNodes(time.Now())
+	}
+	s.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if s.options.Nodes != nil {
+		return s.syncNodes(ctx, nodes)
+	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	service := signingName(r)
+	klog.V(2).Infof("fake cloud: %s %s (%s)", r.Method, r.URL, service)
+
+	if service == "route53" {
+		s.serveREST(w, r)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, false, awserr.New("MalformedQueryString", err.Error(), nil))
+		return
+	}
+
+	isEC2 := service == "ec2"
+	var api interface{}
+	switch service {
+	case "elasticloadbalancing":
+		switch r.Form.Get("Version") {
+		case elbVersion:
+			api = s.ELB
+		case elbv2Version:
+			api = s.ELBV2
+		}
+	default:
+		api = s.queryAPIs[service]
+	}
+	if api == nil {
+		writeError(w, isEC2, awserr.New("UnknownService", fmt.Sprintf("service %q is not supported by the fake cloud", service), nil))
+		return
+	}
+
+	action := r.Form.Get("Action")
+	decoder := &queryDecoder{values: r.Form, isEC2: isEC2}
+	output, err := s.call(api, action, func(input reflect.Value) error {
+		return decoder.decodeStruct(input.Elem(), "")
+	}, func(output reflect.Value) ([]byte, error) {
+		return encodeQueryResponse(action, output, isEC2)
+	})
+	if err != nil {
+		writeError(w, isEC2, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(output); err != nil {
+		klog.Warningf("error writing response: %v", err)
+	}
+}
+
+// call decodes the input of operation, calls the method of api implementing it, and encodes its output.
+// The encoding happens while holding the mutex, as the output can reference the internal objects of the mocks.
+func (s *Server) call(api interface{}, operation string, decode func(input reflect.Value) error, encode func(output reflect.Value) ([]byte, error)) (b []byte, err error) {
+	method := reflect.ValueOf(api).MethodByName(operation)
+	if operation == "" || !method.IsValid() {
+		return nil, awserr.New("InvalidAction", fmt.Sprintf("operation %q is not supported by the fake cloud", operation), nil)
+	}
+	t := method.Type()
+	if t.NumIn() != 1 || t.In(0).Kind() != reflect.Ptr || t.NumOut() != 2 || t.Out(1) != reflect.TypeOf((*error)(nil)).Elem() {
+		return nil, awserr.New("InvalidAction", fmt.Sprintf("%q is not an operation", operation), nil)
+	}
+
+	input := reflect.New(t.In(0).Elem())
+	if err := decode(input); err != nil {
+		return nil, awserr.New("ValidationError", fmt.Sprintf("error decoding the input of %s: %v", operation, err), nil)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Most of the operations of the mocks are stubs which panic
+	defer func() {
+		if r := recover(); r != nil {
+			klog.Warningf("fake cloud: %s failed: %v", operation, r)
+			err = awserr.New("NotImplemented", fmt.Sprintf("%s is not implemented by the fake cloud: %v", operation, r), nil)
+		}
+	}()
+
+	klog.V(4).Infof("fake cloud: calling %s", operation)
+	results := method.Call([]reflect.Value{input})
+	if !results[1].IsNil() {
+		return nil, results[1].Interface().(error)
+	}
+
+	b, err = encode(results[0])
+	if err != nil {
+		return nil, fmt.Errorf("error encoding the output of %s: %v", operation, err)
+	}
+
+	// Let the autoscaling groups react to the change right away, instead of waiting for the next reconciliation
+	if err := s.reconcileInstances(); err != nil {
+		klog.Warningf("error reconciling the instances: %v", err)
+	}
+
+	return b, nil
+}
+
+// encodeQueryResponse serializes the output of an operation the way the query protocols do
+func encodeQueryResponse(action string, output reflect.Value, isEC2 bool) ([]byte, error) {
+	var b bytes.Buffer
+	e := xml.NewEncoder(&b)
+
+	root := xml.StartElement{Name: xml.Name{Local: action + "Response"}}
+	if err := e.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if isEC2 {
+		if err := e.EncodeElement(newRequestID(), xml.StartElement{Name: xml.Name{Local: "requestId"}}); err != nil {
+			return nil, err
+		}
+		if err := encodeFields(e, output); err != nil {
+			return nil, err
+		}
+	} else {
+		result := xml.StartElement{Name: xml.Name{Local: action + "Result"}}
+		if err := e.EncodeToken(result); err != nil {
+			return nil, err
+		}
+		if err := encodeFields(e, output); err != nil {
+			return nil, err
+		}
+		if err := e.EncodeToken(result.End()); err != nil {
+			return nil, err
+		}
+		if err := encodeResponseMetadata(e); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.EncodeToken(root.End()); err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func encodeResponseMetadata(e *xml.Encoder) error {
+	metadata := xml.StartElement{Name: xml.Name{Local: "ResponseMetadata"}}
+	if err := e.EncodeToken(metadata); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(newRequestID(), xml.StartElement{Name: xml.Name{Local: "RequestId"}}); err != nil {
+		return err
+	}
+	return e.EncodeToken(metadata.End())
+}
+
+// ec2Error and queryError are the error responses of the EC2 and of the other XML protocols
+type ec2Error struct {
+	XMLName   xml.Name `xml:"Response"`
+	Code      string   `xml:"Errors>Error>Code"`
+	Message   string   `xml:"Errors>Error>Message"`
+	RequestID string   `xml:"RequestID"`
+}
+
+type queryError struct {
+	XMLName   xml.Name `xml:"ErrorResponse"`
+	Type      string   `xml:"Error>Type"`
+	Code      string   `xml:"Error>Code"`
+	Message   string   `xml:"Error>Message"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// writeError writes err as an error response, keeping the code of AWS errors
+func writeError(w http.ResponseWriter, isEC2 bool, err error) {
+	code := "ValidationError"
+	message := err.Error()
+	if awsErr, ok := err.(awserr.Error); ok {
+		code = awsErr.Code()
+		message = awsErr.Message()
+	}
+	klog.V(2).Infof("fake cloud: returning error %s: %s", code, message)
+
+	var body interface{}
+	if isEC2 {
+		body = &ec2Error{Code: code, Message: message, RequestID: newRequestID()}
+	} else {
+		body = &queryError{Type: "Sender", Code: code, Message: message, RequestID: newRequestID()}
+	}
+	b, err := xml.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusBadRequest)
+	if _, err := w.Write(b); err != nil {
+		klog.Warningf("error writing response: %v", err)
+	}
+}
+
+// signingName returns the service for which the request was signed, from its Authorization header:
+// AWS4-HMAC-SHA256 Credential=<key>/<date>/<region>/<service>/aws4_request, SignedHeaders=..., Signature=...
+func signingName(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	i := strings.Index(auth, "Credential=")
+	if i < 0 {
+		return ""
+	}
+	credential := auth[i+len("Credential="):]
+	if j := strings.IndexAny(credential, ", "); j >= 0 {
+		credential = credential[:j]
+	}
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 {
+		return ""
+	}
+	return parts[3]
+}
+
+func newRequestID() string {
+	return string(uuid.NewUUID())
+}
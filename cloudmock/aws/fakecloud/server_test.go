@@ -0,0 +1,376 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"context"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/sts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestServer starts a fake cloud, and returns a session of the SDK sending the requests to it
+func newTestServer(t *testing.T, options Options) (*Server, *session.Session) {
+	options.Region = "us-test-1"
+	s, err := NewServer(options)
+	if err != nil {
+		t.Fatalf("error building server: %v", err)
+	}
+	httpServer := httptest.NewServer(s)
+	t.Cleanup(httpServer.Close)
+
+	config := aws.NewConfig().
+		WithRegion("us-test-1").
+		WithEndpoint(httpServer.URL).
+		WithCredentials(credentials.NewStaticCredentials("AKIDFAKE", "secret", "")).
+		WithMaxRetries(0)
+	sess, err := session.NewSession(config)
+	if err != nil {
+		t.Fatalf("error building session: %v", err)
+	}
+	return s, sess
+}
+
+func TestDescribeRegion(t *testing.T) {
+	_, sess := newTestServer(t, Options{})
+
+	zones, err := ec2.New(sess).DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{})
+	if err != nil {
+		t.Fatalf("error describing zones: %v", err)
+	}
+	var names []string
+	for _, zone := range zones.AvailabilityZones {
+		names = append(names, aws.StringValue(zone.ZoneName))
+	}
+	if len(names) != 3 || names[0] != "us-test-1a" {
+		t.Errorf("unexpected zones %v", names)
+	}
+
+	identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		t.Fatalf("error getting caller identity: %v", err)
+	}
+	if aws.StringValue(identity.Account) != "123456789012" {
+		t.Errorf("unexpected account %q", aws.StringValue(identity.Account))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, sess := newTestServer(t, Options{})
+
+	_, err := ec2.New(sess).DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice([]string{"i-missing"})})
+	if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != "InvalidInstanceID.NotFound" {
+		t.Errorf("unexpected error for missing instance: %v", err)
+	}
+
+	_, err = autoscaling.New(sess).DescribeScalingActivities(&autoscaling.DescribeScalingActivitiesInput{})
+	if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != "NotImplemented" {
+		t.Errorf("unexpected error for unimplemented operation: %v", err)
+	}
+}
+
+// createGroup creates an autoscaling group with a launch template in two subnets
+func createGroup(t *testing.T, sess *session.Session, name string, role string, desired int64) {
+	ec2Client := ec2.New(sess)
+
+	vpc, err := ec2Client.CreateVpc(&ec2.CreateVpcInput{CidrBlock: aws.String("172.20.0.0/16")})
+	if err != nil {
+		t.Fatalf("error creating VPC: %v", err)
+	}
+	var subnetIDs []string
+	for i, zone := range []string{"us-test-1a", "us-test-1b"} {
+		subnet, err := ec2Client.CreateSubnet(&ec2.CreateSubnetInput{
+			VpcId:            vpc.Vpc.VpcId,
+			AvailabilityZone: aws.String(zone),
+			CidrBlock:        aws.String([]string{"172.20.32.0/19", "172.20.64.0/19"}[i]),
+		})
+		if err != nil {
+			t.Fatalf("error creating subnet: %v", err)
+		}
+		subnetIDs = append(subnetIDs, aws.StringValue(subnet.Subnet.SubnetId))
+	}
+
+	template, err := ec2Client.CreateLaunchTemplate(&ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: aws.String(name),
+		LaunchTemplateData: &ec2.RequestLaunchTemplateData{
+			ImageId:      aws.String("ami-12345678"),
+			InstanceType: aws.String("t3.medium"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("error creating launch template: %v", err)
+	}
+
+	_, err = autoscaling.New(sess).CreateAutoScalingGroup(&autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(name),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateId: template.LaunchTemplate.LaunchTemplateId,
+			Version:          aws.String("$Latest"),
+		},
+		MinSize:           aws.Int64(0),
+		MaxSize:           aws.Int64(10),
+		DesiredCapacity:   aws.Int64(desired),
+		VPCZoneIdentifier: aws.String(subnetIDs[0] + "," + subnetIDs[1]),
+		Tags: []*autoscaling.Tag{
+			{Key: aws.String("k8s.io/role/node"), Value: aws.String("1"), PropagateAtLaunch: aws.Bool(true)},
+			{Key: aws.String("k8s.io/cluster-autoscaler/node-template/label/kops.k8s.io/instancegroup"), Value: aws.String("nodes"), PropagateAtLaunch: aws.Bool(true)},
+			{Key: aws.String("k8s.io/cluster-autoscaler/node-template/label/kubernetes.io/role"), Value: aws.String(role), PropagateAtLaunch: aws.Bool(true)},
+			{Key: aws.String("k8s.io/cluster-autoscaler/node-template/taint/dedicated"), Value: aws.String("test:NoSchedule"), PropagateAtLaunch: aws.Bool(true)},
+			{Key: aws.String("not-propagated"), Value: aws.String("1"), PropagateAtLaunch: aws.Bool(false)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error creating autoscaling group: %v", err)
+	}
+}
+
+func groupInstances(t *testing.T, sess *session.Session, name string) []string {
+	groups, err := autoscaling.New(sess).DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: aws.StringSlice([]string{name}),
+	})
+	if err != nil {
+		t.Fatalf("error describing autoscaling groups: %v", err)
+	}
+	if len(groups.AutoScalingGroups) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(groups.AutoScalingGroups))
+	}
+	var ids []string
+	for _, instance := range groups.AutoScalingGroups[0].Instances {
+		if aws.StringValue(instance.LifecycleState) != autoscaling.LifecycleStateInService {
+			t.Errorf("unexpected lifecycle state %q", aws.StringValue(instance.LifecycleState))
+		}
+		if aws.StringValue(instance.LaunchTemplate.Version) != "1" {
+			t.Errorf("unexpected launch template version %q", aws.StringValue(instance.LaunchTemplate.Version))
+		}
+		ids = append(ids, aws.StringValue(instance.InstanceId))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestAutoscalingGroupInstances(t *testing.T) {
+	_, sess := newTestServer(t, Options{})
+	ec2Client := ec2.New(sess)
+
+	createGroup(t, sess, "nodes.example.com", "node", 2)
+
+	ids := groupInstances(t, sess, "nodes.example.com")
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 instances, got %v", ids)
+	}
+
+	instances, err := ec2Client.DescribeInstances(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("tag:aws:autoscaling:groupName"), Values: aws.StringSlice([]string{"nodes.example.com"})},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error describing instances: %v", err)
+	}
+	zones := make(map[string]bool)
+	for _, reservation := range instances.Reservations {
+		for _, instance := range reservation.Instances {
+			zones[aws.StringValue(instance.Placement.AvailabilityZone)] = true
+			if aws.StringValue(instance.InstanceType) != "t3.medium" {
+				t.Errorf("unexpected instance type %q", aws.StringValue(instance.InstanceType))
+			}
+			tags := make(map[string]string)
+			for _, tag := range instance.Tags {
+				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+			if tags["k8s.io/role/node"] != "1" {
+				t.Errorf("tag was not propagated: %v", tags)
+			}
+			if _, found := tags["not-propagated"]; found {
+				t.Errorf("tag was propagated: %v", tags)
+			}
+		}
+	}
+	if len(zones) != 2 {
+		t.Errorf("expected instances in 2 zones, got %v", zones)
+	}
+
+	// A terminated instance is replaced
+	if _, err := ec2Client.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: aws.StringSlice(ids[:1])}); err != nil {
+		t.Fatalf("error terminating instance: %v", err)
+	}
+	replaced := groupInstances(t, sess, "nodes.example.com")
+	if len(replaced) != 2 || replaced[0] == ids[0] || replaced[1] == ids[0] {
+		t.Errorf("instance %s was not replaced: %v", ids[0], replaced)
+	}
+
+	// A detached instance keeps running
+	_, err = autoscaling.New(sess).DetachInstances(&autoscaling.DetachInstancesInput{
+		AutoScalingGroupName:           aws.String("nodes.example.com"),
+		InstanceIds:                    aws.StringSlice(replaced[:1]),
+		ShouldDecrementDesiredCapacity: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatalf("error detaching instance: %v", err)
+	}
+	if _, err := ec2Client.DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice(replaced[:1])}); err != nil {
+		t.Errorf("detached instance was terminated: %v", err)
+	}
+	if remaining := groupInstances(t, sess, "nodes.example.com"); len(remaining) != 1 {
+		t.Errorf("expected 1 instance after detaching, got %v", remaining)
+	}
+
+	// Deleting the group terminates its instances
+	if _, err := autoscaling.New(sess).DeleteAutoScalingGroup(&autoscaling.DeleteAutoScalingGroupInput{AutoScalingGroupName: aws.String("nodes.example.com")}); err != nil {
+		t.Fatalf("error deleting group: %v", err)
+	}
+	remaining, err := ec2Client.DescribeInstances(&ec2.DescribeInstancesInput{})
+	if err != nil {
+		t.Fatalf("error describing instances: %v", err)
+	}
+	var remainingIDs []string
+	for _, reservation := range remaining.Reservations {
+		for _, instance := range reservation.Instances {
+			remainingIDs = append(remainingIDs, aws.StringValue(instance.InstanceId))
+		}
+	}
+	if len(remainingIDs) != 1 || remainingIDs[0] != replaced[0] {
+		t.Errorf("expected only the detached instance %s to remain, got %v", replaced[0], remainingIDs)
+	}
+}
+
+func TestNodes(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	s, sess := newTestServer(t, Options{Nodes: client})
+	ctx := context.TODO()
+
+	createGroup(t, sess, "master-us-test-1a.masters.example.com", "master", 1)
+	if err := s.Reconcile(ctx); err != nil {
+		t.Fatalf("error reconciling: %v", err)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing nodes: %v", err)
+	}
+	if len(nodes.Items) != 1 {
+		t.Fatalf("expected 1 node, got %d", len(nodes.Items))
+	}
+	node := nodes.Items[0]
+	ids := groupInstances(t, sess, "master-us-test-1a.masters.example.com")
+	if expected := "aws:///us-test-1a/" + ids[0]; node.Spec.ProviderID != expected {
+		t.Errorf("expected provider ID %q, got %q", expected, node.Spec.ProviderID)
+	}
+	if node.Labels["kops.k8s.io/instancegroup"] != "nodes" {
+		t.Errorf("unexpected labels %v", node.Labels)
+	}
+	if len(node.Spec.Taints) != 1 || node.Spec.Taints[0].Key != "dedicated" || node.Spec.Taints[0].Value != "test" || node.Spec.Taints[0].Effect != "NoSchedule" {
+		t.Errorf("unexpected taints %v", node.Spec.Taints)
+	}
+	if len(node.Status.Conditions) != 1 || node.Status.Conditions[0].Status != "True" {
+		t.Errorf("node is not ready: %v", node.Status.Conditions)
+	}
+
+	pods, err := client.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing pods: %v", err)
+	}
+	if len(pods.Items) != 3 {
+		t.Errorf("expected 3 control plane pods, got %d", len(pods.Items))
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != "Running" || pod.Status.HostIP != node.Status.Addresses[0].Address {
+			t.Errorf("unexpected status of pod %q: %v", pod.Name, pod.Status)
+		}
+	}
+
+	_, err = autoscaling.New(sess).UpdateAutoScalingGroup(&autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String("master-us-test-1a.masters.example.com"),
+		DesiredCapacity:      aws.Int64(0),
+	})
+	if err != nil {
+		t.Fatalf("error updating group: %v", err)
+	}
+	if err := s.Reconcile(ctx); err != nil {
+		t.Fatalf("error reconciling: %v", err)
+	}
+	nodes, err = client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing nodes: %v", err)
+	}
+	if len(nodes.Items) != 0 {
+		t.Errorf("expected the node to be deleted, got %d nodes", len(nodes.Items))
+	}
+	pods, err = client.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing pods: %v", err)
+	}
+	if len(pods.Items) != 0 {
+		t.Errorf("expected the pods to be deleted, got %d pods", len(pods.Items))
+	}
+}
+
+func TestRoute53(t *testing.T) {
+	_, sess := newTestServer(t, Options{HostedZones: []string{"example.com"}})
+	client := route53.New(sess)
+
+	var zones []*route53.HostedZone
+	err := client.ListHostedZonesPages(&route53.ListHostedZonesInput{}, func(page *route53.ListHostedZonesOutput, lastPage bool) bool {
+		zones = append(zones, page.HostedZones...)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("error listing hosted zones: %v", err)
+	}
+	if len(zones) != 1 || aws.StringValue(zones[0].Name) != "example.com." {
+		t.Fatalf("unexpected zones %v", zones)
+	}
+
+	_, err = client.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: zones[0].Id,
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{
+				{
+					Action: aws.String(route53.ChangeActionCreate),
+					ResourceRecordSet: &route53.ResourceRecordSet{
+						Name:            aws.String("api.example.com."),
+						Type:            aws.String(route53.RRTypeA),
+						TTL:             aws.Int64(60),
+						ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("203.0.113.1")}},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error changing records: %v", err)
+	}
+
+	records, err := client.ListResourceRecordSets(&route53.ListResourceRecordSetsInput{HostedZoneId: zones[0].Id})
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	if len(records.ResourceRecordSets) != 1 || aws.StringValue(records.ResourceRecordSets[0].ResourceRecords[0].Value) != "203.0.113.1" {
+		t.Errorf("unexpected records %v", records.ResourceRecordSets)
+	}
+}
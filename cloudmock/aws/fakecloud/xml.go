@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakecloud
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/private/protocol"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// encodeFields writes the fields of an SDK shape as XML elements, named the way the SDK unmarshals them
+func encodeFields(e *xml.Encoder, v reflect.Value) error {
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("ignore") != "" {
+			continue
+		}
+		// Headers, URIs and query strings are not part of the body
+		if field.Tag.Get("location") != "" {
+			continue
+		}
+
+		name := field.Name
+		if field.Tag.Get("flattened") != "" && field.Tag.Get("locationNameList") != "" {
+			name = field.Tag.Get("locationNameList")
+		} else if locationName := field.Tag.Get("locationName"); locationName != "" {
+			name = locationName
+		}
+
+		if err := encodeValue(e, name, v.Field(i), field.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeValue writes a value of an SDK shape as the XML element name
+func encodeValue(e *xml.Encoder, name string, v reflect.Value, tag reflect.StructTag) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch {
+	case v.Kind() == reflect.Struct && v.Type() != timeType:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := encodeFields(e, v); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		if v.IsNil() {
+			return nil
+		}
+		if tag.Get("flattened") != "" {
+			for i := 0; i < v.Len(); i++ {
+				if err := encodeValue(e, name, v.Index(i), ""); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		memberName := tag.Get("locationNameList")
+		if memberName == "" {
+			memberName = "member"
+		}
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeValue(e, memberName, v.Index(i), ""); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+
+	case v.Kind() == reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		keyName, valueName := "key", "value"
+		if n := tag.Get("locationNameKey"); n != "" {
+			keyName = n
+		}
+		if n := tag.Get("locationNameValue"); n != "" {
+			valueName = n
+		}
+
+		var keys []string
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			entry := xml.StartElement{Name: xml.Name{Local: "entry"}}
+			if tag.Get("flattened") == "" {
+				if err := e.EncodeToken(entry); err != nil {
+					return err
+				}
+			}
+			if err := e.EncodeElement(k, xml.StartElement{Name: xml.Name{Local: keyName}}); err != nil {
+				return err
+			}
+			if err := encodeValue(e, valueName, v.MapIndex(reflect.ValueOf(k)), ""); err != nil {
+				return err
+			}
+			if tag.Get("flattened") == "" {
+				if err := e.EncodeToken(entry.End()); err != nil {
+					return err
+				}
+			}
+		}
+		return e.EncodeToken(start.End())
+
+	default:
+		s, err := formatScalar(v, tag)
+		if err != nil {
+			return fmt.Errorf("error encoding %s: %v", name, err)
+		}
+		return e.EncodeElement(s, start)
+	}
+}
+
+// formatScalar formats a scalar the way the AWS protocols serialize it
+func formatScalar(v reflect.Value, tag reflect.StructTag) (string, error) {
+	switch value := v.Interface().(type) {
+	case string:
+		return value, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(value), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case time.Time:
+		format := tag.Get("timestampFormat")
+		if format == "" {
+			format = protocol.ISO8601TimeFormatName
+		}
+		return protocol.FormatTime(format, value), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+}
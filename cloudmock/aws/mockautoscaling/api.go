@@ -31,6 +31,8 @@ type MockAutoscaling struct {
 	Groups            map[string]*autoscaling.Group
 	WarmPoolInstances map[string][]*autoscaling.Instance
 	LifecycleHooks    map[string]*autoscaling.LifecycleHook
+	// ScheduledActions are keyed by the name of their group and their own name, separated by a slash
+	ScheduledActions map[string]*autoscaling.ScheduledUpdateGroupAction
 }
 
 var _ autoscalingiface.AutoScalingAPI = &MockAutoscaling{}
@@ -44,6 +44,37 @@ func (m *MockAutoscaling) AttachInstances(input *autoscaling.AttachInstancesInpu
 	return &autoscaling.AttachInstancesOutput{}, nil
 }
 
+func (m *MockAutoscaling) DetachInstances(input *autoscaling.DetachInstancesInput) (*autoscaling.DetachInstancesOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(2).Infof("Mock DetachInstances %v", input)
+
+	g := m.Groups[aws.StringValue(input.AutoScalingGroupName)]
+	if g == nil {
+		return nil, fmt.Errorf("AutoScaling Group not found")
+	}
+
+	for _, instanceID := range input.InstanceIds {
+		found := false
+		for i := range g.Instances {
+			if aws.StringValue(g.Instances[i].InstanceId) == aws.StringValue(instanceID) {
+				g.Instances = append(g.Instances[:i], g.Instances[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Instance %q is not part of the AutoScaling Group", aws.StringValue(instanceID))
+		}
+		if aws.BoolValue(input.ShouldDecrementDesiredCapacity) {
+			g.DesiredCapacity = aws.Int64(aws.Int64Value(g.DesiredCapacity) - 1)
+		}
+	}
+
+	return &autoscaling.DetachInstancesOutput{}, nil
+}
+
 func (m *MockAutoscaling) CreateAutoScalingGroup(input *autoscaling.CreateAutoScalingGroupInput) (*autoscaling.CreateAutoScalingGroupOutput, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -324,6 +355,11 @@ func (m *MockAutoscaling) DeleteAutoScalingGroup(request *autoscaling.DeleteAuto
 		return nil, fmt.Errorf("AutoScalingGroup %q not found", id)
 	}
 	delete(m.Groups, id)
+	for key, action := range m.ScheduledActions {
+		if aws.StringValue(action.AutoScalingGroupName) == id {
+			delete(m.ScheduledActions, key)
+		}
+	}
 
 	return &autoscaling.DeleteAutoScalingGroupOutput{}, nil
 }
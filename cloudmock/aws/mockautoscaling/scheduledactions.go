@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockautoscaling
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"k8s.io/klog/v2"
+)
+
+func (m *MockAutoscaling) PutScheduledUpdateGroupAction(input *autoscaling.PutScheduledUpdateGroupActionInput) (*autoscaling.PutScheduledUpdateGroupActionOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(2).Infof("Mock PutScheduledUpdateGroupAction: %v", input)
+
+	name := aws.StringValue(input.AutoScalingGroupName)
+	if m.Groups[name] == nil {
+		return nil, fmt.Errorf("AutoScalingGroup %q not found", name)
+	}
+
+	action := &autoscaling.ScheduledUpdateGroupAction{
+		AutoScalingGroupName: input.AutoScalingGroupName,
+		DesiredCapacity:      input.DesiredCapacity,
+		EndTime:              input.EndTime,
+		MaxSize:              input.MaxSize,
+		MinSize:              input.MinSize,
+		Recurrence:           input.Recurrence,
+		ScheduledActionARN:   aws.String(fmt.Sprintf("arn:aws-test:autoscaling:us-test-1:123456789012:scheduledUpdateGroupAction:%s:autoScalingGroupName/%s:scheduledActionName/%s", name, name, aws.StringValue(input.ScheduledActionName))),
+		ScheduledActionName:  input.ScheduledActionName,
+		StartTime:            input.StartTime,
+		Time:                 input.Time,
+		TimeZone:             input.TimeZone,
+	}
+
+	if m.ScheduledActions == nil {
+		m.ScheduledActions = make(map[string]*autoscaling.ScheduledUpdateGroupAction)
+	}
+	m.ScheduledActions[name+"/"+aws.StringValue(input.ScheduledActionName)] = action
+
+	return &autoscaling.PutScheduledUpdateGroupActionOutput{}, nil
+}
+
+func (m *MockAutoscaling) DescribeScheduledActions(input *autoscaling.DescribeScheduledActionsInput) (*autoscaling.DescribeScheduledActionsOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(2).Infof("Mock DescribeScheduledActions: %v", input)
+
+	names := make(map[string]bool)
+	for _, name := range input.ScheduledActionNames {
+		names[aws.StringValue(name)] = true
+	}
+
+	response := &autoscaling.DescribeScheduledActionsOutput{}
+	for _, action := range m.ScheduledActions {
+		if input.AutoScalingGroupName != nil && aws.StringValue(input.AutoScalingGroupName) != aws.StringValue(action.AutoScalingGroupName) {
+			continue
+		}
+		if len(names) != 0 && !names[aws.StringValue(action.ScheduledActionName)] {
+			continue
+		}
+		copy := *action
+		response.ScheduledUpdateGroupActions = append(response.ScheduledUpdateGroupActions, &copy)
+	}
+	sort.Slice(response.ScheduledUpdateGroupActions, func(i, j int) bool {
+		return aws.StringValue(response.ScheduledUpdateGroupActions[i].ScheduledActionARN) < aws.StringValue(response.ScheduledUpdateGroupActions[j].ScheduledActionARN)
+	})
+
+	return response, nil
+}
+
+func (m *MockAutoscaling) DescribeScheduledActionsWithContext(aws.Context, *autoscaling.DescribeScheduledActionsInput, ...request.Option) (*autoscaling.DescribeScheduledActionsOutput, error) {
+	klog.Fatalf("Not implemented")
+	return nil, nil
+}
+
+func (m *MockAutoscaling) DescribeScheduledActionsRequest(*autoscaling.DescribeScheduledActionsInput) (*request.Request, *autoscaling.DescribeScheduledActionsOutput) {
+	klog.Fatalf("Not implemented")
+	return nil, nil
+}
+
+func (m *MockAutoscaling) DescribeScheduledActionsPages(input *autoscaling.DescribeScheduledActionsInput, callback func(*autoscaling.DescribeScheduledActionsOutput, bool) bool) error {
+	if input.NextToken != nil {
+		klog.Fatalf("DescribeScheduledActionsPages NextToken not implemented")
+	}
+
+	page, err := m.DescribeScheduledActions(input)
+	if err != nil {
+		return err
+	}
+	callback(page, true)
+
+	return nil
+}
+
+func (m *MockAutoscaling) DescribeScheduledActionsPagesWithContext(aws.Context, *autoscaling.DescribeScheduledActionsInput, func(*autoscaling.DescribeScheduledActionsOutput, bool) bool, ...request.Option) error {
+	klog.Fatalf("Not implemented")
+	return nil
+}
+
+func (m *MockAutoscaling) DeleteScheduledAction(input *autoscaling.DeleteScheduledActionInput) (*autoscaling.DeleteScheduledActionOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(2).Infof("Mock DeleteScheduledAction: %v", input)
+
+	key := aws.StringValue(input.AutoScalingGroupName) + "/" + aws.StringValue(input.ScheduledActionName)
+	if m.ScheduledActions[key] == nil {
+		return nil, fmt.Errorf("ScheduledAction %q not found", key)
+	}
+	delete(m.ScheduledActions, key)
+
+	return &autoscaling.DeleteScheduledActionOutput{}, nil
+}
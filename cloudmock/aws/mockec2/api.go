@@ -40,6 +40,8 @@ type MockEC2 struct {
 
 	Images []*ec2.Image
 
+	Instances map[string]*ec2.Instance
+
 	securityGroupNumber int
 	SecurityGroups      map[string]*ec2.SecurityGroup
 
@@ -55,11 +57,15 @@ type MockEC2 struct {
 
 	InternetGateways map[string]*ec2.InternetGateway
 
+	EgressOnlyInternetGateways map[string]*ec2.EgressOnlyInternetGateway
+
 	launchTemplateNumber int
 	LaunchTemplates      map[string]*launchTemplateInfo
 
 	NatGateways map[string]*ec2.NatGateway
 
+	PlacementGroups map[string]*ec2.PlacementGroup
+
 	idsMutex sync.Mutex
 	ids      map[string]*idAllocator
 }
@@ -81,6 +87,9 @@ func (m *MockEC2) All() map[string]interface{} {
 	for _, o := range m.Images {
 		all[aws.StringValue(o.ImageId)] = o
 	}
+	for id, o := range m.Instances {
+		all[id] = o
+	}
 	for id, o := range m.SecurityGroups {
 		all[id] = o
 	}
@@ -99,12 +108,18 @@ func (m *MockEC2) All() map[string]interface{} {
 	for id, o := range m.InternetGateways {
 		all[id] = o
 	}
+	for id, o := range m.EgressOnlyInternetGateways {
+		all[id] = o
+	}
 	for id, o := range m.LaunchTemplates {
 		all[id] = o
 	}
 	for id, o := range m.NatGateways {
 		all[id] = o
 	}
+	for id, o := range m.PlacementGroups {
+		all[id] = o
+	}
 
 	return all
 }
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockec2
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/klog/v2"
+)
+
+func (m *MockEC2) CreateEgressOnlyInternetGatewayRequest(*ec2.CreateEgressOnlyInternetGatewayInput) (*request.Request, *ec2.CreateEgressOnlyInternetGatewayOutput) {
+	panic("Not implemented")
+}
+
+func (m *MockEC2) CreateEgressOnlyInternetGatewayWithContext(aws.Context, *ec2.CreateEgressOnlyInternetGatewayInput, ...request.Option) (*ec2.CreateEgressOnlyInternetGatewayOutput, error) {
+	panic("Not implemented")
+}
+
+func (m *MockEC2) CreateEgressOnlyInternetGateway(request *ec2.CreateEgressOnlyInternetGatewayInput) (*ec2.CreateEgressOnlyInternetGatewayOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("CreateEgressOnlyInternetGateway: %v", request)
+
+	if m.Vpcs[aws.StringValue(request.VpcId)] == nil {
+		return nil, fmt.Errorf("VPC %q not found", aws.StringValue(request.VpcId))
+	}
+
+	id := m.allocateId("eigw")
+	tags := tagSpecificationsToTags(request.TagSpecifications, ec2.ResourceTypeEgressOnlyInternetGateway)
+
+	eigw := &ec2.EgressOnlyInternetGateway{
+		EgressOnlyInternetGatewayId: s(id),
+		Attachments: []*ec2.InternetGatewayAttachment{
+			{
+				State: s(ec2.AttachmentStatusAttached),
+				VpcId: request.VpcId,
+			},
+		},
+		Tags: tags,
+	}
+
+	if m.EgressOnlyInternetGateways == nil {
+		m.EgressOnlyInternetGateways = make(map[string]*ec2.EgressOnlyInternetGateway)
+	}
+	m.EgressOnlyInternetGateways[id] = eigw
+
+	m.addTags(id, tags...)
+
+	response := &ec2.CreateEgressOnlyInternetGatewayOutput{
+		EgressOnlyInternetGateway: eigw,
+	}
+	return response, nil
+}
+
+func (m *MockEC2) DescribeEgressOnlyInternetGatewaysRequest(*ec2.DescribeEgressOnlyInternetGatewaysInput) (*request.Request, *ec2.DescribeEgressOnlyInternetGatewaysOutput) {
+	panic("Not implemented")
+}
+
+func (m *MockEC2) DescribeEgressOnlyInternetGatewaysWithContext(aws.Context, *ec2.DescribeEgressOnlyInternetGatewaysInput, ...request.Option) (*ec2.DescribeEgressOnlyInternetGatewaysOutput, error) {
+	panic("Not implemented")
+}
+
+func (m *MockEC2) DescribeEgressOnlyInternetGatewaysPagesWithContext(aws.Context, *ec2.DescribeEgressOnlyInternetGatewaysInput, func(*ec2.DescribeEgressOnlyInternetGatewaysOutput, bool) bool, ...request.Option) error {
+	panic("Not implemented")
+}
+
+func (m *MockEC2) DescribeEgressOnlyInternetGatewaysPages(request *ec2.DescribeEgressOnlyInternetGatewaysInput, callback func(*ec2.DescribeEgressOnlyInternetGatewaysOutput, bool) bool) error {
+	// For the mock, we just send everything in one page
+	page, err := m.DescribeEgressOnlyInternetGateways(request)
+	if err != nil {
+		return err
+	}
+
+	callback(page, false)
+
+	return nil
+}
+
+func (m *MockEC2) DescribeEgressOnlyInternetGateways(request *ec2.DescribeEgressOnlyInternetGatewaysInput) (*ec2.DescribeEgressOnlyInternetGatewaysOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DescribeEgressOnlyInternetGateways: %v", request)
+
+	var gateways []*ec2.EgressOnlyInternetGateway
+
+	for id, eigw := range m.EgressOnlyInternetGateways {
+		if len(request.EgressOnlyInternetGatewayIds) != 0 {
+			match := false
+			for _, v := range request.EgressOnlyInternetGatewayIds {
+				if id == aws.StringValue(v) {
+					match = true
+				}
+			}
+			if !match {
+				continue
+			}
+		}
+
+		allFiltersMatch := true
+		for _, filter := range request.Filters {
+			match := false
+			if strings.HasPrefix(*filter.Name, "tag:") || *filter.Name == "tag-key" {
+				match = m.hasTag(ec2.ResourceTypeEgressOnlyInternetGateway, id, filter)
+			} else {
+				return nil, fmt.Errorf("unknown filter name: %q", *filter.Name)
+			}
+
+			if !match {
+				allFiltersMatch = false
+				break
+			}
+		}
+
+		if !allFiltersMatch {
+			continue
+		}
+
+		copy := *eigw
+		copy.Tags = m.getTags(ec2.ResourceTypeEgressOnlyInternetGateway, id)
+		gateways = append(gateways, &copy)
+	}
+
+	response := &ec2.DescribeEgressOnlyInternetGatewaysOutput{
+		EgressOnlyInternetGateways: gateways,
+	}
+
+	return response, nil
+}
+
+func (m *MockEC2) DeleteEgressOnlyInternetGateway(request *ec2.DeleteEgressOnlyInternetGatewayInput) (*ec2.DeleteEgressOnlyInternetGatewayOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DeleteEgressOnlyInternetGateway: %v", request)
+
+	id := aws.StringValue(request.EgressOnlyInternetGatewayId)
+	o := m.EgressOnlyInternetGateways[id]
+	if o == nil {
+		return nil, fmt.Errorf("EgressOnlyInternetGateway %q not found", id)
+	}
+	delete(m.EgressOnlyInternetGateways, id)
+
+	return &ec2.DeleteEgressOnlyInternetGatewayOutput{ReturnCode: aws.Bool(true)}, nil
+}
+
+func (m *MockEC2) DeleteEgressOnlyInternetGatewayWithContext(aws.Context, *ec2.DeleteEgressOnlyInternetGatewayInput, ...request.Option) (*ec2.DeleteEgressOnlyInternetGatewayOutput, error) {
+	panic("Not implemented")
+}
+
+func (m *MockEC2) DeleteEgressOnlyInternetGatewayRequest(*ec2.DeleteEgressOnlyInternetGatewayInput) (*request.Request, *ec2.DeleteEgressOnlyInternetGatewayOutput) {
+	panic("Not implemented")
+}
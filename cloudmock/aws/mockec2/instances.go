@@ -17,15 +17,268 @@ limitations under the License.
 package mockec2
 
 import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"k8s.io/klog/v2"
 )
 
-func (m *MockEC2) DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
-	klog.Warningf("MockEc2::DescribeInstances is stub-implemented")
-	return &ec2.DescribeInstancesOutput{}, nil
+// RunInstances mocks launching instances. The settings missing from the request are taken from its launch template.
+func (m *MockEC2) RunInstances(request *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(2).Infof("Mock RunInstances: %v", request)
+
+	data := &ec2.ResponseLaunchTemplateData{}
+	var launchTemplateTags []*ec2.Tag
+	if request.LaunchTemplate != nil {
+		lt := m.findLaunchTemplate(aws.StringValue(request.LaunchTemplate.LaunchTemplateId), aws.StringValue(request.LaunchTemplate.LaunchTemplateName))
+		if lt == nil {
+			return nil, awserr.New("InvalidLaunchTemplateId.NotFound", "launch template not found", nil)
+		}
+		data = lt.data
+
+		// EC2 applies the tags of the launch template, and records the template of the instance in tags
+		for _, ts := range data.TagSpecifications {
+			if aws.StringValue(ts.ResourceType) == ec2.ResourceTypeInstance {
+				launchTemplateTags = append(launchTemplateTags, ts.Tags...)
+			}
+		}
+		for id, info := range m.LaunchTemplates {
+			if info == lt {
+				launchTemplateTags = append(launchTemplateTags,
+					&ec2.Tag{Key: s("aws:ec2launchtemplate:id"), Value: s(id)},
+					&ec2.Tag{Key: s("aws:ec2launchtemplate:version"), Value: s(strconv.Itoa(lt.version))},
+				)
+			}
+		}
+	}
+
+	subnet := m.subnets[aws.StringValue(request.SubnetId)]
+	if subnet == nil {
+		return nil, awserr.New("InvalidSubnetID.NotFound", fmt.Sprintf("subnet %q not found", aws.StringValue(request.SubnetId)), nil)
+	}
+
+	imageID := request.ImageId
+	if imageID == nil {
+		imageID = data.ImageId
+	}
+	instanceType := request.InstanceType
+	if instanceType == nil {
+		instanceType = data.InstanceType
+	}
+	keyName := request.KeyName
+	if keyName == nil {
+		keyName = data.KeyName
+	}
+	securityGroupIDs := request.SecurityGroupIds
+	if securityGroupIDs == nil {
+		securityGroupIDs = data.SecurityGroupIds
+	}
+
+	count := int(aws.Int64Value(request.MinCount))
+	if count == 0 {
+		count = 1
+	}
+
+	reservation := &ec2.Reservation{
+		ReservationId: s(m.allocateId("r")),
+	}
+	for i := 0; i < count; i++ {
+		id := m.allocateId("i")
+
+		privateIP, err := subnet.allocateAddress()
+		if err != nil {
+			return nil, err
+		}
+		zone := aws.StringValue(subnet.main.AvailabilityZone)
+		region := strings.TrimRight(zone, "abcdefghijklmnopqrstuvwxyz")
+
+		instance := &ec2.Instance{
+			InstanceId:       s(id),
+			ImageId:          imageID,
+			InstanceType:     instanceType,
+			KeyName:          keyName,
+			LaunchTime:       aws.Time(time.Now().UTC()),
+			Placement:        &ec2.Placement{AvailabilityZone: s(zone)},
+			PrivateDnsName:   s(fmt.Sprintf("ip-%s.%s.compute.internal", strings.ReplaceAll(privateIP, ".", "-"), region)),
+			PrivateIpAddress: s(privateIP),
+			State: &ec2.InstanceState{
+				Code: aws.Int64(16),
+				Name: s(ec2.InstanceStateNameRunning),
+			},
+			SubnetId: subnet.main.SubnetId,
+			VpcId:    subnet.main.VpcId,
+		}
+		for _, sg := range securityGroupIDs {
+			instance.SecurityGroups = append(instance.SecurityGroups, &ec2.GroupIdentifier{GroupId: sg})
+		}
+
+		if m.Instances == nil {
+			m.Instances = make(map[string]*ec2.Instance)
+		}
+		m.Instances[id] = instance
+		m.addTags(id, launchTemplateTags...)
+		m.addTags(id, tagSpecificationsToTags(request.TagSpecifications, ec2.ResourceTypeInstance)...)
+
+		copy := *instance
+		copy.Tags = m.getTags(ec2.ResourceTypeInstance, id)
+		reservation.Instances = append(reservation.Instances, &copy)
+	}
+
+	return reservation, nil
+}
+
+// allocateAddress returns the next free private address of the subnet
+func (s *subnetInfo) allocateAddress() (string, error) {
+	_, cidr, err := net.ParseCIDR(aws.StringValue(s.main.CidrBlock))
+	if err != nil {
+		return "", fmt.Errorf("error parsing CIDR of subnet %q: %v", aws.StringValue(s.main.SubnetId), err)
+	}
+	base := cidr.IP.To4()
+	if base == nil {
+		return "", fmt.Errorf("subnet %q has no IPv4 CIDR", aws.StringValue(s.main.SubnetId))
+	}
+
+	// The first four addresses of a subnet are reserved
+	s.addressNumber++
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(base)+uint32(3+s.addressNumber))
+	if !cidr.Contains(ip) {
+		return "", awserr.New("InsufficientFreeAddressesInSubnet", fmt.Sprintf("subnet %q has no free addresses", aws.StringValue(s.main.SubnetId)), nil)
+	}
+	return ip.String(), nil
+}
+
+func (m *MockEC2) DescribeInstances(request *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(2).Infof("Mock DescribeInstances: %v", request)
+
+	for _, id := range request.InstanceIds {
+		if m.Instances[aws.StringValue(id)] == nil {
+			return nil, awserr.New("InvalidInstanceID.NotFound", fmt.Sprintf("instance %q not found", aws.StringValue(id)), nil)
+		}
+	}
+
+	var ids []string
+	for id := range m.Instances {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	reservation := &ec2.Reservation{}
+	for _, id := range ids {
+		instance := m.Instances[id]
+
+		if len(request.InstanceIds) != 0 && !containsString(request.InstanceIds, id) {
+			continue
+		}
+
+		allFiltersMatch := true
+		for _, filter := range request.Filters {
+			match := false
+			switch aws.StringValue(filter.Name) {
+			case "instance-id":
+				match = containsString(filter.Values, id)
+			case "instance-state-name":
+				match = containsString(filter.Values, aws.StringValue(instance.State.Name))
+			case "private-dns-name":
+				match = containsString(filter.Values, aws.StringValue(instance.PrivateDnsName))
+			case "subnet-id":
+				match = containsString(filter.Values, aws.StringValue(instance.SubnetId))
+			case "vpc-id":
+				match = containsString(filter.Values, aws.StringValue(instance.VpcId))
+			default:
+				if strings.HasPrefix(aws.StringValue(filter.Name), "tag:") || aws.StringValue(filter.Name) == "tag-key" {
+					match = m.hasTag(ec2.ResourceTypeInstance, id, filter)
+				} else {
+					return nil, fmt.Errorf("unknown filter name: %q", aws.StringValue(filter.Name))
+				}
+			}
+
+			if !match {
+				allFiltersMatch = false
+				break
+			}
+		}
+
+		if !allFiltersMatch {
+			continue
+		}
+
+		copy := *instance
+		copy.Tags = m.getTags(ec2.ResourceTypeInstance, id)
+		reservation.Instances = append(reservation.Instances, &copy)
+	}
+
+	response := &ec2.DescribeInstancesOutput{}
+	if len(reservation.Instances) != 0 {
+		response.Reservations = []*ec2.Reservation{reservation}
+	}
+	return response, nil
+}
+
+// TerminateInstances mocks terminating instances. Terminated instances are removed right away.
+func (m *MockEC2) TerminateInstances(request *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(2).Infof("Mock TerminateInstances: %v", request)
+
+	for _, id := range request.InstanceIds {
+		if m.Instances[aws.StringValue(id)] == nil {
+			return nil, awserr.New("InvalidInstanceID.NotFound", fmt.Sprintf("instance %q not found", aws.StringValue(id)), nil)
+		}
+	}
+
+	response := &ec2.TerminateInstancesOutput{}
+	if aws.BoolValue(request.DryRun) {
+		return response, nil
+	}
+	for _, id := range request.InstanceIds {
+		instance := m.Instances[aws.StringValue(id)]
+		delete(m.Instances, aws.StringValue(id))
+
+		var tags []*ec2.TagDescription
+		for _, tag := range m.Tags {
+			if aws.StringValue(tag.ResourceId) != aws.StringValue(id) {
+				tags = append(tags, tag)
+			}
+		}
+		m.Tags = tags
+
+		response.TerminatingInstances = append(response.TerminatingInstances, &ec2.InstanceStateChange{
+			InstanceId:    id,
+			PreviousState: instance.State,
+			CurrentState: &ec2.InstanceState{
+				Code: aws.Int64(32),
+				Name: s(ec2.InstanceStateNameShuttingDown),
+			},
+		})
+	}
+
+	return response, nil
+}
+
+func containsString(values []*string, s string) bool {
+	for _, v := range values {
+		if aws.StringValue(v) == s {
+			return true
+		}
+	}
+	return false
 }
 
 func (m *MockEC2) DescribeInstancesWithContext(aws.Context, *ec2.DescribeInstancesInput, ...request.Option) (*ec2.DescribeInstancesOutput, error) {
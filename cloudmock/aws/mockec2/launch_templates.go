@@ -59,6 +59,13 @@ func (m *MockEC2) DescribeLaunchTemplates(request *ec2.DescribeLaunchTemplatesIn
 	for id, ltInfo := range m.LaunchTemplates {
 		launchTemplatetName := aws.StringValue(ltInfo.name)
 
+		if len(request.LaunchTemplateIds) != 0 && !containsString(request.LaunchTemplateIds, id) {
+			continue
+		}
+		if len(request.LaunchTemplateNames) != 0 && !containsString(request.LaunchTemplateNames, launchTemplatetName) {
+			continue
+		}
+
 		allFiltersMatch := true
 		for _, filter := range request.Filters {
 			filterName := aws.StringValue(filter.Name)
@@ -80,8 +87,10 @@ func (m *MockEC2) DescribeLaunchTemplates(request *ec2.DescribeLaunchTemplatesIn
 
 		if allFiltersMatch {
 			o.LaunchTemplates = append(o.LaunchTemplates, &ec2.LaunchTemplate{
-				LaunchTemplateName: aws.String(launchTemplatetName),
-				LaunchTemplateId:   aws.String(id),
+				LaunchTemplateName:   aws.String(launchTemplatetName),
+				LaunchTemplateId:     aws.String(id),
+				DefaultVersionNumber: aws.Int64(int64(ltInfo.version)),
+				LatestVersionNumber:  aws.Int64(int64(ltInfo.version)),
 			})
 		}
 	}
@@ -111,6 +120,7 @@ func (m *MockEC2) DescribeLaunchTemplateVersions(request *ec2.DescribeLaunchTemp
 			LaunchTemplateId:   aws.String(id),
 			LaunchTemplateData: ltInfo.data,
 			LaunchTemplateName: request.LaunchTemplateName,
+			VersionNumber:      aws.Int64(int64(ltInfo.version)),
 		})
 	}
 	return o, nil
@@ -180,8 +190,8 @@ func (m *MockEC2) DeleteLaunchTemplate(request *ec2.DeleteLaunchTemplateInput) (
 	if m.LaunchTemplates == nil {
 		return o, nil
 	}
-	for id := range m.LaunchTemplates {
-		if id == aws.StringValue(request.LaunchTemplateId) {
+	for id, ltInfo := range m.LaunchTemplates {
+		if id == aws.StringValue(request.LaunchTemplateId) || (request.LaunchTemplateName != nil && aws.StringValue(ltInfo.name) == aws.StringValue(request.LaunchTemplateName)) {
 			delete(m.LaunchTemplates, id)
 		}
 	}
@@ -189,6 +199,19 @@ func (m *MockEC2) DeleteLaunchTemplate(request *ec2.DeleteLaunchTemplateInput) (
 	return o, nil
 }
 
+// findLaunchTemplate returns the launch template with the given ID or name, or nil if it does not exist
+func (m *MockEC2) findLaunchTemplate(id string, name string) *launchTemplateInfo {
+	if id != "" {
+		return m.LaunchTemplates[id]
+	}
+	for _, ltInfo := range m.LaunchTemplates {
+		if aws.StringValue(ltInfo.name) == name {
+			return ltInfo
+		}
+	}
+	return nil
+}
+
 func responseLaunchTemplateData(req *ec2.RequestLaunchTemplateData) *ec2.ResponseLaunchTemplateData {
 	resp := &ec2.ResponseLaunchTemplateData{
 		DisableApiTermination: req.DisableApiTermination,
@@ -248,6 +271,23 @@ func responseLaunchTemplateData(req *ec2.RequestLaunchTemplateData) *ec2.Respons
 			Name: req.IamInstanceProfile.Name,
 		}
 	}
+	if req.Placement != nil {
+		resp.Placement = &ec2.LaunchTemplatePlacement{
+			GroupName: req.Placement.GroupName,
+			Tenancy:   req.Placement.Tenancy,
+		}
+	}
+	if req.CapacityReservationSpecification != nil {
+		resp.CapacityReservationSpecification = &ec2.LaunchTemplateCapacityReservationSpecificationResponse{
+			CapacityReservationPreference: req.CapacityReservationSpecification.CapacityReservationPreference,
+		}
+		if target := req.CapacityReservationSpecification.CapacityReservationTarget; target != nil {
+			resp.CapacityReservationSpecification.CapacityReservationTarget = &ec2.CapacityReservationTargetResponse{
+				CapacityReservationId:               target.CapacityReservationId,
+				CapacityReservationResourceGroupArn: target.CapacityReservationResourceGroupArn,
+			}
+		}
+	}
 	if req.InstanceMarketOptions != nil {
 		resp.InstanceMarketOptions = &ec2.LaunchTemplateInstanceMarketOptions{
 			MarketType: req.InstanceMarketOptions.MarketType,
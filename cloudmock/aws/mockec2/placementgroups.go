@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockec2
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/klog/v2"
+)
+
+func (m *MockEC2) CreatePlacementGroupRequest(*ec2.CreatePlacementGroupInput) (*request.Request, *ec2.CreatePlacementGroupOutput) {
+	panic("Not implemented")
+}
+func (m *MockEC2) CreatePlacementGroupWithContext(aws.Context, *ec2.CreatePlacementGroupInput, ...request.Option) (*ec2.CreatePlacementGroupOutput, error) {
+	panic("Not implemented")
+}
+func (m *MockEC2) CreatePlacementGroup(request *ec2.CreatePlacementGroupInput) (*ec2.CreatePlacementGroupOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("CreatePlacementGroup: %v", request)
+
+	name := aws.StringValue(request.GroupName)
+	for _, pg := range m.PlacementGroups {
+		if aws.StringValue(pg.GroupName) == name {
+			return nil, awserr.New("InvalidPlacementGroup.Duplicate", fmt.Sprintf("The Placement Group '%s' already exists.", name), nil)
+		}
+	}
+
+	id := m.allocateId("pg")
+	pg := &ec2.PlacementGroup{
+		GroupId:        aws.String(id),
+		GroupName:      request.GroupName,
+		PartitionCount: request.PartitionCount,
+		State:          aws.String(ec2.PlacementGroupStateAvailable),
+		Strategy:       request.Strategy,
+	}
+	if m.PlacementGroups == nil {
+		m.PlacementGroups = make(map[string]*ec2.PlacementGroup)
+	}
+	m.PlacementGroups[id] = pg
+
+	m.addTags(id, tagSpecificationsToTags(request.TagSpecifications, ec2.ResourceTypePlacementGroup)...)
+
+	copy := *pg
+	copy.Tags = m.getTags(ec2.ResourceTypePlacementGroup, id)
+	return &ec2.CreatePlacementGroupOutput{PlacementGroup: &copy}, nil
+}
+
+func (m *MockEC2) DescribePlacementGroupsRequest(*ec2.DescribePlacementGroupsInput) (*request.Request, *ec2.DescribePlacementGroupsOutput) {
+	panic("Not implemented")
+}
+func (m *MockEC2) DescribePlacementGroupsWithContext(aws.Context, *ec2.DescribePlacementGroupsInput, ...request.Option) (*ec2.DescribePlacementGroupsOutput, error) {
+	panic("Not implemented")
+}
+func (m *MockEC2) DescribePlacementGroups(request *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DescribePlacementGroups: %v", request)
+
+	response := &ec2.DescribePlacementGroupsOutput{}
+	for _, name := range request.GroupNames {
+		found := false
+		for id, pg := range m.PlacementGroups {
+			if aws.StringValue(pg.GroupName) == aws.StringValue(name) {
+				found = true
+				copy := *pg
+				copy.Tags = m.getTags(ec2.ResourceTypePlacementGroup, id)
+				response.PlacementGroups = append(response.PlacementGroups, &copy)
+			}
+		}
+		if !found {
+			return nil, awserr.New("InvalidPlacementGroup.Unknown", fmt.Sprintf("The Placement Group '%s' is unknown.", aws.StringValue(name)), nil)
+		}
+	}
+	if len(request.GroupNames) == 0 {
+		for id, pg := range m.PlacementGroups {
+			allFiltersMatch := true
+			for _, filter := range request.Filters {
+				if !m.hasTag(ec2.ResourceTypePlacementGroup, id, filter) {
+					allFiltersMatch = false
+					break
+				}
+			}
+			if !allFiltersMatch {
+				continue
+			}
+
+			copy := *pg
+			copy.Tags = m.getTags(ec2.ResourceTypePlacementGroup, id)
+			response.PlacementGroups = append(response.PlacementGroups, &copy)
+		}
+	}
+
+	return response, nil
+}
+
+func (m *MockEC2) DeletePlacementGroupRequest(*ec2.DeletePlacementGroupInput) (*request.Request, *ec2.DeletePlacementGroupOutput) {
+	panic("Not implemented")
+}
+func (m *MockEC2) DeletePlacementGroupWithContext(aws.Context, *ec2.DeletePlacementGroupInput, ...request.Option) (*ec2.DeletePlacementGroupOutput, error) {
+	panic("Not implemented")
+}
+func (m *MockEC2) DeletePlacementGroup(request *ec2.DeletePlacementGroupInput) (*ec2.DeletePlacementGroupOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DeletePlacementGroup: %v", request)
+
+	name := aws.StringValue(request.GroupName)
+	for id, pg := range m.PlacementGroups {
+		if aws.StringValue(pg.GroupName) == name {
+			delete(m.PlacementGroups, id)
+			return &ec2.DeletePlacementGroupOutput{}, nil
+		}
+	}
+
+	return nil, awserr.New("InvalidPlacementGroup.Unknown", fmt.Sprintf("The Placement Group '%s' is unknown.", name), nil)
+}
@@ -28,6 +28,8 @@ import (
 
 type subnetInfo struct {
 	main ec2.Subnet
+
+	addressNumber int
 }
 
 func (m *MockEC2) FindSubnet(id string) *ec2.Subnet {
@@ -59,6 +59,8 @@ func (m *MockEC2) addTags(resourceId string, tags ...*ec2.Tag) {
 		resourceType = ec2.ResourceTypeSecurityGroup
 	} else if strings.HasPrefix(resourceId, "vol-") {
 		resourceType = ec2.ResourceTypeVolume
+	} else if strings.HasPrefix(resourceId, "eigw-") {
+		resourceType = ec2.ResourceTypeEgressOnlyInternetGateway
 	} else if strings.HasPrefix(resourceId, "igw-") {
 		resourceType = ec2.ResourceTypeInternetGateway
 	} else if strings.HasPrefix(resourceId, "nat-") {
@@ -73,6 +75,10 @@ func (m *MockEC2) addTags(resourceId string, tags ...*ec2.Tag) {
 		resourceType = ec2.ResourceTypeLaunchTemplate
 	} else if strings.HasPrefix(resourceId, "key-") {
 		resourceType = ec2.ResourceTypeKeyPair
+	} else if strings.HasPrefix(resourceId, "i-") {
+		resourceType = ec2.ResourceTypeInstance
+	} else if strings.HasPrefix(resourceId, "pg-") {
+		resourceType = ec2.ResourceTypePlacementGroup
 	} else {
 		klog.Fatalf("Unknown resource-type in create tags: %v", resourceId)
 	}
@@ -174,6 +180,9 @@ func (m *MockEC2) DescribeTags(request *ec2.DescribeTagsInput) (*ec2.DescribeTag
 					if *v == *tag.Key {
 						match = true
 					}
+					if strings.HasSuffix(*v, "*") && strings.HasPrefix(*tag.Key, strings.TrimSuffix(*v, "*")) {
+						match = true
+					}
 				}
 
 			case "resource-id":
@@ -207,8 +216,16 @@ func (m *MockEC2) DescribeTags(request *ec2.DescribeTagsInput) (*ec2.DescribeTag
 
 	return response, nil
 }
-func (m *MockEC2) DescribeTagsPages(*ec2.DescribeTagsInput, func(*ec2.DescribeTagsOutput, bool) bool) error {
-	panic("Not implemented")
+func (m *MockEC2) DescribeTagsPages(request *ec2.DescribeTagsInput, callback func(*ec2.DescribeTagsOutput, bool) bool) error {
+	// For the mock, we just send everything in one page
+	page, err := m.DescribeTags(request)
+	if err != nil {
+		return err
+	}
+
+	callback(page, false)
+
+	return nil
 }
 func (m *MockEC2) DescribeTagsPagesWithContext(aws.Context, *ec2.DescribeTagsInput, func(*ec2.DescribeTagsOutput, bool) bool, ...request.Option) error {
 	panic("Not implemented")
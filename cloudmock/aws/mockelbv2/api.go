@@ -35,6 +35,7 @@ type MockELBV2 struct {
 	Listeners     map[string]*listener
 	listenerCount int
 	LBAttributes  map[string][]*elbv2.LoadBalancerAttribute
+	TGAttributes  map[string][]*elbv2.TargetGroupAttribute
 
 	Tags map[string]*elbv2.TagDescription
 }
@@ -45,6 +46,7 @@ type loadBalancer struct {
 
 type targetGroup struct {
 	description elbv2.TargetGroup
+	targets     map[string]*elbv2.TargetHealthDescription
 }
 
 type listener struct {
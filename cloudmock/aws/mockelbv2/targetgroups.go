@@ -100,6 +100,11 @@ func (m *MockELBV2) CreateTargetGroup(request *elbv2.CreateTargetGroupInput) (*e
 		VpcId:                   request.VpcId,
 		HealthyThresholdCount:   request.HealthyThresholdCount,
 		UnhealthyThresholdCount: request.UnhealthyThresholdCount,
+
+		HealthCheckProtocol:        request.HealthCheckProtocol,
+		HealthCheckPort:            request.HealthCheckPort,
+		HealthCheckPath:            request.HealthCheckPath,
+		HealthCheckIntervalSeconds: request.HealthCheckIntervalSeconds,
 	}
 
 	m.tgCount++
@@ -131,3 +136,183 @@ func (m *MockELBV2) DeleteTargetGroup(request *elbv2.DeleteTargetGroupInput) (*e
 	delete(m.TargetGroups, arn)
 	return &elbv2.DeleteTargetGroupOutput{}, nil
 }
+
+func (m *MockELBV2) ModifyTargetGroup(request *elbv2.ModifyTargetGroupInput) (*elbv2.ModifyTargetGroupOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("ModifyTargetGroup %v", request)
+
+	tg := m.TargetGroups[aws.StringValue(request.TargetGroupArn)]
+	if tg == nil {
+		return nil, awserr.New(elbv2.ErrCodeTargetGroupNotFoundException, "target group not found", nil)
+	}
+
+	if request.HealthyThresholdCount != nil {
+		tg.description.HealthyThresholdCount = request.HealthyThresholdCount
+	}
+	if request.UnhealthyThresholdCount != nil {
+		tg.description.UnhealthyThresholdCount = request.UnhealthyThresholdCount
+	}
+	if request.HealthCheckProtocol != nil {
+		tg.description.HealthCheckProtocol = request.HealthCheckProtocol
+	}
+	if request.HealthCheckPort != nil {
+		tg.description.HealthCheckPort = request.HealthCheckPort
+	}
+	if request.HealthCheckPath != nil {
+		tg.description.HealthCheckPath = request.HealthCheckPath
+	}
+	if request.HealthCheckIntervalSeconds != nil {
+		tg.description.HealthCheckIntervalSeconds = request.HealthCheckIntervalSeconds
+	}
+
+	return &elbv2.ModifyTargetGroupOutput{TargetGroups: []*elbv2.TargetGroup{&tg.description}}, nil
+}
+
+func (m *MockELBV2) DescribeTargetGroupAttributes(request *elbv2.DescribeTargetGroupAttributesInput) (*elbv2.DescribeTargetGroupAttributesOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DescribeTargetGroupAttributes %v", request)
+
+	arn := aws.StringValue(request.TargetGroupArn)
+	if m.TargetGroups[arn] == nil {
+		return nil, awserr.New(elbv2.ErrCodeTargetGroupNotFoundException, "target group not found", nil)
+	}
+
+	attributes := []*elbv2.TargetGroupAttribute{
+		{
+			Key:   aws.String("deregistration_delay.timeout_seconds"),
+			Value: aws.String("300"),
+		},
+	}
+	for _, attribute := range m.TGAttributes[arn] {
+		found := false
+		for _, existing := range attributes {
+			if aws.StringValue(existing.Key) == aws.StringValue(attribute.Key) {
+				existing.Value = attribute.Value
+				found = true
+			}
+		}
+		if !found {
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	return &elbv2.DescribeTargetGroupAttributesOutput{Attributes: attributes}, nil
+}
+
+func (m *MockELBV2) ModifyTargetGroupAttributes(request *elbv2.ModifyTargetGroupAttributesInput) (*elbv2.ModifyTargetGroupAttributesOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("ModifyTargetGroupAttributes %v", request)
+
+	arn := aws.StringValue(request.TargetGroupArn)
+	if m.TargetGroups[arn] == nil {
+		return nil, awserr.New(elbv2.ErrCodeTargetGroupNotFoundException, "target group not found", nil)
+	}
+
+	if m.TGAttributes == nil {
+		m.TGAttributes = make(map[string][]*elbv2.TargetGroupAttribute)
+	}
+	attributes := m.TGAttributes[arn]
+	for _, attribute := range request.Attributes {
+		found := false
+		for _, existing := range attributes {
+			if aws.StringValue(existing.Key) == aws.StringValue(attribute.Key) {
+				existing.Value = attribute.Value
+				found = true
+			}
+		}
+		if !found {
+			attributes = append(attributes, &elbv2.TargetGroupAttribute{Key: attribute.Key, Value: attribute.Value})
+		}
+	}
+	m.TGAttributes[arn] = attributes
+
+	return &elbv2.ModifyTargetGroupAttributesOutput{Attributes: attributes}, nil
+}
+
+func (m *MockELBV2) RegisterTargets(request *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("RegisterTargets %v", request)
+
+	tg := m.TargetGroups[aws.StringValue(request.TargetGroupArn)]
+	if tg == nil {
+		return nil, awserr.New(elbv2.ErrCodeTargetGroupNotFoundException, "target group not found", nil)
+	}
+
+	if tg.targets == nil {
+		tg.targets = make(map[string]*elbv2.TargetHealthDescription)
+	}
+	for _, target := range request.Targets {
+		tg.targets[aws.StringValue(target.Id)] = &elbv2.TargetHealthDescription{
+			Target: &elbv2.TargetDescription{Id: target.Id, Port: target.Port},
+			TargetHealth: &elbv2.TargetHealth{
+				State: aws.String(elbv2.TargetHealthStateEnumHealthy),
+			},
+		}
+	}
+
+	return &elbv2.RegisterTargetsOutput{}, nil
+}
+
+// DeregisterTargets removes the targets immediately; the mock does not simulate connection draining
+func (m *MockELBV2) DeregisterTargets(request *elbv2.DeregisterTargetsInput) (*elbv2.DeregisterTargetsOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DeregisterTargets %v", request)
+
+	tg := m.TargetGroups[aws.StringValue(request.TargetGroupArn)]
+	if tg == nil {
+		return nil, awserr.New(elbv2.ErrCodeTargetGroupNotFoundException, "target group not found", nil)
+	}
+
+	for _, target := range request.Targets {
+		delete(tg.targets, aws.StringValue(target.Id))
+	}
+
+	return &elbv2.DeregisterTargetsOutput{}, nil
+}
+
+func (m *MockELBV2) DescribeTargetHealth(request *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.Infof("DescribeTargetHealth %v", request)
+
+	tg := m.TargetGroups[aws.StringValue(request.TargetGroupArn)]
+	if tg == nil {
+		return nil, awserr.New(elbv2.ErrCodeTargetGroupNotFoundException, "target group not found", nil)
+	}
+
+	response := &elbv2.DescribeTargetHealthOutput{}
+	if len(request.Targets) == 0 {
+		for _, target := range tg.targets {
+			response.TargetHealthDescriptions = append(response.TargetHealthDescriptions, target)
+		}
+		return response, nil
+	}
+
+	for _, target := range request.Targets {
+		if description := tg.targets[aws.StringValue(target.Id)]; description != nil {
+			response.TargetHealthDescriptions = append(response.TargetHealthDescriptions, description)
+		} else {
+			// AWS reports targets that are not registered as unused
+			response.TargetHealthDescriptions = append(response.TargetHealthDescriptions, &elbv2.TargetHealthDescription{
+				Target: target,
+				TargetHealth: &elbv2.TargetHealth{
+					State:  aws.String(elbv2.TargetHealthStateEnumUnused),
+					Reason: aws.String(elbv2.TargetHealthReasonEnumTargetNotRegistered),
+				},
+			})
+		}
+	}
+
+	return response, nil
+}
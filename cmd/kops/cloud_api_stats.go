@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/tables"
+)
+
+// printCloudAPIStats prints the summary of the calls to the cloud APIs made by the command
+func printCloudAPIStats(out io.Writer) {
+	stats := awsup.APIStats()
+	if len(stats) == 0 {
+		fmt.Fprintf(out, "\nNo calls to the cloud APIs were made.\n")
+		return
+	}
+
+	var calls, throttles int64
+	for _, s := range stats {
+		calls += s.Calls
+		throttles += s.Throttles
+	}
+
+	fmt.Fprintf(out, "\n")
+	t := &tables.Table{}
+	t.AddColumn("SERVICE", func(s awsup.APICallStats) string {
+		return s.Service
+	})
+	t.AddColumn("OPERATION", func(s awsup.APICallStats) string {
+		return s.Operation
+	})
+	t.AddColumn("CALLS", func(s awsup.APICallStats) int64 {
+		return s.Calls
+	})
+	t.AddColumn("ERRORS", func(s awsup.APICallStats) int64 {
+		return s.Errors
+	})
+	t.AddColumn("RETRIES", func(s awsup.APICallStats) int64 {
+		return s.Retries
+	})
+	t.AddColumn("THROTTLED", func(s awsup.APICallStats) int64 {
+		return s.Throttles
+	})
+	t.AddColumn("AVG LATENCY", func(s awsup.APICallStats) string {
+		if s.Calls == 0 {
+			return "-"
+		}
+		return formatLatency(s.TotalLatency / time.Duration(s.Calls))
+	})
+	t.AddColumn("MAX LATENCY", func(s awsup.APICallStats) string {
+		return formatLatency(s.MaxLatency)
+	})
+	if err := t.Render(stats, out, "SERVICE", "OPERATION", "CALLS", "ERRORS", "RETRIES", "THROTTLED", "AVG LATENCY", "MAX LATENCY"); err != nil {
+		klog.Warningf("error printing cloud API stats: %v", err)
+		return
+	}
+
+	fmt.Fprintf(out, "\n%d calls to the AWS APIs, %d attempts throttled", calls, throttles)
+	if wait := awsup.APIRateLimitWait(); wait > 0 {
+		fmt.Fprintf(out, ", %v spent waiting for the --cloud-api-qps budget", wait.Round(time.Millisecond))
+	}
+	fmt.Fprintf(out, ".\n")
+}
+
+func formatLatency(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
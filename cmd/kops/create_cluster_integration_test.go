@@ -133,6 +133,11 @@ func TestCreateClusterIPv6(t *testing.T) {
 	runCreateClusterIntegrationTest(t, "../../tests/integration/create_cluster/ipv6", "v1alpha2")
 }
 
+// TestCreateClusterIPv6Private runs kops create cluster --zones us-test-1a --master-zones us-test-1a --ipv6 --topology private
+func TestCreateClusterIPv6Private(t *testing.T) {
+	runCreateClusterIntegrationTest(t, "../../tests/integration/create_cluster/ipv6-private", "v1alpha2")
+}
+
 func runCreateClusterIntegrationTest(t *testing.T, srcDir string, version string) {
 	ctx := context.Background()
 
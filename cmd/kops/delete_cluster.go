@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 
@@ -44,8 +45,16 @@ type DeleteClusterOptions struct {
 	External    bool
 	Unregister  bool
 	ClusterName string
+	Output      string
+	// Keep selects resources which are not deleted, by type, ID or <type>:<ID>
+	Keep []string
 }
 
+const (
+	// OutputDot renders the cloud resources as a graphviz graph
+	OutputDot = "dot"
+)
+
 var (
 	deleteClusterLong = templates.LongDesc(i18n.T(`
 	Deletes a Kubernetes cluster and all associated resources.  Resources include instancegroups,
@@ -57,13 +66,23 @@ var (
 	# The --yes option runs the command immediately.
 	kops delete cluster --name=k8s.cluster.site --yes
 
+	# Preview which cloud resources would be deleted, why they were matched and what blocks their deletion.
+	kops delete cluster --name=k8s.cluster.site --output=json
+
+	# Render the dependencies between the cloud resources as a graphviz graph.
+	kops delete cluster --name=k8s.cluster.site --output=dot | dot -Tsvg > resources.svg
+
+	# Delete a cluster, keeping a volume and all the Route53 records.
+	kops delete cluster --name=k8s.cluster.site --keep=volume:vol-0123456789abcdef0 --keep=route53-record --yes
 	`))
 
 	deleteClusterShort = i18n.T("Delete a cluster.")
 )
 
 func NewCmdDeleteCluster(f *util.Factory, out io.Writer) *cobra.Command {
-	options := &DeleteClusterOptions{}
+	options := &DeleteClusterOptions{
+		Output: OutputTable,
+	}
 
 	cmd := &cobra.Command{
 		Use:               "cluster [CLUSTER]",
@@ -84,6 +103,12 @@ func NewCmdDeleteCluster(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&options.Region, "region", options.Region, "External cluster's cloud region")
 	cmd.RegisterFlagCompletionFunc("region", completeRegion)
 
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format for the cloud resources. One of: table, json, dot. json and dot only preview the deletion")
+	cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{OutputTable, OutputJSON, OutputDot}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringSliceVar(&options.Keep, "keep", options.Keep, "Cloud resources not to delete, selected by type, ID or <type>:<ID>. Resources which can only be deleted after them are kept too")
+
 	return cmd
 }
 
@@ -93,6 +118,19 @@ func RunDeleteCluster(ctx context.Context, f *util.Factory, out io.Writer, optio
 		return fmt.Errorf("--name is required (for safety)")
 	}
 
+	switch options.Output {
+	case "", OutputTable:
+	case OutputJSON, OutputDot:
+		if options.Yes {
+			return fmt.Errorf("--output=%s only previews the deletion and cannot be used with --yes", options.Output)
+		}
+		if options.Unregister {
+			return fmt.Errorf("--output=%s cannot be used with --unregister", options.Output)
+		}
+	default:
+		return fmt.Errorf("unsupported output format %q, one of %s, %s or %s is expected", options.Output, OutputTable, OutputJSON, OutputDot)
+	}
+
 	var cloud fi.Cloud
 	var cluster *kopsapi.Cluster
 	var err error
@@ -130,12 +168,27 @@ func RunDeleteCluster(ctx context.Context, f *util.Factory, out io.Writer, optio
 			return err
 		}
 
-		clusterResources := make(map[string]*resources.Resource)
-		for k, resource := range allResources {
-			if resource.Shared {
-				continue
+		plan, err := resourceops.BuildDeletionPlan(allResources, options.Keep)
+		if err != nil {
+			return err
+		}
+
+		switch options.Output {
+		case OutputJSON:
+			b, err := json.MarshalIndent(plan, "", "  ")
+			if err != nil {
+				return fmt.Errorf("error marshaling resources to json: %v", err)
 			}
-			clusterResources[k] = resource
+			_, err = fmt.Fprintf(out, "%s\n", b)
+			return err
+		case OutputDot:
+			return plan.WriteDot(out)
+		}
+
+		clusterResources := plan.ResourcesToDelete(allResources)
+
+		if err := renderKeptResources(out, plan); err != nil {
+			return err
 		}
 
 		if len(clusterResources) == 0 {
@@ -207,6 +260,39 @@ func RunDeleteCluster(ctx context.Context, f *util.Factory, out io.Writer, optio
 	return nil
 }
 
+// renderKeptResources prints the resources owned by the cluster which are not deleted because of --keep
+func renderKeptResources(out io.Writer, plan *resourceops.DeletionPlan) error {
+	var kept []*resourceops.PlannedResource
+	for _, r := range plan.Resources {
+		if !r.Delete && !r.Shared {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(out, "Keeping cloud resources:\n")
+	t := &tables.Table{}
+	t.AddColumn("TYPE", func(r *resourceops.PlannedResource) string {
+		return r.Type
+	})
+	t.AddColumn("ID", func(r *resourceops.PlannedResource) string {
+		return r.ID
+	})
+	t.AddColumn("NAME", func(r *resourceops.PlannedResource) string {
+		return r.Name
+	})
+	t.AddColumn("REASON", func(r *resourceops.PlannedResource) string {
+		return r.KeepReason
+	})
+	if err := t.Render(kept, out, "TYPE", "NAME", "ID", "REASON"); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n")
+	return nil
+}
+
 func completeRegion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// TODO call into cloud provider(s) to get list of valid regions
 	return nil, cobra.ShellCompDirectiveNoFileComp
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 
 	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/commands/commandutils"
@@ -42,7 +43,11 @@ var (
 	(original) and download (local repository) locations.
 
 	When invoked with the ` + pretty.Bash("--copy") + ` flag, will copy each asset from the
-	canonical to the download location.`))
+	canonical to the download location.
+
+	When invoked with the ` + pretty.Bash("--export-bundle") + ` flag, will write each asset from the
+	canonical location to a bundle file, which can later be copied to the download locations
+	using ` + pretty.Bash("kops import assets") + `.`))
 
 	getAssetsExample = templates.Examples(i18n.T(`
 	# Display all assets.
@@ -50,6 +55,9 @@ var (
 
 	# Copy assets to the local repositories configured in the cluster spec.
 	kops get assets --copy 
+
+	# Write assets to a bundle, for importing into the local repositories of an air-gapped site.
+	kops get assets --export-bundle=bundle.tar
 	`))
 
 	getAssetsShort = i18n.T(`Display assets for cluster.`)
@@ -57,7 +65,8 @@ var (
 
 type GetAssetsOptions struct {
 	*GetOptions
-	Copy bool
+	Copy         bool
+	ExportBundle string
 }
 
 type Image struct {
@@ -96,6 +105,7 @@ func NewCmdGetAssets(f *util.Factory, out io.Writer, getOptions *GetOptions) *co
 	}
 
 	cmd.Flags().BoolVar(&options.Copy, "copy", options.Copy, "copy assets to local repository")
+	cmd.Flags().StringVar(&options.ExportBundle, "export-bundle", options.ExportBundle, "write assets to a bundle file for use with 'kops import assets'")
 
 	return cmd
 }
@@ -147,6 +157,12 @@ func RunGetAssets(ctx context.Context, f *util.Factory, out io.Writer, options *
 		}
 	}
 
+	if options.ExportBundle != "" {
+		if err := exportAssetsBundle(options.ExportBundle, updateClusterResults.ImageAssets, updateClusterResults.FileAssets); err != nil {
+			return err
+		}
+	}
+
 	switch options.Output {
 	case OutputTable:
 		if err = imageOutputTable(result.Images, out); err != nil {
@@ -176,6 +192,23 @@ func RunGetAssets(ctx context.Context, f *util.Factory, out io.Writer, options *
 	return nil
 }
 
+func exportAssetsBundle(bundleFile string, imageAssets []*assets.ImageAsset, fileAssets []*assets.FileAsset) error {
+	f, err := os.Create(bundleFile)
+	if err != nil {
+		return fmt.Errorf("error creating bundle file: %v", err)
+	}
+
+	if err := assets.ExportBundle(imageAssets, fileAssets, f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing bundle file %q: %v", bundleFile, err)
+	}
+	return nil
+}
+
 func imageOutputTable(images []*Image, out io.Writer) error {
 	fmt.Println("")
 	t := &tables.Table{}
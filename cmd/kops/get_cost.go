@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/cost"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	getCostLong = templates.LongDesc(i18n.T(`
+	Estimate the monthly cost of the cloud resources of a cluster.
+
+	The cloud resources are computed as by a dry run of "kops update cluster", so the current
+	cost of the resources in the cloud is shown next to the cost once the changes to the
+	cluster spec are applied.
+
+	Instances, root volumes, warm pools, etcd volumes, NAT gateways and load balancers are
+	priced from a price table file, so no access to a pricing API is needed. kOps ships
+	approximate price tables for AWS (us-east-1) and GCE (us-central1); use --price-table
+	to use prices matching the region and the discounts of the account.`))
+
+	getCostExample = templates.Examples(i18n.T(`
+	# Compare the cost of the cluster before and after the pending changes
+	kops edit ig nodes-us-east-1a
+	kops get cost k8s-cluster.example.com
+
+	# Use a custom price table
+	kops get cost k8s-cluster.example.com --price-table prices.yaml -o yaml
+	`))
+
+	getCostShort = i18n.T(`Estimate the monthly cost of a cluster.`)
+)
+
+type GetCostOptions struct {
+	*GetOptions
+
+	// PriceTable is the location of the price table, the table shipped with kOps for the cloud provider is used if not set
+	PriceTable string
+}
+
+func NewCmdGetCost(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := GetCostOptions{
+		GetOptions: getOptions,
+	}
+
+	cmd := &cobra.Command{
+		Use:               "cost [CLUSTER]",
+		Short:             getCostShort,
+		Long:              getCostLong,
+		Example:           getCostExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(&rootCommand, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGetCost(context.TODO(), f, out, &options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.PriceTable, "price-table", options.PriceTable, "Price table to use instead of the one shipped with kOps")
+	cmd.MarkFlagFilename("price-table", "yaml", "json")
+
+	return cmd
+}
+
+func RunGetCost(ctx context.Context, f *util.Factory, out io.Writer, options *GetCostOptions) error {
+	if options.Output != OutputTable && options.Output != OutputYaml && options.Output != OutputJSON {
+		return fmt.Errorf("unsupported output format: %q", options.Output)
+	}
+
+	updateClusterOptions := &UpdateClusterOptions{}
+	updateClusterOptions.InitDefaults()
+	updateClusterOptions.Target = cloudup.TargetDryRun
+	updateClusterOptions.Quiet = true
+	updateClusterOptions.CreateKubecfg = false
+	updateClusterOptions.ClusterName = options.ClusterName
+
+	updateClusterResults, err := RunUpdateCluster(ctx, f, out, updateClusterOptions)
+	if err != nil {
+		return err
+	}
+
+	cloudProvider := kops.CloudProviderID(updateClusterResults.Cluster.Spec.CloudProvider)
+
+	var prices *cost.PriceTable
+	if options.PriceTable != "" {
+		prices, err = cost.LoadPriceTable(options.PriceTable)
+	} else {
+		prices, err = cost.DefaultPriceTable(cloudProvider)
+	}
+	if err != nil {
+		return err
+	}
+	if prices.Cloud != string(cloudProvider) {
+		return fmt.Errorf("price table is for cloud %q, but cluster is on %q", prices.Cloud, cloudProvider)
+	}
+
+	target := updateClusterResults.Target.(*fi.DryRunTarget)
+	estimate := cost.EstimateCost(prices, updateClusterResults.TaskMap, target.ActualTasks())
+
+	switch options.Output {
+	case OutputTable:
+		for _, warning := range estimate.Warnings {
+			klog.Warningf("%s", warning)
+		}
+		return costOutputTable(estimate, prices, out)
+	case OutputYaml:
+		y, err := yaml.Marshal(estimate)
+		if err != nil {
+			return fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	case OutputJSON:
+		j, err := json.MarshalIndent(estimate, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := fmt.Fprintf(out, "%s\n", j); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func costOutputTable(estimate *cost.Estimate, prices *cost.PriceTable, out io.Writer) error {
+	total := &cost.Item{
+		Kind:     "TOTAL",
+		Current:  &cost.Cost{Range: estimate.Current},
+		Proposed: &cost.Cost{Range: estimate.Proposed},
+	}
+	items := append(estimate.Items, total)
+
+	t := &tables.Table{}
+	t.AddColumn("KIND", func(i *cost.Item) string {
+		return i.Kind
+	})
+	t.AddColumn("NAME", func(i *cost.Item) string {
+		return i.Name
+	})
+	t.AddColumn("DESCRIPTION", func(i *cost.Item) string {
+		switch {
+		case i.Proposed == nil:
+			return i.Current.Description + " (removed)"
+		case i.Current == nil || i.Current.Description == i.Proposed.Description:
+			return i.Proposed.Description
+		default:
+			return i.Current.Description + " -> " + i.Proposed.Description
+		}
+	})
+	t.AddColumn("CURRENT", func(i *cost.Item) string {
+		return formatCost(i.Current)
+	})
+	t.AddColumn("PROPOSED", func(i *cost.Item) string {
+		return formatCost(i.Proposed)
+	})
+	if err := t.Render(items, out, "KIND", "NAME", "DESCRIPTION", "CURRENT", "PROPOSED"); err != nil {
+		return err
+	}
+
+	region := prices.Region
+	if region == "" {
+		region = "any region"
+	}
+	fmt.Fprintf(out, "\nMonthly cost in %s, from the %s prices for %s.\n", prices.Currency, prices.Cloud, region)
+	return nil
+}
+
+func formatCost(c *cost.Cost) string {
+	if c == nil {
+		return "-"
+	}
+	if fmt.Sprintf("%.2f", c.Min) == fmt.Sprintf("%.2f", c.Max) {
+		return fmt.Sprintf("%.2f", c.Max)
+	}
+	return fmt.Sprintf("%.2f-%.2f", c.Min, c.Max)
+}
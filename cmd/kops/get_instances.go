@@ -18,57 +18,121 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"strings"
+	"time"
 
-	"k8s.io/kops/pkg/cloudinstances"
-	"k8s.io/kops/pkg/commands/commandutils"
-	"k8s.io/kubectl/pkg/util/i18n"
-	"k8s.io/kubectl/pkg/util/templates"
-
-	"k8s.io/klog/v2"
-
-	"k8s.io/client-go/kubernetes"
-
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
-
-	"k8s.io/kops/util/pkg/tables"
-
-	"k8s.io/kops/pkg/apis/kops"
-
-	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
 	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/commands/commandutils"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
 )
 
 var (
+	getInstancesLong = templates.LongDesc(i18n.T(`
+	Display the instances of a cluster, joined with their Kubernetes nodes.
+
+	Each instance shows whether it is up to date with the instance group spec, why it needs
+	an update, and the readiness, kubelet version and age of its node. Instances that have
+	not joined the cluster have no node. The wide output format adds the reason for the update,
+	and the OS image and taints of the node.`))
+
 	getInstancesExample = templates.Examples(i18n.T(`
 	# Display all instances.
 	kops get instances
+
+	# Display the instances that need an update, with the OS image and taints of their nodes.
+	kops get instances --status needsupdate -o wide
+
+	# Display the instances of an instance group whose nodes are not ready.
+	kops get instances --instance-group nodes-us-east-1a --status notready
+
+	# Display the instances of nodes with a label, as JSON.
+	kops get instances --selector workload=batch -o json
 	`))
 
 	getInstancesShort = i18n.T(`Display cluster instances.`)
 )
 
-func NewCmdGetInstances(f *util.Factory, out io.Writer, options *GetOptions) *cobra.Command {
+// OutputWide adds the details of the nodes to the table of instances
+const OutputWide = "wide"
+
+type GetInstancesOptions struct {
+	*GetOptions
+
+	// Selector is a label selector for the nodes of the instances
+	Selector string
+	// InstanceGroups are the instance groups to display the instances of
+	InstanceGroups []string
+	// Status only displays instances with the status: needsupdate, detached or notready
+	Status string
+}
+
+func NewCmdGetInstances(f *util.Factory, out io.Writer, getOptions *GetOptions) *cobra.Command {
+	options := GetInstancesOptions{
+		GetOptions: getOptions,
+	}
+
 	cmd := &cobra.Command{
 		Use:               "instances [CLUSTER]",
 		Short:             getInstancesShort,
+		Long:              getInstancesLong,
 		Example:           getInstancesExample,
 		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
 		ValidArgsFunction: commandutils.CompleteClusterName(&rootCommand, true, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return RunGetInstances(context.TODO(), f, out, options)
+			return RunGetInstances(context.TODO(), f, out, &options)
 		},
 	}
 
+	cmd.Flags().StringVarP(&options.Selector, "selector", "l", options.Selector, "Label selector for the nodes of the instances")
+	cmd.Flags().StringSliceVar(&options.InstanceGroups, "instance-group", options.InstanceGroups, "Instance groups to display the instances of")
+	cmd.RegisterFlagCompletionFunc("instance-group", completeInstanceGroup(&options.InstanceGroups, nil))
+	cmd.Flags().StringVar(&options.Status, "status", options.Status, "Only display instances with the status. One of: "+strings.Join(cloudinstances.FilterStatuses, ", "))
+	cmd.RegisterFlagCompletionFunc("status", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cloudinstances.FilterStatuses, cobra.ShellCompDirectiveNoFileComp
+	})
+
 	return cmd
 }
 
-func RunGetInstances(ctx context.Context, f *util.Factory, out io.Writer, options *GetOptions) error {
+func RunGetInstances(ctx context.Context, f *util.Factory, out io.Writer, options *GetInstancesOptions) error {
+	switch options.Output {
+	case OutputTable, OutputWide, OutputYaml, OutputJSON:
+	default:
+		return fmt.Errorf("Unknown output format: %q", options.Output)
+	}
+
+	filter := &cloudinstances.InstanceFilter{
+		InstanceGroups: options.InstanceGroups,
+		Status:         options.Status,
+	}
+	if options.Selector != "" {
+		selector, err := labels.Parse(options.Selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector %q: %v", options.Selector, err)
+		}
+		filter.Selector = selector
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
 	clientset, err := f.Clientset()
 	if err != nil {
 		return err
@@ -93,9 +157,15 @@ func RunGetInstances(ctx context.Context, f *util.Factory, out io.Writer, option
 		return err
 	}
 
+	var nodes []v1.Node
 	nodeList, err := k8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
+		if filter.NeedsNodes() {
+			return fmt.Errorf("cannot list the nodes to filter the instances by, Kubernetes API unavailable: %v", err)
+		}
 		klog.Warningf("cannot list node names. Kubernetes API unavailable: %v", err)
+	} else {
+		nodes = nodeList.Items
 	}
 
 	igList, err := clientset.InstanceGroupsFor(cluster).List(ctx, metav1.ListOptions{})
@@ -105,66 +175,119 @@ func RunGetInstances(ctx context.Context, f *util.Factory, out io.Writer, option
 
 	var instanceGroups []*kops.InstanceGroup
 	for i := range igList.Items {
-		instanceGroups = append(instanceGroups, &igList.Items[i])
+		ig := &igList.Items[i]
+		instanceGroups = append(instanceGroups, ig)
+	}
+	for _, name := range options.InstanceGroups {
+		found := false
+		for _, ig := range instanceGroups {
+			if ig.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("instance group %q not found", name)
+		}
 	}
 
-	var cloudInstances []*cloudinstances.CloudInstance
-
-	cloudGroups, err := cloud.GetCloudGroups(cluster, instanceGroups, false, nodeList.Items)
-
+	cloudGroups, err := cloud.GetCloudGroups(cluster, instanceGroups, false, nodes)
 	if err != nil {
 		return err
 	}
 
+	var cloudInstances []*cloudinstances.CloudInstance
 	for _, cg := range cloudGroups {
-		cloudInstances = append(cloudInstances, cg.Ready...)
-		cloudInstances = append(cloudInstances, cg.NeedUpdate...)
 		cg.AdjustNeedUpdate()
+		for _, members := range [][]*cloudinstances.CloudInstance{cg.Ready, cg.NeedUpdate} {
+			for _, i := range members {
+				if filter.Matches(i) {
+					cloudInstances = append(cloudInstances, i)
+				}
+			}
+		}
 	}
+	instances := cloudinstances.Summarize(cloudInstances)
 
 	switch options.Output {
-	case OutputTable:
-		return instanceOutputTable(cloudInstances, out)
+	case OutputYaml:
+		y, err := yaml.Marshal(instances)
+		if err != nil {
+			return fmt.Errorf("unable to marshal YAML: %v", err)
+		}
+		if _, err := out.Write(y); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
+	case OutputJSON:
+		j, err := json.MarshalIndent(instances, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to marshal JSON: %v", err)
+		}
+		if _, err := fmt.Fprintf(out, "%s\n", j); err != nil {
+			return fmt.Errorf("error writing to output: %v", err)
+		}
 	default:
-		return fmt.Errorf("unsupported output format: %q", options.Output)
+		return instanceOutputTable(instances, out, options.Output == OutputWide)
 	}
+
+	return nil
 }
 
-func instanceOutputTable(instances []*cloudinstances.CloudInstance, out io.Writer) error {
-	fmt.Println("")
+func instanceOutputTable(instances []*cloudinstances.InstanceSummary, out io.Writer, wide bool) error {
 	t := &tables.Table{}
-	t.AddColumn("ID", func(i *cloudinstances.CloudInstance) string {
+	t.AddColumn("ID", func(i *cloudinstances.InstanceSummary) string {
 		return i.ID
 	})
-	t.AddColumn("NODE-NAME", func(i *cloudinstances.CloudInstance) string {
-		node := i.Node
-		if node == nil {
-			return ""
-		} else {
-			return node.Name
-		}
+	t.AddColumn("NODE-NAME", func(i *cloudinstances.InstanceSummary) string {
+		return i.NodeName
 	})
-	t.AddColumn("STATUS", func(i *cloudinstances.CloudInstance) string {
+	t.AddColumn("STATUS", func(i *cloudinstances.InstanceSummary) string {
 		return i.Status
 	})
-	t.AddColumn("ROLES", func(i *cloudinstances.CloudInstance) string {
+	t.AddColumn("READY", func(i *cloudinstances.InstanceSummary) string {
+		if i.NodeName == "" {
+			return "-"
+		}
+		return i.Ready
+	})
+	t.AddColumn("ROLES", func(i *cloudinstances.InstanceSummary) string {
 		return strings.Join(i.Roles, ", ")
 	})
-	t.AddColumn("INTERNAL-IP", func(i *cloudinstances.CloudInstance) string {
-		return i.PrivateIP
+	t.AddColumn("INTERNAL-IP", func(i *cloudinstances.InstanceSummary) string {
+		return i.InternalIP
 	})
-	t.AddColumn("INSTANCE-GROUP", func(i *cloudinstances.CloudInstance) string {
-		return i.CloudInstanceGroup.HumanName
+	t.AddColumn("INSTANCE-GROUP", func(i *cloudinstances.InstanceSummary) string {
+		return i.CloudGroup
 	})
-	t.AddColumn("MACHINE-TYPE", func(i *cloudinstances.CloudInstance) string {
+	t.AddColumn("MACHINE-TYPE", func(i *cloudinstances.InstanceSummary) string {
 		return i.MachineType
 	})
-	t.AddColumn("STATE", func(i *cloudinstances.CloudInstance) string {
-		return string(i.State)
+	t.AddColumn("STATE", func(i *cloudinstances.InstanceSummary) string {
+		return i.State
+	})
+	t.AddColumn("VERSION", func(i *cloudinstances.InstanceSummary) string {
+		return i.KubeletVersion
+	})
+	t.AddColumn("AGE", func(i *cloudinstances.InstanceSummary) string {
+		if i.CreationTimestamp == nil {
+			return "-"
+		}
+		return duration.HumanDuration(time.Since(i.CreationTimestamp.Time))
+	})
+	t.AddColumn("NEEDS-UPDATE-REASON", func(i *cloudinstances.InstanceSummary) string {
+		return i.NeedsUpdateReason
+	})
+	t.AddColumn("OS-IMAGE", func(i *cloudinstances.InstanceSummary) string {
+		return i.OSImage
+	})
+	t.AddColumn("TAINTS", func(i *cloudinstances.InstanceSummary) string {
+		return strings.Join(i.Taints, ", ")
 	})
 
-	columns := []string{"ID", "NODE-NAME", "STATUS", "ROLES", "STATE", "INTERNAL-IP", "INSTANCE-GROUP", "MACHINE-TYPE"}
-	return t.Render(instances, os.Stdout, columns...)
+	columns := []string{"ID", "NODE-NAME", "STATUS", "READY", "ROLES", "STATE", "INTERNAL-IP", "INSTANCE-GROUP", "MACHINE-TYPE", "VERSION", "AGE"}
+	if wide {
+		columns = append(columns, "NEEDS-UPDATE-REASON", "OS-IMAGE", "TAINTS")
+	}
+	return t.Render(instances, out, columns...)
 }
 
 func createK8sClient(cluster *kops.Cluster) (*kubernetes.Clientset, error) {
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	importAssetsLong = pretty.LongDesc(i18n.T(`
+	Import image and file assets from a bundle into the local repositories
+	configured in the cluster spec.
+
+	The bundle is written by ` + pretty.Bash("kops get assets --export-bundle") + `. Assets which are
+	already present in the local repositories are skipped, so an interrupted import
+	can be resumed by running the command again.`))
+
+	importAssetsExample = templates.Examples(i18n.T(`
+	# Import assets from a bundle into the local repositories.
+	kops import assets bundle.tar \
+		--name k8s-cluster.example.com --state s3://my-state-store
+	`))
+
+	importAssetsShort = i18n.T(`Import assets for cluster from a bundle.`)
+)
+
+type ImportAssetsOptions struct {
+	ClusterName string
+	BundleFile  string
+}
+
+// NewCmdImportAssets returns an import assets command.
+func NewCmdImportAssets(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ImportAssetsOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "assets BUNDLE",
+		Short:   importAssetsShort,
+		Long:    importAssetsLong,
+		Example: importAssetsExample,
+		Args: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.ClusterName(true)
+
+			if options.ClusterName == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("must specify the bundle file to import")
+			}
+			if len(args) > 1 {
+				return fmt.Errorf("can only import one bundle at a time")
+			}
+
+			options.BundleFile = args[0]
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunImportAssets(context.TODO(), f, out, options)
+		},
+	}
+
+	return cmd
+}
+
+// RunImportAssets imports the assets in a bundle into the cluster's local repositories.
+func RunImportAssets(ctx context.Context, f *util.Factory, out io.Writer, options *ImportAssetsOptions) error {
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return fmt.Errorf("getting cluster: %q: %v", options.ClusterName, err)
+	}
+
+	if err := assets.ImportBundle(options.BundleFile, cluster); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Imported assets from %s\n", options.BundleFile)
+	return nil
+}
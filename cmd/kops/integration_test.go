@@ -227,6 +227,24 @@ func TestMinimalIPv6(t *testing.T) {
 	newIntegrationTest("minimal-ipv6.example.com", "minimal-ipv6").runTestCloudformation(t)
 }
 
+// TestMinimalIPv6Private runs the test on a private IPv6 configuration, where pods and services use IPv6
+func TestMinimalIPv6Private(t *testing.T) {
+	featureflag.ParseFlags("+AWSIPv6")
+	unsetFeatureFlags := func() {
+		featureflag.ParseFlags("-AWSIPv6")
+	}
+	defer unsetFeatureFlags()
+
+	newIntegrationTest("minimal-ipv6-private.example.com", "minimal-ipv6-private").
+		withPrivate().
+		withAddons("networking.projectcalico.org-k8s-1.16").
+		runTestTerraformAWS(t)
+	newIntegrationTest("minimal-ipv6-private.example.com", "minimal-ipv6-private").
+		withPrivate().
+		withAddons("networking.projectcalico.org-k8s-1.16").
+		runTestCloudformation(t)
+}
+
 // TestMinimalWarmPool runs the test on a minimum Warm Pool configuration
 func TestMinimalWarmPool(t *testing.T) {
 	newIntegrationTest("minimal-warmpool.example.com", "minimal-warmpool").
@@ -120,6 +120,20 @@ func TestLifecycleIPv6(t *testing.T) {
 	})
 }
 
+// TestLifecyclePrivateIPv6 runs the test on a private IPv6 topology, where pods and services use IPv6
+func TestLifecyclePrivateIPv6(t *testing.T) {
+	featureflag.ParseFlags("+AWSIPv6")
+	unsetFeatureFlags := func() {
+		featureflag.ParseFlags("-AWSIPv6")
+	}
+	defer unsetFeatureFlags()
+
+	runLifecycleTestAWS(&LifecycleTestOptions{
+		t:      t,
+		SrcDir: "minimal-ipv6-private",
+	})
+}
+
 // TestLifecycleSharedVPC runs the test on a shared VPC
 func TestLifecycleSharedVPC(t *testing.T) {
 	runLifecycleTestAWS(&LifecycleTestOptions{
@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/commands"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/pretty"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	migrateDNSLong = pretty.LongDesc(i18n.T(`
+	Migrate a cluster between gossip DNS and a DNS zone.
+
+	The migration is done in two steps. The first step switches the cluster spec
+	to the new API names, while keeping the API server certificates valid for
+	the previous names, and then updates the cluster and replaces all instances.
+	Instances which have not yet been replaced keep reaching the API servers using
+	the previous names.
+
+	Once the first step has completed and the cluster has validated, run the command
+	again with ` + pretty.Bash("--finish") + ` to drop the previous names.
+
+	Without ` + pretty.Bash("--yes") + ` the changes to the cluster spec are only displayed.`))
+
+	migrateDNSExample = templates.Examples(i18n.T(`
+	# Migrate a gossip cluster to the example.com DNS zone.
+	kops migrate dns --name k8s-cluster.k8s.local --dns-zone example.com --yes
+
+	# Drop the gossip names once all instances have been replaced.
+	kops migrate dns --name k8s-cluster.k8s.local --finish --yes
+
+	# Migrate a cluster from its DNS zone to gossip.
+	kops migrate dns --name k8s-cluster.example.com --to-gossip --yes
+	`))
+
+	migrateDNSShort = i18n.T(`Migrate a cluster between gossip and DNS.`)
+)
+
+type MigrateDNSOptions struct {
+	commands.MigrateDNSOptions
+
+	ClusterName string
+	Yes         bool
+	Finish      bool
+}
+
+func NewCmdMigrateDNS(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &MigrateDNSOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "dns [CLUSTER]",
+		Short:             migrateDNSShort,
+		Long:              migrateDNSLong,
+		Example:           migrateDNSExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(&rootCommand, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunMigrateDNS(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Apply the migration, updating the cluster and replacing its instances")
+	cmd.Flags().StringVar(&options.DNSZone, "dns-zone", options.DNSZone, "DNS zone to migrate the cluster to")
+	cmd.Flags().StringVar(&options.DNSName, "dns-name", options.DNSName, "Name under which the API is published in the DNS zone (defaults to the cluster name without .k8s.local, in the DNS zone)")
+	cmd.Flags().BoolVar(&options.ToGossip, "to-gossip", options.ToGossip, "Migrate the cluster from its DNS zone to gossip")
+	cmd.Flags().BoolVar(&options.Finish, "finish", options.Finish, "Finish a migration, dropping the previous names")
+
+	return cmd
+}
+
+// dnsMigrationChange is a change to the cluster spec made by the migration
+type dnsMigrationChange struct {
+	Property string
+	Old      string
+	New      string
+}
+
+func RunMigrateDNS(ctx context.Context, f *util.Factory, out io.Writer, options *MigrateDNSOptions) error {
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := rootCommand.Clientset()
+	if err != nil {
+		return err
+	}
+
+	instanceGroups, err := commands.ReadAllInstanceGroups(ctx, clientset, cluster)
+	if err != nil {
+		return err
+	}
+
+	original := cluster.DeepCopy()
+
+	if options.Finish {
+		if options.ToGossip || options.DNSZone != "" || options.DNSName != "" {
+			return fmt.Errorf("--finish cannot be combined with other migration flags")
+		}
+		if err := commands.FinishDNSMigration(cluster); err != nil {
+			return err
+		}
+	} else {
+		cloud, err := cloudup.BuildCloud(cluster)
+		if err != nil {
+			return err
+		}
+
+		fullCluster := cluster.DeepCopy()
+		if err := cloudup.PerformAssignments(fullCluster, cloud); err != nil {
+			return fmt.Errorf("error populating configuration: %v", err)
+		}
+		fullCluster, err = cloudup.PopulateClusterSpec(clientset, fullCluster, cloud, assets.NewAssetBuilder(fullCluster, false))
+		if err != nil {
+			return err
+		}
+
+		if err := commands.StartDNSMigration(cluster, fullCluster, &options.MigrateDNSOptions); err != nil {
+			return err
+		}
+	}
+
+	if err := renderDNSMigrationChanges(original, cluster, out); err != nil {
+		return err
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to apply the migration\n")
+		return nil
+	}
+
+	if err := commands.UpdateCluster(ctx, clientset, cluster, instanceGroups); err != nil {
+		return err
+	}
+
+	updateOptions := &UpdateClusterOptions{}
+	updateOptions.InitDefaults()
+	updateOptions.ClusterName = cluster.ObjectMeta.Name
+	updateOptions.Yes = true
+	if _, err := RunUpdateCluster(ctx, f, out, updateOptions); err != nil {
+		return err
+	}
+
+	// All instances are replaced, as the API server certificates and the way instances reach the API servers have changed.
+	rollingUpdateOptions := &RollingUpdateOptions{}
+	rollingUpdateOptions.InitDefaults()
+	rollingUpdateOptions.ClusterName = cluster.ObjectMeta.Name
+	rollingUpdateOptions.Yes = true
+	rollingUpdateOptions.Force = true
+	rollingUpdateOptions.FailOnDrainError = true
+	if err := RunRollingUpdateCluster(ctx, f, out, rollingUpdateOptions); err != nil {
+		return fmt.Errorf("error replacing instances, run `kops rolling-update cluster --force` to resume: %v", err)
+	}
+
+	if options.Finish {
+		fmt.Fprintf(out, "\nThe DNS migration of cluster %q is complete.\n", cluster.ObjectMeta.Name)
+	} else {
+		fmt.Fprintf(out, "\nAll instances of cluster %q use the new names.\n", cluster.ObjectMeta.Name)
+		fmt.Fprintf(out, "Once the cluster validates, drop the previous names using `kops migrate dns --name %s --finish --yes`\n", cluster.ObjectMeta.Name)
+	}
+
+	return nil
+}
+
+func renderDNSMigrationChanges(original, cluster *kopsapi.Cluster, out io.Writer) error {
+	var changes []*dnsMigrationChange
+	addChange := func(property, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &dnsMigrationChange{Property: property, Old: oldValue, New: newValue})
+		}
+	}
+
+	issuer := func(c *kopsapi.Cluster) string {
+		if c.Spec.KubeAPIServer == nil {
+			return ""
+		}
+		return fi.StringValue(c.Spec.KubeAPIServer.ServiceAccountIssuer)
+	}
+
+	addChange("DNSZone", original.Spec.DNSZone, cluster.Spec.DNSZone)
+	addChange("MasterPublicName", original.Spec.MasterPublicName, cluster.Spec.MasterPublicName)
+	addChange("MasterInternalName", original.Spec.MasterInternalName, cluster.Spec.MasterInternalName)
+	addChange("AdditionalSANs", strings.Join(original.Spec.AdditionalSANs, ","), strings.Join(cluster.Spec.AdditionalSANs, ","))
+	addChange("KubeAPIServer.ServiceAccountIssuer", issuer(original), issuer(cluster))
+
+	t := &tables.Table{}
+	t.AddColumn("PROPERTY", func(c *dnsMigrationChange) string {
+		return c.Property
+	})
+	t.AddColumn("OLD", func(c *dnsMigrationChange) string {
+		return c.Old
+	})
+	t.AddColumn("NEW", func(c *dnsMigrationChange) string {
+		return c.New
+	})
+	return t.Render(changes, out, "PROPERTY", "OLD", "NEW")
+}
@@ -37,6 +37,7 @@ import (
 	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/commands"
 	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
 )
@@ -75,6 +76,13 @@ type RootCmd struct {
 
 	clusterName string
 
+	// cloudAPIStats prints a summary of the calls to the cloud APIs when the command completes
+	cloudAPIStats bool
+	// cloudAPIQPS is the budget of cloud API requests per second, shared by all the tasks; 0 means no budget
+	cloudAPIQPS float32
+	// cloudAPIBurst is the number of cloud API requests allowed above the budget in bursts
+	cloudAPIBurst int
+
 	cobraCommand *cobra.Command
 }
 
@@ -94,13 +102,17 @@ var rootCommand = RootCmd{
 func Execute() {
 	goflag.Set("logtostderr", "true")
 	goflag.CommandLine.Parse([]string{})
-	if err := rootCommand.cobraCommand.Execute(); err != nil {
+	err := rootCommand.cobraCommand.Execute()
+	if rootCommand.cloudAPIStats {
+		printCloudAPIStats(os.Stderr)
+	}
+	if err != nil {
 		exitWithError(err)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initConfig, initCloudAPI)
 
 	klog.InitFlags(nil)
 
@@ -120,6 +132,8 @@ func NewCmdRoot(f *util.Factory, out io.Writer) *cobra.Command {
 		case "cloud-provider-gce-lb-src-cidrs":
 		case "cloud-provider-gce-l7lb-src-cidrs":
 			// Skip; these is dragged in by the google cloudprovider dependency
+		case "kubeconfig":
+			// Skip; this is dragged in by the envtest dependency of "kops toolbox fake-cloud"
 
 		default:
 			cmd.PersistentFlags().AddGoFlag(goflag)
@@ -142,6 +156,10 @@ func NewCmdRoot(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&rootCommand.clusterName, "name", "", defaultClusterName, "Name of cluster. Overrides KOPS_CLUSTER_NAME environment variable")
 	cmd.RegisterFlagCompletionFunc("name", commandutils.CompleteClusterName(&rootCommand, false, false))
 
+	cmd.PersistentFlags().BoolVar(&rootCommand.cloudAPIStats, "cloud-api-stats", false, "Print a summary of the calls to the cloud APIs when the command completes")
+	cmd.PersistentFlags().Float32Var(&rootCommand.cloudAPIQPS, "cloud-api-qps", 0, "Maximum number of cloud API requests per second, shared by all the tasks. 0 for no limit")
+	cmd.PersistentFlags().IntVar(&rootCommand.cloudAPIBurst, "cloud-api-burst", 10, "Maximum number of cloud API requests above --cloud-api-qps in bursts")
+
 	// create subcommands
 	cmd.AddCommand(NewCmdCreate(f, out))
 	cmd.AddCommand(NewCmdDelete(f, out))
@@ -151,6 +169,8 @@ func NewCmdRoot(f *util.Factory, out io.Writer) *cobra.Command {
 	cmd.AddCommand(NewCmdGenCLIDocs(f, out))
 	cmd.AddCommand(NewCmdGet(f, out))
 	cmd.AddCommand(commands.NewCmdHelpers(f, out))
+	cmd.AddCommand(NewCmdImport(f, out))
+	cmd.AddCommand(NewCmdMigrate(f, out))
 	cmd.AddCommand(NewCmdPromote(f, out))
 	cmd.AddCommand(NewCmdReplace(f, out))
 	cmd.AddCommand(NewCmdRollingUpdate(f, out))
@@ -164,6 +184,11 @@ func NewCmdRoot(f *util.Factory, out io.Writer) *cobra.Command {
 	return cmd
 }
 
+// initCloudAPI sets the budget of cloud API requests shared by all the tasks
+func initCloudAPI() {
+	awsup.SetAPIRateLimit(rootCommand.cloudAPIQPS, rootCommand.cloudAPIBurst)
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	// Config file precedence: --config flag, ${HOME}/.kops.yaml ${HOME}/.kops/config
@@ -35,8 +35,12 @@ func NewCmdToolbox(f *util.Factory, out io.Writer) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdToolboxDump(f, out))
+	cmd.AddCommand(NewCmdToolboxGossip(f, out))
 	cmd.AddCommand(NewCmdToolboxTemplate(f, out))
 	cmd.AddCommand(NewCmdToolboxInstanceSelector(f, out))
+	cmd.AddCommand(NewCmdToolboxFindOrphans(f, out))
+	cmd.AddCommand(NewCmdToolboxReconcile(f, out))
+	cmd.AddCommand(NewCmdToolboxFakeCloud(f, out))
 
 	return cmd
 }
@@ -0,0 +1,233 @@
+// +build fakecloud
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cloudmock/aws/fakecloud"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+var (
+	toolboxFakeCloudLong = templates.LongDesc(i18n.T(`
+	Serves a simulated AWS cloud over the AWS APIs, to run kops commands end to end without an AWS account.
+
+	The EC2, Auto Scaling, ELB, IAM, Route53 and STS APIs are served from the in-memory mocks used by
+	the tests of kops. Point kops at the fake cloud by setting ` + awsup.EnvEndpointURL + ` to its address,
+	together with dummy AWS credentials. The autoscaling groups launch instances from their launch
+	templates, and replace the instances which are terminated.
+
+	With --kubernetes, a local Kubernetes API server is started as well, and every running instance
+	registers a ready node in it. This requires the kube-apiserver and etcd binaries in the directory
+	set by KUBEBUILDER_ASSETS. The kubeconfig of the API server is written to --kubeconfig, with a
+	context named after the cluster, so that "kops validate cluster" and "kops rolling-update cluster"
+	can use it.
+
+	The state of the fake cloud is lost when the command exits.`))
+
+	toolboxFakeCloudExample = templates.Examples(i18n.T(`
+	# Serve a fake cloud with fake nodes for the cluster fake.k8s.local
+	kops toolbox fake-cloud --name fake.k8s.local --kubernetes --kubeconfig /tmp/fake.kubeconfig
+
+	# In another terminal, run kops against it
+	export AWS_ENDPOINT_URL=http://127.0.0.1:8080 AWS_ACCESS_KEY_ID=fake AWS_SECRET_ACCESS_KEY=fake
+	export KOPS_STATE_STORE=file:///tmp/fake-state KUBECONFIG=/tmp/fake.kubeconfig
+	kops create cluster --cloud aws --name fake.k8s.local --zones us-test-1a
+	kops update cluster --name fake.k8s.local --yes --create-kube-config=false
+	kops validate cluster --name fake.k8s.local --wait 5m
+	`))
+
+	toolboxFakeCloudShort = i18n.T(`Serve a simulated AWS cloud for local end-to-end tests`)
+)
+
+type ToolboxFakeCloudOptions struct {
+	ClusterName string
+
+	// Listen is the address the AWS APIs are served on
+	Listen string
+
+	Region      string
+	Zones       []string
+	HostedZones []string
+
+	// Kubernetes starts a Kubernetes API server, in which the instances register nodes
+	Kubernetes bool
+	Kubeconfig string
+	BootDelay  time.Duration
+
+	// ReconcileInterval is how often the autoscaling groups and the nodes are reconciled
+	ReconcileInterval time.Duration
+}
+
+func (o *ToolboxFakeCloudOptions) InitDefaults() {
+	o.Listen = "127.0.0.1:8080"
+	o.Region = "us-test-1"
+	o.Kubeconfig = "fake-cloud.kubeconfig"
+	o.BootDelay = 10 * time.Second
+	o.ReconcileInterval = 5 * time.Second
+}
+
+func NewCmdToolboxFakeCloud(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxFakeCloudOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "fake-cloud",
+		Short:   toolboxFakeCloudShort,
+		Long:    toolboxFakeCloudLong,
+		Example: toolboxFakeCloudExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.ClusterName = rootCommand.clusterName
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			return RunToolboxFakeCloud(ctx, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Listen, "listen", options.Listen, "Address to serve the AWS APIs on")
+	cmd.RegisterFlagCompletionFunc("listen", cobra.NoFileCompletions)
+	cmd.Flags().StringVar(&options.Region, "region", options.Region, "Region of the fake cloud")
+	cmd.RegisterFlagCompletionFunc("region", cobra.NoFileCompletions)
+	cmd.Flags().StringSliceVar(&options.Zones, "zones", options.Zones, "Availability zones of the region. Defaults to the zones a, b and c of the region")
+	cmd.RegisterFlagCompletionFunc("zones", cobra.NoFileCompletions)
+	cmd.Flags().StringSliceVar(&options.HostedZones, "dns-zone", options.HostedZones, "Public Route53 hosted zones to create")
+	cmd.RegisterFlagCompletionFunc("dns-zone", cobra.NoFileCompletions)
+
+	cmd.Flags().BoolVar(&options.Kubernetes, "kubernetes", options.Kubernetes, "Start a Kubernetes API server in which the running instances register nodes")
+	cmd.Flags().StringVar(&options.Kubeconfig, "kubeconfig", options.Kubeconfig, "File to write the kubeconfig of the Kubernetes API server to")
+	cmd.Flags().DurationVar(&options.BootDelay, "boot-delay", options.BootDelay, "Time between the launch of an instance and the registration of its node")
+	cmd.Flags().DurationVar(&options.ReconcileInterval, "reconcile-interval", options.ReconcileInterval, "Interval between the reconciliations of the instances and the nodes")
+
+	return cmd
+}
+
+func RunToolboxFakeCloud(ctx context.Context, out io.Writer, options *ToolboxFakeCloudOptions) error {
+	serverOptions := fakecloud.Options{
+		Region:      options.Region,
+		Zones:       options.Zones,
+		HostedZones: options.HostedZones,
+		BootDelay:   options.BootDelay,
+	}
+
+	if options.Kubernetes {
+		env := &envtest.Environment{}
+		restConfig, err := env.Start()
+		if err != nil {
+			return fmt.Errorf("error starting the Kubernetes API server (is KUBEBUILDER_ASSETS set?): %v", err)
+		}
+		defer func() {
+			if err := env.Stop(); err != nil {
+				klog.Warningf("error stopping the Kubernetes API server: %v", err)
+			}
+		}()
+
+		client, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return fmt.Errorf("error building Kubernetes client: %v", err)
+		}
+		serverOptions.Nodes = client
+
+		contextName := options.ClusterName
+		if contextName == "" {
+			contextName = "fake-cloud"
+		}
+		if err := writeFakeCloudKubeconfig(options.Kubeconfig, contextName, restConfig); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Kubernetes API server at %s, kubeconfig written to %s\n", restConfig.Host, options.Kubeconfig)
+	}
+
+	server, err := fakecloud.NewServer(serverOptions)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", options.Listen)
+	if err != nil {
+		return fmt.Errorf("error listening on %q: %v", options.Listen, err)
+	}
+	httpServer := &http.Server{Handler: server}
+	go func() {
+		<-ctx.Done()
+		if err := httpServer.Close(); err != nil {
+			klog.Warningf("error stopping the server: %v", err)
+		}
+	}()
+	go server.Run(ctx, options.ReconcileInterval)
+
+	fmt.Fprintf(out, "Serving the fake cloud in region %s on http://%s\n", options.Region, listener.Addr())
+	fmt.Fprintf(out, "Use it with:\n  export %s=http://%s AWS_ACCESS_KEY_ID=fake AWS_SECRET_ACCESS_KEY=fake\n", awsup.EnvEndpointURL, listener.Addr())
+
+	if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// writeFakeCloudKubeconfig writes a kubeconfig for the Kubernetes API server, with a single context
+func writeFakeCloudKubeconfig(path string, contextName string, restConfig *rest.Config) error {
+	host := restConfig.Host
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[contextName] = &clientcmdapi.Cluster{
+		Server:                   host,
+		CertificateAuthorityData: restConfig.CAData,
+	}
+	config.AuthInfos[contextName] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: restConfig.CertData,
+		ClientKeyData:         restConfig.KeyData,
+		Token:                 restConfig.BearerToken,
+	}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:  contextName,
+		AuthInfo: contextName,
+	}
+	config.CurrentContext = contextName
+
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		return fmt.Errorf("error writing kubeconfig %q: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// +build !fakecloud
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+)
+
+// NewCmdToolboxFakeCloud returns a hidden placeholder for "kops toolbox fake-cloud".
+// The fake cloud links a local Kubernetes API server, so it is only built with the fakecloud build tag.
+func NewCmdToolboxFakeCloud(f *util.Factory, out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:    "fake-cloud",
+		Short:  "Serve a simulated AWS cloud for local end-to-end tests",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("kops was built without the fake cloud; rebuild it with \"go build -tags fakecloud ./cmd/kops\"")
+		},
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	resourceops "k8s.io/kops/pkg/resources/ops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxFindOrphansLong = templates.LongDesc(i18n.T(`
+	Finds the cloud resources of clusters which are not in the state store.
+
+	On AWS, the clusters are discovered from the EC2 resources having both a KubernetesCluster tag
+	and a tag only set by kOps, such as the role tags of the instances or the etcd tags of the volumes.
+	On GCE, they are discovered from the cluster-name metadata of the instance templates.
+	The resources of each cluster missing from the state store are collected the same way as by
+	"kops delete cluster".
+
+	As the discovery only looks at EC2 resources on AWS, the IAM, Route53, SQS and EventBridge
+	resources of a cluster without any EC2 resources left are not found. Name the cluster with
+	--cluster to find them.
+
+	--yes requires --cluster, so that only the resources of the named clusters are deleted.
+	The clusters must not be in the state store.`))
+
+	toolboxFindOrphansExample = templates.Examples(i18n.T(`
+	# List the resources of the clusters in us-east-1 which are not in the state store
+	kops toolbox find-orphans --region us-east-1
+
+	# Delete the resources left behind by a cluster
+	kops toolbox find-orphans --region us-east-1 --cluster old.example.com --yes
+
+	# List the orphaned resources of a GCE project
+	kops toolbox find-orphans --cloud gce --project my-project --region us-central1
+	`))
+
+	toolboxFindOrphansShort = i18n.T(`Find cloud resources of clusters which are not in the state store`)
+)
+
+type ToolboxFindOrphansOptions struct {
+	CloudProvider string
+	Region        string
+	Project       string
+
+	// Clusters limits the search to these cluster names
+	Clusters []string
+
+	Output string
+	Yes    bool
+}
+
+func (o *ToolboxFindOrphansOptions) InitDefaults() {
+	o.CloudProvider = string(kops.CloudProviderAWS)
+	o.Output = OutputTable
+}
+
+// orphanedResource is a resource of a cluster which is not in the state store
+type orphanedResource struct {
+	Cluster string `json:"cluster"`
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+}
+
+func NewCmdToolboxFindOrphans(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxFindOrphansOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "find-orphans",
+		Short:   toolboxFindOrphansShort,
+		Long:    toolboxFindOrphansLong,
+		Example: toolboxFindOrphansExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxFindOrphans(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.CloudProvider, "cloud", options.CloudProvider, "Cloud provider to scan. One of: aws, gce")
+	cmd.RegisterFlagCompletionFunc("cloud", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(kops.CloudProviderAWS), string(kops.CloudProviderGCE)}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringVar(&options.Region, "region", options.Region, "Cloud region to scan")
+	cmd.RegisterFlagCompletionFunc("region", completeRegion)
+	cmd.Flags().StringVar(&options.Project, "project", options.Project, "GCE project to scan")
+	cmd.RegisterFlagCompletionFunc("project", cobra.NoFileCompletions)
+	cmd.Flags().StringSliceVar(&options.Clusters, "cluster", options.Clusters, "Only consider the resources of these clusters, instead of discovering them. Required with --yes")
+	cmd.RegisterFlagCompletionFunc("cluster", cobra.NoFileCompletions)
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format. One of: table, json")
+	cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{OutputTable, OutputJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().BoolVarP(&options.Yes, "yes", "y", options.Yes, "Delete the resources of the clusters which are not in the state store")
+
+	return cmd
+}
+
+func RunToolboxFindOrphans(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxFindOrphansOptions) error {
+	if options.Region == "" {
+		return fmt.Errorf("--region is required")
+	}
+	if options.Output != OutputTable && options.Output != OutputJSON {
+		return fmt.Errorf("unsupported output format %q, one of %s or %s is expected", options.Output, OutputTable, OutputJSON)
+	}
+	if options.Output != OutputTable && options.Yes {
+		return fmt.Errorf("--output=%s cannot be used with --yes", options.Output)
+	}
+	if options.Yes && len(options.Clusters) == 0 {
+		return fmt.Errorf("--cluster is required with --yes")
+	}
+
+	var cloud fi.Cloud
+	var err error
+	switch kops.CloudProviderID(options.CloudProvider) {
+	case kops.CloudProviderAWS:
+		cloud, err = awsup.NewAWSCloud(options.Region, nil)
+		if err != nil {
+			return fmt.Errorf("error initializing AWS client: %v", err)
+		}
+	case kops.CloudProviderGCE:
+		if options.Project == "" {
+			return fmt.Errorf("--project is required (when --cloud=gce)")
+		}
+		cloud, err = gce.NewGCECloud(options.Region, options.Project, nil)
+		if err != nil {
+			return fmt.Errorf("error initializing GCE client: %v", err)
+		}
+	default:
+		return fmt.Errorf("finding orphaned resources on %q not (yet) supported", options.CloudProvider)
+	}
+
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+	list, err := clientset.ListClusters(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var knownClusters []string
+	for _, cluster := range list.Items {
+		knownClusters = append(knownClusters, cluster.ObjectMeta.Name)
+	}
+
+	orphans, err := resourceops.FindOrphanedResources(cloud, options.Region, knownClusters, options.Clusters)
+	if err != nil {
+		return err
+	}
+
+	var clusterNames []string
+	var items []*orphanedResource
+	for clusterName, clusterResources := range orphans {
+		clusterNames = append(clusterNames, clusterName)
+		for _, r := range clusterResources {
+			items = append(items, &orphanedResource{
+				Cluster: clusterName,
+				Type:    r.Type,
+				ID:      r.ID,
+				Name:    r.Name,
+			})
+		}
+	}
+	sort.Strings(clusterNames)
+
+	if options.Output == OutputJSON {
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Cluster != items[j].Cluster {
+				return items[i].Cluster < items[j].Cluster
+			}
+			return items[i].Type+":"+items[i].ID < items[j].Type+":"+items[j].ID
+		})
+		if items == nil {
+			items = []*orphanedResource{}
+		}
+		b, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling resources to json: %v", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintf(out, "No orphaned cloud resources found\n")
+		return nil
+	}
+
+	t := &tables.Table{}
+	t.AddColumn("CLUSTER", func(r *orphanedResource) string {
+		return r.Cluster
+	})
+	t.AddColumn("TYPE", func(r *orphanedResource) string {
+		return r.Type
+	})
+	t.AddColumn("ID", func(r *orphanedResource) string {
+		return r.ID
+	})
+	t.AddColumn("NAME", func(r *orphanedResource) string {
+		return r.Name
+	})
+	if err := t.Render(items, out, "CLUSTER", "TYPE", "NAME", "ID"); err != nil {
+		return err
+	}
+
+	if !options.Yes {
+		fmt.Fprintf(out, "\nMust specify --yes to delete the resources\n")
+		return nil
+	}
+
+	for _, clusterName := range clusterNames {
+		fmt.Fprintf(out, "\nDeleting resources of cluster %q\n", clusterName)
+		if err := resourceops.DeleteResources(resourceops.CloudForClusterName(cloud, clusterName), orphans[clusterName]); err != nil {
+			return fmt.Errorf("error deleting resources of cluster %q: %v", clusterName, err)
+		}
+	}
+
+	return nil
+}
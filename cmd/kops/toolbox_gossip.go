@@ -0,0 +1,448 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/commands/commandutils"
+	"k8s.io/kops/pkg/resources"
+	resourceops "k8s.io/kops/pkg/resources/ops"
+	"k8s.io/kops/pkg/wellknownports"
+	gossipdebug "k8s.io/kops/protokube/pkg/gossip/debug"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/util/pkg/tables"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxGossipLong = templates.LongDesc(i18n.T(`
+	Inspect the gossip state of a cluster using gossip DNS.
+
+	Gathers the gossip values, peers and DNS records from protokube on each
+	control plane node and reports where the nodes disagree.
+
+	Protokube only serves the state on localhost. By default it is fetched by
+	port-forwarding through the kops-controller pod of each control plane node,
+	which requires permission to port-forward to pods in kube-system.
+	If the API server is not reachable, use --ssh to fetch the state over SSH
+	from the public addresses of the control plane instances.`))
+
+	toolboxGossipExample = templates.Examples(i18n.T(`
+	# Report gossip disagreements between the control plane nodes
+	kops toolbox gossip --name k8s-cluster.k8s.local
+
+	# Fetch the gossip state over SSH
+	kops toolbox gossip --name k8s-cluster.k8s.local --ssh --ssh-user ubuntu
+
+	# Dump the full gossip state of every node
+	kops toolbox gossip --name k8s-cluster.k8s.local -o yaml
+	`))
+
+	toolboxGossipShort = i18n.T(`Inspect gossip DNS state`)
+)
+
+type ToolboxGossipOptions struct {
+	Output string
+
+	ClusterName string
+
+	SSH        bool
+	PrivateKey string
+	SSHUser    string
+}
+
+func (o *ToolboxGossipOptions) InitDefaults() {
+	o.Output = OutputTable
+	o.PrivateKey = "~/.ssh/id_rsa"
+	o.SSHUser = "ubuntu"
+}
+
+// gossipReport is the result of inspecting the gossip state of the control plane nodes.
+type gossipReport struct {
+	Nodes         map[string]*gossipdebug.NodeState `json:"nodes,omitempty"`
+	Errors        map[string]string                 `json:"errors,omitempty"`
+	Disagreements []gossipdebug.Disagreement        `json:"disagreements,omitempty"`
+}
+
+func NewCmdToolboxGossip(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxGossipOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:               "gossip [CLUSTER]",
+		Short:             toolboxGossipShort,
+		Long:              toolboxGossipLong,
+		Example:           toolboxGossipExample,
+		Args:              rootCommand.clusterNameArgs(&options.ClusterName),
+		ValidArgsFunction: commandutils.CompleteClusterName(&rootCommand, true, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxGossip(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format.  One of table, json or yaml")
+	cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{OutputTable, OutputJSON, OutputYaml}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	cmd.Flags().BoolVar(&options.SSH, "ssh", options.SSH, "Fetch the gossip state over SSH instead of port-forwarding through the API server")
+	cmd.Flags().StringVar(&options.PrivateKey, "private-key", options.PrivateKey, "File containing private key to use for SSH access to instances")
+	cmd.Flags().StringVar(&options.SSHUser, "ssh-user", options.SSHUser, "The remote user for SSH access to instances")
+	cmd.RegisterFlagCompletionFunc("ssh-user", cobra.NoFileCompletions)
+
+	return cmd
+}
+
+func RunToolboxGossip(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxGossipOptions) error {
+	cluster, err := GetCluster(ctx, f, options.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	if !cluster.IsGossip() {
+		return fmt.Errorf("cluster %q does not use gossip DNS", cluster.ObjectMeta.Name)
+	}
+
+	report := &gossipReport{
+		Nodes:  make(map[string]*gossipdebug.NodeState),
+		Errors: make(map[string]string),
+	}
+
+	var fetched map[string][]byte
+	if options.SSH {
+		fetched, err = fetchGossipStateSSH(ctx, cluster, options, report.Errors)
+	} else {
+		fetched, err = fetchGossipStatePortForward(ctx, cluster, report.Errors)
+	}
+	if err != nil {
+		return err
+	}
+
+	for node, b := range fetched {
+		state := &gossipdebug.NodeState{}
+		if err := json.Unmarshal(b, state); err != nil {
+			report.Errors[node] = fmt.Sprintf("error parsing gossip state: %v", err)
+			continue
+		}
+		report.Nodes[node] = state
+	}
+
+	if len(report.Nodes) == 0 && len(report.Errors) == 0 {
+		return fmt.Errorf("no control plane nodes found")
+	}
+
+	report.Disagreements = gossipdebug.FindDisagreements(report.Nodes)
+
+	switch options.Output {
+	case OutputTable:
+		return renderGossipReport(report, out)
+
+	case OutputYaml:
+		b, err := kops.ToRawYaml(report)
+		if err != nil {
+			return fmt.Errorf("error marshaling yaml: %v", err)
+		}
+		_, err = out.Write(b)
+		return err
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling json: %v", err)
+		}
+		_, err = out.Write(b)
+		return err
+
+	default:
+		return fmt.Errorf("unsupported output format: %q", options.Output)
+	}
+}
+
+// fetchGossipStatePortForward fetches the gossip state of the control plane nodes by port-forwarding
+// to the kops-controller pods, which share the host network of the control plane nodes with protokube.
+func fetchGossipStatePortForward(ctx context.Context, cluster *kops.Cluster, errors map[string]string) (map[string][]byte, error) {
+	contextName := cluster.ObjectMeta.Name
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load kubecfg settings for %q: %v", contextName, err)
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build kubernetes api client for %q: %v", contextName, err)
+	}
+
+	pods, err := k8sClient.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: "k8s-app=kops-controller"})
+	if err != nil {
+		return nil, fmt.Errorf("error listing kops-controller pods: %v (use --ssh if the API server is not reachable)", err)
+	}
+
+	fetched := make(map[string][]byte)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		node := pod.Spec.NodeName
+		if node == "" {
+			continue
+		}
+		if pod.Status.Phase != v1.PodRunning {
+			errors[node] = fmt.Sprintf("kops-controller pod %q is %s", pod.Name, pod.Status.Phase)
+			continue
+		}
+
+		klog.V(2).Infof("fetching gossip state from node %q through pod %q", node, pod.Name)
+		b, err := portForwardGet(config, k8sClient, pod, wellknownports.ProtokubeGossipDebug, gossipdebug.Path)
+		if err != nil {
+			errors[node] = fmt.Sprintf("error fetching gossip state: %v", err)
+			continue
+		}
+		fetched[node] = b
+	}
+	return fetched, nil
+}
+
+// portForwardGet port-forwards to the port of the pod, and returns the body of an HTTP GET of the path through it.
+func portForwardGet(config *rest.Config, k8sClient kubernetes.Interface, pod *v1.Pod, port int, path string) ([]byte, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building port-forward transport: %v", err)
+	}
+	req := k8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{"0:" + strconv.Itoa(port)}, stopCh, readyCh, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("error port-forwarding to pod %q: %v", pod.Name, err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return nil, fmt.Errorf("error port-forwarding to pod %q: %v", pod.Name, err)
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		return nil, fmt.Errorf("error getting forwarded port for pod %q: %v", pod.Name, err)
+	}
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", ports[0].Local, path))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// fetchGossipStateSSH fetches the gossip state of the control plane instances over SSH.
+func fetchGossipStateSSH(ctx context.Context, cluster *kops.Cluster, options *ToolboxGossipOptions, errors map[string]string) (map[string][]byte, error) {
+	privateKeyPath := options.PrivateKey
+	if strings.HasPrefix(privateKeyPath, "~/") {
+		privateKeyPath = filepath.Join(os.Getenv("HOME"), privateKeyPath[2:])
+	}
+	key, err := ioutil.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading private key %q: %v", privateKeyPath, err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key %q: %v", privateKeyPath, err)
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User: options.SSHUser,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeys(signer),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return nil, err
+	}
+	resourceMap, err := resourceops.ListResources(cloud, cluster, "")
+	if err != nil {
+		return nil, err
+	}
+	d, err := resources.BuildDump(ctx, cloud, resourceMap)
+	if err != nil {
+		return nil, err
+	}
+
+	command := fmt.Sprintf("curl -s http://127.0.0.1:%d%s", wellknownports.ProtokubeGossipDebug, gossipdebug.Path)
+
+	fetched := make(map[string][]byte)
+	for _, instance := range d.Instances {
+		isMaster := false
+		for _, role := range instance.Roles {
+			if role == "master" {
+				isMaster = true
+			}
+		}
+		if !isMaster {
+			continue
+		}
+		if len(instance.PublicAddresses) == 0 {
+			errors[instance.Name] = "instance has no public address"
+			continue
+		}
+
+		klog.V(2).Infof("fetching gossip state from instance %q", instance.Name)
+		b, err := runSSHCommand(sshConfig, instance.PublicAddresses[0], command)
+		if err != nil {
+			errors[instance.Name] = fmt.Sprintf("error fetching gossip state: %v", err)
+			continue
+		}
+		fetched[instance.Name] = b
+	}
+	return fetched, nil
+}
+
+func runSSHCommand(sshConfig *ssh.ClientConfig, host string, command string) ([]byte, error) {
+	client, err := ssh.Dial("tcp", host+":22", sshConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %q: %v", host, err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("error creating ssh session: %v", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if err := session.Run(command); err != nil {
+		return nil, fmt.Errorf("error running %q: %v: %s", command, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+func renderGossipReport(report *gossipReport, out io.Writer) error {
+	var nodeNames []string
+	for node := range report.Nodes {
+		nodeNames = append(nodeNames, node)
+	}
+	sort.Strings(nodeNames)
+
+	nodeTable := &tables.Table{}
+	nodeTable.AddColumn("NODE", func(node string) string {
+		return node
+	})
+	nodeTable.AddColumn("VERSION", func(node string) string {
+		return strconv.FormatUint(report.Nodes[node].Version, 10)
+	})
+	nodeTable.AddColumn("PEERS", func(node string) string {
+		return strconv.Itoa(len(report.Nodes[node].Peers))
+	})
+	nodeTable.AddColumn("RECORDS", func(node string) string {
+		return strconv.Itoa(len(report.Nodes[node].DNSRecords))
+	})
+	if err := nodeTable.Render(nodeNames, out, "NODE", "VERSION", "PEERS", "RECORDS"); err != nil {
+		return fmt.Errorf("error rendering nodes table: %v", err)
+	}
+
+	if len(report.Errors) != 0 {
+		var failed []string
+		for node := range report.Errors {
+			failed = append(failed, node)
+		}
+		sort.Strings(failed)
+
+		fmt.Fprintf(out, "\nUNREACHABLE\n")
+		errorsTable := &tables.Table{}
+		errorsTable.AddColumn("NODE", func(node string) string {
+			return node
+		})
+		errorsTable.AddColumn("ERROR", func(node string) string {
+			return report.Errors[node]
+		})
+		if err := errorsTable.Render(failed, out, "NODE", "ERROR"); err != nil {
+			return fmt.Errorf("error rendering errors table: %v", err)
+		}
+	}
+
+	if len(report.Disagreements) == 0 {
+		fmt.Fprintf(out, "\nAll %d nodes agree on the gossip peers and DNS records.\n", len(report.Nodes))
+		return nil
+	}
+
+	fmt.Fprintf(out, "\nDISAGREEMENTS\n")
+	disagreementsTable := &tables.Table{}
+	disagreementsTable.AddColumn("KIND", func(d gossipdebug.Disagreement) string {
+		return d.Kind
+	})
+	disagreementsTable.AddColumn("KEY", func(d gossipdebug.Disagreement) string {
+		return d.Key
+	})
+	for _, node := range nodeNames {
+		node := node
+		disagreementsTable.AddColumn(node, func(d gossipdebug.Disagreement) string {
+			if v := d.Values[node]; v != "" {
+				return v
+			}
+			return "<missing>"
+		})
+	}
+	columns := append([]string{"KIND", "KEY"}, nodeNames...)
+	if err := disagreementsTable.Render(report.Disagreements, out, columns...); err != nil {
+		return fmt.Errorf("error rendering disagreements table: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kops/cmd/kops/util"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple/crdclientset"
+	"k8s.io/kops/pkg/reconciler"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	toolboxReconcileLong = templates.LongDesc(i18n.T(`
+	Applies the changes to the clusters stored as custom resources in a management cluster.
+
+	The Cluster and InstanceGroup resources are watched, and the changes to a cluster are applied
+	as by "kops update cluster --yes" when the cluster or one of its instance groups changes.
+	The progress, and the error if the changes could not be applied, are reported in the status
+	of the Cluster resource.
+
+	The state store must be a Kubernetes state store (k8s://). The credentials of the cloud
+	providers of the clusters must be available, as for "kops update cluster".`))
+
+	toolboxReconcileExample = templates.Examples(i18n.T(`
+	# Apply the changes to the clusters in the kops namespace of the management context
+	kops toolbox reconcile --state k8s://management/kops
+
+	# In a pod of the management cluster, use its service account
+	kops toolbox reconcile --state k8s:///kops
+
+	# Apply the pending changes once, and exit
+	kops toolbox reconcile --state k8s://management/kops --once
+	`))
+
+	toolboxReconcileShort = i18n.T(`Apply the changes to the clusters stored as custom resources`)
+)
+
+type ToolboxReconcileOptions struct {
+	// ResyncInterval is the maximum interval between two checks for changes
+	ResyncInterval time.Duration
+	// RetryInterval is the interval after which the changes that could not be applied are retried
+	RetryInterval time.Duration
+	// Once applies the pending changes and exits
+	Once bool
+}
+
+func (o *ToolboxReconcileOptions) InitDefaults() {
+	o.ResyncInterval = 5 * time.Minute
+	o.RetryInterval = 10 * time.Minute
+}
+
+func NewCmdToolboxReconcile(f *util.Factory, out io.Writer) *cobra.Command {
+	options := &ToolboxReconcileOptions{}
+	options.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:     "reconcile",
+		Short:   toolboxReconcileShort,
+		Long:    toolboxReconcileLong,
+		Example: toolboxReconcileExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunToolboxReconcile(context.TODO(), f, out, options)
+		},
+	}
+
+	cmd.Flags().DurationVar(&options.ResyncInterval, "resync-interval", options.ResyncInterval, "Maximum interval between two checks for changes")
+	cmd.Flags().DurationVar(&options.RetryInterval, "retry-interval", options.RetryInterval, "Interval after which the changes that could not be applied are retried")
+	cmd.Flags().BoolVar(&options.Once, "once", options.Once, "Apply the pending changes and exit")
+
+	return cmd
+}
+
+func RunToolboxReconcile(ctx context.Context, f *util.Factory, out io.Writer, options *ToolboxReconcileOptions) error {
+	clientset, err := f.Clientset()
+	if err != nil {
+		return err
+	}
+	crdClientset, ok := clientset.(*crdclientset.CRDClientset)
+	if !ok {
+		return fmt.Errorf("reconcile requires a Kubernetes state store (k8s://), got %q", f.KopsStateStore())
+	}
+
+	apply := func(ctx context.Context, cluster *kops.Cluster) error {
+		updateClusterOptions := &UpdateClusterOptions{}
+		updateClusterOptions.InitDefaults()
+		updateClusterOptions.Yes = true
+		updateClusterOptions.CreateKubecfg = false
+		updateClusterOptions.ClusterName = cluster.Name
+
+		_, err := RunUpdateCluster(ctx, f, out, updateClusterOptions)
+		return err
+	}
+
+	r := reconciler.NewClusterReconciler(crdClientset, apply, options.RetryInterval)
+	if options.Once {
+		return r.ReconcileAll(ctx)
+	}
+	return r.Run(ctx, options.ResyncInterval)
+}
@@ -69,6 +69,8 @@ type UpdateClusterOptions struct {
 	AllowKopsDowngrade bool
 	// GetAssets is whether this is invoked from the CmdGetAssets.
 	GetAssets bool
+	// Quiet suppresses the report of the changes of a dry run, used when another command consumes the results.
+	Quiet bool
 
 	ClusterName string
 
@@ -291,6 +293,7 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 		TargetName:         targetName,
 		LifecycleOverrides: lifecycleOverrideMap,
 		GetAssets:          c.GetAssets,
+		Quiet:              c.Quiet,
 	}
 
 	if err := applyCmd.Run(ctx); err != nil {
@@ -304,6 +307,9 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, out io.Writer, c *Up
 	results.Cluster = cluster
 
 	if isDryrun && !c.GetAssets {
+		if c.Quiet {
+			return results, nil
+		}
 		target := applyCmd.Target.(*fi.DryRunTarget)
 		if target.HasChanges() {
 			fmt.Fprintf(out, "Must specify --yes to apply changes\n")
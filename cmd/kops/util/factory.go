@@ -22,13 +22,12 @@ import (
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation/field"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 	gceacls "k8s.io/kops/pkg/acls/gce"
 	s3acls "k8s.io/kops/pkg/acls/s3"
 	kopsclient "k8s.io/kops/pkg/client/clientset_generated/clientset"
 	"k8s.io/kops/pkg/client/simple"
-	"k8s.io/kops/pkg/client/simple/api"
+	"k8s.io/kops/pkg/client/simple/crdclientset"
 	"k8s.io/kops/pkg/client/simple/vfsclientset"
 	"k8s.io/kops/util/pkg/vfs"
 )
@@ -70,26 +69,33 @@ func (f *Factory) Clientset() (simple.Clientset, error) {
 			return nil, field.Required(field.NewPath("State Store"), STATE_ERROR)
 		}
 
-		// We recognize a `k8s` scheme; this might change in future so we won't document it yet
-		// In practice nobody is going to hit this accidentally, so I don't think we need a feature flag.
+		// The `k8s` scheme stores clusters and instance groups as custom resources in a management cluster:
+		// k8s://<kubeconfig context>/<namespace>, as for the k8s:// VFS paths, with each cluster in its own namespace
+		// if none is specified. The configBase query parameter sets the location the configBase of new clusters defaults to.
 		if strings.HasPrefix(registryPath, "k8s://") {
-			loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-
-			configOverrides := &clientcmd.ConfigOverrides{}
+			u, err := url.Parse(registryPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid kubernetes state store url: %q", registryPath)
+			}
+			namespace := strings.Trim(u.Path, "/")
+			if strings.Contains(namespace, "/") {
+				return nil, field.Invalid(field.NewPath("State Store"), registryPath, "kubernetes state store must be of the form k8s://<context>/<namespace>")
+			}
 
-			if registryPath == "k8s://" {
-			} else {
-				u, err := url.Parse(registryPath)
+			var configBase vfs.Path
+			if s := u.Query().Get("configBase"); s != "" {
+				configBase, err = vfs.Context.BuildVfsPath(s)
 				if err != nil {
-					return nil, fmt.Errorf("invalid kops server url: %q", registryPath)
+					return nil, fmt.Errorf("error building path for configBase %q: %v", s, err)
+				}
+				if !vfs.IsClusterReadable(configBase) {
+					return nil, field.Invalid(field.NewPath("State Store"), registryPath, "configBase must be cluster readable")
 				}
-				configOverrides.CurrentContext = u.Host
 			}
 
-			kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
-			config, err := kubeConfig.ClientConfig()
+			config, err := vfs.KubernetesRESTConfig(u.Host)
 			if err != nil {
-				return nil, fmt.Errorf("error loading kubeconfig for %q", registryPath)
+				return nil, fmt.Errorf("error loading kubeconfig for %q: %v", registryPath, err)
 			}
 
 			kopsClient, err := kopsclient.NewForConfig(config)
@@ -97,12 +103,7 @@ func (f *Factory) Clientset() (simple.Clientset, error) {
 				return nil, fmt.Errorf("error building kops API client: %v", err)
 			}
 
-			f.clientset = &api.RESTClientset{
-				BaseURL: &url.URL{
-					Scheme: "k8s",
-				},
-				KopsClient: kopsClient.Kops(),
-			}
+			f.clientset = crdclientset.NewCRDClientset(kopsClient.KopsV1alpha2(), namespace, configBase)
 		} else if strings.HasPrefix(registryPath, "vault://") {
 			return nil, field.Invalid(field.NewPath("State Store"), registryPath, "Vault is not supported as registry path")
 		} else {
@@ -17,6 +17,7 @@ limitations under the License.
 package main // import "k8s.io/kops/cmd/nodeup"
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -38,7 +39,8 @@ func main() {
 
 	var flagConf, flagCacheDir, gitVersion string
 	var flagRetries int
-	var dryrun, installSystemdUnit bool
+	var dryrun, installSystemdUnit, repair bool
+	var reconcileInterval time.Duration
 	target := "direct"
 
 	if kops.GitVersion != "" {
@@ -51,6 +53,8 @@ func main() {
 	flag.BoolVar(&dryrun, "dryrun", false, "Don't create cloud resources; just show what would be done")
 	flag.StringVar(&target, "target", target, "Target - direct, cloudinit")
 	flag.BoolVar(&installSystemdUnit, "install-systemd-unit", installSystemdUnit, "If true, will install a systemd unit instead of running directly")
+	flag.DurationVar(&reconcileInterval, "reconcile-interval", 0, "If set, keep running and check the node for configuration drift at this interval")
+	flag.BoolVar(&repair, "repair", false, "If true, repair the drifted files and services when running with --reconcile-interval")
 
 	if dryrun {
 		target = "dryrun"
@@ -63,6 +67,23 @@ func main() {
 		klog.Exitf("--conf is required")
 	}
 
+	if reconcileInterval > 0 {
+		if installSystemdUnit || target != "direct" {
+			klog.Exitf("--reconcile-interval can only be used with the direct target")
+		}
+		cmd := &nodeup.NodeUpCommand{
+			ConfigLocation:    flagConf,
+			Target:            target,
+			CacheDir:          flagCacheDir,
+			ReconcileInterval: reconcileInterval,
+			Repair:            repair,
+		}
+		if err := cmd.Reconcile(context.Background(), os.Stdout); err != nil {
+			klog.Exitf("error reconciling node: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	retries := flagRetries
 
 	for {
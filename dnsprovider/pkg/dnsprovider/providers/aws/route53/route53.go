@@ -19,6 +19,7 @@ package route53
 
 import (
 	"io"
+	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
@@ -66,6 +67,12 @@ func newRoute53(config io.Reader) (*Interface, error) {
 	// To avoid API throttling on busier accounts
 	awsConfig = awsConfig.WithMaxRetries(5)
 
+	// Same as awsup.EnvEndpointURL, which cannot be imported from here.
+	// Route53 is signed in us-east-1, which the SDK only knows when it resolves the endpoint itself.
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(endpoint).WithRegion("us-east-1")
+	}
+
 	sess, err := session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
 	})
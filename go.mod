@@ -93,6 +93,7 @@ require (
 	k8s.io/gengo v0.0.0-20210203185629-de9496dff47b
 	k8s.io/klog/v2 v2.8.0
 	k8s.io/kubectl v0.21.1
+	k8s.io/kubelet v0.21.1
 	k8s.io/legacy-cloud-providers v0.21.1
 	k8s.io/mount-utils v0.21.1
 	k8s.io/utils v0.0.0-20210527160623-6fdb442a123b
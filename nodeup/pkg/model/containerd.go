@@ -32,9 +32,13 @@ import (
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/distributions"
 )
 
+// kataInstallDir is where the Kata Containers static release is installed
+const kataInstallDir = "/opt/kata"
+
 // ContainerdBuilder install containerd (just the packages at the moment)
 type ContainerdBuilder struct {
 	*NodeupModelContext
@@ -123,6 +127,10 @@ func (b *ContainerdBuilder) installContainerd(c *fi.ModelBuilderContext) error {
 
 		// Add configuration file for easier use of crictl
 		b.addCrictlConfig(c)
+
+		if err := b.installContainerdRuntimes(c); err != nil {
+			return err
+		}
 	}
 
 	var containerRuntimeVersion string
@@ -152,6 +160,94 @@ func (b *ContainerdBuilder) installContainerd(c *fi.ModelBuilderContext) error {
 	return nil
 }
 
+// installContainerdRuntimes installs the binaries of the additional runtime handlers.
+func (b *ContainerdBuilder) installContainerdRuntimes(c *fi.ModelBuilderContext) error {
+	if b.NodeupConfig.ContainerdConfig == nil {
+		return nil
+	}
+
+	for _, runtime := range b.NodeupConfig.ContainerdConfig.Runtimes {
+		switch runtime.Type {
+		case kops.ContainerdRuntimeTypeGVisor:
+			var source, hash string
+			if runtime.Packages != nil {
+				switch b.Architecture {
+				case architectures.ArchitectureAmd64:
+					source = fi.StringValue(runtime.Packages.UrlAmd64)
+					hash = fi.StringValue(runtime.Packages.HashAmd64)
+				case architectures.ArchitectureArm64:
+					source = fi.StringValue(runtime.Packages.UrlArm64)
+					hash = fi.StringValue(runtime.Packages.HashArm64)
+				}
+			}
+			if source != "" {
+				// The package has the binaries at the top level of the archive
+				c.AddTask(&nodetasks.Archive{
+					Name:      "containerd-runtime-" + runtime.Name,
+					Source:    source,
+					Hash:      hash,
+					TargetDir: "/usr/bin",
+					MapFiles: map[string]string{
+						"runsc":                    "",
+						"containerd-shim-runsc-v1": "",
+					},
+				})
+				continue
+			}
+
+			for _, binary := range []string{"runsc", "containerd-shim-runsc-v1"} {
+				asset, err := b.Assets.Find(binary, "")
+				if err != nil {
+					return fmt.Errorf("error trying to locate asset %q: %v", binary, err)
+				}
+				if asset == nil {
+					return fmt.Errorf("unable to locate asset %q for runtime %q", binary, runtime.Name)
+				}
+				c.AddTask(&nodetasks.File{
+					Path:     filepath.Join("/usr/bin", binary),
+					Contents: asset,
+					Type:     nodetasks.FileType_File,
+					Mode:     fi.String("0755"),
+				})
+			}
+
+		case kops.ContainerdRuntimeTypeKata:
+			var source, hash string
+			if runtime.Packages != nil {
+				switch b.Architecture {
+				case architectures.ArchitectureAmd64:
+					source = fi.StringValue(runtime.Packages.UrlAmd64)
+					hash = fi.StringValue(runtime.Packages.HashAmd64)
+				case architectures.ArchitectureArm64:
+					source = fi.StringValue(runtime.Packages.UrlArm64)
+					hash = fi.StringValue(runtime.Packages.HashArm64)
+				}
+			}
+			if source == "" {
+				return fmt.Errorf("unable to find the %s package for runtime %q", b.Architecture, runtime.Name)
+			}
+			// The static release tarball installs everything under /opt/kata
+			c.AddTask(&nodetasks.Archive{
+				Name:      "containerd-runtime-" + runtime.Name,
+				Source:    source,
+				Hash:      hash,
+				TargetDir: "/",
+			})
+			// containerd looks up the shim binary in its PATH
+			c.AddTask(&nodetasks.File{
+				Path:    "/usr/local/bin/containerd-shim-kata-v2",
+				Type:    nodetasks.FileType_Symlink,
+				Symlink: fi.String(kataInstallDir + "/bin/containerd-shim-kata-v2"),
+			})
+
+		default:
+			return fmt.Errorf("unknown type %q for runtime %q", runtime.Type, runtime.Name)
+		}
+	}
+
+	return nil
+}
+
 func (b *ContainerdBuilder) buildSystemdService(sv semver.Version) *nodetasks.Service {
 	// Based on https://github.com/containerd/containerd/blob/master/containerd.service
 
@@ -450,6 +546,15 @@ func (b *ContainerdBuilder) buildContainerdConfig() string {
 	config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", "runc", "runtime_type"}, "io.containerd.runc.v2")
 	// only enable systemd cgroups for kubernetes >= 1.20
 	config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", "runc", "options", "SystemdCgroup"}, cluster.IsKubernetesGTE("1.20"))
+	for _, runtime := range containerd.Runtimes {
+		switch runtime.Type {
+		case kops.ContainerdRuntimeTypeGVisor:
+			config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", runtime.Name, "runtime_type"}, "io.containerd.runsc.v1")
+		case kops.ContainerdRuntimeTypeKata:
+			config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", runtime.Name, "runtime_type"}, "io.containerd.kata.v2")
+			config.SetPath([]string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes", runtime.Name, "options", "ConfigPath"}, kataInstallDir+"/share/defaults/kata-containers/configuration.toml")
+		}
+	}
 	if components.UsesKubenet(cluster.Spec.Networking) {
 		// Using containerd with Kubenet requires special configuration.
 		// This is a temporary backwards-compatible solution for kubenet users and will be deprecated when Kubenet is deprecated:
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/pelletier/go-toml"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/flagbuilder"
@@ -207,3 +208,62 @@ func TestContainerdConfig(t *testing.T) {
 	}
 
 }
+
+func TestContainerdConfigRuntimes(t *testing.T) {
+	cluster := &kops.Cluster{
+		Spec: kops.ClusterSpec{
+			ContainerRuntime:  "containerd",
+			Containerd:        &kops.ContainerdConfig{},
+			KubernetesVersion: "1.21.0",
+			Networking: &kops.NetworkingSpec{
+				Calico: &kops.CalicoNetworkingSpec{},
+			},
+		},
+	}
+
+	b := &ContainerdBuilder{
+		NodeupModelContext: &NodeupModelContext{
+			Cluster: cluster,
+			NodeupConfig: &nodeup.Config{
+				ContainerdConfig: &kops.ContainerdConfig{
+					Runtimes: []kops.ContainerdRuntimeSpec{
+						{Name: "gvisor", Type: kops.ContainerdRuntimeTypeGVisor},
+						{Name: "kata", Type: kops.ContainerdRuntimeTypeKata},
+					},
+				},
+			},
+		},
+	}
+
+	config, err := toml.Load(b.buildContainerdConfig())
+	if err != nil {
+		t.Fatalf("error parsing containerd config: %v", err)
+	}
+
+	runtimesPath := []string{"plugins", "io.containerd.grpc.v1.cri", "containerd", "runtimes"}
+	for _, test := range []struct {
+		path     []string
+		expected string
+	}{
+		{
+			path:     append(runtimesPath, "gvisor", "runtime_type"),
+			expected: "io.containerd.runsc.v1",
+		},
+		{
+			path:     append(runtimesPath, "kata", "runtime_type"),
+			expected: "io.containerd.kata.v2",
+		},
+		{
+			path:     append(runtimesPath, "kata", "options", "ConfigPath"),
+			expected: "/opt/kata/share/defaults/kata-containers/configuration.toml",
+		},
+		{
+			path:     append(runtimesPath, "runc", "runtime_type"),
+			expected: "io.containerd.runc.v2",
+		},
+	} {
+		if actual := config.GetPath(test.path); actual != test.expected {
+			t.Errorf("unexpected value for %v, expected %q, got %v", test.path, test.expected, actual)
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/systemd"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
@@ -72,6 +73,12 @@ type NodeupModelContext struct {
 	// ConfigurationMode determines if we are prewarming an instance or running it live
 	ConfigurationMode string
 	InstanceID        string
+
+	// ConfigLocation is the location of the boot config nodeup was started with
+	ConfigLocation string
+
+	// ImageDigests maps the images remapped by nodeup to the references cloudup pinned them to
+	ImageDigests map[string]string
 }
 
 // Init completes initialization of the object, for example pre-parsing the kubernetes version
@@ -341,6 +348,22 @@ func (c *NodeupModelContext) BuildBootstrapKubeconfig(name string, ctx *fi.Model
 	}
 }
 
+// RemapImage remaps the image to the configured asset repositories.
+// Images are only pinned to their digests by cloudup, which resolves the images nodeup remaps into ImageDigests.
+func (c *NodeupModelContext) RemapImage(image string) (string, error) {
+	if pinned, found := c.ImageDigests[image]; found {
+		return pinned, nil
+	}
+
+	builder := assets.NewAssetBuilder(c.Cluster, false)
+	if builder.AssetsLocation != nil && builder.AssetsLocation.ImageDigests != nil {
+		location := *builder.AssetsLocation
+		location.ImageDigests = nil
+		builder.AssetsLocation = &location
+	}
+	return builder.RemapImage(image)
+}
+
 // IsKubernetesGTE checks if the version is greater-than-or-equal
 func (c *NodeupModelContext) IsKubernetesGTE(version string) bool {
 	if c.kubernetesVersion.Major == 0 {
@@ -357,6 +380,11 @@ func (c *NodeupModelContext) IsKubernetesLT(version string) bool {
 	return !c.IsKubernetesGTE(version)
 }
 
+// UseGossip checks if the instance takes part in gossip DNS, which it also does while the cluster is migrated away from gossip
+func (c *NodeupModelContext) UseGossip() bool {
+	return c.Cluster.IsGossip() || c.Cluster.IsMigratingFromGossip()
+}
+
 // UseEtcdManager checks if the etcd cluster has etcd-manager enabled
 func (c *NodeupModelContext) UseEtcdManager() bool {
 	for _, x := range c.Cluster.Spec.EtcdClusters {
@@ -651,6 +679,33 @@ func EvaluateHostnameOverride(hostnameOverride string) (string, error) {
 	return *(result.Reservations[0].Instances[0].PrivateDnsName), nil
 }
 
+// UsesIPv6NodeIP checks if the node should register and advertise its IPv6 address instead of its IPv4 address.
+func (c *NodeupModelContext) UsesIPv6NodeIP() bool {
+	return c.Cluster.Spec.IsIPv6Only() && c.Cluster.Spec.CloudProvider == string(kops.CloudProviderAWS)
+}
+
+// GetMetadataLocalIPv6 returns the first IPv6 address of the primary network interface, read from the AWS metadata.
+func (c *NodeupModelContext) GetMetadataLocalIPv6() (string, error) {
+	macBytes, err := vfs.Context.ReadFile("metadata://aws/meta-data/mac")
+	if err != nil {
+		return "", fmt.Errorf("error reading mac from AWS metadata: %v", err)
+	}
+	mac := strings.TrimSpace(string(macBytes))
+
+	ipv6sBytes, err := vfs.Context.ReadFile("metadata://aws/meta-data/network/interfaces/macs/" + mac + "/ipv6s")
+	if err != nil {
+		return "", fmt.Errorf("error reading ipv6s from AWS metadata: %v", err)
+	}
+	for _, ipv6 := range strings.Split(string(ipv6sBytes), "\n") {
+		ipv6 = strings.TrimSpace(ipv6)
+		if ipv6 != "" {
+			return ipv6, nil
+		}
+	}
+
+	return "", fmt.Errorf("no IPv6 address found in AWS metadata for interface %q", mac)
+}
+
 func (b *NodeupModelContext) AddCNIBinAssets(c *fi.ModelBuilderContext, assetNames []string) error {
 	for _, assetName := range assetNames {
 		re, err := regexp.Compile(fmt.Sprintf("^%s$", regexp.QuoteMeta(assetName)))
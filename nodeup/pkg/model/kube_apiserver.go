@@ -44,6 +44,9 @@ import (
 // PathAuthnConfig is the path to the custom webhook authentication config.
 const PathAuthnConfig = "/etc/kubernetes/authn.config"
 
+// DefaultAuditLogPath is the default path of the audit log written by kube-apiserver.
+const DefaultAuditLogPath = "/var/log/kube-apiserver-audit.log"
+
 // KubeAPIServerBuilder installs kube-apiserver.
 type KubeAPIServerBuilder struct {
 	*NodeupModelContext
@@ -68,6 +71,10 @@ func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
 		return err
 	}
 
+	if err := b.writeAuditingConfig(c, &kubeAPIServer); err != nil {
+		return err
+	}
+
 	if b.NodeupConfig.APIServerConfig.EncryptionConfigSecretHash != "" {
 		encryptionConfigPath := fi.String(filepath.Join(pathSrvKAPI, "encryptionconfig.yaml"))
 
@@ -210,6 +217,117 @@ func (b *KubeAPIServerBuilder) Build(c *fi.ModelBuilderContext) error {
 	return nil
 }
 
+// pathSrvAudit returns the directory holding the audit policy and webhook config
+func (b *KubeAPIServerBuilder) pathSrvAudit() string {
+	return filepath.Join(b.PathSrvKubernetes(), "audit")
+}
+
+// writeAuditingConfig writes the audit policy and the audit webhook kubeconfig, and sets the matching flags
+func (b *KubeAPIServerBuilder) writeAuditingConfig(c *fi.ModelBuilderContext, kubeAPIServer *kops.KubeAPIServerConfig) error {
+	auditing := kubeAPIServer.Auditing
+	if auditing == nil {
+		return nil
+	}
+
+	pathSrvAudit := b.pathSrvAudit()
+
+	if auditing.Policy != "" {
+		kubeAPIServer.AuditPolicyFile = filepath.Join(pathSrvAudit, "policy.yaml")
+		c.AddTask(&nodetasks.File{
+			Path:     kubeAPIServer.AuditPolicyFile,
+			Contents: fi.NewStringResource(auditing.Policy),
+			Type:     nodetasks.FileType_File,
+			Mode:     fi.String("600"),
+		})
+	}
+
+	if log := auditing.Log; log != nil {
+		kubeAPIServer.AuditLogPath = fi.String(auditLogPath(log))
+		if log.Format != "" {
+			kubeAPIServer.AuditLogFormat = fi.String(log.Format)
+		}
+		kubeAPIServer.AuditLogMaxAge = log.MaxAge
+		kubeAPIServer.AuditLogMaxBackups = log.MaxBackups
+		kubeAPIServer.AuditLogMaxSize = log.MaxSize
+	}
+
+	if webhook := auditing.Webhook; webhook != nil {
+		config := kubeconfig.KubectlConfig{
+			Kind:       "Config",
+			ApiVersion: "v1",
+		}
+		config.Clusters = append(config.Clusters, &kubeconfig.KubectlClusterWithName{
+			Name: "audit-webhook",
+			Cluster: kubeconfig.KubectlCluster{
+				Server:                   webhook.Server,
+				CertificateAuthorityData: []byte(webhook.CertificateAuthority),
+			},
+		})
+		config.Users = append(config.Users, &kubeconfig.KubectlUserWithName{
+			Name: "kube-apiserver",
+		})
+		config.CurrentContext = "audit-webhook"
+		config.Contexts = append(config.Contexts, &kubeconfig.KubectlContextWithName{
+			Name: "audit-webhook",
+			Context: kubeconfig.KubectlContext{
+				Cluster: "audit-webhook",
+				User:    "kube-apiserver",
+			},
+		})
+
+		manifest, err := kops.ToRawYaml(config)
+		if err != nil {
+			return fmt.Errorf("error marshaling audit webhook config to yaml: %v", err)
+		}
+
+		kubeAPIServer.AuditWebhookConfigFile = filepath.Join(pathSrvAudit, "webhook-config.yaml")
+		c.AddTask(&nodetasks.File{
+			Path:     kubeAPIServer.AuditWebhookConfigFile,
+			Contents: fi.NewBytesResource(manifest),
+			Type:     nodetasks.FileType_File,
+			Mode:     fi.String("600"),
+		})
+
+		kubeAPIServer.AuditWebhookMode = webhook.Mode
+		kubeAPIServer.AuditWebhookInitialBackoff = webhook.InitialBackoff
+		kubeAPIServer.AuditWebhookBatchBufferSize = webhook.BatchBufferSize
+		kubeAPIServer.AuditWebhookBatchMaxSize = webhook.BatchMaxSize
+		kubeAPIServer.AuditWebhookBatchMaxWait = webhook.BatchMaxWait
+		kubeAPIServer.AuditWebhookBatchThrottleEnable = webhook.BatchThrottleEnable
+		kubeAPIServer.AuditWebhookBatchThrottleQps = webhook.BatchThrottleQPS
+		kubeAPIServer.AuditWebhookBatchThrottleBurst = webhook.BatchThrottleBurst
+	}
+
+	if fi.BoolValue(auditing.Dynamic) {
+		kubeAPIServer.AuditDynamicConfiguration = fi.Bool(true)
+
+		// The maps are shared with the nodeup config, which kubeAPIServer is a shallow copy of
+		featureGates := make(map[string]string)
+		for k, v := range kubeAPIServer.FeatureGates {
+			featureGates[k] = v
+		}
+		featureGates["DynamicAuditing"] = "true"
+		kubeAPIServer.FeatureGates = featureGates
+
+		runtimeConfig := make(map[string]string)
+		for k, v := range kubeAPIServer.RuntimeConfig {
+			runtimeConfig[k] = v
+		}
+		runtimeConfig["auditregistration.k8s.io/v1alpha1"] = "true"
+		kubeAPIServer.RuntimeConfig = runtimeConfig
+	}
+
+	return nil
+}
+
+// auditLogPath returns the path of the audit log file
+func auditLogPath(log *kops.AuditLogBackendSpec) string {
+	if log.Path == "" {
+		return DefaultAuditLogPath
+	}
+	return log.Path
+}
+
 func (b *KubeAPIServerBuilder) writeAuthenticationConfig(c *fi.ModelBuilderContext, kubeAPIServer *kops.KubeAPIServerConfig) error {
 	if b.Cluster.Spec.Authentication == nil || b.Cluster.Spec.Authentication.IsEmpty() {
 		return nil
@@ -567,6 +685,15 @@ func (b *KubeAPIServerBuilder) buildPod(kubeAPIServer *kops.KubeAPIServerConfig)
 		flags = append(flags, fmt.Sprintf("--cloud-config=%s", CloudConfigFilePath))
 	}
 
+	// advertise the IPv6 address of the instance, the apiserver would otherwise pick the IPv4 address
+	if b.UsesIPv6NodeIP() {
+		localIPv6, err := b.GetMetadataLocalIPv6()
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, fmt.Sprintf("--advertise-address=%s", localIPv6))
+	}
+
 	pod := &v1.Pod{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -717,6 +844,10 @@ func (b *KubeAPIServerBuilder) buildPod(kubeAPIServer *kops.KubeAPIServerConfig)
 		addHostPathMapping(pod, container, "auditlogpathdir", auditLogPathDir).ReadOnly = false
 	}
 
+	if auditing := kubeAPIServer.Auditing; auditing != nil && (auditing.Policy != "" || auditing.Webhook != nil) {
+		addHostPathMapping(pod, container, "auditconfig", b.pathSrvAudit())
+	}
+
 	if b.Cluster.Spec.Authentication != nil {
 		if b.Cluster.Spec.Authentication.Kopeio != nil || b.Cluster.Spec.Authentication.Aws != nil {
 			addHostPathMapping(pod, container, "authn-config", PathAuthnConfig)
@@ -17,11 +17,15 @@ limitations under the License.
 package model
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 	"k8s.io/kops/util/pkg/architectures"
 )
 
@@ -193,3 +197,84 @@ func TestKubeAPIServerBuilderARM64(t *testing.T) {
 		return builder.Build(target)
 	})
 }
+
+func TestKubeAPIServerAuditingConfig(t *testing.T) {
+	policy := "apiVersion: audit.k8s.io/v1\nkind: Policy\nrules:\n- level: Metadata\n"
+
+	kubeAPIServer := &kops.KubeAPIServerConfig{
+		Auditing: &kops.KubeAPIServerAuditingSpec{
+			Policy: policy,
+			Log: &kops.AuditLogBackendSpec{
+				MaxAge: fi.Int32(10),
+			},
+			Webhook: &kops.AuditWebhookBackendSpec{
+				Server:       "https://audit.example.com/events",
+				Mode:         "batch",
+				BatchMaxWait: &metav1.Duration{Duration: 5 * time.Second},
+			},
+		},
+	}
+
+	builder := KubeAPIServerBuilder{NodeupModelContext: &NodeupModelContext{}}
+	c := &fi.ModelBuilderContext{Tasks: make(map[string]fi.Task)}
+	if err := builder.writeAuditingConfig(c, kubeAPIServer); err != nil {
+		t.Fatalf("error writing auditing config: %v", err)
+	}
+
+	flags, err := flagbuilder.BuildFlags(kubeAPIServer)
+	if err != nil {
+		t.Fatalf("error building flags: %v", err)
+	}
+	expectedFlags := "--audit-log-maxage=10 --audit-log-path=/var/log/kube-apiserver-audit.log" +
+		" --audit-policy-file=/srv/kubernetes/audit/policy.yaml --audit-webhook-batch-max-wait=5s" +
+		" --audit-webhook-config-file=/srv/kubernetes/audit/webhook-config.yaml --audit-webhook-mode=batch" +
+		" --insecure-port=0 --secure-port=0"
+	if flags != expectedFlags {
+		t.Errorf("flags did not match.  actual=%q expected=%q", flags, expectedFlags)
+	}
+
+	policyTask, found := c.Tasks["File//srv/kubernetes/audit/policy.yaml"].(*nodetasks.File)
+	if !found {
+		t.Fatalf("audit policy file task not found in %v", c.Tasks)
+	}
+	if contents, err := fi.ResourceAsString(policyTask.Contents); err != nil || contents != policy {
+		t.Errorf("unexpected audit policy %q: %v", contents, err)
+	}
+
+	webhookTask, found := c.Tasks["File//srv/kubernetes/audit/webhook-config.yaml"].(*nodetasks.File)
+	if !found {
+		t.Fatalf("audit webhook config file task not found in %v", c.Tasks)
+	}
+	if contents, err := fi.ResourceAsString(webhookTask.Contents); err != nil || !strings.Contains(contents, "server: https://audit.example.com/events") {
+		t.Errorf("unexpected audit webhook config %q: %v", contents, err)
+	}
+}
+
+func TestKubeAPIServerDynamicAuditingCopiesMaps(t *testing.T) {
+	featureGates := map[string]string{"SomeFeature": "true"}
+	runtimeConfig := map[string]string{"api/all": "true"}
+	shared := kops.KubeAPIServerConfig{
+		FeatureGates:  featureGates,
+		RuntimeConfig: runtimeConfig,
+		Auditing: &kops.KubeAPIServerAuditingSpec{
+			Dynamic: fi.Bool(true),
+		},
+	}
+
+	kubeAPIServer := shared
+	builder := KubeAPIServerBuilder{NodeupModelContext: &NodeupModelContext{}}
+	c := &fi.ModelBuilderContext{Tasks: make(map[string]fi.Task)}
+	if err := builder.writeAuditingConfig(c, &kubeAPIServer); err != nil {
+		t.Fatalf("error writing auditing config: %v", err)
+	}
+
+	if kubeAPIServer.FeatureGates["DynamicAuditing"] != "true" || kubeAPIServer.FeatureGates["SomeFeature"] != "true" {
+		t.Errorf("unexpected feature gates %v", kubeAPIServer.FeatureGates)
+	}
+	if kubeAPIServer.RuntimeConfig["auditregistration.k8s.io/v1alpha1"] != "true" || kubeAPIServer.RuntimeConfig["api/all"] != "true" {
+		t.Errorf("unexpected runtime config %v", kubeAPIServer.RuntimeConfig)
+	}
+	if len(featureGates) != 1 || len(runtimeConfig) != 1 {
+		t.Errorf("shared maps were modified: feature gates %v, runtime config %v", featureGates, runtimeConfig)
+	}
+}
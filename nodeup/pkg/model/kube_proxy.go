@@ -24,7 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
-	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/flagbuilder"
 	"k8s.io/kops/pkg/k8scodecs"
 	"k8s.io/kops/pkg/kubemanifest"
@@ -227,7 +226,7 @@ func (b *KubeProxyBuilder) buildPod() (*v1.Pod, error) {
 		sslCertsHost.MountPath = "/etc/ssl/certs"
 	}
 
-	if dns.IsGossipHostname(b.Cluster.Name) {
+	if b.UseGossip() {
 		// Map /etc/hosts from host, so that we see the updates that are made by protokube
 		addHostPathMapping(pod, container, "etchosts", "/etc/hosts")
 	}
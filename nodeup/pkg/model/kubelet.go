@@ -68,7 +68,13 @@ func (b *KubeletBuilder) Build(c *fi.ModelBuilderContext) error {
 	}
 
 	{
-		t, err := b.buildSystemdEnvironmentFile(kubeletConfig)
+		configFile, kubeletFlags, err := b.buildKubeletConfigFile(kubeletConfig)
+		if err != nil {
+			return err
+		}
+		c.AddTask(configFile)
+
+		t, err := b.buildSystemdEnvironmentFile(kubeletFlags)
 		if err != nil {
 			return err
 		}
@@ -181,26 +187,22 @@ func (b *KubeletBuilder) buildManifestDirectory(kubeletConfig *kops.KubeletConfi
 	return directory, nil
 }
 
-// buildSystemdEnvironmentFile renders the environment file for the kubelet
+// buildSystemdEnvironmentFile renders the environment file for the kubelet, holding the settings which are passed as flags
 func (b *KubeletBuilder) buildSystemdEnvironmentFile(kubeletConfig *kops.KubeletConfigSpec) (*nodetasks.File, error) {
 	// @step: ensure the masters do not get a bootstrap configuration
 	if b.UseBootstrapTokens() && b.IsMaster {
 		kubeletConfig.BootstrapKubeconfig = ""
 	}
 
-	if kubeletConfig.ExperimentalAllowedUnsafeSysctls != nil {
-		// The ExperimentalAllowedUnsafeSysctls flag was renamed in k/k #63717
-		klog.V(1).Info("ExperimentalAllowedUnsafeSysctls was renamed in k8s 1.11+, please use AllowedUnsafeSysctls instead.")
-		kubeletConfig.AllowedUnsafeSysctls = append(kubeletConfig.ExperimentalAllowedUnsafeSysctls, kubeletConfig.AllowedUnsafeSysctls...)
-		kubeletConfig.ExperimentalAllowedUnsafeSysctls = nil
-	}
-
 	// TODO: Dump the separate file for flags - just complexity!
 	flags, err := flagbuilder.BuildFlags(kubeletConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error building kubelet flags: %v", err)
 	}
 
+	// Most settings are passed in the config file, see buildKubeletConfigFile
+	flags += " --config=" + kubeletConfigFilePath
+
 	// Add cloud config file if needed
 	// We build this flag differently because it depends on CloudConfig, and to expose it directly
 	// would be a degree of freedom we don't have (we'd have to write the config to different files)
@@ -209,7 +211,13 @@ func (b *KubeletBuilder) buildSystemdEnvironmentFile(kubeletConfig *kops.Kubelet
 		flags += " --cloud-config=" + CloudConfigFilePath
 	}
 
-	if b.UsesSecondaryIP() {
+	if b.UsesIPv6NodeIP() {
+		localIPv6, err := b.GetMetadataLocalIPv6()
+		if err != nil {
+			return nil, err
+		}
+		flags += " --node-ip=" + localIPv6
+	} else if b.UsesSecondaryIP() {
 		sess := session.Must(session.NewSession())
 		metadata := ec2metadata.New(sess)
 		localIpv4, err := metadata.GetMetadata("local-ipv4")
@@ -231,7 +239,6 @@ func (b *KubeletBuilder) buildSystemdEnvironmentFile(kubeletConfig *kops.Kubelet
 		flags += " --cni-conf-dir=" + b.CNIConfDir()
 	case "containerd":
 		flags += " --container-runtime=remote"
-		flags += " --runtime-request-timeout=15m"
 		if b.Cluster.Spec.Containerd == nil || b.Cluster.Spec.Containerd.Address == nil {
 			flags += " --container-runtime-endpoint=unix:///run/containerd/containerd.sock"
 		} else {
@@ -239,11 +246,6 @@ func (b *KubeletBuilder) buildSystemdEnvironmentFile(kubeletConfig *kops.Kubelet
 		}
 	}
 
-	if b.UseKopsControllerForNodeBootstrap() {
-		flags += " --tls-cert-file=" + b.PathSrvKubernetes() + "/kubelet-server.crt"
-		flags += " --tls-private-key-file=" + b.PathSrvKubernetes() + "/kubelet-server.key"
-	}
-
 	sysconfig := "DAEMON_ARGS=\"" + flags + "\"\n"
 	// Makes kubelet read /root/.docker/config.json properly
 	sysconfig = sysconfig + "HOME=\"/root" + "\"\n"
@@ -537,6 +539,13 @@ func (b *KubeletBuilder) buildKubeletConfigSpec() (*kops.KubeletConfigSpec, erro
 		c.AuthenticationTokenWebhook = fi.Bool(true)
 	}
 
+	if c.ExperimentalAllowedUnsafeSysctls != nil {
+		// The ExperimentalAllowedUnsafeSysctls flag was renamed in k/k #63717
+		klog.V(1).Info("ExperimentalAllowedUnsafeSysctls was renamed in k8s 1.11+, please use AllowedUnsafeSysctls instead.")
+		c.AllowedUnsafeSysctls = append(c.ExperimentalAllowedUnsafeSysctls, c.AllowedUnsafeSysctls...)
+		c.ExperimentalAllowedUnsafeSysctls = nil
+	}
+
 	return &c, nil
 }
 
@@ -0,0 +1,331 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	kubeletv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// kubeletConfigFilePath is the path of the KubeletConfiguration file passed to the kubelet with --config
+	kubeletConfigFilePath = "/var/lib/kubelet/config.yaml"
+
+	// kubeletDefaultReadOnlyPort is the default of the --read-only-port flag; the config file defaults to disabling the port
+	kubeletDefaultReadOnlyPort = 10255
+)
+
+// buildKubeletConfigFile renders the KubeletConfiguration file.
+// It returns a copy of the kubelet spec holding only the settings which must still be passed as flags.
+func (b *KubeletBuilder) buildKubeletConfigFile(kubeletConfig *kops.KubeletConfigSpec) (*nodetasks.File, *kops.KubeletConfigSpec, error) {
+	flags := *kubeletConfig
+
+	if b.Cluster.Spec.ContainerRuntime == "containerd" && flags.RuntimeRequestTimeout == nil {
+		flags.RuntimeRequestTimeout = &metav1.Duration{Duration: 15 * time.Minute}
+	}
+	if b.UseKopsControllerForNodeBootstrap() {
+		flags.TLSCertFile = b.PathSrvKubernetes() + "/kubelet-server.crt"
+		flags.TLSPrivateKeyFile = b.PathSrvKubernetes() + "/kubelet-server.key"
+	}
+
+	config := buildKubeletConfiguration(&flags)
+
+	contents, err := renderKubeletConfiguration(config, kubeletConfig.ConfigFileOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := &nodetasks.File{
+		Path:     kubeletConfigFilePath,
+		Contents: fi.NewBytesResource(contents),
+		Type:     nodetasks.FileType_File,
+		Mode:     s("0644"),
+	}
+
+	return t, &flags, nil
+}
+
+// buildKubeletConfiguration moves the settings supported by the KubeletConfiguration out of the spec.
+// Settings which cannot be expressed in the config file are left in the spec, to be passed as flags.
+func buildKubeletConfiguration(c *kops.KubeletConfigSpec) *kubeletv1beta1.KubeletConfiguration {
+	config := &kubeletv1beta1.KubeletConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kubeletv1beta1.SchemeGroupVersion.String(),
+			Kind:       "KubeletConfiguration",
+		},
+	}
+
+	// The defaults of the config file are more secure than those of the flags,
+	// so we set the flag defaults explicitly to keep the existing behaviour.
+	config.Authentication.Anonymous.Enabled = fi.Bool(true)
+	if c.AnonymousAuth != nil {
+		config.Authentication.Anonymous.Enabled = c.AnonymousAuth
+		c.AnonymousAuth = nil
+	}
+	config.Authentication.Webhook.Enabled = fi.Bool(false)
+	if c.AuthenticationTokenWebhook != nil {
+		config.Authentication.Webhook.Enabled = c.AuthenticationTokenWebhook
+		c.AuthenticationTokenWebhook = nil
+	}
+	if c.AuthenticationTokenWebhookCacheTTL != nil {
+		config.Authentication.Webhook.CacheTTL = *c.AuthenticationTokenWebhookCacheTTL
+		c.AuthenticationTokenWebhookCacheTTL = nil
+	}
+	config.Authentication.X509.ClientCAFile = c.ClientCAFile
+	c.ClientCAFile = ""
+
+	config.Authorization.Mode = kubeletv1beta1.KubeletAuthorizationModeAlwaysAllow
+	if c.AuthorizationMode != "" {
+		config.Authorization.Mode = kubeletv1beta1.KubeletAuthorizationMode(c.AuthorizationMode)
+		c.AuthorizationMode = ""
+	}
+
+	config.ReadOnlyPort = kubeletDefaultReadOnlyPort
+	if c.ReadOnlyPort != nil {
+		config.ReadOnlyPort = *c.ReadOnlyPort
+		c.ReadOnlyPort = nil
+	}
+
+	config.TLSCertFile, c.TLSCertFile = c.TLSCertFile, ""
+	config.TLSPrivateKeyFile, c.TLSPrivateKeyFile = c.TLSPrivateKeyFile, ""
+	config.TLSCipherSuites, c.TLSCipherSuites = c.TLSCipherSuites, nil
+	config.TLSMinVersion, c.TLSMinVersion = c.TLSMinVersion, ""
+	if c.RotateCertificates != nil {
+		config.RotateCertificates = *c.RotateCertificates
+		c.RotateCertificates = nil
+	}
+
+	config.StaticPodPath, c.PodManifestPath = c.PodManifestPath, ""
+	config.EnableDebuggingHandlers, c.EnableDebuggingHandlers = c.EnableDebuggingHandlers, nil
+	if c.NodeStatusUpdateFrequency != nil {
+		config.NodeStatusUpdateFrequency = *c.NodeStatusUpdateFrequency
+		c.NodeStatusUpdateFrequency = nil
+	}
+	config.ClusterDomain, c.ClusterDomain = c.ClusterDomain, ""
+	if c.ClusterDNS != "" {
+		config.ClusterDNS = strings.Split(c.ClusterDNS, ",")
+		c.ClusterDNS = ""
+	}
+	if c.ResolverConfig != nil && *c.ResolverConfig != "" {
+		config.ResolverConfig = *c.ResolverConfig
+		c.ResolverConfig = nil
+	}
+
+	config.KubeletCgroups, c.KubeletCgroups = c.KubeletCgroups, ""
+	config.SystemCgroups, c.SystemCgroups = c.SystemCgroups, ""
+	config.CgroupRoot, c.CgroupRoot = c.CgroupRoot, ""
+	config.CgroupDriver, c.CgroupDriver = c.CgroupDriver, ""
+
+	config.HairpinMode, c.HairpinMode = c.HairpinMode, ""
+	if c.MaxPods != nil {
+		config.MaxPods = *c.MaxPods
+		c.MaxPods = nil
+	}
+	config.PodCIDR, c.PodCIDR = c.PodCIDR, ""
+	config.PodPidsLimit, c.PodPidsLimit = c.PodPidsLimit, nil
+
+	config.SerializeImagePulls, c.SerializeImagePulls = c.SerializeImagePulls, nil
+	config.ImageGCHighThresholdPercent, c.ImageGCHighThresholdPercent = c.ImageGCHighThresholdPercent, nil
+	config.ImageGCLowThresholdPercent, c.ImageGCLowThresholdPercent = c.ImageGCLowThresholdPercent, nil
+	config.RegistryPullQPS, c.RegistryPullQPS = c.RegistryPullQPS, nil
+	if c.RegistryBurst != nil {
+		config.RegistryBurst = *c.RegistryBurst
+		c.RegistryBurst = nil
+	}
+	config.EventRecordQPS, c.EventQPS = c.EventQPS, nil
+	if c.EventBurst != nil {
+		config.EventBurst = *c.EventBurst
+		c.EventBurst = nil
+	}
+
+	// Eviction thresholds which cannot be parsed are left for the kubelet to report as flags
+	if c.EvictionHard != nil {
+		if m, err := parseKeyValueList(*c.EvictionHard, "<"); err == nil {
+			config.EvictionHard = m
+			c.EvictionHard = nil
+		}
+	}
+	if m, err := parseKeyValueList(c.EvictionSoft, "<"); err == nil {
+		config.EvictionSoft = m
+		c.EvictionSoft = ""
+	}
+	if m, err := parseKeyValueList(c.EvictionSoftGracePeriod, "="); err == nil {
+		config.EvictionSoftGracePeriod = m
+		c.EvictionSoftGracePeriod = ""
+	}
+	if m, err := parseKeyValueList(c.EvictionMinimumReclaim, "="); err == nil {
+		config.EvictionMinimumReclaim = m
+		c.EvictionMinimumReclaim = ""
+	}
+	if c.EvictionPressureTransitionPeriod != nil {
+		config.EvictionPressureTransitionPeriod = *c.EvictionPressureTransitionPeriod
+		c.EvictionPressureTransitionPeriod = nil
+	}
+	config.EvictionMaxPodGracePeriod, c.EvictionMaxPodGracePeriod = c.EvictionMaxPodGracePeriod, 0
+
+	config.VolumePluginDir, c.VolumePluginDirectory = c.VolumePluginDirectory, ""
+	if c.VolumeStatsAggPeriod != nil {
+		config.VolumeStatsAggPeriod = *c.VolumeStatsAggPeriod
+		c.VolumeStatsAggPeriod = nil
+	}
+
+	if len(c.FeatureGates) != 0 {
+		featureGates := make(map[string]bool)
+		for k, v := range c.FeatureGates {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				featureGates = nil
+				break
+			}
+			featureGates[k] = enabled
+		}
+		if featureGates != nil {
+			config.FeatureGates = featureGates
+			c.FeatureGates = nil
+		}
+	}
+
+	config.KubeReserved, c.KubeReserved = c.KubeReserved, nil
+	config.KubeReservedCgroup, c.KubeReservedCgroup = c.KubeReservedCgroup, ""
+	config.SystemReserved, c.SystemReserved = c.SystemReserved, nil
+	config.SystemReservedCgroup, c.SystemReservedCgroup = c.SystemReservedCgroup, ""
+	if c.EnforceNodeAllocatable != "" {
+		config.EnforceNodeAllocatable = strings.Split(c.EnforceNodeAllocatable, ",")
+		c.EnforceNodeAllocatable = ""
+	}
+
+	if c.RuntimeRequestTimeout != nil {
+		config.RuntimeRequestTimeout = *c.RuntimeRequestTimeout
+		c.RuntimeRequestTimeout = nil
+	}
+	config.FailSwapOn, c.FailSwapOn = c.FailSwapOn, nil
+	config.AllowedUnsafeSysctls, c.AllowedUnsafeSysctls = c.AllowedUnsafeSysctls, nil
+	if c.StreamingConnectionIdleTimeout != nil {
+		config.StreamingConnectionIdleTimeout = *c.StreamingConnectionIdleTimeout
+		c.StreamingConnectionIdleTimeout = nil
+	}
+	if c.ProtectKernelDefaults != nil {
+		config.ProtectKernelDefaults = *c.ProtectKernelDefaults
+		c.ProtectKernelDefaults = nil
+	}
+
+	config.CPUCFSQuota, c.CPUCFSQuota = c.CPUCFSQuota, nil
+	config.CPUCFSQuotaPeriod, c.CPUCFSQuotaPeriod = c.CPUCFSQuotaPeriod, nil
+	config.CPUManagerPolicy, c.CpuManagerPolicy = c.CpuManagerPolicy, ""
+	config.TopologyManagerPolicy, c.TopologyManagerPolicy = c.TopologyManagerPolicy, ""
+	config.TopologyManagerScope, c.TopologyManagerScope = c.TopologyManagerScope, ""
+	config.MemoryManagerPolicy, c.MemoryManagerPolicy = c.MemoryManagerPolicy, ""
+	for _, reservation := range c.ReservedMemory {
+		limits := make(v1.ResourceList)
+		for k, v := range reservation.Limits {
+			limits[v1.ResourceName(k)] = v
+		}
+		config.ReservedMemory = append(config.ReservedMemory, kubeletv1beta1.MemoryReservation{
+			NumaNode: reservation.NumaNode,
+			Limits:   limits,
+		})
+	}
+	c.ReservedMemory = nil
+
+	config.ContainerLogMaxSize, c.ContainerLogMaxSize = c.ContainerLogMaxSize, ""
+	config.ContainerLogMaxFiles, c.ContainerLogMaxFiles = c.ContainerLogMaxFiles, nil
+
+	if c.ShutdownGracePeriod != nil {
+		config.ShutdownGracePeriod = *c.ShutdownGracePeriod
+		c.ShutdownGracePeriod = nil
+	}
+	if c.ShutdownGracePeriodCriticalPods != nil {
+		config.ShutdownGracePeriodCriticalPods = *c.ShutdownGracePeriodCriticalPods
+		c.ShutdownGracePeriodCriticalPods = nil
+	}
+	c.ConfigFileOverrides = nil
+
+	return config
+}
+
+// renderKubeletConfiguration serializes the configuration, applying the overrides to its top-level fields.
+// Fields left at their zero value are omitted, so that the kubelet applies its own defaults.
+func renderKubeletConfiguration(config *kubeletv1beta1.KubeletConfiguration, overrides map[string]kops.JSON) ([]byte, error) {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing kubelet configuration: %v", err)
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("error parsing kubelet configuration: %v", err)
+	}
+	pruneEmptyFields(fields)
+
+	for k, v := range overrides {
+		var value interface{}
+		if err := json.Unmarshal(v.Raw, &value); err != nil {
+			return nil, fmt.Errorf("error parsing kubelet configFileOverrides %q: %v", k, err)
+		}
+		fields[k] = value
+	}
+
+	y, err := yaml.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing kubelet configuration: %v", err)
+	}
+	return y, nil
+}
+
+// pruneEmptyFields removes unset durations and empty objects, which the kubelet types always serialize
+func pruneEmptyFields(fields map[string]interface{}) {
+	for k, v := range fields {
+		switch v := v.(type) {
+		case string:
+			if v == "0s" {
+				delete(fields, k)
+			}
+		case map[string]interface{}:
+			pruneEmptyFields(v)
+			if len(v) == 0 {
+				delete(fields, k)
+			}
+		}
+	}
+}
+
+// parseKeyValueList parses a comma separated list of key-value pairs, such as "memory.available<100Mi,nodefs.available<10%"
+func parseKeyValueList(s string, separator string) (map[string]string, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty list")
+	}
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		tokens := strings.SplitN(pair, separator, 2)
+		if len(tokens) != 2 || strings.TrimSpace(tokens[0]) == "" {
+			return nil, fmt.Errorf("invalid key-value pair %q", pair)
+		}
+		m[strings.TrimSpace(tokens[0])] = strings.TrimSpace(tokens[1])
+	}
+	return m, nil
+}
@@ -209,6 +209,36 @@ func Test_RunKubeletBuilderWarmPool(t *testing.T) {
 
 }
 
+func Test_RunKubeletBuilderConfigFile(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+
+	h.MockKopsVersion("1.18.0")
+	h.SetupMockAWS()
+
+	basedir := "tests/kubelet/configfile"
+
+	context := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+
+	model, err := testutils.LoadModel(basedir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeUpModelContext, err := BuildNodeupModelContext(model)
+	if err != nil {
+		t.Fatalf("error loading model %q: %v", basedir, err)
+		return
+	}
+
+	runKubeletBuilder(t, context, nodeUpModelContext)
+
+	testutils.ValidateTasks(t, filepath.Join(basedir, "tasks.yaml"), context)
+
+}
+
 func runKubeletBuilder(t *testing.T, context *fi.ModelBuilderContext, nodeupModelContext *NodeupModelContext) {
 	if err := nodeupModelContext.Init(); err != nil {
 		t.Fatalf("error from nodeupModelContext.Init(): %v", err)
@@ -222,7 +252,14 @@ func runKubeletBuilder(t *testing.T, context *fi.ModelBuilderContext, nodeupMode
 		return
 	}
 
-	fileTask, err := builder.buildSystemdEnvironmentFile(kubeletConfig)
+	configFileTask, kubeletFlags, err := builder.buildKubeletConfigFile(kubeletConfig)
+	if err != nil {
+		t.Fatalf("error from KubeletBuilder buildKubeletConfigFile: %v", err)
+		return
+	}
+	context.AddTask(configFileTask)
+
+	fileTask, err := builder.buildSystemdEnvironmentFile(kubeletFlags)
 	if err != nil {
 		t.Fatalf("error from KubeletBuilder buildSystemdEnvironmentFile: %v", err)
 		return
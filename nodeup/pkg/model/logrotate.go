@@ -61,6 +61,15 @@ func (b *LogrotateBuilder) Build(c *fi.ModelBuilderContext) error {
 		b.addLogRotate(c, "etcd-cilium", "/var/log/etcd-cilium.log", logRotateOptions{})
 	}
 
+	// The audit log is rotated by kube-apiserver itself when any of its rotation limits is set
+	if b.NodeupConfig.APIServerConfig != nil && b.NodeupConfig.APIServerConfig.KubeAPIServer != nil {
+		if auditing := b.NodeupConfig.APIServerConfig.KubeAPIServer.Auditing; auditing != nil && auditing.Log != nil && auditing.Log.MaxSize == nil && auditing.Log.MaxAge == nil && auditing.Log.MaxBackups == nil {
+			if path := auditLogPath(auditing.Log); path != "-" {
+				b.addLogRotate(c, "kube-apiserver-audit", path, logRotateOptions{})
+			}
+		}
+	}
+
 	if err := b.addLogrotateService(c); err != nil {
 		return err
 	}
@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"path/filepath"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model/components/etcdmanager"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// MetalVolumesBuilder creates the directories that etcd-manager uses as volumes on metal hosts
+type MetalVolumesBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &MetalVolumesBuilder{}
+
+// Build is responsible for creating a volume directory for each etcd cluster on the control plane hosts
+func (b *MetalVolumesBuilder) Build(c *fi.ModelBuilderContext) error {
+	if !b.IsMaster || !b.UseEtcdManager() || kops.CloudProviderID(b.Cluster.Spec.CloudProvider) != kops.CloudProviderMetal {
+		return nil
+	}
+
+	for _, etcdCluster := range b.Cluster.Spec.EtcdClusters {
+		c.AddTask(&nodetasks.File{
+			Path: filepath.Join("/mnt/disks", etcdmanager.MetalVolumePrefix(b.Cluster.Name, etcdCluster.Name)+"0"),
+			Type: nodetasks.FileType_Directory,
+			Mode: s("0700"),
+		})
+	}
+
+	return nil
+}
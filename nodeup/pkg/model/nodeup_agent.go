@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+const (
+	// nodeupAgentServiceName is the systemd service running nodeup as an agent
+	nodeupAgentServiceName = "kops-nodeup-agent.service"
+	// nodeupAgentReconcileInterval is the interval at which the agent checks the node configuration
+	nodeupAgentReconcileInterval = "1m"
+)
+
+// NodeupAgentBuilder runs nodeup as an agent, applying the in-place updates requested by rolling updates
+type NodeupAgentBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &NodeupAgentBuilder{}
+
+// Build is responsible for creating the nodeup agent service
+func (b *NodeupAgentBuilder) Build(c *fi.ModelBuilderContext) error {
+	if !b.NodeupConfig.InPlaceUpdates {
+		return nil
+	}
+
+	if b.ConfigLocation == "" {
+		return fmt.Errorf("ConfigLocation is required to run the nodeup agent")
+	}
+
+	nodeupPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error finding nodeup binary: %v", err)
+	}
+
+	manifest := &systemd.Manifest{}
+	manifest.Set("Unit", "Description", "Kops nodeup agent, applying in-place updates")
+	manifest.Set("Unit", "Documentation", "https://github.com/kubernetes/kops")
+	manifest.Set("Unit", "After", "kops-configuration.service")
+	manifest.Set("Service", "ExecStart", fmt.Sprintf("%s --conf=%s --reconcile-interval=%s --v=2", nodeupPath, b.ConfigLocation, nodeupAgentReconcileInterval))
+	manifest.Set("Service", "Restart", "always")
+	manifest.Set("Service", "RestartSec", "10s")
+	manifest.Set("Install", "WantedBy", "multi-user.target")
+
+	manifestString := manifest.Render()
+	klog.V(8).Infof("Built service manifest %q\n%s", nodeupAgentServiceName, manifestString)
+
+	service := &nodetasks.Service{
+		Name:       nodeupAgentServiceName,
+		Definition: s(manifestString),
+	}
+	service.InitDefaults()
+	c.AddTask(service)
+
+	return nil
+}
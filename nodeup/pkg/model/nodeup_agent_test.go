@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+func TestNodeupAgentBuilder(t *testing.T) {
+	for _, inPlaceUpdates := range []bool{false, true} {
+		b := &NodeupAgentBuilder{
+			NodeupModelContext: &NodeupModelContext{
+				NodeupConfig:   &nodeup.Config{InPlaceUpdates: inPlaceUpdates},
+				ConfigLocation: "/opt/kops/conf/kube_env.yaml",
+			},
+		}
+		c := &fi.ModelBuilderContext{
+			Tasks: make(map[string]fi.Task),
+		}
+		if err := b.Build(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		task := c.Tasks["Service/"+nodeupAgentServiceName]
+		if !inPlaceUpdates {
+			if task != nil {
+				t.Errorf("agent should only run with in-place updates")
+			}
+			continue
+		}
+		if task == nil {
+			t.Fatalf("agent service not found in tasks: %v", c.Tasks)
+		}
+		definition := fi.StringValue(task.(*nodetasks.Service).Definition)
+		if !strings.Contains(definition, " --conf=/opt/kops/conf/kube_env.yaml --reconcile-interval=1m ") {
+			t.Errorf("unexpected service definition:\n%s", definition)
+		}
+	}
+}
@@ -25,11 +25,11 @@ import (
 
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
-	"k8s.io/kops/pkg/assets"
-	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/flagbuilder"
+	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/pkg/rbac"
 	"k8s.io/kops/pkg/systemd"
+	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
 	"k8s.io/kops/util/pkg/distributions"
@@ -49,7 +49,7 @@ var _ fi.ModelBuilder = &ProtokubeBuilder{}
 
 // Build is responsible for generating the options for protokube
 func (t *ProtokubeBuilder) Build(c *fi.ModelBuilderContext) error {
-	useGossip := dns.IsGossipHostname(t.Cluster.Spec.MasterInternalName)
+	useGossip := t.UseGossip()
 
 	// check is not a master and we are not using gossip (https://github.com/kubernetes/kops/pull/3091)
 	if !t.IsMaster && !useGossip {
@@ -225,14 +225,21 @@ type ProtokubeFlags struct {
 	GossipProtocolSecondary *string `json:"gossip-protocol-secondary" flag:"gossip-protocol-secondary" flag-include-empty:"true"`
 	GossipListenSecondary   *string `json:"gossip-listen-secondary" flag:"gossip-listen-secondary"`
 	GossipSecretSecondary   *string `json:"gossip-secret-secondary" flag:"gossip-secret-secondary"`
+
+	// GossipAPIName is a name that masters publish into gossip with their own address, while the cluster is migrated away from gossip
+	GossipAPIName *string `json:"gossip-api-name" flag:"gossip-api-name"`
+
+	// GossipDebugListen is the address on which protokube serves its gossip and DNS state, for kops toolbox gossip
+	GossipDebugListen *string `json:"gossip-debug-listen" flag:"gossip-debug-listen"`
+
+	// ClusterID is the name of the cluster, on clouds where protokube cannot find it from the instance
+	ClusterID *string `json:"clusterID,omitempty" flag:"cluster-id"`
+	// GossipSeeds are the addresses through which protokube joins gossip, on clouds where the peers cannot be discovered
+	GossipSeeds []string `json:"gossipSeeds,omitempty" flag:"gossip-seed"`
 }
 
 // ProtokubeFlags is responsible for building the command line flags for protokube
 func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*ProtokubeFlags, error) {
-	imageVersion := t.Cluster.Spec.EtcdClusters[0].Version
-	// overrides imageVersion if set
-	etcdContainerImage := t.Cluster.Spec.EtcdClusters[0].Image
-
 	var leaderElectionTimeout string
 	var heartbeatInterval string
 
@@ -277,14 +284,8 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 			}
 		}
 
-		// TODO this is duplicate code with etcd model
-		image := fmt.Sprintf("k8s.gcr.io/etcd:%s", imageVersion)
-		// override image if set as API value
-		if etcdContainerImage != "" {
-			image = etcdContainerImage
-		}
-		assets := assets.NewAssetBuilder(t.Cluster, false)
-		remapped, err := assets.RemapImage(image)
+		image := components.ProtokubeEtcdImage(t.Cluster)
+		remapped, err := t.RemapImage(image)
 		if err != nil {
 			return nil, fmt.Errorf("unable to remap container %q: %v", image, err)
 		}
@@ -324,8 +325,18 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 		//argv = append(argv, "--zone=*/*")
 	}
 
-	if dns.IsGossipHostname(t.Cluster.Spec.MasterInternalName) {
-		klog.Warningf("MasterInternalName %q implies gossip DNS", t.Cluster.Spec.MasterInternalName)
+	if t.UseGossip() {
+		// While migrating away from gossip, we keep gossip running under the previous internal name,
+		// and the masters publish that name so instances that have not yet been replaced can still reach them.
+		gossipInternalName := t.Cluster.Spec.MasterInternalName
+		if t.Cluster.IsMigratingFromGossip() {
+			gossipInternalName = t.Cluster.DNSMigrationPreviousInternalName()
+			if t.IsMaster {
+				f.GossipAPIName = fi.String(gossipInternalName)
+			}
+		}
+
+		klog.Warningf("MasterInternalName %q implies gossip DNS", gossipInternalName)
 		f.DNSProvider = fi.String("gossip")
 		if t.Cluster.Spec.GossipConfig != nil {
 			f.GossipProtocol = t.Cluster.Spec.GossipConfig.Protocol
@@ -339,8 +350,13 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 			}
 		}
 
+		if t.IsMaster {
+			// The debug state is unauthenticated, so it is only served on localhost
+			f.GossipDebugListen = fi.String(fmt.Sprintf("127.0.0.1:%d", wellknownports.ProtokubeGossipDebug))
+		}
+
 		// @TODO: This is hacky, but we want it so that we can have a different internal & external name
-		internalSuffix := t.Cluster.Spec.MasterInternalName
+		internalSuffix := gossipInternalName
 		internalSuffix = strings.TrimPrefix(internalSuffix, "api.")
 		f.DNSInternalSuffix = fi.String(internalSuffix)
 	}
@@ -362,6 +378,11 @@ func (t *ProtokubeBuilder) ProtokubeFlags(k8sVersion semver.Version) (*Protokube
 		}
 	}
 
+	if kops.CloudProviderID(t.Cluster.Spec.CloudProvider) == kops.CloudProviderMetal {
+		f.ClusterID = fi.String(t.Cluster.ObjectMeta.Name)
+		f.GossipSeeds = t.NodeupConfig.GossipSeeds
+	}
+
 	if f.DNSInternalSuffix == nil {
 		f.DNSInternalSuffix = fi.String(".internal." + t.Cluster.ObjectMeta.Name)
 	}
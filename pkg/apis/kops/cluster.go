@@ -18,11 +18,13 @@ package kops
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/upup/pkg/fi/utils"
 )
 
@@ -35,6 +37,8 @@ type Cluster struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec ClusterSpec `json:"spec,omitempty"`
+	// Status reports the progress of the reconciliation of the cluster, when it is managed by the kops controller
+	Status *ReconcileStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -47,6 +51,36 @@ type ClusterList struct {
 	Items []Cluster `json:"items"`
 }
 
+// ReconcilePhase is the phase of the reconciliation of a cluster by the kops controller
+type ReconcilePhase string
+
+const (
+	// ReconcilePhasePending means that the changes to the cluster have not been applied yet
+	ReconcilePhasePending ReconcilePhase = "Pending"
+	// ReconcilePhaseApplying means that the changes to the cluster are being applied
+	ReconcilePhaseApplying ReconcilePhase = "Applying"
+	// ReconcilePhaseReady means that the last changes to the cluster were applied
+	ReconcilePhaseReady ReconcilePhase = "Ready"
+	// ReconcilePhaseFailed means that the changes to the cluster could not be applied
+	ReconcilePhaseFailed ReconcilePhase = "Failed"
+)
+
+// ReconcileStatus reports the progress of the reconciliation of a cluster by the kops controller
+type ReconcileStatus struct {
+	// ObservedGeneration is the generation of the cluster last reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ObservedInstanceGroups is the generation of each instance group last reconciled
+	ObservedInstanceGroups map[string]int64 `json:"observedInstanceGroups,omitempty"`
+	// Phase is the phase of the reconciliation
+	Phase ReconcilePhase `json:"phase,omitempty"`
+	// Message describes the progress of the reconciliation, or the error that made it fail
+	Message string `json:"message,omitempty"`
+	// LastUpdateTime is the last time the status was updated
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// LastAppliedTime is the last time the changes to the cluster were applied successfully
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+}
+
 // ClusterSpec defines the configuration for a cluster
 type ClusterSpec struct {
 	// The Channel we are following
@@ -206,6 +240,8 @@ type ClusterSpec struct {
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
 	// ClusterAutoscaler defines the cluster autoscaler configuration.
 	ClusterAutoscaler *ClusterAutoscalerConfig `json:"clusterAutoscaler,omitempty"`
+	// Karpenter defines the karpenter provisioner configuration, used by instance groups with the Provisioner manager.
+	Karpenter *KarpenterConfig `json:"karpenter,omitempty"`
 	// WarmPool defines the default warm pool settings for instance groups (AWS only).
 	WarmPool *WarmPoolSpec `json:"warmPool,omitempty"`
 
@@ -296,6 +332,18 @@ type Assets struct {
 	FileRepository *string `json:"fileRepository,omitempty"`
 	// ContainerProxy is a url for a pull-through proxy of a docker registry
 	ContainerProxy *string `json:"containerProxy,omitempty"`
+	// ImageDigests configures pinning container images to immutable digests
+	ImageDigests *ImageDigestsSpec `json:"imageDigests,omitempty"`
+}
+
+// ImageDigestsSpec configures pinning container images to immutable digests
+type ImageDigestsSpec struct {
+	// Enabled resolves each container image to its digest when the cluster is updated,
+	// and refers to the image by that digest in the rendered manifests and nodeup configuration.
+	Enabled bool `json:"enabled,omitempty"`
+	// SignaturePublicKey is a PEM encoded public key. If set, each container image must
+	// have a cosign signature which can be verified with this key.
+	SignaturePublicKey *string `json:"signaturePublicKey,omitempty"`
 }
 
 // IAMSpec adds control over the IAM security policies applied to resources
@@ -751,6 +799,45 @@ func (c *Cluster) SharedVPC() bool {
 	return c.Spec.NetworkID != ""
 }
 
+// IsGossip returns true if the cluster uses gossip DNS to discover the API servers.
+// This follows the internal API name rather than the cluster name, so that a cluster can be migrated between gossip and DNS.
+func (c *Cluster) IsGossip() bool {
+	if c.Spec.MasterInternalName != "" {
+		return dns.IsGossipHostname(c.Spec.MasterInternalName)
+	}
+	return dns.IsGossipHostname(c.ObjectMeta.Name)
+}
+
+// DNSMigrationPreviousNames returns the API names used before a migration between gossip and DNS that is in progress.
+func (c *Cluster) DNSMigrationPreviousNames() []string {
+	var names []string
+	for _, name := range strings.Split(c.ObjectMeta.Annotations[AnnotationNameDNSMigrationPreviousNames], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// DNSMigrationPreviousInternalName returns the internal API name used before a migration between gossip and DNS that is in progress.
+func (c *Cluster) DNSMigrationPreviousInternalName() string {
+	names := c.DNSMigrationPreviousNames()
+	if len(names) == 0 {
+		return ""
+	}
+	return names[len(names)-1]
+}
+
+// IsMigratingFromGossip returns true if the cluster is being migrated from gossip to DNS,
+// in which case instances that have not yet been replaced still rely on gossip.
+func (c *Cluster) IsMigratingFromGossip() bool {
+	if c.IsGossip() {
+		return false
+	}
+	previous := c.DNSMigrationPreviousInternalName()
+	return previous != "" && dns.IsGossipHostname(previous)
+}
+
 // IsKubernetesGTE checks if the version is >= the specified version.
 // It panics if the kubernetes version in the cluster is invalid, or if the version is invalid.
 func (c *Cluster) IsKubernetesGTE(version string) bool {
@@ -874,6 +961,12 @@ type RollingUpdate struct {
 	// nodes.
 	// +optional
 	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+	// InPlaceUpdates enables applying changes that don't require replacing the instances,
+	// such as kubelet settings, file assets or sysctls, by re-running nodeup on the running nodes.
+	// Nodes are cordoned while the change is applied, instead of being drained and terminated.
+	// Requires the AWS cloud provider.
+	// Defaults to false.
+	InPlaceUpdates *bool `json:"inPlaceUpdates,omitempty"`
 }
 
 type PackagesConfig struct {
@@ -30,6 +30,9 @@ type ContainerdConfig struct {
 	RegistryMirrors map[string][]string `json:"registryMirrors,omitempty"`
 	// Root directory for persistent data (default "/var/lib/containerd").
 	Root *string `json:"root,omitempty" flag:"root"`
+	// Runtimes are the additional runtime handlers, such as gVisor or Kata Containers, installed alongside runc.
+	// The runtimes can be overridden for each instance group.
+	Runtimes []ContainerdRuntimeSpec `json:"runtimes,omitempty"`
 	// SkipInstall prevents kOps from installing and modifying containerd in any way (default "false").
 	SkipInstall bool `json:"skipInstall,omitempty"`
 	// State directory for execution state files (default "/run/containerd").
@@ -37,3 +40,40 @@ type ContainerdConfig struct {
 	// Version used to pick the containerd package.
 	Version *string `json:"version,omitempty"`
 }
+
+// ContainerdRuntimeType is the type of a containerd runtime handler
+type ContainerdRuntimeType string
+
+const (
+	// ContainerdRuntimeTypeGVisor runs containers in the gVisor application kernel
+	ContainerdRuntimeTypeGVisor ContainerdRuntimeType = "gvisor"
+	// ContainerdRuntimeTypeKata runs containers in Kata Containers lightweight virtual machines
+	ContainerdRuntimeTypeKata ContainerdRuntimeType = "kata"
+)
+
+// SupportedContainerdRuntimeTypes is the list of supported containerd runtime handler types
+var SupportedContainerdRuntimeTypes = []string{string(ContainerdRuntimeTypeGVisor), string(ContainerdRuntimeTypeKata)}
+
+// ContainerdRuntimeSpec is the configuration of a containerd runtime handler
+type ContainerdRuntimeSpec struct {
+	// Name of the runtime handler, which RuntimeClasses refer to.
+	Name string `json:"name,omitempty"`
+	// Type of the runtime handler: gvisor or kata.
+	Type ContainerdRuntimeType `json:"type,omitempty"`
+	// Version of the runtime release, used to pick the binaries (gVisor only).
+	Version *string `json:"version,omitempty"`
+	// Packages overrides the URL and hash for the runtime release archive.
+	Packages *PackagesConfig `json:"packages,omitempty"`
+}
+
+// ResolveRuntimes returns the runtime handlers of the instance group.
+// Runtime handlers set on the instance group replace those set on the cluster.
+func (in *ContainerdConfig) ResolveRuntimes(ig *InstanceGroup) []ContainerdRuntimeSpec {
+	if ig != nil && ig.Spec.Containerd != nil && ig.Spec.Containerd.Runtimes != nil {
+		return ig.Spec.Containerd.Runtimes
+	}
+	if in == nil {
+		return nil
+	}
+	return in.Runtimes
+}
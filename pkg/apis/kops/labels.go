@@ -23,6 +23,10 @@ const (
 	// AnnotationValueManagementImported is the annotation value that indicates a cluster was imported, typically as part of an upgrade
 	AnnotationValueManagementImported = "imported"
 
+	// AnnotationNameDNSMigrationPreviousNames is the annotation that holds the API names a cluster used before a migration
+	// between gossip and DNS that is still in progress: the public name followed by the internal name, comma separated
+	AnnotationNameDNSMigrationPreviousNames = "kops.kubernetes.io/dns-migration-previous-names"
+
 	// UpdatePolicyAutomatic is a value for ClusterSpec.UpdatePolicy and InstanceGroup.UpdatePolicy indicating that upgrades are performed automatically
 	UpdatePolicyAutomatic = "automatic"
 
@@ -39,3 +39,14 @@ func UseCiliumEtcd(cluster *kops.Cluster) bool {
 
 	return false
 }
+
+// UseInPlaceUpdates is true if changes that don't require replacing the instances of the instance group are applied in place.
+func UseInPlaceUpdates(cluster *kops.Cluster, ig *kops.InstanceGroup) bool {
+	if ig.Spec.RollingUpdate != nil && ig.Spec.RollingUpdate.InPlaceUpdates != nil {
+		return *ig.Spec.RollingUpdate.InPlaceUpdates
+	}
+	if cluster.Spec.RollingUpdate != nil && cluster.Spec.RollingUpdate.InPlaceUpdates != nil {
+		return *cluster.Spec.RollingUpdate.InPlaceUpdates
+	}
+	return false
+}
@@ -223,6 +223,54 @@ type KubeletConfigSpec struct {
 	EnableCadvisorJsonEndpoints *bool `json:"enableCadvisorJsonEndpoints,omitempty" flag:"enable-cadvisor-json-endpoints"`
 	// PodPidsLimit is the maximum number of pids in any pod.
 	PodPidsLimit *int64 `json:"podPidsLimit,omitempty" flag:"pod-max-pids"`
+	// ShutdownGracePeriod is the total duration the node delays its shutdown by, to terminate pods.
+	// Requires the GracefulNodeShutdown feature gate.
+	ShutdownGracePeriod *metav1.Duration `json:"shutdownGracePeriod,omitempty"`
+	// ShutdownGracePeriodCriticalPods is the part of ShutdownGracePeriod used to terminate critical pods.
+	ShutdownGracePeriodCriticalPods *metav1.Duration `json:"shutdownGracePeriodCriticalPods,omitempty"`
+	// MemoryManagerPolicy is the policy of the memory manager: None or Static.
+	// Requires the MemoryManager feature gate.
+	MemoryManagerPolicy string `json:"memoryManagerPolicy,omitempty"`
+	// ReservedMemory is the memory reserved for system use on each NUMA node, when using the Static memory manager policy.
+	ReservedMemory []KubeletMemoryReservation `json:"reservedMemory,omitempty"`
+	// TopologyManagerScope is the scope of the topology manager hints: container or pod.
+	TopologyManagerScope string `json:"topologyManagerScope,omitempty"`
+	// ConfigFileOverrides sets fields of the kubelet configuration file that kOps does not model.
+	// Keys are top-level fields of the KubeletConfiguration, which replace the values generated by kOps.
+	ConfigFileOverrides map[string]JSON `json:"configFileOverrides,omitempty"`
+}
+
+// KubeletMemoryReservation is the memory reserved for system use on a NUMA node
+type KubeletMemoryReservation struct {
+	// NumaNode is the index of the NUMA node
+	NumaNode int32 `json:"numaNode"`
+	// Limits is the amount of each resource reserved, for example memory or hugepages-1Gi
+	Limits map[string]resource.Quantity `json:"limits"`
+}
+
+// JSON holds an arbitrary JSON value, such as an object, a list or a number
+// +kubebuilder:validation:Type=""
+// +kubebuilder:pruning:PreserveUnknownFields
+type JSON struct {
+	Raw []byte `json:"-"`
+}
+
+// MarshalJSON returns the raw JSON value
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j.Raw) == 0 {
+		return []byte("null"), nil
+	}
+	return j.Raw, nil
+}
+
+// UnmarshalJSON keeps a copy of the raw JSON value
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		j.Raw = nil
+		return nil
+	}
+	j.Raw = append(j.Raw[0:0], data...)
+	return nil
 }
 
 // KubeProxyConfig defines the configuration for a proxy
@@ -515,6 +563,64 @@ type KubeAPIServerConfig struct {
 	DefaultNotReadyTolerationSeconds *int64 `json:"defaultNotReadyTolerationSeconds,omitempty" flag:"default-not-ready-toleration-seconds"`
 	// DefaultUnreachableTolerationSeconds
 	DefaultUnreachableTolerationSeconds *int64 `json:"defaultUnreachableTolerationSeconds,omitempty" flag:"default-unreachable-toleration-seconds"`
+
+	// Auditing configures auditing of the requests made to the API server.
+	// It sets the Audit* flags, which should not be set when this is used.
+	Auditing *KubeAPIServerAuditingSpec `json:"auditing,omitempty" flag:"-"`
+}
+
+// KubeAPIServerAuditingSpec configures auditing of the requests made to the API server.
+type KubeAPIServerAuditingSpec struct {
+	// Policy is the audit policy, an audit.k8s.io Policy object in YAML format.
+	Policy string `json:"policy,omitempty"`
+	// Log configures the backend writing the audit events to a log file on the control plane nodes.
+	Log *AuditLogBackendSpec `json:"log,omitempty"`
+	// Webhook configures the backend sending the audit events to an external API.
+	Webhook *AuditWebhookBackendSpec `json:"webhook,omitempty"`
+	// Dynamic enables dynamic audit configuration via AuditSink objects.
+	// Only supported by Kubernetes versions older than 1.19.
+	Dynamic *bool `json:"dynamic,omitempty"`
+}
+
+// AuditLogBackendSpec configures the audit log backend.
+type AuditLogBackendSpec struct {
+	// Path is the path of the audit log file on the control plane nodes. Defaults to /var/log/kube-apiserver-audit.log.
+	// Set to "-" to write the events to standard out.
+	Path string `json:"path,omitempty"`
+	// Format is the format of the audit log file, "json" or "legacy". Defaults to "json".
+	Format string `json:"format,omitempty"`
+	// MaxAge is the maximum number of days to retain old audit log files.
+	MaxAge *int32 `json:"maxAge,omitempty"`
+	// MaxBackups is the maximum number of old audit log files to retain.
+	MaxBackups *int32 `json:"maxBackups,omitempty"`
+	// MaxSize is the maximum size in megabytes of the audit log file before it gets rotated by the API server.
+	// When not set, the audit log file is rotated by logrotate.
+	MaxSize *int32 `json:"maxSize,omitempty"`
+}
+
+// AuditWebhookBackendSpec configures the audit webhook backend.
+type AuditWebhookBackendSpec struct {
+	// Server is the URL of the API receiving the audit events.
+	Server string `json:"server,omitempty"`
+	// CertificateAuthority is the PEM encoded CA bundle used to verify the certificate of the server.
+	// Defaults to the system trust store.
+	CertificateAuthority string `json:"certificateAuthority,omitempty"`
+	// Mode is the strategy for sending audit events, "batch" or "blocking". Defaults to "batch".
+	Mode string `json:"mode,omitempty"`
+	// InitialBackoff is the amount of time to wait before retrying the first failed request. (default 10s)
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// BatchBufferSize is the size of the buffer to store events before batching and writing. (default 10000)
+	BatchBufferSize *int32 `json:"batchBufferSize,omitempty"`
+	// BatchMaxSize is the maximum size of a batch. (default 400)
+	BatchMaxSize *int32 `json:"batchMaxSize,omitempty"`
+	// BatchMaxWait is the amount of time to wait before force writing a batch that hasn't reached the max size. (default 30s)
+	BatchMaxWait *metav1.Duration `json:"batchMaxWait,omitempty"`
+	// BatchThrottleEnable enables throttling of the batches. (default true)
+	BatchThrottleEnable *bool `json:"batchThrottleEnable,omitempty"`
+	// BatchThrottleQPS is the maximum average number of batches per second. (default 10)
+	BatchThrottleQPS *resource.Quantity `json:"batchThrottleQPS,omitempty"`
+	// BatchThrottleBurst is the maximum number of requests sent at the same moment if BatchThrottleQPS was not utilized before. (default 15)
+	BatchThrottleBurst *int32 `json:"batchThrottleBurst,omitempty"`
 }
 
 // KubeControllerManagerConfig is the configuration for the controller
@@ -965,6 +1071,25 @@ type ClusterAutoscalerConfig struct {
 	CPURequest *resource.Quantity `json:"cpuRequest,omitempty"`
 }
 
+// KarpenterConfig determines the karpenter provisioner configuration.
+type KarpenterConfig struct {
+	// Enabled enables the karpenter provisioner.
+	// Default: false
+	Enabled *bool `json:"enabled,omitempty"`
+	// Image is the docker container used for the controller.
+	// Default: the latest supported image.
+	Image *string `json:"image,omitempty"`
+	// TTLSecondsAfterEmpty is the number of seconds a node without workload pods is kept before it is terminated.
+	// Default: 30
+	TTLSecondsAfterEmpty *int64 `json:"ttlSecondsAfterEmpty,omitempty"`
+	// MemoryRequest of karpenter controller container.
+	// Default: 300Mi
+	MemoryRequest *resource.Quantity `json:"memoryRequest,omitempty"`
+	// CPURequest of karpenter controller container.
+	// Default: 100m
+	CPURequest *resource.Quantity `json:"cpuRequest,omitempty"`
+}
+
 // MetricsServerConfig determines the metrics server configuration.
 type MetricsServerConfig struct {
 	// Enabled enables the metrics server.
@@ -150,6 +150,82 @@ type InstanceGroupSpec struct {
 	UpdatePolicy *string `json:"updatePolicy,omitempty"`
 	// WarmPool configures an ASG warm pool for the instance group
 	WarmPool *WarmPoolSpec `json:"warmPool,omitempty"`
+	// Containerd overrides the containerd config from the ClusterSpec.
+	// Only the runtimes can be overridden.
+	Containerd *ContainerdConfig `json:"containerd,omitempty"`
+	// Hosts are the pre-provisioned machines of the instance group (metal only).
+	Hosts []StaticHostSpec `json:"hosts,omitempty"`
+	// ScheduledScaling changes the size of the instance group on a recurring schedule (AWS only).
+	ScheduledScaling []ScheduledScalingSpec `json:"scheduledScaling,omitempty"`
+	// CapacityReservation targets EC2 On-Demand Capacity Reservations with the instances (AWS only).
+	CapacityReservation *CapacityReservationSpec `json:"capacityReservation,omitempty"`
+	// PlacementGroup launches the instances in an EC2 placement group (AWS only).
+	PlacementGroup *PlacementGroupSpec `json:"placementGroup,omitempty"`
+	// Manager determines what creates the instances of the group.
+	// Valid values:
+	//   'CloudGroup' (default): the instances are managed by a cloud group, such as an AWS Autoscaling Group
+	//   'Provisioner': kops renders the launch template only; the karpenter addon creates instances for pending pods (AWS only)
+	Manager InstanceManager `json:"manager,omitempty"`
+}
+
+// InstanceManager describes what creates the instances of an instance group
+type InstanceManager string
+
+const (
+	// InstanceManagerCloudGroup means the instances are managed by a cloud group
+	InstanceManagerCloudGroup InstanceManager = "CloudGroup"
+	// InstanceManagerProvisioner means the instances are created on demand by a provisioner addon
+	InstanceManagerProvisioner InstanceManager = "Provisioner"
+)
+
+// StaticHostSpec is a pre-provisioned machine, configured over SSH
+type StaticHostSpec struct {
+	// Name is the name of the host, which must be the name of its node
+	Name string `json:"name,omitempty"`
+	// Address is the address at which the host is reached over SSH, and at which the other hosts reach it
+	Address string `json:"address,omitempty"`
+	// SSHUser is the user to log in as over SSH; it must be root or be allowed to run sudo without a password. Defaults to root.
+	SSHUser string `json:"sshUser,omitempty"`
+	// SSHPort is the port of the SSH server of the host. Defaults to 22.
+	SSHPort *int32 `json:"sshPort,omitempty"`
+}
+
+// ScheduledScalingSpec is a recurring change of the size of an instance group
+type ScheduledScalingSpec struct {
+	// Name identifies the schedule within the instance group
+	Name string `json:"name,omitempty"`
+	// Recurrence is when the size changes, as a cron expression with five fields, e.g. "0 8 * * MON-FRI"
+	Recurrence string `json:"recurrence,omitempty"`
+	// TimeZone is the IANA time zone of the recurrence, e.g. "Europe/Paris". Defaults to UTC.
+	TimeZone *string `json:"timeZone,omitempty"`
+	// MinSize is the minimum size of the instance group from the recurrence on
+	MinSize *int32 `json:"minSize,omitempty"`
+	// MaxSize is the maximum size of the instance group from the recurrence on
+	MaxSize *int32 `json:"maxSize,omitempty"`
+	// DesiredCapacity is the number of instances to scale to at the recurrence
+	DesiredCapacity *int32 `json:"desiredCapacity,omitempty"`
+}
+
+// CapacityReservationSpec configures the use of EC2 On-Demand Capacity Reservations by the instances
+type CapacityReservationSpec struct {
+	// Preference is "open" to launch the instances in any open capacity reservation with matching attributes,
+	// the AWS default, or "none" to launch them outside of capacity reservations.
+	// It cannot be set together with id or resourceGroupARN.
+	Preference *string `json:"preference,omitempty"`
+	// ID is the ID of the capacity reservation to launch the instances in.
+	ID *string `json:"id,omitempty"`
+	// ResourceGroupARN is the ARN of the resource group of capacity reservations to launch the instances in.
+	ResourceGroupARN *string `json:"resourceGroupARN,omitempty"`
+}
+
+// PlacementGroupSpec configures the EC2 placement group of the instances
+type PlacementGroupSpec struct {
+	// Name is the name of an existing placement group. If not set, kOps manages a placement group for the instance group.
+	Name *string `json:"name,omitempty"`
+	// Strategy is the strategy of the placement group managed by kOps: cluster, partition or spread.
+	Strategy string `json:"strategy,omitempty"`
+	// PartitionCount is the number of partitions of a placement group with the partition strategy, from 1 to 7. Defaults to 2.
+	PartitionCount *int64 `json:"partitionCount,omitempty"`
 }
 
 // InstanceMetadataOptions defines the EC2 instance metadata service options (AWS Only)
@@ -245,4 +321,37 @@ type LoadBalancer struct {
 	LoadBalancerName *string `json:"loadBalancerName,omitempty"`
 	// TargetGroupARN to associate with this instance group (AWS ALB/NLB)
 	TargetGroupARN *string `json:"targetGroupArn,omitempty"`
+	// TargetGroup is a target group created and managed by kOps for this instance group (AWS ALB/NLB)
+	TargetGroup *TargetGroupSpec `json:"targetGroup,omitempty"`
+}
+
+// TargetGroupSpec configures a target group managed by kOps
+type TargetGroupSpec struct {
+	// Name of the target group. Defaults to a name derived from the cluster, the instance group and the port.
+	Name *string `json:"name,omitempty"`
+	// Protocol used to route traffic to the instances: TCP, TLS, UDP or TCP_UDP for NLBs, HTTP or HTTPS for ALBs.
+	Protocol string `json:"protocol,omitempty"`
+	// Port on which the instances receive traffic.
+	Port int64 `json:"port,omitempty"`
+	// DeregistrationDelaySeconds is the time the load balancer waits for in-flight requests to complete
+	// before removing a deregistered instance. Defaults to 300.
+	DeregistrationDelaySeconds *int64 `json:"deregistrationDelaySeconds,omitempty"`
+	// HealthCheck configures how the load balancer checks the health of the instances.
+	HealthCheck *TargetGroupHealthCheckSpec `json:"healthCheck,omitempty"`
+}
+
+// TargetGroupHealthCheckSpec configures the health checks of a target group
+type TargetGroupHealthCheckSpec struct {
+	// Protocol used for the health checks: TCP, HTTP or HTTPS. Defaults to TCP for NLBs and to the target group protocol for ALBs.
+	Protocol string `json:"protocol,omitempty"`
+	// Port used for the health checks. Defaults to the port on which the instances receive traffic.
+	Port *int64 `json:"port,omitempty"`
+	// Path is the destination of HTTP and HTTPS health checks.
+	Path string `json:"path,omitempty"`
+	// IntervalSeconds is the time between health checks.
+	IntervalSeconds *int64 `json:"intervalSeconds,omitempty"`
+	// HealthyThreshold is the number of consecutive successful health checks required to consider an instance healthy.
+	HealthyThreshold *int64 `json:"healthyThreshold,omitempty"`
+	// UnhealthyThreshold is the number of consecutive failed health checks required to consider an instance unhealthy.
+	UnhealthyThreshold *int64 `json:"unhealthyThreshold,omitempty"`
 }
@@ -113,6 +113,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*AuditLogBackendSpec)(nil), (*kops.AuditLogBackendSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_AuditLogBackendSpec_To_kops_AuditLogBackendSpec(a.(*AuditLogBackendSpec), b.(*kops.AuditLogBackendSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.AuditLogBackendSpec)(nil), (*AuditLogBackendSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_AuditLogBackendSpec_To_v1alpha2_AuditLogBackendSpec(a.(*kops.AuditLogBackendSpec), b.(*AuditLogBackendSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*AuditWebhookBackendSpec)(nil), (*kops.AuditWebhookBackendSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_AuditWebhookBackendSpec_To_kops_AuditWebhookBackendSpec(a.(*AuditWebhookBackendSpec), b.(*kops.AuditWebhookBackendSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.AuditWebhookBackendSpec)(nil), (*AuditWebhookBackendSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_AuditWebhookBackendSpec_To_v1alpha2_AuditWebhookBackendSpec(a.(*kops.AuditWebhookBackendSpec), b.(*AuditWebhookBackendSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*AuthenticationSpec)(nil), (*kops.AuthenticationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_AuthenticationSpec_To_kops_AuthenticationSpec(a.(*AuthenticationSpec), b.(*kops.AuthenticationSpec), scope)
 	}); err != nil {
@@ -203,6 +223,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CapacityReservationSpec)(nil), (*kops.CapacityReservationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CapacityReservationSpec_To_kops_CapacityReservationSpec(a.(*CapacityReservationSpec), b.(*kops.CapacityReservationSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.CapacityReservationSpec)(nil), (*CapacityReservationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_CapacityReservationSpec_To_v1alpha2_CapacityReservationSpec(a.(*kops.CapacityReservationSpec), b.(*CapacityReservationSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CertManagerConfig)(nil), (*kops.CertManagerConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_CertManagerConfig_To_kops_CertManagerConfig(a.(*CertManagerConfig), b.(*kops.CertManagerConfig), scope)
 	}); err != nil {
@@ -313,6 +343,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ContainerdRuntimeSpec)(nil), (*kops.ContainerdRuntimeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ContainerdRuntimeSpec_To_kops_ContainerdRuntimeSpec(a.(*ContainerdRuntimeSpec), b.(*kops.ContainerdRuntimeSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ContainerdRuntimeSpec)(nil), (*ContainerdRuntimeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ContainerdRuntimeSpec_To_v1alpha2_ContainerdRuntimeSpec(a.(*kops.ContainerdRuntimeSpec), b.(*ContainerdRuntimeSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DNSAccessSpec)(nil), (*kops.DNSAccessSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_DNSAccessSpec_To_kops_DNSAccessSpec(a.(*DNSAccessSpec), b.(*kops.DNSAccessSpec), scope)
 	}); err != nil {
@@ -553,6 +593,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ImageDigestsSpec)(nil), (*kops.ImageDigestsSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ImageDigestsSpec_To_kops_ImageDigestsSpec(a.(*ImageDigestsSpec), b.(*kops.ImageDigestsSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ImageDigestsSpec)(nil), (*ImageDigestsSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ImageDigestsSpec_To_v1alpha2_ImageDigestsSpec(a.(*kops.ImageDigestsSpec), b.(*ImageDigestsSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*InstanceGroup)(nil), (*kops.InstanceGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_InstanceGroup_To_kops_InstanceGroup(a.(*InstanceGroup), b.(*kops.InstanceGroup), scope)
 	}); err != nil {
@@ -593,6 +643,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*JSON)(nil), (*kops.JSON)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_JSON_To_kops_JSON(a.(*JSON), b.(*kops.JSON), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.JSON)(nil), (*JSON)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_JSON_To_v1alpha2_JSON(a.(*kops.JSON), b.(*JSON), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*KarpenterConfig)(nil), (*kops.KarpenterConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_KarpenterConfig_To_kops_KarpenterConfig(a.(*KarpenterConfig), b.(*kops.KarpenterConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KarpenterConfig)(nil), (*KarpenterConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig(a.(*kops.KarpenterConfig), b.(*KarpenterConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Keyset)(nil), (*kops.Keyset)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_Keyset_To_kops_Keyset(a.(*Keyset), b.(*kops.Keyset), scope)
 	}); err != nil {
@@ -653,6 +723,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*KubeAPIServerAuditingSpec)(nil), (*kops.KubeAPIServerAuditingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_KubeAPIServerAuditingSpec_To_kops_KubeAPIServerAuditingSpec(a.(*KubeAPIServerAuditingSpec), b.(*kops.KubeAPIServerAuditingSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KubeAPIServerAuditingSpec)(nil), (*KubeAPIServerAuditingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KubeAPIServerAuditingSpec_To_v1alpha2_KubeAPIServerAuditingSpec(a.(*kops.KubeAPIServerAuditingSpec), b.(*KubeAPIServerAuditingSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*KubeAPIServerConfig)(nil), (*kops.KubeAPIServerConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_KubeAPIServerConfig_To_kops_KubeAPIServerConfig(a.(*KubeAPIServerConfig), b.(*kops.KubeAPIServerConfig), scope)
 	}); err != nil {
@@ -713,6 +793,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*KubeletMemoryReservation)(nil), (*kops.KubeletMemoryReservation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_KubeletMemoryReservation_To_kops_KubeletMemoryReservation(a.(*KubeletMemoryReservation), b.(*kops.KubeletMemoryReservation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.KubeletMemoryReservation)(nil), (*KubeletMemoryReservation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_KubeletMemoryReservation_To_v1alpha2_KubeletMemoryReservation(a.(*kops.KubeletMemoryReservation), b.(*KubeletMemoryReservation), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*KubenetNetworkingSpec)(nil), (*kops.KubenetNetworkingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_KubenetNetworkingSpec_To_kops_KubenetNetworkingSpec(a.(*KubenetNetworkingSpec), b.(*kops.KubenetNetworkingSpec), scope)
 	}); err != nil {
@@ -943,6 +1033,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PlacementGroupSpec)(nil), (*kops.PlacementGroupSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(a.(*PlacementGroupSpec), b.(*kops.PlacementGroupSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.PlacementGroupSpec)(nil), (*PlacementGroupSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(a.(*kops.PlacementGroupSpec), b.(*PlacementGroupSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*RBACAuthorizationSpec)(nil), (*kops.RBACAuthorizationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(a.(*RBACAuthorizationSpec), b.(*kops.RBACAuthorizationSpec), scope)
 	}); err != nil {
@@ -953,6 +1053,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ReconcileStatus)(nil), (*kops.ReconcileStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ReconcileStatus_To_kops_ReconcileStatus(a.(*ReconcileStatus), b.(*kops.ReconcileStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ReconcileStatus)(nil), (*ReconcileStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ReconcileStatus_To_v1alpha2_ReconcileStatus(a.(*kops.ReconcileStatus), b.(*ReconcileStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*RollingUpdate)(nil), (*kops.RollingUpdate)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_RollingUpdate_To_kops_RollingUpdate(a.(*RollingUpdate), b.(*kops.RollingUpdate), scope)
 	}); err != nil {
@@ -1003,6 +1113,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ScheduledScalingSpec)(nil), (*kops.ScheduledScalingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ScheduledScalingSpec_To_kops_ScheduledScalingSpec(a.(*ScheduledScalingSpec), b.(*kops.ScheduledScalingSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ScheduledScalingSpec)(nil), (*ScheduledScalingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ScheduledScalingSpec_To_v1alpha2_ScheduledScalingSpec(a.(*kops.ScheduledScalingSpec), b.(*ScheduledScalingSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ServiceAccountExternalPermission)(nil), (*kops.ServiceAccountExternalPermission)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_ServiceAccountExternalPermission_To_kops_ServiceAccountExternalPermission(a.(*ServiceAccountExternalPermission), b.(*kops.ServiceAccountExternalPermission), scope)
 	}); err != nil {
@@ -1033,6 +1153,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*StaticHostSpec)(nil), (*kops.StaticHostSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_StaticHostSpec_To_kops_StaticHostSpec(a.(*StaticHostSpec), b.(*kops.StaticHostSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.StaticHostSpec)(nil), (*StaticHostSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_StaticHostSpec_To_v1alpha2_StaticHostSpec(a.(*kops.StaticHostSpec), b.(*StaticHostSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*TargetGroupHealthCheckSpec)(nil), (*kops.TargetGroupHealthCheckSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_TargetGroupHealthCheckSpec_To_kops_TargetGroupHealthCheckSpec(a.(*TargetGroupHealthCheckSpec), b.(*kops.TargetGroupHealthCheckSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.TargetGroupHealthCheckSpec)(nil), (*TargetGroupHealthCheckSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_TargetGroupHealthCheckSpec_To_v1alpha2_TargetGroupHealthCheckSpec(a.(*kops.TargetGroupHealthCheckSpec), b.(*TargetGroupHealthCheckSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*TargetGroupSpec)(nil), (*kops.TargetGroupSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_TargetGroupSpec_To_kops_TargetGroupSpec(a.(*TargetGroupSpec), b.(*kops.TargetGroupSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.TargetGroupSpec)(nil), (*TargetGroupSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_TargetGroupSpec_To_v1alpha2_TargetGroupSpec(a.(*kops.TargetGroupSpec), b.(*TargetGroupSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*TargetSpec)(nil), (*kops.TargetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_TargetSpec_To_kops_TargetSpec(a.(*TargetSpec), b.(*kops.TargetSpec), scope)
 	}); err != nil {
@@ -1324,6 +1474,15 @@ func autoConvert_v1alpha2_Assets_To_kops_Assets(in *Assets, out *kops.Assets, s
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	if in.ImageDigests != nil {
+		in, out := &in.ImageDigests, &out.ImageDigests
+		*out = new(kops.ImageDigestsSpec)
+		if err := Convert_v1alpha2_ImageDigestsSpec_To_kops_ImageDigestsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ImageDigests = nil
+	}
 	return nil
 }
 
@@ -1336,6 +1495,15 @@ func autoConvert_kops_Assets_To_v1alpha2_Assets(in *kops.Assets, out *Assets, s
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	if in.ImageDigests != nil {
+		in, out := &in.ImageDigests, &out.ImageDigests
+		*out = new(ImageDigestsSpec)
+		if err := Convert_kops_ImageDigestsSpec_To_v1alpha2_ImageDigestsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ImageDigests = nil
+	}
 	return nil
 }
 
@@ -1344,6 +1512,72 @@ func Convert_kops_Assets_To_v1alpha2_Assets(in *kops.Assets, out *Assets, s conv
 	return autoConvert_kops_Assets_To_v1alpha2_Assets(in, out, s)
 }
 
+func autoConvert_v1alpha2_AuditLogBackendSpec_To_kops_AuditLogBackendSpec(in *AuditLogBackendSpec, out *kops.AuditLogBackendSpec, s conversion.Scope) error {
+	out.Path = in.Path
+	out.Format = in.Format
+	out.MaxAge = in.MaxAge
+	out.MaxBackups = in.MaxBackups
+	out.MaxSize = in.MaxSize
+	return nil
+}
+
+// Convert_v1alpha2_AuditLogBackendSpec_To_kops_AuditLogBackendSpec is an autogenerated conversion function.
+func Convert_v1alpha2_AuditLogBackendSpec_To_kops_AuditLogBackendSpec(in *AuditLogBackendSpec, out *kops.AuditLogBackendSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AuditLogBackendSpec_To_kops_AuditLogBackendSpec(in, out, s)
+}
+
+func autoConvert_kops_AuditLogBackendSpec_To_v1alpha2_AuditLogBackendSpec(in *kops.AuditLogBackendSpec, out *AuditLogBackendSpec, s conversion.Scope) error {
+	out.Path = in.Path
+	out.Format = in.Format
+	out.MaxAge = in.MaxAge
+	out.MaxBackups = in.MaxBackups
+	out.MaxSize = in.MaxSize
+	return nil
+}
+
+// Convert_kops_AuditLogBackendSpec_To_v1alpha2_AuditLogBackendSpec is an autogenerated conversion function.
+func Convert_kops_AuditLogBackendSpec_To_v1alpha2_AuditLogBackendSpec(in *kops.AuditLogBackendSpec, out *AuditLogBackendSpec, s conversion.Scope) error {
+	return autoConvert_kops_AuditLogBackendSpec_To_v1alpha2_AuditLogBackendSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_AuditWebhookBackendSpec_To_kops_AuditWebhookBackendSpec(in *AuditWebhookBackendSpec, out *kops.AuditWebhookBackendSpec, s conversion.Scope) error {
+	out.Server = in.Server
+	out.CertificateAuthority = in.CertificateAuthority
+	out.Mode = in.Mode
+	out.InitialBackoff = in.InitialBackoff
+	out.BatchBufferSize = in.BatchBufferSize
+	out.BatchMaxSize = in.BatchMaxSize
+	out.BatchMaxWait = in.BatchMaxWait
+	out.BatchThrottleEnable = in.BatchThrottleEnable
+	out.BatchThrottleQPS = in.BatchThrottleQPS
+	out.BatchThrottleBurst = in.BatchThrottleBurst
+	return nil
+}
+
+// Convert_v1alpha2_AuditWebhookBackendSpec_To_kops_AuditWebhookBackendSpec is an autogenerated conversion function.
+func Convert_v1alpha2_AuditWebhookBackendSpec_To_kops_AuditWebhookBackendSpec(in *AuditWebhookBackendSpec, out *kops.AuditWebhookBackendSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AuditWebhookBackendSpec_To_kops_AuditWebhookBackendSpec(in, out, s)
+}
+
+func autoConvert_kops_AuditWebhookBackendSpec_To_v1alpha2_AuditWebhookBackendSpec(in *kops.AuditWebhookBackendSpec, out *AuditWebhookBackendSpec, s conversion.Scope) error {
+	out.Server = in.Server
+	out.CertificateAuthority = in.CertificateAuthority
+	out.Mode = in.Mode
+	out.InitialBackoff = in.InitialBackoff
+	out.BatchBufferSize = in.BatchBufferSize
+	out.BatchMaxSize = in.BatchMaxSize
+	out.BatchMaxWait = in.BatchMaxWait
+	out.BatchThrottleEnable = in.BatchThrottleEnable
+	out.BatchThrottleQPS = in.BatchThrottleQPS
+	out.BatchThrottleBurst = in.BatchThrottleBurst
+	return nil
+}
+
+// Convert_kops_AuditWebhookBackendSpec_To_v1alpha2_AuditWebhookBackendSpec is an autogenerated conversion function.
+func Convert_kops_AuditWebhookBackendSpec_To_v1alpha2_AuditWebhookBackendSpec(in *kops.AuditWebhookBackendSpec, out *AuditWebhookBackendSpec, s conversion.Scope) error {
+	return autoConvert_kops_AuditWebhookBackendSpec_To_v1alpha2_AuditWebhookBackendSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_AuthenticationSpec_To_kops_AuthenticationSpec(in *AuthenticationSpec, out *kops.AuthenticationSpec, s conversion.Scope) error {
 	if in.Kopeio != nil {
 		in, out := &in.Kopeio, &out.Kopeio
@@ -1712,6 +1946,30 @@ func Convert_kops_CanalNetworkingSpec_To_v1alpha2_CanalNetworkingSpec(in *kops.C
 	return autoConvert_kops_CanalNetworkingSpec_To_v1alpha2_CanalNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_CapacityReservationSpec_To_kops_CapacityReservationSpec(in *CapacityReservationSpec, out *kops.CapacityReservationSpec, s conversion.Scope) error {
+	out.Preference = in.Preference
+	out.ID = in.ID
+	out.ResourceGroupARN = in.ResourceGroupARN
+	return nil
+}
+
+// Convert_v1alpha2_CapacityReservationSpec_To_kops_CapacityReservationSpec is an autogenerated conversion function.
+func Convert_v1alpha2_CapacityReservationSpec_To_kops_CapacityReservationSpec(in *CapacityReservationSpec, out *kops.CapacityReservationSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CapacityReservationSpec_To_kops_CapacityReservationSpec(in, out, s)
+}
+
+func autoConvert_kops_CapacityReservationSpec_To_v1alpha2_CapacityReservationSpec(in *kops.CapacityReservationSpec, out *CapacityReservationSpec, s conversion.Scope) error {
+	out.Preference = in.Preference
+	out.ID = in.ID
+	out.ResourceGroupARN = in.ResourceGroupARN
+	return nil
+}
+
+// Convert_kops_CapacityReservationSpec_To_v1alpha2_CapacityReservationSpec is an autogenerated conversion function.
+func Convert_kops_CapacityReservationSpec_To_v1alpha2_CapacityReservationSpec(in *kops.CapacityReservationSpec, out *CapacityReservationSpec, s conversion.Scope) error {
+	return autoConvert_kops_CapacityReservationSpec_To_v1alpha2_CapacityReservationSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_CertManagerConfig_To_kops_CertManagerConfig(in *CertManagerConfig, out *kops.CertManagerConfig, s conversion.Scope) error {
 	out.Enabled = in.Enabled
 	out.Managed = in.Managed
@@ -2135,6 +2393,15 @@ func autoConvert_v1alpha2_Cluster_To_kops_Cluster(in *Cluster, out *kops.Cluster
 	if err := Convert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(&in.Spec, &out.Spec, s); err != nil {
 		return err
 	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(kops.ReconcileStatus)
+		if err := Convert_v1alpha2_ReconcileStatus_To_kops_ReconcileStatus(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Status = nil
+	}
 	return nil
 }
 
@@ -2148,6 +2415,15 @@ func autoConvert_kops_Cluster_To_v1alpha2_Cluster(in *kops.Cluster, out *Cluster
 	if err := Convert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(&in.Spec, &out.Spec, s); err != nil {
 		return err
 	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(ReconcileStatus)
+		if err := Convert_kops_ReconcileStatus_To_v1alpha2_ReconcileStatus(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Status = nil
+	}
 	return nil
 }
 
@@ -2615,6 +2891,15 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.ClusterAutoscaler = nil
 	}
+	if in.Karpenter != nil {
+		in, out := &in.Karpenter, &out.Karpenter
+		*out = new(kops.KarpenterConfig)
+		if err := Convert_v1alpha2_KarpenterConfig_To_kops_KarpenterConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Karpenter = nil
+	}
 	if in.WarmPool != nil {
 		in, out := &in.WarmPool, &out.WarmPool
 		*out = new(kops.WarmPoolSpec)
@@ -3027,6 +3312,15 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.ClusterAutoscaler = nil
 	}
+	if in.Karpenter != nil {
+		in, out := &in.Karpenter, &out.Karpenter
+		*out = new(KarpenterConfig)
+		if err := Convert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Karpenter = nil
+	}
 	if in.WarmPool != nil {
 		in, out := &in.WarmPool, &out.WarmPool
 		*out = new(WarmPoolSpec)
@@ -3113,6 +3407,17 @@ func autoConvert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(in *Containe
 	}
 	out.RegistryMirrors = in.RegistryMirrors
 	out.Root = in.Root
+	if in.Runtimes != nil {
+		in, out := &in.Runtimes, &out.Runtimes
+		*out = make([]kops.ContainerdRuntimeSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_ContainerdRuntimeSpec_To_kops_ContainerdRuntimeSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Runtimes = nil
+	}
 	out.SkipInstall = in.SkipInstall
 	out.State = in.State
 	out.Version = in.Version
@@ -3139,6 +3444,17 @@ func autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.Con
 	}
 	out.RegistryMirrors = in.RegistryMirrors
 	out.Root = in.Root
+	if in.Runtimes != nil {
+		in, out := &in.Runtimes, &out.Runtimes
+		*out = make([]ContainerdRuntimeSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_ContainerdRuntimeSpec_To_v1alpha2_ContainerdRuntimeSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Runtimes = nil
+	}
 	out.SkipInstall = in.SkipInstall
 	out.State = in.State
 	out.Version = in.Version
@@ -3150,6 +3466,48 @@ func Convert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.Contain
 	return autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_ContainerdRuntimeSpec_To_kops_ContainerdRuntimeSpec(in *ContainerdRuntimeSpec, out *kops.ContainerdRuntimeSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = kops.ContainerdRuntimeType(in.Type)
+	out.Version = in.Version
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = new(kops.PackagesConfig)
+		if err := Convert_v1alpha2_PackagesConfig_To_kops_PackagesConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Packages = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_ContainerdRuntimeSpec_To_kops_ContainerdRuntimeSpec is an autogenerated conversion function.
+func Convert_v1alpha2_ContainerdRuntimeSpec_To_kops_ContainerdRuntimeSpec(in *ContainerdRuntimeSpec, out *kops.ContainerdRuntimeSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ContainerdRuntimeSpec_To_kops_ContainerdRuntimeSpec(in, out, s)
+}
+
+func autoConvert_kops_ContainerdRuntimeSpec_To_v1alpha2_ContainerdRuntimeSpec(in *kops.ContainerdRuntimeSpec, out *ContainerdRuntimeSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = ContainerdRuntimeType(in.Type)
+	out.Version = in.Version
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = new(PackagesConfig)
+		if err := Convert_kops_PackagesConfig_To_v1alpha2_PackagesConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Packages = nil
+	}
+	return nil
+}
+
+// Convert_kops_ContainerdRuntimeSpec_To_v1alpha2_ContainerdRuntimeSpec is an autogenerated conversion function.
+func Convert_kops_ContainerdRuntimeSpec_To_v1alpha2_ContainerdRuntimeSpec(in *kops.ContainerdRuntimeSpec, out *ContainerdRuntimeSpec, s conversion.Scope) error {
+	return autoConvert_kops_ContainerdRuntimeSpec_To_v1alpha2_ContainerdRuntimeSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_DNSAccessSpec_To_kops_DNSAccessSpec(in *DNSAccessSpec, out *kops.DNSAccessSpec, s conversion.Scope) error {
 	return nil
 }
@@ -3992,6 +4350,28 @@ func Convert_kops_IAMSpec_To_v1alpha2_IAMSpec(in *kops.IAMSpec, out *IAMSpec, s
 	return autoConvert_kops_IAMSpec_To_v1alpha2_IAMSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_ImageDigestsSpec_To_kops_ImageDigestsSpec(in *ImageDigestsSpec, out *kops.ImageDigestsSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.SignaturePublicKey = in.SignaturePublicKey
+	return nil
+}
+
+// Convert_v1alpha2_ImageDigestsSpec_To_kops_ImageDigestsSpec is an autogenerated conversion function.
+func Convert_v1alpha2_ImageDigestsSpec_To_kops_ImageDigestsSpec(in *ImageDigestsSpec, out *kops.ImageDigestsSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ImageDigestsSpec_To_kops_ImageDigestsSpec(in, out, s)
+}
+
+func autoConvert_kops_ImageDigestsSpec_To_v1alpha2_ImageDigestsSpec(in *kops.ImageDigestsSpec, out *ImageDigestsSpec, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.SignaturePublicKey = in.SignaturePublicKey
+	return nil
+}
+
+// Convert_kops_ImageDigestsSpec_To_v1alpha2_ImageDigestsSpec is an autogenerated conversion function.
+func Convert_kops_ImageDigestsSpec_To_v1alpha2_ImageDigestsSpec(in *kops.ImageDigestsSpec, out *ImageDigestsSpec, s conversion.Scope) error {
+	return autoConvert_kops_ImageDigestsSpec_To_v1alpha2_ImageDigestsSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_InstanceGroup_To_kops_InstanceGroup(in *InstanceGroup, out *kops.InstanceGroup, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -4214,6 +4594,56 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	} else {
 		out.WarmPool = nil
 	}
+	if in.Containerd != nil {
+		in, out := &in.Containerd, &out.Containerd
+		*out = new(kops.ContainerdConfig)
+		if err := Convert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Containerd = nil
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]kops.StaticHostSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_StaticHostSpec_To_kops_StaticHostSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Hosts = nil
+	}
+	if in.ScheduledScaling != nil {
+		in, out := &in.ScheduledScaling, &out.ScheduledScaling
+		*out = make([]kops.ScheduledScalingSpec, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_ScheduledScalingSpec_To_kops_ScheduledScalingSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ScheduledScaling = nil
+	}
+	if in.CapacityReservation != nil {
+		in, out := &in.CapacityReservation, &out.CapacityReservation
+		*out = new(kops.CapacityReservationSpec)
+		if err := Convert_v1alpha2_CapacityReservationSpec_To_kops_CapacityReservationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CapacityReservation = nil
+	}
+	if in.PlacementGroup != nil {
+		in, out := &in.PlacementGroup, &out.PlacementGroup
+		*out = new(kops.PlacementGroupSpec)
+		if err := Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PlacementGroup = nil
+	}
+	out.Manager = kops.InstanceManager(in.Manager)
 	return nil
 }
 
@@ -4376,6 +4806,56 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 	} else {
 		out.WarmPool = nil
 	}
+	if in.Containerd != nil {
+		in, out := &in.Containerd, &out.Containerd
+		*out = new(ContainerdConfig)
+		if err := Convert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Containerd = nil
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]StaticHostSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_StaticHostSpec_To_v1alpha2_StaticHostSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Hosts = nil
+	}
+	if in.ScheduledScaling != nil {
+		in, out := &in.ScheduledScaling, &out.ScheduledScaling
+		*out = make([]ScheduledScalingSpec, len(*in))
+		for i := range *in {
+			if err := Convert_kops_ScheduledScalingSpec_To_v1alpha2_ScheduledScalingSpec(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ScheduledScaling = nil
+	}
+	if in.CapacityReservation != nil {
+		in, out := &in.CapacityReservation, &out.CapacityReservation
+		*out = new(CapacityReservationSpec)
+		if err := Convert_kops_CapacityReservationSpec_To_v1alpha2_CapacityReservationSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CapacityReservation = nil
+	}
+	if in.PlacementGroup != nil {
+		in, out := &in.PlacementGroup, &out.PlacementGroup
+		*out = new(PlacementGroupSpec)
+		if err := Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PlacementGroup = nil
+	}
+	out.Manager = InstanceManager(in.Manager)
 	return nil
 }
 
@@ -4406,6 +4886,54 @@ func Convert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions(in
 	return autoConvert_kops_InstanceMetadataOptions_To_v1alpha2_InstanceMetadataOptions(in, out, s)
 }
 
+func autoConvert_v1alpha2_JSON_To_kops_JSON(in *JSON, out *kops.JSON, s conversion.Scope) error {
+	out.Raw = in.Raw
+	return nil
+}
+
+// Convert_v1alpha2_JSON_To_kops_JSON is an autogenerated conversion function.
+func Convert_v1alpha2_JSON_To_kops_JSON(in *JSON, out *kops.JSON, s conversion.Scope) error {
+	return autoConvert_v1alpha2_JSON_To_kops_JSON(in, out, s)
+}
+
+func autoConvert_kops_JSON_To_v1alpha2_JSON(in *kops.JSON, out *JSON, s conversion.Scope) error {
+	out.Raw = in.Raw
+	return nil
+}
+
+// Convert_kops_JSON_To_v1alpha2_JSON is an autogenerated conversion function.
+func Convert_kops_JSON_To_v1alpha2_JSON(in *kops.JSON, out *JSON, s conversion.Scope) error {
+	return autoConvert_kops_JSON_To_v1alpha2_JSON(in, out, s)
+}
+
+func autoConvert_v1alpha2_KarpenterConfig_To_kops_KarpenterConfig(in *KarpenterConfig, out *kops.KarpenterConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Image = in.Image
+	out.TTLSecondsAfterEmpty = in.TTLSecondsAfterEmpty
+	out.MemoryRequest = in.MemoryRequest
+	out.CPURequest = in.CPURequest
+	return nil
+}
+
+// Convert_v1alpha2_KarpenterConfig_To_kops_KarpenterConfig is an autogenerated conversion function.
+func Convert_v1alpha2_KarpenterConfig_To_kops_KarpenterConfig(in *KarpenterConfig, out *kops.KarpenterConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha2_KarpenterConfig_To_kops_KarpenterConfig(in, out, s)
+}
+
+func autoConvert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig(in *kops.KarpenterConfig, out *KarpenterConfig, s conversion.Scope) error {
+	out.Enabled = in.Enabled
+	out.Image = in.Image
+	out.TTLSecondsAfterEmpty = in.TTLSecondsAfterEmpty
+	out.MemoryRequest = in.MemoryRequest
+	out.CPURequest = in.CPURequest
+	return nil
+}
+
+// Convert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig is an autogenerated conversion function.
+func Convert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig(in *kops.KarpenterConfig, out *KarpenterConfig, s conversion.Scope) error {
+	return autoConvert_kops_KarpenterConfig_To_v1alpha2_KarpenterConfig(in, out, s)
+}
+
 func autoConvert_v1alpha2_Keyset_To_kops_Keyset(in *Keyset, out *kops.Keyset, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha2_KeysetSpec_To_kops_KeysetSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -4588,6 +5116,64 @@ func Convert_kops_KopeioNetworkingSpec_To_v1alpha2_KopeioNetworkingSpec(in *kops
 	return autoConvert_kops_KopeioNetworkingSpec_To_v1alpha2_KopeioNetworkingSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_KubeAPIServerAuditingSpec_To_kops_KubeAPIServerAuditingSpec(in *KubeAPIServerAuditingSpec, out *kops.KubeAPIServerAuditingSpec, s conversion.Scope) error {
+	out.Policy = in.Policy
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = new(kops.AuditLogBackendSpec)
+		if err := Convert_v1alpha2_AuditLogBackendSpec_To_kops_AuditLogBackendSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Log = nil
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(kops.AuditWebhookBackendSpec)
+		if err := Convert_v1alpha2_AuditWebhookBackendSpec_To_kops_AuditWebhookBackendSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Webhook = nil
+	}
+	out.Dynamic = in.Dynamic
+	return nil
+}
+
+// Convert_v1alpha2_KubeAPIServerAuditingSpec_To_kops_KubeAPIServerAuditingSpec is an autogenerated conversion function.
+func Convert_v1alpha2_KubeAPIServerAuditingSpec_To_kops_KubeAPIServerAuditingSpec(in *KubeAPIServerAuditingSpec, out *kops.KubeAPIServerAuditingSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_KubeAPIServerAuditingSpec_To_kops_KubeAPIServerAuditingSpec(in, out, s)
+}
+
+func autoConvert_kops_KubeAPIServerAuditingSpec_To_v1alpha2_KubeAPIServerAuditingSpec(in *kops.KubeAPIServerAuditingSpec, out *KubeAPIServerAuditingSpec, s conversion.Scope) error {
+	out.Policy = in.Policy
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = new(AuditLogBackendSpec)
+		if err := Convert_kops_AuditLogBackendSpec_To_v1alpha2_AuditLogBackendSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Log = nil
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(AuditWebhookBackendSpec)
+		if err := Convert_kops_AuditWebhookBackendSpec_To_v1alpha2_AuditWebhookBackendSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Webhook = nil
+	}
+	out.Dynamic = in.Dynamic
+	return nil
+}
+
+// Convert_kops_KubeAPIServerAuditingSpec_To_v1alpha2_KubeAPIServerAuditingSpec is an autogenerated conversion function.
+func Convert_kops_KubeAPIServerAuditingSpec_To_v1alpha2_KubeAPIServerAuditingSpec(in *kops.KubeAPIServerAuditingSpec, out *KubeAPIServerAuditingSpec, s conversion.Scope) error {
+	return autoConvert_kops_KubeAPIServerAuditingSpec_To_v1alpha2_KubeAPIServerAuditingSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_KubeAPIServerConfig_To_kops_KubeAPIServerConfig(in *KubeAPIServerConfig, out *kops.KubeAPIServerConfig, s conversion.Scope) error {
 	out.Image = in.Image
 	out.DisableBasicAuth = in.DisableBasicAuth
@@ -4691,6 +5277,15 @@ func autoConvert_v1alpha2_KubeAPIServerConfig_To_kops_KubeAPIServerConfig(in *Ku
 	out.CorsAllowedOrigins = in.CorsAllowedOrigins
 	out.DefaultNotReadyTolerationSeconds = in.DefaultNotReadyTolerationSeconds
 	out.DefaultUnreachableTolerationSeconds = in.DefaultUnreachableTolerationSeconds
+	if in.Auditing != nil {
+		in, out := &in.Auditing, &out.Auditing
+		*out = new(kops.KubeAPIServerAuditingSpec)
+		if err := Convert_v1alpha2_KubeAPIServerAuditingSpec_To_kops_KubeAPIServerAuditingSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Auditing = nil
+	}
 	return nil
 }
 
@@ -4802,6 +5397,15 @@ func autoConvert_kops_KubeAPIServerConfig_To_v1alpha2_KubeAPIServerConfig(in *ko
 	out.CorsAllowedOrigins = in.CorsAllowedOrigins
 	out.DefaultNotReadyTolerationSeconds = in.DefaultNotReadyTolerationSeconds
 	out.DefaultUnreachableTolerationSeconds = in.DefaultUnreachableTolerationSeconds
+	if in.Auditing != nil {
+		in, out := &in.Auditing, &out.Auditing
+		*out = new(KubeAPIServerAuditingSpec)
+		if err := Convert_kops_KubeAPIServerAuditingSpec_To_v1alpha2_KubeAPIServerAuditingSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Auditing = nil
+	}
 	return nil
 }
 
@@ -5218,6 +5822,34 @@ func autoConvert_v1alpha2_KubeletConfigSpec_To_kops_KubeletConfigSpec(in *Kubele
 	out.ContainerLogMaxFiles = in.ContainerLogMaxFiles
 	out.EnableCadvisorJsonEndpoints = in.EnableCadvisorJsonEndpoints
 	out.PodPidsLimit = in.PodPidsLimit
+	out.ShutdownGracePeriod = in.ShutdownGracePeriod
+	out.ShutdownGracePeriodCriticalPods = in.ShutdownGracePeriodCriticalPods
+	out.MemoryManagerPolicy = in.MemoryManagerPolicy
+	if in.ReservedMemory != nil {
+		in, out := &in.ReservedMemory, &out.ReservedMemory
+		*out = make([]kops.KubeletMemoryReservation, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_KubeletMemoryReservation_To_kops_KubeletMemoryReservation(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ReservedMemory = nil
+	}
+	out.TopologyManagerScope = in.TopologyManagerScope
+	if in.ConfigFileOverrides != nil {
+		in, out := &in.ConfigFileOverrides, &out.ConfigFileOverrides
+		*out = make(map[string]kops.JSON, len(*in))
+		for key, val := range *in {
+			newVal := new(kops.JSON)
+			if err := Convert_v1alpha2_JSON_To_kops_JSON(&val, newVal, s); err != nil {
+				return err
+			}
+			(*out)[key] = *newVal
+		}
+	} else {
+		out.ConfigFileOverrides = nil
+	}
 	return nil
 }
 
@@ -5314,6 +5946,34 @@ func autoConvert_kops_KubeletConfigSpec_To_v1alpha2_KubeletConfigSpec(in *kops.K
 	out.ContainerLogMaxFiles = in.ContainerLogMaxFiles
 	out.EnableCadvisorJsonEndpoints = in.EnableCadvisorJsonEndpoints
 	out.PodPidsLimit = in.PodPidsLimit
+	out.ShutdownGracePeriod = in.ShutdownGracePeriod
+	out.ShutdownGracePeriodCriticalPods = in.ShutdownGracePeriodCriticalPods
+	out.MemoryManagerPolicy = in.MemoryManagerPolicy
+	if in.ReservedMemory != nil {
+		in, out := &in.ReservedMemory, &out.ReservedMemory
+		*out = make([]KubeletMemoryReservation, len(*in))
+		for i := range *in {
+			if err := Convert_kops_KubeletMemoryReservation_To_v1alpha2_KubeletMemoryReservation(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ReservedMemory = nil
+	}
+	out.TopologyManagerScope = in.TopologyManagerScope
+	if in.ConfigFileOverrides != nil {
+		in, out := &in.ConfigFileOverrides, &out.ConfigFileOverrides
+		*out = make(map[string]JSON, len(*in))
+		for key, val := range *in {
+			newVal := new(JSON)
+			if err := Convert_kops_JSON_To_v1alpha2_JSON(&val, newVal, s); err != nil {
+				return err
+			}
+			(*out)[key] = *newVal
+		}
+	} else {
+		out.ConfigFileOverrides = nil
+	}
 	return nil
 }
 
@@ -5322,6 +5982,28 @@ func Convert_kops_KubeletConfigSpec_To_v1alpha2_KubeletConfigSpec(in *kops.Kubel
 	return autoConvert_kops_KubeletConfigSpec_To_v1alpha2_KubeletConfigSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_KubeletMemoryReservation_To_kops_KubeletMemoryReservation(in *KubeletMemoryReservation, out *kops.KubeletMemoryReservation, s conversion.Scope) error {
+	out.NumaNode = in.NumaNode
+	out.Limits = in.Limits
+	return nil
+}
+
+// Convert_v1alpha2_KubeletMemoryReservation_To_kops_KubeletMemoryReservation is an autogenerated conversion function.
+func Convert_v1alpha2_KubeletMemoryReservation_To_kops_KubeletMemoryReservation(in *KubeletMemoryReservation, out *kops.KubeletMemoryReservation, s conversion.Scope) error {
+	return autoConvert_v1alpha2_KubeletMemoryReservation_To_kops_KubeletMemoryReservation(in, out, s)
+}
+
+func autoConvert_kops_KubeletMemoryReservation_To_v1alpha2_KubeletMemoryReservation(in *kops.KubeletMemoryReservation, out *KubeletMemoryReservation, s conversion.Scope) error {
+	out.NumaNode = in.NumaNode
+	out.Limits = in.Limits
+	return nil
+}
+
+// Convert_kops_KubeletMemoryReservation_To_v1alpha2_KubeletMemoryReservation is an autogenerated conversion function.
+func Convert_kops_KubeletMemoryReservation_To_v1alpha2_KubeletMemoryReservation(in *kops.KubeletMemoryReservation, out *KubeletMemoryReservation, s conversion.Scope) error {
+	return autoConvert_kops_KubeletMemoryReservation_To_v1alpha2_KubeletMemoryReservation(in, out, s)
+}
+
 func autoConvert_v1alpha2_KubenetNetworkingSpec_To_kops_KubenetNetworkingSpec(in *KubenetNetworkingSpec, out *kops.KubenetNetworkingSpec, s conversion.Scope) error {
 	return nil
 }
@@ -5393,6 +6075,15 @@ func Convert_kops_LeaderElectionConfiguration_To_v1alpha2_LeaderElectionConfigur
 func autoConvert_v1alpha2_LoadBalancer_To_kops_LoadBalancer(in *LoadBalancer, out *kops.LoadBalancer, s conversion.Scope) error {
 	out.LoadBalancerName = in.LoadBalancerName
 	out.TargetGroupARN = in.TargetGroupARN
+	if in.TargetGroup != nil {
+		in, out := &in.TargetGroup, &out.TargetGroup
+		*out = new(kops.TargetGroupSpec)
+		if err := Convert_v1alpha2_TargetGroupSpec_To_kops_TargetGroupSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TargetGroup = nil
+	}
 	return nil
 }
 
@@ -5404,6 +6095,15 @@ func Convert_v1alpha2_LoadBalancer_To_kops_LoadBalancer(in *LoadBalancer, out *k
 func autoConvert_kops_LoadBalancer_To_v1alpha2_LoadBalancer(in *kops.LoadBalancer, out *LoadBalancer, s conversion.Scope) error {
 	out.LoadBalancerName = in.LoadBalancerName
 	out.TargetGroupARN = in.TargetGroupARN
+	if in.TargetGroup != nil {
+		in, out := &in.TargetGroup, &out.TargetGroup
+		*out = new(TargetGroupSpec)
+		if err := Convert_kops_TargetGroupSpec_To_v1alpha2_TargetGroupSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TargetGroup = nil
+	}
 	return nil
 }
 
@@ -6308,6 +7008,30 @@ func Convert_kops_PackagesConfig_To_v1alpha2_PackagesConfig(in *kops.PackagesCon
 	return autoConvert_kops_PackagesConfig_To_v1alpha2_PackagesConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(in *PlacementGroupSpec, out *kops.PlacementGroupSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Strategy = in.Strategy
+	out.PartitionCount = in.PartitionCount
+	return nil
+}
+
+// Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec is an autogenerated conversion function.
+func Convert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(in *PlacementGroupSpec, out *kops.PlacementGroupSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_PlacementGroupSpec_To_kops_PlacementGroupSpec(in, out, s)
+}
+
+func autoConvert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(in *kops.PlacementGroupSpec, out *PlacementGroupSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Strategy = in.Strategy
+	out.PartitionCount = in.PartitionCount
+	return nil
+}
+
+// Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec is an autogenerated conversion function.
+func Convert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(in *kops.PlacementGroupSpec, out *PlacementGroupSpec, s conversion.Scope) error {
+	return autoConvert_kops_PlacementGroupSpec_To_v1alpha2_PlacementGroupSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(in *RBACAuthorizationSpec, out *kops.RBACAuthorizationSpec, s conversion.Scope) error {
 	return nil
 }
@@ -6326,10 +7050,41 @@ func Convert_kops_RBACAuthorizationSpec_To_v1alpha2_RBACAuthorizationSpec(in *ko
 	return autoConvert_kops_RBACAuthorizationSpec_To_v1alpha2_RBACAuthorizationSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_ReconcileStatus_To_kops_ReconcileStatus(in *ReconcileStatus, out *kops.ReconcileStatus, s conversion.Scope) error {
+	out.ObservedGeneration = in.ObservedGeneration
+	out.ObservedInstanceGroups = in.ObservedInstanceGroups
+	out.Phase = kops.ReconcilePhase(in.Phase)
+	out.Message = in.Message
+	out.LastUpdateTime = in.LastUpdateTime
+	out.LastAppliedTime = in.LastAppliedTime
+	return nil
+}
+
+// Convert_v1alpha2_ReconcileStatus_To_kops_ReconcileStatus is an autogenerated conversion function.
+func Convert_v1alpha2_ReconcileStatus_To_kops_ReconcileStatus(in *ReconcileStatus, out *kops.ReconcileStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ReconcileStatus_To_kops_ReconcileStatus(in, out, s)
+}
+
+func autoConvert_kops_ReconcileStatus_To_v1alpha2_ReconcileStatus(in *kops.ReconcileStatus, out *ReconcileStatus, s conversion.Scope) error {
+	out.ObservedGeneration = in.ObservedGeneration
+	out.ObservedInstanceGroups = in.ObservedInstanceGroups
+	out.Phase = ReconcilePhase(in.Phase)
+	out.Message = in.Message
+	out.LastUpdateTime = in.LastUpdateTime
+	out.LastAppliedTime = in.LastAppliedTime
+	return nil
+}
+
+// Convert_kops_ReconcileStatus_To_v1alpha2_ReconcileStatus is an autogenerated conversion function.
+func Convert_kops_ReconcileStatus_To_v1alpha2_ReconcileStatus(in *kops.ReconcileStatus, out *ReconcileStatus, s conversion.Scope) error {
+	return autoConvert_kops_ReconcileStatus_To_v1alpha2_ReconcileStatus(in, out, s)
+}
+
 func autoConvert_v1alpha2_RollingUpdate_To_kops_RollingUpdate(in *RollingUpdate, out *kops.RollingUpdate, s conversion.Scope) error {
 	out.DrainAndTerminate = in.DrainAndTerminate
 	out.MaxUnavailable = in.MaxUnavailable
 	out.MaxSurge = in.MaxSurge
+	out.InPlaceUpdates = in.InPlaceUpdates
 	return nil
 }
 
@@ -6342,6 +7097,7 @@ func autoConvert_kops_RollingUpdate_To_v1alpha2_RollingUpdate(in *kops.RollingUp
 	out.DrainAndTerminate = in.DrainAndTerminate
 	out.MaxUnavailable = in.MaxUnavailable
 	out.MaxSurge = in.MaxSurge
+	out.InPlaceUpdates = in.InPlaceUpdates
 	return nil
 }
 
@@ -6460,6 +7216,36 @@ func Convert_kops_SSHCredentialSpec_To_v1alpha2_SSHCredentialSpec(in *kops.SSHCr
 	return autoConvert_kops_SSHCredentialSpec_To_v1alpha2_SSHCredentialSpec(in, out, s)
 }
 
+func autoConvert_v1alpha2_ScheduledScalingSpec_To_kops_ScheduledScalingSpec(in *ScheduledScalingSpec, out *kops.ScheduledScalingSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Recurrence = in.Recurrence
+	out.TimeZone = in.TimeZone
+	out.MinSize = in.MinSize
+	out.MaxSize = in.MaxSize
+	out.DesiredCapacity = in.DesiredCapacity
+	return nil
+}
+
+// Convert_v1alpha2_ScheduledScalingSpec_To_kops_ScheduledScalingSpec is an autogenerated conversion function.
+func Convert_v1alpha2_ScheduledScalingSpec_To_kops_ScheduledScalingSpec(in *ScheduledScalingSpec, out *kops.ScheduledScalingSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ScheduledScalingSpec_To_kops_ScheduledScalingSpec(in, out, s)
+}
+
+func autoConvert_kops_ScheduledScalingSpec_To_v1alpha2_ScheduledScalingSpec(in *kops.ScheduledScalingSpec, out *ScheduledScalingSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Recurrence = in.Recurrence
+	out.TimeZone = in.TimeZone
+	out.MinSize = in.MinSize
+	out.MaxSize = in.MaxSize
+	out.DesiredCapacity = in.DesiredCapacity
+	return nil
+}
+
+// Convert_kops_ScheduledScalingSpec_To_v1alpha2_ScheduledScalingSpec is an autogenerated conversion function.
+func Convert_kops_ScheduledScalingSpec_To_v1alpha2_ScheduledScalingSpec(in *kops.ScheduledScalingSpec, out *ScheduledScalingSpec, s conversion.Scope) error {
+	return autoConvert_kops_ScheduledScalingSpec_To_v1alpha2_ScheduledScalingSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_ServiceAccountExternalPermission_To_kops_ServiceAccountExternalPermission(in *ServiceAccountExternalPermission, out *kops.ServiceAccountExternalPermission, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Namespace = in.Namespace
@@ -6544,6 +7330,106 @@ func Convert_kops_SnapshotControllerConfig_To_v1alpha2_SnapshotControllerConfig(
 	return autoConvert_kops_SnapshotControllerConfig_To_v1alpha2_SnapshotControllerConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_StaticHostSpec_To_kops_StaticHostSpec(in *StaticHostSpec, out *kops.StaticHostSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Address = in.Address
+	out.SSHUser = in.SSHUser
+	out.SSHPort = in.SSHPort
+	return nil
+}
+
+// Convert_v1alpha2_StaticHostSpec_To_kops_StaticHostSpec is an autogenerated conversion function.
+func Convert_v1alpha2_StaticHostSpec_To_kops_StaticHostSpec(in *StaticHostSpec, out *kops.StaticHostSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_StaticHostSpec_To_kops_StaticHostSpec(in, out, s)
+}
+
+func autoConvert_kops_StaticHostSpec_To_v1alpha2_StaticHostSpec(in *kops.StaticHostSpec, out *StaticHostSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Address = in.Address
+	out.SSHUser = in.SSHUser
+	out.SSHPort = in.SSHPort
+	return nil
+}
+
+// Convert_kops_StaticHostSpec_To_v1alpha2_StaticHostSpec is an autogenerated conversion function.
+func Convert_kops_StaticHostSpec_To_v1alpha2_StaticHostSpec(in *kops.StaticHostSpec, out *StaticHostSpec, s conversion.Scope) error {
+	return autoConvert_kops_StaticHostSpec_To_v1alpha2_StaticHostSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_TargetGroupHealthCheckSpec_To_kops_TargetGroupHealthCheckSpec(in *TargetGroupHealthCheckSpec, out *kops.TargetGroupHealthCheckSpec, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Port = in.Port
+	out.Path = in.Path
+	out.IntervalSeconds = in.IntervalSeconds
+	out.HealthyThreshold = in.HealthyThreshold
+	out.UnhealthyThreshold = in.UnhealthyThreshold
+	return nil
+}
+
+// Convert_v1alpha2_TargetGroupHealthCheckSpec_To_kops_TargetGroupHealthCheckSpec is an autogenerated conversion function.
+func Convert_v1alpha2_TargetGroupHealthCheckSpec_To_kops_TargetGroupHealthCheckSpec(in *TargetGroupHealthCheckSpec, out *kops.TargetGroupHealthCheckSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_TargetGroupHealthCheckSpec_To_kops_TargetGroupHealthCheckSpec(in, out, s)
+}
+
+func autoConvert_kops_TargetGroupHealthCheckSpec_To_v1alpha2_TargetGroupHealthCheckSpec(in *kops.TargetGroupHealthCheckSpec, out *TargetGroupHealthCheckSpec, s conversion.Scope) error {
+	out.Protocol = in.Protocol
+	out.Port = in.Port
+	out.Path = in.Path
+	out.IntervalSeconds = in.IntervalSeconds
+	out.HealthyThreshold = in.HealthyThreshold
+	out.UnhealthyThreshold = in.UnhealthyThreshold
+	return nil
+}
+
+// Convert_kops_TargetGroupHealthCheckSpec_To_v1alpha2_TargetGroupHealthCheckSpec is an autogenerated conversion function.
+func Convert_kops_TargetGroupHealthCheckSpec_To_v1alpha2_TargetGroupHealthCheckSpec(in *kops.TargetGroupHealthCheckSpec, out *TargetGroupHealthCheckSpec, s conversion.Scope) error {
+	return autoConvert_kops_TargetGroupHealthCheckSpec_To_v1alpha2_TargetGroupHealthCheckSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_TargetGroupSpec_To_kops_TargetGroupSpec(in *TargetGroupSpec, out *kops.TargetGroupSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Protocol = in.Protocol
+	out.Port = in.Port
+	out.DeregistrationDelaySeconds = in.DeregistrationDelaySeconds
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(kops.TargetGroupHealthCheckSpec)
+		if err := Convert_v1alpha2_TargetGroupHealthCheckSpec_To_kops_TargetGroupHealthCheckSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HealthCheck = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_TargetGroupSpec_To_kops_TargetGroupSpec is an autogenerated conversion function.
+func Convert_v1alpha2_TargetGroupSpec_To_kops_TargetGroupSpec(in *TargetGroupSpec, out *kops.TargetGroupSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_TargetGroupSpec_To_kops_TargetGroupSpec(in, out, s)
+}
+
+func autoConvert_kops_TargetGroupSpec_To_v1alpha2_TargetGroupSpec(in *kops.TargetGroupSpec, out *TargetGroupSpec, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Protocol = in.Protocol
+	out.Port = in.Port
+	out.DeregistrationDelaySeconds = in.DeregistrationDelaySeconds
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(TargetGroupHealthCheckSpec)
+		if err := Convert_kops_TargetGroupHealthCheckSpec_To_v1alpha2_TargetGroupHealthCheckSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.HealthCheck = nil
+	}
+	return nil
+}
+
+// Convert_kops_TargetGroupSpec_To_v1alpha2_TargetGroupSpec is an autogenerated conversion function.
+func Convert_kops_TargetGroupSpec_To_v1alpha2_TargetGroupSpec(in *kops.TargetGroupSpec, out *TargetGroupSpec, s conversion.Scope) error {
+	return autoConvert_kops_TargetGroupSpec_To_v1alpha2_TargetGroupSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_TargetSpec_To_kops_TargetSpec(in *TargetSpec, out *kops.TargetSpec, s conversion.Scope) error {
 	if in.Terraform != nil {
 		in, out := &in.Terraform, &out.Terraform
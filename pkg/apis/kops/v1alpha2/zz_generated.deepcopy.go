@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha2
 
 import (
+	resource "k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
@@ -201,6 +202,11 @@ func (in *Assets) DeepCopyInto(out *Assets) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ImageDigests != nil {
+		in, out := &in.ImageDigests, &out.ImageDigests
+		*out = new(ImageDigestsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -214,6 +220,88 @@ func (in *Assets) DeepCopy() *Assets {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLogBackendSpec) DeepCopyInto(out *AuditLogBackendSpec) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBackups != nil {
+		in, out := &in.MaxBackups, &out.MaxBackups
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLogBackendSpec.
+func (in *AuditLogBackendSpec) DeepCopy() *AuditLogBackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLogBackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditWebhookBackendSpec) DeepCopyInto(out *AuditWebhookBackendSpec) {
+	*out = *in
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BatchBufferSize != nil {
+		in, out := &in.BatchBufferSize, &out.BatchBufferSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BatchMaxSize != nil {
+		in, out := &in.BatchMaxSize, &out.BatchMaxSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BatchMaxWait != nil {
+		in, out := &in.BatchMaxWait, &out.BatchMaxWait
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BatchThrottleEnable != nil {
+		in, out := &in.BatchThrottleEnable, &out.BatchThrottleEnable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BatchThrottleQPS != nil {
+		in, out := &in.BatchThrottleQPS, &out.BatchThrottleQPS
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BatchThrottleBurst != nil {
+		in, out := &in.BatchThrottleBurst, &out.BatchThrottleBurst
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditWebhookBackendSpec.
+func (in *AuditWebhookBackendSpec) DeepCopy() *AuditWebhookBackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditWebhookBackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthenticationSpec) DeepCopyInto(out *AuthenticationSpec) {
 	*out = *in
@@ -438,6 +526,37 @@ func (in *CanalNetworkingSpec) DeepCopy() *CanalNetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReservationSpec) DeepCopyInto(out *CapacityReservationSpec) {
+	*out = *in
+	if in.Preference != nil {
+		in, out := &in.Preference, &out.Preference
+		*out = new(string)
+		**out = **in
+	}
+	if in.ID != nil {
+		in, out := &in.ID, &out.ID
+		*out = new(string)
+		**out = **in
+	}
+	if in.ResourceGroupARN != nil {
+		in, out := &in.ResourceGroupARN, &out.ResourceGroupARN
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReservationSpec.
+func (in *CapacityReservationSpec) DeepCopy() *CapacityReservationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReservationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerConfig) DeepCopyInto(out *CertManagerConfig) {
 	*out = *in
@@ -738,6 +857,11 @@ func (in *Cluster) DeepCopyInto(out *Cluster) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(ReconcileStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1149,6 +1273,11 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(ClusterAutoscalerConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Karpenter != nil {
+		in, out := &in.Karpenter, &out.Karpenter
+		*out = new(KarpenterConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.WarmPool != nil {
 		in, out := &in.WarmPool, &out.WarmPool
 		*out = new(WarmPoolSpec)
@@ -1236,6 +1365,13 @@ func (in *ContainerdConfig) DeepCopyInto(out *ContainerdConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Runtimes != nil {
+		in, out := &in.Runtimes, &out.Runtimes
+		*out = make([]ContainerdRuntimeSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.State != nil {
 		in, out := &in.State, &out.State
 		*out = new(string)
@@ -1259,6 +1395,32 @@ func (in *ContainerdConfig) DeepCopy() *ContainerdConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerdRuntimeSpec) DeepCopyInto(out *ContainerdRuntimeSpec) {
+	*out = *in
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = new(PackagesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerdRuntimeSpec.
+func (in *ContainerdRuntimeSpec) DeepCopy() *ContainerdRuntimeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerdRuntimeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DNSAccessSpec) DeepCopyInto(out *DNSAccessSpec) {
 	*out = *in
@@ -2020,6 +2182,27 @@ func (in *IAMSpec) DeepCopy() *IAMSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageDigestsSpec) DeepCopyInto(out *ImageDigestsSpec) {
+	*out = *in
+	if in.SignaturePublicKey != nil {
+		in, out := &in.SignaturePublicKey, &out.SignaturePublicKey
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDigestsSpec.
+func (in *ImageDigestsSpec) DeepCopy() *ImageDigestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageDigestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceGroup) DeepCopyInto(out *InstanceGroup) {
 	*out = *in
@@ -2302,6 +2485,35 @@ func (in *InstanceGroupSpec) DeepCopyInto(out *InstanceGroupSpec) {
 		*out = new(WarmPoolSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Containerd != nil {
+		in, out := &in.Containerd, &out.Containerd
+		*out = new(ContainerdConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]StaticHostSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScheduledScaling != nil {
+		in, out := &in.ScheduledScaling, &out.ScheduledScaling
+		*out = make([]ScheduledScalingSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CapacityReservation != nil {
+		in, out := &in.CapacityReservation, &out.CapacityReservation
+		*out = new(CapacityReservationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlacementGroup != nil {
+		in, out := &in.PlacementGroup, &out.PlacementGroup
+		*out = new(PlacementGroupSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2341,6 +2553,68 @@ func (in *InstanceMetadataOptions) DeepCopy() *InstanceMetadataOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JSON) DeepCopyInto(out *JSON) {
+	*out = *in
+	if in.Raw != nil {
+		in, out := &in.Raw, &out.Raw
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JSON.
+func (in *JSON) DeepCopy() *JSON {
+	if in == nil {
+		return nil
+	}
+	out := new(JSON)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KarpenterConfig) DeepCopyInto(out *KarpenterConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.TTLSecondsAfterEmpty != nil {
+		in, out := &in.TTLSecondsAfterEmpty, &out.TTLSecondsAfterEmpty
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MemoryRequest != nil {
+		in, out := &in.MemoryRequest, &out.MemoryRequest
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CPURequest != nil {
+		in, out := &in.CPURequest, &out.CPURequest
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KarpenterConfig.
+func (in *KarpenterConfig) DeepCopy() *KarpenterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KarpenterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Keyset) DeepCopyInto(out *Keyset) {
 	*out = *in
@@ -2486,6 +2760,37 @@ func (in *KopeioNetworkingSpec) DeepCopy() *KopeioNetworkingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeAPIServerAuditingSpec) DeepCopyInto(out *KubeAPIServerAuditingSpec) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = new(AuditLogBackendSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(AuditWebhookBackendSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dynamic != nil {
+		in, out := &in.Dynamic, &out.Dynamic
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeAPIServerAuditingSpec.
+func (in *KubeAPIServerAuditingSpec) DeepCopy() *KubeAPIServerAuditingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeAPIServerAuditingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeAPIServerConfig) DeepCopyInto(out *KubeAPIServerConfig) {
 	*out = *in
@@ -2828,6 +3133,11 @@ func (in *KubeAPIServerConfig) DeepCopyInto(out *KubeAPIServerConfig) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Auditing != nil {
+		in, out := &in.Auditing, &out.Auditing
+		*out = new(KubeAPIServerAuditingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3472,6 +3782,30 @@ func (in *KubeletConfigSpec) DeepCopyInto(out *KubeletConfigSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.ShutdownGracePeriod != nil {
+		in, out := &in.ShutdownGracePeriod, &out.ShutdownGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ShutdownGracePeriodCriticalPods != nil {
+		in, out := &in.ShutdownGracePeriodCriticalPods, &out.ShutdownGracePeriodCriticalPods
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ReservedMemory != nil {
+		in, out := &in.ReservedMemory, &out.ReservedMemory
+		*out = make([]KubeletMemoryReservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigFileOverrides != nil {
+		in, out := &in.ConfigFileOverrides, &out.ConfigFileOverrides
+		*out = make(map[string]JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -3485,6 +3819,29 @@ func (in *KubeletConfigSpec) DeepCopy() *KubeletConfigSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeletMemoryReservation) DeepCopyInto(out *KubeletMemoryReservation) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(map[string]resource.Quantity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeletMemoryReservation.
+func (in *KubeletMemoryReservation) DeepCopy() *KubeletMemoryReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeletMemoryReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubenetNetworkingSpec) DeepCopyInto(out *KubenetNetworkingSpec) {
 	*out = *in
@@ -3581,6 +3938,11 @@ func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.TargetGroup != nil {
+		in, out := &in.TargetGroup, &out.TargetGroup
+		*out = new(TargetGroupSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4354,6 +4716,32 @@ func (in *PackagesConfig) DeepCopy() *PackagesConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementGroupSpec) DeepCopyInto(out *PlacementGroupSpec) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.PartitionCount != nil {
+		in, out := &in.PartitionCount, &out.PartitionCount
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementGroupSpec.
+func (in *PlacementGroupSpec) DeepCopy() *PlacementGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RBACAuthorizationSpec) DeepCopyInto(out *RBACAuthorizationSpec) {
 	*out = *in
@@ -4370,6 +4758,37 @@ func (in *RBACAuthorizationSpec) DeepCopy() *RBACAuthorizationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileStatus) DeepCopyInto(out *ReconcileStatus) {
+	*out = *in
+	if in.ObservedInstanceGroups != nil {
+		in, out := &in.ObservedInstanceGroups, &out.ObservedInstanceGroups
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileStatus.
+func (in *ReconcileStatus) DeepCopy() *ReconcileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RollingUpdate) DeepCopyInto(out *RollingUpdate) {
 	*out = *in
@@ -4388,6 +4807,11 @@ func (in *RollingUpdate) DeepCopyInto(out *RollingUpdate) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.InPlaceUpdates != nil {
+		in, out := &in.InPlaceUpdates, &out.InPlaceUpdates
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -4493,6 +4917,42 @@ func (in *SSHCredentialSpec) DeepCopy() *SSHCredentialSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledScalingSpec) DeepCopyInto(out *ScheduledScalingSpec) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DesiredCapacity != nil {
+		in, out := &in.DesiredCapacity, &out.DesiredCapacity
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledScalingSpec.
+func (in *ScheduledScalingSpec) DeepCopy() *ScheduledScalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledScalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountExternalPermission) DeepCopyInto(out *ServiceAccountExternalPermission) {
 	*out = *in
@@ -4551,6 +5011,94 @@ func (in *SnapshotControllerConfig) DeepCopy() *SnapshotControllerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticHostSpec) DeepCopyInto(out *StaticHostSpec) {
+	*out = *in
+	if in.SSHPort != nil {
+		in, out := &in.SSHPort, &out.SSHPort
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticHostSpec.
+func (in *StaticHostSpec) DeepCopy() *StaticHostSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticHostSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetGroupHealthCheckSpec) DeepCopyInto(out *TargetGroupHealthCheckSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int64)
+		**out = **in
+	}
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HealthyThreshold != nil {
+		in, out := &in.HealthyThreshold, &out.HealthyThreshold
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UnhealthyThreshold != nil {
+		in, out := &in.UnhealthyThreshold, &out.UnhealthyThreshold
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetGroupHealthCheckSpec.
+func (in *TargetGroupHealthCheckSpec) DeepCopy() *TargetGroupHealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetGroupHealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetGroupSpec) DeepCopyInto(out *TargetGroupSpec) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeregistrationDelaySeconds != nil {
+		in, out := &in.DeregistrationDelaySeconds, &out.DeregistrationDelaySeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(TargetGroupHealthCheckSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetGroupSpec.
+func (in *TargetGroupSpec) DeepCopy() *TargetGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetSpec) DeepCopyInto(out *TargetSpec) {
 	*out = *in
@@ -22,10 +22,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
@@ -43,6 +45,34 @@ func awsValidateCluster(c *kops.Cluster) field.ErrorList {
 
 	allErrs = append(allErrs, awsValidateExternalCloudControllerManager(c)...)
 
+	if c.Spec.IsIPv6Only() {
+		allErrs = append(allErrs, awsValidateIPv6(c.Spec)...)
+	}
+
+	return allErrs
+}
+
+// awsValidateIPv6 checks the requirements of IPv6 primary clusters, where pods and services use IPv6 addresses
+func awsValidateIPv6(spec kops.ClusterSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !featureflag.AWSIPv6.Enabled() {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "nonMasqueradeCIDR"), "IPv6 pods and services are experimental on AWS and require the AWSIPv6 feature flag"))
+	}
+
+	subnetsPath := field.NewPath("spec", "subnets")
+	for i, subnet := range spec.Subnets {
+		if subnet.IPv6CIDR == "" {
+			allErrs = append(allErrs, field.Required(subnetsPath.Index(i).Child("ipv6CIDR"), "all subnets need an IPv6 CIDR when the cluster uses IPv6"))
+		}
+	}
+
+	if n := spec.Networking; n != nil {
+		if n.Calico == nil && n.Cilium == nil && n.CNI == nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "networking"), "IPv6 is only supported with Calico, Cilium or a custom CNI"))
+		}
+	}
+
 	return allErrs
 }
 
@@ -310,3 +340,116 @@ func hasAWSEBSCSIDriver(c kops.ClusterSpec) bool {
 
 	return *c.CloudConfig.AWSEBSCSIDriver.Enabled
 }
+
+// awsUsesSpotInstances returns true if the instance group may launch spot instances
+func awsUsesSpotInstances(ig *kops.InstanceGroup) bool {
+	if ig.Spec.MaxPrice != nil {
+		return true
+	}
+	if mip := ig.Spec.MixedInstancesPolicy; mip != nil && mip.OnDemandAboveBase != nil && *mip.OnDemandAboveBase < 100 {
+		return true
+	}
+	return false
+}
+
+func awsValidateCapacityReservation(fieldPath *field.Path, ig *kops.InstanceGroup) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := ig.Spec.CapacityReservation
+
+	targeted := spec.ID != nil || spec.ResourceGroupARN != nil
+	if spec.Preference == nil && !targeted {
+		allErrs = append(allErrs, field.Required(fieldPath, "one of preference, id or resourceGroupARN must be set"))
+	}
+	if spec.Preference != nil {
+		allErrs = append(allErrs, IsValidValue(fieldPath.Child("preference"), spec.Preference, ec2.CapacityReservationPreference_Values())...)
+		if targeted {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("preference"), "preference cannot be set together with id or resourceGroupARN"))
+		}
+	}
+	if spec.ID != nil {
+		if !strings.HasPrefix(*spec.ID, "cr-") {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("id"), *spec.ID, "must be the ID of a capacity reservation, starting with cr-"))
+		}
+		if spec.ResourceGroupARN != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("resourceGroupARN"), "resourceGroupARN cannot be set together with id"))
+		}
+	}
+	if spec.ResourceGroupARN != nil {
+		if _, err := arn.Parse(*spec.ResourceGroupARN); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("resourceGroupARN"), *spec.ResourceGroupARN, "must be a valid ARN"))
+		}
+	}
+
+	if awsUsesSpotInstances(ig) && fi.StringValue(spec.Preference) != ec2.CapacityReservationPreferenceNone {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "capacity reservations only apply to On-Demand instances, and cannot be used with spot instances"))
+	}
+	if ig.Spec.MixedInstancesPolicy != nil && targeted {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "a capacity reservation cannot be targeted with a mixed instances policy"))
+	}
+
+	return allErrs
+}
+
+func awsValidatePlacementGroup(fieldPath *field.Path, ig *kops.InstanceGroup, cluster *kops.Cluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+	spec := ig.Spec.PlacementGroup
+
+	if spec.Name != nil {
+		if *spec.Name == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("name"), ""))
+		}
+		if spec.Strategy != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("strategy"), "strategy cannot be set for an existing placement group"))
+		}
+		if spec.PartitionCount != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("partitionCount"), "partitionCount cannot be set for an existing placement group"))
+		}
+	} else if spec.Strategy == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("strategy"), "either strategy or the name of an existing placement group must be set"))
+	} else {
+		allErrs = append(allErrs, IsValidValue(fieldPath.Child("strategy"), &spec.Strategy, ec2.PlacementStrategy_Values())...)
+	}
+
+	if spec.PartitionCount != nil {
+		if spec.Name == nil && spec.Strategy != ec2.PlacementStrategyPartition {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("partitionCount"), "partitionCount can only be set with the partition strategy"))
+		}
+		if *spec.PartitionCount < 1 || *spec.PartitionCount > 7 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("partitionCount"), *spec.PartitionCount, "must be between 1 and 7"))
+		}
+	}
+
+	if ig.Spec.Tenancy == ec2.TenancyHost {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "instances on dedicated hosts cannot be launched in a placement group"))
+	}
+
+	zones := sets.NewString()
+	for _, subnet := range cluster.Spec.Subnets {
+		for _, name := range ig.Spec.Subnets {
+			if subnet.Name == name {
+				zones.Insert(subnet.Zone)
+			}
+		}
+	}
+
+	if spec.Strategy == ec2.PlacementStrategyCluster {
+		if zones.Len() > 1 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("strategy"), fmt.Sprintf("a cluster placement group is in a single zone, but the subnets of the instance group are in the zones %s", strings.Join(zones.List(), ", "))))
+		}
+		if ig.Spec.MixedInstancesPolicy != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("strategy"), "a cluster placement group cannot be used with a mixed instances policy"))
+		}
+		if awsUsesSpotInstances(ig) {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("strategy"), "a cluster placement group cannot be used with spot instances"))
+		}
+	}
+
+	if spec.Strategy == ec2.PlacementStrategySpread && ig.Spec.MaxSize != nil {
+		// A spread placement group holds at most 7 running instances per zone
+		if max := int32(7 * zones.Len()); zones.Len() > 0 && *ig.Spec.MaxSize > max {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "maxSize"), *ig.Spec.MaxSize, fmt.Sprintf("a spread placement group holds at most 7 instances per zone, %d for the zones of the instance group", max)))
+		}
+	}
+
+	return allErrs
+}
@@ -27,7 +27,9 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/featureflag"
 )
 
 func TestAWSValidateExternalCloudConfig(t *testing.T) {
@@ -549,3 +551,209 @@ func TestLoadBalancerSubnets(t *testing.T) {
 		testErrors(t, test, errs, test.expected)
 	}
 }
+
+func TestCapacityReservationAndPlacementGroup(t *testing.T) {
+	grid := []struct {
+		Input          kops.InstanceGroupSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:             []string{"us-test-1a"},
+				CapacityReservation: &kops.CapacityReservationSpec{ID: fi.String("cr-1234567890abcdef0")},
+				PlacementGroup:      &kops.PlacementGroupSpec{Strategy: "cluster"},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:             []string{"us-test-1a", "us-test-1b"},
+				CapacityReservation: &kops.CapacityReservationSpec{ResourceGroupARN: fi.String("arn:aws:resource-groups:us-test-1:123456789012:group/hpc")},
+				PlacementGroup:      &kops.PlacementGroupSpec{Strategy: "partition", PartitionCount: fi.Int64(3)},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:        []string{"us-test-1a"},
+				PlacementGroup: &kops.PlacementGroupSpec{Name: fi.String("existing")},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				CapacityReservation: &kops.CapacityReservationSpec{},
+				PlacementGroup:      &kops.PlacementGroupSpec{},
+			},
+			ExpectedErrors: []string{
+				"Required value::spec.capacityReservation",
+				"Required value::spec.placementGroup.strategy",
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				CapacityReservation: &kops.CapacityReservationSpec{
+					Preference:       fi.String("always"),
+					ID:               fi.String("1234"),
+					ResourceGroupARN: fi.String("hpc"),
+				},
+				PlacementGroup: &kops.PlacementGroupSpec{Name: fi.String("existing"), Strategy: "cluster", PartitionCount: fi.Int64(8)},
+			},
+			ExpectedErrors: []string{
+				"Unsupported value::spec.capacityReservation.preference",
+				"Forbidden::spec.capacityReservation.preference",
+				"Invalid value::spec.capacityReservation.id",
+				"Forbidden::spec.capacityReservation.resourceGroupARN",
+				"Invalid value::spec.capacityReservation.resourceGroupARN",
+				"Forbidden::spec.placementGroup.strategy",
+				"Forbidden::spec.placementGroup.partitionCount",
+				"Invalid value::spec.placementGroup.partitionCount",
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:             []string{"us-test-1a", "us-test-1b"},
+				MaxPrice:            fi.String("0.1"),
+				CapacityReservation: &kops.CapacityReservationSpec{Preference: fi.String("open")},
+				PlacementGroup:      &kops.PlacementGroupSpec{Strategy: "cluster"},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::spec.capacityReservation",
+				"Forbidden::spec.placementGroup.strategy",
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets: []string{"us-test-1a"},
+				MixedInstancesPolicy: &kops.MixedInstancesPolicySpec{
+					Instances: []string{"c5.large", "c5a.large"},
+				},
+				CapacityReservation: &kops.CapacityReservationSpec{ID: fi.String("cr-1234567890abcdef0")},
+				PlacementGroup:      &kops.PlacementGroupSpec{Strategy: "cluster"},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::spec.capacityReservation",
+				"Forbidden::spec.placementGroup.strategy",
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				Subnets:        []string{"us-test-1a"},
+				MaxSize:        fi.Int32(8),
+				Tenancy:        "host",
+				PlacementGroup: &kops.PlacementGroupSpec{Strategy: "spread", PartitionCount: fi.Int64(2)},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::spec.placementGroup",
+				"Forbidden::spec.placementGroup.partitionCount",
+				"Invalid value::spec.maxSize",
+			},
+		},
+	}
+
+	cluster := &kops.Cluster{
+		Spec: kops.ClusterSpec{
+			CloudProvider: "aws",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Zone: "us-test-1a"},
+				{Name: "us-test-1b", Zone: "us-test-1b"},
+			},
+		},
+	}
+	for _, g := range grid {
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "test-nodes",
+			},
+			Spec: g.Input,
+		}
+		ig.Spec.Role = kops.InstanceGroupRoleNode
+
+		var errs field.ErrorList
+		if ig.Spec.CapacityReservation != nil {
+			errs = append(errs, awsValidateCapacityReservation(field.NewPath("spec", "capacityReservation"), ig)...)
+		}
+		if ig.Spec.PlacementGroup != nil {
+			errs = append(errs, awsValidatePlacementGroup(field.NewPath("spec", "placementGroup"), ig, cluster)...)
+		}
+
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+
+	ig := &kops.InstanceGroup{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test-nodes",
+		},
+		Spec: kops.InstanceGroupSpec{
+			Role:                kops.InstanceGroupRoleNode,
+			CapacityReservation: &kops.CapacityReservationSpec{Preference: fi.String("none")},
+			PlacementGroup:      &kops.PlacementGroupSpec{Strategy: "spread"},
+		},
+	}
+	cluster.Spec.CloudProvider = "gce"
+	testErrors(t, "gce", CrossValidateInstanceGroup(ig, cluster, nil), []string{
+		"Forbidden::spec.capacityReservation",
+		"Forbidden::spec.placementGroup",
+	})
+}
+
+func TestAWSValidateIPv6(t *testing.T) {
+	featureflag.ParseFlags("+AWSIPv6")
+	defer featureflag.ParseFlags("-AWSIPv6")
+
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				NonMasqueradeCIDR: "::/0",
+				Networking:        &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{}},
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "us-test-1a", IPv6CIDR: "/64#1"},
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				NonMasqueradeCIDR: "::/0",
+				Networking:        &kops.NetworkingSpec{Kubenet: &kops.KubenetNetworkingSpec{}},
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "us-test-1a", IPv6CIDR: "/64#1"},
+					{Name: "us-test-1b"},
+				},
+			},
+			ExpectedErrors: []string{
+				"Required value::spec.subnets[1].ipv6CIDR",
+				"Forbidden::spec.networking",
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				NonMasqueradeCIDR: "100.64.0.0/10",
+				Networking:        &kops.NetworkingSpec{Kubenet: &kops.KubenetNetworkingSpec{}},
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "us-test-1a"},
+				},
+			},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: g.Input,
+		}
+		errs := awsValidateCluster(cluster)
+
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func TestAWSValidateIPv6FeatureFlag(t *testing.T) {
+	spec := kops.ClusterSpec{
+		NonMasqueradeCIDR: "::/0",
+		Networking:        &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{}},
+		Subnets: []kops.ClusterSubnetSpec{
+			{Name: "us-test-1a", IPv6CIDR: "/64#1"},
+		},
+	}
+	errs := awsValidateCluster(&kops.Cluster{Spec: spec})
+
+	testErrors(t, spec, errs, []string{"Forbidden::spec.nonMasqueradeCIDR"})
+}
@@ -18,14 +18,19 @@ package validation
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"k8s.io/kops/pkg/nodeidentity/aws"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
@@ -56,6 +61,16 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud) field.ErrorLis
 		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "role"), g.Spec.Role, supported))
 	}
 
+	switch g.Spec.Manager {
+	case "", kops.InstanceManagerCloudGroup:
+	case kops.InstanceManagerProvisioner:
+		if g.Spec.Role != kops.InstanceGroupRoleNode {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "manager"), "Provisioner manager only allowed on instance groups with role Node"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "manager"), g.Spec.Manager, []string{string(kops.InstanceManagerCloudGroup), string(kops.InstanceManagerProvisioner)}))
+	}
+
 	if g.Spec.Tenancy != "" {
 		allErrs = append(allErrs, IsValidValue(field.NewPath("spec", "tenancy"), &g.Spec.Tenancy, ec2.Tenancy_Values())...)
 	}
@@ -135,10 +150,19 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud) field.ErrorLis
 		allErrs = append(allErrs, awsValidateInstanceGroup(g, cloud.(awsup.AWSCloud))...)
 	}
 
+	targetGroupPorts := make(map[int64]bool)
 	for i, lb := range g.Spec.ExternalLoadBalancers {
 		path := field.NewPath("spec", "externalLoadBalancers").Index(i)
 
 		allErrs = append(allErrs, validateExternalLoadBalancer(&lb, path)...)
+
+		// The default target group name is derived from the port
+		if lb.TargetGroup != nil && lb.TargetGroup.Name == nil {
+			if targetGroupPorts[lb.TargetGroup.Port] {
+				allErrs = append(allErrs, field.Duplicate(path.Child("targetGroup", "port"), lb.TargetGroup.Port))
+			}
+			targetGroupPorts[lb.TargetGroup.Port] = true
+		}
 	}
 
 	allErrs = append(allErrs, IsValidValue(field.NewPath("spec", "updatePolicy"), g.Spec.UpdatePolicy, []string{kops.UpdatePolicyAutomatic, kops.UpdatePolicyExternal})...)
@@ -191,6 +215,10 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, cl
 		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "role"), "Apiserver role only supported on AWS"))
 	}
 
+	if model.UseInPlaceUpdates(cluster, g) && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "rollingUpdate", "inPlaceUpdates"), "In-place updates are only supported on AWS"))
+	}
+
 	// Check that instance groups are defined in subnets that are defined in the cluster
 	{
 		clusterSubnets := make(map[string]*kops.ClusterSubnetSpec)
@@ -206,14 +234,51 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, cl
 		}
 	}
 
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderMetal {
+		allErrs = append(allErrs, validateStaticHosts(g, field.NewPath("spec"))...)
+	} else if len(g.Spec.Hosts) != 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "hosts"), "hosts are only supported on metal"))
+	}
+
 	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderAWS {
 		if g.Spec.RootVolumeType != nil {
 			allErrs = append(allErrs, IsValidValue(field.NewPath("spec", "rootVolumeType"), g.Spec.RootVolumeType, []string{"standard", "gp3", "gp2", "io1", "io2"})...)
 		}
+		allErrs = append(allErrs, validateScheduledScaling(g, field.NewPath("spec", "scheduledScaling"))...)
+		if g.Spec.CapacityReservation != nil {
+			allErrs = append(allErrs, awsValidateCapacityReservation(field.NewPath("spec", "capacityReservation"), g)...)
+		}
+		if g.Spec.PlacementGroup != nil {
+			allErrs = append(allErrs, awsValidatePlacementGroup(field.NewPath("spec", "placementGroup"), g, cluster)...)
+		}
 	} else {
 		if g.Spec.WarmPool != nil {
 			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "warmPool"), "warm pool only supported on AWS"))
 		}
+		if len(g.Spec.ScheduledScaling) != 0 {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "scheduledScaling"), "scheduled scaling only supported on AWS"))
+		}
+		if g.Spec.CapacityReservation != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "capacityReservation"), "capacity reservations only supported on AWS"))
+		}
+		if g.Spec.PlacementGroup != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "placementGroup"), "placement groups only supported on AWS"))
+		}
+	}
+
+	if g.IsProvisioned() {
+		allErrs = append(allErrs, validateProvisionedInstanceGroup(g, cluster, field.NewPath("spec"))...)
+	}
+
+	if g.Spec.Containerd != nil {
+		fldPath := field.NewPath("spec", "containerd")
+		if cluster.Spec.ContainerRuntime != "containerd" {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "containerd can only be configured when the cluster uses the containerd runtime"))
+		}
+		if !reflect.DeepEqual(*g.Spec.Containerd, kops.ContainerdConfig{Runtimes: g.Spec.Containerd.Runtimes}) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "only the runtimes of containerd can be overridden for an instance group"))
+		}
+		allErrs = append(allErrs, validateContainerdRuntimes(g.Spec.Containerd.Runtimes, fldPath.Child("runtimes"))...)
 	}
 
 	{
@@ -262,6 +327,176 @@ func ValidateMasterInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster) f
 	return allErrs
 }
 
+// validateStaticHosts checks the hosts of an instance group on metal, which are its instances
+func validateStaticHosts(g *kops.InstanceGroup, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	size := int32(len(g.Spec.Hosts))
+	if g.Spec.MinSize != nil && *g.Spec.MinSize != size {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minSize"), *g.Spec.MinSize, "minSize must be the number of hosts"))
+	}
+	if g.Spec.MaxSize != nil && *g.Spec.MaxSize != size {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxSize"), *g.Spec.MaxSize, "maxSize must be the number of hosts"))
+	}
+
+	names := make(map[string]bool)
+	addresses := make(map[string]bool)
+	for i, host := range g.Spec.Hosts {
+		path := fldPath.Child("hosts").Index(i)
+
+		if host.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), ""))
+		} else {
+			for _, msg := range utilvalidation.IsDNS1123Subdomain(host.Name) {
+				allErrs = append(allErrs, field.Invalid(path.Child("name"), host.Name, msg))
+			}
+			if names[host.Name] {
+				allErrs = append(allErrs, field.Duplicate(path.Child("name"), host.Name))
+			}
+			names[host.Name] = true
+		}
+
+		if host.Address == "" {
+			allErrs = append(allErrs, field.Required(path.Child("address"), ""))
+		} else {
+			if addresses[host.Address] {
+				allErrs = append(allErrs, field.Duplicate(path.Child("address"), host.Address))
+			}
+			addresses[host.Address] = true
+		}
+
+		if host.SSHPort != nil {
+			for _, msg := range utilvalidation.IsValidPortNum(int(*host.SSHPort)) {
+				allErrs = append(allErrs, field.Invalid(path.Child("sshPort"), *host.SSHPort, msg))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateProvisionedInstanceGroup checks an instance group whose instances are created by the karpenter provisioner
+func validateProvisionedInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("manager"), "Provisioner manager only supported on AWS"))
+	}
+	if cluster.Spec.Karpenter == nil || !fi.BoolValue(cluster.Spec.Karpenter.Enabled) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("manager"), "Provisioner manager requires spec.karpenter.enabled on the cluster"))
+	}
+
+	if g.Spec.WarmPool != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("warmPool"), "warm pool cannot be used with the Provisioner manager"))
+	}
+	if len(g.Spec.ScheduledScaling) != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("scheduledScaling"), "scheduled scaling cannot be used with the Provisioner manager"))
+	}
+	if len(g.Spec.ExternalLoadBalancers) != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("externalLoadBalancers"), "external load balancers cannot be attached to instances of the Provisioner manager"))
+	}
+	if model.UseInPlaceUpdates(cluster, g) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("rollingUpdate", "inPlaceUpdates"), "in-place updates cannot be used with the Provisioner manager"))
+	}
+	if g.Spec.IAM != nil && g.Spec.IAM.Profile != nil {
+		// karpenter is only allowed to pass the node role of the cluster
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("iam", "profile"), "custom instance profiles cannot be used with the Provisioner manager"))
+	}
+
+	return allErrs
+}
+
+// validateScheduledScaling checks the schedules of an instance group, which become scheduled actions of its autoscaling group
+func validateScheduledScaling(g *kops.InstanceGroup, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := make(map[string]bool)
+	for i, schedule := range g.Spec.ScheduledScaling {
+		path := fldPath.Index(i)
+
+		if schedule.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), ""))
+		} else {
+			for _, msg := range utilvalidation.IsDNS1123Label(schedule.Name) {
+				allErrs = append(allErrs, field.Invalid(path.Child("name"), schedule.Name, msg))
+			}
+			if names[schedule.Name] {
+				allErrs = append(allErrs, field.Duplicate(path.Child("name"), schedule.Name))
+			}
+			names[schedule.Name] = true
+		}
+
+		if schedule.Recurrence == "" {
+			allErrs = append(allErrs, field.Required(path.Child("recurrence"), ""))
+		} else if err := validateCronExpression(schedule.Recurrence); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("recurrence"), schedule.Recurrence, err.Error()))
+		}
+
+		if schedule.TimeZone != nil {
+			if _, err := time.LoadLocation(*schedule.TimeZone); err != nil || *schedule.TimeZone == "" || *schedule.TimeZone == "Local" {
+				allErrs = append(allErrs, field.Invalid(path.Child("timeZone"), *schedule.TimeZone, "must be an IANA time zone, such as Europe/Paris"))
+			}
+		}
+
+		if schedule.MinSize == nil && schedule.MaxSize == nil && schedule.DesiredCapacity == nil {
+			allErrs = append(allErrs, field.Required(path, "at least one of minSize, maxSize or desiredCapacity must be set"))
+		}
+		for _, size := range []struct {
+			name  string
+			value *int32
+		}{
+			{"minSize", schedule.MinSize},
+			{"maxSize", schedule.MaxSize},
+			{"desiredCapacity", schedule.DesiredCapacity},
+		} {
+			if size.value != nil && *size.value < 0 {
+				allErrs = append(allErrs, field.Invalid(path.Child(size.name), *size.value, "must not be negative"))
+			}
+		}
+		if schedule.MinSize != nil && schedule.MaxSize != nil && *schedule.MinSize > *schedule.MaxSize {
+			allErrs = append(allErrs, field.Invalid(path.Child("maxSize"), *schedule.MaxSize, "maxSize cannot be lower than minSize"))
+		}
+		if schedule.DesiredCapacity != nil {
+			if schedule.MinSize != nil && *schedule.DesiredCapacity < *schedule.MinSize {
+				allErrs = append(allErrs, field.Invalid(path.Child("desiredCapacity"), *schedule.DesiredCapacity, "desiredCapacity cannot be lower than minSize"))
+			}
+			if schedule.MaxSize != nil && *schedule.DesiredCapacity > *schedule.MaxSize {
+				allErrs = append(allErrs, field.Invalid(path.Child("desiredCapacity"), *schedule.DesiredCapacity, "desiredCapacity cannot be higher than maxSize"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// cronFieldNames are the names allowed in the month and day-of-week fields of a cron expression
+var cronFieldNames = map[int][]string{
+	3: {"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+	4: {"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"},
+}
+
+// validateCronExpression checks that s is a cron expression with the five fields
+// minute, hour, day of month, month and day of week, as accepted by AWS scheduled actions
+func validateCronExpression(s string) error {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return fmt.Errorf("must have the five fields minute, hour, day of month, month and day of week")
+	}
+
+	for i, f := range fields {
+		value := strings.ToUpper(f)
+		for _, name := range cronFieldNames[i] {
+			value = strings.ReplaceAll(value, name, "0")
+		}
+		for _, c := range value {
+			if !strings.ContainsRune("0123456789*,-/", c) {
+				return fmt.Errorf("field %q contains the unsupported character %q", f, c)
+			}
+		}
+	}
+	return nil
+}
+
 var validUserDataTypes = []string{
 	"text/x-include-once-url",
 	"text/x-include-url",
@@ -341,8 +576,14 @@ func validateIGCloudLabels(ig *kops.InstanceGroup, fldPath *field.Path) (allErrs
 func validateExternalLoadBalancer(lb *kops.LoadBalancer, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
-	if lb.LoadBalancerName != nil && lb.TargetGroupARN != nil {
-		allErrs = append(allErrs, field.TooMany(fldPath, 2, 1))
+	count := 0
+	for _, set := range []bool{lb.LoadBalancerName != nil, lb.TargetGroupARN != nil, lb.TargetGroup != nil} {
+		if set {
+			count++
+		}
+	}
+	if count > 1 {
+		allErrs = append(allErrs, field.TooMany(fldPath, count, 1))
 	}
 
 	if lb.LoadBalancerName != nil {
@@ -383,5 +624,80 @@ func validateExternalLoadBalancer(lb *kops.LoadBalancer, fldPath *field.Path) fi
 		}
 	}
 
+	if lb.TargetGroup != nil {
+		allErrs = append(allErrs, validateTargetGroup(lb.TargetGroup, fldPath.Child("targetGroup"))...)
+	}
+
+	return allErrs
+}
+
+// targetGroupNameRegex matches the names AWS accepts for target groups
+var targetGroupNameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+
+func validateTargetGroup(tg *kops.TargetGroupSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if tg.Name != nil {
+		name := fi.StringValue(tg.Name)
+		if len(name) > 32 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), name, "Target Group name must have at most 32 characters"))
+		}
+		if !targetGroupNameRegex.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), name, "Target Group name must consist of alphanumeric characters and hyphens, and must not begin or end with a hyphen"))
+		}
+	}
+
+	protocols := []string{"TCP", "TLS", "UDP", "TCP_UDP", "HTTP", "HTTPS"}
+	if tg.Protocol == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("protocol"), "protocol must be specified"))
+	} else {
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("protocol"), &tg.Protocol, protocols)...)
+	}
+
+	if tg.Port < 1 || tg.Port > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("port"), tg.Port, "port must be between 1 and 65535"))
+	}
+
+	if tg.DeregistrationDelaySeconds != nil {
+		delay := *tg.DeregistrationDelaySeconds
+		if delay < 0 || delay > 3600 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("deregistrationDelaySeconds"), delay, "deregistration delay must be between 0 and 3600 seconds"))
+		}
+	}
+
+	if hc := tg.HealthCheck; hc != nil {
+		hcPath := fldPath.Child("healthCheck")
+		if hc.Protocol != "" {
+			allErrs = append(allErrs, IsValidValue(hcPath.Child("protocol"), &hc.Protocol, []string{"TCP", "HTTP", "HTTPS"})...)
+			if hc.Protocol == "TCP" && (tg.Protocol == "HTTP" || tg.Protocol == "HTTPS") {
+				allErrs = append(allErrs, field.Invalid(hcPath.Child("protocol"), hc.Protocol, "TCP health checks are not supported for HTTP and HTTPS target groups"))
+			}
+		}
+		if hc.Path != "" {
+			protocol := hc.Protocol
+			if protocol == "" && (tg.Protocol == "HTTP" || tg.Protocol == "HTTPS") {
+				protocol = tg.Protocol
+			}
+			if protocol != "HTTP" && protocol != "HTTPS" {
+				allErrs = append(allErrs, field.Forbidden(hcPath.Child("path"), "path is only supported for HTTP and HTTPS health checks"))
+			}
+			if !strings.HasPrefix(hc.Path, "/") {
+				allErrs = append(allErrs, field.Invalid(hcPath.Child("path"), hc.Path, "path must begin with /"))
+			}
+		}
+		if hc.Port != nil && (*hc.Port < 1 || *hc.Port > 65535) {
+			allErrs = append(allErrs, field.Invalid(hcPath.Child("port"), *hc.Port, "port must be between 1 and 65535"))
+		}
+		if hc.IntervalSeconds != nil && (*hc.IntervalSeconds < 5 || *hc.IntervalSeconds > 300) {
+			allErrs = append(allErrs, field.Invalid(hcPath.Child("intervalSeconds"), *hc.IntervalSeconds, "interval must be between 5 and 300 seconds"))
+		}
+		if hc.HealthyThreshold != nil && (*hc.HealthyThreshold < 2 || *hc.HealthyThreshold > 10) {
+			allErrs = append(allErrs, field.Invalid(hcPath.Child("healthyThreshold"), *hc.HealthyThreshold, "threshold must be between 2 and 10"))
+		}
+		if hc.UnhealthyThreshold != nil && (*hc.UnhealthyThreshold < 2 || *hc.UnhealthyThreshold > 10) {
+			allErrs = append(allErrs, field.Invalid(hcPath.Child("unhealthyThreshold"), *hc.UnhealthyThreshold, "threshold must be between 2 and 10"))
+		}
+	}
+
 	return allErrs
 }
@@ -385,6 +385,618 @@ func TestIGUpdatePolicy(t *testing.T) {
 	}
 }
 
+func TestIGExternalTargetGroup(t *testing.T) {
+	for _, test := range []struct {
+		label         string
+		loadBalancers []kops.LoadBalancer
+		expected      []string
+	}{
+		{
+			label: "valid",
+			loadBalancers: []kops.LoadBalancer{
+				{
+					TargetGroup: &kops.TargetGroupSpec{
+						Protocol:                   "HTTP",
+						Port:                       30080,
+						DeregistrationDelaySeconds: fi.Int64(30),
+						HealthCheck: &kops.TargetGroupHealthCheckSpec{
+							Path:            "/healthz",
+							IntervalSeconds: fi.Int64(10),
+						},
+					},
+				},
+				{
+					TargetGroup: &kops.TargetGroupSpec{
+						Protocol: "TCP",
+						Port:     30443,
+					},
+				},
+			},
+		},
+		{
+			label: "with target group ARN",
+			loadBalancers: []kops.LoadBalancer{
+				{
+					TargetGroupARN: fi.String("arn:aws:elasticloadbalancing:us-east-1:000000000000:targetgroup/tg/1234567890123456"),
+					TargetGroup: &kops.TargetGroupSpec{
+						Protocol: "TCP",
+						Port:     30443,
+					},
+				},
+			},
+			expected: []string{"Too many::spec.externalLoadBalancers[0]"},
+		},
+		{
+			label: "missing protocol and port",
+			loadBalancers: []kops.LoadBalancer{
+				{
+					TargetGroup: &kops.TargetGroupSpec{},
+				},
+			},
+			expected: []string{
+				"Required value::spec.externalLoadBalancers[0].targetGroup.protocol",
+				"Invalid value::spec.externalLoadBalancers[0].targetGroup.port",
+			},
+		},
+		{
+			label: "invalid name",
+			loadBalancers: []kops.LoadBalancer{
+				{
+					TargetGroup: &kops.TargetGroupSpec{
+						Name:     fi.String("ingress_http"),
+						Protocol: "TCP",
+						Port:     30080,
+					},
+				},
+			},
+			expected: []string{"Invalid value::spec.externalLoadBalancers[0].targetGroup.name"},
+		},
+		{
+			label: "duplicate port",
+			loadBalancers: []kops.LoadBalancer{
+				{
+					TargetGroup: &kops.TargetGroupSpec{
+						Protocol: "TCP",
+						Port:     30080,
+					},
+				},
+				{
+					TargetGroup: &kops.TargetGroupSpec{
+						Protocol: "UDP",
+						Port:     30080,
+					},
+				},
+			},
+			expected: []string{"Duplicate value::spec.externalLoadBalancers[1].targetGroup.port"},
+		},
+		{
+			label: "path with TCP health check",
+			loadBalancers: []kops.LoadBalancer{
+				{
+					TargetGroup: &kops.TargetGroupSpec{
+						Protocol: "TCP",
+						Port:     30080,
+						HealthCheck: &kops.TargetGroupHealthCheckSpec{
+							Path: "/healthz",
+						},
+					},
+				},
+			},
+			expected: []string{"Forbidden::spec.externalLoadBalancers[0].targetGroup.healthCheck.path"},
+		},
+		{
+			label: "deregistration delay too long",
+			loadBalancers: []kops.LoadBalancer{
+				{
+					TargetGroup: &kops.TargetGroupSpec{
+						Protocol:                   "TCP",
+						Port:                       30080,
+						DeregistrationDelaySeconds: fi.Int64(7200),
+					},
+				},
+			},
+			expected: []string{"Invalid value::spec.externalLoadBalancers[0].targetGroup.deregistrationDelaySeconds"},
+		},
+	} {
+		ig := kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-ig",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role: "Node",
+			},
+		}
+		t.Run(test.label, func(t *testing.T) {
+			ig.Spec.ExternalLoadBalancers = test.loadBalancers
+			errs := ValidateInstanceGroup(&ig, nil)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGContainerdRuntimes(t *testing.T) {
+	gvisor := kops.ContainerdRuntimeSpec{
+		Name:    "gvisor",
+		Type:    kops.ContainerdRuntimeTypeGVisor,
+		Version: fi.String("20210720"),
+	}
+	kata := kops.ContainerdRuntimeSpec{
+		Name: "kata",
+		Type: kops.ContainerdRuntimeTypeKata,
+		Packages: &kops.PackagesConfig{
+			UrlAmd64:  fi.String("https://example.com/kata-static-2.1.1-x86_64.tar.xz"),
+			HashAmd64: fi.String("0000000000000000000000000000000000000000000000000000000000000000"),
+		},
+	}
+
+	for _, test := range []struct {
+		label            string
+		containerRuntime string
+		containerd       *kops.ContainerdConfig
+		expected         []string
+	}{
+		{
+			label:            "missing",
+			containerRuntime: "containerd",
+		},
+		{
+			label:            "runtimes",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{gvisor, kata},
+			},
+		},
+		{
+			label:            "empty runtimes",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{},
+			},
+		},
+		{
+			label:            "docker",
+			containerRuntime: "docker",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{gvisor},
+			},
+			expected: []string{"Forbidden::spec.containerd"},
+		},
+		{
+			label:            "other fields",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				LogLevel: fi.String("debug"),
+				Runtimes: []kops.ContainerdRuntimeSpec{gvisor},
+			},
+			expected: []string{"Forbidden::spec.containerd"},
+		},
+		{
+			label:            "duplicate name",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{gvisor, gvisor},
+			},
+			expected: []string{
+				"Duplicate value::spec.containerd.runtimes[1].name",
+				"Forbidden::spec.containerd.runtimes[1].type",
+			},
+		},
+		{
+			label:            "runc name",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{
+					{Name: "runc", Type: kops.ContainerdRuntimeTypeGVisor, Version: fi.String("20210720")},
+				},
+			},
+			expected: []string{"Duplicate value::spec.containerd.runtimes[0].name"},
+		},
+		{
+			label:            "invalid name",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{
+					{Name: "gVisor", Type: kops.ContainerdRuntimeTypeGVisor, Version: fi.String("20210720")},
+				},
+			},
+			expected: []string{"Invalid value::spec.containerd.runtimes[0].name"},
+		},
+		{
+			label:            "unknown type",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{
+					{Name: "other", Type: "other"},
+				},
+			},
+			expected: []string{"Unsupported value::spec.containerd.runtimes[0].type"},
+		},
+		{
+			label:            "gvisor without version",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{
+					{Name: "gvisor", Type: kops.ContainerdRuntimeTypeGVisor},
+				},
+			},
+			expected: []string{"Required value::spec.containerd.runtimes[0].version"},
+		},
+		{
+			label:            "kata without packages",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{
+					{Name: "kata", Type: kops.ContainerdRuntimeTypeKata, Version: fi.String("2.1.1")},
+				},
+			},
+			expected: []string{
+				"Forbidden::spec.containerd.runtimes[0].version",
+				"Required value::spec.containerd.runtimes[0].packages",
+			},
+		},
+		{
+			label:            "package without hash",
+			containerRuntime: "containerd",
+			containerd: &kops.ContainerdConfig{
+				Runtimes: []kops.ContainerdRuntimeSpec{
+					{
+						Name: "kata",
+						Type: kops.ContainerdRuntimeTypeKata,
+						Packages: &kops.PackagesConfig{
+							UrlArm64: fi.String("https://example.com/kata-static-2.1.1-aarch64.tar.xz"),
+						},
+					},
+				},
+			},
+			expected: []string{"Invalid value::spec.containerd.runtimes[0].packages"},
+		},
+	} {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				CloudProvider:    "aws",
+				ContainerRuntime: test.containerRuntime,
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-ig",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role:       "Node",
+				Containerd: test.containerd,
+			},
+		}
+		t.Run(test.label, func(t *testing.T) {
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGInPlaceUpdates(t *testing.T) {
+	for _, test := range []struct {
+		label         string
+		cloudProvider string
+		cluster       *kops.RollingUpdate
+		ig            *kops.RollingUpdate
+		expected      []string
+	}{
+		{
+			label:         "aws",
+			cloudProvider: "aws",
+			ig:            &kops.RollingUpdate{InPlaceUpdates: fi.Bool(true)},
+		},
+		{
+			label:         "gce",
+			cloudProvider: "gce",
+			ig:            &kops.RollingUpdate{InPlaceUpdates: fi.Bool(true)},
+			expected:      []string{"Forbidden::spec.rollingUpdate.inPlaceUpdates"},
+		},
+		{
+			label:         "gce cluster default",
+			cloudProvider: "gce",
+			cluster:       &kops.RollingUpdate{InPlaceUpdates: fi.Bool(true)},
+			expected:      []string{"Forbidden::spec.rollingUpdate.inPlaceUpdates"},
+		},
+		{
+			label:         "gce disabled on instance group",
+			cloudProvider: "gce",
+			cluster:       &kops.RollingUpdate{InPlaceUpdates: fi.Bool(true)},
+			ig:            &kops.RollingUpdate{InPlaceUpdates: fi.Bool(false)},
+		},
+	} {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				CloudProvider: test.cloudProvider,
+				RollingUpdate: test.cluster,
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-ig",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role:          "Node",
+				RollingUpdate: test.ig,
+			},
+		}
+		t.Run(test.label, func(t *testing.T) {
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGStaticHosts(t *testing.T) {
+	port := int32(2222)
+	badPort := int32(0)
+	for _, test := range []struct {
+		label         string
+		cloudProvider string
+		minSize       *int32
+		hosts         []kops.StaticHostSpec
+		expected      []string
+	}{
+		{
+			label:         "metal",
+			cloudProvider: "metal",
+			hosts: []kops.StaticHostSpec{
+				{Name: "host-a", Address: "10.0.0.1"},
+				{Name: "host-b", Address: "10.0.0.2", SSHUser: "ubuntu", SSHPort: &port},
+			},
+		},
+		{
+			label:         "hosts on aws",
+			cloudProvider: "aws",
+			hosts: []kops.StaticHostSpec{
+				{Name: "host-a", Address: "10.0.0.1"},
+			},
+			expected: []string{"Forbidden::spec.hosts"},
+		},
+		{
+			label:         "missing fields",
+			cloudProvider: "metal",
+			hosts: []kops.StaticHostSpec{
+				{},
+			},
+			expected: []string{"Required value::spec.hosts[0].name", "Required value::spec.hosts[0].address"},
+		},
+		{
+			label:         "invalid",
+			cloudProvider: "metal",
+			hosts: []kops.StaticHostSpec{
+				{Name: "Host_A", Address: "10.0.0.1", SSHPort: &badPort},
+			},
+			expected: []string{"Invalid value::spec.hosts[0].name", "Invalid value::spec.hosts[0].sshPort"},
+		},
+		{
+			label:         "duplicates",
+			cloudProvider: "metal",
+			hosts: []kops.StaticHostSpec{
+				{Name: "host-a", Address: "10.0.0.1"},
+				{Name: "host-a", Address: "10.0.0.1"},
+			},
+			expected: []string{"Duplicate value::spec.hosts[1].name", "Duplicate value::spec.hosts[1].address"},
+		},
+		{
+			label:         "size",
+			cloudProvider: "metal",
+			minSize:       fi.Int32(2),
+			hosts: []kops.StaticHostSpec{
+				{Name: "host-a", Address: "10.0.0.1"},
+			},
+			expected: []string{"Invalid value::spec.minSize"},
+		},
+	} {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				CloudProvider: test.cloudProvider,
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-ig",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role:    "Node",
+				MinSize: test.minSize,
+				Hosts:   test.hosts,
+			},
+		}
+		t.Run(test.label, func(t *testing.T) {
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGScheduledScaling(t *testing.T) {
+	for _, test := range []struct {
+		label         string
+		cloudProvider string
+		schedules     []kops.ScheduledScalingSpec
+		expected      []string
+	}{
+		{
+			label:         "valid",
+			cloudProvider: "aws",
+			schedules: []kops.ScheduledScalingSpec{
+				{Name: "business-hours", Recurrence: "0 8 * * MON-FRI", TimeZone: fi.String("Europe/Paris"), MinSize: fi.Int32(3), MaxSize: fi.Int32(10)},
+				{Name: "night", Recurrence: "0 20 * * *", MinSize: fi.Int32(0), MaxSize: fi.Int32(0), DesiredCapacity: fi.Int32(0)},
+				{Name: "quarter", Recurrence: "*/15 0-6 1,15 JAN,jul SUN", DesiredCapacity: fi.Int32(1)},
+			},
+		},
+		{
+			label:         "not aws",
+			cloudProvider: "gce",
+			schedules: []kops.ScheduledScalingSpec{
+				{Name: "night", Recurrence: "0 20 * * *", DesiredCapacity: fi.Int32(0)},
+			},
+			expected: []string{"Forbidden::spec.scheduledScaling"},
+		},
+		{
+			label:         "missing fields",
+			cloudProvider: "aws",
+			schedules: []kops.ScheduledScalingSpec{
+				{},
+			},
+			expected: []string{"Required value::spec.scheduledScaling[0].name", "Required value::spec.scheduledScaling[0].recurrence", "Required value::spec.scheduledScaling[0]"},
+		},
+		{
+			label:         "invalid",
+			cloudProvider: "aws",
+			schedules: []kops.ScheduledScalingSpec{
+				{Name: "Night", Recurrence: "0 20 * *", TimeZone: fi.String("Mars/Olympus"), DesiredCapacity: fi.Int32(-1)},
+				{Name: "night", Recurrence: "0 20 ? * *", DesiredCapacity: fi.Int32(1)},
+			},
+			expected: []string{
+				"Invalid value::spec.scheduledScaling[0].name",
+				"Invalid value::spec.scheduledScaling[0].recurrence",
+				"Invalid value::spec.scheduledScaling[0].timeZone",
+				"Invalid value::spec.scheduledScaling[0].desiredCapacity",
+				"Invalid value::spec.scheduledScaling[1].recurrence",
+			},
+		},
+		{
+			label:         "sizes",
+			cloudProvider: "aws",
+			schedules: []kops.ScheduledScalingSpec{
+				{Name: "min-above-max", Recurrence: "0 8 * * *", MinSize: fi.Int32(3), MaxSize: fi.Int32(2)},
+				{Name: "desired-below-min", Recurrence: "0 8 * * *", MinSize: fi.Int32(3), DesiredCapacity: fi.Int32(2)},
+				{Name: "desired-below-min", Recurrence: "0 8 * * *", MaxSize: fi.Int32(3), DesiredCapacity: fi.Int32(4)},
+			},
+			expected: []string{
+				"Invalid value::spec.scheduledScaling[0].maxSize",
+				"Invalid value::spec.scheduledScaling[1].desiredCapacity",
+				"Duplicate value::spec.scheduledScaling[2].name",
+				"Invalid value::spec.scheduledScaling[2].desiredCapacity",
+			},
+		},
+	} {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				CloudProvider: test.cloudProvider,
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-ig",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role:             "Node",
+				ScheduledScaling: test.schedules,
+			},
+		}
+		t.Run(test.label, func(t *testing.T) {
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestIGProvisioner(t *testing.T) {
+	for _, test := range []struct {
+		label         string
+		cloudProvider string
+		karpenter     bool
+		role          kops.InstanceGroupRole
+		manager       kops.InstanceManager
+		warmPool      *kops.WarmPoolSpec
+		iam           *kops.IAMProfileSpec
+		expected      []string
+	}{
+		{
+			label:         "cloud group",
+			cloudProvider: "gce",
+			role:          kops.InstanceGroupRoleNode,
+			manager:       kops.InstanceManagerCloudGroup,
+		},
+		{
+			label:         "provisioner",
+			cloudProvider: "aws",
+			karpenter:     true,
+			role:          kops.InstanceGroupRoleNode,
+			manager:       kops.InstanceManagerProvisioner,
+		},
+		{
+			label:         "unknown manager",
+			cloudProvider: "aws",
+			role:          kops.InstanceGroupRoleNode,
+			manager:       "Fleet",
+			expected:      []string{"Unsupported value::spec.manager"},
+		},
+		{
+			label:         "master",
+			cloudProvider: "aws",
+			karpenter:     true,
+			role:          kops.InstanceGroupRoleMaster,
+			manager:       kops.InstanceManagerProvisioner,
+			expected:      []string{"Forbidden::spec.manager"},
+		},
+		{
+			label:         "karpenter disabled",
+			cloudProvider: "aws",
+			role:          kops.InstanceGroupRoleNode,
+			manager:       kops.InstanceManagerProvisioner,
+			expected:      []string{"Forbidden::spec.manager"},
+		},
+		{
+			label:         "gce",
+			cloudProvider: "gce",
+			karpenter:     true,
+			role:          kops.InstanceGroupRoleNode,
+			manager:       kops.InstanceManagerProvisioner,
+			expected:      []string{"Forbidden::spec.manager"},
+		},
+		{
+			label:         "warm pool",
+			cloudProvider: "aws",
+			karpenter:     true,
+			role:          kops.InstanceGroupRoleNode,
+			manager:       kops.InstanceManagerProvisioner,
+			warmPool:      &kops.WarmPoolSpec{},
+			expected:      []string{"Forbidden::spec.warmPool"},
+		},
+		{
+			label:         "custom instance profile",
+			cloudProvider: "aws",
+			karpenter:     true,
+			role:          kops.InstanceGroupRoleNode,
+			manager:       kops.InstanceManagerProvisioner,
+			iam:           &kops.IAMProfileSpec{Profile: fi.String("arn:aws:iam::123456789012:instance-profile/custom")},
+			expected:      []string{"Forbidden::spec.iam.profile"},
+		},
+	} {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				CloudProvider: test.cloudProvider,
+				Karpenter: &kops.KarpenterConfig{
+					Enabled: fi.Bool(test.karpenter),
+				},
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "some-ig",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role:     test.role,
+				Manager:  test.manager,
+				WarmPool: test.warmPool,
+				IAM:      test.iam,
+			},
+		}
+		if test.role == kops.InstanceGroupRoleMaster {
+			ig.Spec.Subnets = []string{"subnet"}
+			cluster.Spec.Subnets = []kops.ClusterSubnetSpec{{Name: "subnet"}}
+		}
+		t.Run(test.label, func(t *testing.T) {
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
 func TestValidInstanceGroup(t *testing.T) {
 	grid := []struct {
 		IG             *kops.InstanceGroup
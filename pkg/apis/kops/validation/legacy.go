@@ -85,6 +85,13 @@ func ValidateCluster(c *kops.Cluster, strict bool) field.ErrorList {
 	case kops.CloudProviderOpenstack:
 		requiresNetworkCIDR = false
 		requiresSubnetCIDR = false
+	case kops.CloudProviderMetal:
+		requiresSubnets = false
+		requiresSubnetCIDR = false
+		requiresNetworkCIDR = false
+		if !c.IsGossip() {
+			allErrs = append(allErrs, field.Forbidden(fieldSpec.Child("cloudProvider"), "metal requires gossip DNS (a cluster name ending in .k8s.local)"))
+		}
 
 	default:
 		allErrs = append(allErrs, field.NotSupported(fieldSpec.Child("cloudProvider"), c.Spec.CloudProvider, []string{
@@ -94,6 +101,7 @@ func ValidateCluster(c *kops.Cluster, strict bool) field.ErrorList {
 			string(kops.CloudProviderAzure),
 			string(kops.CloudProviderAWS),
 			string(kops.CloudProviderOpenstack),
+			string(kops.CloudProviderMetal),
 		}))
 	}
 
@@ -306,6 +314,8 @@ func ValidateCluster(c *kops.Cluster, strict bool) field.ErrorList {
 			k8sCloudProvider = "alicloud"
 		case kops.CloudProviderAzure:
 			k8sCloudProvider = "azure"
+		case kops.CloudProviderMetal:
+			k8sCloudProvider = ""
 		default:
 			// We already added an error above
 			k8sCloudProvider = "ignore"
@@ -29,6 +29,7 @@ import (
 	"golang.org/x/net/ipv4"
 	"golang.org/x/net/ipv6"
 	"k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -37,8 +38,10 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/pkg/pki"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/utils"
+	"sigs.k8s.io/yaml"
 )
 
 func newValidateCluster(cluster *kops.Cluster) field.ErrorList {
@@ -212,6 +215,9 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 		if spec.Assets.ContainerProxy != nil && spec.Assets.ContainerRegistry != nil {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("assets", "containerProxy"), "containerProxy cannot be used in conjunction with containerRegistry"))
 		}
+		if spec.Assets.ImageDigests != nil {
+			allErrs = append(allErrs, validateImageDigests(spec.Assets.ImageDigests, fieldPath.Child("assets", "imageDigests"))...)
+		}
 	}
 
 	if spec.IAM == nil || spec.IAM.Legacy {
@@ -572,6 +578,134 @@ func validateKubeAPIServer(v *kops.KubeAPIServerConfig, c *kops.Cluster, fldPath
 		allErrs = append(allErrs, IsValidValue(fldPath.Child("logFormat"), &v.LogFormat, []string{"text", "json"})...)
 	}
 
+	if v.Auditing != nil {
+		allErrs = append(allErrs, validateKubeAPIServerAuditing(v, c, fldPath)...)
+	}
+
+	return allErrs
+}
+
+// auditPolicy is the subset of the audit.k8s.io Policy type needed for validating audit policies
+type auditPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Rules             []auditPolicyRule `json:"rules"`
+	OmitStages        []string          `json:"omitStages,omitempty"`
+	OmitManagedFields bool              `json:"omitManagedFields,omitempty"`
+}
+
+type auditPolicyRule struct {
+	Level             string                      `json:"level"`
+	Users             []string                    `json:"users,omitempty"`
+	UserGroups        []string                    `json:"userGroups,omitempty"`
+	Verbs             []string                    `json:"verbs,omitempty"`
+	Resources         []auditPolicyGroupResources `json:"resources,omitempty"`
+	Namespaces        []string                    `json:"namespaces,omitempty"`
+	NonResourceURLs   []string                    `json:"nonResourceURLs,omitempty"`
+	OmitStages        []string                    `json:"omitStages,omitempty"`
+	OmitManagedFields *bool                       `json:"omitManagedFields,omitempty"`
+}
+
+type auditPolicyGroupResources struct {
+	Group         string   `json:"group,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
+
+var (
+	auditPolicyAPIVersions = []string{"audit.k8s.io/v1", "audit.k8s.io/v1beta1", "audit.k8s.io/v1alpha1"}
+	auditLevels            = []string{"None", "Metadata", "Request", "RequestResponse"}
+	auditStages            = []string{"RequestReceived", "ResponseStarted", "ResponseComplete", "Panic"}
+)
+
+func validateKubeAPIServerAuditing(v *kops.KubeAPIServerConfig, c *kops.Cluster, kubeAPIServerPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	auditing := v.Auditing
+	fldPath := kubeAPIServerPath.Child("auditing")
+
+	if v.AuditPolicyFile != "" {
+		allErrs = append(allErrs, field.Forbidden(kubeAPIServerPath.Child("auditPolicyFile"), "auditPolicyFile cannot be used together with auditing"))
+	}
+	if v.AuditLogPath != nil {
+		allErrs = append(allErrs, field.Forbidden(kubeAPIServerPath.Child("auditLogPath"), "auditLogPath cannot be used together with auditing"))
+	}
+	if v.AuditWebhookConfigFile != "" {
+		allErrs = append(allErrs, field.Forbidden(kubeAPIServerPath.Child("auditWebhookConfigFile"), "auditWebhookConfigFile cannot be used together with auditing"))
+	}
+
+	if auditing.Policy == "" {
+		if auditing.Log != nil || auditing.Webhook != nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("policy"), "an audit policy is required for the log and webhook backends"))
+		}
+	} else {
+		allErrs = append(allErrs, validateAuditPolicy(auditing.Policy, fldPath.Child("policy"))...)
+	}
+
+	if log := auditing.Log; log != nil {
+		if log.Path != "" && log.Path != "-" && !strings.HasPrefix(log.Path, "/") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("log", "path"), log.Path, "path must be absolute or \"-\""))
+		}
+		if log.Format != "" {
+			allErrs = append(allErrs, IsValidValue(fldPath.Child("log", "format"), &log.Format, []string{"json", "legacy"})...)
+		}
+	}
+
+	if webhook := auditing.Webhook; webhook != nil {
+		if webhook.Server == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("webhook", "server"), ""))
+		} else if u, err := url.Parse(webhook.Server); err != nil || u.Scheme != "https" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("webhook", "server"), webhook.Server, "server must be an https URL"))
+		}
+		if webhook.Mode != "" {
+			allErrs = append(allErrs, IsValidValue(fldPath.Child("webhook", "mode"), &webhook.Mode, []string{"batch", "blocking"})...)
+		}
+	}
+
+	if fi.BoolValue(auditing.Dynamic) && c.IsKubernetesGTE("1.19") {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("dynamic"), "dynamic audit configuration was removed in Kubernetes 1.19"))
+	}
+
+	return allErrs
+}
+
+// validateAuditPolicy rejects audit policies that kube-apiserver would fail to load
+func validateAuditPolicy(policy string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	p := &auditPolicy{}
+	if err := yaml.UnmarshalStrict([]byte(policy), p); err != nil {
+		return append(allErrs, field.Invalid(fldPath, policy, fmt.Sprintf("error parsing audit policy: %v", err)))
+	}
+
+	allErrs = append(allErrs, IsValidValue(fldPath.Child("apiVersion"), &p.APIVersion, auditPolicyAPIVersions)...)
+	allErrs = append(allErrs, IsValidValue(fldPath.Child("kind"), &p.Kind, []string{"Policy"})...)
+	if len(p.Rules) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("rules"), "an audit policy without rules does not log any events"))
+	}
+
+	allErrs = append(allErrs, validateAuditStages(p.OmitStages, fldPath.Child("omitStages"))...)
+	for i, rule := range p.Rules {
+		rulePath := fldPath.Child("rules").Index(i)
+		allErrs = append(allErrs, IsValidValue(rulePath.Child("level"), &rule.Level, auditLevels)...)
+		allErrs = append(allErrs, validateAuditStages(rule.OmitStages, rulePath.Child("omitStages"))...)
+		if len(rule.NonResourceURLs) > 0 && (len(rule.Resources) > 0 || len(rule.Namespaces) > 0) {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("nonResourceURLs"), rule.NonResourceURLs, "rules cannot apply to both regular resources and non-resource URLs"))
+		}
+		for j, url := range rule.NonResourceURLs {
+			if !strings.HasPrefix(url, "/") {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("nonResourceURLs").Index(j), url, "non-resource URLs must start with \"/\""))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateAuditStages(stages []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range stages {
+		allErrs = append(allErrs, IsValidValue(fldPath.Index(i), &stages[i], auditStages)...)
+	}
 	return allErrs
 }
 
@@ -639,6 +773,28 @@ func validateKubelet(k *kops.KubeletConfigSpec, c *kops.Cluster, kubeletPath *fi
 			allErrs = append(allErrs, IsValidValue(kubeletPath.Child("logFormat"), &k.LogFormat, []string{"text", "json"})...)
 		}
 
+		if k.TopologyManagerScope != "" {
+			allErrs = append(allErrs, IsValidValue(kubeletPath.Child("topologyManagerScope"), &k.TopologyManagerScope, []string{"container", "pod"})...)
+		}
+
+		if k.MemoryManagerPolicy != "" {
+			allErrs = append(allErrs, IsValidValue(kubeletPath.Child("memoryManagerPolicy"), &k.MemoryManagerPolicy, []string{"None", "Static"})...)
+		}
+		if len(k.ReservedMemory) != 0 && k.MemoryManagerPolicy != "Static" {
+			allErrs = append(allErrs, field.Forbidden(kubeletPath.Child("reservedMemory"), "reservedMemory requires the Static memoryManagerPolicy"))
+		}
+
+		if k.ShutdownGracePeriodCriticalPods != nil {
+			if k.ShutdownGracePeriod == nil || k.ShutdownGracePeriodCriticalPods.Duration > k.ShutdownGracePeriod.Duration {
+				allErrs = append(allErrs, field.Invalid(kubeletPath.Child("shutdownGracePeriodCriticalPods"), k.ShutdownGracePeriodCriticalPods.Duration.String(), "shutdownGracePeriodCriticalPods must not be greater than shutdownGracePeriod"))
+			}
+		}
+
+		for _, key := range []string{"apiVersion", "kind"} {
+			if _, found := k.ConfigFileOverrides[key]; found {
+				allErrs = append(allErrs, field.Forbidden(kubeletPath.Child("configFileOverrides").Key(key), "the kind of the kubelet configuration file cannot be overridden"))
+			}
+		}
 	}
 	return allErrs
 }
@@ -1292,6 +1448,63 @@ func validateContainerdConfig(config *kops.ContainerdConfig, fldPath *field.Path
 		}
 	}
 
+	allErrs = append(allErrs, validateContainerdRuntimes(config.Runtimes, fldPath.Child("runtimes"))...)
+
+	return allErrs
+}
+
+func validateContainerdRuntimes(runtimes []kops.ContainerdRuntimeSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := sets.NewString("runc")
+	types := sets.NewString()
+	for i, runtime := range runtimes {
+		fieldPath := fldPath.Index(i)
+
+		if runtime.Name == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("name"), "runtime handler name must be set"))
+		} else {
+			for _, msg := range utilvalidation.IsDNS1123Label(runtime.Name) {
+				allErrs = append(allErrs, field.Invalid(fieldPath.Child("name"), runtime.Name, msg))
+			}
+			if names.Has(runtime.Name) {
+				allErrs = append(allErrs, field.Duplicate(fieldPath.Child("name"), runtime.Name))
+			}
+			names.Insert(runtime.Name)
+		}
+
+		runtimeType := string(runtime.Type)
+		allErrs = append(allErrs, IsValidValue(fieldPath.Child("type"), &runtimeType, kops.SupportedContainerdRuntimeTypes)...)
+		if types.Has(runtimeType) {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("type"), "only one runtime of each type can be configured"))
+		}
+		types.Insert(runtimeType)
+
+		if runtime.Packages != nil {
+			packages := runtime.Packages
+			if (packages.UrlAmd64 == nil) != (packages.HashAmd64 == nil) {
+				allErrs = append(allErrs, field.Invalid(fieldPath.Child("packages"), "", "both the URL and hash of the amd64 package must be set"))
+			}
+			if (packages.UrlArm64 == nil) != (packages.HashArm64 == nil) {
+				allErrs = append(allErrs, field.Invalid(fieldPath.Child("packages"), "", "both the URL and hash of the arm64 package must be set"))
+			}
+		}
+
+		switch runtime.Type {
+		case kops.ContainerdRuntimeTypeGVisor:
+			if runtime.Version == nil && runtime.Packages == nil {
+				allErrs = append(allErrs, field.Required(fieldPath.Child("version"), "either the version or the packages of the gVisor runtime must be set"))
+			}
+		case kops.ContainerdRuntimeTypeKata:
+			if runtime.Version != nil {
+				allErrs = append(allErrs, field.Forbidden(fieldPath.Child("version"), "the Kata Containers runtime is installed from its packages"))
+			}
+			if runtime.Packages == nil {
+				allErrs = append(allErrs, field.Required(fieldPath.Child("packages"), "the packages of the Kata Containers runtime must be set"))
+			}
+		}
+	}
+
 	return allErrs
 }
 
@@ -1366,6 +1579,21 @@ func validateDockerConfig(config *kops.DockerConfig, fldPath *field.Path) field.
 	return allErrs
 }
 
+func validateImageDigests(spec *kops.ImageDigestsSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.SignaturePublicKey != nil {
+		if !spec.Enabled {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("signaturePublicKey"), "signatures can only be verified when image digests are enabled"))
+		}
+		if _, err := pki.ParsePEMPublicKey(*spec.SignaturePublicKey); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("signaturePublicKey"), *spec.SignaturePublicKey, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
 func validateRollingUpdate(rollingUpdate *kops.RollingUpdate, fldpath *field.Path, onMasterInstanceGroup bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 	var err error
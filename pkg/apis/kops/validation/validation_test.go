@@ -18,7 +18,10 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -295,6 +298,69 @@ func TestValidateKubeAPIServer(t *testing.T) {
 			},
 			ExpectedErrors: []string{"Unsupported value::KubeAPIServer.logFormat"},
 		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				Auditing: &kops.KubeAPIServerAuditingSpec{
+					Policy: "apiVersion: audit.k8s.io/v1\nkind: Policy\nrules:\n- level: Metadata\n  omitStages:\n  - RequestReceived\n",
+					Log:    &kops.AuditLogBackendSpec{},
+					Webhook: &kops.AuditWebhookBackendSpec{
+						Server: "https://audit.example.com/events",
+						Mode:   "blocking",
+					},
+				},
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				Auditing: &kops.KubeAPIServerAuditingSpec{
+					Log: &kops.AuditLogBackendSpec{},
+				},
+			},
+			ExpectedErrors: []string{"Required value::KubeAPIServer.auditing.policy"},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				Auditing: &kops.KubeAPIServerAuditingSpec{
+					Policy: "apiVersion: audit.k8s.io/v1\nkind: Policy\nrule:\n- level: Metadata\n",
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::KubeAPIServer.auditing.policy"},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				Auditing: &kops.KubeAPIServerAuditingSpec{
+					Policy: "apiVersion: v1\nkind: Policy\nrules:\n- level: Everything\n  omitStages:\n  - Done\n",
+				},
+			},
+			ExpectedErrors: []string{
+				"Unsupported value::KubeAPIServer.auditing.policy.apiVersion",
+				"Unsupported value::KubeAPIServer.auditing.policy.rules[0].level",
+				"Unsupported value::KubeAPIServer.auditing.policy.rules[0].omitStages[0]",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AuditPolicyFile: "/srv/kubernetes/audit.yaml",
+				Auditing: &kops.KubeAPIServerAuditingSpec{
+					Policy: "apiVersion: audit.k8s.io/v1\nkind: Policy\nrules:\n- level: Metadata\n",
+					Webhook: &kops.AuditWebhookBackendSpec{
+						Server: "http://audit.example.com/events",
+					},
+				},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::KubeAPIServer.auditPolicyFile",
+				"Invalid value::KubeAPIServer.auditing.webhook.server",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				Auditing: &kops.KubeAPIServerAuditingSpec{
+					Dynamic: fi.Bool(true),
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::KubeAPIServer.auditing.dynamic"},
+		},
 	}
 	for _, g := range grid {
 		if g.Cluster == nil {
@@ -388,6 +454,76 @@ func Test_Validate_Networking_Flannel(t *testing.T) {
 	}
 }
 
+func Test_Validate_KubeletConfigFile(t *testing.T) {
+	grid := []struct {
+		Input          kops.KubeletConfigSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.KubeletConfigSpec{
+				ShutdownGracePeriod:             &metav1.Duration{Duration: 30 * time.Second},
+				ShutdownGracePeriodCriticalPods: &metav1.Duration{Duration: 10 * time.Second},
+				MemoryManagerPolicy:             "Static",
+				ReservedMemory: []kops.KubeletMemoryReservation{
+					{NumaNode: 0, Limits: map[string]resource.Quantity{"memory": resource.MustParse("1Gi")}},
+				},
+				TopologyManagerScope: "pod",
+				ConfigFileOverrides: map[string]kops.JSON{
+					"containerLogMaxWorkers": {Raw: []byte("2")},
+				},
+			},
+		},
+		{
+			Input: kops.KubeletConfigSpec{
+				ShutdownGracePeriodCriticalPods: &metav1.Duration{Duration: 10 * time.Second},
+			},
+			ExpectedErrors: []string{"Invalid value::kubelet.shutdownGracePeriodCriticalPods"},
+		},
+		{
+			Input: kops.KubeletConfigSpec{
+				ShutdownGracePeriod:             &metav1.Duration{Duration: 10 * time.Second},
+				ShutdownGracePeriodCriticalPods: &metav1.Duration{Duration: 30 * time.Second},
+			},
+			ExpectedErrors: []string{"Invalid value::kubelet.shutdownGracePeriodCriticalPods"},
+		},
+		{
+			Input: kops.KubeletConfigSpec{
+				MemoryManagerPolicy: "static",
+			},
+			ExpectedErrors: []string{"Unsupported value::kubelet.memoryManagerPolicy"},
+		},
+		{
+			Input: kops.KubeletConfigSpec{
+				ReservedMemory: []kops.KubeletMemoryReservation{
+					{NumaNode: 0, Limits: map[string]resource.Quantity{"memory": resource.MustParse("1Gi")}},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::kubelet.reservedMemory"},
+		},
+		{
+			Input: kops.KubeletConfigSpec{
+				TopologyManagerScope: "node",
+			},
+			ExpectedErrors: []string{"Unsupported value::kubelet.topologyManagerScope"},
+		},
+		{
+			Input: kops.KubeletConfigSpec{
+				ConfigFileOverrides: map[string]kops.JSON{
+					"kind": {Raw: []byte(`"Other"`)},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::kubelet.configFileOverrides[kind]"},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{}
+		cluster.Spec.KubernetesVersion = "1.21.0"
+
+		errs := validateKubelet(&g.Input, cluster, field.NewPath("kubelet"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 func Test_Validate_AdditionalPolicies(t *testing.T) {
 	grid := []struct {
 		Input          map[string]string
@@ -1311,3 +1447,53 @@ func TestValidateSAExternalPermissions(t *testing.T) {
 	}
 
 }
+
+func Test_Validate_ImageDigests(t *testing.T) {
+	publicKey := `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEyuiLF+pNt3iYrYnORj3p2YKsV3I1
+n9CsolsNcDeO5z8iJa67bRp0f7liSA78bDovJBP2R+DK8ZHyzs7GQxf+fQ==
+-----END PUBLIC KEY-----
+`
+
+	grid := []struct {
+		Description    string
+		Input          kops.ImageDigestsSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "Enabled",
+			Input: kops.ImageDigestsSpec{
+				Enabled: true,
+			},
+		},
+		{
+			Description: "Enabled with signature public key",
+			Input: kops.ImageDigestsSpec{
+				Enabled:            true,
+				SignaturePublicKey: fi.String(publicKey),
+			},
+		},
+		{
+			Description: "Signature public key when disabled",
+			Input: kops.ImageDigestsSpec{
+				SignaturePublicKey: fi.String(publicKey),
+			},
+			ExpectedErrors: []string{"Forbidden::assets.imageDigests.signaturePublicKey"},
+		},
+		{
+			Description: "Malformed signature public key",
+			Input: kops.ImageDigestsSpec{
+				Enabled:            true,
+				SignaturePublicKey: fi.String("not a key"),
+			},
+			ExpectedErrors: []string{"Invalid value::assets.imageDigests.signaturePublicKey"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateImageDigests(&g.Input, field.NewPath("assets", "imageDigests"))
+			testErrors(t, g.Input, errs, g.ExpectedErrors)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/model"
 	"k8s.io/kops/pkg/nodelabels"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/architectures"
@@ -39,8 +40,12 @@ type Config struct {
 	Channels []string `json:"channels,omitempty"`
 	// ApiserverAdditionalIPs are additional IP address to put in the apiserver server cert.
 	ApiserverAdditionalIPs []string `json:",omitempty"`
+	// GossipSeeds are the addresses of the control plane hosts, used to join gossip on clouds without instance discovery.
+	GossipSeeds []string `json:",omitempty"`
 	// WarmPoolImages are the container images to pre-pull during instance pre-initialization
 	WarmPoolImages []string `json:"warmPoolImages,omitempty"`
+	// ImageDigests maps container images to references pinned to their digests.
+	ImageDigests map[string]string `json:"imageDigests,omitempty"`
 
 	// Manifests for running etcd
 	EtcdManifests []string `json:"etcdManifests,omitempty"`
@@ -76,8 +81,18 @@ type Config struct {
 
 	// APIServerConfig is additional configuration for nodes running an APIServer.
 	APIServerConfig *APIServerConfig `json:",omitempty"`
+
+	// InPlaceUpdates runs the nodeup agent, which applies the nodeup config updates requested by rolling updates.
+	InPlaceUpdates bool `json:"inPlaceUpdates,omitempty"`
 }
 
+const (
+	// AnnotationRequestedConfigHash is set on the node by rolling updates, requesting the nodeup agent to apply the nodeup config with this hash in place.
+	AnnotationRequestedConfigHash = "kops.k8s.io/requested-nodeup-config-hash"
+	// AnnotationAppliedConfigHash is set on the node by the nodeup agent once the nodeup config with this hash was applied in place.
+	AnnotationAppliedConfigHash = "kops.k8s.io/nodeup-config-hash"
+)
+
 // BootConfig is the configuration for the nodeup binary that might be too big to fit in userdata.
 type BootConfig struct {
 	// CloudProvider is the cloud provider in use.
@@ -92,6 +107,9 @@ type BootConfig struct {
 	InstanceGroupRole kops.InstanceGroupRole
 	// NodeupConfigHash holds a secure hash of the nodeup.Config.
 	NodeupConfigHash string
+	// NodeupConfigReplacementHash holds a secure hash of the parts of the nodeup.Config that can't be applied
+	// to a running instance. It is only set when in-place updates are enabled.
+	NodeupConfigReplacementHash string `json:",omitempty"`
 }
 
 type ConfigServerOptions struct {
@@ -200,6 +218,8 @@ func NewConfig(cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) (*Confi
 		config.UpdatePolicy = kops.UpdatePolicyAutomatic
 	}
 
+	config.InPlaceUpdates = model.UseInPlaceUpdates(cluster, instanceGroup)
+
 	if cluster.Spec.Networking != nil && cluster.Spec.Networking.AmazonVPC != nil {
 		config.DefaultMachineType = fi.String(strings.Split(instanceGroup.Spec.MachineType, ",")[0])
 	}
@@ -49,6 +49,9 @@ type AssetBuilder struct {
 
 	// StaticManifests records static manifests
 	StaticManifests []*StaticManifest
+
+	// imageDigests caches the references images are pinned to, when AssetsLocation.ImageDigests is enabled.
+	imageDigests map[string]string
 }
 
 type StaticManifest struct {
@@ -119,6 +122,19 @@ func (a *AssetBuilder) RemapManifest(data []byte) ([]byte, error) {
 
 // RemapImage normalizes a containers location if a user sets the AssetsLocation ContainerRegistry location.
 func (a *AssetBuilder) RemapImage(image string) (string, error) {
+	asset, image := a.remapImageLocation(image)
+
+	a.ImageAssets = append(a.ImageAssets, asset)
+
+	if a.pinImageDigests() {
+		return a.pinImage(image)
+	}
+	return image, nil
+}
+
+// remapImageLocation returns the asset for the image and the location the image should be run from,
+// without recording the asset.
+func (a *AssetBuilder) remapImageLocation(image string) (*ImageAsset, string) {
 	asset := &ImageAsset{
 		DownloadLocation:  image,
 		CanonicalLocation: image,
@@ -195,8 +211,7 @@ func (a *AssetBuilder) RemapImage(image string) (string, error) {
 		image = asset.DownloadLocation
 	}
 
-	a.ImageAssets = append(a.ImageAssets, asset)
-	return image, nil
+	return asset, image
 }
 
 // RemapFileAndSHA returns a remapped URL for the file, if AssetsLocation is defined.
@@ -298,7 +313,7 @@ func (a *AssetBuilder) findHash(file *FileAsset) (*hashing.Hash, error) {
 			Steps:    backoffSteps,
 		}
 
-		for _, ext := range []string{".sha256", ".sha1"} {
+		for _, ext := range []string{".sha256", ".sha512", ".sha1"} {
 			for _, mirror := range mirrors.FindUrlMirrors(u.String()) {
 				hashURL := mirror + ext
 				klog.V(3).Infof("Trying to read hash fie: %q", hashURL)
@@ -0,0 +1,554 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/hashing"
+	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/yaml"
+)
+
+// A bundle is a tar archive holding:
+// * manifest.yaml, the BundleManifest describing the contents of the bundle
+// * images/, an OCI image layout holding all of the images
+// * files/<sha>, the contents of each of the files
+const (
+	bundleManifestPath = "manifest.yaml"
+	bundleImagesDir    = "images"
+	bundleFilesDir     = "files"
+
+	// ociRefNameAnnotation is the OCI image layout annotation naming an image.
+	ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+)
+
+// BundleManifest describes the assets held in an offline asset bundle.
+type BundleManifest struct {
+	// Images are the image assets in the bundle.
+	Images []*BundleImage `json:"images,omitempty"`
+	// Files are the file assets in the bundle.
+	Files []*BundleFile `json:"files,omitempty"`
+}
+
+// BundleImage is an image held in the OCI image layout of a bundle.
+type BundleImage struct {
+	// Canonical is the source location of the image.
+	Canonical string `json:"canonical"`
+	// Digest is the digest of the image, or image index, in the image layout.
+	Digest string `json:"digest"`
+	// SignatureDigest is the digest of the image's cosign signatures in the image layout, if it has any.
+	SignatureDigest string `json:"signatureDigest,omitempty"`
+}
+
+// BundleFile is a file held in a bundle.
+type BundleFile struct {
+	// Canonical is the source location of the file.
+	Canonical string `json:"canonical"`
+	// SHA is the hash of the file.
+	SHA string `json:"sha"`
+	// Path is the location of the file within the bundle.
+	Path string `json:"path"`
+}
+
+// ExportBundle downloads the image and file assets from their canonical locations
+// and writes them to out as a tar archive, to be imported with ImportBundle.
+func ExportBundle(imageAssets []*ImageAsset, fileAssets []*FileAsset, out io.Writer) error {
+	dir, err := ioutil.TempDir("", "kops-assets")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			klog.Warningf("error removing temporary directory %q: %v", dir, err)
+		}
+	}()
+
+	manifest := &BundleManifest{}
+
+	imageLayout, err := layout.Write(filepath.Join(dir, bundleImagesDir), empty.Index)
+	if err != nil {
+		return fmt.Errorf("error creating image layout: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, imageAsset := range imageAssets {
+		canonical := imageAsset.CanonicalLocation
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+
+		image, err := exportImage(imageLayout, canonical)
+		if err != nil {
+			return fmt.Errorf("error exporting image %q: %v", canonical, err)
+		}
+		manifest.Images = append(manifest.Images, image)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, bundleFilesDir), 0755); err != nil {
+		return fmt.Errorf("error creating files directory: %v", err)
+	}
+
+	seen = map[string]bool{}
+	for _, fileAsset := range fileAssets {
+		canonical := fileAsset.CanonicalURL.String()
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+
+		file, err := exportFile(dir, canonical, fileAsset.SHAValue)
+		if err != nil {
+			return fmt.Errorf("error exporting file %q: %v", canonical, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	b, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error marshaling bundle manifest: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, bundleManifestPath), b, 0644); err != nil {
+		return fmt.Errorf("error writing bundle manifest: %v", err)
+	}
+
+	return writeTar(dir, out)
+}
+
+// exportImage adds an image, or image index, and its signatures to the image layout.
+func exportImage(imageLayout layout.Path, image string) (*BundleImage, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %v", image, err)
+	}
+
+	options := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+
+	desc, err := remote.Get(ref, options...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %q: %v", image, err)
+	}
+
+	klog.Infof("exporting image %v", ref)
+
+	if err := appendToLayout(imageLayout, desc, image); err != nil {
+		return nil, err
+	}
+
+	bundleImage := &BundleImage{
+		Canonical: image,
+		Digest:    desc.Digest.String(),
+	}
+
+	sigRef := signatureTag(ref.Context(), desc.Digest)
+	sigDesc, err := remote.Get(sigRef, options...)
+	if err != nil {
+		klog.V(2).Infof("no signatures found for %v: %v", ref, err)
+	} else {
+		if err := appendToLayout(imageLayout, sigDesc, sigRef.String()); err != nil {
+			return nil, err
+		}
+		bundleImage.SignatureDigest = sigDesc.Digest.String()
+	}
+
+	return bundleImage, nil
+}
+
+// appendToLayout adds an image, or image index, to the image layout.
+func appendToLayout(imageLayout layout.Path, desc *remote.Descriptor, refName string) error {
+	annotations := layout.WithAnnotations(map[string]string{ociRefNameAnnotation: refName})
+	switch desc.MediaType {
+	case types.OCIImageIndex, types.DockerManifestList:
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		return imageLayout.AppendIndex(idx, annotations)
+	default:
+		// Assume anything else is an image, since some registries don't set mediaTypes properly.
+		img, err := desc.Image()
+		if err != nil {
+			return err
+		}
+		return imageLayout.AppendImage(img, annotations)
+	}
+}
+
+// exportFile downloads a file, validates it matches the SHA, and writes it to the bundle directory.
+func exportFile(dir string, source string, sha string) (*BundleFile, error) {
+	shaHash, err := hashing.FromString(strings.TrimSpace(sha))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse sha: %q, %v", sha, err)
+	}
+
+	klog.Infof("exporting file %q", source)
+
+	data, err := vfs.Context.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading file %q: %v", source, err)
+	}
+
+	dataHash, err := shaHash.Algorithm.Hash(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unable to hash file %q downloaded: %v", source, err)
+	}
+	if !shaHash.Equal(dataHash) {
+		return nil, fmt.Errorf("the sha value %q does not match %q calculated value %q", shaHash.Hex(), source, dataHash.Hex())
+	}
+
+	p := path.Join(bundleFilesDir, shaHash.Hex())
+	if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(p)), data, 0644); err != nil {
+		return nil, fmt.Errorf("error writing file: %v", err)
+	}
+
+	return &BundleFile{
+		Canonical: source,
+		SHA:       shaHash.Hex(),
+		Path:      p,
+	}, nil
+}
+
+// ImportBundle pushes the assets in a bundle written by ExportBundle to the
+// repositories configured in the cluster's assets spec.
+// Assets already present in the repositories are skipped, so an interrupted import can be resumed.
+func ImportBundle(bundleFile string, cluster *kops.Cluster) error {
+	dir, err := ioutil.TempDir("", "kops-assets")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			klog.Warningf("error removing temporary directory %q: %v", dir, err)
+		}
+	}()
+
+	f, err := os.Open(bundleFile)
+	if err != nil {
+		return fmt.Errorf("error opening bundle: %v", err)
+	}
+	defer f.Close()
+
+	if err := extractTar(f, dir); err != nil {
+		return fmt.Errorf("error reading bundle %q: %v", bundleFile, err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, bundleManifestPath))
+	if err != nil {
+		return fmt.Errorf("error reading bundle manifest: %v", err)
+	}
+	manifest := &BundleManifest{}
+	if err := yaml.Unmarshal(b, manifest); err != nil {
+		return fmt.Errorf("error parsing bundle manifest: %v", err)
+	}
+
+	// Files must be inside the bundle, so a crafted manifest cannot import arbitrary local files
+	sourceFiles := make(map[*BundleFile]string)
+	for _, file := range manifest.Files {
+		p, err := bundlePath(dir, file.Path)
+		if err != nil {
+			return fmt.Errorf("error reading bundle manifest: %v", err)
+		}
+		sourceFiles[file] = p
+	}
+
+	imageLayout, err := layout.FromPath(filepath.Join(dir, bundleImagesDir))
+	if err != nil {
+		return fmt.Errorf("error reading image layout: %v", err)
+	}
+
+	assetBuilder := NewAssetBuilder(cluster, true)
+	tasks := map[string]assetTask{}
+
+	for _, image := range manifest.Images {
+		target, err := assetBuilder.RemapImage(image.Canonical)
+		if err != nil {
+			return err
+		}
+		if target == image.Canonical {
+			return fmt.Errorf("assets.containerRegistry or assets.containerProxy must be set to import image %q", image.Canonical)
+		}
+
+		tasks[target] = &ImportImage{
+			Name:            target,
+			Layout:          imageLayout,
+			Digest:          image.Digest,
+			SignatureDigest: image.SignatureDigest,
+			TargetImage:     target,
+		}
+	}
+
+	for _, file := range manifest.Files {
+		canonicalURL, err := url.Parse(file.Canonical)
+		if err != nil {
+			return fmt.Errorf("unable to parse file url %q: %v", file.Canonical, err)
+		}
+		target, err := assetBuilder.remapURL(canonicalURL)
+		if err != nil {
+			return err
+		}
+
+		tasks[file.Canonical] = &ImportFile{
+			Name:       file.Canonical,
+			SourceFile: sourceFiles[file],
+			TargetFile: target.String(),
+			SHA:        file.SHA,
+			Cluster:    cluster,
+		}
+	}
+
+	if !runTasks(tasks) {
+		return fmt.Errorf("not all assets imported successfully, run the import again to resume")
+	}
+	return nil
+}
+
+// ImportImage pushes an image from the image layout of a bundle to a target registry.
+type ImportImage struct {
+	Name            string
+	Layout          layout.Path
+	Digest          string
+	SignatureDigest string
+	TargetImage     string
+}
+
+func (e *ImportImage) Run() error {
+	targetRef, err := name.ParseReference(e.TargetImage)
+	if err != nil {
+		return fmt.Errorf("parsing reference for %q: %v", e.TargetImage, err)
+	}
+
+	digest, err := v1.NewHash(e.Digest)
+	if err != nil {
+		return fmt.Errorf("parsing digest %q: %v", e.Digest, err)
+	}
+
+	options := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+
+	if e.SignatureDigest != "" {
+		if err := writeFromLayout(e.Layout, e.SignatureDigest, signatureTag(targetRef.Context(), digest), options...); err != nil {
+			return fmt.Errorf("importing signatures: %v", err)
+		}
+	}
+
+	return writeFromLayout(e.Layout, e.Digest, targetRef, options...)
+}
+
+// writeFromLayout pushes the image, or image index, with the digest in the image layout to the target,
+// unless the target already has that digest.
+func writeFromLayout(imageLayout layout.Path, digestString string, targetRef name.Reference, options ...remote.Option) error {
+	digest, err := v1.NewHash(digestString)
+	if err != nil {
+		return fmt.Errorf("parsing digest %q: %v", digestString, err)
+	}
+
+	targetDesc, err := remote.Get(targetRef, options...)
+	if err == nil && targetDesc.Digest == digest {
+		klog.Infof("no need to import image %v", targetRef)
+		return nil
+	}
+
+	index, err := imageLayout.ImageIndex()
+	if err != nil {
+		return fmt.Errorf("reading image layout: %v", err)
+	}
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return fmt.Errorf("reading image layout: %v", err)
+	}
+
+	for _, desc := range indexManifest.Manifests {
+		if desc.Digest != digest {
+			continue
+		}
+
+		klog.Infof("importing image %v to %v", digest, targetRef)
+
+		switch desc.MediaType {
+		case types.OCIImageIndex, types.DockerManifestList:
+			idx, err := index.ImageIndex(digest)
+			if err != nil {
+				return err
+			}
+			return remote.WriteIndex(targetRef, idx, options...)
+		default:
+			img, err := index.Image(digest)
+			if err != nil {
+				return err
+			}
+			return remote.Write(targetRef, img, options...)
+		}
+	}
+
+	return fmt.Errorf("image %v not found in bundle", digest)
+}
+
+// ImportFile uploads a file from a bundle to a target repository.
+type ImportFile struct {
+	Name       string
+	SourceFile string
+	TargetFile string
+	SHA        string
+	Cluster    *kops.Cluster
+}
+
+func (e *ImportFile) Run() error {
+	expectedSHA := strings.TrimSpace(e.SHA)
+
+	found, err := targetMatchesSHA(e.TargetFile, expectedSHA)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(e.SourceFile)
+	if err != nil {
+		return fmt.Errorf("error reading file from bundle: %v", err)
+	}
+
+	klog.V(2).Infof("importing bits from %q to %q", e.Name, e.TargetFile)
+
+	if err := uploadFile(e.Cluster, e.Name, e.TargetFile, expectedSHA, data); err != nil {
+		return fmt.Errorf("unable to import %q to %q: %v", e.Name, e.TargetFile, err)
+	}
+
+	return nil
+}
+
+// writeTar writes the contents of dir to out as a tar archive.
+func writeTar(dir string, out io.Writer) error {
+	tw := tar.NewWriter(out)
+
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error writing bundle: %v", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error writing bundle: %v", err)
+	}
+	return nil
+}
+
+// bundlePath returns the local path of the slash-separated path p in the bundle extracted to dir.
+// Absolute paths and paths escaping dir are rejected.
+func bundlePath(dir string, p string) (string, error) {
+	rel := path.Clean(p)
+	if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("invalid path %q in bundle", p)
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+// extractTar extracts the tar archive from in to dir.
+func extractTar(in io.Reader, dir string) error {
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		p, err := bundlePath(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(p, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry %q in archive", hdr.Name)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	sourceImage := host + "/source/pause:3.2"
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatalf("error building image: %v", err)
+	}
+	if err := remote.Write(mustParseReference(t, sourceImage), img); err != nil {
+		t.Fatalf("error pushing image: %v", err)
+	}
+	imgDigest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("error getting image digest: %v", err)
+	}
+
+	data := []byte("kubelet binary")
+	sum := sha256.Sum256(data)
+	sha := hex.EncodeToString(sum[:])
+	sourceFile := mustWriteMemFS(t, "memfs://source/bin/kubelet", data)
+
+	imageAssets := []*ImageAsset{
+		{CanonicalLocation: sourceImage, DownloadLocation: sourceImage},
+		{CanonicalLocation: sourceImage, DownloadLocation: sourceImage},
+	}
+	fileAssets := []*FileAsset{
+		{CanonicalURL: sourceFile, DownloadURL: sourceFile, SHAValue: sha},
+	}
+
+	bundle := filepath.Join(t.TempDir(), "bundle.tar")
+	var buf bytes.Buffer
+	if err := ExportBundle(imageAssets, fileAssets, &buf); err != nil {
+		t.Fatalf("error exporting bundle: %v", err)
+	}
+	if err := ioutil.WriteFile(bundle, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("error writing bundle: %v", err)
+	}
+
+	proxy := host + "/mirror"
+	fileRepository := "memfs://mirror"
+	cluster := &kops.Cluster{
+		Spec: kops.ClusterSpec{
+			KubernetesVersion: "1.21.0",
+			Assets: &kops.Assets{
+				ContainerProxy: &proxy,
+				FileRepository: &fileRepository,
+			},
+		},
+	}
+
+	// Importing a second time should find everything already present.
+	for i := 0; i < 2; i++ {
+		if err := ImportBundle(bundle, cluster); err != nil {
+			t.Fatalf("error importing bundle: %v", err)
+		}
+	}
+
+	desc, err := remote.Get(mustParseReference(t, host+"/mirror/source/pause:3.2"))
+	if err != nil {
+		t.Fatalf("error fetching imported image: %v", err)
+	}
+	if desc.Digest != imgDigest {
+		t.Errorf("unexpected digest for imported image: got %v, want %v", desc.Digest, imgDigest)
+	}
+
+	actual, err := vfs.Context.ReadFile("memfs://mirror/bin/kubelet")
+	if err != nil {
+		t.Fatalf("error reading imported file: %v", err)
+	}
+	if !bytes.Equal(actual, data) {
+		t.Errorf("unexpected contents for imported file: %q", actual)
+	}
+	actualSHA, err := vfs.Context.ReadFile("memfs://mirror/bin/kubelet.sha256")
+	if err != nil {
+		t.Fatalf("error reading imported hash file: %v", err)
+	}
+	if string(actualSHA) != sha {
+		t.Errorf("unexpected contents for imported hash file: %q", actualSHA)
+	}
+}
+
+func TestExportBundleHashMismatch(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	sourceFile := mustWriteMemFS(t, "memfs://source/bin/kubelet", []byte("kubelet binary"))
+	fileAssets := []*FileAsset{
+		{CanonicalURL: sourceFile, DownloadURL: sourceFile, SHAValue: strings.Repeat("0", 64)},
+	}
+
+	err := ExportBundle(nil, fileAssets, ioutil.Discard)
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected hash mismatch error, got %v", err)
+	}
+}
+
+func TestExtractTarRejectsEscapingPaths(t *testing.T) {
+	for _, entry := range []string{"../escape", "/escape", "files/../../escape"} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		if err := tw.WriteHeader(&tar.Header{Name: entry, Typeflag: tar.TypeReg, Mode: 0644, Size: 2}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte("ok")); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		err := extractTar(&buf, t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), "invalid path") {
+			t.Errorf("expected invalid path error extracting %q, got %v", entry, err)
+		}
+	}
+}
+
+func TestImportBundleRejectsEscapingFiles(t *testing.T) {
+	for _, file := range []string{"../escape", "/etc/passwd", "files/../../escape"} {
+		manifest := fmt.Sprintf("files:\n- canonical: https://example.com/kubelet\n  path: %q\n  sha: %s\n", file, strings.Repeat("0", 64))
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		if err := tw.WriteHeader(&tar.Header{Name: bundleManifestPath, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(manifest))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(manifest)); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		bundle := filepath.Join(t.TempDir(), "bundle.tar")
+		if err := ioutil.WriteFile(bundle, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("error writing bundle: %v", err)
+		}
+
+		err := ImportBundle(bundle, &kops.Cluster{})
+		if err == nil || !strings.Contains(err.Error(), "invalid path") {
+			t.Errorf("expected invalid path error importing file %q, got %v", file, err)
+		}
+	}
+}
+
+func mustParseReference(t *testing.T, s string) name.Reference {
+	ref, err := name.ParseReference(s)
+	if err != nil {
+		t.Fatalf("error parsing reference %q: %v", s, err)
+	}
+	return ref
+}
+
+func mustWriteMemFS(t *testing.T, location string, data []byte) *url.URL {
+	p, err := vfs.Context.BuildVfsPath(location)
+	if err != nil {
+		t.Fatalf("error building path %q: %v", location, err)
+	}
+	if err := p.WriteFile(bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("error writing %q: %v", location, err)
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		t.Fatalf("error parsing %q: %v", location, err)
+	}
+	return u
+}
@@ -76,6 +76,15 @@ func Copy(imageAssets []*ImageAsset, fileAssets []*FileAsset, cluster *kops.Clus
 		}
 	}
 
+	if !runTasks(tasks) {
+		return fmt.Errorf("not all assets copied successfully")
+	}
+	return nil
+}
+
+// runTasks runs the tasks, a few at a time, in order of their names.
+// It returns false if any of the tasks failed.
+func runTasks(tasks map[string]assetTask) bool {
 	ch := make(chan error, 5)
 	for i := 0; i < cap(ch); i++ {
 		ch <- nil
@@ -112,8 +121,5 @@ func Copy(imageAssets []*ImageAsset, fileAssets []*FileAsset, cluster *kops.Clus
 	}
 
 	close(ch)
-	if gotError {
-		return fmt.Errorf("not all assets copied successfully")
-	}
-	return nil
+	return !gotError
 }
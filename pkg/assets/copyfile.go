@@ -56,12 +56,36 @@ func fileExtensionForSHA(sha string) (string, error) {
 func (e *CopyFile) Run() error {
 	expectedSHA := strings.TrimSpace(e.SHA)
 
-	shaExtension, err := fileExtensionForSHA(expectedSHA)
+	found, err := targetMatchesSHA(e.TargetFile, expectedSHA)
 	if err != nil {
 		return err
 	}
+	if found {
+		return nil
+	}
 
-	targetSHAFile := e.TargetFile + shaExtension
+	source := e.SourceFile
+	target := e.TargetFile
+	sourceSha := e.SHA
+
+	klog.V(2).Infof("copying bits from %q to %q", source, target)
+
+	if err := transferFile(e.Cluster, source, target, sourceSha); err != nil {
+		return fmt.Errorf("unable to transfer %q to %q: %v", source, target, err)
+	}
+
+	return nil
+}
+
+// targetMatchesSHA returns true if the target repository already holds a hash file
+// matching the expected SHA, meaning the file does not need to be copied again.
+func targetMatchesSHA(target string, expectedSHA string) (bool, error) {
+	shaExtension, err := fileExtensionForSHA(expectedSHA)
+	if err != nil {
+		return false, err
+	}
+
+	targetSHAFile := target + shaExtension
 
 	targetSHABytes, err := vfs.Context.ReadFile(targetSHAFile)
 	if err != nil {
@@ -71,28 +95,18 @@ func (e *CopyFile) Run() error {
 		} else {
 			klog.V(4).Infof("unable to download: %q, %v", targetSHAFile, err)
 		}
-	} else {
-		targetSHA := string(targetSHABytes)
-
-		if strings.TrimSpace(targetSHA) == expectedSHA {
-			klog.V(8).Infof("found matching target sha for file: %q", e.TargetFile)
-			return nil
-		}
-
-		klog.V(8).Infof("did not find same file, found mismatching target sha1 for file: %q", e.TargetFile)
+		return false, nil
 	}
 
-	source := e.SourceFile
-	target := e.TargetFile
-	sourceSha := e.SHA
-
-	klog.V(2).Infof("copying bits from %q to %q", source, target)
+	targetSHA := string(targetSHABytes)
 
-	if err := transferFile(e.Cluster, source, target, sourceSha); err != nil {
-		return fmt.Errorf("unable to transfer %q to %q: %v", source, target, err)
+	if strings.TrimSpace(targetSHA) == expectedSHA {
+		klog.V(8).Infof("found matching target sha for file: %q", target)
+		return true, nil
 	}
 
-	return nil
+	klog.V(8).Infof("did not find same file, found mismatching target sha1 for file: %q", target)
+	return false, nil
 }
 
 // transferFile downloads a file from the source location, validates the file matches the SHA,
@@ -111,6 +125,12 @@ func transferFile(cluster *kops.Cluster, source string, target string, sha strin
 		return fmt.Errorf("error downloading file %q: %v", source, err)
 	}
 
+	return uploadFile(cluster, source, target, sha, data)
+}
+
+// uploadFile validates that data matches the SHA, and uploads it along with
+// its hash file to the target location.
+func uploadFile(cluster *kops.Cluster, source string, target string, sha string, data []byte) error {
 	objectStore, err := buildVFSPath(target)
 	if err != nil {
 		return err
@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"k8s.io/klog/v2"
@@ -55,6 +56,10 @@ func (e *CopyImage) Run() error {
 		return fmt.Errorf("fetching %q: %v", source, err)
 	}
 
+	if err := copySignatures(desc.Digest, sourceRef, targetRef, options...); err != nil {
+		return fmt.Errorf("failed to copy signatures: %v", err)
+	}
+
 	targetDesc, err := remote.Get(targetRef, options...)
 	if err == nil && desc.Digest.String() == targetDesc.Digest.String() {
 		klog.Infof("no need to copy image from %v to %v", sourceRef, targetRef)
@@ -96,3 +101,22 @@ func copyIndex(desc *remote.Descriptor, sourceRef name.Reference, targetRef name
 	}
 	return remote.WriteIndex(targetRef, idx, options...)
 }
+
+// copySignatures copies the cosign signatures for the image digest, if there are any.
+func copySignatures(digest v1.Hash, sourceRef name.Reference, targetRef name.Reference, options ...remote.Option) error {
+	sourceSigRef := signatureTag(sourceRef.Context(), digest)
+	targetSigRef := signatureTag(targetRef.Context(), digest)
+
+	desc, err := remote.Get(sourceSigRef, options...)
+	if err != nil {
+		klog.V(2).Infof("no signatures found for %v: %v", sourceRef, err)
+		return nil
+	}
+
+	targetDesc, err := remote.Get(targetSigRef, options...)
+	if err == nil && desc.Digest.String() == targetDesc.Digest.String() {
+		return nil
+	}
+
+	return copyImage(desc, sourceSigRef, targetSigRef, options...)
+}
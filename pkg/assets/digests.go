@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/values"
+)
+
+const (
+	// cosignSignatureAnnotation is the layer annotation holding a cosign signature.
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+)
+
+// cosignPayload is the part of a cosign simple signing payload that we verify.
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// pinImageDigests returns true if images should be pinned to their digests.
+// Images are not pinned while gathering assets, as they may not yet have been copied to the download location.
+func (a *AssetBuilder) pinImageDigests() bool {
+	if a.GetAssets || a.AssetsLocation == nil || a.AssetsLocation.ImageDigests == nil {
+		return false
+	}
+	return a.AssetsLocation.ImageDigests.Enabled
+}
+
+// PinnedImages returns the references the remapped images are pinned to, keyed by image.
+// Unlike RemapImage, it does not record the images as assets.
+// It returns nil if image digests are not enabled.
+func (a *AssetBuilder) PinnedImages(images []string) (map[string]string, error) {
+	if !a.pinImageDigests() || len(images) == 0 {
+		return nil, nil
+	}
+
+	pinned := make(map[string]string)
+	for _, image := range images {
+		_, remapped := a.remapImageLocation(image)
+		digest, err := a.pinImage(remapped)
+		if err != nil {
+			return nil, err
+		}
+		pinned[image] = digest
+	}
+	return pinned, nil
+}
+
+// pinImage resolves an image to its digest, verifying its signature if a public key is configured,
+// and returns a reference to the image pinned to that digest.
+func (a *AssetBuilder) pinImage(image string) (string, error) {
+	if pinned, found := a.imageDigests[image]; found {
+		return pinned, nil
+	}
+
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference %q: %v", image, err)
+	}
+
+	options := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+
+	var digest v1.Hash
+	pinned := image
+	if d, ok := ref.(name.Digest); ok {
+		digest, err = v1.NewHash(d.DigestStr())
+		if err != nil {
+			return "", fmt.Errorf("parsing digest of %q: %v", image, err)
+		}
+	} else {
+		desc, err := remote.Head(ref, options...)
+		if err != nil {
+			return "", fmt.Errorf("resolving digest of image %q: %v", image, err)
+		}
+		digest = desc.Digest
+		pinned = image + "@" + digest.String()
+	}
+
+	if publicKey := values.StringValue(a.AssetsLocation.ImageDigests.SignaturePublicKey); publicKey != "" {
+		key, err := pki.ParsePEMPublicKey(publicKey)
+		if err != nil {
+			return "", err
+		}
+		if err := verifyImageSignature(ref.Context(), digest, key, options...); err != nil {
+			return "", fmt.Errorf("verifying signature of image %q: %v", image, err)
+		}
+	}
+
+	klog.V(2).Infof("pinned image %q to %q", image, pinned)
+
+	if a.imageDigests == nil {
+		a.imageDigests = make(map[string]string)
+	}
+	a.imageDigests[image] = pinned
+	a.imageDigests[pinned] = pinned
+
+	return pinned, nil
+}
+
+// signatureTag returns the tag under which cosign stores the signatures for the digest.
+func signatureTag(repo name.Repository, digest v1.Hash) name.Tag {
+	return repo.Tag(fmt.Sprintf("%s-%s.sig", digest.Algorithm, digest.Hex))
+}
+
+// verifyImageSignature checks that the image with the digest has at least one cosign signature
+// made with the private key matching publicKey.
+func verifyImageSignature(repo name.Repository, digest v1.Hash, publicKey crypto.PublicKey, options ...remote.Option) error {
+	sigRef := signatureTag(repo, digest)
+
+	img, err := remote.Image(sigRef, options...)
+	if err != nil {
+		return fmt.Errorf("fetching signatures %q: %v", sigRef, err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("reading signatures %q: %v", sigRef, err)
+	}
+
+	for _, desc := range manifest.Layers {
+		encoded, ok := desc.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			klog.V(2).Infof("ignoring malformed signature in %q: %v", sigRef, err)
+			continue
+		}
+
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return fmt.Errorf("reading signature payload in %q: %v", sigRef, err)
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			return fmt.Errorf("reading signature payload in %q: %v", sigRef, err)
+		}
+		payload, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("reading signature payload in %q: %v", sigRef, err)
+		}
+
+		if err := verifySignature(publicKey, payload, signature); err != nil {
+			klog.V(2).Infof("ignoring signature in %q: %v", sigRef, err)
+			continue
+		}
+
+		var p cosignPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			klog.V(2).Infof("ignoring signature in %q with malformed payload: %v", sigRef, err)
+			continue
+		}
+		if p.Critical.Image.DockerManifestDigest != digest.String() {
+			klog.V(2).Infof("ignoring signature in %q for digest %q", sigRef, p.Critical.Image.DockerManifestDigest)
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no valid signature found in %q", sigRef)
+}
+
+// verifySignature verifies the signature of the payload.
+func verifySignature(publicKey crypto.PublicKey, payload []byte, signature []byte) error {
+	hash := sha256.Sum256(payload)
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestRemapImagePinsDigests(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	signed := host + "/signed:1.0"
+	signedDigest := pushRandomImage(t, signed)
+	unsigned := host + "/unsigned:1.0"
+	pushRandomImage(t, unsigned)
+
+	key := generateKey(t)
+	pushSignature(t, signed, signedDigest, key)
+
+	otherKey := generateKey(t)
+
+	grid := []struct {
+		Description   string
+		Image         string
+		PublicKey     *ecdsa.PrivateKey
+		ExpectedImage string
+		ExpectedError string
+	}{
+		{
+			Description:   "Unsigned image without verification",
+			Image:         unsigned,
+			ExpectedImage: unsigned + "@",
+		},
+		{
+			Description:   "Signed image",
+			Image:         signed,
+			PublicKey:     key,
+			ExpectedImage: signed + "@" + signedDigest.String(),
+		},
+		{
+			Description:   "Signed image pinned to a digest",
+			Image:         host + "/signed@" + signedDigest.String(),
+			PublicKey:     key,
+			ExpectedImage: host + "/signed@" + signedDigest.String(),
+		},
+		{
+			Description:   "Signed image with another key",
+			Image:         signed,
+			PublicKey:     otherKey,
+			ExpectedError: "no valid signature found",
+		},
+		{
+			Description:   "Unsigned image",
+			Image:         unsigned,
+			PublicKey:     key,
+			ExpectedError: "fetching signatures",
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			imageDigests := &kops.ImageDigestsSpec{Enabled: true}
+			if g.PublicKey != nil {
+				publicKey := encodePublicKey(t, g.PublicKey)
+				imageDigests.SignaturePublicKey = &publicKey
+			}
+			builder := &AssetBuilder{
+				AssetsLocation: &kops.Assets{ImageDigests: imageDigests},
+			}
+
+			// Remap twice, as kops does until the cluster spec converges.
+			for i := 0; i < 2; i++ {
+				image, err := builder.RemapImage(g.Image)
+				if g.ExpectedError != "" {
+					if err == nil || !strings.Contains(err.Error(), g.ExpectedError) {
+						t.Fatalf("expected error containing %q, got %v", g.ExpectedError, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !strings.HasPrefix(image, g.ExpectedImage) || !strings.Contains(image, "@sha256:") {
+					t.Fatalf("unexpected image: got %q, want %q", image, g.ExpectedImage)
+				}
+				g.Image = image
+			}
+		})
+	}
+}
+
+func TestRemapImageGetAssetsDoesNotPin(t *testing.T) {
+	builder := &AssetBuilder{
+		AssetsLocation: &kops.Assets{ImageDigests: &kops.ImageDigestsSpec{Enabled: true}},
+		GetAssets:      true,
+	}
+
+	image, err := builder.RemapImage("k8s.gcr.io/pause:3.2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image != "k8s.gcr.io/pause:3.2" {
+		t.Errorf("unexpected image: %q", image)
+	}
+}
+
+func TestPinnedImages(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image := host + "/etcd:3.4.13"
+	digest := pushRandomImage(t, image)
+	pushRandomImage(t, host+"/unused:1.0")
+
+	builder := &AssetBuilder{
+		AssetsLocation: &kops.Assets{ImageDigests: &kops.ImageDigestsSpec{Enabled: true}},
+	}
+	pinned, err := builder.PinnedImages([]string{image})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{image: image + "@" + digest.String()}
+	if !reflect.DeepEqual(pinned, expected) {
+		t.Errorf("unexpected pinned images: got %v, want %v", pinned, expected)
+	}
+	if len(builder.ImageAssets) != 0 {
+		t.Errorf("expected no image assets to be recorded, got %v", builder.ImageAssets)
+	}
+
+	builder.AssetsLocation.ImageDigests.Enabled = false
+	pinned, err = builder.PinnedImages([]string{image})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pinned != nil {
+		t.Errorf("expected no pinned images when image digests are disabled, got %v", pinned)
+	}
+}
+
+func pushRandomImage(t *testing.T, image string) v1.Hash {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatalf("error building image: %v", err)
+	}
+	if err := remote.Write(mustParseReference(t, image), img); err != nil {
+		t.Fatalf("error pushing image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("error getting image digest: %v", err)
+	}
+	return digest
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	return key
+}
+
+func encodePublicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("error encoding public key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// pushSignature pushes a cosign style signature for the image digest.
+func pushSignature(t *testing.T, image string, digest v1.Hash, key *ecdsa.PrivateKey) {
+	ref := mustParseReference(t, image)
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, ref.Context().Name(), digest.String()))
+	hash := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("error signing payload: %v", err)
+	}
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer: newPayloadLayer(payload),
+		Annotations: map[string]string{
+			cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature),
+		},
+	})
+	if err != nil {
+		t.Fatalf("error building signature image: %v", err)
+	}
+	if err := remote.Write(signatureTag(ref.Context(), digest), img); err != nil {
+		t.Fatalf("error pushing signature: %v", err)
+	}
+}
+
+// payloadLayer is an uncompressed layer holding a signature payload.
+type payloadLayer struct {
+	payload []byte
+	digest  v1.Hash
+}
+
+var _ v1.Layer = &payloadLayer{}
+
+func newPayloadLayer(payload []byte) *payloadLayer {
+	sum := sha256.Sum256(payload)
+	return &payloadLayer{
+		payload: payload,
+		digest:  v1.Hash{Algorithm: "sha256", Hex: fmt.Sprintf("%x", sum)},
+	}
+}
+
+func (l *payloadLayer) Digest() (v1.Hash, error) {
+	return l.digest, nil
+}
+
+func (l *payloadLayer) DiffID() (v1.Hash, error) {
+	return l.digest, nil
+}
+
+func (l *payloadLayer) Compressed() (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(l.payload)), nil
+}
+
+func (l *payloadLayer) Uncompressed() (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(l.payload)), nil
+}
+
+func (l *payloadLayer) Size() (int64, error) {
+	return int64(len(l.payload)), nil
+}
+
+func (l *payloadLayer) MediaType() (types.MediaType, error) {
+	return "application/vnd.dev.cosign.simplesigning.v1+json", nil
+}
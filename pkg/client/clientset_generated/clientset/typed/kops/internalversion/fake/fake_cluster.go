@@ -102,6 +102,18 @@ func (c *FakeClusters) Update(ctx context.Context, cluster *kops.Cluster, opts v
 	return obj.(*kops.Cluster), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeClusters) UpdateStatus(ctx context.Context, cluster *kops.Cluster, opts v1.UpdateOptions) (*kops.Cluster, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(clustersResource, "status", c.ns, cluster), &kops.Cluster{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*kops.Cluster), err
+}
+
 // Delete takes name of the cluster and deletes it. Returns an error if one occurs.
 func (c *FakeClusters) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -40,6 +40,7 @@ type ClustersGetter interface {
 type ClusterInterface interface {
 	Create(ctx context.Context, cluster *kops.Cluster, opts v1.CreateOptions) (*kops.Cluster, error)
 	Update(ctx context.Context, cluster *kops.Cluster, opts v1.UpdateOptions) (*kops.Cluster, error)
+	UpdateStatus(ctx context.Context, cluster *kops.Cluster, opts v1.UpdateOptions) (*kops.Cluster, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
 	Get(ctx context.Context, name string, opts v1.GetOptions) (*kops.Cluster, error)
@@ -135,6 +136,22 @@ func (c *clusters) Update(ctx context.Context, cluster *kops.Cluster, opts v1.Up
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *clusters) UpdateStatus(ctx context.Context, cluster *kops.Cluster, opts v1.UpdateOptions) (result *kops.Cluster, err error) {
+	result = &kops.Cluster{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("clusters").
+		Name(cluster.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(cluster).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the cluster and deletes it. Returns an error if one occurs.
 func (c *clusters) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
@@ -0,0 +1,310 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdclientset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/apis/kops/v1alpha2"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	kopsinternalversion "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/internalversion"
+	kopsv1alpha2 "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/v1alpha2"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/secrets"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// CRDClientset is an implementation of simple.Clientset that stores clusters and instance groups
+// as Cluster and InstanceGroup custom resources in a management cluster.
+// The keystore, the secrets and the addons are stored in the ConfigBase of the cluster, which must be
+// cluster readable, because the nodes read their configuration from it.
+type CRDClientset struct {
+	KopsClient kopsv1alpha2.KopsV1alpha2Interface
+
+	// Namespace is the namespace of the custom resources.
+	// If empty, each cluster is stored in a namespace named after the cluster.
+	Namespace string
+
+	// ConfigBase is the location under which the configBase of the clusters defaults to a directory named after the cluster.
+	// If nil, the configBase must be set on the clusters.
+	ConfigBase vfs.Path
+}
+
+var _ simple.Clientset = &CRDClientset{}
+
+// NewCRDClientset builds a CRDClientset storing the custom resources in the namespace, or in per-cluster namespaces if it is empty,
+// and defaulting the configBase of the clusters to a directory under configBase, if it is not nil
+func NewCRDClientset(kopsClient kopsv1alpha2.KopsV1alpha2Interface, namespace string, configBase vfs.Path) *CRDClientset {
+	return &CRDClientset{
+		KopsClient: kopsClient,
+		Namespace:  namespace,
+		ConfigBase: configBase,
+	}
+}
+
+// GetCluster implements the GetCluster method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) GetCluster(ctx context.Context, name string) (*kops.Cluster, error) {
+	versioned, err := c.KopsClient.Clusters(c.namespaceFor(name)).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toInternalCluster(versioned)
+}
+
+// CreateCluster implements the CreateCluster method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) CreateCluster(ctx context.Context, cluster *kops.Cluster) (*kops.Cluster, error) {
+	if errs := validation.ValidateCluster(cluster, false); len(errs) != 0 {
+		return nil, errs.ToAggregate()
+	}
+	if cluster.Spec.ConfigBase == "" {
+		configBase, err := c.ConfigBaseFor(cluster)
+		if err != nil {
+			return nil, err
+		}
+		cluster.Spec.ConfigBase = configBase.Path()
+	}
+
+	versioned, err := toVersionedCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	versioned.Status = nil
+
+	created, err := c.KopsClient.Clusters(c.namespaceFor(cluster.Name)).Create(ctx, versioned, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toInternalCluster(created)
+}
+
+// UpdateCluster implements the UpdateCluster method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) UpdateCluster(ctx context.Context, cluster *kops.Cluster, status *kops.ClusterStatus) (*kops.Cluster, error) {
+	old, err := c.GetCluster(ctx, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateClusterUpdate(cluster, status, old).ToAggregate(); err != nil {
+		return nil, err
+	}
+
+	versioned, err := toVersionedCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	if versioned.ResourceVersion == "" {
+		// The cluster was not read from the API, e.g. by "kops replace"
+		versioned.ResourceVersion = old.ResourceVersion
+	}
+
+	updated, err := c.KopsClient.Clusters(c.namespaceFor(cluster.Name)).Update(ctx, versioned, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toInternalCluster(updated)
+}
+
+// UpdateClusterStatus writes the Status of the cluster to the status subresource of the latest version of the cluster
+func (c *CRDClientset) UpdateClusterStatus(ctx context.Context, cluster *kops.Cluster) (*kops.Cluster, error) {
+	status := &v1alpha2.ReconcileStatus{}
+	if cluster.Status != nil {
+		if err := kopscodecs.Scheme.Convert(cluster.Status, status, nil); err != nil {
+			return nil, fmt.Errorf("error converting status of cluster %q: %v", cluster.Name, err)
+		}
+	}
+
+	clusters := c.KopsClient.Clusters(c.namespaceFor(cluster.Name))
+	var updated *v1alpha2.Cluster
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := clusters.Get(ctx, cluster.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest.Status = status
+		updated, err = clusters.UpdateStatus(ctx, latest, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error updating status of cluster %q: %v", cluster.Name, err)
+	}
+	return toInternalCluster(updated)
+}
+
+// ListClusters implements the ListClusters method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) ListClusters(ctx context.Context, options metav1.ListOptions) (*kops.ClusterList, error) {
+	namespace := c.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceAll
+	}
+	list, err := c.KopsClient.Clusters(namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := &kops.ClusterList{}
+	for i := range list.Items {
+		cluster, err := toInternalCluster(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		clusters.Items = append(clusters.Items, *cluster)
+	}
+	return clusters, nil
+}
+
+// ConfigBaseFor implements the ConfigBaseFor method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) ConfigBaseFor(cluster *kops.Cluster) (vfs.Path, error) {
+	if cluster.Spec.ConfigBase != "" {
+		return vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+	}
+	if c.ConfigBase == nil {
+		return nil, fmt.Errorf("configBase must be set for clusters stored in custom resources, unless the state store sets a default configBase")
+	}
+	return c.ConfigBase.Join(cluster.Name), nil
+}
+
+// InstanceGroupsFor implements the InstanceGroupsFor method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) InstanceGroupsFor(cluster *kops.Cluster) kopsinternalversion.InstanceGroupInterface {
+	return newInstanceGroupsCRD(c.KopsClient.InstanceGroups(c.namespaceFor(cluster.Name)), cluster)
+}
+
+// AddonsFor implements the AddonsFor method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) AddonsFor(cluster *kops.Cluster) simple.AddonsClient {
+	configBase, err := c.ConfigBaseFor(cluster)
+	if err != nil {
+		klog.Fatalf("error building ConfigBase for cluster %q: %v", cluster.Name, err)
+	}
+	return vfsclientset.NewAddonsClient(configBase, cluster)
+}
+
+func (c *CRDClientset) SecretStore(cluster *kops.Cluster) (fi.SecretStore, error) {
+	if cluster.Spec.SecretStore == "" {
+		configBase, err := registry.ConfigBase(cluster)
+		if err != nil {
+			return nil, err
+		}
+		return secrets.NewVFSSecretStore(cluster, configBase.Join("secrets")), nil
+	}
+	storePath, err := vfs.Context.BuildVfsPath(cluster.Spec.SecretStore)
+	if err != nil {
+		return nil, err
+	}
+	return secrets.NewVFSSecretStore(cluster, storePath), nil
+}
+
+func (c *CRDClientset) KeyStore(cluster *kops.Cluster) (fi.CAStore, error) {
+	basedir, err := pkiPath(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return fi.NewVFSCAStore(cluster, basedir), nil
+}
+
+func (c *CRDClientset) SSHCredentialStore(cluster *kops.Cluster) (fi.SSHCredentialStore, error) {
+	basedir, err := pkiPath(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return fi.NewVFSSSHCredentialStore(cluster, basedir), nil
+}
+
+func pkiPath(cluster *kops.Cluster) (vfs.Path, error) {
+	if cluster.Spec.KeyStore == "" {
+		configBase, err := registry.ConfigBase(cluster)
+		if err != nil {
+			return nil, err
+		}
+		return configBase.Join("pki"), nil
+	}
+	return vfs.Context.BuildVfsPath(cluster.Spec.KeyStore)
+}
+
+// DeleteCluster implements the DeleteCluster method of simple.Clientset for a CRD-backed state store
+func (c *CRDClientset) DeleteCluster(ctx context.Context, cluster *kops.Cluster) error {
+	if err := vfsclientset.DeleteClusterState(cluster); err != nil {
+		return err
+	}
+
+	name := cluster.Name
+	namespace := c.namespaceFor(name)
+
+	igs, err := c.KopsClient.InstanceGroups(namespace).List(ctx, metav1.ListOptions{LabelSelector: kops.LabelClusterName + "=" + name})
+	if err != nil {
+		return fmt.Errorf("error listing instance groups: %v", err)
+	}
+	for i := range igs.Items {
+		ig := &igs.Items[i]
+		err = c.KopsClient.InstanceGroups(namespace).Delete(ctx, ig.Name, metav1.DeleteOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				klog.Warningf("instance group %q was concurrently deleted", ig.Name)
+			} else {
+				return fmt.Errorf("error deleting instance group %q: %v", ig.Name, err)
+			}
+		}
+	}
+
+	err = c.KopsClient.Clusters(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Warningf("cluster %q was concurrently deleted", name)
+		} else {
+			return fmt.Errorf("error deleting cluster %q: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *CRDClientset) namespaceFor(clusterName string) string {
+	if c.Namespace != "" {
+		return c.Namespace
+	}
+	// We are not allowed dots, so we map them to dashes, as for the kops API server
+	return strings.Replace(clusterName, ".", "-", -1)
+}
+
+func toInternalCluster(versioned *v1alpha2.Cluster) (*kops.Cluster, error) {
+	versioned = versioned.DeepCopy()
+	kopscodecs.Scheme.Default(versioned)
+
+	cluster := &kops.Cluster{}
+	if err := kopscodecs.Scheme.Convert(versioned, cluster, nil); err != nil {
+		return nil, fmt.Errorf("error converting cluster %q: %v", versioned.Name, err)
+	}
+	// The namespace is derived from the name of the cluster, and clusters are not namespaced in the kops API
+	cluster.Namespace = ""
+	return cluster, nil
+}
+
+func toVersionedCluster(cluster *kops.Cluster) (*v1alpha2.Cluster, error) {
+	versioned := &v1alpha2.Cluster{}
+	if err := kopscodecs.Scheme.Convert(cluster, versioned, nil); err != nil {
+		return nil, fmt.Errorf("error converting cluster %q: %v", cluster.Name, err)
+	}
+	return versioned, nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdclientset
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func testInstanceGroup(name string) *kops.InstanceGroup {
+	ig := testutils.BuildMinimalNodeInstanceGroup(name, "subnet-us-mock-1a")
+	ig.Spec.MaxSize = fi.Int32(2)
+	return &ig
+}
+
+func TestCRDClientsetClusters(t *testing.T) {
+	ctx := context.TODO()
+	vfs.Context.ResetMemfsContext(true)
+
+	kopsClient := fake.NewSimpleClientset()
+	clientset := NewCRDClientset(kopsClient.KopsV1alpha2(), "", nil)
+
+	cluster := testutils.BuildMinimalCluster("a.example.com")
+	cluster.Spec.ConfigBase = ""
+	if _, err := clientset.CreateCluster(ctx, cluster); err == nil {
+		t.Errorf("expected an error creating a cluster without configBase")
+	}
+
+	if _, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("a.example.com")); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	if _, err := kopsClient.KopsV1alpha2().Clusters("a-example-com").Get(ctx, "a.example.com", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the cluster to be stored in its namespace: %v", err)
+	}
+
+	cluster, err := clientset.GetCluster(ctx, "a.example.com")
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	if cluster.Spec.KubernetesVersion != "1.14.6" || cluster.Spec.ConfigBase != "memfs://unittest-bucket/a.example.com" {
+		t.Errorf("unexpected cluster spec %v", cluster.Spec)
+	}
+
+	cluster.Spec.KubernetesVersion = "1.21.1"
+	if _, err := clientset.UpdateCluster(ctx, cluster, nil); err != nil {
+		t.Fatalf("error updating cluster: %v", err)
+	}
+
+	cluster.Status = &kops.ReconcileStatus{
+		ObservedGeneration: 2,
+		Phase:              kops.ReconcilePhaseReady,
+	}
+	updated, err := clientset.UpdateClusterStatus(ctx, cluster)
+	if err != nil {
+		t.Fatalf("error updating cluster status: %v", err)
+	}
+	if updated.Spec.KubernetesVersion != "1.21.1" || updated.Status == nil || updated.Status.Phase != kops.ReconcilePhaseReady {
+		t.Errorf("unexpected cluster %v", updated)
+	}
+
+	if _, err := clientset.CreateCluster(ctx, testutils.BuildMinimalCluster("b.example.com")); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	clusters, err := clientset.ListClusters(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing clusters: %v", err)
+	}
+	if len(clusters.Items) != 2 {
+		t.Errorf("expected 2 clusters, got %d", len(clusters.Items))
+	}
+
+	configBase, err := clientset.ConfigBaseFor(cluster)
+	if err != nil || configBase.Path() != "memfs://unittest-bucket/a.example.com" {
+		t.Errorf("unexpected configBase %v, err %v", configBase, err)
+	}
+
+	if err := clientset.DeleteCluster(ctx, cluster); err != nil {
+		t.Fatalf("error deleting cluster: %v", err)
+	}
+	if _, err := clientset.GetCluster(ctx, "a.example.com"); !errors.IsNotFound(err) {
+		t.Errorf("expected not found error getting deleted cluster, got %v", err)
+	}
+}
+
+func TestCRDClientsetDefaultConfigBase(t *testing.T) {
+	ctx := context.TODO()
+	vfs.Context.ResetMemfsContext(true)
+
+	configBase, err := vfs.Context.BuildVfsPath("memfs://kops-config")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	kopsClient := fake.NewSimpleClientset()
+	clientset := NewCRDClientset(kopsClient.KopsV1alpha2(), "kops", configBase)
+
+	cluster := testutils.BuildMinimalCluster("a.example.com")
+	cluster.Spec.ConfigBase = ""
+	created, err := clientset.CreateCluster(ctx, cluster)
+	if err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	if created.Spec.ConfigBase != "memfs://kops-config/a.example.com" {
+		t.Errorf("unexpected configBase %q", created.Spec.ConfigBase)
+	}
+
+	cluster = testutils.BuildMinimalCluster("b.example.com")
+	if created, err := clientset.CreateCluster(ctx, cluster); err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	} else if created.Spec.ConfigBase != "memfs://unittest-bucket/b.example.com" {
+		t.Errorf("expected the configBase of the cluster to be kept, got %q", created.Spec.ConfigBase)
+	}
+}
+
+func TestCRDClientsetInstanceGroups(t *testing.T) {
+	ctx := context.TODO()
+
+	kopsClient := fake.NewSimpleClientset()
+	clientset := NewCRDClientset(kopsClient.KopsV1alpha2(), "kops", nil)
+
+	a := testutils.BuildMinimalCluster("a.example.com")
+	b := testutils.BuildMinimalCluster("b.example.com")
+	for _, cluster := range []*kops.Cluster{a, b} {
+		if _, err := clientset.CreateCluster(ctx, cluster); err != nil {
+			t.Fatalf("error creating cluster: %v", err)
+		}
+	}
+
+	if _, err := clientset.InstanceGroupsFor(a).Create(ctx, testInstanceGroup("nodes"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+	if _, err := clientset.InstanceGroupsFor(a).Create(ctx, testInstanceGroup("bastions"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+
+	ig, err := clientset.InstanceGroupsFor(a).Get(ctx, "nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting instance group: %v", err)
+	}
+	if ig.Labels[kops.LabelClusterName] != "a.example.com" {
+		t.Errorf("expected the instance group to be labeled with the cluster name, got %v", ig.Labels)
+	}
+
+	// The clusters share the namespace, so the instance groups are filtered by cluster
+	if _, err := clientset.InstanceGroupsFor(b).Get(ctx, "nodes", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected not found error getting instance group of another cluster, got %v", err)
+	}
+	list, err := clientset.InstanceGroupsFor(b).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing instance groups: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("expected no instance groups for cluster b, got %d", len(list.Items))
+	}
+
+	ig.Spec.MaxSize = fi.Int32(5)
+	if _, err := clientset.InstanceGroupsFor(a).Update(ctx, ig, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("error updating instance group: %v", err)
+	}
+	list, err = clientset.InstanceGroupsFor(a).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing instance groups: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("expected 2 instance groups for cluster a, got %d", len(list.Items))
+	}
+	for _, ig := range list.Items {
+		if ig.Name == "nodes" && *ig.Spec.MaxSize != 5 {
+			t.Errorf("expected the instance group to be updated, got %v", ig.Spec)
+		}
+	}
+
+	if err := clientset.InstanceGroupsFor(a).Delete(ctx, "bastions", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("error deleting instance group: %v", err)
+	}
+	if _, err := clientset.InstanceGroupsFor(a).Get(ctx, "bastions", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected not found error getting deleted instance group, got %v", err)
+	}
+}
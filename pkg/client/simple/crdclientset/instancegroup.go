@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdclientset
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/v1alpha2"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	kopsinternalversion "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/internalversion"
+	kopsv1alpha2 "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/v1alpha2"
+	"k8s.io/kops/pkg/kopscodecs"
+)
+
+// instanceGroupsCRD adapts the versioned client of the InstanceGroup custom resources to the internal API.
+// The instance groups of the cluster are labeled with its name, so that several clusters can share a namespace.
+type instanceGroupsCRD struct {
+	client  kopsv1alpha2.InstanceGroupInterface
+	cluster *kops.Cluster
+}
+
+var _ kopsinternalversion.InstanceGroupInterface = &instanceGroupsCRD{}
+
+func newInstanceGroupsCRD(client kopsv1alpha2.InstanceGroupInterface, cluster *kops.Cluster) *instanceGroupsCRD {
+	if cluster == nil || cluster.Name == "" {
+		klog.Fatalf("cluster / cluster.Name is required")
+	}
+	return &instanceGroupsCRD{
+		client:  client,
+		cluster: cluster,
+	}
+}
+
+func (c *instanceGroupsCRD) Get(ctx context.Context, name string, options metav1.GetOptions) (*kops.InstanceGroup, error) {
+	versioned, err := c.client.Get(ctx, name, options)
+	if err != nil {
+		return nil, err
+	}
+	if versioned.Labels[kops.LabelClusterName] != c.cluster.Name {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: kops.GroupName, Resource: "InstanceGroup"}, name)
+	}
+	return toInternalInstanceGroup(versioned)
+}
+
+func (c *instanceGroupsCRD) List(ctx context.Context, options metav1.ListOptions) (*kops.InstanceGroupList, error) {
+	options.LabelSelector = c.labelSelector(options.LabelSelector)
+	list, err := c.client.List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	igs := &kops.InstanceGroupList{}
+	for i := range list.Items {
+		ig, err := toInternalInstanceGroup(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		igs.Items = append(igs.Items, *ig)
+	}
+	return igs, nil
+}
+
+func (c *instanceGroupsCRD) Create(ctx context.Context, ig *kops.InstanceGroup, options metav1.CreateOptions) (*kops.InstanceGroup, error) {
+	if err := validation.ValidateInstanceGroup(ig, nil).ToAggregate(); err != nil {
+		return nil, err
+	}
+
+	versioned, err := c.toVersioned(ig)
+	if err != nil {
+		return nil, err
+	}
+	created, err := c.client.Create(ctx, versioned, options)
+	if err != nil {
+		return nil, err
+	}
+	return toInternalInstanceGroup(created)
+}
+
+func (c *instanceGroupsCRD) Update(ctx context.Context, ig *kops.InstanceGroup, options metav1.UpdateOptions) (*kops.InstanceGroup, error) {
+	if err := validation.ValidateInstanceGroup(ig, nil).ToAggregate(); err != nil {
+		return nil, err
+	}
+
+	versioned, err := c.toVersioned(ig)
+	if err != nil {
+		return nil, err
+	}
+	if versioned.ResourceVersion == "" {
+		old, err := c.Get(ctx, ig.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		versioned.ResourceVersion = old.ResourceVersion
+	}
+	updated, err := c.client.Update(ctx, versioned, options)
+	if err != nil {
+		return nil, err
+	}
+	return toInternalInstanceGroup(updated)
+}
+
+func (c *instanceGroupsCRD) Delete(ctx context.Context, name string, options metav1.DeleteOptions) error {
+	if _, err := c.Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return err
+	}
+	return c.client.Delete(ctx, name, options)
+}
+
+func (c *instanceGroupsCRD) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	listOptions.LabelSelector = c.labelSelector(listOptions.LabelSelector)
+	return c.client.DeleteCollection(ctx, options, listOptions)
+}
+
+func (c *instanceGroupsCRD) Watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+	return nil, fmt.Errorf("instance group Watch not implemented for the internal API, watch the custom resources instead")
+}
+
+func (c *instanceGroupsCRD) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*kops.InstanceGroup, error) {
+	return nil, fmt.Errorf("instance group Patch not implemented for the internal API, patch the custom resources instead")
+}
+
+func (c *instanceGroupsCRD) labelSelector(selector string) string {
+	clusterSelector := kops.LabelClusterName + "=" + c.cluster.Name
+	if selector == "" {
+		return clusterSelector
+	}
+	return selector + "," + clusterSelector
+}
+
+func (c *instanceGroupsCRD) toVersioned(ig *kops.InstanceGroup) (*v1alpha2.InstanceGroup, error) {
+	versioned := &v1alpha2.InstanceGroup{}
+	if err := kopscodecs.Scheme.Convert(ig, versioned, nil); err != nil {
+		return nil, fmt.Errorf("error converting instance group %q: %v", ig.Name, err)
+	}
+	if versioned.Labels == nil {
+		versioned.Labels = make(map[string]string)
+	}
+	versioned.Labels[kops.LabelClusterName] = c.cluster.Name
+	return versioned, nil
+}
+
+func toInternalInstanceGroup(versioned *v1alpha2.InstanceGroup) (*kops.InstanceGroup, error) {
+	versioned = versioned.DeepCopy()
+	kopscodecs.Scheme.Default(versioned)
+
+	ig := &kops.InstanceGroup{}
+	if err := kopscodecs.Scheme.Convert(versioned, ig, nil); err != nil {
+		return nil, fmt.Errorf("error converting instance group %q: %v", versioned.Name, err)
+	}
+	ig.Namespace = ""
+	return ig, nil
+}
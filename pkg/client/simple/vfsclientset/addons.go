@@ -43,13 +43,24 @@ func newAddonsVFS(c *VFSClientset, cluster *kops.Cluster) *vfsAddonsClient {
 		klog.Fatalf("cluster / cluster.Name is required")
 	}
 
-	clusterName := cluster.Name
+	return newAddonsVFSForConfigBase(c.basePath.Join(cluster.Name), cluster)
+}
+
+// NewAddonsClient builds an AddonsClient storing the addons of the cluster under its configBase
+func NewAddonsClient(configBase vfs.Path, cluster *kops.Cluster) simple.AddonsClient {
+	if cluster == nil || cluster.Name == "" {
+		klog.Fatalf("cluster / cluster.Name is required")
+	}
+
+	return newAddonsVFSForConfigBase(configBase, cluster)
+}
 
+func newAddonsVFSForConfigBase(configBase vfs.Path, cluster *kops.Cluster) *vfsAddonsClient {
 	r := &vfsAddonsClient{
 		cluster:     cluster,
-		clusterName: clusterName,
+		clusterName: cluster.Name,
 	}
-	r.basePath = c.basePath.Join(clusterName, "clusteraddons")
+	r.basePath = configBase.Join("clusteraddons")
 
 	return r
 }
@@ -202,6 +202,11 @@ func deleteAllPaths(basePath vfs.Path) error {
 	return nil
 }
 func (c *VFSClientset) DeleteCluster(ctx context.Context, cluster *kops.Cluster) error {
+	return DeleteClusterState(cluster)
+}
+
+// DeleteClusterState deletes the files of the cluster in its configBase, keystore, secret store and discovery store
+func DeleteClusterState(cluster *kops.Cluster) error {
 	if cluster.Spec.ServiceAccountIssuerDiscovery != nil {
 		discoveryStore := cluster.Spec.ServiceAccountIssuerDiscovery.DiscoveryStore
 		if discoveryStore != "" {
@@ -50,4 +50,6 @@ type CloudInstance struct {
 	PrivateIP string
 	// State is in which state the instance is in
 	State State
+	// InPlaceUpdate is true if the changes needed by the instance can be applied without replacing it
+	InPlaceUpdate bool
 }
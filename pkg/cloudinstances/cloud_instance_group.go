@@ -35,6 +35,8 @@ type CloudInstanceGroup struct {
 	MinSize       int
 	TargetSize    int
 	MaxSize       int
+	// NodeupConfigHash is the hash of the nodeup config of new instances, set when some instances can be updated in place
+	NodeupConfigHash string
 
 	// Raw allows for the implementer to attach an object, for tracking additional state
 	Raw interface{}
@@ -114,6 +116,20 @@ func GetNodeMap(nodes []v1.Node, cluster *kopsapi.Cluster) map[string]*v1.Node {
 		return nodeMap
 	}
 
+	// Metal nodes have no provider ID; they are matched to the hosts by name, or by internal address
+	if kopsapi.CloudProviderID(cluster.Spec.CloudProvider) == kopsapi.CloudProviderMetal {
+		for i := range nodes {
+			node := &nodes[i]
+			nodeMap[node.Name] = node
+			for _, address := range node.Status.Addresses {
+				if address.Type == v1.NodeInternalIP {
+					nodeMap[address.Address] = node
+				}
+			}
+		}
+		return nodeMap
+	}
+
 	delimiter := "/"
 	// Alicloud CCM uses the "{region}.{instance-id}" of a instance as ProviderID.
 	// We need to set delimiter to "." for Alicloud.
@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinstances
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// FilterStatusNeedsUpdate selects the instances that need an update, including the detached ones
+	FilterStatusNeedsUpdate = "needsupdate"
+	// FilterStatusDetached selects the instances that have been detached from their group
+	FilterStatusDetached = "detached"
+	// FilterStatusNotReady selects the instances without a ready kubernetes node
+	FilterStatusNotReady = "notready"
+)
+
+// FilterStatuses are the supported values of InstanceFilter.Status
+var FilterStatuses = []string{FilterStatusNeedsUpdate, FilterStatusDetached, FilterStatusNotReady}
+
+// InstanceFilter selects cloud instances
+type InstanceFilter struct {
+	// Selector matches the labels of the kubernetes node of the instance, if set
+	Selector labels.Selector
+	// InstanceGroups are the names of the instance groups to select from; all instance groups if empty
+	InstanceGroups []string
+	// Status is one of FilterStatuses; instances of any status are selected if empty
+	Status string
+}
+
+// Validate checks the filter settings
+func (f *InstanceFilter) Validate() error {
+	switch f.Status {
+	case "", FilterStatusNeedsUpdate, FilterStatusDetached, FilterStatusNotReady:
+		return nil
+	default:
+		return fmt.Errorf("unsupported status %q, expected one of %v", f.Status, FilterStatuses)
+	}
+}
+
+// NeedsNodes checks if the filter selects the instances by their kubernetes nodes
+func (f *InstanceFilter) NeedsNodes() bool {
+	return (f.Selector != nil && !f.Selector.Empty()) || f.Status == FilterStatusNotReady
+}
+
+// Matches checks if the instance is selected by the filter
+func (f *InstanceFilter) Matches(i *CloudInstance) bool {
+	if len(f.InstanceGroups) != 0 {
+		found := false
+		for _, name := range f.InstanceGroups {
+			if i.CloudInstanceGroup.InstanceGroup != nil && i.CloudInstanceGroup.InstanceGroup.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.Selector != nil && !f.Selector.Empty() {
+		if i.Node == nil || !f.Selector.Matches(labels.Set(i.Node.Labels)) {
+			return false
+		}
+	}
+
+	switch f.Status {
+	case FilterStatusNeedsUpdate:
+		return i.Status == CloudInstanceStatusNeedsUpdate || i.Status == CloudInstanceStatusDetached
+	case FilterStatusDetached:
+		return i.Status == CloudInstanceStatusDetached
+	case FilterStatusNotReady:
+		return nodeReady(i.Node) != string(v1.ConditionTrue)
+	}
+	return true
+}
+
+// InstanceSummary is a cloud instance joined with its kubernetes node
+type InstanceSummary struct {
+	// ID is the cloud identifier of the instance
+	ID string `json:"id"`
+	// InstanceGroup is the name of the instance group of the instance
+	InstanceGroup string `json:"instanceGroup"`
+	// CloudGroup is the name of the cloud group of the instance
+	CloudGroup string `json:"cloudGroup"`
+	// Status is UpToDate, NeedsUpdate or Detached
+	Status string `json:"status"`
+	// NeedsUpdateReason explains why the instance needs an update
+	NeedsUpdateReason string `json:"needsUpdateReason,omitempty"`
+	// Detached is true if the instance has been detached from its cloud group
+	Detached bool `json:"detached"`
+	// State is the state of the instance in its group, such as WarmPool
+	State string `json:"state,omitempty"`
+	// Roles are the roles of the instance
+	Roles []string `json:"roles,omitempty"`
+	// MachineType is the machine type of the instance
+	MachineType string `json:"machineType,omitempty"`
+	// InternalIP is the private IP address of the instance
+	InternalIP string `json:"internalIP,omitempty"`
+
+	// NodeName is the name of the kubernetes node of the instance, empty if the instance has not joined the cluster
+	NodeName string `json:"nodeName,omitempty"`
+	// Ready is the status of the Ready condition of the node: True, False or Unknown
+	Ready string `json:"ready,omitempty"`
+	// KubeletVersion is the version of the kubelet of the node
+	KubeletVersion string `json:"kubeletVersion,omitempty"`
+	// OSImage is the operating system of the node
+	OSImage string `json:"osImage,omitempty"`
+	// Taints are the taints of the node, in the key=value:Effect format
+	Taints []string `json:"taints,omitempty"`
+	// CreationTimestamp is the time the node registered
+	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
+}
+
+// Summarize joins the instances with their nodes, sorted by instance group and ID
+func Summarize(instances []*CloudInstance) []*InstanceSummary {
+	var summaries []*InstanceSummary
+	for _, i := range instances {
+		s := &InstanceSummary{
+			ID:                i.ID,
+			CloudGroup:        i.CloudInstanceGroup.HumanName,
+			Status:            i.Status,
+			NeedsUpdateReason: needsUpdateReason(i),
+			Detached:          i.Status == CloudInstanceStatusDetached,
+			State:             string(i.State),
+			Roles:             i.Roles,
+			MachineType:       i.MachineType,
+			InternalIP:        i.PrivateIP,
+		}
+		if i.CloudInstanceGroup.InstanceGroup != nil {
+			s.InstanceGroup = i.CloudInstanceGroup.InstanceGroup.Name
+		}
+		if node := i.Node; node != nil {
+			s.NodeName = node.Name
+			s.Ready = nodeReady(node)
+			s.KubeletVersion = node.Status.NodeInfo.KubeletVersion
+			s.OSImage = node.Status.NodeInfo.OSImage
+			for _, taint := range node.Spec.Taints {
+				s.Taints = append(s.Taints, taint.ToString())
+			}
+			creationTimestamp := node.CreationTimestamp
+			s.CreationTimestamp = &creationTimestamp
+		}
+		summaries = append(summaries, s)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].InstanceGroup != summaries[j].InstanceGroup {
+			return summaries[i].InstanceGroup < summaries[j].InstanceGroup
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries
+}
+
+// needsUpdateReason returns why the instance needs an update, or an empty string if it is up to date
+func needsUpdateReason(i *CloudInstance) string {
+	if i.Status == CloudInstanceStatusUpToDate {
+		return ""
+	}
+	if i.Status == CloudInstanceStatusDetached {
+		return "detached"
+	}
+	if i.Node != nil {
+		if _, ok := i.Node.Annotations["kops.k8s.io/needs-update"]; ok {
+			return "node annotated with kops.k8s.io/needs-update"
+		}
+	}
+	if i.InPlaceUpdate {
+		return "nodeup config changed"
+	}
+	return "instance template changed"
+}
+
+// nodeReady returns the status of the Ready condition of the node, or an empty string if there is no node
+func nodeReady(node *v1.Node) string {
+	if node == nil {
+		return ""
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return string(condition.Status)
+		}
+	}
+	return string(v1.ConditionUnknown)
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinstances
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+)
+
+func testNode(name string, ready v1.ConditionStatus, nodeLabels map[string]string) *v1.Node {
+	node := &v1.Node{}
+	node.Name = name
+	node.Labels = nodeLabels
+	node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}}
+	node.Status.NodeInfo.KubeletVersion = "v1.21.0"
+	node.Status.NodeInfo.OSImage = "Ubuntu 20.04.3 LTS"
+	return node
+}
+
+func testInstances(t *testing.T) map[string]*CloudInstance {
+	newGroup := func(name string) *CloudInstanceGroup {
+		ig := &kopsapi.InstanceGroup{}
+		ig.Name = name
+		return &CloudInstanceGroup{HumanName: name + ".example.com", InstanceGroup: ig}
+	}
+	nodes := newGroup("nodes")
+	batch := newGroup("batch")
+
+	instances := make(map[string]*CloudInstance)
+	add := func(group *CloudInstanceGroup, id string, status string, node *v1.Node) *CloudInstance {
+		i, err := group.NewCloudInstance(id, status, node)
+		if err != nil {
+			t.Fatalf("error creating instance: %v", err)
+		}
+		instances[id] = i
+		return i
+	}
+	add(nodes, "i-ready", CloudInstanceStatusUpToDate, testNode("node-ready", v1.ConditionTrue, map[string]string{"workload": "web"}))
+	add(nodes, "i-notready", CloudInstanceStatusUpToDate, testNode("node-notready", v1.ConditionFalse, map[string]string{"workload": "web"}))
+	add(nodes, "i-notjoined", CloudInstanceStatusUpToDate, nil)
+	add(batch, "i-outdated", CloudInstanceStatusNeedsUpdate, testNode("node-outdated", v1.ConditionTrue, map[string]string{"workload": "batch"}))
+	add(batch, "i-detached", CloudInstanceStatusDetached, testNode("node-detached", v1.ConditionTrue, map[string]string{"workload": "batch"}))
+	return instances
+}
+
+func TestInstanceFilter(t *testing.T) {
+	grid := []struct {
+		name     string
+		filter   InstanceFilter
+		selector string
+		// expected are sorted by instance group, then ID
+		expected []string
+	}{
+		{
+			name:     "all",
+			expected: []string{"i-detached", "i-outdated", "i-notjoined", "i-notready", "i-ready"},
+		},
+		{
+			name:     "instance group",
+			filter:   InstanceFilter{InstanceGroups: []string{"batch"}},
+			expected: []string{"i-detached", "i-outdated"},
+		},
+		{
+			name:     "selector",
+			selector: "workload=web",
+			expected: []string{"i-notready", "i-ready"},
+		},
+		{
+			name:     "needs update",
+			filter:   InstanceFilter{Status: FilterStatusNeedsUpdate},
+			expected: []string{"i-detached", "i-outdated"},
+		},
+		{
+			name:     "detached",
+			filter:   InstanceFilter{Status: FilterStatusDetached},
+			expected: []string{"i-detached"},
+		},
+		{
+			name:     "not ready",
+			filter:   InstanceFilter{Status: FilterStatusNotReady},
+			expected: []string{"i-notjoined", "i-notready"},
+		},
+		{
+			name:     "combined",
+			filter:   InstanceFilter{InstanceGroups: []string{"nodes"}, Status: FilterStatusNotReady},
+			selector: "workload",
+			expected: []string{"i-notready"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			filter := g.filter
+			if g.selector != "" {
+				selector, err := labels.Parse(g.selector)
+				if err != nil {
+					t.Fatalf("error parsing selector: %v", err)
+				}
+				filter.Selector = selector
+			}
+			if err := filter.Validate(); err != nil {
+				t.Fatalf("unexpected error validating filter: %v", err)
+			}
+
+			var matched []*CloudInstance
+			for _, i := range testInstances(t) {
+				if filter.Matches(i) {
+					matched = append(matched, i)
+				}
+			}
+			var actual []string
+			for _, s := range Summarize(matched) {
+				actual = append(actual, s.ID)
+			}
+			if !reflect.DeepEqual(actual, g.expected) {
+				t.Errorf("expected %v, got %v", g.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInstanceFilterValidate(t *testing.T) {
+	filter := &InstanceFilter{Status: "outdated"}
+	if err := filter.Validate(); err == nil {
+		t.Errorf("expected an error for an unsupported status")
+	}
+}
+
+func TestInstanceFilterNeedsNodes(t *testing.T) {
+	grid := []struct {
+		filter   *InstanceFilter
+		expected bool
+	}{
+		{filter: &InstanceFilter{}, expected: false},
+		{filter: &InstanceFilter{InstanceGroups: []string{"nodes"}, Status: FilterStatusNeedsUpdate}, expected: false},
+		{filter: &InstanceFilter{Selector: labels.Everything()}, expected: false},
+		{filter: &InstanceFilter{Selector: labels.SelectorFromSet(labels.Set{"role": "worker"})}, expected: true},
+		{filter: &InstanceFilter{Status: FilterStatusNotReady}, expected: true},
+	}
+	for _, g := range grid {
+		if actual := g.filter.NeedsNodes(); actual != g.expected {
+			t.Errorf("filter %+v: expected %v, got %v", g.filter, g.expected, actual)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	instances := testInstances(t)
+
+	annotated := instances["i-outdated"]
+	annotated.Node.Annotations = map[string]string{"kops.k8s.io/needs-update": ""}
+	annotated.Node.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "batch", Effect: v1.TaintEffectNoSchedule}}
+	annotated.Node.CreationTimestamp = metav1.Unix(1600000000, 0)
+	instances["i-detached"].InPlaceUpdate = true
+
+	var all []*CloudInstance
+	for _, i := range instances {
+		all = append(all, i)
+	}
+	summaries := make(map[string]*InstanceSummary)
+	for _, s := range Summarize(all) {
+		summaries[s.ID] = s
+	}
+
+	outdated := summaries["i-outdated"]
+	expected := &InstanceSummary{
+		ID:                "i-outdated",
+		InstanceGroup:     "batch",
+		CloudGroup:        "batch.example.com",
+		Status:            CloudInstanceStatusNeedsUpdate,
+		NeedsUpdateReason: "node annotated with kops.k8s.io/needs-update",
+		NodeName:          "node-outdated",
+		Ready:             "True",
+		KubeletVersion:    "v1.21.0",
+		OSImage:           "Ubuntu 20.04.3 LTS",
+		Taints:            []string{"dedicated=batch:NoSchedule"},
+		CreationTimestamp: &annotated.Node.CreationTimestamp,
+	}
+	if !reflect.DeepEqual(outdated, expected) {
+		t.Errorf("expected %+v, got %+v", expected, outdated)
+	}
+
+	detached := summaries["i-detached"]
+	if !detached.Detached || detached.NeedsUpdateReason != "detached" {
+		t.Errorf("expected a detached instance, got %+v", detached)
+	}
+	if reason := summaries["i-ready"].NeedsUpdateReason; reason != "" {
+		t.Errorf("expected no reason for an up to date instance, got %q", reason)
+	}
+	notJoined := summaries["i-notjoined"]
+	if notJoined.NodeName != "" || notJoined.Ready != "" || notJoined.CreationTimestamp != nil {
+		t.Errorf("expected no node details for an instance that has not joined, got %+v", notJoined)
+	}
+}
+
+func TestNeedsUpdateReason(t *testing.T) {
+	annotated := testNode("node-annotated", v1.ConditionTrue, nil)
+	annotated.Annotations = map[string]string{"kops.k8s.io/needs-update": ""}
+
+	grid := []struct {
+		name     string
+		instance *CloudInstance
+		expected string
+	}{
+		{
+			name:     "up to date",
+			instance: &CloudInstance{Status: CloudInstanceStatusUpToDate, InPlaceUpdate: true},
+			expected: "",
+		},
+		{
+			name:     "detached",
+			instance: &CloudInstance{Status: CloudInstanceStatusDetached, Node: annotated, InPlaceUpdate: true},
+			expected: "detached",
+		},
+		{
+			name:     "annotated",
+			instance: &CloudInstance{Status: CloudInstanceStatusNeedsUpdate, Node: annotated, InPlaceUpdate: true},
+			expected: "node annotated with kops.k8s.io/needs-update",
+		},
+		{
+			name:     "in-place update",
+			instance: &CloudInstance{Status: CloudInstanceStatusNeedsUpdate, InPlaceUpdate: true},
+			expected: "nodeup config changed",
+		},
+		{
+			name:     "replacement",
+			instance: &CloudInstance{Status: CloudInstanceStatusNeedsUpdate},
+			expected: "instance template changed",
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			if actual := needsUpdateReason(g.instance); actual != g.expected {
+				t.Errorf("expected %q, got %q", g.expected, actual)
+			}
+		})
+	}
+}
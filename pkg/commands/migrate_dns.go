@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/dns"
+)
+
+// gossipDomain is the domain suffix that implies gossip DNS
+const gossipDomain = ".k8s.local"
+
+// MigrateDNSOptions holds the options for migrating a cluster between gossip and DNS
+type MigrateDNSOptions struct {
+	// ToGossip migrates the cluster from DNS to gossip, instead of from gossip to DNS
+	ToGossip bool
+	// DNSZone is the DNS zone to migrate to
+	DNSZone string
+	// DNSName is the name under which the API records are published in the DNS zone
+	DNSName string
+}
+
+// StartDNSMigration updates the cluster to use the new API names, while keeping the previous names valid.
+// fullCluster is the populated cluster spec, from which the names in use are taken.
+func StartDNSMigration(cluster *kops.Cluster, fullCluster *kops.Cluster, options *MigrateDNSOptions) error {
+	if len(cluster.DNSMigrationPreviousNames()) != 0 {
+		return fmt.Errorf("a DNS migration is already in progress for cluster %q; finish it first", cluster.ObjectMeta.Name)
+	}
+
+	previousPublicName := fullCluster.Spec.MasterPublicName
+	previousInternalName := fullCluster.Spec.MasterInternalName
+	if previousPublicName == "" || previousInternalName == "" {
+		return fmt.Errorf("unable to determine the API names of cluster %q", cluster.ObjectMeta.Name)
+	}
+
+	var base string
+	if options.ToGossip {
+		if fullCluster.IsGossip() {
+			return fmt.Errorf("cluster %q already uses gossip DNS", cluster.ObjectMeta.Name)
+		}
+		if kops.CloudProviderID(fullCluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+			return fmt.Errorf("migrating to gossip DNS is only supported on AWS")
+		}
+		if fullCluster.Spec.API == nil || fullCluster.Spec.API.LoadBalancer == nil {
+			return fmt.Errorf("gossip DNS requires the API to be exposed through a load balancer")
+		}
+		if options.DNSZone != "" || options.DNSName != "" {
+			return fmt.Errorf("a DNS zone cannot be specified when migrating to gossip DNS")
+		}
+
+		base = cluster.ObjectMeta.Name + gossipDomain
+		// The DNS zone is kept until the migration is finished, to keep the previous names published.
+		cluster.Spec.DNSZone = fullCluster.Spec.DNSZone
+	} else {
+		if !fullCluster.IsGossip() {
+			return fmt.Errorf("cluster %q does not use gossip DNS", cluster.ObjectMeta.Name)
+		}
+		if options.DNSZone == "" {
+			return fmt.Errorf("the DNS zone to migrate to must be specified")
+		}
+
+		base = options.DNSName
+		if base == "" {
+			if !strings.Contains(options.DNSZone, ".") {
+				return fmt.Errorf("the DNS name must be specified when the DNS zone is specified by ID")
+			}
+			base = strings.TrimSuffix(cluster.ObjectMeta.Name, gossipDomain) + "." + strings.TrimSuffix(options.DNSZone, ".")
+		}
+		if dns.IsGossipHostname(base) {
+			return fmt.Errorf("DNS name %q implies gossip DNS", base)
+		}
+		if strings.Contains(options.DNSZone, ".") && !strings.HasSuffix("."+base, "."+strings.TrimSuffix(options.DNSZone, ".")) {
+			return fmt.Errorf("DNS name %q is not in DNS zone %q", base, options.DNSZone)
+		}
+
+		cluster.Spec.DNSZone = options.DNSZone
+	}
+
+	cluster.Spec.MasterPublicName = "api." + base
+	cluster.Spec.MasterInternalName = "api.internal." + base
+
+	// The API server certificate must be valid for both names until the migration is finished.
+	for _, name := range []string{previousPublicName, previousInternalName} {
+		if !containsString(cluster.Spec.AdditionalSANs, name) {
+			cluster.Spec.AdditionalSANs = append(cluster.Spec.AdditionalSANs, name)
+		}
+	}
+
+	// The default service account issuer depends on the DNS mode; keep it so that existing tokens remain valid.
+	if fullCluster.Spec.KubeAPIServer != nil && fullCluster.Spec.KubeAPIServer.ServiceAccountIssuer != nil {
+		if cluster.Spec.KubeAPIServer == nil {
+			cluster.Spec.KubeAPIServer = &kops.KubeAPIServerConfig{}
+		}
+		if cluster.Spec.KubeAPIServer.ServiceAccountIssuer == nil {
+			issuer := *fullCluster.Spec.KubeAPIServer.ServiceAccountIssuer
+			cluster.Spec.KubeAPIServer.ServiceAccountIssuer = &issuer
+		}
+	}
+
+	if cluster.ObjectMeta.Annotations == nil {
+		cluster.ObjectMeta.Annotations = make(map[string]string)
+	}
+	cluster.ObjectMeta.Annotations[kops.AnnotationNameDNSMigrationPreviousNames] = previousPublicName + "," + previousInternalName
+
+	return nil
+}
+
+// FinishDNSMigration drops the API names the cluster used before the migration.
+func FinishDNSMigration(cluster *kops.Cluster) error {
+	previousNames := cluster.DNSMigrationPreviousNames()
+	if len(previousNames) == 0 {
+		return fmt.Errorf("no DNS migration is in progress for cluster %q", cluster.ObjectMeta.Name)
+	}
+
+	var sans []string
+	for _, san := range cluster.Spec.AdditionalSANs {
+		if !containsString(previousNames, san) {
+			sans = append(sans, san)
+		}
+	}
+	cluster.Spec.AdditionalSANs = sans
+
+	if cluster.IsGossip() {
+		cluster.Spec.DNSZone = ""
+	}
+
+	delete(cluster.ObjectMeta.Annotations, kops.AnnotationNameDNSMigrationPreviousNames)
+
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
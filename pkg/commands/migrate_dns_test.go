@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestDNSMigrationFromGossip(t *testing.T) {
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "minimal.k8s.local"},
+		Spec: kops.ClusterSpec{
+			CloudProvider:  "aws",
+			AdditionalSANs: []string{"proxy.example.com"},
+		},
+	}
+	fullCluster := &kops.Cluster{
+		ObjectMeta: cluster.ObjectMeta,
+		Spec: kops.ClusterSpec{
+			CloudProvider:      "aws",
+			MasterPublicName:   "api.minimal.k8s.local",
+			MasterInternalName: "api.internal.minimal.k8s.local",
+			KubeAPIServer: &kops.KubeAPIServerConfig{
+				ServiceAccountIssuer: fi.String("https://kubernetes.default"),
+			},
+		},
+	}
+
+	if err := StartDNSMigration(cluster, fullCluster, &MigrateDNSOptions{DNSZone: "example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cluster.Spec.DNSZone != "example.com" {
+		t.Errorf("unexpected DNS zone %q", cluster.Spec.DNSZone)
+	}
+	if cluster.Spec.MasterPublicName != "api.minimal.example.com" || cluster.Spec.MasterInternalName != "api.internal.minimal.example.com" {
+		t.Errorf("unexpected API names %q, %q", cluster.Spec.MasterPublicName, cluster.Spec.MasterInternalName)
+	}
+	expectedSANs := []string{"proxy.example.com", "api.minimal.k8s.local", "api.internal.minimal.k8s.local"}
+	if !reflect.DeepEqual(cluster.Spec.AdditionalSANs, expectedSANs) {
+		t.Errorf("unexpected additional SANs %v", cluster.Spec.AdditionalSANs)
+	}
+	if fi.StringValue(cluster.Spec.KubeAPIServer.ServiceAccountIssuer) != "https://kubernetes.default" {
+		t.Errorf("service account issuer was not kept")
+	}
+	if cluster.IsGossip() || !cluster.IsMigratingFromGossip() {
+		t.Errorf("expected cluster to be migrating from gossip")
+	}
+	if cluster.DNSMigrationPreviousInternalName() != "api.internal.minimal.k8s.local" {
+		t.Errorf("unexpected previous internal name %q", cluster.DNSMigrationPreviousInternalName())
+	}
+
+	if err := StartDNSMigration(cluster, fullCluster, &MigrateDNSOptions{DNSZone: "example.com"}); err == nil || !strings.Contains(err.Error(), "already in progress") {
+		t.Errorf("expected error starting a second migration, got %v", err)
+	}
+
+	if err := FinishDNSMigration(cluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cluster.Spec.AdditionalSANs, []string{"proxy.example.com"}) {
+		t.Errorf("unexpected additional SANs after finishing %v", cluster.Spec.AdditionalSANs)
+	}
+	if cluster.Spec.DNSZone != "example.com" {
+		t.Errorf("unexpected DNS zone after finishing %q", cluster.Spec.DNSZone)
+	}
+	if cluster.IsMigratingFromGossip() || len(cluster.DNSMigrationPreviousNames()) != 0 {
+		t.Errorf("expected migration to be finished")
+	}
+
+	if err := FinishDNSMigration(cluster); err == nil {
+		t.Errorf("expected error finishing a migration that is not in progress")
+	}
+}
+
+func TestDNSMigrationToGossip(t *testing.T) {
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "minimal.example.com"},
+		Spec: kops.ClusterSpec{
+			CloudProvider: "aws",
+			API: &kops.AccessSpec{
+				LoadBalancer: &kops.LoadBalancerAccessSpec{},
+			},
+		},
+	}
+	fullCluster := &kops.Cluster{
+		ObjectMeta: cluster.ObjectMeta,
+		Spec: kops.ClusterSpec{
+			CloudProvider:      "aws",
+			DNSZone:            "example.com",
+			MasterPublicName:   "api.minimal.example.com",
+			MasterInternalName: "api.internal.minimal.example.com",
+			API:                cluster.Spec.API,
+		},
+	}
+
+	if err := StartDNSMigration(cluster, fullCluster, &MigrateDNSOptions{ToGossip: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cluster.IsGossip() || cluster.IsMigratingFromGossip() {
+		t.Errorf("expected cluster to use gossip")
+	}
+	if cluster.Spec.MasterInternalName != "api.internal.minimal.example.com.k8s.local" {
+		t.Errorf("unexpected internal API name %q", cluster.Spec.MasterInternalName)
+	}
+	if cluster.Spec.DNSZone != "example.com" {
+		t.Errorf("DNS zone should be kept during the migration, got %q", cluster.Spec.DNSZone)
+	}
+
+	if err := FinishDNSMigration(cluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Spec.DNSZone != "" {
+		t.Errorf("DNS zone should be dropped after the migration, got %q", cluster.Spec.DNSZone)
+	}
+	if len(cluster.Spec.AdditionalSANs) != 0 {
+		t.Errorf("unexpected additional SANs after finishing %v", cluster.Spec.AdditionalSANs)
+	}
+}
+
+func TestStartDNSMigrationErrors(t *testing.T) {
+	gossip := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "minimal.k8s.local"},
+		Spec: kops.ClusterSpec{
+			CloudProvider:      "gce",
+			MasterPublicName:   "api.minimal.k8s.local",
+			MasterInternalName: "api.internal.minimal.k8s.local",
+		},
+	}
+	dnsCluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "minimal.example.com"},
+		Spec: kops.ClusterSpec{
+			CloudProvider:      "aws",
+			MasterPublicName:   "api.minimal.example.com",
+			MasterInternalName: "api.internal.minimal.example.com",
+		},
+	}
+
+	grid := []struct {
+		Description string
+		Cluster     *kops.Cluster
+		Options     MigrateDNSOptions
+		Expected    string
+	}{
+		{
+			Description: "missing zone",
+			Cluster:     gossip,
+			Expected:    "must be specified",
+		},
+		{
+			Description: "zone ID without name",
+			Cluster:     gossip,
+			Options:     MigrateDNSOptions{DNSZone: "Z1234"},
+			Expected:    "DNS name must be specified",
+		},
+		{
+			Description: "name outside zone",
+			Cluster:     gossip,
+			Options:     MigrateDNSOptions{DNSZone: "example.com", DNSName: "minimal.example.org"},
+			Expected:    "not in DNS zone",
+		},
+		{
+			Description: "already using DNS",
+			Cluster:     dnsCluster,
+			Options:     MigrateDNSOptions{DNSZone: "example.com"},
+			Expected:    "does not use gossip",
+		},
+		{
+			Description: "already using gossip",
+			Cluster:     gossip,
+			Options:     MigrateDNSOptions{ToGossip: true},
+			Expected:    "already uses gossip",
+		},
+		{
+			Description: "gossip without load balancer",
+			Cluster:     dnsCluster,
+			Options:     MigrateDNSOptions{ToGossip: true},
+			Expected:    "load balancer",
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			cluster := g.Cluster.DeepCopy()
+			err := StartDNSMigration(cluster, g.Cluster, &g.Options)
+			if err == nil || !strings.Contains(err.Error(), g.Expected) {
+				t.Errorf("expected error containing %q, got %v", g.Expected, err)
+			}
+		})
+	}
+}
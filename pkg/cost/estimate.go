@@ -0,0 +1,485 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gcetasks"
+)
+
+const (
+	KindInstances    = "instances"
+	KindRootVolumes  = "root-volumes"
+	KindWarmPool     = "warm-pool"
+	KindVolume       = "volume"
+	KindNATGateway   = "nat-gateway"
+	KindLoadBalancer = "load-balancer"
+)
+
+// Range is a monthly cost, from the cost at the minimum size to the cost at the maximum size of a resource
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Cost is the monthly cost of a resource in one state of the cluster
+type Cost struct {
+	// Description summarizes what was priced, for example the machine type and number of instances
+	Description string `json:"description"`
+	Range
+}
+
+// Item is a priced cloud resource
+type Item struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Current is the cost of the resource as it exists in the cloud, nil if it doesn't exist yet
+	Current *Cost `json:"current,omitempty"`
+	// Proposed is the cost of the resource once the cluster is updated
+	Proposed *Cost `json:"proposed,omitempty"`
+}
+
+// Estimate is the monthly cost of a cluster, before and after an update
+type Estimate struct {
+	Currency string  `json:"currency,omitempty"`
+	Items    []*Item `json:"items"`
+	Current  Range   `json:"current"`
+	Proposed Range   `json:"proposed"`
+	// Warnings lists the resources which could not be priced
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// state is the set of tasks describing the cluster, either as it exists or as it is proposed
+type state struct {
+	tasks []fi.Task
+	// byExpected maps the expected tasks to the tasks of this state, nil if the task doesn't exist in this state
+	byExpected map[fi.Task]fi.Task
+}
+
+func (s *state) add(e fi.Task, t fi.Task) {
+	s.byExpected[e] = t
+	if t != nil {
+		s.tasks = append(s.tasks, t)
+	}
+}
+
+// Tasks found in the cloud only reference other tasks by name or id,
+// so references are resolved by pointer first and then by name or id.
+
+func (s *state) launchTemplate(ref *awstasks.LaunchTemplate) *awstasks.LaunchTemplate {
+	if ref == nil {
+		return nil
+	}
+	if t, found := s.byExpected[ref]; found {
+		lt, _ := t.(*awstasks.LaunchTemplate)
+		return lt
+	}
+	for _, t := range s.tasks {
+		if lt, ok := t.(*awstasks.LaunchTemplate); ok && fi.StringValue(lt.Name) == fi.StringValue(ref.Name) {
+			return lt
+		}
+	}
+	return nil
+}
+
+func (s *state) autoscalingGroup(name string) *awstasks.AutoscalingGroup {
+	for _, t := range s.tasks {
+		if asg, ok := t.(*awstasks.AutoscalingGroup); ok && fi.StringValue(asg.Name) == name {
+			return asg
+		}
+	}
+	return nil
+}
+
+func (s *state) instanceTemplate(ref *gcetasks.InstanceTemplate) *gcetasks.InstanceTemplate {
+	if ref == nil {
+		return nil
+	}
+	if t, found := s.byExpected[ref]; found {
+		it, _ := t.(*gcetasks.InstanceTemplate)
+		return it
+	}
+	for _, t := range s.tasks {
+		it, ok := t.(*gcetasks.InstanceTemplate)
+		if !ok {
+			continue
+		}
+		if ref.ID != nil && fi.StringValue(it.ID) == fi.StringValue(ref.ID) {
+			return it
+		}
+		if ref.Name != nil && fi.StringValue(it.Name) == fi.StringValue(ref.Name) {
+			return it
+		}
+	}
+	return nil
+}
+
+// pricedItem is the cost of a resource in one state
+type pricedItem struct {
+	kind string
+	name string
+	cost *Cost
+}
+
+type estimator struct {
+	prices   *PriceTable
+	warnings sets.String
+}
+
+// EstimateCost prices the tasks of a dry run of the cluster update.
+// actual maps the expected state of the tasks which would be created or updated to their actual state,
+// with a nil actual state for the tasks which would be created; the other tasks are unchanged.
+func EstimateCost(prices *PriceTable, taskMap map[string]fi.Task, actual map[fi.Task]fi.Task) *Estimate {
+	current := &state{byExpected: make(map[fi.Task]fi.Task)}
+	proposed := &state{byExpected: make(map[fi.Task]fi.Task)}
+
+	var keys []string
+	for k, e := range taskMap {
+		keys = append(keys, k)
+		proposed.add(e, e)
+		if a, changed := actual[e]; changed {
+			current.add(e, a)
+		} else {
+			current.add(e, e)
+		}
+	}
+	sort.Strings(keys)
+
+	est := &estimator{
+		prices:   prices,
+		warnings: sets.NewString(),
+	}
+
+	items := make(map[string]*Item)
+	item := func(p *pricedItem) *Item {
+		k := p.kind + "/" + p.name
+		if items[k] == nil {
+			items[k] = &Item{Kind: p.kind, Name: p.name}
+		}
+		return items[k]
+	}
+
+	for _, k := range keys {
+		e := taskMap[k]
+		for _, p := range est.price(proposed, e) {
+			item(p).Proposed = p.cost
+		}
+		if a := current.byExpected[e]; a != nil {
+			for _, p := range est.price(current, a) {
+				item(p).Current = p.cost
+			}
+		}
+	}
+
+	estimate := &Estimate{
+		Currency: prices.Currency,
+		Warnings: est.warnings.List(),
+	}
+	for _, i := range items {
+		estimate.Items = append(estimate.Items, i)
+		if i.Current != nil {
+			estimate.Current.Min += i.Current.Min
+			estimate.Current.Max += i.Current.Max
+		}
+		if i.Proposed != nil {
+			estimate.Proposed.Min += i.Proposed.Min
+			estimate.Proposed.Max += i.Proposed.Max
+		}
+	}
+	sort.Slice(estimate.Items, func(i, j int) bool {
+		if estimate.Items[i].Kind != estimate.Items[j].Kind {
+			return estimate.Items[i].Kind < estimate.Items[j].Kind
+		}
+		return estimate.Items[i].Name < estimate.Items[j].Name
+	})
+
+	return estimate
+}
+
+// price returns the cost of the resources created by the task, resolving its references in the state
+func (e *estimator) price(s *state, task fi.Task) []*pricedItem {
+	switch t := task.(type) {
+	case *awstasks.AutoscalingGroup:
+		return e.priceAutoscalingGroup(s, t)
+	case *awstasks.WarmPool:
+		return e.priceWarmPool(s, t)
+	case *awstasks.EBSVolume:
+		return e.priceVolume(fi.StringValue(t.Name), fi.Int64Value(t.SizeGB), fi.StringValue(t.VolumeType))
+	case *awstasks.NatGateway:
+		if fi.BoolValue(t.Shared) {
+			return nil
+		}
+		return e.priceNATGateway(fi.StringValue(t.Name))
+	case *awstasks.ClassicLoadBalancer:
+		if fi.BoolValue(t.Shared) {
+			return nil
+		}
+		return e.priceLoadBalancer(fi.StringValue(t.Name), "classic")
+	case *awstasks.NetworkLoadBalancer:
+		lbType := fi.StringValue(t.Type)
+		if lbType == "" {
+			lbType = "network"
+		}
+		return e.priceLoadBalancer(fi.StringValue(t.Name), lbType)
+
+	case *gcetasks.InstanceGroupManager:
+		return e.priceInstanceGroupManager(s, t)
+	case *gcetasks.Disk:
+		return e.priceVolume(fi.StringValue(t.Name), fi.Int64Value(t.SizeGB), gce.LastComponent(fi.StringValue(t.VolumeType)))
+	case *gcetasks.ForwardingRule:
+		return e.priceLoadBalancer(fi.StringValue(t.Name), "forwarding-rule")
+	case *gcetasks.Router:
+		if t.NATIPAllocationOption == nil {
+			return nil
+		}
+		return e.priceNATGateway(fi.StringValue(t.Name))
+	}
+	return nil
+}
+
+func (e *estimator) priceAutoscalingGroup(s *state, asg *awstasks.AutoscalingGroup) []*pricedItem {
+	name := fi.StringValue(asg.Name)
+	lt := s.launchTemplate(asg.LaunchTemplate)
+	if lt == nil {
+		e.warnings.Insert(fmt.Sprintf("launch template of autoscaling group %q not found", name))
+		return nil
+	}
+
+	instanceTypes := asg.MixedInstanceOverrides
+	if len(instanceTypes) == 0 {
+		instanceTypes = []string{fi.StringValue(lt.InstanceType)}
+	}
+
+	minSize := fi.Int64Value(asg.MinSize)
+	maxSize := fi.Int64Value(asg.MaxSize)
+
+	// The on-demand instances use the first instance type, the spot instances the cheapest one
+	onDemandPrice := e.instancePrice(instanceTypes[0])
+	spotPrice := onDemandPrice
+	for _, instanceType := range instanceTypes[1:] {
+		if p := e.instancePrice(instanceType); p < spotPrice {
+			spotPrice = p
+		}
+	}
+	spotPrice *= 1 - e.prices.SpotDiscount
+
+	onDemandCount := func(n int64) float64 {
+		if len(asg.MixedInstanceOverrides) == 0 {
+			if fi.StringValue(lt.SpotPrice) != "" {
+				return 0
+			}
+			return float64(n)
+		}
+		base := fi.Int64Value(asg.MixedOnDemandBase)
+		if n <= base {
+			return float64(n)
+		}
+		aboveBase := int64(100)
+		if asg.MixedOnDemandAboveBase != nil {
+			aboveBase = *asg.MixedOnDemandAboveBase
+		}
+		return float64(base) + float64(n-base)*float64(aboveBase)/100
+	}
+	instancesCost := func(n int64) float64 {
+		onDemand := onDemandCount(n)
+		return e.prices.monthly(onDemand*onDemandPrice + (float64(n)-onDemand)*spotPrice)
+	}
+
+	description := fmt.Sprintf("%s x %s", sizeRange(minSize, maxSize), strings.Join(instanceTypes, ","))
+	if maxSize > 0 {
+		if spotShare := 1 - onDemandCount(maxSize)/float64(maxSize); spotShare > 0 {
+			description += fmt.Sprintf(", %d%% spot", int(spotShare*100+0.5))
+		}
+	}
+
+	items := []*pricedItem{
+		{
+			kind: KindInstances,
+			name: name,
+			cost: &Cost{
+				Description: description,
+				Range:       Range{Min: instancesCost(minSize), Max: instancesCost(maxSize)},
+			},
+		},
+	}
+	if rootVolume := e.rootVolumeCost(KindRootVolumes, name, minSize, maxSize, fi.Int64Value(lt.RootVolumeSize), fi.StringValue(lt.RootVolumeType)); rootVolume != nil {
+		items = append(items, rootVolume)
+	}
+	return items
+}
+
+// priceWarmPool prices the instances of the warm pool, which are stopped and only cost their volumes
+func (e *estimator) priceWarmPool(s *state, warmPool *awstasks.WarmPool) []*pricedItem {
+	if !fi.BoolValue(warmPool.Enabled) {
+		return nil
+	}
+	name := fi.StringValue(warmPool.Name)
+	asg := s.autoscalingGroup(name)
+	if asg == nil {
+		e.warnings.Insert(fmt.Sprintf("autoscaling group of warm pool %q not found", name))
+		return nil
+	}
+	lt := s.launchTemplate(asg.LaunchTemplate)
+	if lt == nil {
+		e.warnings.Insert(fmt.Sprintf("launch template of autoscaling group %q not found", name))
+		return nil
+	}
+
+	maxPrepared := fi.Int64Value(asg.MaxSize)
+	if warmPool.MaxSize != nil && *warmPool.MaxSize >= 0 {
+		maxPrepared = *warmPool.MaxSize
+	}
+	minSize := warmPool.MinSize
+	maxSize := maxPrepared - fi.Int64Value(asg.MinSize)
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+
+	return []*pricedItem{e.rootVolumeCost(KindWarmPool, name, minSize, maxSize, fi.Int64Value(lt.RootVolumeSize), fi.StringValue(lt.RootVolumeType))}
+}
+
+func (e *estimator) priceInstanceGroupManager(s *state, igm *gcetasks.InstanceGroupManager) []*pricedItem {
+	name := fi.StringValue(igm.Name)
+	it := s.instanceTemplate(igm.InstanceTemplate)
+	if it == nil {
+		e.warnings.Insert(fmt.Sprintf("instance template of instance group manager %q not found", name))
+		return nil
+	}
+
+	size := fi.Int64Value(igm.TargetSize)
+	machineType := gce.LastComponent(fi.StringValue(it.MachineType))
+	price := e.instancePrice(machineType)
+	description := fmt.Sprintf("%d x %s", size, machineType)
+	if fi.BoolValue(it.Preemptible) {
+		price *= 1 - e.prices.SpotDiscount
+		description += ", preemptible"
+	}
+	cost := e.prices.monthly(float64(size) * price)
+
+	items := []*pricedItem{
+		{
+			kind: KindInstances,
+			name: name,
+			cost: &Cost{
+				Description: description,
+				Range:       Range{Min: cost, Max: cost},
+			},
+		},
+	}
+	if rootVolume := e.rootVolumeCost(KindRootVolumes, name, size, size, fi.Int64Value(it.BootDiskSizeGB), gce.LastComponent(fi.StringValue(it.BootDiskType))); rootVolume != nil {
+		items = append(items, rootVolume)
+	}
+	return items
+}
+
+// rootVolumeCost prices the volumes of a group of instances, it returns nil if the size of the volume is not known
+func (e *estimator) rootVolumeCost(kind string, name string, minSize, maxSize int64, sizeGB int64, volumeType string) *pricedItem {
+	if sizeGB == 0 {
+		return nil
+	}
+	if volumeType == "" {
+		volumeType = e.prices.DefaultVolumeType
+	}
+	price := float64(sizeGB) * e.volumePrice(volumeType)
+	return &pricedItem{
+		kind: kind,
+		name: name,
+		cost: &Cost{
+			Description: fmt.Sprintf("%s x %dGB %s", sizeRange(minSize, maxSize), sizeGB, volumeType),
+			Range:       Range{Min: float64(minSize) * price, Max: float64(maxSize) * price},
+		},
+	}
+}
+
+func (e *estimator) priceVolume(name string, sizeGB int64, volumeType string) []*pricedItem {
+	if volumeType == "" {
+		volumeType = e.prices.DefaultVolumeType
+	}
+	cost := float64(sizeGB) * e.volumePrice(volumeType)
+	return []*pricedItem{
+		{
+			kind: KindVolume,
+			name: name,
+			cost: &Cost{
+				Description: fmt.Sprintf("%dGB %s", sizeGB, volumeType),
+				Range:       Range{Min: cost, Max: cost},
+			},
+		},
+	}
+}
+
+func (e *estimator) priceNATGateway(name string) []*pricedItem {
+	cost := e.prices.monthly(e.prices.NATGateway)
+	return []*pricedItem{
+		{
+			kind: KindNATGateway,
+			name: name,
+			cost: &Cost{
+				Description: "NAT gateway",
+				Range:       Range{Min: cost, Max: cost},
+			},
+		},
+	}
+}
+
+func (e *estimator) priceLoadBalancer(name string, lbType string) []*pricedItem {
+	price, found := e.prices.LoadBalancers[lbType]
+	if !found {
+		e.warnings.Insert(fmt.Sprintf("no price for load balancer type %q", lbType))
+	}
+	cost := e.prices.monthly(price)
+	return []*pricedItem{
+		{
+			kind: KindLoadBalancer,
+			name: name,
+			cost: &Cost{
+				Description: lbType,
+				Range:       Range{Min: cost, Max: cost},
+			},
+		},
+	}
+}
+
+func (e *estimator) instancePrice(instanceType string) float64 {
+	price, found := e.prices.Instances[instanceType]
+	if !found {
+		e.warnings.Insert(fmt.Sprintf("no price for instance type %q", instanceType))
+	}
+	return price
+}
+
+func (e *estimator) volumePrice(volumeType string) float64 {
+	price, found := e.prices.Volumes[volumeType]
+	if !found {
+		e.warnings.Insert(fmt.Sprintf("no price for volume type %q", volumeType))
+	}
+	return price
+}
+
+func sizeRange(minSize, maxSize int64) string {
+	if minSize == maxSize {
+		return fmt.Sprintf("%d", minSize)
+	}
+	return fmt.Sprintf("%d-%d", minSize, maxSize)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gcetasks"
+)
+
+func testPriceTable() *PriceTable {
+	return &PriceTable{
+		Cloud:             "aws",
+		Currency:          "USD",
+		HoursPerMonth:     100,
+		SpotDiscount:      0.5,
+		DefaultVolumeType: "gp3",
+		Instances: map[string]float64{
+			"t3.medium": 0.04,
+			"t3.large":  0.08,
+			"m5.large":  0.06,
+		},
+		Volumes: map[string]float64{
+			"gp3": 0.1,
+		},
+		LoadBalancers: map[string]float64{
+			"network": 0.02,
+		},
+		NATGateway: 0.05,
+	}
+}
+
+// summarize renders the costs of the estimate, rounded to the cent
+func summarize(estimate *Estimate) map[string]string {
+	format := func(c *Cost) string {
+		if c == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%s: %.2f-%.2f", c.Description, c.Min, c.Max)
+	}
+	summary := make(map[string]string)
+	for _, i := range estimate.Items {
+		summary[i.Kind+"/"+i.Name] = format(i.Current) + " => " + format(i.Proposed)
+	}
+	return summary
+}
+
+func TestEstimateCostAWS(t *testing.T) {
+	oldTemplate := &awstasks.LaunchTemplate{
+		Name:           fi.String("nodes"),
+		InstanceType:   fi.String("t3.medium"),
+		RootVolumeSize: fi.Int64(10),
+	}
+	newTemplate := &awstasks.LaunchTemplate{
+		Name:           fi.String("nodes"),
+		InstanceType:   fi.String("t3.large"),
+		RootVolumeSize: fi.Int64(10),
+		RootVolumeType: fi.String("gp3"),
+	}
+	// Tasks found in the cloud reference each other by name
+	oldASG := &awstasks.AutoscalingGroup{
+		Name:           fi.String("nodes"),
+		MinSize:        fi.Int64(1),
+		MaxSize:        fi.Int64(3),
+		LaunchTemplate: &awstasks.LaunchTemplate{Name: fi.String("nodes")},
+	}
+	newASG := &awstasks.AutoscalingGroup{
+		Name:                   fi.String("nodes"),
+		MinSize:                fi.Int64(2),
+		MaxSize:                fi.Int64(4),
+		LaunchTemplate:         newTemplate,
+		MixedInstanceOverrides: []string{"t3.large", "m5.large"},
+		MixedOnDemandBase:      fi.Int64(1),
+		MixedOnDemandAboveBase: fi.Int64(0),
+	}
+	warmPool := &awstasks.WarmPool{
+		Name:    fi.String("nodes"),
+		Enabled: fi.Bool(true),
+		MinSize: 1,
+	}
+	etcdVolume := &awstasks.EBSVolume{
+		Name:   fi.String("a.etcd-main"),
+		SizeGB: fi.Int64(20),
+	}
+	natGateway := &awstasks.NatGateway{
+		Name: fi.String("us-test-1a"),
+	}
+	sharedNATGateway := &awstasks.NatGateway{
+		Name:   fi.String("us-test-1b"),
+		Shared: fi.Bool(true),
+	}
+	loadBalancer := &awstasks.NetworkLoadBalancer{
+		Name: fi.String("api"),
+	}
+
+	taskMap := map[string]fi.Task{
+		"LaunchTemplate/nodes":        newTemplate,
+		"AutoscalingGroup/nodes":      newASG,
+		"WarmPool/nodes":              warmPool,
+		"EBSVolume/a.etcd-main":       etcdVolume,
+		"NatGateway/us-test-1a":       natGateway,
+		"NatGateway/us-test-1b":       sharedNATGateway,
+		"NetworkLoadBalancer/api":     loadBalancer,
+		"SSHKey/admin":                &awstasks.SSHKey{Name: fi.String("admin")},
+		"LaunchTemplate/unreferenced": &awstasks.LaunchTemplate{Name: fi.String("unreferenced")},
+	}
+	actual := map[fi.Task]fi.Task{
+		newTemplate:  oldTemplate,
+		newASG:       oldASG,
+		warmPool:     nil,
+		loadBalancer: nil,
+	}
+
+	estimate := EstimateCost(testPriceTable(), taskMap, actual)
+
+	expected := map[string]string{
+		// 2 instances: 1 on-demand t3.large (8) + 1 spot m5.large (3); 4 instances: 8 + 3*3
+		"instances/nodes":        "1-3 x t3.medium: 4.00-12.00 => 2-4 x t3.large,m5.large, 75% spot: 11.00-17.00",
+		"root-volumes/nodes":     "1-3 x 10GB gp3: 1.00-3.00 => 2-4 x 10GB gp3: 2.00-4.00",
+		"warm-pool/nodes":        "- => 1-2 x 10GB gp3: 1.00-2.00",
+		"volume/a.etcd-main":     "20GB gp3: 2.00-2.00 => 20GB gp3: 2.00-2.00",
+		"nat-gateway/us-test-1a": "NAT gateway: 5.00-5.00 => NAT gateway: 5.00-5.00",
+		"load-balancer/api":      "- => network: 2.00-2.00",
+	}
+	if actual := summarize(estimate); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected estimate\nexpected: %v\nactual:   %v", expected, actual)
+	}
+
+	if math.Abs(estimate.Current.Min-12) > 0.001 || math.Abs(estimate.Current.Max-22) > 0.001 {
+		t.Errorf("unexpected current total %v", estimate.Current)
+	}
+	if math.Abs(estimate.Proposed.Min-23) > 0.001 || math.Abs(estimate.Proposed.Max-32) > 0.001 {
+		t.Errorf("unexpected proposed total %v", estimate.Proposed)
+	}
+	if len(estimate.Warnings) != 0 {
+		t.Errorf("unexpected warnings %v", estimate.Warnings)
+	}
+}
+
+func TestEstimateCostGCE(t *testing.T) {
+	prices, err := DefaultPriceTable(kops.CloudProviderGCE)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	prices.HoursPerMonth = 100
+
+	template := &gcetasks.InstanceTemplate{
+		Name:           fi.String("nodes-1234"),
+		MachineType:    fi.String("n1-standard-2"),
+		Preemptible:    fi.Bool(true),
+		BootDiskSizeGB: fi.Int64(100),
+	}
+	taskMap := map[string]fi.Task{
+		"InstanceTemplate/nodes": template,
+		"InstanceGroupManager/a-nodes": &gcetasks.InstanceGroupManager{
+			Name:             fi.String("a-nodes"),
+			TargetSize:       fi.Int64(2),
+			InstanceTemplate: template,
+		},
+		"InstanceGroupManager/b-nodes": &gcetasks.InstanceGroupManager{
+			Name:             fi.String("b-nodes"),
+			TargetSize:       fi.Int64(1),
+			InstanceTemplate: &gcetasks.InstanceTemplate{Name: fi.String("custom"), MachineType: fi.String("custom-4-8192")},
+		},
+	}
+
+	estimate := EstimateCost(prices, taskMap, nil)
+
+	// Nothing changes, so the current and proposed costs are the same
+	expected := map[string]string{
+		"instances/a-nodes":    "2 x n1-standard-2, preemptible: 5.70-5.70 => 2 x n1-standard-2, preemptible: 5.70-5.70",
+		"root-volumes/a-nodes": "2 x 100GB pd-standard: 8.00-8.00 => 2 x 100GB pd-standard: 8.00-8.00",
+	}
+	if actual := summarize(estimate); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected estimate\nexpected: %v\nactual:   %v", expected, actual)
+	}
+	if !reflect.DeepEqual(estimate.Warnings, []string{`instance template of instance group manager "b-nodes" not found`}) {
+		t.Errorf("unexpected warnings %v", estimate.Warnings)
+	}
+}
+
+func TestParsePriceTable(t *testing.T) {
+	for _, cloud := range []kops.CloudProviderID{kops.CloudProviderAWS, kops.CloudProviderGCE} {
+		prices, err := DefaultPriceTable(cloud)
+		if err != nil {
+			t.Fatalf("unexpected error reading the %s price table: %v", cloud, err)
+		}
+		if prices.Cloud != string(cloud) || len(prices.Instances) == 0 || prices.Volumes[prices.DefaultVolumeType] == 0 {
+			t.Errorf("unexpected %s price table: %v", cloud, prices)
+		}
+	}
+
+	if _, err := ParsePriceTable([]byte("cloud: aws\ninstance:\n  t3.medium: 0.04\n")); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+	if _, err := ParsePriceTable([]byte("cloud: aws\nspotDiscount: 1.5\n")); err == nil {
+		t.Errorf("expected an error for an invalid spot discount")
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"embed"
+	"fmt"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultHoursPerMonth is used when the price table doesn't specify the number of hours in a month
+const DefaultHoursPerMonth = 730
+
+//go:embed tables
+var tables embed.FS
+
+// PriceTable holds the prices used to estimate the cost of the cloud resources of a cluster.
+// The prices are read from a file, so that estimates don't need access to a pricing API.
+type PriceTable struct {
+	// Cloud is the cloud provider the prices apply to
+	Cloud string `json:"cloud"`
+	// Region is the region the prices apply to
+	Region string `json:"region,omitempty"`
+	// Currency is the currency of the prices
+	Currency string `json:"currency,omitempty"`
+	// HoursPerMonth is used to convert the hourly prices to monthly prices
+	HoursPerMonth float64 `json:"hoursPerMonth,omitempty"`
+	// SpotDiscount is the fraction of the on-demand price saved by spot or preemptible instances
+	SpotDiscount float64 `json:"spotDiscount,omitempty"`
+	// DefaultVolumeType is the volume type used for volumes which don't specify one
+	DefaultVolumeType string `json:"defaultVolumeType,omitempty"`
+	// Instances are the hourly on-demand prices, by machine type
+	Instances map[string]float64 `json:"instances,omitempty"`
+	// Volumes are the monthly prices of a GB of storage, by volume type
+	Volumes map[string]float64 `json:"volumes,omitempty"`
+	// LoadBalancers are the hourly prices, by load balancer type
+	LoadBalancers map[string]float64 `json:"loadBalancers,omitempty"`
+	// NATGateway is the hourly price of a NAT gateway
+	NATGateway float64 `json:"natGateway,omitempty"`
+}
+
+// ParsePriceTable parses a price table from its YAML or JSON representation
+func ParsePriceTable(data []byte) (*PriceTable, error) {
+	prices := &PriceTable{}
+	if err := yaml.UnmarshalStrict(data, prices); err != nil {
+		return nil, fmt.Errorf("error parsing price table: %v", err)
+	}
+	if prices.Cloud == "" {
+		return nil, fmt.Errorf("price table does not specify the cloud")
+	}
+	if prices.HoursPerMonth == 0 {
+		prices.HoursPerMonth = DefaultHoursPerMonth
+	}
+	if prices.SpotDiscount < 0 || prices.SpotDiscount >= 1 {
+		return nil, fmt.Errorf("spotDiscount must be at least 0 and less than 1, was %v", prices.SpotDiscount)
+	}
+	return prices, nil
+}
+
+// LoadPriceTable reads a price table from a file, which can be any vfs path
+func LoadPriceTable(location string) (*PriceTable, error) {
+	data, err := vfs.Context.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("error reading price table %q: %v", location, err)
+	}
+	prices, err := ParsePriceTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading price table %q: %v", location, err)
+	}
+	return prices, nil
+}
+
+// DefaultPriceTable returns the price table shipped with kOps for the cloud provider
+func DefaultPriceTable(cloud kops.CloudProviderID) (*PriceTable, error) {
+	data, err := tables.ReadFile("tables/" + string(cloud) + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("no price table for cloud provider %q, use --price-table to specify one", cloud)
+	}
+	return ParsePriceTable(data)
+}
+
+// monthly converts an hourly price to a monthly price
+func (p *PriceTable) monthly(hourly float64) float64 {
+	return hourly * p.HoursPerMonth
+}
@@ -97,7 +97,7 @@ var (
 	APIServerNodes = new("APIServerNodes", Bool(false))
 	// UseAddonOperators activates experimental addon operator support
 	UseAddonOperators = new("UseAddonOperators", Bool(false))
-	// AWSIPv6 activates experimental AWS IPv6 support.
+	// AWSIPv6 activates experimental support for AWS clusters whose pods and services use IPv6. Dual-stack clusters don't need it.
 	AWSIPv6 = new("AWSIPv6", Bool(false))
 	// TerraformManagedFiles enables rendering managed files into the Terraform configuration.
 	TerraformManagedFiles = new("TerraformManagedFiles", Bool(true))
@@ -105,6 +105,8 @@ var (
 	AlphaAllowGCE = new("AlphaAllowGCE", Bool(false))
 	// AlphaAllowALI is a feature flag that gates aliyun support while it is alpha.
 	AlphaAllowALI = new("AlphaAllowALI", Bool(false))
+	// Metal toggles the support for pre-provisioned hosts configured over SSH.
+	Metal = new("Metal", Bool(false))
 )
 
 // FeatureFlag defines a feature flag
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kubectl/pkg/drain"
+)
+
+// partitionInPlaceUpdates splits the instances in those that can be updated in place and those that must be replaced
+func partitionInPlaceUpdates(update []*cloudinstances.CloudInstance) (inPlace []*cloudinstances.CloudInstance, replace []*cloudinstances.CloudInstance) {
+	for _, u := range update {
+		if u.InPlaceUpdate && u.Node != nil && u.Status == cloudinstances.CloudInstanceStatusNeedsUpdate && !hasNeedsUpdateAnnotation(u.Node) {
+			inPlace = append(inPlace, u)
+		} else {
+			replace = append(replace, u)
+		}
+	}
+	return inPlace, replace
+}
+
+// hasNeedsUpdateAnnotation returns true if the node was explicitly marked for replacement
+func hasNeedsUpdateAnnotation(node *corev1.Node) bool {
+	_, found := node.Annotations["kops.k8s.io/needs-update"]
+	return found
+}
+
+// updateInstancesInPlace applies the new nodeup config to the instances one at a time, without replacing them.
+// Each node is cordoned while the nodeup agent running on it applies the new config.
+func (c *RollingUpdateCluster) updateInstancesInPlace(group *cloudinstances.CloudInstanceGroup, update []*cloudinstances.CloudInstance) error {
+	if group.NodeupConfigHash == "" {
+		return fmt.Errorf("nodeup config hash not known for instance group %q", group.InstanceGroup.Name)
+	}
+
+	for _, u := range update {
+		if err := c.updateInstanceInPlace(u, group.NodeupConfigHash); err != nil {
+			return err
+		}
+
+		if err := c.maybeValidate(" after updating instance in place", c.ValidateCount, group); err != nil {
+			return err
+		}
+
+		if c.Interactive {
+			stopPrompting, err := promptInteractive(u.ID, u.Node.Name)
+			if err != nil {
+				return err
+			}
+			if stopPrompting {
+				c.Interactive = false
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *RollingUpdateCluster) updateInstanceInPlace(u *cloudinstances.CloudInstance, hash string) error {
+	nodeName := u.Node.Name
+	klog.Infof("Updating instance %q, node %q in place.", u.ID, nodeName)
+
+	helper := &drain.Helper{
+		Ctx:    c.Ctx,
+		Client: c.K8sClient,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	if err := drain.RunCordonOrUncordon(helper, u.Node, true); err != nil {
+		return fmt.Errorf("error cordoning node %q: %v", nodeName, err)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				nodeup.AnnotationRequestedConfigHash: hash,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.K8sClient.CoreV1().Nodes().Patch(c.Ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error requesting in-place update of node %q: %v", nodeName, err)
+	}
+
+	node, err := c.waitForInPlaceUpdate(nodeName, hash)
+	if err != nil {
+		return err
+	}
+
+	if err := c.uncordonAndRemoveTaint(node); err != nil {
+		return fmt.Errorf("error uncordoning node %q: %v", nodeName, err)
+	}
+
+	klog.Infof("Node %q was updated in place.", nodeName)
+	return nil
+}
+
+// waitForInPlaceUpdate waits until the nodeup agent reports that the requested config was applied to the node
+func (c *RollingUpdateCluster) waitForInPlaceUpdate(nodeName string, hash string) (*corev1.Node, error) {
+	ctx, cancel := context.WithTimeout(c.Ctx, c.ValidationTimeout)
+	defer cancel()
+
+	for {
+		node, err := c.K8sClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			klog.Warningf("error getting node %q: %v", nodeName, err)
+		} else if node.Annotations[nodeup.AnnotationAppliedConfigHash] == hash {
+			return node, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("node %q was not updated in place within %s, check that the nodeup agent is running on it", nodeName, c.ValidationTimeout)
+		case <-time.After(c.ValidateTickDuration):
+		}
+	}
+}
+
+func (c *RollingUpdateCluster) uncordonAndRemoveTaint(node *corev1.Node) error {
+	oldData, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	node.Spec.Unschedulable = false
+	var taints []corev1.Taint
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != rollingUpdateTaintKey {
+			taints = append(taints, taint)
+		}
+	}
+	node.Spec.Taints = taints
+
+	newData, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, node)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.K8sClient.CoreV1().Nodes().Patch(c.Ctx, node.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
+	return err
+}
@@ -26,7 +26,10 @@ import (
 
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -106,6 +109,19 @@ func (c *RollingUpdateCluster) rollingUpdateInstanceGroup(group *cloudinstances.
 
 	settings := resolveSettings(c.Cluster, group.InstanceGroup, numInstances)
 
+	if *settings.InPlaceUpdates && !c.CloudOnly {
+		var inPlace []*cloudinstances.CloudInstance
+		inPlace, update = partitionInPlaceUpdates(update)
+		if len(inPlace) > 0 {
+			if err := c.updateInstancesInPlace(group, inPlace); err != nil {
+				return err
+			}
+		}
+		if len(update) == 0 {
+			return nil
+		}
+	}
+
 	runningDrains := 0
 	maxSurge := settings.MaxSurge.IntValue()
 	if maxSurge > len(update) {
@@ -124,6 +140,16 @@ func (c *RollingUpdateCluster) rollingUpdateInstanceGroup(group *cloudinstances.
 		}
 	}
 
+	if group.InstanceGroup.IsProvisioned() && maxSurge != 0 {
+		// Provisioned instances are not in a group that could launch surge instances. Once a node is drained,
+		// the provisioner launches the replacement from the current launch template for the evicted pods.
+		maxSurge = 0
+		maxConcurrency = settings.MaxUnavailable.IntValue()
+		if maxConcurrency == 0 {
+			maxConcurrency = 1
+		}
+	}
+
 	nonWarmPool := []*cloudinstances.CloudInstance{}
 	// Run through the warm pool and delete all instances directly
 	for _, instance := range update {
@@ -386,6 +412,14 @@ func (c *RollingUpdateCluster) drainTerminateAndWait(u *cloudinstances.CloudInst
 
 	isBastion := u.CloudInstanceGroup.InstanceGroup.IsBastion()
 
+	// Stop load balancers sending new traffic to the instance before its pods are evicted
+	if err := c.deregisterInstance(u); err != nil {
+		if c.FailOnDrainError {
+			return fmt.Errorf("failed to deregister instance %q from load balancers: %v", instanceID, err)
+		}
+		klog.Infof("Ignoring error deregistering instance %q from load balancers: %v", instanceID, err)
+	}
+
 	if isBastion {
 		// We don't want to validate for bastions - they aren't part of the cluster
 	} else if c.CloudOnly {
@@ -580,6 +614,58 @@ func (c *RollingUpdateCluster) detachInstance(u *cloudinstances.CloudInstance) e
 	return nil
 }
 
+// deregisterInstance deregisters an instance from the load balancer target groups of its instance group,
+// waiting for in-flight requests to drain. Target groups attached to the autoscaling group by other means,
+// such as those of the API load balancer, are left alone.
+func (c *RollingUpdateCluster) deregisterInstance(u *cloudinstances.CloudInstance) error {
+	if c.CloudOnly {
+		return nil
+	}
+	awsCloud, ok := c.Cloud.(awsup.AWSCloud)
+	if !ok {
+		return nil
+	}
+	asg, ok := u.CloudInstanceGroup.Raw.(*autoscaling.Group)
+	if !ok {
+		return nil
+	}
+
+	targetGroupARNs := instanceGroupTargetGroupARNs(c.ClusterName, u.CloudInstanceGroup.InstanceGroup, aws.StringValueSlice(asg.TargetGroupARNs))
+	if len(targetGroupARNs) == 0 {
+		return nil
+	}
+
+	klog.Infof("Deregistering instance %q from %d target group(s).", u.ID, len(targetGroupARNs))
+	return awsup.DeregisterInstanceFromTargetGroups(awsCloud, u.ID, targetGroupARNs)
+}
+
+// instanceGroupTargetGroupARNs returns the target groups of the autoscaling group
+// that are configured in the external load balancers of the instance group
+func instanceGroupTargetGroupARNs(clusterName string, ig *api.InstanceGroup, asgTargetGroupARNs []string) []string {
+	if ig == nil {
+		return nil
+	}
+
+	arns := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, lb := range ig.Spec.ExternalLoadBalancers {
+		if lb.TargetGroupARN != nil {
+			arns[*lb.TargetGroupARN] = true
+		}
+		if lb.TargetGroup != nil {
+			names[awsup.ManagedTargetGroupName(clusterName, ig.ObjectMeta.Name, lb.TargetGroup)] = true
+		}
+	}
+
+	var targetGroupARNs []string
+	for _, arn := range asgTargetGroupARNs {
+		if arns[arn] || names[awsup.TargetGroupNameFromARN(arn)] {
+			targetGroupARNs = append(targetGroupARNs, arn)
+		}
+	}
+	return targetGroupARNs
+}
+
 // deleteInstance deletes an Cloud Instance.
 func (c *RollingUpdateCluster) deleteInstance(u *cloudinstances.CloudInstance) error {
 	id := u.ID
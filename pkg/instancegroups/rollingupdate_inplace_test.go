@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancegroups
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	testingclient "k8s.io/client-go/testing"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+const testNodeupConfigHash = "newhash"
+
+func getInPlaceTestSetup(t *testing.T, count int, inPlace int) (*RollingUpdateCluster, map[string]*cloudinstances.CloudInstanceGroup) {
+	c, cloud := getTestSetup()
+	c.ValidationTimeout = time.Second
+	c.Cluster.Spec.RollingUpdate = &kops.RollingUpdate{
+		InPlaceUpdates: fi.Bool(true),
+	}
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	makeGroup(groups, c.K8sClient, cloud, "node-1", kops.InstanceGroupRoleNode, count, count)
+	group := groups["node-1"]
+	group.NodeupConfigHash = testNodeupConfigHash
+	for i := 0; i < inPlace; i++ {
+		group.NeedUpdate[i].InPlaceUpdate = true
+	}
+
+	return c, groups
+}
+
+// simulateNodeupAgent reports the requested nodeup config as applied, the way the nodeup agent does
+func simulateNodeupAgent(fakeClient *fake.Clientset) {
+	fakeClient.PrependReactor("get", "nodes", func(action testingclient.Action) (bool, runtime.Object, error) {
+		name := action.(testingclient.GetAction).GetName()
+		obj, err := fakeClient.Tracker().Get(action.GetResource(), "", name)
+		if err != nil {
+			return true, nil, err
+		}
+		node := obj.(*v1.Node).DeepCopy()
+		if hash, found := node.Annotations[nodeup.AnnotationRequestedConfigHash]; found {
+			node.Annotations[nodeup.AnnotationAppliedConfigHash] = hash
+		}
+		return true, node, nil
+	})
+}
+
+func TestRollingUpdateInPlace(t *testing.T) {
+	c, groups := getInPlaceTestSetup(t, 3, 2)
+	fakeClient := c.K8sClient.(*fake.Clientset)
+	simulateNodeupAgent(fakeClient)
+
+	err := c.RollingUpdate(groups, &kops.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+
+	requested := map[string]bool{}
+	deleted := map[string]bool{}
+	for _, action := range fakeClient.Actions() {
+		switch a := action.(type) {
+		case testingclient.PatchAction:
+			if string(a.GetPatch()) == `{"metadata":{"annotations":{"kops.k8s.io/requested-nodeup-config-hash":"newhash"}}}` {
+				requested[a.GetName()] = true
+			}
+		case testingclient.DeleteActionImpl:
+			if a.GetResource().Resource == "nodes" {
+				deleted[a.GetName()] = true
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]bool{"node-1a.local": true, "node-1b.local": true}, requested, "nodes updated in place")
+	assert.Equal(t, map[string]bool{"node-1c.local": true}, deleted, "nodes replaced")
+
+	for _, name := range []string{"node-1a.local", "node-1b.local"} {
+		node, err := fakeClient.Tracker().Get(v1.SchemeGroupVersion.WithResource("nodes"), "", name)
+		if !assert.NoError(t, err, "getting node") {
+			continue
+		}
+		assert.False(t, node.(*v1.Node).Spec.Unschedulable, "node %s uncordoned", name)
+		assert.Empty(t, node.(*v1.Node).Spec.Taints, "node %s untainted", name)
+	}
+}
+
+func TestRollingUpdateInPlaceDisabled(t *testing.T) {
+	c, groups := getInPlaceTestSetup(t, 2, 2)
+	c.Cluster.Spec.RollingUpdate = nil
+
+	err := c.RollingUpdate(groups, &kops.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+
+	deleted := 0
+	for _, action := range c.K8sClient.(*fake.Clientset).Actions() {
+		if a, ok := action.(testingclient.DeleteActionImpl); ok && a.GetResource().Resource == "nodes" {
+			deleted++
+		}
+	}
+	assert.Equal(t, 2, deleted, "nodes replaced")
+}
+
+func TestRollingUpdateInPlaceTimeout(t *testing.T) {
+	c, groups := getInPlaceTestSetup(t, 1, 1)
+	c.ValidationTimeout = 10 * time.Millisecond
+
+	err := c.RollingUpdate(groups, &kops.InstanceGroupList{})
+	if assert.Error(t, err, "rolling update") {
+		assert.Contains(t, err.Error(), "was not updated in place")
+	}
+
+	node, err := c.K8sClient.(*fake.Clientset).Tracker().Get(v1.SchemeGroupVersion.WithResource("nodes"), "", "node-1a.local")
+	if assert.NoError(t, err, "getting node") {
+		assert.True(t, node.(*v1.Node).Spec.Unschedulable, "node stays cordoned")
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	v1meta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,6 +38,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	testingclient "k8s.io/client-go/testing"
 	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+	"k8s.io/kops/cloudmock/aws/mockelbv2"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/cloudinstances"
 	"k8s.io/kops/pkg/validation"
@@ -915,6 +917,32 @@ func TestRollingUpdateMaxSurgeIgnoredForMaster(t *testing.T) {
 	assertGroupInstanceCount(t, cloud, "master-1", 1)
 }
 
+func TestRollingUpdateMaxSurgeIgnoredForProvisioned(t *testing.T) {
+
+	c, cloud := getTestSetup()
+
+	disabledSurgeTest := &disabledSurgeTest{
+		AutoScalingAPI: cloud.MockAutoscaling,
+		t:              t,
+	}
+	cloud.MockAutoscaling = disabledSurgeTest
+	cloud.MockEC2 = &ec2IgnoreTags{EC2API: cloud.MockEC2}
+
+	two := intstr.FromInt(2)
+	c.Cluster.Spec.RollingUpdate = &kopsapi.RollingUpdate{
+		MaxSurge: &two,
+	}
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	makeGroup(groups, c.K8sClient, cloud, "node-1", kopsapi.InstanceGroupRoleNode, 3, 2)
+	groups["node-1"].InstanceGroup.Spec.Manager = kopsapi.InstanceManagerProvisioner
+	err := c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+
+	assertGroupInstanceCount(t, cloud, "node-1", 1)
+	assert.Equal(t, 0, disabledSurgeTest.numDetached)
+}
+
 func TestRollingUpdateDisabled(t *testing.T) {
 
 	c, cloud := getTestSetup()
@@ -1526,3 +1554,117 @@ func assertGroupInstanceCount(t *testing.T, cloud awsup.AWSCloud, groupName stri
 		assert.Lenf(t, group.Instances, expected, "%s instances", groupName)
 	}
 }
+
+type assertDeregisteredEC2 struct {
+	ec2iface.EC2API
+	t              *testing.T
+	elbv2          *mockelbv2.MockELBV2
+	targetGroupARN string
+	terminated     int
+}
+
+func (e *assertDeregisteredEC2) TerminateInstances(input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+	if input.DryRun != nil && *input.DryRun {
+		return &ec2.TerminateInstancesOutput{}, nil
+	}
+
+	for _, id := range input.InstanceIds {
+		health, err := e.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: aws.String(e.targetGroupARN),
+			Targets:        []*elbv2.TargetDescription{{Id: id}},
+		})
+		if assert.NoError(e.t, err, "describing target health") {
+			assert.Equal(e.t, elbv2.TargetHealthStateEnumUnused, aws.StringValue(health.TargetHealthDescriptions[0].TargetHealth.State), "instance %s deregistered before termination", *id)
+		}
+		e.terminated++
+	}
+	return e.EC2API.TerminateInstances(input)
+}
+
+func TestRollingUpdateDeregistersFromTargetGroups(t *testing.T) {
+	c, cloud := getTestSetup()
+
+	mockELBV2 := &mockelbv2.MockELBV2{}
+	cloud.MockELBV2 = mockELBV2
+	tg, err := mockELBV2.CreateTargetGroup(&elbv2.CreateTargetGroupInput{
+		Name:     aws.String("ingress"),
+		Port:     aws.Int64(30080),
+		Protocol: aws.String(elbv2.ProtocolEnumTcp),
+	})
+	if err != nil {
+		t.Fatalf("error creating target group: %v", err)
+	}
+	targetGroupARN := aws.StringValue(tg.TargetGroups[0].TargetGroupArn)
+
+	// A target group attached to the autoscaling group outside of the instance group spec, such as the API's
+	apiTG, err := mockELBV2.CreateTargetGroup(&elbv2.CreateTargetGroupInput{
+		Name:     aws.String("api"),
+		Port:     aws.Int64(443),
+		Protocol: aws.String(elbv2.ProtocolEnumTcp),
+	})
+	if err != nil {
+		t.Fatalf("error creating target group: %v", err)
+	}
+	apiTargetGroupARN := aws.StringValue(apiTG.TargetGroups[0].TargetGroupArn)
+
+	ec2Wrapper := &assertDeregisteredEC2{
+		EC2API:         cloud.MockEC2,
+		t:              t,
+		elbv2:          mockELBV2,
+		targetGroupARN: targetGroupARN,
+	}
+	cloud.MockEC2 = ec2Wrapper
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	makeGroup(groups, c.K8sClient, cloud, "node-1", kopsapi.InstanceGroupRoleNode, 3, 3)
+	groups["node-1"].Raw.(*autoscaling.Group).TargetGroupARNs = []*string{aws.String(targetGroupARN), aws.String(apiTargetGroupARN)}
+	groups["node-1"].InstanceGroup.Spec.ExternalLoadBalancers = []kopsapi.LoadBalancer{{TargetGroupARN: aws.String(targetGroupARN)}}
+
+	var targets []*elbv2.TargetDescription
+	for _, instance := range groups["node-1"].NeedUpdate {
+		targets = append(targets, &elbv2.TargetDescription{Id: aws.String(instance.ID)})
+	}
+	for _, arn := range []string{targetGroupARN, apiTargetGroupARN} {
+		if _, err := mockELBV2.RegisterTargets(&elbv2.RegisterTargetsInput{TargetGroupArn: aws.String(arn), Targets: targets}); err != nil {
+			t.Fatalf("error registering targets: %v", err)
+		}
+	}
+
+	err = c.RollingUpdate(groups, &kopsapi.InstanceGroupList{})
+	assert.NoError(t, err, "rolling update")
+	assert.Equal(t, 3, ec2Wrapper.terminated, "terminated instances")
+
+	health, err := mockELBV2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{TargetGroupArn: aws.String(targetGroupARN)})
+	assert.NoError(t, err, "describing target health")
+	assert.Empty(t, health.TargetHealthDescriptions, "registered targets")
+
+	health, err = mockELBV2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{TargetGroupArn: aws.String(apiTargetGroupARN)})
+	assert.NoError(t, err, "describing target health")
+	assert.Len(t, health.TargetHealthDescriptions, 3, "registered targets of the API target group")
+}
+
+func TestInstanceGroupTargetGroupARNs(t *testing.T) {
+	ig := &kopsapi.InstanceGroup{
+		ObjectMeta: v1meta.ObjectMeta{Name: "nodes"},
+		Spec: kopsapi.InstanceGroupSpec{
+			ExternalLoadBalancers: []kopsapi.LoadBalancer{
+				{TargetGroupARN: aws.String("arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/external/1")},
+				{TargetGroup: &kopsapi.TargetGroupSpec{Protocol: "TCP", Port: 30080}},
+				{TargetGroup: &kopsapi.TargetGroupSpec{Name: aws.String("named"), Protocol: "TCP", Port: 30443}},
+			},
+		},
+	}
+	managedName := awsup.ManagedTargetGroupName("test.k8s.local", "nodes", ig.Spec.ExternalLoadBalancers[1].TargetGroup)
+
+	actual := instanceGroupTargetGroupARNs("test.k8s.local", ig, []string{
+		"arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/external/1",
+		"arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/" + managedName + "/2",
+		"arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/named/3",
+		"arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/tcp-test-k8s-local-abcdef/4",
+	})
+	assert.Equal(t, []string{
+		"arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/external/1",
+		"arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/" + managedName + "/2",
+		"arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/named/3",
+	}, actual)
+}
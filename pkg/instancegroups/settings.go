@@ -39,12 +39,19 @@ func resolveSettings(cluster *kops.Cluster, group *kops.InstanceGroup, numInstan
 		if rollingUpdate.MaxSurge == nil {
 			rollingUpdate.MaxSurge = def.MaxSurge
 		}
+		if rollingUpdate.InPlaceUpdates == nil {
+			rollingUpdate.InPlaceUpdates = def.InPlaceUpdates
+		}
 	}
 
 	if rollingUpdate.DrainAndTerminate == nil {
 		rollingUpdate.DrainAndTerminate = fi.Bool(true)
 	}
 
+	if rollingUpdate.InPlaceUpdates == nil {
+		rollingUpdate.InPlaceUpdates = fi.Bool(false)
+	}
+
 	if rollingUpdate.MaxSurge == nil {
 		val := intstr.FromInt(0)
 		if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderAWS && !featureflag.Spotinst.Enabled() {
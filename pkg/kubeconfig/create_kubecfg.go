@@ -50,6 +50,16 @@ func BuildKubecfg(cluster *kops.Cluster, keyStore fi.Keystore, secretStore fi.Se
 		}
 	}
 
+	// While the cluster is migrated between gossip and DNS, we keep using the previous name,
+	// as the API servers that have not yet been replaced are not valid for the new one.
+	if previousNames := cluster.DNSMigrationPreviousNames(); len(previousNames) == 2 {
+		if internal {
+			master = previousNames[1]
+		} else {
+			master = previousNames[0]
+		}
+	}
+
 	server := "https://" + master
 
 	// We use the LoadBalancer where we know the master DNS name is otherwise unreachable
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/util/pkg/text"
 	"sigs.k8s.io/yaml"
@@ -116,6 +117,11 @@ func (m *Object) accept(visitor Visitor) error {
 	return err
 }
 
+// ToUnstructured returns the object as an unstructured kubernetes object, sharing the underlying data
+func (m *Object) ToUnstructured() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: m.data}
+}
+
 // IsEmptyObject checks if the object has no keys set (i.e. `== {}`)
 func (m *Object) IsEmptyObject() bool {
 	return len(m.data) == 0
@@ -19,6 +19,7 @@ package awsmodel
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/pkg/model/defaults"
+	nodeidentityaws "k8s.io/kops/pkg/nodeidentity/aws"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
@@ -78,6 +80,11 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 		c.AddTask(task)
 
+		// Instances of provisioned groups are created by karpenter from the launch template, outside of any autoscaling group
+		if ig.IsProvisioned() {
+			continue
+		}
+
 		// @step: now lets build the autoscaling group task
 		tsk, err := b.buildAutoScalingGroupTask(c, name, ig)
 		if err != nil {
@@ -319,9 +326,50 @@ func (b *AutoscalingGroupModelBuilder) buildLaunchTemplateTask(c *fi.ModelBuilde
 		lt.Tenancy = fi.String(ig.Spec.Tenancy)
 	}
 
+	if cr := ig.Spec.CapacityReservation; cr != nil {
+		lt.CapacityReservationPreference = cr.Preference
+		lt.CapacityReservationID = cr.ID
+		lt.CapacityReservationResourceGroupARN = cr.ResourceGroupARN
+	}
+
+	if ig.Spec.PlacementGroup != nil {
+		if lt.PlacementGroup, err = b.buildPlacementGroupTask(c, name, ig); err != nil {
+			return nil, err
+		}
+	}
+
 	return lt, nil
 }
 
+// buildPlacementGroupTask is responsible for building the placement group used by the launch template.
+// A named placement group is an existing one that kOps only references.
+func (b *AutoscalingGroupModelBuilder) buildPlacementGroupTask(c *fi.ModelBuilderContext, name string, ig *kops.InstanceGroup) (*awstasks.PlacementGroup, error) {
+	spec := ig.Spec.PlacementGroup
+	if fi.StringValue(spec.Name) != "" {
+		pg := &awstasks.PlacementGroup{
+			Name:      spec.Name,
+			Lifecycle: b.Lifecycle,
+			Shared:    fi.Bool(true),
+		}
+		// several instance groups may share the same existing placement group
+		if err := c.EnsureTask(pg); err != nil {
+			return nil, err
+		}
+		return pg, nil
+	}
+
+	pg := &awstasks.PlacementGroup{
+		Name:           fi.String(name),
+		Lifecycle:      b.Lifecycle,
+		Strategy:       fi.String(spec.Strategy),
+		PartitionCount: spec.PartitionCount,
+		Shared:         fi.Bool(false),
+		Tags:           b.CloudTags(name, false),
+	}
+	c.AddTask(pg)
+	return pg, nil
+}
+
 // buildSecurityGroups is responsible for building security groups for a launch template.
 func (b *AutoscalingGroupModelBuilder) buildSecurityGroups(c *fi.ModelBuilderContext, ig *kops.InstanceGroup) ([]*awstasks.SecurityGroup, error) {
 	// @step: if required we add the override for the security group for this instancegroup
@@ -433,6 +481,26 @@ func (b *AutoscalingGroupModelBuilder) buildAutoScalingGroupTask(c *fi.ModelBuil
 		t.InstanceProtection = ig.Spec.InstanceProtection
 	}
 
+	for _, schedule := range ig.Spec.ScheduledScaling {
+		size := func(v *int32) *int64 {
+			if v == nil {
+				return nil
+			}
+			return fi.Int64(int64(*v))
+		}
+		t.ScheduledActions = append(t.ScheduledActions, &awstasks.ScheduledAction{
+			Name:            fi.String(awstasks.ScheduledActionNamePrefix + schedule.Name),
+			Recurrence:      fi.String(schedule.Recurrence),
+			TimeZone:        schedule.TimeZone,
+			MinSize:         size(schedule.MinSize),
+			MaxSize:         size(schedule.MaxSize),
+			DesiredCapacity: size(schedule.DesiredCapacity),
+		})
+	}
+	sort.Slice(t.ScheduledActions, func(i, j int) bool {
+		return fi.StringValue(t.ScheduledActions[i].Name) < fi.StringValue(t.ScheduledActions[j].Name)
+	})
+
 	t.LoadBalancers = []*awstasks.ClassicLoadBalancer{}
 	t.TargetGroups = []*awstasks.TargetGroup{}
 
@@ -483,6 +551,12 @@ func (b *AutoscalingGroupModelBuilder) buildAutoScalingGroupTask(c *fi.ModelBuil
 			t.TargetGroups = append(t.TargetGroups, tg)
 			c.AddTask(tg)
 		}
+
+		if extLB.TargetGroup != nil {
+			tg := b.buildManagedTargetGroup(b.Lifecycle, ig, extLB.TargetGroup)
+			t.TargetGroups = append(t.TargetGroups, tg)
+			c.AddTask(tg)
+		}
 	}
 	sort.Stable(awstasks.OrderLoadBalancersByName(t.LoadBalancers))
 	sort.Stable(awstasks.OrderTargetGroupsByName(t.TargetGroups))
@@ -502,3 +576,54 @@ func (b *AutoscalingGroupModelBuilder) buildAutoScalingGroupTask(c *fi.ModelBuil
 
 	return t, nil
 }
+
+// buildManagedTargetGroup builds a target group that kOps creates and owns for the instance group
+func (b *AWSModelContext) buildManagedTargetGroup(lifecycle fi.Lifecycle, ig *kops.InstanceGroup, spec *kops.TargetGroupSpec) *awstasks.TargetGroup {
+	name := awsup.ManagedTargetGroupName(b.ClusterName(), ig.ObjectMeta.Name, spec)
+
+	tags := b.CloudTags(name, false)
+	// Override the returned name to be the expected target group name
+	tags["Name"] = name
+	tags[nodeidentityaws.CloudTagInstanceGroupName] = ig.ObjectMeta.Name
+
+	tg := &awstasks.TargetGroup{
+		Name:                fi.String(name),
+		Lifecycle:           lifecycle,
+		VPC:                 b.LinkToVPC(),
+		Tags:                tags,
+		Protocol:            fi.String(spec.Protocol),
+		Port:                fi.Int64(spec.Port),
+		HealthyThreshold:    fi.Int64(2),
+		UnhealthyThreshold:  fi.Int64(2),
+		DeregistrationDelay: spec.DeregistrationDelaySeconds,
+		Shared:              fi.Bool(false),
+	}
+
+	// ALB target groups only support HTTP(S) health checks; default to checking the way traffic is routed
+	healthCheckProtocol := "TCP"
+	if spec.Protocol == "HTTP" || spec.Protocol == "HTTPS" {
+		healthCheckProtocol = spec.Protocol
+	}
+
+	if hc := spec.HealthCheck; hc != nil {
+		if hc.Protocol != "" {
+			healthCheckProtocol = hc.Protocol
+		}
+		if hc.Port != nil {
+			tg.HealthCheckPort = fi.String(strconv.FormatInt(*hc.Port, 10))
+		}
+		if hc.Path != "" {
+			tg.HealthCheckPath = fi.String(hc.Path)
+		}
+		tg.HealthCheckInterval = hc.IntervalSeconds
+		if hc.HealthyThreshold != nil {
+			tg.HealthyThreshold = hc.HealthyThreshold
+		}
+		if hc.UnhealthyThreshold != nil {
+			tg.UnhealthyThreshold = hc.UnhealthyThreshold
+		}
+	}
+	tg.HealthCheckProtocol = fi.String(healthCheckProtocol)
+
+	return tg
+}
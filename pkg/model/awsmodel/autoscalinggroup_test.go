@@ -18,6 +18,7 @@ package awsmodel
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -276,3 +277,220 @@ func TestAPIServerAdditionalSecurityGroupsWithNLB(t *testing.T) {
 		})
 	}
 }
+
+func TestManagedTargetGroup(t *testing.T) {
+	cluster := buildMinimalCluster()
+	ig := buildNodeInstanceGroup("subnet-us-mock-1a")
+	ig.Spec.ExternalLoadBalancers = []kops.LoadBalancer{
+		{
+			TargetGroup: &kops.TargetGroupSpec{
+				Protocol:                   "HTTP",
+				Port:                       30080,
+				DeregistrationDelaySeconds: fi.Int64(30),
+				HealthCheck: &kops.TargetGroupHealthCheckSpec{
+					Path:             "/healthz",
+					Port:             fi.Int64(10256),
+					HealthyThreshold: fi.Int64(3),
+				},
+			},
+		},
+		{
+			TargetGroup: &kops.TargetGroupSpec{
+				Name:     fi.String("ingress-tls"),
+				Protocol: "TCP",
+				Port:     30443,
+			},
+		},
+	}
+
+	b := AutoscalingGroupModelBuilder{
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				IAMModelContext: iam.IAMModelContext{Cluster: cluster},
+				SSHPublicKeys:   [][]byte{[]byte(sshPublicKeyEntry)},
+				InstanceGroups:  []*kops.InstanceGroup{ig},
+			},
+		},
+		BootstrapScriptBuilder: &model.BootstrapScriptBuilder{
+			Lifecycle: fi.LifecycleSync,
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider:     "aws",
+					Networking:        &kops.NetworkingSpec{},
+					KubernetesVersion: "1.20.0",
+				},
+			},
+		},
+		Cluster:   cluster,
+		Lifecycle: fi.LifecycleSync,
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+	for _, keypair := range []string{fi.CertificateIDCA, "etcd-clients-ca"} {
+		c.AddTask(&fitasks.Keypair{
+			Name:    fi.String(keypair),
+			Subject: "cn=" + keypair,
+			Type:    "ca",
+		})
+	}
+
+	if err := b.Build(c); err != nil {
+		t.Fatalf("error from Build: %v", err)
+	}
+
+	asg := c.Tasks["AutoscalingGroup/nodes.testcluster.test.com"].(*awstasks.AutoscalingGroup)
+	if len(asg.TargetGroups) != 2 {
+		t.Fatalf("expected 2 target groups, got %d", len(asg.TargetGroups))
+	}
+
+	tg := c.Tasks["TargetGroup/nodes-30080-testcluster-t-0pp4f6"].(*awstasks.TargetGroup)
+	if fi.BoolValue(tg.Shared) {
+		t.Errorf("managed target group should not be shared")
+	}
+	if fi.StringValue(tg.HealthCheckProtocol) != "HTTP" || fi.StringValue(tg.HealthCheckPath) != "/healthz" || fi.StringValue(tg.HealthCheckPort) != "10256" {
+		t.Errorf("unexpected health check %s %s %s", fi.StringValue(tg.HealthCheckProtocol), fi.StringValue(tg.HealthCheckPort), fi.StringValue(tg.HealthCheckPath))
+	}
+	if fi.Int64Value(tg.HealthyThreshold) != 3 || fi.Int64Value(tg.UnhealthyThreshold) != 2 {
+		t.Errorf("unexpected thresholds %d/%d", fi.Int64Value(tg.HealthyThreshold), fi.Int64Value(tg.UnhealthyThreshold))
+	}
+	if fi.Int64Value(tg.DeregistrationDelay) != 30 {
+		t.Errorf("unexpected deregistration delay %d", fi.Int64Value(tg.DeregistrationDelay))
+	}
+	if tg.Tags["kops.k8s.io/instancegroup"] != "nodes" {
+		t.Errorf("unexpected tags %v", tg.Tags)
+	}
+
+	tcp := c.Tasks["TargetGroup/ingress-tls"].(*awstasks.TargetGroup)
+	if fi.StringValue(tcp.HealthCheckProtocol) != "TCP" || tcp.DeregistrationDelay != nil {
+		t.Errorf("unexpected defaults for TCP target group: %s %v", fi.StringValue(tcp.HealthCheckProtocol), tcp.DeregistrationDelay)
+	}
+}
+
+func TestPlacementGroupAndCapacityReservation(t *testing.T) {
+	cluster := buildMinimalCluster()
+	managed := buildNodeInstanceGroup("subnet-us-mock-1a")
+	managed.Spec.PlacementGroup = &kops.PlacementGroupSpec{
+		Strategy:       "partition",
+		PartitionCount: fi.Int64(3),
+	}
+	managed.Spec.CapacityReservation = &kops.CapacityReservationSpec{
+		ID: fi.String("cr-1234567890abcdef0"),
+	}
+	existing := buildNodeInstanceGroup("subnet-us-mock-1a")
+	existing.ObjectMeta.Name = "gpu"
+	existing.Spec.PlacementGroup = &kops.PlacementGroupSpec{
+		Name: fi.String("my-placement-group"),
+	}
+
+	b := AutoscalingGroupModelBuilder{
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				IAMModelContext: iam.IAMModelContext{Cluster: cluster},
+				SSHPublicKeys:   [][]byte{[]byte(sshPublicKeyEntry)},
+				InstanceGroups:  []*kops.InstanceGroup{managed, existing},
+			},
+		},
+		BootstrapScriptBuilder: &model.BootstrapScriptBuilder{
+			Lifecycle: fi.LifecycleSync,
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider:     "aws",
+					Networking:        &kops.NetworkingSpec{},
+					KubernetesVersion: "1.20.0",
+				},
+			},
+		},
+		Cluster:   cluster,
+		Lifecycle: fi.LifecycleSync,
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+	for _, keypair := range []string{fi.CertificateIDCA, "etcd-clients-ca"} {
+		c.AddTask(&fitasks.Keypair{
+			Name:    fi.String(keypair),
+			Subject: "cn=" + keypair,
+			Type:    "ca",
+		})
+	}
+
+	if err := b.Build(c); err != nil {
+		t.Fatalf("error from Build: %v", err)
+	}
+
+	pg := c.Tasks["PlacementGroup/nodes.testcluster.test.com"].(*awstasks.PlacementGroup)
+	if fi.BoolValue(pg.Shared) || fi.StringValue(pg.Strategy) != "partition" || fi.Int64Value(pg.PartitionCount) != 3 {
+		t.Errorf("unexpected managed placement group %v", pg)
+	}
+	lt := c.Tasks["LaunchTemplate/nodes.testcluster.test.com"].(*awstasks.LaunchTemplate)
+	if lt.PlacementGroup != pg {
+		t.Errorf("launch template does not reference the managed placement group")
+	}
+	if fi.StringValue(lt.CapacityReservationID) != "cr-1234567890abcdef0" || lt.CapacityReservationPreference != nil {
+		t.Errorf("unexpected capacity reservation %v/%v", lt.CapacityReservationPreference, lt.CapacityReservationID)
+	}
+
+	shared := c.Tasks["PlacementGroup/my-placement-group"].(*awstasks.PlacementGroup)
+	if !fi.BoolValue(shared.Shared) {
+		t.Errorf("named placement group should be shared")
+	}
+	lt = c.Tasks["LaunchTemplate/gpu.testcluster.test.com"].(*awstasks.LaunchTemplate)
+	if lt.PlacementGroup != shared {
+		t.Errorf("launch template does not reference the existing placement group")
+	}
+}
+
+func TestProvisionedInstanceGroup(t *testing.T) {
+	cluster := buildMinimalCluster()
+	ig := buildNodeInstanceGroup("subnet-us-mock-1a")
+	ig.Spec.Manager = kops.InstanceManagerProvisioner
+
+	b := AutoscalingGroupModelBuilder{
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				IAMModelContext: iam.IAMModelContext{Cluster: cluster},
+				SSHPublicKeys:   [][]byte{[]byte(sshPublicKeyEntry)},
+				InstanceGroups:  []*kops.InstanceGroup{ig},
+			},
+		},
+		BootstrapScriptBuilder: &model.BootstrapScriptBuilder{
+			Lifecycle: fi.LifecycleSync,
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider:     "aws",
+					Networking:        &kops.NetworkingSpec{},
+					KubernetesVersion: "1.20.0",
+				},
+			},
+		},
+		Cluster:   cluster,
+		Lifecycle: fi.LifecycleSync,
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+	for _, keypair := range []string{fi.CertificateIDCA, "etcd-clients-ca"} {
+		c.AddTask(&fitasks.Keypair{
+			Name:    fi.String(keypair),
+			Subject: "cn=" + keypair,
+			Type:    "ca",
+		})
+	}
+
+	if err := b.Build(c); err != nil {
+		t.Fatalf("error from Build: %v", err)
+	}
+
+	if _, ok := c.Tasks["LaunchTemplate/nodes.testcluster.test.com"]; !ok {
+		t.Errorf("launch template not found in tasks")
+	}
+	for name := range c.Tasks {
+		if strings.HasPrefix(name, "AutoscalingGroup/") || strings.HasPrefix(name, "WarmPool/") {
+			t.Errorf("unexpected task %q for provisioned instance group", name)
+		}
+	}
+}
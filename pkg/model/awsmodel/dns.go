@@ -35,7 +35,7 @@ type DNSModelBuilder struct {
 var _ fi.ModelBuilder = &DNSModelBuilder{}
 
 func (b *DNSModelBuilder) ensureDNSZone(c *fi.ModelBuilderContext) error {
-	if dns.IsGossipHostname(b.Cluster.Name) {
+	if b.Cluster.IsGossip() && len(b.previousDNSNames()) == 0 {
 		return nil
 	}
 
@@ -84,7 +84,7 @@ func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		// We now create the DNS Zone for AWS even in the case of public zones;
 		// it has to exist for the IAM record anyway.
 		// TODO: We can now rationalize the code paths
-		if !dns.IsGossipHostname(b.Cluster.Name) {
+		if !b.Cluster.IsGossip() {
 			if err := b.ensureDNSZone(c); err != nil {
 				return err
 			}
@@ -107,7 +107,7 @@ func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		// This will point our external DNS record to the load balancer, and put the
 		// pieces together for kubectl to work
 
-		if !dns.IsGossipHostname(b.Cluster.Name) {
+		if !b.Cluster.IsGossip() {
 			if err := b.ensureDNSZone(c); err != nil {
 				return err
 			}
@@ -137,7 +137,7 @@ func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		// This will point the internal API DNS record to the load balancer.
 		// This means kubelet connections go via the load balancer and are more HA.
 
-		if !dns.IsGossipHostname(b.Cluster.Name) {
+		if !b.Cluster.IsGossip() {
 			if err := b.ensureDNSZone(c); err != nil {
 				return err
 			}
@@ -172,6 +172,28 @@ func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 	}
 
+	// While the cluster is being migrated to gossip, we keep the names it used before pointing at the load balancer,
+	// so that clients and instances which have not yet been replaced can still reach the API servers.
+	if previousNames := b.previousDNSNames(); len(previousNames) != 0 && targetLoadBalancer != nil {
+		if err := b.ensureDNSZone(c); err != nil {
+			return err
+		}
+
+		for _, name := range previousNames {
+			err := c.EnsureTask(&awstasks.DNSName{
+				Name:               fi.String(name),
+				ResourceName:       fi.String(name),
+				Lifecycle:          b.Lifecycle,
+				Zone:               b.LinkToDNSZone(),
+				ResourceType:       fi.String("A"),
+				TargetLoadBalancer: targetLoadBalancer,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	if b.UsesBastionDns() {
 		// Pulling this down into it's own if statement. The DNS configuration here
 		// is similar to others, but I would like to keep it on it's own in case we need
@@ -184,3 +206,18 @@ func (b *DNSModelBuilder) Build(c *fi.ModelBuilderContext) error {
 
 	return nil
 }
+
+// previousDNSNames returns the API names that were published in DNS before a migration to gossip that is still in progress.
+func (b *DNSModelBuilder) previousDNSNames() []string {
+	if !b.Cluster.IsGossip() {
+		return nil
+	}
+
+	var names []string
+	for _, name := range b.Cluster.DNSMigrationPreviousNames() {
+		if !dns.IsGossipHostname(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
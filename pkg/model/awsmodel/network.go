@@ -238,6 +238,18 @@ func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			default:
 				klog.V(2).Infof("unable to properly tag subnet %q because it has unknown type %q. Load balancers may be created in incorrect subnets", subnetSpec.Name, subnetSpec.Type)
 			}
+
+			// Karpenter selects the subnets of provisioned instance groups by tag
+			for _, ig := range b.InstanceGroups {
+				if !ig.IsProvisioned() {
+					continue
+				}
+				for _, name := range ig.Spec.Subnets {
+					if name == subnetSpec.Name {
+						tags[awsup.TagNameProvisionedSubnetPrefix+ig.ObjectMeta.Name] = "1"
+					}
+				}
+			}
 		}
 
 		subnet := &awstasks.Subnet{
@@ -298,6 +310,23 @@ func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 	}
 
+	// Private subnets with IPv6 addresses reach the internet through an egress-only internet gateway
+	var eigw *awstasks.EgressOnlyInternetGateway
+	for _, info := range infoByZone {
+		for _, subnetSpec := range info.PrivateSubnets {
+			if subnetSpec.IPv6CIDR != "" && subnetSpec.Egress != "External" && eigw == nil {
+				eigw = &awstasks.EgressOnlyInternetGateway{
+					Name:      fi.String(b.ClusterName()),
+					Lifecycle: b.Lifecycle,
+					VPC:       b.LinkToVPC(),
+					Shared:    fi.Bool(sharedVPC),
+				}
+				eigw.Tags = b.CloudTags(*eigw.Name, *eigw.Shared)
+				c.AddTask(eigw)
+			}
+		}
+	}
+
 	// Set up private route tables & egress
 	for zone, info := range infoByZone {
 		if len(info.PrivateSubnets) == 0 {
@@ -479,7 +508,26 @@ func (b *NetworkModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 		c.AddTask(r)
 
+		if eigw != nil && egress != "External" && hasIPv6Subnet(info.PrivateSubnets) {
+			c.AddTask(&awstasks.Route{
+				Name:                      fi.String("private-" + zone + "-::/0"),
+				Lifecycle:                 b.Lifecycle,
+				IPv6CIDR:                  fi.String("::/0"),
+				RouteTable:                rt,
+				EgressOnlyInternetGateway: eigw,
+			})
+		}
 	}
 
 	return nil
 }
+
+// hasIPv6Subnet returns true if any of the subnets has an IPv6 CIDR
+func hasIPv6Subnet(subnets []*kops.ClusterSubnetSpec) bool {
+	for _, subnetSpec := range subnets {
+		if subnetSpec.IPv6CIDR != "" {
+			return true
+		}
+	}
+	return false
+}
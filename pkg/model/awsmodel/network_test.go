@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsmodel
+
+import (
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+)
+
+func TestPrivateIPv6Routes(t *testing.T) {
+	cluster := buildMinimalCluster()
+	cluster.Spec.NonMasqueradeCIDR = "::/0"
+	cluster.Spec.Topology = &kops.TopologySpec{
+		Masters: kops.TopologyPrivate,
+		Nodes:   kops.TopologyPrivate,
+	}
+	cluster.Spec.Subnets = []kops.ClusterSubnetSpec{
+		{Name: "us-test-1a", Zone: "us-test-1a", CIDR: "172.20.32.0/19", IPv6CIDR: "/64#1", Type: kops.SubnetTypePrivate},
+		{Name: "utility-us-test-1a", Zone: "us-test-1a", CIDR: "172.20.4.0/22", IPv6CIDR: "/64#2", Type: kops.SubnetTypeUtility},
+		{Name: "us-test-1b", Zone: "us-test-1b", CIDR: "172.20.64.0/19", Type: kops.SubnetTypePrivate},
+		{Name: "utility-us-test-1b", Zone: "us-test-1b", CIDR: "172.20.8.0/22", Type: kops.SubnetTypeUtility},
+	}
+
+	b := NetworkModelBuilder{
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				IAMModelContext: iam.IAMModelContext{Cluster: cluster},
+			},
+		},
+		Lifecycle: fi.LifecycleSync,
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+	if err := b.Build(c); err != nil {
+		t.Fatalf("error from Build: %v", err)
+	}
+
+	eigw, ok := c.Tasks["EgressOnlyInternetGateway/testcluster.test.com"].(*awstasks.EgressOnlyInternetGateway)
+	if !ok {
+		t.Fatalf("egress-only internet gateway not found in tasks")
+	}
+	if fi.BoolValue(eigw.Shared) {
+		t.Errorf("egress-only internet gateway should not be shared")
+	}
+
+	route, ok := c.Tasks["Route/private-us-test-1a-::/0"].(*awstasks.Route)
+	if !ok {
+		t.Fatalf("IPv6 route not found in the private route table of us-test-1a")
+	}
+	if route.EgressOnlyInternetGateway != eigw || fi.StringValue(route.IPv6CIDR) != "::/0" {
+		t.Errorf("unexpected IPv6 route %v", route)
+	}
+	if fi.StringValue(route.RouteTable.Name) != b.NamePrivateRouteTableInZone("us-test-1a") {
+		t.Errorf("IPv6 route in unexpected route table %q", fi.StringValue(route.RouteTable.Name))
+	}
+
+	if _, ok := c.Tasks["Route/private-us-test-1b-::/0"]; ok {
+		t.Errorf("unexpected IPv6 route in the private route table of us-test-1b, which has no IPv6 subnets")
+	}
+}
+
+func TestProvisionedSubnetTags(t *testing.T) {
+	cluster := buildMinimalCluster()
+	for i := range cluster.Spec.Subnets {
+		cluster.Spec.Subnets[i].Type = kops.SubnetTypePublic
+	}
+	ig := buildNodeInstanceGroup("subnet-us-mock-1a")
+	ig.ObjectMeta.Name = "spot"
+	ig.Spec.Manager = kops.InstanceManagerProvisioner
+
+	b := NetworkModelBuilder{
+		AWSModelContext: &AWSModelContext{
+			KopsModelContext: &model.KopsModelContext{
+				IAMModelContext: iam.IAMModelContext{Cluster: cluster},
+				InstanceGroups:  []*kops.InstanceGroup{ig, buildNodeInstanceGroup("subnet-us-mock-1a")},
+			},
+		},
+		Lifecycle: fi.LifecycleSync,
+	}
+
+	c := &fi.ModelBuilderContext{
+		Tasks: make(map[string]fi.Task),
+	}
+	if err := b.Build(c); err != nil {
+		t.Fatalf("error from Build: %v", err)
+	}
+
+	subnet, ok := c.Tasks["Subnet/subnet-us-mock-1a.testcluster.test.com"].(*awstasks.Subnet)
+	if !ok {
+		t.Fatalf("subnet not found in tasks")
+	}
+	if subnet.Tags["kops.k8s.io/instance-group/spot"] != "1" {
+		t.Errorf("subnet is not tagged for the provisioned instance group: %v", subnet.Tags)
+	}
+	if _, ok := subnet.Tags["kops.k8s.io/instance-group/nodes"]; ok {
+		t.Errorf("subnet is tagged for an instance group that is not provisioned: %v", subnet.Tags)
+	}
+}
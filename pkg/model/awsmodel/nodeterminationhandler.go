@@ -76,6 +76,10 @@ type NodeTerminationHandlerBuilder struct {
 func (b *NodeTerminationHandlerBuilder) Build(c *fi.ModelBuilderContext) error {
 
 	for _, ig := range b.InstanceGroups {
+		// Provisioned groups have no autoscaling group to hook into
+		if ig.IsProvisioned() {
+			continue
+		}
 		err := b.configureASG(c, ig)
 		if err != nil {
 			return err
@@ -792,6 +792,11 @@ func (b *SpotInstanceGroupModelBuilder) buildLoadBalancers(c *fi.ModelBuilderCon
 			targetGroups = append(targetGroups, tg)
 			c.AddTask(tg)
 		}
+		if extLB.TargetGroup != nil {
+			tg := b.buildManagedTargetGroup(b.Lifecycle, ig, extLB.TargetGroup)
+			targetGroups = append(targetGroups, tg)
+			c.AddTask(tg)
+		}
 	}
 
 	return loadBalancers, targetGroups, nil
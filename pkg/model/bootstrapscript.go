@@ -107,6 +107,13 @@ func (b *BootstrapScript) kubeEnv(ig *kops.InstanceGroup, c *fi.Context) (string
 	bootConfig.NodeupConfigHash = base64.StdEncoding.EncodeToString(sum256[:])
 	b.nodeupConfig.Resource = fi.NewBytesResource(configData)
 
+	if config.InPlaceUpdates {
+		bootConfig.NodeupConfigReplacementHash, err = resources.NodeupConfigReplacementHash(config)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	bootConfigData, err := utils.YamlMarshal(bootConfig)
 	if err != nil {
 		return "", fmt.Errorf("error converting boot config to yaml: %v", err)
@@ -32,6 +32,7 @@ import (
 	"k8s.io/kops/pkg/model/components/addonmanifests/awsloadbalancercontroller"
 	"k8s.io/kops/pkg/model/components/addonmanifests/clusterautoscaler"
 	"k8s.io/kops/pkg/model/components/addonmanifests/dnscontroller"
+	"k8s.io/kops/pkg/model/components/addonmanifests/karpenter"
 	"k8s.io/kops/pkg/model/components/addonmanifests/nodeterminationhandler"
 	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/upup/pkg/fi"
@@ -126,6 +127,8 @@ func getWellknownServiceAccount(name string) iam.Subject {
 		return &awsebscsidriver.ServiceAccount{}
 	case "aws-node-termination-handler":
 		return &nodeterminationhandler.ServiceAccount{}
+	case "karpenter":
+		return &karpenter.ServiceAccount{}
 	default:
 		return nil
 	}
@@ -118,6 +118,8 @@ func (b *KubeAPIServerOptionsBuilder) BuildOptions(o interface{}) error {
 		c.CloudProvider = "alicloud"
 	case kops.CloudProviderAzure:
 		c.CloudProvider = "azure"
+	case kops.CloudProviderMetal:
+		// Hosts are not managed by a cloud
 	default:
 		return fmt.Errorf("unknown cloudprovider %q", clusterSpec.CloudProvider)
 	}
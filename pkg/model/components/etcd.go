@@ -39,6 +39,18 @@ const (
 	DefaultEtcd3Version_1_22 = "3.5.0"
 )
 
+// ProtokubeEtcdImage returns the etcd image that protokube runs when it manages etcd, before remapping
+func ProtokubeEtcdImage(cluster *kops.Cluster) string {
+	if len(cluster.Spec.EtcdClusters) == 0 {
+		return ""
+	}
+	etcdCluster := cluster.Spec.EtcdClusters[0]
+	if etcdCluster.Image != "" {
+		return etcdCluster.Image
+	}
+	return fmt.Sprintf("k8s.gcr.io/etcd:%s", etcdCluster.Version)
+}
+
 // BuildOptions is responsible for filling in the defaults for the etcd cluster model
 func (b *EtcdOptionsBuilder) BuildOptions(o interface{}) error {
 	spec := o.(*kops.ClusterSpec)
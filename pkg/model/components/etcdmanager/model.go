@@ -300,11 +300,16 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster kops.EtcdClusterSpec) (*v1.Pod
 
 	// The dns suffix logic mirrors the existing logic, so we should be compatible with existing clusters
 	// (etcd makes it difficult to change peer urls, treating it as a cluster event, for reasons unknown)
+	// It follows the cluster name, which never changes, so the suffix is kept when a cluster is migrated between gossip and DNS.
 	dnsInternalSuffix := ""
-	if dns.IsGossipHostname(b.Cluster.Spec.MasterInternalName) {
-		// @TODO: This is hacky, but we want it so that we can have a different internal & external name
-		dnsInternalSuffix = b.Cluster.Spec.MasterInternalName
-		dnsInternalSuffix = strings.TrimPrefix(dnsInternalSuffix, "api.")
+	if dns.IsGossipHostname(b.Cluster.ObjectMeta.Name) {
+		if dns.IsGossipHostname(b.Cluster.Spec.MasterInternalName) {
+			// @TODO: This is hacky, but we want it so that we can have a different internal & external name
+			dnsInternalSuffix = b.Cluster.Spec.MasterInternalName
+			dnsInternalSuffix = strings.TrimPrefix(dnsInternalSuffix, "api.")
+		} else {
+			dnsInternalSuffix = "internal." + b.Cluster.ObjectMeta.Name
+		}
 	}
 
 	if dnsInternalSuffix == "" {
@@ -447,6 +452,14 @@ func (b *EtcdManagerBuilder) buildPod(etcdCluster kops.EtcdClusterSpec) (*v1.Pod
 			}
 			config.VolumeNameTag = openstack.TagNameEtcdClusterPrefix + etcdCluster.Name
 
+		case kops.CloudProviderMetal:
+			config.VolumeProvider = "external"
+
+			// The volumes are directories under /mnt/disks on the control plane hosts, created by nodeup
+			config.VolumeTag = []string{
+				MetalVolumePrefix(b.Cluster.Name, etcdCluster.Name),
+			}
+
 		default:
 			return nil, fmt.Errorf("CloudProvider %q not supported with etcd-manager", b.Cluster.Spec.CloudProvider)
 		}
@@ -577,3 +590,8 @@ type config struct {
 	VolumeNameTag         string   `flag:"volume-name-tag"`
 	DNSSuffix             string   `flag:"dns-suffix"`
 }
+
+// MetalVolumePrefix is the prefix of the names of the directories that etcd-manager uses as volumes on metal
+func MetalVolumePrefix(clusterName string, etcdClusterName string) string {
+	return clusterName + "--" + etcdClusterName + "--"
+}
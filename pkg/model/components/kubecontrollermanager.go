@@ -97,6 +97,9 @@ func (b *KubeControllerManagerOptionsBuilder) BuildOptions(o interface{}) error
 	case kops.CloudProviderAzure:
 		kcm.CloudProvider = "azure"
 
+	case kops.CloudProviderMetal:
+		// Hosts are not managed by a cloud
+
 	default:
 		return fmt.Errorf("unknown cloudprovider %q", clusterSpec.CloudProvider)
 	}
@@ -35,6 +35,7 @@ import (
 
 	"github.com/blang/semver/v4"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
@@ -418,3 +419,18 @@ func (b *KopsModelContext) NodePortRange() (utilnet.PortRange, error) {
 func (b *KopsModelContext) UseServiceAccountIAM() bool {
 	return featureflag.UseServiceAccountIAM.Enabled()
 }
+
+// ContainerdRuntimeHandlers returns the sorted names of the containerd runtime handlers configured on any instance group.
+func (b *KopsModelContext) ContainerdRuntimeHandlers() []string {
+	if b.Cluster.Spec.ContainerRuntime != "containerd" {
+		return nil
+	}
+
+	names := sets.NewString()
+	for _, ig := range b.InstanceGroups {
+		for _, runtime := range b.Cluster.Spec.Containerd.ResolveRuntimes(ig) {
+			names.Insert(runtime.Name)
+		}
+	}
+	return names.List()
+}
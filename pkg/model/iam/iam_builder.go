@@ -340,6 +340,10 @@ func (r *NodeRoleMaster) BuildAWSPolicy(b *PolicyBuilder) (*Policy, error) {
 		}
 		AddClusterAutoscalerPermissions(p)
 
+		if b.Cluster.Spec.Karpenter != nil && fi.BoolValue(b.Cluster.Spec.Karpenter.Enabled) {
+			AddKarpenterPermissions(p, b.IAMPrefix())
+		}
+
 		nth := b.Cluster.Spec.NodeTerminationHandler
 		if nth != nil && fi.BoolValue(nth.Enabled) && fi.BoolValue(nth.EnableSQSTerminationDraining) {
 			AddNodeTerminationHandlerSQSPermissions(p)
@@ -940,6 +944,94 @@ func AddClusterAutoscalerPermissions(p *Policy) {
 	)
 }
 
+// AddKarpenterPermissions grants the permissions karpenter needs to launch and terminate the instances of provisioned instance groups.
+// The instances are launched from the launch templates of the cluster, tagged with the cluster tag, and with the node role of the cluster.
+func AddKarpenterPermissions(p *Policy, iamPrefix string) {
+	p.clusterTaggedAction.Insert(
+		"ec2:TerminateInstances",
+	)
+	p.unconditionalAction.Insert(
+		"ec2:DescribeAvailabilityZones",
+		"ec2:DescribeInstanceTypeOfferings",
+		"ec2:DescribeInstanceTypes",
+		"ec2:DescribeInstances",
+		"ec2:DescribeLaunchTemplates",
+		"ec2:DescribeSecurityGroups",
+		"ec2:DescribeSubnets",
+		"ssm:GetParameter",
+	)
+	p.Statement = append(p.Statement,
+		&Statement{
+			Effect: StatementEffectAllow,
+			Action: stringorslice.Of("ec2:CreateFleet", "ec2:RunInstances"),
+			Resource: stringorslice.Slice([]string{
+				iamPrefix + ":ec2:*:*:launch-template/*",
+			}),
+			Condition: Condition{
+				"StringEquals": map[string]string{
+					"aws:ResourceTag/KubernetesCluster": p.clusterName,
+				},
+			},
+		},
+		&Statement{
+			Effect: StatementEffectAllow,
+			Action: stringorslice.Of("ec2:CreateFleet", "ec2:RunInstances"),
+			Resource: stringorslice.Slice([]string{
+				iamPrefix + ":ec2:*:*:fleet/*",
+				iamPrefix + ":ec2:*:*:instance/*",
+				iamPrefix + ":ec2:*:*:network-interface/*",
+				iamPrefix + ":ec2:*:*:spot-instances-request/*",
+				iamPrefix + ":ec2:*:*:volume/*",
+			}),
+			Condition: Condition{
+				"StringEquals": map[string]string{
+					"aws:RequestTag/KubernetesCluster": p.clusterName,
+				},
+			},
+		},
+		&Statement{
+			Effect: StatementEffectAllow,
+			Action: stringorslice.Of("ec2:CreateFleet", "ec2:RunInstances"),
+			Resource: stringorslice.Slice([]string{
+				iamPrefix + ":ec2:*::image/*",
+				iamPrefix + ":ec2:*::snapshot/*",
+				iamPrefix + ":ec2:*:*:security-group/*",
+				iamPrefix + ":ec2:*:*:subnet/*",
+			}),
+		},
+		&Statement{
+			Effect: StatementEffectAllow,
+			Action: stringorslice.Of("ec2:CreateTags"),
+			Resource: stringorslice.Slice([]string{
+				iamPrefix + ":ec2:*:*:fleet/*",
+				iamPrefix + ":ec2:*:*:instance/*",
+				iamPrefix + ":ec2:*:*:network-interface/*",
+				iamPrefix + ":ec2:*:*:spot-instances-request/*",
+				iamPrefix + ":ec2:*:*:volume/*",
+			}),
+			Condition: Condition{
+				"StringEquals": map[string]interface{}{
+					"aws:RequestTag/KubernetesCluster": p.clusterName,
+					"ec2:CreateAction": []string{
+						"CreateFleet",
+						"RunInstances",
+					},
+				},
+			},
+		},
+		&Statement{
+			Effect:   StatementEffectAllow,
+			Action:   stringorslice.Of("iam:PassRole"),
+			Resource: stringorslice.String(iamPrefix + ":iam::*:role/nodes." + p.clusterName),
+			Condition: Condition{
+				"StringEquals": map[string]string{
+					"iam:PassedToService": "ec2.amazonaws.com",
+				},
+			},
+		},
+	)
+}
+
 // AddAWSEBSCSIDriverPermissions appens policy statements that the AWS EBS CSI Driver needs to operate.
 func AddAWSEBSCSIDriverPermissions(p *Policy, appendSnapshotPermissions bool) {
 
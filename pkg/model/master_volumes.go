@@ -58,6 +58,11 @@ type MasterVolumeBuilder struct {
 var _ fi.ModelBuilder = &MasterVolumeBuilder{}
 
 func (b *MasterVolumeBuilder) Build(c *fi.ModelBuilderContext) error {
+	if kops.CloudProviderID(b.Cluster.Spec.CloudProvider) == kops.CloudProviderMetal {
+		// etcd is stored in directories on the disks of the hosts, created by nodeup
+		return nil
+	}
+
 	for _, etcd := range b.Cluster.Spec.EtcdClusters {
 		for _, m := range etcd.Members {
 			// EBS volume for each member of the each etcd cluster
@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metalmodel
+
+import (
+	"fmt"
+
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metaltasks"
+	"k8s.io/kops/upup/pkg/fi/fitasks"
+	"sigs.k8s.io/yaml"
+)
+
+// HostModelBuilder configures the pre-provisioned hosts of the instance groups
+type HostModelBuilder struct {
+	*model.KopsModelContext
+
+	BootstrapScriptBuilder *model.BootstrapScriptBuilder
+	Lifecycle              fi.Lifecycle
+}
+
+var _ fi.ModelBuilder = &HostModelBuilder{}
+
+func (b *HostModelBuilder) Build(c *fi.ModelBuilderContext) error {
+	for _, ig := range b.InstanceGroups {
+		if len(ig.Spec.Hosts) == 0 {
+			continue
+		}
+
+		// The hosts of an instance group share its bootstrap script
+		userData, err := b.BootstrapScriptBuilder.ResourceNodeUp(c, ig)
+		if err != nil {
+			return err
+		}
+
+		for _, host := range ig.Spec.Hosts {
+			t := &metaltasks.Host{
+				Name:      fi.String(host.Name),
+				Lifecycle: b.Lifecycle,
+
+				Address:      fi.String(host.Address),
+				SSHPort:      host.SSHPort,
+				UserData:     userData,
+				NodeUpAssets: b.BootstrapScriptBuilder.NodeUpAssets,
+			}
+			if host.SSHUser != "" {
+				t.SSHUser = fi.String(host.SSHUser)
+			}
+			c.AddTask(t)
+		}
+	}
+
+	// The inventory lets the hosts be found from the state store, e.g. for kops export kubecfg
+	inventory, err := yaml.Marshal(metal.BuildInventory(b.InstanceGroups))
+	if err != nil {
+		return fmt.Errorf("error building inventory: %v", err)
+	}
+	c.AddTask(&fitasks.ManagedFile{
+		Name:      fi.String("metal-inventory"),
+		Lifecycle: b.Lifecycle,
+		Base:      fi.String(b.Cluster.Spec.ConfigBase),
+		Location:  fi.String(metal.PathInventory),
+		Contents:  fi.NewBytesResource(inventory),
+	})
+
+	return nil
+}
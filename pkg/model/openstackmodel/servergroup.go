@@ -24,7 +24,6 @@ import (
 
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/apis/kops"
-	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
@@ -280,7 +279,7 @@ func (b *ServerGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		}
 		c.AddTask(lbfipTask)
 
-		if dns.IsGossipHostname(b.Cluster.Name) || b.UsePrivateDNS() {
+		if b.Cluster.IsGossip() || b.Cluster.IsMigratingFromGossip() || b.UsePrivateDNS() {
 			b.associateFIPToKeypair(lbfipTask)
 		}
 
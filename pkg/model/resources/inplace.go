@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	clusterSpecFile = "cluster_spec.yaml"
+	kubeEnvFile     = "kube_env.yaml"
+
+	nodeupConfigHashField            = "NodeupConfigHash"
+	nodeupConfigReplacementHashField = "NodeupConfigReplacementHash"
+)
+
+// inPlaceClusterSpecFields are the fields of the cluster spec embedded in the userdata
+// that nodeup can apply on a running instance
+var inPlaceClusterSpecFields = []string{"kubelet", "masterKubelet", "kubeProxy"}
+
+// NodeupConfigReplacementHash returns a hash of the nodeup config without the fields that nodeup can apply
+// on a running instance, these being the kubelet settings, the file assets and the sysctls.
+// Any other change, such as new Kubernetes or containerd assets, changes the hash and requires replacing the instance.
+func NodeupConfigReplacementHash(config *nodeup.Config) (string, error) {
+	replaced := *config
+	replaced.KubeletConfig = kops.KubeletConfigSpec{}
+	replaced.FileAssets = nil
+	replaced.SysctlParameters = nil
+
+	data, err := json.Marshal(&replaced)
+	if err != nil {
+		return "", fmt.Errorf("error converting nodeup config to json: %v", err)
+	}
+	sum256 := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(sum256[:]), nil
+}
+
+var (
+	compressedConfigRegexp = regexp.MustCompile(`^echo "([^"]*)" \| base64 -d \| gzip -d > conf/([a-z_]+\.yaml)$`)
+	heredocConfigRegexp    = regexp.MustCompile(`^cat > conf/([a-z_]+\.yaml) << '([A-Z_]+)'$`)
+)
+
+// nodeUpUserData is the userdata of the nodeup bootstrap script, split in the script and the embedded config files
+type nodeUpUserData struct {
+	script string
+	files  map[string]string
+}
+
+// InPlaceUserDataChange compares the userdata of two nodeup bootstrap scripts.
+// It returns true if the new userdata only differs from the old one in changes that nodeup can apply
+// on a running instance, these being the kubelet settings, file assets and sysctls of the nodeup config
+// and the kubelet and kube-proxy settings, together with the hash of the nodeup config of the new userdata.
+func InPlaceUserDataChange(oldUserData, newUserData string) (bool, string, error) {
+	oldData, err := parseNodeUpUserData(oldUserData)
+	if err != nil {
+		return false, "", err
+	}
+	newData, err := parseNodeUpUserData(newUserData)
+	if err != nil {
+		return false, "", err
+	}
+
+	if _, found := newData.files[kubeEnvFile]; !found {
+		return false, "", nil
+	}
+	if oldData.script != newData.script {
+		return false, "", nil
+	}
+
+	oldKubeEnv, err := unmarshalConfigFile(oldData.files[kubeEnvFile])
+	if err != nil {
+		return false, "", err
+	}
+	newKubeEnv, err := unmarshalConfigFile(newData.files[kubeEnvFile])
+	if err != nil {
+		return false, "", err
+	}
+	if replacementHash, _ := newKubeEnv[nodeupConfigReplacementHashField].(string); replacementHash == "" {
+		// Without the hash, other changes to the nodeup config can't be told apart
+		return false, "", nil
+	}
+	hash, _ := newKubeEnv[nodeupConfigHashField].(string)
+	delete(oldKubeEnv, nodeupConfigHashField)
+	delete(newKubeEnv, nodeupConfigHashField)
+	if !reflect.DeepEqual(oldKubeEnv, newKubeEnv) {
+		return false, "", nil
+	}
+
+	oldClusterSpec, err := unmarshalConfigFile(oldData.files[clusterSpecFile])
+	if err != nil {
+		return false, "", err
+	}
+	newClusterSpec, err := unmarshalConfigFile(newData.files[clusterSpecFile])
+	if err != nil {
+		return false, "", err
+	}
+	for _, field := range inPlaceClusterSpecFields {
+		delete(oldClusterSpec, field)
+		delete(newClusterSpec, field)
+	}
+	if !reflect.DeepEqual(oldClusterSpec, newClusterSpec) {
+		return false, "", nil
+	}
+
+	return true, hash, nil
+}
+
+// parseNodeUpUserData extracts the config files written by the bootstrap script, whether compressed or not
+func parseNodeUpUserData(userData string) (*nodeUpUserData, error) {
+	data := &nodeUpUserData{
+		files: make(map[string]string),
+	}
+
+	var script []string
+	lines := strings.Split(strings.ReplaceAll(userData, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := compressedConfigRegexp.FindStringSubmatch(line); m != nil {
+			contents, err := gunzipBase64(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("error decoding %s from userdata: %v", m[2], err)
+			}
+			data.files[m[2]] = contents
+			script = append(script, "conf/"+m[2])
+			continue
+		}
+
+		if m := heredocConfigRegexp.FindStringSubmatch(line); m != nil {
+			var contents []string
+			for i++; i < len(lines) && lines[i] != m[2]; i++ {
+				contents = append(contents, lines[i])
+			}
+			if i == len(lines) {
+				return nil, fmt.Errorf("unterminated %s in userdata", m[1])
+			}
+			data.files[m[1]] = strings.Join(contents, "\n")
+			script = append(script, "conf/"+m[1])
+			continue
+		}
+
+		script = append(script, line)
+	}
+	data.script = strings.Join(script, "\n")
+
+	return data, nil
+}
+
+func unmarshalConfigFile(contents string) (map[string]interface{}, error) {
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(contents), &config); err != nil {
+		return nil, fmt.Errorf("error parsing config from userdata: %v", err)
+	}
+	return config, nil
+}
+
+func gunzipBase64(data string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	defer gz.Close()
+	contents, err := ioutil.ReadAll(gz)
+	if err != nil {
+		return "", err
+	}
+	return string(contents), nil
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
+)
+
+const (
+	testClusterSpec = `containerRuntime: containerd
+kubelet:
+  maxPods: 100
+`
+	testKubeEnv = `CloudProvider: aws
+InstanceGroupName: nodes
+InstanceGroupRole: Node
+NodeupConfigHash: %s
+NodeupConfigReplacementHash: replacementhash
+`
+)
+
+func buildTestUserData(t *testing.T, compress bool, nodeupVersion, clusterSpec, kubeEnv string) string {
+	userData := "#!/bin/bash\nNODEUP_URL=" + nodeupVersion + "\n\n"
+	if compress {
+		userData += fmt.Sprintf("echo \"%s\" | base64 -d | gzip -d > conf/cluster_spec.yaml\n\n", testGzipBase64(t, clusterSpec))
+		userData += fmt.Sprintf("echo \"%s\" | base64 -d | gzip -d > conf/kube_env.yaml\n", testGzipBase64(t, kubeEnv))
+	} else {
+		userData += "cat > conf/cluster_spec.yaml << '__EOF_CLUSTER_SPEC'\n" + clusterSpec + "\n__EOF_CLUSTER_SPEC\n\n"
+		userData += "cat > conf/kube_env.yaml << '__EOF_KUBE_ENV'\n" + kubeEnv + "\n__EOF_KUBE_ENV\n"
+	}
+	return userData + "\ndownload-release\n"
+}
+
+func testGzipBase64(t *testing.T, data string) string {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatalf("error compressing: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("error compressing: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func TestInPlaceUserDataChange(t *testing.T) {
+	oldKubeEnv := fmt.Sprintf(testKubeEnv, "oldhash")
+	newKubeEnv := fmt.Sprintf(testKubeEnv, "newhash")
+
+	for _, test := range []struct {
+		name          string
+		nodeupVersion string
+		clusterSpec   string
+		kubeEnv       string
+		inPlace       bool
+	}{
+		{
+			name:          "nodeup config changed",
+			nodeupVersion: "1.22.0",
+			clusterSpec:   testClusterSpec,
+			kubeEnv:       newKubeEnv,
+			inPlace:       true,
+		},
+		{
+			name:          "kubelet changed",
+			nodeupVersion: "1.22.0",
+			clusterSpec:   "containerRuntime: containerd\nkubelet:\n  maxPods: 110\n",
+			kubeEnv:       newKubeEnv,
+			inPlace:       true,
+		},
+		{
+			name:          "container runtime changed",
+			nodeupVersion: "1.22.0",
+			clusterSpec:   "containerRuntime: docker\nkubelet:\n  maxPods: 100\n",
+			kubeEnv:       newKubeEnv,
+		},
+		{
+			name:          "nodeup config replacement changed",
+			nodeupVersion: "1.22.0",
+			clusterSpec:   testClusterSpec,
+			kubeEnv:       "CloudProvider: aws\nInstanceGroupName: nodes\nInstanceGroupRole: Node\nNodeupConfigHash: newhash\nNodeupConfigReplacementHash: otherhash\n",
+		},
+		{
+			name:          "nodeup config without replacement hash",
+			nodeupVersion: "1.22.0",
+			clusterSpec:   testClusterSpec,
+			kubeEnv:       "CloudProvider: aws\nInstanceGroupName: nodes\nInstanceGroupRole: Node\nNodeupConfigHash: newhash\n",
+		},
+		{
+			name:          "boot config changed",
+			nodeupVersion: "1.22.0",
+			clusterSpec:   testClusterSpec,
+			kubeEnv:       "CloudProvider: aws\nInstanceGroupName: other\nInstanceGroupRole: Node\nNodeupConfigHash: newhash\nNodeupConfigReplacementHash: replacementhash\n",
+		},
+		{
+			name:          "script changed",
+			nodeupVersion: "1.22.1",
+			clusterSpec:   testClusterSpec,
+			kubeEnv:       newKubeEnv,
+		},
+	} {
+		for _, compress := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s compressed=%v", test.name, compress), func(t *testing.T) {
+				oldUserData := buildTestUserData(t, compress, "1.22.0", testClusterSpec, oldKubeEnv)
+				newUserData := buildTestUserData(t, compress, test.nodeupVersion, test.clusterSpec, test.kubeEnv)
+
+				inPlace, hash, err := InPlaceUserDataChange(oldUserData, newUserData)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if inPlace != test.inPlace {
+					t.Errorf("expected in-place %v, got %v", test.inPlace, inPlace)
+				}
+				if inPlace && hash != "newhash" {
+					t.Errorf("expected hash %q, got %q", "newhash", hash)
+				}
+			})
+		}
+	}
+}
+
+func TestInPlaceUserDataChangeBastion(t *testing.T) {
+	inPlace, _, err := InPlaceUserDataChange("#!/bin/bash\necho old\n", "#!/bin/bash\necho new\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inPlace {
+		t.Errorf("userdata without a nodeup config should not be updated in place")
+	}
+}
+
+func TestNodeupConfigReplacementHash(t *testing.T) {
+	buildConfig := func() *nodeup.Config {
+		return &nodeup.Config{
+			Assets: map[architectures.Architecture][]string{
+				architectures.ArchitectureAmd64: {"sha@https://storage.googleapis.com/kubernetes-release/release/v1.21.0/bin/linux/amd64/kubelet"},
+			},
+			KubeletConfig:    kops.KubeletConfigSpec{MaxPods: fi.Int32(100)},
+			SysctlParameters: []string{"net.ipv4.ip_forward=1"},
+		}
+	}
+
+	expected, err := NodeupConfigReplacementHash(buildConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		name    string
+		modify  func(config *nodeup.Config)
+		inPlace bool
+	}{
+		{
+			name:    "kubelet changed",
+			modify:  func(config *nodeup.Config) { config.KubeletConfig.MaxPods = fi.Int32(110) },
+			inPlace: true,
+		},
+		{
+			name: "file assets changed",
+			modify: func(config *nodeup.Config) {
+				config.FileAssets = []kops.FileAssetSpec{{Name: "asset", Path: "/etc/asset"}}
+			},
+			inPlace: true,
+		},
+		{
+			name:    "sysctls changed",
+			modify:  func(config *nodeup.Config) { config.SysctlParameters = nil },
+			inPlace: true,
+		},
+		{
+			name: "kubernetes assets changed",
+			modify: func(config *nodeup.Config) {
+				config.Assets[architectures.ArchitectureAmd64] = []string{"sha@https://storage.googleapis.com/kubernetes-release/release/v1.21.1/bin/linux/amd64/kubelet"}
+			},
+		},
+		{
+			name: "containerd changed",
+			modify: func(config *nodeup.Config) {
+				config.ContainerdConfig = &kops.ContainerdConfig{Version: fi.String("1.4.9")}
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := buildConfig()
+			test.modify(config)
+			hash, err := NodeupConfigReplacementHash(config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (hash == expected) != test.inPlace {
+				t.Errorf("expected in-place %v, got hash %q for %q", test.inPlace, hash, expected)
+			}
+		})
+	}
+}
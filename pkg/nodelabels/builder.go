@@ -33,6 +33,9 @@ const (
 	RoleLabelNode16      = "node-role.kubernetes.io/node"
 
 	RoleLabelControlPlane20 = "node-role.kubernetes.io/control-plane"
+
+	// RuntimeHandlerLabelPrefix is the prefix of the labels for the containerd runtime handlers available on a node
+	RuntimeHandlerLabelPrefix = "runtime.kops.k8s.io/"
 )
 
 // BuildNodeLabels returns the node labels for the specified instance group
@@ -81,6 +84,15 @@ func BuildNodeLabels(cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) m
 		}
 	}
 
+	if cluster.Spec.ContainerRuntime == "containerd" {
+		for _, runtime := range cluster.Spec.Containerd.ResolveRuntimes(instanceGroup) {
+			if nodeLabels == nil {
+				nodeLabels = make(map[string]string)
+			}
+			nodeLabels[RuntimeHandlerLabelPrefix+runtime.Name] = "true"
+		}
+	}
+
 	for k, v := range instanceGroup.Spec.NodeLabels {
 		if nodeLabels == nil {
 			nodeLabels = make(map[string]string)
@@ -111,6 +111,35 @@ func TestBuildNodeLabels(t *testing.T) {
 				"node3":         "override3",
 			},
 		},
+		{
+			name: "ContainerdRuntimes",
+			cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KubernetesVersion: "v1.21.0",
+					ContainerRuntime:  "containerd",
+					Containerd: &kops.ContainerdConfig{
+						Runtimes: []kops.ContainerdRuntimeSpec{
+							{Name: "gvisor", Type: kops.ContainerdRuntimeTypeGVisor},
+						},
+					},
+				},
+			},
+			ig: &kops.InstanceGroup{
+				Spec: kops.InstanceGroupSpec{
+					Role: kops.InstanceGroupRoleNode,
+					Containerd: &kops.ContainerdConfig{
+						Runtimes: []kops.ContainerdRuntimeSpec{
+							{Name: "kata", Type: kops.ContainerdRuntimeTypeKata},
+						},
+					},
+				},
+			},
+			expected: map[string]string{
+				RoleLabelNode16:                    "",
+				RoleLabelName15:                    RoleNodeLabelValue15,
+				RuntimeHandlerLabelPrefix + "kata": "true",
+			},
+		},
 	}
 
 	for _, test := range tests {
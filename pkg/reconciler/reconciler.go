@@ -0,0 +1,257 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple/crdclientset"
+)
+
+// ApplyFunc applies the changes to a cluster to the cloud, as "kops update cluster --yes" does
+type ApplyFunc func(ctx context.Context, cluster *kops.Cluster) error
+
+// ClusterReconciler applies the changes to the clusters stored as custom resources,
+// and reports the progress in the status of the Cluster resources.
+type ClusterReconciler struct {
+	Clientset *crdclientset.CRDClientset
+	Apply     ApplyFunc
+
+	// RetryInterval is the interval after which the changes to a cluster that could not be applied are retried
+	RetryInterval time.Duration
+
+	// now returns the current time, it can be overridden in tests
+	now func() time.Time
+}
+
+// NewClusterReconciler builds a ClusterReconciler
+func NewClusterReconciler(clientset *crdclientset.CRDClientset, apply ApplyFunc, retryInterval time.Duration) *ClusterReconciler {
+	return &ClusterReconciler{
+		Clientset:     clientset,
+		Apply:         apply,
+		RetryInterval: retryInterval,
+		now:           time.Now,
+	}
+}
+
+// Run reconciles the clusters each time a Cluster or an InstanceGroup resource changes, and at least every resyncInterval
+func (r *ClusterReconciler) Run(ctx context.Context, resyncInterval time.Duration) error {
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	namespace := r.Clientset.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceAll
+	}
+	go r.watch(ctx, "clusters", func() (watch.Interface, error) {
+		return r.Clientset.KopsClient.Clusters(namespace).Watch(ctx, metav1.ListOptions{})
+	}, notify)
+	go r.watch(ctx, "instance groups", func() (watch.Interface, error) {
+		return r.Clientset.KopsClient.InstanceGroups(namespace).Watch(ctx, metav1.ListOptions{})
+	}, notify)
+
+	for {
+		if err := r.ReconcileAll(ctx); err != nil {
+			klog.Warningf("error reconciling clusters: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changes:
+		case <-time.After(resyncInterval):
+		}
+	}
+}
+
+// watch calls notify on each change to the watched resources, restarting the watch when it is closed
+func (r *ClusterReconciler) watch(ctx context.Context, kind string, start func() (watch.Interface, error), notify func()) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		w, err := start()
+		if err != nil {
+			klog.Warningf("error watching %s: %v", kind, err)
+			return
+		}
+		defer w.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				if event.Type != watch.Bookmark {
+					notify()
+				}
+			}
+		}
+	}, 10*time.Second)
+}
+
+// ReconcileAll applies the changes to all the clusters which were not applied yet, one cluster at a time.
+// The clusters waiting for their changes to be applied are marked as Pending first.
+func (r *ClusterReconciler) ReconcileAll(ctx context.Context) error {
+	list, err := r.Clientset.ListClusters(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing clusters: %v", err)
+	}
+
+	type work struct {
+		cluster  *kops.Cluster
+		observed map[string]int64
+	}
+	var queue []work
+	for i := range list.Items {
+		cluster := &list.Items[i]
+		observed, err := r.observedInstanceGroups(ctx, cluster)
+		if err != nil {
+			klog.Warningf("error listing instance groups of cluster %q: %v", cluster.Name, err)
+			continue
+		}
+		if !r.needsApply(cluster, observed) {
+			continue
+		}
+
+		if cluster.Status == nil || cluster.Status.Phase != kops.ReconcilePhasePending {
+			cluster, err = r.setStatus(ctx, cluster, kops.ReconcilePhasePending, "Waiting for the changes to be applied")
+			if err != nil {
+				klog.Warningf("%v", err)
+				continue
+			}
+		}
+		queue = append(queue, work{cluster: cluster, observed: observed})
+	}
+
+	for _, w := range queue {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.reconcile(ctx, w.cluster, w.observed); err != nil {
+			klog.Warningf("%v", err)
+		}
+	}
+	return nil
+}
+
+// Reconcile applies the changes to the cluster if they were not applied yet
+func (r *ClusterReconciler) Reconcile(ctx context.Context, cluster *kops.Cluster) error {
+	observed, err := r.observedInstanceGroups(ctx, cluster)
+	if err != nil {
+		return fmt.Errorf("error listing instance groups of cluster %q: %v", cluster.Name, err)
+	}
+	if !r.needsApply(cluster, observed) {
+		return nil
+	}
+	return r.reconcile(ctx, cluster, observed)
+}
+
+func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *kops.Cluster, observed map[string]int64) error {
+	generation := cluster.Generation
+
+	klog.Infof("applying changes to cluster %q", cluster.Name)
+	cluster, err := r.setStatus(ctx, cluster, kops.ReconcilePhaseApplying, "Applying the changes to the cloud")
+	if err != nil {
+		return err
+	}
+
+	if err := r.Apply(ctx, cluster); err != nil {
+		klog.Warningf("error applying changes to cluster %q: %v", cluster.Name, err)
+		if _, err := r.setStatus(ctx, cluster, kops.ReconcilePhaseFailed, err.Error()); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	klog.Infof("applied changes to cluster %q", cluster.Name)
+	now := metav1.NewTime(r.now())
+	cluster.Status.ObservedGeneration = generation
+	cluster.Status.ObservedInstanceGroups = observed
+	cluster.Status.LastAppliedTime = &now
+	if _, err := r.setStatus(ctx, cluster, kops.ReconcilePhaseReady, "The changes were applied"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// needsApply returns true if the cluster or its instance groups changed since they were last applied,
+// or if the changes failed to apply and should be retried.
+func (r *ClusterReconciler) needsApply(cluster *kops.Cluster, observed map[string]int64) bool {
+	status := cluster.Status
+	if status == nil || status.ObservedGeneration != cluster.Generation {
+		return true
+	}
+	if len(status.ObservedInstanceGroups) != 0 || len(observed) != 0 {
+		if !reflect.DeepEqual(status.ObservedInstanceGroups, observed) {
+			return true
+		}
+	}
+
+	switch status.Phase {
+	case kops.ReconcilePhaseReady:
+		return false
+	case kops.ReconcilePhaseFailed:
+		return status.LastUpdateTime == nil || r.now().Sub(status.LastUpdateTime.Time) >= r.RetryInterval
+	default:
+		// The controller stopped while the changes were pending or being applied
+		return true
+	}
+}
+
+// observedInstanceGroups returns the generation of each instance group of the cluster
+func (r *ClusterReconciler) observedInstanceGroups(ctx context.Context, cluster *kops.Cluster) (map[string]int64, error) {
+	igs, err := r.Clientset.InstanceGroupsFor(cluster).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	observed := make(map[string]int64)
+	for _, ig := range igs.Items {
+		observed[ig.Name] = ig.Generation
+	}
+	return observed, nil
+}
+
+func (r *ClusterReconciler) setStatus(ctx context.Context, cluster *kops.Cluster, phase kops.ReconcilePhase, message string) (*kops.Cluster, error) {
+	cluster = cluster.DeepCopy()
+	if cluster.Status == nil {
+		cluster.Status = &kops.ReconcileStatus{}
+	}
+	now := metav1.NewTime(r.now())
+	cluster.Status.Phase = phase
+	cluster.Status.Message = message
+	cluster.Status.LastUpdateTime = &now
+
+	updated, err := r.Clientset.UpdateClusterStatus(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
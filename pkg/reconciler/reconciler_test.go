@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kops/pkg/client/simple/crdclientset"
+	"k8s.io/kops/pkg/testutils"
+)
+
+type testApplier struct {
+	applied []string
+	fail    map[string]error
+}
+
+func (a *testApplier) apply(ctx context.Context, cluster *kops.Cluster) error {
+	if cluster.Status == nil || cluster.Status.Phase != kops.ReconcilePhaseApplying {
+		return fmt.Errorf("expected cluster %q to be applying, got %v", cluster.Name, cluster.Status)
+	}
+	a.applied = append(a.applied, cluster.Name)
+	return a.fail[cluster.Name]
+}
+
+func (a *testApplier) reset() []string {
+	applied := a.applied
+	sort.Strings(applied)
+	a.applied = nil
+	return applied
+}
+
+func TestReconcileAll(t *testing.T) {
+	ctx := context.TODO()
+
+	kopsClient := fake.NewSimpleClientset()
+	clientset := crdclientset.NewCRDClientset(kopsClient.KopsV1alpha2(), "kops", nil)
+	applier := &testApplier{fail: map[string]error{}}
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	r := NewClusterReconciler(clientset, applier.apply, 10*time.Minute)
+	r.now = func() time.Time { return now }
+
+	a := testutils.BuildMinimalCluster("a.example.com")
+	b := testutils.BuildMinimalCluster("b.example.com")
+	for _, cluster := range []*kops.Cluster{a, b} {
+		if _, err := clientset.CreateCluster(ctx, cluster); err != nil {
+			t.Fatalf("error creating cluster: %v", err)
+		}
+	}
+	ig := testutils.BuildMinimalNodeInstanceGroup("nodes", "subnet-us-mock-1a")
+	if _, err := clientset.InstanceGroupsFor(a).Create(ctx, &ig, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("error creating instance group: %v", err)
+	}
+	applier.fail["b.example.com"] = fmt.Errorf("quota exceeded")
+
+	status := func(name string) *kops.ReconcileStatus {
+		cluster, err := clientset.GetCluster(ctx, name)
+		if err != nil {
+			t.Fatalf("error getting cluster: %v", err)
+		}
+		return cluster.Status
+	}
+
+	// New clusters are applied
+	if err := r.ReconcileAll(ctx); err != nil {
+		t.Fatalf("error reconciling: %v", err)
+	}
+	if applied := applier.reset(); !reflect.DeepEqual(applied, []string{"a.example.com", "b.example.com"}) {
+		t.Errorf("unexpected clusters applied: %v", applied)
+	}
+	if s := status("a.example.com"); s.Phase != kops.ReconcilePhaseReady || s.LastAppliedTime == nil || !reflect.DeepEqual(s.ObservedInstanceGroups, map[string]int64{"nodes": 0}) {
+		t.Errorf("unexpected status of cluster a: %v", s)
+	}
+	if s := status("b.example.com"); s.Phase != kops.ReconcilePhaseFailed || s.Message != "quota exceeded" || s.LastAppliedTime != nil {
+		t.Errorf("unexpected status of cluster b: %v", s)
+	}
+
+	// Nothing changed, and failed clusters are not retried before the retry interval
+	now = now.Add(time.Minute)
+	if err := r.ReconcileAll(ctx); err != nil {
+		t.Fatalf("error reconciling: %v", err)
+	}
+	if applied := applier.reset(); len(applied) != 0 {
+		t.Errorf("unexpected clusters applied: %v", applied)
+	}
+
+	// A change to an instance group is applied, failed clusters are retried
+	now = now.Add(10 * time.Minute)
+	ig2, err := kopsClient.KopsV1alpha2().InstanceGroups("kops").Get(ctx, "nodes", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting instance group: %v", err)
+	}
+	ig2.Generation = 2
+	if _, err := kopsClient.KopsV1alpha2().InstanceGroups("kops").Update(ctx, ig2, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("error updating instance group: %v", err)
+	}
+	delete(applier.fail, "b.example.com")
+	if err := r.ReconcileAll(ctx); err != nil {
+		t.Fatalf("error reconciling: %v", err)
+	}
+	if applied := applier.reset(); !reflect.DeepEqual(applied, []string{"a.example.com", "b.example.com"}) {
+		t.Errorf("unexpected clusters applied: %v", applied)
+	}
+	if s := status("b.example.com"); s.Phase != kops.ReconcilePhaseReady {
+		t.Errorf("unexpected status of cluster b: %v", s)
+	}
+
+	// A change to the cluster spec is applied
+	cluster, err := kopsClient.KopsV1alpha2().Clusters("kops").Get(ctx, "b.example.com", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting cluster: %v", err)
+	}
+	cluster.Generation = 3
+	if _, err := kopsClient.KopsV1alpha2().Clusters("kops").Update(ctx, cluster, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("error updating cluster: %v", err)
+	}
+	if err := r.ReconcileAll(ctx); err != nil {
+		t.Fatalf("error reconciling: %v", err)
+	}
+	if applied := applier.reset(); !reflect.DeepEqual(applied, []string{"b.example.com"}) {
+		t.Errorf("unexpected clusters applied: %v", applied)
+	}
+	if s := status("b.example.com"); s.ObservedGeneration != 3 {
+		t.Errorf("unexpected status of cluster b: %v", s)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/route53"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/resources"
@@ -64,10 +65,12 @@ func ListResourcesAWS(cloud awsup.AWSCloud, clusterName string) (map[string]*res
 		ListAutoScalingGroups,
 		ListInstances,
 		ListKeypairs,
+		ListPlacementGroups,
 		ListSecurityGroups,
 		ListVolumes,
 		// EC2 VPC
 		ListDhcpOptions,
+		ListEgressOnlyInternetGateways,
 		ListInternetGateways,
 		ListRouteTables,
 		ListSubnets,
@@ -101,6 +104,9 @@ func ListResourcesAWS(cloud awsup.AWSCloud, clusterName string) (map[string]*res
 			return nil, err
 		}
 		for _, t := range rt {
+			if t.MatchReason == "" {
+				t.MatchReason = resources.MatchReasonTag
+			}
 			resourceTrackers[t.Type+":"+t.ID] = t
 		}
 	}
@@ -130,6 +136,8 @@ func ListResourcesAWS(cloud awsup.AWSCloud, clusterName string) (map[string]*res
 						Type:    "internet-gateway",
 						Deleter: DeleteInternetGateway,
 						Shared:  vpc.Shared, // Shared iff the VPC is shared
+
+						MatchReason: resources.MatchReasonVPC,
 					}
 				}
 			}
@@ -151,6 +159,7 @@ func ListResourcesAWS(cloud awsup.AWSCloud, clusterName string) (map[string]*res
 			return nil, err
 		}
 		for _, t := range lts {
+			t.MatchReason = resources.MatchReasonTag
 			resourceTrackers[t.Type+":"+t.ID] = t
 		}
 	}
@@ -175,6 +184,7 @@ func ListResourcesAWS(cloud awsup.AWSCloud, clusterName string) (map[string]*res
 		}
 
 		for _, t := range natGateways {
+			t.MatchReason = resources.MatchReasonVPC
 			resourceTrackers[t.Type+":"+t.ID] = t
 		}
 	}
@@ -187,6 +197,23 @@ func ListResourcesAWS(cloud awsup.AWSCloud, clusterName string) (map[string]*res
 	return resourceTrackers, nil
 }
 
+// ListResourcesAWSForCluster lists the resources of the cluster, including the DNS records of the API names of its spec
+func ListResourcesAWSForCluster(cloud awsup.AWSCloud, cluster *kops.Cluster) (map[string]*resources.Resource, error) {
+	resourceTrackers, err := ListResourcesAWS(cloud, cluster.ObjectMeta.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := ListRoute53RecordsForCluster(cloud, cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range records {
+		resourceTrackers[t.Type+":"+t.ID] = t
+	}
+	return resourceTrackers, nil
+}
+
 func BuildEC2Filters(cloud fi.Cloud) []*ec2.Filter {
 	awsCloud := cloud.(awsup.AWSCloud)
 	tags := awsCloud.Tags()
@@ -199,7 +226,7 @@ func BuildEC2Filters(cloud fi.Cloud) []*ec2.Filter {
 	return filters
 }
 
-func addUntaggedRouteTables(cloud awsup.AWSCloud, clusterName string, resources map[string]*resources.Resource) error {
+func addUntaggedRouteTables(cloud awsup.AWSCloud, clusterName string, resourceTrackers map[string]*resources.Resource) error {
 	// We sometimes have trouble tagging the route table (eventual consistency, e.g. #597)
 	// If we are deleting the VPC, we should delete the route table
 	// (no real reason not to; easy to recreate; no real state etc)
@@ -215,7 +242,7 @@ func addUntaggedRouteTables(cloud awsup.AWSCloud, clusterName string, resources
 			continue
 		}
 
-		if resources["vpc:"+vpcID] == nil || resources["vpc:"+vpcID].Shared {
+		if resourceTrackers["vpc:"+vpcID] == nil || resourceTrackers["vpc:"+vpcID].Shared {
 			// Not deleting this VPC; ignore
 			continue
 		}
@@ -238,8 +265,9 @@ func addUntaggedRouteTables(cloud awsup.AWSCloud, clusterName string, resources
 		}
 
 		t := buildTrackerForRouteTable(rt, clusterName)
-		if resources[t.Type+":"+t.ID] == nil {
-			resources[t.Type+":"+t.ID] = t
+		if resourceTrackers[t.Type+":"+t.ID] == nil {
+			t.MatchReason = resources.MatchReasonVPC
+			resourceTrackers[t.Type+":"+t.ID] = t
 		}
 	}
 
@@ -713,6 +741,57 @@ func ListKeypairs(cloud fi.Cloud, clusterName string) ([]*resources.Resource, er
 			ID:      id,
 			Type:    "keypair",
 			Deleter: DeleteKeypair,
+
+			MatchReason: resources.MatchReasonName,
+		}
+
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func DeletePlacementGroup(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(awsup.AWSCloud)
+
+	name := r.Name
+
+	klog.V(2).Infof("Deleting EC2 PlacementGroup %q", name)
+	request := &ec2.DeletePlacementGroupInput{
+		GroupName: &name,
+	}
+	_, err := c.EC2().DeletePlacementGroup(request)
+	if err != nil {
+		if awsup.AWSErrorCode(err) == "InvalidPlacementGroup.Unknown" {
+			klog.V(2).Infof("Got InvalidPlacementGroup.Unknown error deleting placement group %q; will treat as already-deleted", name)
+			return nil
+		}
+		return fmt.Errorf("error deleting PlacementGroup %q: %v", name, err)
+	}
+	return nil
+}
+
+func ListPlacementGroups(cloud fi.Cloud, clusterName string) ([]*resources.Resource, error) {
+	c := cloud.(awsup.AWSCloud)
+
+	klog.V(2).Infof("Listing EC2 PlacementGroups")
+
+	request := &ec2.DescribePlacementGroupsInput{
+		Filters: BuildEC2Filters(cloud),
+	}
+	response, err := c.EC2().DescribePlacementGroups(request)
+	if err != nil {
+		return nil, fmt.Errorf("error listing PlacementGroups: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+
+	for _, pg := range response.PlacementGroups {
+		resourceTracker := &resources.Resource{
+			Name:    aws.StringValue(pg.GroupName),
+			ID:      aws.StringValue(pg.GroupId),
+			Type:    "placement-group",
+			Deleter: DeletePlacementGroup,
 		}
 
 		resourceTrackers = append(resourceTrackers, resourceTracker)
@@ -1112,6 +1191,72 @@ func DescribeInternetGatewaysIgnoreTags(cloud fi.Cloud) ([]*ec2.InternetGateway,
 	return gateways, nil
 }
 
+func DeleteEgressOnlyInternetGateway(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(awsup.AWSCloud)
+
+	id := r.ID
+
+	klog.V(2).Infof("Deleting EC2 EgressOnlyInternetGateway %q", id)
+	request := &ec2.DeleteEgressOnlyInternetGatewayInput{
+		EgressOnlyInternetGatewayId: &id,
+	}
+	_, err := c.EC2().DeleteEgressOnlyInternetGateway(request)
+	if err != nil {
+		if IsDependencyViolation(err) {
+			return err
+		}
+		if awsup.AWSErrorCode(err) == "InvalidGatewayID.NotFound" {
+			klog.Infof("Egress-only internet gateway %q not found; assuming already deleted", id)
+			return nil
+		}
+		return fmt.Errorf("error deleting EgressOnlyInternetGateway %q: %v", id, err)
+	}
+
+	return nil
+}
+
+func ListEgressOnlyInternetGateways(cloud fi.Cloud, clusterName string) ([]*resources.Resource, error) {
+	c := cloud.(awsup.AWSCloud)
+
+	klog.V(2).Infof("Listing EC2 EgressOnlyInternetGateways")
+	request := &ec2.DescribeEgressOnlyInternetGatewaysInput{
+		Filters: BuildEC2Filters(cloud),
+	}
+
+	var gateways []*ec2.EgressOnlyInternetGateway
+	err := c.EC2().DescribeEgressOnlyInternetGatewaysPages(request, func(p *ec2.DescribeEgressOnlyInternetGatewaysOutput, lastPage bool) bool {
+		gateways = append(gateways, p.EgressOnlyInternetGateways...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing EgressOnlyInternetGateways: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+
+	for _, o := range gateways {
+		resourceTracker := &resources.Resource{
+			Name:    FindName(o.Tags),
+			ID:      aws.StringValue(o.EgressOnlyInternetGatewayId),
+			Type:    "egress-only-internet-gateway",
+			Deleter: DeleteEgressOnlyInternetGateway,
+			Shared:  HasSharedTag(ec2.ResourceTypeEgressOnlyInternetGateway+":"+aws.StringValue(o.EgressOnlyInternetGatewayId), o.Tags, clusterName),
+		}
+
+		var blocks []string
+		for _, a := range o.Attachments {
+			if aws.StringValue(a.VpcId) != "" {
+				blocks = append(blocks, "vpc:"+aws.StringValue(a.VpcId))
+			}
+		}
+		resourceTracker.Blocks = blocks
+
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
 func DeleteAutoScalingGroup(cloud fi.Cloud, r *resources.Resource) error {
 	c := cloud.(awsup.AWSCloud)
 
@@ -1729,17 +1874,50 @@ func deleteRoute53Records(cloud fi.Cloud, zone *route53.HostedZone, resourceTrac
 	return nil
 }
 
+// ListRoute53Records lists the records named after the cluster name, for clusters not using gossip DNS
 func ListRoute53Records(cloud fi.Cloud, clusterName string) ([]*resources.Resource, error) {
-	var resourceTrackers []*resources.Resource
+	if dns.IsGossipHostname(clusterName) {
+		return nil, nil
+	}
+	return listRoute53Records(cloud, clusterName, nil)
+}
+
+// ListRoute53RecordsForCluster lists the records of the API names of the cluster spec, together with the records named
+// after the cluster name. After a migration between gossip and DNS, the API names are no longer named after the cluster.
+func ListRoute53RecordsForCluster(cloud fi.Cloud, cluster *kops.Cluster) ([]*resources.Resource, error) {
+	var names []string
+	for _, name := range append([]string{cluster.Spec.MasterPublicName, cluster.Spec.MasterInternalName}, cluster.DNSMigrationPreviousNames()...) {
+		if name != "" && !dns.IsGossipHostname(name) {
+			names = append(names, name)
+		}
+	}
 
+	clusterName := cluster.ObjectMeta.Name
 	if dns.IsGossipHostname(clusterName) {
-		return resourceTrackers, nil
+		if len(names) == 0 {
+			return nil, nil
+		}
+		clusterName = ""
 	}
+	return listRoute53Records(cloud, clusterName, names)
+}
+
+// listRoute53Records lists the A and AAAA records named after the cluster name, unless it is empty, and the records with the names
+func listRoute53Records(cloud fi.Cloud, clusterName string, names []string) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
 
 	c := cloud.(awsup.AWSCloud)
 
-	// Normalize cluster name, with leading "."
-	clusterName = "." + strings.TrimSuffix(clusterName, ".")
+	// Normalize the names, with leading "."
+	recordNames := sets.NewString()
+	for _, name := range names {
+		recordNames.Insert("." + strings.TrimSuffix(name, "."))
+	}
+	suffixes := recordNames.List()
+	if clusterName != "" {
+		clusterName = "." + strings.TrimSuffix(clusterName, ".")
+		suffixes = append(suffixes, clusterName)
+	}
 
 	// TODO: If we have the zone id in the cluster spec, use it!
 	var zones []*route53.HostedZone
@@ -1752,8 +1930,11 @@ func ListRoute53Records(cloud fi.Cloud, clusterName string) ([]*resources.Resour
 				zoneName := aws.StringValue(zone.Name)
 				zoneName = "." + strings.TrimSuffix(zoneName, ".")
 
-				if strings.HasSuffix(clusterName, zoneName) {
-					zones = append(zones, zone)
+				for _, suffix := range suffixes {
+					if strings.HasSuffix(suffix, zoneName) {
+						zones = append(zones, zone)
+						break
+					}
 				}
 			}
 			return true
@@ -1782,17 +1963,16 @@ func ListRoute53Records(cloud fi.Cloud, clusterName string) ([]*resources.Resour
 				name := aws.StringValue(rrs.Name)
 				name = "." + strings.TrimSuffix(name, ".")
 
-				if !strings.HasSuffix(name, clusterName) {
-					continue
-				}
-				prefix := strings.TrimSuffix(name, clusterName)
-
-				remove := false
-				// TODO: Compute the actual set of names?
-				if prefix == ".api" || prefix == ".api.internal" || prefix == ".bastion" || prefix == ".kops-controller.internal" {
-					remove = true
-				} else if strings.HasPrefix(prefix, ".etcd-") {
-					remove = true
+				remove := recordNames.Has(name)
+				if clusterName != "" && strings.HasSuffix(name, clusterName) {
+					prefix := strings.TrimSuffix(name, clusterName)
+
+					// TODO: Compute the actual set of names?
+					if prefix == ".api" || prefix == ".api.internal" || prefix == ".bastion" || prefix == ".kops-controller.internal" {
+						remove = true
+					} else if strings.HasPrefix(prefix, ".etcd-") {
+						remove = true
+					}
 				}
 
 				if !remove {
@@ -1807,7 +1987,8 @@ func ListRoute53Records(cloud fi.Cloud, clusterName string) ([]*resources.Resour
 					GroupDeleter: func(cloud fi.Cloud, resourceTrackers []*resources.Resource) error {
 						return deleteRoute53Records(cloud, zone, resourceTrackers)
 					},
-					Obj: rrs,
+					Obj:         rrs,
+					MatchReason: resources.MatchReasonName,
 				}
 				resourceTrackers = append(resourceTrackers, resourceTracker)
 			}
@@ -24,7 +24,11 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/route53"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/cloudmock/aws/mockroute53"
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/resources"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
@@ -257,3 +261,77 @@ func TestMatchesElbTags(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteRoute53RecordsOfMigratedCluster(t *testing.T) {
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	c := &mockroute53.MockRoute53{}
+	cloud.MockRoute53 = c
+
+	zone := &route53.HostedZone{
+		Id:   aws.String("/hostedzone/Z1"),
+		Name: aws.String("example.com."),
+	}
+	c.MockCreateZone(zone, nil)
+	var changes []*route53.Change
+	for _, name := range []string{"api.minimal.example.com.", "api.internal.minimal.example.com.", "other.example.com."} {
+		changes = append(changes, &route53.Change{
+			Action: aws.String("CREATE"),
+			ResourceRecordSet: &route53.ResourceRecordSet{
+				Name: aws.String(name),
+				Type: aws.String("A"),
+			},
+		})
+	}
+	if _, err := c.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: zone.Id,
+		ChangeBatch:  &route53.ChangeBatch{Changes: changes},
+	}); err != nil {
+		t.Fatalf("error creating records: %v", err)
+	}
+
+	// The cluster kept its gossip name after migrating to DNS
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "minimal.k8s.local"},
+		Spec: kops.ClusterSpec{
+			MasterPublicName:   "api.minimal.example.com",
+			MasterInternalName: "api.internal.minimal.example.com",
+		},
+	}
+
+	if records, err := ListRoute53Records(cloud, cluster.ObjectMeta.Name); err != nil {
+		t.Fatalf("error listing records: %v", err)
+	} else if len(records) != 0 {
+		t.Errorf("expected no records named after the gossip cluster name, got %v", records)
+	}
+
+	records, err := ListRoute53RecordsForCluster(cloud, cluster)
+	if err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	var names []string
+	for _, r := range records {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	expected := []string{"api.internal.minimal.example.com.", "api.minimal.example.com."}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected records %v, expected %v", names, expected)
+	}
+
+	if err := records[0].GroupDeleter(cloud, records); err != nil {
+		t.Fatalf("error deleting records: %v", err)
+	}
+
+	var remaining []string
+	if err := c.ListResourceRecordSetsPages(&route53.ListResourceRecordSetsInput{HostedZoneId: zone.Id}, func(p *route53.ListResourceRecordSetsOutput, lastPage bool) bool {
+		for _, rrs := range p.ResourceRecordSets {
+			remaining = append(remaining, aws.StringValue(rrs.Name))
+		}
+		return true
+	}); err != nil {
+		t.Fatalf("error listing records: %v", err)
+	}
+	if !reflect.DeepEqual(remaining, []string{"other.example.com."}) {
+		t.Errorf("unexpected remaining records %v", remaining)
+	}
+}
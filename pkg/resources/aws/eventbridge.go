@@ -103,6 +103,8 @@ func ListEventBridgeRules(cloud fi.Cloud, clusterName string) ([]*resources.Reso
 			Deleter: DeleteEventBridgeRule,
 			Dumper:  DumpEventBridgeRule,
 			Obj:     rule,
+
+			MatchReason: resources.MatchReasonName,
 		}
 
 		resourceTrackers = append(resourceTrackers, resourceTracker)
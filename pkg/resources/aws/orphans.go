@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+// kopsTagKeys match the tags that only kops sets on EC2 resources, such as the role and instance group tags
+// of the instances and launch templates, and the etcd tags of the volumes
+var kopsTagKeys = []string{
+	awsup.TagNameRolePrefix + "*",
+	awsup.TagNameEtcdClusterPrefix + "*",
+	awsup.TagNameKopsRole,
+	"kops.k8s.io/*",
+}
+
+// ListClusterNamesAWS returns the names of the kops clusters found in the tags of the EC2 resources in the region.
+// Clusters are found from both the legacy KubernetesCluster tag and the kubernetes.io/cluster/<name> ownership tags.
+// A cluster is only listed if one of the resources with its cluster tags also has a kops tag,
+// so that the clusters created by other tools, such as EKS, are not mistaken for kops clusters.
+func ListClusterNamesAWS(cloud awsup.AWSCloud) ([]string, error) {
+	klog.V(2).Infof("Listing cluster tags of EC2 resources")
+
+	resourceClusters := make(map[string]sets.String)
+	request := &ec2.DescribeTagsInput{
+		Filters: []*ec2.Filter{
+			awsup.NewEC2Filter("key", awsup.TagClusterName, awsup.TagNameClusterOwnershipPrefix+"*"),
+		},
+	}
+	err := cloud.EC2().DescribeTagsPages(request, func(p *ec2.DescribeTagsOutput, lastPage bool) bool {
+		for _, tag := range p.Tags {
+			name := aws.StringValue(tag.Value)
+			if key := aws.StringValue(tag.Key); strings.HasPrefix(key, awsup.TagNameClusterOwnershipPrefix) {
+				name = strings.TrimPrefix(key, awsup.TagNameClusterOwnershipPrefix)
+			}
+			if name == "" {
+				continue
+			}
+			resourceID := aws.StringValue(tag.ResourceId)
+			if resourceClusters[resourceID] == nil {
+				resourceClusters[resourceID] = sets.NewString()
+			}
+			resourceClusters[resourceID].Insert(name)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing cluster tags: %v", err)
+	}
+
+	clusterNames := sets.NewString()
+	request = &ec2.DescribeTagsInput{
+		Filters: []*ec2.Filter{
+			awsup.NewEC2Filter("key", kopsTagKeys...),
+		},
+	}
+	err = cloud.EC2().DescribeTagsPages(request, func(p *ec2.DescribeTagsOutput, lastPage bool) bool {
+		for _, tag := range p.Tags {
+			if names, found := resourceClusters[aws.StringValue(tag.ResourceId)]; found {
+				clusterNames.Insert(names.List()...)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing kops tags: %v", err)
+	}
+
+	return clusterNames.List(), nil
+}
@@ -81,6 +81,8 @@ func ListSQSQueues(cloud fi.Cloud, clusterName string) ([]*resources.Resource, e
 			Deleter: DeleteSQSQueue,
 			Dumper:  DumpSQSQueue,
 			Obj:     queueUrl,
+
+			MatchReason: resources.MatchReasonName,
 		}
 
 		resourceTrackers = append(resourceTrackers, resourceTracker)
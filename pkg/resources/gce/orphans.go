@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// ListClusterNamesGCE returns the names of the clusters found in the cluster-name metadata of the instance templates in the project
+func ListClusterNamesGCE(gceCloud gce.GCECloud) ([]string, error) {
+	klog.V(2).Infof("Listing cluster names of instance templates")
+
+	templates, err := gceCloud.Compute().InstanceTemplates().List(context.Background(), gceCloud.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing instance templates: %v", err)
+	}
+
+	clusterNames := sets.NewString()
+	for _, t := range templates {
+		if t.Properties == nil || t.Properties.Metadata == nil {
+			continue
+		}
+		for _, item := range t.Properties.Metadata.Items {
+			if item.Key == "cluster-name" {
+				if name := strings.TrimSpace(fi.StringValue(item.Value)); name != "" {
+					clusterNames.Insert(name)
+				}
+			}
+		}
+	}
+
+	return clusterNames.List(), nil
+}
@@ -41,7 +41,7 @@ func ListResources(cloud fi.Cloud, cluster *kops.Cluster, region string) (map[st
 	clusterName := cluster.Name
 	switch cloud.ProviderID() {
 	case kops.CloudProviderAWS:
-		return aws.ListResourcesAWS(cloud.(awsup.AWSCloud), clusterName)
+		return aws.ListResourcesAWSForCluster(cloud.(awsup.AWSCloud), cluster)
 	case kops.CloudProviderDO:
 		return digitalocean.ListResources(cloud.(clouddo.DOCloud), clusterName)
 	case kops.CloudProviderGCE:
@@ -52,6 +52,9 @@ func ListResources(cloud fi.Cloud, cluster *kops.Cluster, region string) (map[st
 		return ali.ListResourcesALI(cloud.(cloudali.ALICloud), clusterName, region)
 	case kops.CloudProviderAzure:
 		return azure.ListResourcesAzure(cloud.(cloudazure.AzureCloud), cluster)
+	case kops.CloudProviderMetal:
+		// The hosts are not owned by kOps, so there are no cloud resources to delete
+		return map[string]*resources.Resource{}, nil
 	default:
 		return nil, fmt.Errorf("delete on clusters on %q not (yet) supported", cloud.ProviderID())
 	}
@@ -29,19 +29,13 @@ import (
 
 // DeleteResources deletes the resources, as previously collected by ListResources
 func DeleteResources(cloud fi.Cloud, resourceMap map[string]*resources.Resource) error {
-	depMap := make(map[string][]string)
+	depMap := buildDependencyMap(resourceMap)
 
 	done := make(map[string]*resources.Resource)
 
 	var mutex sync.Mutex
 
 	for k, t := range resourceMap {
-		for _, block := range t.Blocks {
-			depMap[block] = append(depMap[block], k)
-		}
-
-		depMap[k] = append(depMap[k], t.Blocked...)
-
 		if t.Done {
 			done[k] = t
 		}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/pkg/resources/aws"
+	"k8s.io/kops/pkg/resources/gce"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	cloudgce "k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// ListClusterNames returns the names of the clusters owning resources in the specified cloud
+func ListClusterNames(cloud fi.Cloud) ([]string, error) {
+	switch cloud.ProviderID() {
+	case kops.CloudProviderAWS:
+		return aws.ListClusterNamesAWS(cloud.(awsup.AWSCloud))
+	case kops.CloudProviderGCE:
+		return gce.ListClusterNamesGCE(cloud.(cloudgce.GCECloud))
+	default:
+		return nil, fmt.Errorf("finding clusters on %q not (yet) supported", cloud.ProviderID())
+	}
+}
+
+// CloudForClusterName returns the cloud bound to a cluster known only by its name, for listing and deleting its resources
+func CloudForClusterName(cloud fi.Cloud, clusterName string) fi.Cloud {
+	if awsCloud, ok := cloud.(awsup.AWSCloud); ok {
+		return awsCloud.WithTags(map[string]string{awsup.TagClusterName: clusterName})
+	}
+	return cloud
+}
+
+// ListResourcesForClusterName collects the resources of a cluster known only by its name, such as a cluster missing from the state store
+func ListResourcesForClusterName(cloud fi.Cloud, clusterName string, region string) (map[string]*resources.Resource, error) {
+	switch cloud.ProviderID() {
+	case kops.CloudProviderAWS:
+		return aws.ListResourcesAWS(CloudForClusterName(cloud, clusterName).(awsup.AWSCloud), clusterName)
+	case kops.CloudProviderGCE:
+		return gce.ListResourcesGCE(cloud.(cloudgce.GCECloud), clusterName, region)
+	default:
+		return nil, fmt.Errorf("finding clusters on %q not (yet) supported", cloud.ProviderID())
+	}
+}
+
+// FindOrphanedResources returns the resources owned by clusters which are not in the known clusters, grouped by cluster name.
+// If clusterNames is empty, the clusters are discovered with ListClusterNames. Shared resources are not included.
+func FindOrphanedResources(cloud fi.Cloud, region string, knownClusters []string, clusterNames []string) (map[string]map[string]*resources.Resource, error) {
+	if len(clusterNames) == 0 {
+		var err error
+		clusterNames, err = ListClusterNames(cloud)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, name := range knownClusters {
+		known[name] = true
+	}
+
+	orphans := make(map[string]map[string]*resources.Resource)
+	for _, clusterName := range clusterNames {
+		if known[clusterName] {
+			klog.V(2).Infof("cluster %q found in the state store", clusterName)
+			continue
+		}
+
+		allResources, err := ListResourcesForClusterName(cloud, clusterName, region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing resources of cluster %q: %v", clusterName, err)
+		}
+
+		clusterResources := make(map[string]*resources.Resource)
+		for k, r := range allResources {
+			if !r.Shared {
+				clusterResources[k] = r
+			}
+		}
+		if len(clusterResources) > 0 {
+			orphans[clusterName] = clusterResources
+		}
+	}
+
+	return orphans, nil
+}
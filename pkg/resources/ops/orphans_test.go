@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+func TestFindOrphanedResourcesAWS(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+
+	cloud := h.SetupMockAWS()
+
+	for _, tags := range []map[string]string{
+		{awsup.TagClusterName: "live.example.com", awsup.TagNameClusterOwnershipPrefix + "live.example.com": "owned", awsup.TagNameEtcdClusterPrefix + "main": "a/a"},
+		{awsup.TagClusterName: "orphan.example.com", awsup.TagNameClusterOwnershipPrefix + "orphan.example.com": "owned", awsup.TagNameRolePrefix + "master": "1"},
+		// Clusters created by other tools, without kops tags
+		{awsup.TagNameClusterOwnershipPrefix + "eks.example.com": "owned"},
+		{awsup.TagClusterName: "other.example.com", awsup.TagNameClusterOwnershipPrefix + "other.example.com": "owned"},
+		// Clusters only tagged with the ownership tag
+		{awsup.TagNameClusterOwnershipPrefix + "owned.example.com": "owned", awsup.TagNameRolePrefix + "node": "1"},
+	} {
+		var ec2Tags []*ec2.Tag
+		for k, v := range tags {
+			ec2Tags = append(ec2Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		_, err := cloud.MockEC2.CreateVolume(&ec2.CreateVolumeInput{
+			AvailabilityZone: aws.String("us-test-1a"),
+			TagSpecifications: []*ec2.TagSpecification{
+				{ResourceType: aws.String(ec2.ResourceTypeVolume), Tags: ec2Tags},
+			},
+		})
+		if err != nil {
+			t.Fatalf("error creating volume: %v", err)
+		}
+	}
+
+	clusterNames, err := ListClusterNames(cloud)
+	if err != nil {
+		t.Fatalf("error listing cluster names: %v", err)
+	}
+	expectedNames := []string{"live.example.com", "orphan.example.com", "owned.example.com"}
+	if !reflect.DeepEqual(clusterNames, expectedNames) {
+		t.Errorf("unexpected cluster names, expected %v, got %v", expectedNames, clusterNames)
+	}
+
+	orphans, err := FindOrphanedResources(cloud, "us-test-1", []string{"live.example.com", "minimal.example.com"}, nil)
+	if err != nil {
+		t.Fatalf("error finding orphaned resources: %v", err)
+	}
+
+	actual := make(map[string][]string)
+	for clusterName, clusterResources := range orphans {
+		for k := range clusterResources {
+			actual[clusterName] = append(actual[clusterName], k)
+		}
+		sort.Strings(actual[clusterName])
+	}
+	expected := map[string][]string{
+		"orphan.example.com": {"volume:vol-2"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected orphaned resources, expected %v, got %v", expected, actual)
+	}
+
+	// Clusters named explicitly are not discovered, so they don't need kops tags
+	orphans, err = FindOrphanedResources(cloud, "us-test-1", []string{"live.example.com", "minimal.example.com"}, []string{"other.example.com", "live.example.com"})
+	if err != nil {
+		t.Fatalf("error finding orphaned resources: %v", err)
+	}
+	if len(orphans) != 1 || orphans["other.example.com"]["volume:vol-4"] == nil {
+		t.Errorf("unexpected orphaned resources of named clusters: %v", orphans)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"k8s.io/kops/pkg/resources"
+)
+
+// PlannedResource describes what deleting the cluster does with a resource
+type PlannedResource struct {
+	// Key identifies the resource, as <type>:<id>
+	Key  string `json:"key"`
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// MatchReason records why the resource was considered part of the cluster
+	MatchReason string `json:"matchReason,omitempty"`
+	// Shared is true if the resource is not owned by the cluster
+	Shared bool `json:"shared,omitempty"`
+	// Delete is true if the resource would be deleted
+	Delete bool `json:"delete"`
+	// KeepReason explains why a resource is not deleted
+	KeepReason string `json:"keepReason,omitempty"`
+	// Blocks are the resources which can only be deleted after this resource
+	Blocks []string `json:"blocks,omitempty"`
+	// BlockedBy are the resources which must be deleted before this resource
+	BlockedBy []string `json:"blockedBy,omitempty"`
+}
+
+// DeletionPlan is the preview of the deletion of the cloud resources of a cluster
+type DeletionPlan struct {
+	Resources []*PlannedResource `json:"resources"`
+}
+
+// buildDependencyMap returns, for each resource, the resources that must be deleted before it
+func buildDependencyMap(resourceMap map[string]*resources.Resource) map[string][]string {
+	depMap := make(map[string][]string)
+	for k, t := range resourceMap {
+		for _, block := range t.Blocks {
+			depMap[block] = append(depMap[block], k)
+		}
+
+		depMap[k] = append(depMap[k], t.Blocked...)
+	}
+	return depMap
+}
+
+// matchesKeepSelector returns true if the selector is the type, the id or the <type>:<id> key of the resource
+func matchesKeepSelector(r *resources.Resource, selector string) bool {
+	return selector == r.Type || selector == r.ID || selector == r.Type+":"+r.ID
+}
+
+// BuildDeletionPlan computes which of the resources would be deleted.
+// Shared resources are never deleted, and resources matching one of the keep selectors are not deleted,
+// together with all the resources that can only be deleted after them.
+func BuildDeletionPlan(resourceMap map[string]*resources.Resource, keep []string) (*DeletionPlan, error) {
+	depMap := buildDependencyMap(resourceMap)
+
+	blocks := make(map[string][]string)
+	for k, deps := range depMap {
+		for _, dep := range deps {
+			blocks[dep] = append(blocks[dep], k)
+		}
+	}
+
+	keepReasons := make(map[string]string)
+	for _, selector := range keep {
+		found := false
+		for k, r := range resourceMap {
+			if matchesKeepSelector(r, selector) {
+				keepReasons[k] = fmt.Sprintf("matches --keep %q", selector)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("--keep %q did not match any resource", selector)
+		}
+	}
+
+	// A resource cannot be deleted while a resource it depends on is kept
+	for {
+		changed := false
+		for k := range resourceMap {
+			if _, found := keepReasons[k]; found {
+				continue
+			}
+			for _, dep := range depMap[k] {
+				if _, found := keepReasons[dep]; found {
+					keepReasons[k] = fmt.Sprintf("blocked by kept resource %q", dep)
+					changed = true
+					break
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	plan := &DeletionPlan{}
+	for k, r := range resourceMap {
+		p := &PlannedResource{
+			Key:         k,
+			Type:        r.Type,
+			ID:          r.ID,
+			Name:        r.Name,
+			MatchReason: r.MatchReason,
+			Shared:      r.Shared,
+			Blocks:      uniqueSorted(blocks[k]),
+			BlockedBy:   uniqueSorted(depMap[k]),
+		}
+		if r.Shared {
+			p.KeepReason = "shared"
+		} else {
+			p.KeepReason = keepReasons[k]
+		}
+		p.Delete = p.KeepReason == ""
+		plan.Resources = append(plan.Resources, p)
+	}
+	sort.Slice(plan.Resources, func(i, j int) bool {
+		return plan.Resources[i].Key < plan.Resources[j].Key
+	})
+
+	return plan, nil
+}
+
+// ResourcesToDelete returns the resources the plan would delete
+func (p *DeletionPlan) ResourcesToDelete(resourceMap map[string]*resources.Resource) map[string]*resources.Resource {
+	toDelete := make(map[string]*resources.Resource)
+	for _, r := range p.Resources {
+		if r.Delete {
+			toDelete[r.Key] = resourceMap[r.Key]
+		}
+	}
+	return toDelete
+}
+
+// WriteDot renders the plan as a graphviz graph, with an edge from each resource to the resources it blocks
+func (p *DeletionPlan) WriteDot(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph resources {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, r := range p.Resources {
+		// %q renders the line breaks as the \n escape sequence of graphviz
+		label := r.Type + "\n" + r.ID
+		if r.Name != "" && r.Name != r.ID {
+			label += "\n" + r.Name
+		}
+		if r.MatchReason != "" {
+			label += "\nmatched by " + r.MatchReason
+		}
+
+		var attrs []string
+		attrs = append(attrs, fmt.Sprintf("label=%q", label))
+		if r.Shared {
+			attrs = append(attrs, "style=dashed")
+		} else if !r.Delete {
+			attrs = append(attrs, "style=filled", "fillcolor=lightgrey")
+		}
+		if r.KeepReason != "" {
+			attrs = append(attrs, fmt.Sprintf("tooltip=%q", r.KeepReason))
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", r.Key, strings.Join(attrs, " "))
+	}
+	for _, r := range p.Resources {
+		for _, blocked := range r.Blocks {
+			fmt.Fprintf(&b, "  %q -> %q;\n", r.Key, blocked)
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func uniqueSorted(l []string) []string {
+	if len(l) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var unique []string
+	for _, s := range l {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
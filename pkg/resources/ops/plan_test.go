@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/resources"
+)
+
+func buildTestResources() map[string]*resources.Resource {
+	l := []*resources.Resource{
+		{Type: "vpc", ID: "vpc-1", Shared: true, MatchReason: resources.MatchReasonTag},
+		{Type: "subnet", ID: "subnet-1", Blocks: []string{"vpc:vpc-1"}, MatchReason: resources.MatchReasonTag},
+		{Type: "security-group", ID: "sg-1", Blocks: []string{"vpc:vpc-1"}, MatchReason: resources.MatchReasonTag},
+		{Type: "instance", ID: "i-1", Blocks: []string{"subnet:subnet-1", "security-group:sg-1"}, MatchReason: resources.MatchReasonTag},
+		{Type: "volume", ID: "vol-1", Blocked: []string{"instance:i-1"}, MatchReason: resources.MatchReasonTag},
+		{Type: "keypair", ID: "key-1", Name: "kubernetes.example.com", MatchReason: resources.MatchReasonName},
+	}
+	m := make(map[string]*resources.Resource)
+	for _, r := range l {
+		m[r.Type+":"+r.ID] = r
+	}
+	return m
+}
+
+func TestBuildDeletionPlan(t *testing.T) {
+	grid := []struct {
+		name    string
+		keep    []string
+		deleted []string
+		kept    map[string]string
+	}{
+		{
+			name:    "nothing kept",
+			deleted: []string{"instance:i-1", "keypair:key-1", "security-group:sg-1", "subnet:subnet-1", "volume:vol-1"},
+			kept:    map[string]string{"vpc:vpc-1": "shared"},
+		},
+		{
+			name:    "keep by type",
+			keep:    []string{"keypair"},
+			deleted: []string{"instance:i-1", "security-group:sg-1", "subnet:subnet-1", "volume:vol-1"},
+			kept: map[string]string{
+				"keypair:key-1": `matches --keep "keypair"`,
+				"vpc:vpc-1":     "shared",
+			},
+		},
+		{
+			name:    "keep by id keeps blocked resources",
+			keep:    []string{"i-1"},
+			deleted: []string{"keypair:key-1"},
+			kept: map[string]string{
+				"instance:i-1":        `matches --keep "i-1"`,
+				"security-group:sg-1": `blocked by kept resource "instance:i-1"`,
+				"subnet:subnet-1":     `blocked by kept resource "instance:i-1"`,
+				"volume:vol-1":        `blocked by kept resource "instance:i-1"`,
+				"vpc:vpc-1":           "shared",
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.name, func(t *testing.T) {
+			resourceMap := buildTestResources()
+			plan, err := BuildDeletionPlan(resourceMap, g.keep)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var deleted []string
+			kept := make(map[string]string)
+			for _, r := range plan.Resources {
+				if r.Delete {
+					deleted = append(deleted, r.Key)
+				} else {
+					kept[r.Key] = r.KeepReason
+				}
+			}
+			if !reflect.DeepEqual(deleted, g.deleted) {
+				t.Errorf("unexpected deleted resources, expected %v, got %v", g.deleted, deleted)
+			}
+			if !reflect.DeepEqual(kept, g.kept) {
+				t.Errorf("unexpected kept resources, expected %v, got %v", g.kept, kept)
+			}
+			if len(plan.ResourcesToDelete(resourceMap)) != len(g.deleted) {
+				t.Errorf("unexpected resources to delete: %v", plan.ResourcesToDelete(resourceMap))
+			}
+		})
+	}
+}
+
+func TestBuildDeletionPlanEdges(t *testing.T) {
+	plan, err := BuildDeletionPlan(buildTestResources(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, r := range plan.Resources {
+		if r.Key != "instance:i-1" {
+			continue
+		}
+		expectedBlocks := []string{"security-group:sg-1", "subnet:subnet-1", "volume:vol-1"}
+		if !reflect.DeepEqual(r.Blocks, expectedBlocks) {
+			t.Errorf("unexpected blocks, expected %v, got %v", expectedBlocks, r.Blocks)
+		}
+		if r.BlockedBy != nil {
+			t.Errorf("unexpected blocked by: %v", r.BlockedBy)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := plan.WriteDot(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{
+		`"instance:i-1" -> "volume:vol-1";`,
+		`"subnet:subnet-1" -> "vpc:vpc-1";`,
+		`"vpc:vpc-1" [label="vpc\nvpc-1\nmatched by tag" style=dashed tooltip="shared"];`,
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("expected %q in graph:\n%s", s, b.String())
+		}
+	}
+}
+
+func TestBuildDeletionPlanUnknownKeep(t *testing.T) {
+	_, err := BuildDeletionPlan(buildTestResources(), []string{"vol-2"})
+	if err == nil {
+		t.Fatalf("expected an error for a selector not matching any resource")
+	}
+}
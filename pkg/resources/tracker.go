@@ -20,6 +20,15 @@ import (
 	"k8s.io/kops/upup/pkg/fi"
 )
 
+const (
+	// MatchReasonTag is set on resources found by their cluster ownership tags
+	MatchReasonTag = "tag"
+	// MatchReasonName is set on resources found by a name derived from the cluster name
+	MatchReasonName = "name"
+	// MatchReasonVPC is set on resources found because they are attached to the cluster VPC or its route tables
+	MatchReasonVPC = "vpc"
+)
+
 type Resource struct {
 	Name string
 	Type string
@@ -28,6 +37,9 @@ type Resource struct {
 	// If true, this resource is not owned by the cluster
 	Shared bool
 
+	// MatchReason records why the resource was considered part of the cluster, one of the MatchReason constants
+	MatchReason string
+
 	Blocks  []string
 	Blocked []string
 	Done    bool
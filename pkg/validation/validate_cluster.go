@@ -21,10 +21,12 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"sort"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/pager"
+	channelsapi "k8s.io/kops/channels/pkg/api"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/upup/pkg/fi"
 
@@ -33,7 +35,6 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/cloudinstances"
-	"k8s.io/kops/pkg/dns"
 )
 
 // ValidationCluster uses a cluster to validate.
@@ -127,7 +128,7 @@ func (v *clusterValidatorImpl) Validate() (*ValidationCluster, error) {
 	validation := &ValidationCluster{}
 
 	// Do not use if we are running gossip
-	if !dns.IsGossipHostname(clusterName) {
+	if !v.cluster.IsGossip() {
 		hasPlaceHolderIPAddress, err := hasPlaceHolderIP(v.host)
 		if err != nil {
 			return nil, err
@@ -165,6 +166,10 @@ func (v *clusterValidatorImpl) Validate() (*ValidationCluster, error) {
 		return nil, fmt.Errorf("cannot get pod health for %q: %v", clusterName, err)
 	}
 
+	if err := validation.collectAddonFailures(ctx, v.k8sClient); err != nil {
+		return nil, fmt.Errorf("cannot get addon status for %q: %v", clusterName, err)
+	}
+
 	return validation, nil
 }
 
@@ -381,3 +386,33 @@ func (v *ValidationCluster) validateNodes(cloudGroups map[string]*cloudinstances
 
 	return readyNodes, nodeInstanceGroupMapping
 }
+
+// collectAddonFailures logs the addon updates that the channels tool recorded as failing to roll out.
+// They are warnings rather than failures: the recorded status is only cleared by a later successful update,
+// so failing validation on it would block the rolling update that could deliver the fix.
+func (v *ValidationCluster) collectAddonFailures(ctx context.Context, client kubernetes.Interface) error {
+	namespaces, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing namespaces: %v", err)
+	}
+
+	for _, ns := range namespaces.Items {
+		statuses, err := channelsapi.FindAddonStatuses(ns.Annotations)
+		if err != nil {
+			klog.Warningf("ignoring addon status in namespace %q: %v", ns.Name, err)
+			continue
+		}
+
+		var names []string
+		for name := range statuses {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			klog.Warningf("addon %q %s", name, statuses[name].Describe())
+		}
+	}
+
+	return nil
+}
@@ -892,3 +892,25 @@ func Test_ValidateDetachedNodesNotValidated(t *testing.T) {
 		printDebug(t, v)
 	}
 }
+
+func Test_ValidateAddonFailures(t *testing.T) {
+	objects := []runtime.Object{
+		&v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "kube-system",
+				Annotations: map[string]string{
+					"addons.k8s.io/coredns.addons.k8s.io":               `{"manifestHash":"abc"}`,
+					"status.addons.k8s.io/coredns.addons.k8s.io":        `{"failedManifestHash":"def","message":"timed out waiting for rollout; rollback failed: boom"}`,
+					"addons.k8s.io/dns-controller.addons.k8s.io":        `{"manifestHash":"abc"}`,
+					"status.addons.k8s.io/dns-controller.addons.k8s.io": `{"failedManifestHash":"def","message":"timed out waiting for rollout","rolledBack":true}`,
+				},
+			},
+		},
+	}
+
+	v, err := testValidate(t, nil, objects)
+	require.NoError(t, err)
+	if !assert.Empty(t, v.Failures) {
+		printDebug(t, v)
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package wellknownports
 
 const (
+	// ProtokubeGossipDebug is the port where protokube serves its gossip and DNS state for debugging
+	ProtokubeGossipDebug = 3987
+
 	// KopsControllerPort is the port where kops-controller listens.
 	KopsControllerPort = 3988
 
@@ -21,9 +21,11 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/klog/v2"
@@ -31,6 +33,7 @@ import (
 	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
 	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/protokube/pkg/gossip"
+	gossipdebug "k8s.io/kops/protokube/pkg/gossip/debug"
 	gossipdns "k8s.io/kops/protokube/pkg/gossip/dns"
 	_ "k8s.io/kops/protokube/pkg/gossip/memberlist"
 	_ "k8s.io/kops/protokube/pkg/gossip/mesh"
@@ -61,9 +64,9 @@ func main() {
 
 // run is responsible for running the protokube service controller
 func run() error {
-	var zones []string
+	var zones, metalGossipSeeds []string
 	var applyTaints, initializeRBAC, containerized, master, tlsAuth bool
-	var cloud, clusterID, dnsServer, dnsProviderID, dnsInternalSuffix, gossipSecret, gossipListen, gossipProtocol, gossipSecretSecondary, gossipListenSecondary, gossipProtocolSecondary string
+	var cloud, clusterID, dnsServer, dnsProviderID, dnsInternalSuffix, gossipSecret, gossipListen, gossipProtocol, gossipSecretSecondary, gossipListenSecondary, gossipProtocolSecondary, gossipDebugListen, gossipAPIName string
 	var flagChannels, tlsCert, tlsKey, tlsCA, peerCert, peerKey, peerCA string
 	var etcdBackupImage, etcdBackupStore, etcdImageSource, etcdElectionTimeout, etcdHeartbeatInterval string
 	var dnsUpdateInterval int
@@ -72,7 +75,7 @@ func run() error {
 	flag.BoolVar(&containerized, "containerized", containerized, "Set if we are running containerized.")
 	flag.BoolVar(&initializeRBAC, "initialize-rbac", initializeRBAC, "Set if we should initialize RBAC")
 	flag.BoolVar(&master, "master", master, "Whether or not this node is a master")
-	flag.StringVar(&cloud, "cloud", "aws", "CloudProvider we are using (aws,digitalocean,gce,openstack)")
+	flag.StringVar(&cloud, "cloud", "aws", "CloudProvider we are using (aws,digitalocean,gce,openstack,metal)")
 	flag.StringVar(&clusterID, "cluster-id", clusterID, "Cluster ID")
 	flag.StringVar(&dnsInternalSuffix, "dns-internal-suffix", dnsInternalSuffix, "DNS suffix for internal domain names")
 	flag.StringVar(&dnsServer, "dns-server", dnsServer, "DNS Server")
@@ -84,6 +87,8 @@ func run() error {
 	flag.StringVar(&gossipProtocolSecondary, "gossip-protocol-secondary", "memberlist", "mesh/memberlist")
 	flag.StringVar(&gossipListenSecondary, "gossip-listen-secondary", fmt.Sprintf("0.0.0.0:%d", wellknownports.ProtokubeGossipMemberlist), "address:port on which to bind for gossip")
 	flags.StringVar(&gossipSecretSecondary, "gossip-secret-secondary", gossipSecret, "Secret to use to secure gossip")
+	flag.StringVar(&gossipAPIName, "gossip-api-name", gossipAPIName, "If set, publish this name with the address of this node into gossip DNS; used while migrating a cluster away from gossip")
+	flag.StringVar(&gossipDebugListen, "gossip-debug-listen", gossipDebugListen, "If set, address:port on which to serve the gossip and DNS state for debugging")
 	flag.StringVar(&peerCA, "peer-ca", peerCA, "Path to a file containing the peer ca in PEM format")
 	flag.StringVar(&peerCert, "peer-cert", peerCert, "Path to a file containing the peer certificate")
 	flag.StringVar(&peerKey, "peer-key", peerKey, "Path to a file containing the private key for the peers")
@@ -92,6 +97,7 @@ func run() error {
 	flag.StringVar(&tlsCert, "tls-cert", tlsCert, "Path to a file containing the certificate for etcd server")
 	flag.StringVar(&tlsKey, "tls-key", tlsKey, "Path to a file containing the private key for etcd server")
 	flags.StringSliceVarP(&zones, "zone", "z", []string{}, "Configure permitted zones and their mappings")
+	flags.StringSliceVar(&metalGossipSeeds, "gossip-seed", []string{}, "Addresses through which to join gossip, on clouds where the peers cannot be discovered (metal)")
 	flags.StringVar(&dnsProviderID, "dns", "aws-route53", "DNS provider we should use (aws-route53, google-clouddns, digitalocean)")
 	flags.StringVar(&etcdBackupImage, "etcd-backup-image", "", "Set to override the image for (experimental) etcd backups")
 	flags.StringVar(&etcdBackupStore, "etcd-backup-store", "", "Set to enable (experimental) etcd backups")
@@ -206,6 +212,14 @@ func run() error {
 		if clusterID == "" {
 			clusterID = azureVolumes.ClusterID()
 		}
+	} else if cloud == "metal" {
+		metalVolumes, err := protokube.NewMetalVolumes(metalGossipSeeds)
+		if err != nil {
+			klog.Errorf("Error initializing metal: %q", err)
+			os.Exit(1)
+		}
+		volumes = metalVolumes
+		internalIP = metalVolumes.InternalIP()
 	} else {
 		klog.Errorf("Unknown cloud %q", cloud)
 		os.Exit(1)
@@ -286,6 +300,12 @@ func run() error {
 				return err
 			}
 			gossipName = volumes.(*protokube.AzureVolumes).InstanceID()
+		} else if cloud == "metal" {
+			gossipSeeds, err = volumes.(*protokube.MetalVolumes).GossipSeeds()
+			if err != nil {
+				return err
+			}
+			gossipName = volumes.(*protokube.MetalVolumes).InstanceName()
 		} else {
 			klog.Fatalf("seed provider for %q not yet implemented", cloud)
 		}
@@ -339,7 +359,30 @@ func run() error {
 			klog.Fatalf("RunDNSUpdates exited unexpectedly")
 		}()
 
-		dnsProvider = &protokube.GossipDnsProvider{DNSView: dnsView, Zone: zoneInfo}
+		if gossipDebugListen != "" {
+			go func() {
+				mux := http.NewServeMux()
+				mux.Handle(gossipdebug.Path, gossipdebug.Handler(gossipState, dnsView))
+				klog.Infof("serving gossip debug state on %s", gossipDebugListen)
+				if err := http.ListenAndServe(gossipDebugListen, mux); err != nil {
+					klog.Errorf("gossip debug server exited: %v", err)
+				}
+			}()
+		}
+
+		gossipDNSProvider := &protokube.GossipDnsProvider{DNSView: dnsView, Zone: zoneInfo}
+		dnsProvider = gossipDNSProvider
+
+		if gossipAPIName != "" {
+			go func() {
+				for {
+					if err := gossipDNSProvider.Replace(gossipAPIName, []string{internalIP.String()}); err != nil {
+						klog.Warningf("error publishing %q to gossip: %v", gossipAPIName, err)
+					}
+					time.Sleep(1 * time.Minute)
+				}
+			}()
+		}
 	} else {
 		var dnsScope dns.Scope
 		var dnsController *dns.DNSController
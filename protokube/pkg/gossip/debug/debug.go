@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/protokube/pkg/gossip"
+	"k8s.io/kops/protokube/pkg/gossip/dns"
+)
+
+// Path is the HTTP path on which the gossip state is served.
+const Path = "/gossip"
+
+const (
+	// DisagreementPeer is reported when nodes do not agree on the members of the gossip cluster.
+	DisagreementPeer = "peer"
+	// DisagreementDNS is reported when nodes do not agree on a DNS record.
+	DisagreementDNS = "dns"
+)
+
+// NodeState is what a single node believes the gossip cluster looks like.
+type NodeState struct {
+	// Version is the version of the local gossip state.
+	Version uint64 `json:"version"`
+	// Values holds the raw gossip key/value pairs.
+	Values map[string]string `json:"values,omitempty"`
+	// Peers holds the gossip peers known to the node, including itself.
+	Peers []gossip.GossipPeer `json:"peers,omitempty"`
+	// DNSVersion is the gossip version the DNS view was built from.
+	DNSVersion uint64 `json:"dnsVersion"`
+	// DNSRecords holds the records of the DNS view.
+	DNSRecords []DNSRecord `json:"dnsRecords,omitempty"`
+}
+
+// DNSRecord is a record in the gossip DNS view.
+type DNSRecord struct {
+	Zone    string   `json:"zone"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Rrdatas []string `json:"rrdatas"`
+}
+
+// Disagreement describes a difference between what the nodes believe.
+type Disagreement struct {
+	// Kind is the kind of disagreement, DisagreementPeer or DisagreementDNS.
+	Kind string `json:"kind"`
+	// Key identifies the peer or DNS record.
+	Key string `json:"key"`
+	// Values maps each node to what it reports for the key; an empty value means the node does not know the key.
+	Values map[string]string `json:"values"`
+}
+
+// BuildNodeState captures the current state of the gossip and the DNS view built from it.
+func BuildNodeState(gossipState gossip.GossipState, dnsView *dns.DNSView) *NodeState {
+	state := &NodeState{}
+
+	snapshot := gossipState.Snapshot()
+	state.Version = snapshot.Version
+	state.Values = snapshot.Values
+	state.Peers = gossipState.Peers()
+	sort.Slice(state.Peers, func(i, j int) bool {
+		return state.Peers[i].Name < state.Peers[j].Name
+	})
+
+	if dnsView != nil {
+		dnsSnapshot := dnsView.Snapshot()
+		state.DNSVersion = dnsSnapshot.Version()
+		for _, zone := range dnsSnapshot.ListZones() {
+			for _, r := range dnsSnapshot.RecordsForZone(zone) {
+				rrdatas := append([]string(nil), r.Rrdatas...)
+				sort.Strings(rrdatas)
+				state.DNSRecords = append(state.DNSRecords, DNSRecord{
+					Zone:    zone.Name,
+					Name:    r.Name,
+					Type:    r.RrsType,
+					Rrdatas: rrdatas,
+				})
+			}
+		}
+		sort.Slice(state.DNSRecords, func(i, j int) bool {
+			return state.DNSRecords[i].key() < state.DNSRecords[j].key()
+		})
+	}
+
+	return state
+}
+
+func (r *DNSRecord) key() string {
+	return r.Zone + "/" + r.Type + "/" + r.Name
+}
+
+// Handler returns an http.Handler serving the NodeState as JSON.
+func Handler(gossipState gossip.GossipState, dnsView *dns.DNSView) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.MarshalIndent(BuildNodeState(gossipState, dnsView), "", "  ")
+		if err != nil {
+			klog.Warningf("error marshaling gossip state: %v", err)
+			http.Error(w, "error marshaling gossip state", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(b); err != nil {
+			klog.Warningf("error writing gossip state: %v", err)
+		}
+	})
+}
+
+// FindDisagreements compares the states reported by the nodes, keyed by node name,
+// and returns the peers and DNS records that the nodes do not agree on.
+// The gossip versions are local to each node, so they are not compared.
+func FindDisagreements(states map[string]*NodeState) []Disagreement {
+	peers := make(map[string]map[string]string)
+	records := make(map[string]map[string]string)
+	for node, state := range states {
+		for _, peer := range state.Peers {
+			if peers[peer.Name] == nil {
+				peers[peer.Name] = make(map[string]string)
+			}
+			peers[peer.Name][node] = "present"
+		}
+		for i := range state.DNSRecords {
+			r := &state.DNSRecords[i]
+			if records[r.key()] == nil {
+				records[r.key()] = make(map[string]string)
+			}
+			records[r.key()][node] = strings.Join(r.Rrdatas, ",")
+		}
+	}
+
+	var disagreements []Disagreement
+	disagreements = append(disagreements, findDisagreements(DisagreementPeer, peers, states)...)
+	disagreements = append(disagreements, findDisagreements(DisagreementDNS, records, states)...)
+	return disagreements
+}
+
+func findDisagreements(kind string, values map[string]map[string]string, states map[string]*NodeState) []Disagreement {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var disagreements []Disagreement
+	for _, key := range keys {
+		seen := values[key]
+
+		agreed := true
+		var first *string
+		for node := range states {
+			v := seen[node]
+			if first == nil {
+				first = &v
+			} else if *first != v {
+				agreed = false
+			}
+		}
+		if agreed {
+			continue
+		}
+
+		d := Disagreement{
+			Kind:   kind,
+			Key:    key,
+			Values: make(map[string]string),
+		}
+		for node := range states {
+			d.Values[node] = seen[node]
+		}
+		disagreements = append(disagreements, d)
+	}
+	return disagreements
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/protokube/pkg/gossip"
+	"k8s.io/kops/protokube/pkg/gossip/dns"
+)
+
+type fakeGossipState struct {
+	values map[string]string
+	peers  []gossip.GossipPeer
+}
+
+var _ gossip.GossipState = &fakeGossipState{}
+
+func (f *fakeGossipState) Snapshot() *gossip.GossipStateSnapshot {
+	return &gossip.GossipStateSnapshot{Values: f.values, Version: 3}
+}
+
+func (f *fakeGossipState) Peers() []gossip.GossipPeer {
+	return f.peers
+}
+
+func (f *fakeGossipState) UpdateValues(removeKeys []string, putKeys map[string]string) error {
+	for _, k := range removeKeys {
+		delete(f.values, k)
+	}
+	for k, v := range putKeys {
+		f.values[k] = v
+	}
+	return nil
+}
+
+func (f *fakeGossipState) Start() error {
+	return nil
+}
+
+func TestBuildNodeState(t *testing.T) {
+	gossipState := &fakeGossipState{
+		values: map[string]string{
+			"dns/local/A/api.internal.example.k8s.local": "10.0.0.2,10.0.0.1",
+		},
+		peers: []gossip.GossipPeer{
+			{Name: "b"},
+			{Name: "a", Self: true},
+		},
+	}
+	state := BuildNodeState(gossipState, dns.NewDNSView(gossipState))
+
+	if state.Version != 3 || state.DNSVersion != 3 {
+		t.Errorf("unexpected versions: %d, %d", state.Version, state.DNSVersion)
+	}
+	expectedPeers := []gossip.GossipPeer{{Name: "a", Self: true}, {Name: "b"}}
+	if !reflect.DeepEqual(state.Peers, expectedPeers) {
+		t.Errorf("unexpected peers: %v", state.Peers)
+	}
+	expectedRecords := []DNSRecord{
+		{Zone: "local", Name: "api.internal.example.k8s.local", Type: "A", Rrdatas: []string{"10.0.0.1", "10.0.0.2"}},
+	}
+	if !reflect.DeepEqual(state.DNSRecords, expectedRecords) {
+		t.Errorf("unexpected records: %v", state.DNSRecords)
+	}
+}
+
+func TestFindDisagreements(t *testing.T) {
+	api := func(rrdatas ...string) DNSRecord {
+		return DNSRecord{Zone: "local", Name: "api.internal.example.k8s.local", Type: "A", Rrdatas: rrdatas}
+	}
+	peers := func(names ...string) []gossip.GossipPeer {
+		var peers []gossip.GossipPeer
+		for _, name := range names {
+			peers = append(peers, gossip.GossipPeer{Name: name})
+		}
+		return peers
+	}
+
+	grid := []struct {
+		Description string
+		States      map[string]*NodeState
+		Expected    []Disagreement
+	}{
+		{
+			Description: "agreement",
+			States: map[string]*NodeState{
+				"master-a": {Version: 1, Peers: peers("a", "b"), DNSRecords: []DNSRecord{api("10.0.0.1", "10.0.0.2")}},
+				"master-b": {Version: 7, Peers: peers("a", "b"), DNSRecords: []DNSRecord{api("10.0.0.1", "10.0.0.2")}},
+			},
+		},
+		{
+			Description: "split brain",
+			States: map[string]*NodeState{
+				"master-a": {Peers: peers("a"), DNSRecords: []DNSRecord{api("10.0.0.1")}},
+				"master-b": {Peers: peers("b"), DNSRecords: []DNSRecord{api("10.0.0.2")}},
+			},
+			Expected: []Disagreement{
+				{Kind: DisagreementPeer, Key: "a", Values: map[string]string{"master-a": "present", "master-b": ""}},
+				{Kind: DisagreementPeer, Key: "b", Values: map[string]string{"master-a": "", "master-b": "present"}},
+				{Kind: DisagreementDNS, Key: "local/A/api.internal.example.k8s.local", Values: map[string]string{"master-a": "10.0.0.1", "master-b": "10.0.0.2"}},
+			},
+		},
+		{
+			Description: "missing record",
+			States: map[string]*NodeState{
+				"master-a": {Peers: peers("a", "b"), DNSRecords: []DNSRecord{api("10.0.0.1")}},
+				"master-b": {Peers: peers("a", "b")},
+			},
+			Expected: []Disagreement{
+				{Kind: DisagreementDNS, Key: "local/A/api.internal.example.k8s.local", Values: map[string]string{"master-a": "10.0.0.1", "master-b": ""}},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			actual := FindDisagreements(g.States)
+			if !reflect.DeepEqual(actual, g.Expected) {
+				t.Errorf("unexpected disagreements:\n got %v\nwant %v", actual, g.Expected)
+			}
+		})
+	}
+}
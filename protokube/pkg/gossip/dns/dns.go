@@ -80,6 +80,11 @@ type dnsViewSnapshotZone struct {
 	Records map[string]DNSRecord
 }
 
+// Version returns the version of the gossip state the snapshot was built from
+func (s *DNSViewSnapshot) Version() uint64 {
+	return s.version
+}
+
 // RecordsForZone returns records matching the specified zone
 func (s *DNSViewSnapshot) RecordsForZone(zoneInfo DNSZoneInfo) []DNSRecord {
 	var records []DNSRecord
@@ -26,8 +26,18 @@ type GossipStateSnapshot struct {
 	Version uint64
 }
 
+// GossipPeer describes a member of the gossip cluster, as seen by the local peer.
+type GossipPeer struct {
+	Name     string `json:"name"`
+	NickName string `json:"nickName,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Self     bool   `json:"self,omitempty"`
+}
+
 type GossipState interface {
 	Snapshot() *GossipStateSnapshot
+	// Peers returns the gossip peers known to this node, including itself.
+	Peers() []GossipPeer
 	UpdateValues(removeKeys []string, putKeys map[string]string) error
 	Start() error
 }
@@ -44,6 +54,10 @@ func (m *MultiGossipState) Snapshot() *GossipStateSnapshot {
 	return m.Primary.Snapshot()
 }
 
+func (m *MultiGossipState) Peers() []GossipPeer {
+	return m.Primary.Peers()
+}
+
 func (m *MultiGossipState) UpdateValues(removeKeys []string, putKeys map[string]string) error {
 	err := m.Primary.UpdateValues(removeKeys, putKeys)
 	m.Secondary.UpdateValues(removeKeys, putKeys)
@@ -147,6 +147,20 @@ func (g *MemberlistGossiper) Snapshot() *gossip.GossipStateSnapshot {
 	return g.state.snapshot()
 }
 
+func (g *MemberlistGossiper) Peers() []gossip.GossipPeer {
+	self := g.peer.Name()
+
+	var peers []gossip.GossipPeer
+	for _, n := range g.peer.Peers() {
+		peers = append(peers, gossip.GossipPeer{
+			Name:    n.Name,
+			Address: net.JoinHostPort(n.Addr.String(), strconv.Itoa(int(n.Port))),
+			Self:    n.Name == self,
+		})
+	}
+	return peers
+}
+
 func (g *MemberlistGossiper) UpdateValues(removeKeys []string, putKeys map[string]string) error {
 	klog.V(2).Infof("UpdateValues: remove=%s, put=%s", removeKeys, putKeys)
 	g.state.updateValues(removeKeys, putKeys)
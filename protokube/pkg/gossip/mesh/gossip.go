@@ -159,6 +159,18 @@ func (g *MeshGossiper) Snapshot() *gossip.GossipStateSnapshot {
 	return g.peer.snapshot()
 }
 
+func (g *MeshGossiper) Peers() []gossip.GossipPeer {
+	var peers []gossip.GossipPeer
+	for _, d := range g.router.Peers.Descriptions() {
+		peers = append(peers, gossip.GossipPeer{
+			Name:     d.Name.String(),
+			NickName: d.NickName,
+			Self:     d.Self,
+		})
+	}
+	return peers
+}
+
 func (g *MeshGossiper) UpdateValues(removeKeys []string, putEntries map[string]string) error {
 	klog.V(2).Infof("UpdateValues: remove=%s, put=%s", removeKeys, putEntries)
 	return g.peer.updateValues(removeKeys, putEntries)
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protokube
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/wellknownports"
+	"k8s.io/kops/protokube/pkg/gossip"
+)
+
+// MetalVolumes is the Volumes implementation for pre-provisioned hosts, which have no cloud volumes:
+// etcd-manager stores etcd on the disks of the hosts, and the gossip peers are configured statically
+type MetalVolumes struct {
+	seeds      []string
+	hostname   string
+	internalIP net.IP
+}
+
+var _ Volumes = &MetalVolumes{}
+
+// NewMetalVolumes returns the Volumes of a host, which joins gossip through the seeds
+func NewMetalVolumes(seeds []string) (*MetalVolumes, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("error getting hostname: %v", err)
+	}
+
+	internalIP, err := findMetalInternalIP(seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetalVolumes{
+		seeds:      seeds,
+		hostname:   strings.ToLower(hostname),
+		internalIP: internalIP,
+	}, nil
+}
+
+// findMetalInternalIP returns the address of the host on the network of the seeds,
+// or its first IPv4 address if there are no seeds
+func findMetalInternalIP(seeds []string) (net.IP, error) {
+	for _, seed := range seeds {
+		// Connecting a UDP socket does not send anything, but selects the local address routing to the seed
+		conn, err := net.Dial("udp", net.JoinHostPort(seed, strconv.Itoa(wellknownports.ProtokubeGossipWeaveMesh)))
+		if err != nil {
+			klog.Warningf("unable to find a route to gossip seed %q: %v", seed, err)
+			continue
+		}
+		localAddr := conn.LocalAddr().(*net.UDPAddr)
+		conn.Close()
+		return localAddr.IP, nil
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("error listing addresses: %v", err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil && ipNet.IP.IsGlobalUnicast() {
+			return ipNet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to find the internal IP of the host")
+}
+
+// InternalIP returns the address of the host on the network of the cluster
+func (v *MetalVolumes) InternalIP() net.IP {
+	return v.internalIP
+}
+
+// InstanceName returns the name of the host
+func (v *MetalVolumes) InstanceName() string {
+	return v.hostname
+}
+
+// GossipSeeds returns the statically configured seeds
+func (v *MetalVolumes) GossipSeeds() (gossip.SeedProvider, error) {
+	return gossip.NewStaticSeedProvider(v.seeds), nil
+}
+
+func (v *MetalVolumes) AttachVolume(volume *Volume) error {
+	return fmt.Errorf("volumes are not supported on metal")
+}
+
+func (v *MetalVolumes) FindVolumes() ([]*Volume, error) {
+	return nil, nil
+}
+
+func (v *MetalVolumes) FindMountedVolume(volume *Volume) (string, error) {
+	return "", nil
+}
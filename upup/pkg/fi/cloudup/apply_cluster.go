@@ -42,7 +42,6 @@ import (
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/client/simple"
-	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/pkg/model/alimodel"
@@ -54,6 +53,7 @@ import (
 	"k8s.io/kops/pkg/model/domodel"
 	"k8s.io/kops/pkg/model/gcemodel"
 	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/pkg/model/metalmodel"
 	"k8s.io/kops/pkg/model/openstackmodel"
 	"k8s.io/kops/pkg/templates"
 	"k8s.io/kops/pkg/wellknownports"
@@ -66,6 +66,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/cloudformation"
 	"k8s.io/kops/upup/pkg/fi/cloudup/do"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
 	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
 	"k8s.io/kops/upup/pkg/fi/cloudup/terraformWriter"
@@ -139,6 +140,9 @@ type ApplyClusterCmd struct {
 	// GetAssets is whether this is called just to obtain the list of assets.
 	GetAssets bool
 
+	// Quiet suppresses the report of the changes of a dry run, for callers which consume the results themselves.
+	Quiet bool
+
 	// TaskMap is the map of tasks that we built (output)
 	TaskMap map[string]fi.Task
 
@@ -283,7 +287,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	if cluster.Spec.KubernetesVersion == "" {
 		return fmt.Errorf("KubernetesVersion not set")
 	}
-	if cluster.Spec.DNSZone == "" && !dns.IsGossipHostname(cluster.ObjectMeta.Name) {
+	if cluster.Spec.DNSZone == "" && !cluster.IsGossip() {
 		return fmt.Errorf("DNSZone not set")
 	}
 
@@ -475,6 +479,12 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 				return fmt.Errorf("exactly one 'admin' SSH public key can be specified when running with Openstack; please delete a key using `kops delete secret`")
 			}
 		}
+	case kops.CloudProviderMetal:
+		{
+			if !featureflag.Metal.Enabled() {
+				return fmt.Errorf("metal support is currently alpha, and is feature-gated. Please export KOPS_FEATURE_FLAGS=Metal")
+			}
+		}
 	default:
 		return fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
 	}
@@ -482,7 +492,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	modelContext.SSHPublicKeys = sshPublicKeys
 	modelContext.Region = cloud.Region()
 
-	if dns.IsGossipHostname(cluster.ObjectMeta.Name) {
+	if cluster.IsGossip() {
 		klog.Infof("Gossip DNS: skipping DNS validation")
 	} else {
 		err = validateDNS(cluster, cloud)
@@ -496,7 +506,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		cloud:            cloud,
 	}
 
-	configBuilder, err := newNodeUpConfigBuilder(cluster, assetBuilder, c.Assets, encryptionConfigSecretHash)
+	configBuilder, err := newNodeUpConfigBuilder(cluster, c.InstanceGroups, assetBuilder, c.Assets, encryptionConfigSecretHash)
 	if err != nil {
 		return err
 	}
@@ -667,6 +677,11 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 				&openstackmodel.ServerGroupModelBuilder{OpenstackModelContext: openstackModelContext, BootstrapScriptBuilder: bootstrapScriptBuilder, Lifecycle: clusterLifecycle},
 			)
 
+		case kops.CloudProviderMetal:
+			l.Builders = append(l.Builders,
+				&metalmodel.HostModelBuilder{KopsModelContext: modelContext, BootstrapScriptBuilder: bootstrapScriptBuilder, Lifecycle: clusterLifecycle},
+			)
+
 		default:
 			return fmt.Errorf("unknown cloudprovider %q", cluster.Spec.CloudProvider)
 		}
@@ -694,6 +709,8 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 			target = aliup.NewALIAPITarget(cloud.(aliup.ALICloud))
 		case kops.CloudProviderAzure:
 			target = azure.NewAzureAPITarget(cloud.(azure.AzureCloud))
+		case kops.CloudProviderMetal:
+			target = metal.NewMetalAPITarget(cloud.(metal.MetalCloud))
 		default:
 			return fmt.Errorf("direct configuration not supported with CloudProvider:%q", cluster.Spec.CloudProvider)
 		}
@@ -741,7 +758,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 
 	case TargetDryRun:
 		var out io.Writer = os.Stdout
-		if c.GetAssets {
+		if c.GetAssets || c.Quiet {
 			out = io.Discard
 		}
 		target = fi.NewDryRunTarget(assetBuilder, out)
@@ -779,7 +796,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("error running tasks: %v", err)
 	}
 
-	if dns.IsGossipHostname(cluster.Name) {
+	if cluster.IsGossip() {
 		shouldPrecreateDNS = false
 	}
 
@@ -1126,9 +1143,10 @@ type nodeUpConfigBuilder struct {
 	protokubeAsset             map[architectures.Architecture][]*mirrors.MirroredAsset
 	channelsAsset              map[architectures.Architecture][]*mirrors.MirroredAsset
 	encryptionConfigSecretHash string
+	instanceGroups             []*kops.InstanceGroup
 }
 
-func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, assets map[architectures.Architecture][]*mirrors.MirroredAsset, encryptionConfigSecretHash string) (model.NodeUpConfigBuilder, error) {
+func newNodeUpConfigBuilder(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, assetBuilder *assets.AssetBuilder, assets map[architectures.Architecture][]*mirrors.MirroredAsset, encryptionConfigSecretHash string) (model.NodeUpConfigBuilder, error) {
 	configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
@@ -1271,6 +1289,7 @@ func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBui
 		protokubeAsset:             protokubeAsset,
 		channelsAsset:              channelsAsset,
 		encryptionConfigSecretHash: encryptionConfigSecretHash,
+		instanceGroups:             instanceGroups,
 	}
 
 	return &configBuilder, nil
@@ -1289,7 +1308,7 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 		return nil, nil, fmt.Errorf("cannot determine role for instance group: %v", ig.ObjectMeta.Name)
 	}
 
-	useGossip := dns.IsGossipHostname(cluster.Spec.MasterInternalName)
+	useGossip := cluster.IsGossip() || cluster.IsMigratingFromGossip()
 	isMaster := role == kops.InstanceGroupRoleMaster
 	hasAPIServer := isMaster || role == kops.InstanceGroupRoleAPIServer
 
@@ -1403,6 +1422,15 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 		config.ApiserverAdditionalIPs = apiserverAdditionalIPs
 	}
 
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderMetal {
+		// Hosts cannot be discovered, so the addresses of the control plane hosts are configured statically
+		controlPlaneAddresses := metal.ControlPlaneAddresses(n.instanceGroups)
+		config.GossipSeeds = controlPlaneAddresses
+		if hasAPIServer {
+			config.ApiserverAdditionalIPs = append(config.ApiserverAdditionalIPs, controlPlaneAddresses...)
+		}
+	}
+
 	for _, manifest := range n.assetBuilder.StaticManifests {
 		match := false
 		for _, r := range manifest.Roles {
@@ -1427,12 +1455,40 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 
 	if cluster.Spec.ContainerRuntime == "containerd" {
 		config.ContainerdConfig = cluster.Spec.Containerd
+
+		// Runtime handlers can be overridden per instance group
+		if runtimes := cluster.Spec.Containerd.ResolveRuntimes(ig); runtimes != nil {
+			containerd := *cluster.Spec.Containerd
+			resolved, runtimeAssets, err := buildContainerdRuntimes(n.assetBuilder, runtimes)
+			if err != nil {
+				return nil, nil, err
+			}
+			containerd.Runtimes = resolved
+			config.ContainerdConfig = &containerd
+
+			for arch, assets := range runtimeAssets {
+				for _, a := range assets {
+					config.Assets[arch] = append(config.Assets[arch], a.CompactString())
+				}
+			}
+		}
 	}
 
 	if ig.Spec.WarmPool != nil || cluster.Spec.WarmPool != nil {
 		config.WarmPoolImages = n.buildWarmPoolImages(ig)
 	}
 
+	// Only the images nodeup remaps itself are pinned in the nodeup config; protokube runs etcd when there are no etcd manifests
+	var nodeupImages []string
+	if len(config.EtcdManifests) == 0 && len(cluster.Spec.EtcdClusters) != 0 {
+		nodeupImages = append(nodeupImages, components.ProtokubeEtcdImage(cluster))
+	}
+	imageDigests, err := n.assetBuilder.PinnedImages(nodeupImages)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.ImageDigests = imageDigests
+
 	return config, bootConfig, nil
 }
 
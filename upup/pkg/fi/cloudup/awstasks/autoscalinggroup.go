@@ -77,6 +77,8 @@ type AutoscalingGroup struct {
 	MixedSpotInstancePools *int64
 	// MixedSpotMaxPrice is the maximum price per unit hour you are willing to pay for a Spot Instance
 	MixedSpotMaxPrice *string
+	// ScheduledActions are the recurring changes of the size of the asg
+	ScheduledActions []*ScheduledAction
 	// Subnets is a collection of subnets to attach the nodes to
 	Subnets []*Subnet
 	// SuspendProcesses
@@ -249,6 +251,25 @@ func (e *AutoscalingGroup) Find(c *fi.Context) (*AutoscalingGroup, error) {
 		actual.InstanceProtection = g.NewInstancesProtectedFromScaleIn
 	}
 
+	actual.ScheduledActions, err = findScheduledActions(cloud, fi.StringValue(e.Name))
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range actual.ScheduledActions {
+		// AWS defaults the time zone to UTC
+		if tz := fi.StringValue(a.TimeZone); tz == "UTC" || tz == "Etc/UTC" {
+			for _, x := range e.ScheduledActions {
+				if fi.StringValue(x.Name) == fi.StringValue(a.Name) && x.TimeZone == nil {
+					a.TimeZone = nil
+				}
+			}
+		}
+	}
+
+	// The scheduled actions change the sizes of the asg: the sizes they set must not be reverted
+	actual.MinSize = scheduledSize(actual.MinSize, e.MinSize, e.ScheduledActions, func(a *ScheduledAction) *int64 { return a.MinSize })
+	actual.MaxSize = scheduledSize(actual.MaxSize, e.MaxSize, e.ScheduledActions, func(a *ScheduledAction) *int64 { return a.MaxSize })
+
 	return actual, nil
 }
 
@@ -420,6 +441,9 @@ func (v *AutoscalingGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Autos
 			}
 		}
 
+		if err := e.renderScheduledActions(t, nil); err != nil {
+			return err
+		}
 	} else {
 		// @logic: else we have found a autoscaling group and we need to evaluate the difference
 		request := &autoscaling.UpdateAutoScalingGroupInput{
@@ -598,6 +622,10 @@ func (v *AutoscalingGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Autos
 			changes.InstanceProtection = nil
 		}
 
+		// The changes don't record the removal of all the scheduled actions
+		updateScheduledActions := changes.ScheduledActions != nil || len(a.ScheduledActions) != 0 && len(e.ScheduledActions) == 0
+		changes.ScheduledActions = nil
+
 		empty := &AutoscalingGroup{}
 		if !reflect.DeepEqual(empty, changes) {
 			klog.Warningf("cannot apply changes to AutoScalingGroup: %v", changes)
@@ -640,6 +668,11 @@ func (v *AutoscalingGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Autos
 				return fmt.Errorf("error attaching TargetGroups: %v", err)
 			}
 		}
+		if updateScheduledActions {
+			if err := e.renderScheduledActions(t, a.ScheduledActions); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -983,6 +1016,10 @@ func (_ *AutoscalingGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, c
 	}
 	tf.SuspendedProcesses = processes
 
+	if err := e.renderScheduledActionsTerraform(t); err != nil {
+		return err
+	}
+
 	return t.RenderResource("aws_autoscaling_group", *e.Name, tf)
 }
 
@@ -1122,6 +1159,10 @@ func (_ *AutoscalingGroup) RenderCloudformation(t *cloudformation.Cloudformation
 		cf.TargetGroupARNs = append(cf.TargetGroupARNs, tg.CloudformationLink())
 	}
 
+	if err := e.renderScheduledActionsCloudformation(t); err != nil {
+		return err
+	}
+
 	return t.RenderResource("AWS::AutoScaling::AutoScalingGroup", fi.StringValue(e.Name), cf)
 }
 
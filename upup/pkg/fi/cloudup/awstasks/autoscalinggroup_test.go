@@ -17,11 +17,14 @@ limitations under the License.
 package awstasks
 
 import (
+	"reflect"
 	"sort"
 	"testing"
 
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
 	"k8s.io/kops/pkg/diff"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
@@ -197,6 +200,148 @@ func TestProcessCompare(t *testing.T) {
 	}
 }
 
+func TestRenderScheduledActions(t *testing.T) {
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	c := &mockautoscaling.MockAutoscaling{}
+	cloud.MockAutoscaling = c
+
+	if _, err := c.CreateAutoScalingGroup(&autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String("nodes"),
+		MinSize:              aws.Int64(1),
+		MaxSize:              aws.Int64(1),
+	}); err != nil {
+		t.Fatalf("error creating test ASG: %v", err)
+	}
+	for _, name := range []string{"kops-stale", "kops-night", "manual"} {
+		if _, err := c.PutScheduledUpdateGroupAction(&autoscaling.PutScheduledUpdateGroupActionInput{
+			AutoScalingGroupName: aws.String("nodes"),
+			ScheduledActionName:  aws.String(name),
+			Recurrence:           aws.String("0 20 * * *"),
+			DesiredCapacity:      aws.Int64(1),
+		}); err != nil {
+			t.Fatalf("error creating test scheduled action: %v", err)
+		}
+	}
+
+	e := &AutoscalingGroup{
+		Name: aws.String("nodes"),
+		ScheduledActions: []*ScheduledAction{
+			{
+				Name:       aws.String("kops-business-hours"),
+				Recurrence: aws.String("0 8 * * MON-FRI"),
+				TimeZone:   aws.String("Europe/Paris"),
+				MinSize:    aws.Int64(3),
+			},
+			{
+				Name:            aws.String("kops-night"),
+				Recurrence:      aws.String("0 20 * * *"),
+				DesiredCapacity: aws.Int64(0),
+			},
+		},
+	}
+
+	actual, err := findScheduledActions(cloud, "nodes")
+	if err != nil {
+		t.Fatalf("error finding scheduled actions: %v", err)
+	}
+	if len(actual) != 2 {
+		t.Fatalf("expected the 2 scheduled actions managed by kops, got %v", actual)
+	}
+
+	if err := e.renderScheduledActions(&awsup.AWSAPITarget{Cloud: cloud}, actual); err != nil {
+		t.Fatalf("error rendering scheduled actions: %v", err)
+	}
+
+	actual, err = findScheduledActions(cloud, "nodes")
+	if err != nil {
+		t.Fatalf("error finding scheduled actions: %v", err)
+	}
+	if !reflect.DeepEqual(actual, e.ScheduledActions) {
+		t.Errorf("unexpected scheduled actions %v, expected %v", actual, e.ScheduledActions)
+	}
+	if c.ScheduledActions["nodes/manual"] == nil {
+		t.Errorf("the scheduled action not managed by kops was deleted")
+	}
+}
+
+func TestRenderAWSDeletesScheduledActions(t *testing.T) {
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	c := &mockautoscaling.MockAutoscaling{}
+	cloud.MockAutoscaling = c
+
+	if _, err := c.CreateAutoScalingGroup(&autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String("nodes"),
+		MinSize:              aws.Int64(1),
+		MaxSize:              aws.Int64(1),
+	}); err != nil {
+		t.Fatalf("error creating test ASG: %v", err)
+	}
+	for _, name := range []string{"kops-night", "manual"} {
+		if _, err := c.PutScheduledUpdateGroupAction(&autoscaling.PutScheduledUpdateGroupActionInput{
+			AutoScalingGroupName: aws.String("nodes"),
+			ScheduledActionName:  aws.String(name),
+			Recurrence:           aws.String("0 20 * * *"),
+			DesiredCapacity:      aws.Int64(0),
+		}); err != nil {
+			t.Fatalf("error creating test scheduled action: %v", err)
+		}
+	}
+
+	actual, err := findScheduledActions(cloud, "nodes")
+	if err != nil {
+		t.Fatalf("error finding scheduled actions: %v", err)
+	}
+	a := &AutoscalingGroup{Name: aws.String("nodes"), ScheduledActions: actual}
+	e := &AutoscalingGroup{Name: aws.String("nodes")}
+	changes := &AutoscalingGroup{}
+	if !fi.BuildChanges(a, e, changes) {
+		t.Fatalf("expected the removal of the scheduled actions to be a change")
+	}
+
+	if err := e.RenderAWS(&awsup.AWSAPITarget{Cloud: cloud}, a, e, changes); err != nil {
+		t.Fatalf("error rendering AutoscalingGroup: %v", err)
+	}
+
+	if actual, err := findScheduledActions(cloud, "nodes"); err != nil {
+		t.Fatalf("error finding scheduled actions: %v", err)
+	} else if len(actual) != 0 {
+		t.Errorf("expected the scheduled actions managed by kops to be deleted, got %v", actual)
+	}
+	if c.ScheduledActions["nodes/manual"] == nil {
+		t.Errorf("the scheduled action not managed by kops was deleted")
+	}
+}
+
+func TestScheduledSize(t *testing.T) {
+	actions := []*ScheduledAction{
+		{Name: aws.String("kops-business-hours"), MinSize: aws.Int64(3)},
+		{Name: aws.String("kops-night"), DesiredCapacity: aws.Int64(0)},
+	}
+	minSize := func(a *ScheduledAction) *int64 { return a.MinSize }
+
+	grid := []struct {
+		actual   int64
+		expected int64
+		result   int64
+	}{
+		// The size set by the schedule is not reverted
+		{actual: 3, expected: 1, result: 1},
+		// Other sizes are drift
+		{actual: 2, expected: 1, result: 2},
+		{actual: 1, expected: 2, result: 1},
+	}
+	for _, g := range grid {
+		result := scheduledSize(aws.Int64(g.actual), aws.Int64(g.expected), actions, minSize)
+		if aws.Int64Value(result) != g.result {
+			t.Errorf("actual %d expected %d: got %d, expected %d", g.actual, g.expected, aws.Int64Value(result), g.result)
+		}
+	}
+
+	if result := scheduledSize(aws.Int64(3), aws.Int64(1), nil, minSize); aws.Int64Value(result) != 3 {
+		t.Errorf("expected the size to be kept without schedules, got %d", aws.Int64Value(result))
+	}
+}
+
 func TestAutoscalingGroupTerraformRender(t *testing.T) {
 	cases := []*renderTest{
 		{
@@ -319,6 +464,72 @@ resource "aws_autoscaling_group" "test1" {
   vpc_zone_identifier = [aws_subnet.test-sg.id]
 }
 
+terraform {
+  required_version = ">= 0.12.26"
+  required_providers {
+    aws = {
+      "source"  = "hashicorp/aws"
+      "version" = ">= 3.34.0"
+    }
+  }
+}
+`,
+		},
+		{
+			Resource: &AutoscalingGroup{
+				Name:           fi.String("test2"),
+				LaunchTemplate: &LaunchTemplate{Name: fi.String("test_lt")},
+				MaxSize:        fi.Int64(10),
+				MinSize:        fi.Int64(1),
+				ScheduledActions: []*ScheduledAction{
+					{
+						Name:       fi.String("kops-business-hours"),
+						Recurrence: fi.String("0 8 * * MON-FRI"),
+						TimeZone:   fi.String("Europe/Paris"),
+						MinSize:    fi.Int64(3),
+					},
+					{
+						Name:            fi.String("kops-night"),
+						Recurrence:      fi.String("0 20 * * *"),
+						MinSize:         fi.Int64(0),
+						MaxSize:         fi.Int64(0),
+						DesiredCapacity: fi.Int64(0),
+					},
+				},
+			},
+			Expected: `provider "aws" {
+  region = "eu-west-2"
+}
+
+resource "aws_autoscaling_group" "test2" {
+  launch_template {
+    id      = aws_launch_template.test_lt.id
+    version = aws_launch_template.test_lt.latest_version
+  }
+  max_size = 10
+  min_size = 1
+  name     = "test2"
+}
+
+resource "aws_autoscaling_schedule" "test2-kops-business-hours" {
+  autoscaling_group_name = aws_autoscaling_group.test2.id
+  desired_capacity       = -1
+  max_size               = -1
+  min_size               = 3
+  recurrence             = "0 8 * * MON-FRI"
+  scheduled_action_name  = "kops-business-hours"
+  time_zone              = "Europe/Paris"
+}
+
+resource "aws_autoscaling_schedule" "test2-kops-night" {
+  autoscaling_group_name = aws_autoscaling_group.test2.id
+  desired_capacity       = 0
+  max_size               = 0
+  min_size               = 0
+  recurrence             = "0 20 * * *"
+  scheduled_action_name  = "kops-night"
+}
+
 terraform {
   required_version = ">= 0.12.26"
   required_providers {
@@ -421,6 +632,81 @@ func TestAutoscalingGroupCloudformationRender(t *testing.T) {
       }
     }
   }
+}`,
+		},
+		{
+			Resource: &AutoscalingGroup{
+				Name:           fi.String("test2"),
+				LaunchTemplate: &LaunchTemplate{Name: fi.String("test_lt")},
+				MaxSize:        fi.Int64(10),
+				MinSize:        fi.Int64(1),
+				ScheduledActions: []*ScheduledAction{
+					{
+						Name:       fi.String("kops-business-hours"),
+						Recurrence: fi.String("0 8 * * MON-FRI"),
+						TimeZone:   fi.String("Europe/Paris"),
+						MinSize:    fi.Int64(3),
+					},
+					{
+						Name:            fi.String("kops-night"),
+						Recurrence:      fi.String("0 20 * * *"),
+						MinSize:         fi.Int64(0),
+						MaxSize:         fi.Int64(0),
+						DesiredCapacity: fi.Int64(0),
+					},
+				},
+			},
+			Expected: `{
+  "Resources": {
+    "AWSAutoScalingAutoScalingGrouptest2": {
+      "Type": "AWS::AutoScaling::AutoScalingGroup",
+      "Properties": {
+        "AutoScalingGroupName": "test2",
+        "LaunchTemplate": {
+          "LaunchTemplateId": {
+            "Ref": "AWSEC2LaunchTemplatetest_lt"
+          },
+          "Version": {
+            "Fn::GetAtt": [
+              "AWSEC2LaunchTemplatetest_lt",
+              "LatestVersionNumber"
+            ]
+          }
+        },
+        "MaxSize": "10",
+        "MinSize": "1",
+        "MetricsCollection": [
+          {
+            "Granularity": null,
+            "Metrics": []
+          }
+        ]
+      }
+    },
+    "AWSAutoScalingScheduledActiontest2kopsbusinesshours": {
+      "Type": "AWS::AutoScaling::ScheduledAction",
+      "Properties": {
+        "AutoScalingGroupName": {
+          "Ref": "AWSAutoScalingAutoScalingGrouptest2"
+        },
+        "Recurrence": "0 8 * * MON-FRI",
+        "TimeZone": "Europe/Paris",
+        "MinSize": 3
+      }
+    },
+    "AWSAutoScalingScheduledActiontest2kopsnight": {
+      "Type": "AWS::AutoScaling::ScheduledAction",
+      "Properties": {
+        "AutoScalingGroupName": {
+          "Ref": "AWSAutoScalingAutoScalingGrouptest2"
+        },
+        "Recurrence": "0 20 * * *",
+        "MinSize": 0,
+        "MaxSize": 0,
+        "DesiredCapacity": 0
+      }
+    }
+  }
 }`,
 		},
 	}
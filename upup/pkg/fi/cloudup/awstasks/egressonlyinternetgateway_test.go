@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awstasks
+
+import (
+	"testing"
+
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+func TestEgressOnlyInternetGatewayCreate(t *testing.T) {
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	c := &mockec2.MockEC2{}
+	cloud.MockEC2 = c
+
+	// We define a function so we can rebuild the tasks, because we modify in-place when running
+	buildTasks := func() map[string]fi.Task {
+		vpc1 := &VPC{
+			Name:      s("vpc1"),
+			Lifecycle: fi.LifecycleSync,
+			CIDR:      s("172.20.0.0/16"),
+			Tags:      map[string]string{"Name": "vpc1"},
+		}
+		eigw1 := &EgressOnlyInternetGateway{
+			Name:      s("eigw1"),
+			Lifecycle: fi.LifecycleSync,
+			VPC:       vpc1,
+			Shared:    fi.Bool(false),
+			Tags:      map[string]string{"Name": "eigw1"},
+		}
+		return map[string]fi.Task{
+			"vpc1":  vpc1,
+			"eigw1": eigw1,
+		}
+	}
+
+	{
+		allTasks := buildTasks()
+		eigw1 := allTasks["eigw1"].(*EgressOnlyInternetGateway)
+		vpc1 := allTasks["vpc1"].(*VPC)
+
+		target := &awsup.AWSAPITarget{
+			Cloud: cloud,
+		}
+
+		context, err := fi.NewContext(target, nil, cloud, nil, nil, nil, true, allTasks)
+		if err != nil {
+			t.Fatalf("error building context: %v", err)
+		}
+		defer context.Close()
+
+		if err := context.RunTasks(testRunTasksOptions); err != nil {
+			t.Fatalf("unexpected error during Run: %v", err)
+		}
+
+		if fi.StringValue(eigw1.ID) == "" {
+			t.Fatalf("ID not set after create")
+		}
+
+		if len(c.EgressOnlyInternetGateways) != 1 {
+			t.Fatalf("Expected exactly one EgressOnlyInternetGateway; found %v", c.EgressOnlyInternetGateways)
+		}
+
+		actual := c.EgressOnlyInternetGateways[fi.StringValue(eigw1.ID)]
+		if len(actual.Attachments) != 1 || fi.StringValue(actual.Attachments[0].VpcId) != fi.StringValue(vpc1.ID) {
+			t.Fatalf("Unexpected EgressOnlyInternetGateway attachments: %v", actual.Attachments)
+		}
+	}
+
+	{
+		allTasks := buildTasks()
+		checkNoChanges(t, cloud, allTasks)
+	}
+}
+
+func TestEgressOnlyInternetGatewayTerraformRender(t *testing.T) {
+	cases := []*renderTest{
+		{
+			Resource: &EgressOnlyInternetGateway{
+				Name: fi.String("test"),
+				VPC: &VPC{
+					Name: fi.String("test"),
+				},
+				Tags: map[string]string{
+					"KubernetesCluster": "test",
+				},
+			},
+			Expected: `provider "aws" {
+  region = "eu-west-2"
+}
+
+resource "aws_egress_only_internet_gateway" "test" {
+  tags = {
+    "KubernetesCluster" = "test"
+  }
+  vpc_id = aws_vpc.test.id
+}
+
+terraform {
+  required_version = ">= 0.12.26"
+  required_providers {
+    aws = {
+      "source"  = "hashicorp/aws"
+      "version" = ">= 3.34.0"
+    }
+  }
+}
+`,
+		},
+	}
+	doRenderTests(t, "RenderTerraform", cases)
+}
+
+func TestEgressOnlyInternetGatewayCloudformationRender(t *testing.T) {
+	cases := []*renderTest{
+		{
+			Resource: &EgressOnlyInternetGateway{
+				Name: fi.String("test"),
+				VPC: &VPC{
+					Name: fi.String("test"),
+				},
+				Tags: map[string]string{
+					"KubernetesCluster": "test",
+				},
+			},
+			Expected: `{
+  "Resources": {
+    "AWSEC2EgressOnlyInternetGatewaytest": {
+      "Type": "AWS::EC2::EgressOnlyInternetGateway",
+      "Properties": {
+        "VpcId": {
+          "Ref": "AWSEC2VPCtest"
+        }
+      }
+    }
+  }
+}`,
+		},
+	}
+	doRenderTests(t, "RenderCloudformation", cases)
+}
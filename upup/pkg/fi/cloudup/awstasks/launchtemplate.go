@@ -41,6 +41,12 @@ type LaunchTemplate struct {
 	BlockDeviceMappings []*BlockDeviceMapping
 	// CPUCredits is the credit option for CPU Usage on some instance types
 	CPUCredits *string
+	// CapacityReservationPreference is the capacity reservation preference: open or none
+	CapacityReservationPreference *string
+	// CapacityReservationID is the id of the capacity reservation to target
+	CapacityReservationID *string
+	// CapacityReservationResourceGroupARN is the ARN of the capacity reservation resource group to target
+	CapacityReservationResourceGroupARN *string
 	// HTTPPutResponseHopLimit is the desired HTTP PUT response hop limit for instance metadata requests.
 	HTTPPutResponseHopLimit *int64
 	// HTTPTokens is the state of token usage for your instance metadata requests.
@@ -58,6 +64,8 @@ type LaunchTemplate struct {
 	InstanceType *string
 	// Ipv6AddressCount is the number of IPv6 addresses to assign with the primary network interface.
 	IPv6AddressCount *int64
+	// PlacementGroup is the placement group to launch instances into
+	PlacementGroup *PlacementGroup
 	// RootVolumeIops is the provisioned IOPS when the volume type is io1, io2 or gp3
 	RootVolumeIops *int64
 	// RootVolumeOptimization enables EBS optimization for an instance
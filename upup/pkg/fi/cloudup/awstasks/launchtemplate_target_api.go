@@ -83,9 +83,25 @@ func (t *LaunchTemplate) RenderAWS(c *awsup.AWSAPITarget, a, e, changes *LaunchT
 	for _, sg := range t.SecurityGroups {
 		data.NetworkInterfaces[0].Groups = append(data.NetworkInterfaces[0].Groups, sg.ID)
 	}
-	// @step: add any tenancy details
-	if t.Tenancy != nil {
+	// @step: add any tenancy and placement group details
+	if t.Tenancy != nil || t.PlacementGroup != nil {
 		data.Placement = &ec2.LaunchTemplatePlacementRequest{Tenancy: t.Tenancy}
+		if t.PlacementGroup != nil {
+			data.Placement.GroupName = t.PlacementGroup.Name
+		}
+	}
+	// @step: add the capacity reservation details
+	if t.CapacityReservationPreference != nil {
+		data.CapacityReservationSpecification = &ec2.LaunchTemplateCapacityReservationSpecificationRequest{
+			CapacityReservationPreference: t.CapacityReservationPreference,
+		}
+	} else if t.CapacityReservationID != nil || t.CapacityReservationResourceGroupARN != nil {
+		data.CapacityReservationSpecification = &ec2.LaunchTemplateCapacityReservationSpecificationRequest{
+			CapacityReservationTarget: &ec2.CapacityReservationTarget{
+				CapacityReservationId:               t.CapacityReservationID,
+				CapacityReservationResourceGroupArn: t.CapacityReservationResourceGroupARN,
+			},
+		}
 	}
 	// @step: set the instance monitoring
 	data.Monitoring = &ec2.LaunchTemplatesMonitoringRequest{Enabled: fi.Bool(false)}
@@ -232,9 +248,23 @@ func (t *LaunchTemplate) Find(c *fi.Context) (*LaunchTemplate, error) {
 	if lt.LaunchTemplateData.Monitoring != nil {
 		actual.InstanceMonitoring = lt.LaunchTemplateData.Monitoring.Enabled
 	}
-	// @step: add the tenancy
+	// @step: add the tenancy and placement group
 	if lt.LaunchTemplateData.Placement != nil {
 		actual.Tenancy = lt.LaunchTemplateData.Placement.Tenancy
+		if aws.StringValue(lt.LaunchTemplateData.Placement.GroupName) != "" {
+			actual.PlacementGroup = &PlacementGroup{Name: lt.LaunchTemplateData.Placement.GroupName}
+		}
+	}
+	// @step: add the capacity reservation details
+	if crs := lt.LaunchTemplateData.CapacityReservationSpecification; crs != nil {
+		// open is the default preference, so only record it when it was asked for
+		if aws.StringValue(crs.CapacityReservationPreference) != ec2.CapacityReservationPreferenceOpen || t.CapacityReservationPreference != nil {
+			actual.CapacityReservationPreference = crs.CapacityReservationPreference
+		}
+		if crs.CapacityReservationTarget != nil {
+			actual.CapacityReservationID = crs.CapacityReservationTarget.CapacityReservationId
+			actual.CapacityReservationResourceGroupARN = crs.CapacityReservationTarget.CapacityReservationResourceGroupArn
+		}
 	}
 	// @step: add the ssh if there is one
 	if lt.LaunchTemplateData.KeyName != nil {
@@ -49,7 +49,7 @@ type cloudformationLaunchTemplatePlacement struct {
 	// AvailabilityZone is the Availability Zone for the instance.
 	AvailabilityZone *string `json:"AvailabilityZone,omitempty"`
 	// GroupName is the name of the placement group for the instance.
-	GroupName *string `json:"GroupName,omitempty"`
+	GroupName *cloudformation.Literal `json:"GroupName,omitempty"`
 	// HostID is the ID of the Dedicated Host for the instance.
 	HostID *string `json:"HostId,omitempty"`
 	// SpreadDomain are reserved for future use.
@@ -58,6 +58,20 @@ type cloudformationLaunchTemplatePlacement struct {
 	Tenancy *string `json:"Tenancy,omitempty"`
 }
 
+type cloudformationLaunchTemplateCapacityReservationTarget struct {
+	// CapacityReservationID is the id of the capacity reservation to target.
+	CapacityReservationID *string `json:"CapacityReservationId,omitempty"`
+	// CapacityReservationResourceGroupARN is the ARN of the capacity reservation resource group to target.
+	CapacityReservationResourceGroupARN *string `json:"CapacityReservationResourceGroupArn,omitempty"`
+}
+
+type cloudformationLaunchTemplateCapacityReservationSpecification struct {
+	// CapacityReservationPreference is the capacity reservation preference. Can be open or none.
+	CapacityReservationPreference *string `json:"CapacityReservationPreference,omitempty"`
+	// CapacityReservationTarget is the capacity reservation to target.
+	CapacityReservationTarget *cloudformationLaunchTemplateCapacityReservationTarget `json:"CapacityReservationTarget,omitempty"`
+}
+
 type cloudformationLaunchTemplateIAMProfile struct {
 	// Name is the name of the profile
 	Name *cloudformation.Literal `json:"Name,omitempty"`
@@ -129,6 +143,8 @@ type cloudformationLaunchTemplateInstanceMetadataOptions struct {
 type cloudformationLaunchTemplateData struct {
 	// BlockDeviceMappings is the device mappings
 	BlockDeviceMappings []*cloudformationLaunchTemplateBlockDevice `json:"BlockDeviceMappings,omitempty"`
+	// CapacityReservationSpecification is the capacity reservation targeting option
+	CapacityReservationSpecification *cloudformationLaunchTemplateCapacityReservationSpecification `json:"CapacityReservationSpecification,omitempty"`
 	// CreditSpecification is the credit option for CPU Usage on some instance types
 	CreditSpecification *cloudformationLaunchTemplateCreditSpecification `json:"CreditSpecification,omitempty"`
 	// EBSOptimized indicates if the root device is ebs optimized
@@ -236,8 +252,24 @@ func (t *LaunchTemplate) RenderCloudformation(target *cloudformation.Cloudformat
 	if e.SSHKey != nil {
 		data.KeyName = e.SSHKey.Name
 	}
-	if e.Tenancy != nil {
-		data.Placement = []*cloudformationLaunchTemplatePlacement{{Tenancy: e.Tenancy}}
+	if e.Tenancy != nil || e.PlacementGroup != nil {
+		placement := &cloudformationLaunchTemplatePlacement{Tenancy: e.Tenancy}
+		if e.PlacementGroup != nil {
+			placement.GroupName = e.PlacementGroup.CloudformationLink()
+		}
+		data.Placement = []*cloudformationLaunchTemplatePlacement{placement}
+	}
+	if e.CapacityReservationPreference != nil {
+		data.CapacityReservationSpecification = &cloudformationLaunchTemplateCapacityReservationSpecification{
+			CapacityReservationPreference: e.CapacityReservationPreference,
+		}
+	} else if e.CapacityReservationID != nil || e.CapacityReservationResourceGroupARN != nil {
+		data.CapacityReservationSpecification = &cloudformationLaunchTemplateCapacityReservationSpecification{
+			CapacityReservationTarget: &cloudformationLaunchTemplateCapacityReservationTarget{
+				CapacityReservationID:               e.CapacityReservationID,
+				CapacityReservationResourceGroupARN: e.CapacityReservationResourceGroupARN,
+			},
+		}
 	}
 	if e.InstanceMonitoring != nil {
 		data.Monitoring = &cloudformationLaunchTemplateMonitoring{
@@ -197,6 +197,55 @@ func TestLaunchTemplateCloudformationRender(t *testing.T) {
       }
     }
   }
+}`,
+		},
+		{
+			Resource: &LaunchTemplate{
+				Name:         fi.String("test"),
+				ID:           fi.String("test-11"),
+				InstanceType: fi.String("c5n.18xlarge"),
+				PlacementGroup: &PlacementGroup{
+					Name:     fi.String("nodes.test"),
+					Strategy: fi.String("cluster"),
+				},
+				CapacityReservationID:   fi.String("cr-1234567890abcdef0"),
+				HTTPTokens:              fi.String("optional"),
+				HTTPPutResponseHopLimit: fi.Int64(1),
+			},
+			Expected: `{
+  "Resources": {
+    "AWSEC2LaunchTemplatetest": {
+      "Type": "AWS::EC2::LaunchTemplate",
+      "Properties": {
+        "LaunchTemplateName": "test",
+        "LaunchTemplateData": {
+          "CapacityReservationSpecification": {
+            "CapacityReservationTarget": {
+              "CapacityReservationId": "cr-1234567890abcdef0"
+            }
+          },
+          "InstanceType": "c5n.18xlarge",
+          "MetadataOptions": {
+            "HttpPutResponseHopLimit": 1,
+            "HttpTokens": "optional"
+          },
+          "NetworkInterfaces": [
+            {
+              "DeleteOnTermination": true,
+              "DeviceIndex": 0
+            }
+          ],
+          "Placement": [
+            {
+              "GroupName": {
+                "Ref": "AWSEC2PlacementGroupnodestest"
+              }
+            }
+          ]
+        }
+      }
+    }
+  }
 }`,
 		},
 	}
@@ -45,7 +45,7 @@ type terraformLaunchTemplatePlacement struct {
 	// AvailabilityZone is the Availability Zone for the instance.
 	AvailabilityZone *string `json:"availability_zone,omitempty" cty:"availability_zone"`
 	// GroupName is the name of the placement group for the instance.
-	GroupName *string `json:"group_name,omitempty" cty:"group_name"`
+	GroupName *terraformWriter.Literal `json:"group_name,omitempty" cty:"group_name"`
 	// HostID is the ID of the Dedicated Host for the instance.
 	HostID *string `json:"host_id,omitempty" cty:"host_id"`
 	// SpreadDomain are reserved for future use.
@@ -54,6 +54,20 @@ type terraformLaunchTemplatePlacement struct {
 	Tenancy *string `json:"tenancy,omitempty" cty:"tenancy"`
 }
 
+type terraformLaunchTemplateCapacityReservationTarget struct {
+	// CapacityReservationID is the id of the capacity reservation to target.
+	CapacityReservationID *string `json:"capacity_reservation_id,omitempty" cty:"capacity_reservation_id"`
+	// CapacityReservationResourceGroupARN is the ARN of the capacity reservation resource group to target.
+	CapacityReservationResourceGroupARN *string `json:"capacity_reservation_resource_group_arn,omitempty" cty:"capacity_reservation_resource_group_arn"`
+}
+
+type terraformLaunchTemplateCapacityReservationSpecification struct {
+	// CapacityReservationPreference is the capacity reservation preference. Can be open or none.
+	CapacityReservationPreference *string `json:"capacity_reservation_preference,omitempty" cty:"capacity_reservation_preference"`
+	// CapacityReservationTarget is the capacity reservation to target.
+	CapacityReservationTarget *terraformLaunchTemplateCapacityReservationTarget `json:"capacity_reservation_target,omitempty" cty:"capacity_reservation_target"`
+}
+
 type terraformLaunchTemplateIAMProfile struct {
 	// Name is the name of the profile
 	Name *terraformWriter.Literal `json:"name,omitempty" cty:"name"`
@@ -133,6 +147,8 @@ type terraformLaunchTemplate struct {
 
 	// BlockDeviceMappings is the device mappings
 	BlockDeviceMappings []*terraformLaunchTemplateBlockDevice `json:"block_device_mappings,omitempty" cty:"block_device_mappings"`
+	// CapacityReservationSpecification is the capacity reservation targeting option
+	CapacityReservationSpecification *terraformLaunchTemplateCapacityReservationSpecification `json:"capacity_reservation_specification,omitempty" cty:"capacity_reservation_specification"`
 	// CreditSpecification is the credit option for CPU Usage on some instance types
 	CreditSpecification *terraformLaunchTemplateCreditSpecification `json:"credit_specification,omitempty" cty:"credit_specification"`
 	// EBSOptimized indicates if the root device is ebs optimized
@@ -235,8 +251,24 @@ func (t *LaunchTemplate) RenderTerraform(target *terraform.TerraformTarget, a, e
 	if e.SSHKey != nil {
 		tf.KeyName = e.SSHKey.TerraformLink()
 	}
-	if e.Tenancy != nil {
-		tf.Placement = []*terraformLaunchTemplatePlacement{{Tenancy: e.Tenancy}}
+	if e.Tenancy != nil || e.PlacementGroup != nil {
+		placement := &terraformLaunchTemplatePlacement{Tenancy: e.Tenancy}
+		if e.PlacementGroup != nil {
+			placement.GroupName = e.PlacementGroup.TerraformLink()
+		}
+		tf.Placement = []*terraformLaunchTemplatePlacement{placement}
+	}
+	if e.CapacityReservationPreference != nil {
+		tf.CapacityReservationSpecification = &terraformLaunchTemplateCapacityReservationSpecification{
+			CapacityReservationPreference: e.CapacityReservationPreference,
+		}
+	} else if e.CapacityReservationID != nil || e.CapacityReservationResourceGroupARN != nil {
+		tf.CapacityReservationSpecification = &terraformLaunchTemplateCapacityReservationSpecification{
+			CapacityReservationTarget: &terraformLaunchTemplateCapacityReservationTarget{
+				CapacityReservationID:               e.CapacityReservationID,
+				CapacityReservationResourceGroupARN: e.CapacityReservationResourceGroupARN,
+			},
+		}
 	}
 	if e.InstanceMonitoring != nil {
 		tf.Monitoring = []*terraformLaunchTemplateMonitoring{
@@ -179,6 +179,58 @@ resource "aws_launch_template" "test" {
   }
 }
 
+terraform {
+  required_version = ">= 0.12.26"
+  required_providers {
+    aws = {
+      "source"  = "hashicorp/aws"
+      "version" = ">= 3.34.0"
+    }
+  }
+}
+`,
+		},
+		{
+			Resource: &LaunchTemplate{
+				Name:         fi.String("test"),
+				ID:           fi.String("test-11"),
+				InstanceType: fi.String("c5n.18xlarge"),
+				PlacementGroup: &PlacementGroup{
+					Name:     fi.String("nodes.test"),
+					Strategy: fi.String("cluster"),
+				},
+				CapacityReservationID:   fi.String("cr-1234567890abcdef0"),
+				HTTPTokens:              fi.String("optional"),
+				HTTPPutResponseHopLimit: fi.Int64(1),
+			},
+			Expected: `provider "aws" {
+  region = "eu-west-2"
+}
+
+resource "aws_launch_template" "test" {
+  capacity_reservation_specification {
+    capacity_reservation_target {
+      capacity_reservation_id = "cr-1234567890abcdef0"
+    }
+  }
+  instance_type = "c5n.18xlarge"
+  lifecycle {
+    create_before_destroy = true
+  }
+  metadata_options {
+    http_endpoint               = "enabled"
+    http_put_response_hop_limit = 1
+    http_tokens                 = "optional"
+  }
+  name = "test"
+  network_interfaces {
+    delete_on_termination = true
+  }
+  placement {
+    group_name = aws_placement_group.nodes-test.name
+  }
+}
+
 terraform {
   required_version = ">= 0.12.26"
   required_providers {
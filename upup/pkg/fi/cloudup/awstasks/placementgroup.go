@@ -0,0 +1,221 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awstasks
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudformation"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraformWriter"
+)
+
+// PlacementGroup is an EC2 placement group used by the launch templates of an instance group.
+// +kops:fitask
+type PlacementGroup struct {
+	// Name is the name of the placement group, which is also its identifier within the region
+	Name      *string
+	Lifecycle fi.Lifecycle
+
+	// ID is the placement group id
+	ID *string
+	// Strategy is the placement strategy: cluster, partition or spread
+	Strategy *string
+	// PartitionCount is the number of partitions, only valid with the partition strategy
+	PartitionCount *int64
+
+	// Shared is set if this is an existing placement group not managed by kops
+	Shared *bool
+
+	// Tags is a map of aws tags that are added to the placement group
+	Tags map[string]string
+}
+
+var _ fi.CompareWithID = &PlacementGroup{}
+
+// CompareWithID implements the comparable interface
+func (e *PlacementGroup) CompareWithID() *string {
+	return e.Name
+}
+
+// Find is used to discover the placement group in the cloud provider
+func (e *PlacementGroup) Find(c *fi.Context) (*PlacementGroup, error) {
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	request := &ec2.DescribePlacementGroupsInput{
+		GroupNames: []*string{e.Name},
+	}
+	response, err := cloud.EC2().DescribePlacementGroups(request)
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidPlacementGroup.Unknown" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing placement groups: %v", err)
+	}
+	if response == nil || len(response.PlacementGroups) == 0 {
+		return nil, nil
+	}
+	if len(response.PlacementGroups) != 1 {
+		return nil, fmt.Errorf("found multiple placement groups with name %q", fi.StringValue(e.Name))
+	}
+
+	pg := response.PlacementGroups[0]
+	actual := &PlacementGroup{
+		Name:     pg.GroupName,
+		ID:       pg.GroupId,
+		Strategy: pg.Strategy,
+		Tags:     intersectTags(pg.Tags, e.Tags),
+	}
+	if aws.Int64Value(pg.PartitionCount) != 0 {
+		actual.PartitionCount = pg.PartitionCount
+	}
+
+	e.ID = actual.ID
+
+	// Avoid spurious changes
+	actual.Lifecycle = e.Lifecycle
+	actual.Shared = e.Shared
+	if fi.BoolValue(e.Shared) {
+		actual.Strategy = e.Strategy
+		actual.PartitionCount = e.PartitionCount
+		actual.Tags = e.Tags
+	}
+
+	return actual, nil
+}
+
+// Run is responsible for executing the task
+func (e *PlacementGroup) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+// CheckChanges is responsible for checking for invalid changes
+func (s *PlacementGroup) CheckChanges(a, e, changes *PlacementGroup) error {
+	if a == nil {
+		if e.Name == nil {
+			return fi.RequiredField("Name")
+		}
+		if !fi.BoolValue(e.Shared) && e.Strategy == nil {
+			return fi.RequiredField("Strategy")
+		}
+	}
+	if a != nil {
+		if changes.Strategy != nil {
+			return fi.CannotChangeField("Strategy")
+		}
+		if changes.PartitionCount != nil {
+			return fi.CannotChangeField("PartitionCount")
+		}
+	}
+	return nil
+}
+
+// RenderAWS is responsible for creating the placement group via the api
+func (_ *PlacementGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *PlacementGroup) error {
+	if fi.BoolValue(e.Shared) {
+		if a == nil {
+			return fmt.Errorf("placement group %q not found", fi.StringValue(e.Name))
+		}
+		return nil
+	}
+
+	if a == nil {
+		klog.V(2).Infof("Creating PlacementGroup with Name:%q", fi.StringValue(e.Name))
+
+		request := &ec2.CreatePlacementGroupInput{
+			GroupName:         e.Name,
+			Strategy:          e.Strategy,
+			PartitionCount:    e.PartitionCount,
+			TagSpecifications: awsup.EC2TagSpecification(ec2.ResourceTypePlacementGroup, e.Tags),
+		}
+		response, err := t.Cloud.EC2().CreatePlacementGroup(request)
+		if err != nil {
+			return fmt.Errorf("error creating PlacementGroup: %v", err)
+		}
+		e.ID = response.PlacementGroup.GroupId
+	}
+
+	return t.AddAWSTags(fi.StringValue(e.ID), e.Tags)
+}
+
+type terraformPlacementGroup struct {
+	Name           *string           `json:"name,omitempty" cty:"name"`
+	Strategy       *string           `json:"strategy,omitempty" cty:"strategy"`
+	PartitionCount *int64            `json:"partition_count,omitempty" cty:"partition_count"`
+	Tags           map[string]string `json:"tags,omitempty" cty:"tags"`
+}
+
+// RenderTerraform is responsible for rendering the terraform json
+func (_ *PlacementGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *PlacementGroup) error {
+	if fi.BoolValue(e.Shared) {
+		return nil
+	}
+
+	tf := &terraformPlacementGroup{
+		Name:           e.Name,
+		Strategy:       e.Strategy,
+		PartitionCount: e.PartitionCount,
+		Tags:           e.Tags,
+	}
+
+	return t.RenderResource("aws_placement_group", fi.StringValue(e.Name), tf)
+}
+
+// TerraformLink returns the terraform reference to the placement group name
+func (e *PlacementGroup) TerraformLink() *terraformWriter.Literal {
+	if fi.BoolValue(e.Shared) {
+		return terraformWriter.LiteralFromStringValue(fi.StringValue(e.Name))
+	}
+
+	return terraformWriter.LiteralProperty("aws_placement_group", fi.StringValue(e.Name), "name")
+}
+
+type cloudformationPlacementGroup struct {
+	Strategy       *string             `json:"Strategy,omitempty"`
+	PartitionCount *int64              `json:"PartitionCount,omitempty"`
+	Tags           []cloudformationTag `json:"Tags,omitempty"`
+}
+
+// RenderCloudformation is responsible for rendering the cloudformation json
+func (_ *PlacementGroup) RenderCloudformation(t *cloudformation.CloudformationTarget, a, e, changes *PlacementGroup) error {
+	if fi.BoolValue(e.Shared) {
+		return nil
+	}
+
+	cf := &cloudformationPlacementGroup{
+		Strategy:       e.Strategy,
+		PartitionCount: e.PartitionCount,
+		Tags:           buildCloudformationTags(e.Tags),
+	}
+
+	return t.RenderResource("AWS::EC2::PlacementGroup", fi.StringValue(e.Name), cf)
+}
+
+// CloudformationLink returns the cloudformation reference to the placement group name
+func (e *PlacementGroup) CloudformationLink() *cloudformation.Literal {
+	if fi.BoolValue(e.Shared) {
+		return cloudformation.LiteralString(fi.StringValue(e.Name))
+	}
+
+	return cloudformation.Ref("AWS::EC2::PlacementGroup", fi.StringValue(e.Name))
+}
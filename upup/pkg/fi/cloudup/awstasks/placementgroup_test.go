@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awstasks
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+func TestPlacementGroupCreate(t *testing.T) {
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	c := &mockec2.MockEC2{}
+	cloud.MockEC2 = c
+
+	// We define a function so we can rebuild the tasks, because we modify in-place when running
+	buildTasks := func() map[string]fi.Task {
+		pg1 := &PlacementGroup{
+			Name:           s("nodes.cluster.example.com"),
+			Lifecycle:      fi.LifecycleSync,
+			Strategy:       s(ec2.PlacementStrategyPartition),
+			PartitionCount: fi.Int64(3),
+			Shared:         fi.Bool(false),
+			Tags:           map[string]string{"kubernetes.io/cluster/cluster.example.com": "owned"},
+		}
+		return map[string]fi.Task{
+			"pg1": pg1,
+		}
+	}
+
+	{
+		allTasks := buildTasks()
+		pg1 := allTasks["pg1"].(*PlacementGroup)
+
+		target := &awsup.AWSAPITarget{
+			Cloud: cloud,
+		}
+
+		context, err := fi.NewContext(target, nil, cloud, nil, nil, nil, true, allTasks)
+		if err != nil {
+			t.Fatalf("error building context: %v", err)
+		}
+		defer context.Close()
+
+		if err := context.RunTasks(testRunTasksOptions); err != nil {
+			t.Fatalf("unexpected error during Run: %v", err)
+		}
+
+		if fi.StringValue(pg1.ID) == "" {
+			t.Fatalf("ID not set after create")
+		}
+
+		if len(c.PlacementGroups) != 1 {
+			t.Fatalf("Expected exactly one PlacementGroup; found %v", c.PlacementGroups)
+		}
+
+		actual := c.PlacementGroups[fi.StringValue(pg1.ID)]
+		if aws.StringValue(actual.Strategy) != ec2.PlacementStrategyPartition || aws.Int64Value(actual.PartitionCount) != 3 {
+			t.Fatalf("Unexpected PlacementGroup: %v", actual)
+		}
+	}
+
+	{
+		allTasks := buildTasks()
+		checkNoChanges(t, cloud, allTasks)
+	}
+}
+
+func TestSharedPlacementGroupNotFound(t *testing.T) {
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+	c := &mockec2.MockEC2{}
+	cloud.MockEC2 = c
+
+	allTasks := map[string]fi.Task{
+		"pg1": &PlacementGroup{
+			Name:      s("existing"),
+			Lifecycle: fi.LifecycleSync,
+			Shared:    fi.Bool(true),
+		},
+	}
+
+	target := &awsup.AWSAPITarget{
+		Cloud: cloud,
+	}
+
+	context, err := fi.NewContext(target, nil, cloud, nil, nil, nil, true, allTasks)
+	if err != nil {
+		t.Fatalf("error building context: %v", err)
+	}
+	defer context.Close()
+
+	if err := context.RunTasks(testRunTasksOptions); err == nil {
+		t.Fatalf("expected an error for a missing shared placement group")
+	}
+	if len(c.PlacementGroups) != 0 {
+		t.Fatalf("shared placement group should not be created; found %v", c.PlacementGroups)
+	}
+}
+
+func TestPlacementGroupTerraformRender(t *testing.T) {
+	cases := []*renderTest{
+		{
+			Resource: &PlacementGroup{
+				Name:           fi.String("nodes.test"),
+				Strategy:       fi.String("partition"),
+				PartitionCount: fi.Int64(3),
+				Tags: map[string]string{
+					"KubernetesCluster": "test",
+				},
+			},
+			Expected: `provider "aws" {
+  region = "eu-west-2"
+}
+
+resource "aws_placement_group" "nodes-test" {
+  name            = "nodes.test"
+  partition_count = 3
+  strategy        = "partition"
+  tags = {
+    "KubernetesCluster" = "test"
+  }
+}
+
+terraform {
+  required_version = ">= 0.12.26"
+  required_providers {
+    aws = {
+      "source"  = "hashicorp/aws"
+      "version" = ">= 3.34.0"
+    }
+  }
+}
+`,
+		},
+	}
+	doRenderTests(t, "RenderTerraform", cases)
+}
+
+func TestPlacementGroupCloudformationRender(t *testing.T) {
+	cases := []*renderTest{
+		{
+			Resource: &PlacementGroup{
+				Name:           fi.String("nodes.test"),
+				Strategy:       fi.String("partition"),
+				PartitionCount: fi.Int64(3),
+				Tags: map[string]string{
+					"KubernetesCluster": "test",
+				},
+			},
+			Expected: `{
+  "Resources": {
+    "AWSEC2PlacementGroupnodestest": {
+      "Type": "AWS::EC2::PlacementGroup",
+      "Properties": {
+        "Strategy": "partition",
+        "PartitionCount": 3,
+        "Tags": [
+          {
+            "Key": "KubernetesCluster",
+            "Value": "test"
+          }
+        ]
+      }
+    }
+  }
+}`,
+		},
+	}
+	doRenderTests(t, "RenderCloudformation", cases)
+}
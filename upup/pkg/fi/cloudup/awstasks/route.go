@@ -41,9 +41,10 @@ type Route struct {
 
 	// Exactly one of the below fields
 	// MUST be provided.
-	InternetGateway  *InternetGateway
-	NatGateway       *NatGateway
-	TransitGatewayID *string
+	EgressOnlyInternetGateway *EgressOnlyInternetGateway
+	InternetGateway           *InternetGateway
+	NatGateway                *NatGateway
+	TransitGatewayID          *string
 }
 
 func (e *Route) Find(c *fi.Context) (*Route, error) {
@@ -84,6 +85,9 @@ func (e *Route) Find(c *fi.Context) (*Route, error) {
 				CIDR:       r.DestinationCidrBlock,
 				IPv6CIDR:   r.DestinationIpv6CidrBlock,
 			}
+			if r.EgressOnlyInternetGatewayId != nil {
+				actual.EgressOnlyInternetGateway = &EgressOnlyInternetGateway{ID: r.EgressOnlyInternetGatewayId}
+			}
 			if r.GatewayId != nil {
 				actual.InternetGateway = &InternetGateway{ID: r.GatewayId}
 			}
@@ -101,6 +105,7 @@ func (e *Route) Find(c *fi.Context) (*Route, error) {
 				klog.V(2).Infof("found route is a blackhole route")
 				// These should be nil anyway, but just in case...
 				actual.Instance = nil
+				actual.EgressOnlyInternetGateway = nil
 				actual.InternetGateway = nil
 				actual.TransitGatewayID = nil
 			}
@@ -133,6 +138,9 @@ func (s *Route) CheckChanges(a, e, changes *Route) error {
 			return fmt.Errorf("cannot set more than 1 CIDR or IPv6CIDR")
 		}
 		targetCount := 0
+		if e.EgressOnlyInternetGateway != nil {
+			targetCount++
+		}
 		if e.InternetGateway != nil {
 			targetCount++
 		}
@@ -146,10 +154,10 @@ func (s *Route) CheckChanges(a, e, changes *Route) error {
 			targetCount++
 		}
 		if targetCount == 0 {
-			return fmt.Errorf("InternetGateway, Instance, NatGateway, or TransitGateway is required")
+			return fmt.Errorf("EgressOnlyInternetGateway, InternetGateway, Instance, NatGateway, or TransitGateway is required")
 		}
 		if targetCount != 1 {
-			return fmt.Errorf("cannot set more than 1 InternetGateway, Instance, NatGateway, or TransitGateway")
+			return fmt.Errorf("cannot set more than 1 EgressOnlyInternetGateway, InternetGateway, Instance, NatGateway, or TransitGateway")
 		}
 	}
 
@@ -179,8 +187,10 @@ func (_ *Route) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Route) error {
 			klog.Fatal("both CIDR and IPv6CIDR were unexpectedly nil")
 		}
 
-		if e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
+		if e.EgressOnlyInternetGateway == nil && e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
 			return fmt.Errorf("missing target for route")
+		} else if e.EgressOnlyInternetGateway != nil {
+			request.EgressOnlyInternetGatewayId = checkNotNil(e.EgressOnlyInternetGateway.ID)
 		} else if e.InternetGateway != nil {
 			request.GatewayId = checkNotNil(e.InternetGateway.ID)
 		} else if e.NatGateway != nil {
@@ -221,8 +231,10 @@ func (_ *Route) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Route) error {
 			klog.Fatal("both CIDR and IPv6CIDR were unexpectedly nil")
 		}
 
-		if e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
+		if e.EgressOnlyInternetGateway == nil && e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
 			return fmt.Errorf("missing target for route")
+		} else if e.EgressOnlyInternetGateway != nil {
+			request.EgressOnlyInternetGatewayId = checkNotNil(e.EgressOnlyInternetGateway.ID)
 		} else if e.InternetGateway != nil {
 			request.GatewayId = checkNotNil(e.InternetGateway.ID)
 		} else if e.NatGateway != nil {
@@ -235,7 +247,8 @@ func (_ *Route) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Route) error {
 			request.InstanceId = checkNotNil(e.Instance.ID)
 		}
 
-		klog.V(2).Infof("Updating Route with RouteTable:%q CIDR:%q", *e.RouteTable.ID, *e.CIDR)
+		klog.V(2).Infof("Updating Route with RouteTable:%q CIDR:%q IPv6CIDR:%q",
+			aws.StringValue(e.RouteTable.ID), aws.StringValue(e.CIDR), aws.StringValue(e.IPv6CIDR))
 
 		if _, err := t.Cloud.EC2().ReplaceRoute(request); err != nil {
 			code := awsup.AWSErrorCode(err)
@@ -259,13 +272,14 @@ func checkNotNil(s *string) *string {
 }
 
 type terraformRoute struct {
-	RouteTableID      *terraformWriter.Literal `json:"route_table_id" cty:"route_table_id"`
-	CIDR              *string                  `json:"destination_cidr_block,omitempty" cty:"destination_cidr_block"`
-	IPv6CIDR          *string                  `json:"destination_ipv6_cidr_block,omitempty" cty:"destination_ipv6_cidr_block"`
-	InternetGatewayID *terraformWriter.Literal `json:"gateway_id,omitempty" cty:"gateway_id"`
-	NATGatewayID      *terraformWriter.Literal `json:"nat_gateway_id,omitempty" cty:"nat_gateway_id"`
-	TransitGatewayID  *string                  `json:"transit_gateway_id,omitempty" cty:"transit_gateway_id"`
-	InstanceID        *terraformWriter.Literal `json:"instance_id,omitempty" cty:"instance_id"`
+	RouteTableID                *terraformWriter.Literal `json:"route_table_id" cty:"route_table_id"`
+	CIDR                        *string                  `json:"destination_cidr_block,omitempty" cty:"destination_cidr_block"`
+	IPv6CIDR                    *string                  `json:"destination_ipv6_cidr_block,omitempty" cty:"destination_ipv6_cidr_block"`
+	EgressOnlyInternetGatewayID *terraformWriter.Literal `json:"egress_only_gateway_id,omitempty" cty:"egress_only_gateway_id"`
+	InternetGatewayID           *terraformWriter.Literal `json:"gateway_id,omitempty" cty:"gateway_id"`
+	NATGatewayID                *terraformWriter.Literal `json:"nat_gateway_id,omitempty" cty:"nat_gateway_id"`
+	TransitGatewayID            *string                  `json:"transit_gateway_id,omitempty" cty:"transit_gateway_id"`
+	InstanceID                  *terraformWriter.Literal `json:"instance_id,omitempty" cty:"instance_id"`
 }
 
 func (_ *Route) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Route) error {
@@ -275,8 +289,10 @@ func (_ *Route) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Rou
 		IPv6CIDR:     e.IPv6CIDR,
 	}
 
-	if e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
+	if e.EgressOnlyInternetGateway == nil && e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
 		return fmt.Errorf("missing target for route")
+	} else if e.EgressOnlyInternetGateway != nil {
+		tf.EgressOnlyInternetGatewayID = e.EgressOnlyInternetGateway.TerraformLink()
 	} else if e.InternetGateway != nil {
 		tf.InternetGatewayID = e.InternetGateway.TerraformLink()
 	} else if e.NatGateway != nil {
@@ -296,13 +312,14 @@ func (_ *Route) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Rou
 }
 
 type cloudformationRoute struct {
-	RouteTableID      *cloudformation.Literal `json:"RouteTableId"`
-	CIDR              *string                 `json:"DestinationCidrBlock,omitempty"`
-	IPv6CIDR          *string                 `json:"DestinationIpv6CidrBlock,omitempty"`
-	InternetGatewayID *cloudformation.Literal `json:"GatewayId,omitempty"`
-	NATGatewayID      *cloudformation.Literal `json:"NatGatewayId,omitempty"`
-	TransitGatewayID  *string                 `json:"TransitGatewayId,omitempty"`
-	InstanceID        *cloudformation.Literal `json:"InstanceId,omitempty"`
+	RouteTableID                *cloudformation.Literal `json:"RouteTableId"`
+	CIDR                        *string                 `json:"DestinationCidrBlock,omitempty"`
+	IPv6CIDR                    *string                 `json:"DestinationIpv6CidrBlock,omitempty"`
+	EgressOnlyInternetGatewayID *cloudformation.Literal `json:"EgressOnlyInternetGatewayId,omitempty"`
+	InternetGatewayID           *cloudformation.Literal `json:"GatewayId,omitempty"`
+	NATGatewayID                *cloudformation.Literal `json:"NatGatewayId,omitempty"`
+	TransitGatewayID            *string                 `json:"TransitGatewayId,omitempty"`
+	InstanceID                  *cloudformation.Literal `json:"InstanceId,omitempty"`
 }
 
 func (_ *Route) RenderCloudformation(t *cloudformation.CloudformationTarget, a, e, changes *Route) error {
@@ -312,8 +329,10 @@ func (_ *Route) RenderCloudformation(t *cloudformation.CloudformationTarget, a,
 		IPv6CIDR:     e.IPv6CIDR,
 	}
 
-	if e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
+	if e.EgressOnlyInternetGateway == nil && e.InternetGateway == nil && e.NatGateway == nil && e.TransitGatewayID == nil {
 		return fmt.Errorf("missing target for route")
+	} else if e.EgressOnlyInternetGateway != nil {
+		tf.EgressOnlyInternetGatewayID = e.EgressOnlyInternetGateway.CloudformationLink()
 	} else if e.InternetGateway != nil {
 		tf.InternetGatewayID = e.InternetGateway.CloudformationLink()
 	} else if e.NatGateway != nil {
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awstasks
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/cloudformation"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraformWriter"
+)
+
+// ScheduledActionNamePrefix is the prefix of the names of the scheduled actions managed by kops.
+// The scheduled actions of an autoscaling group without this prefix are left alone.
+const ScheduledActionNamePrefix = "kops-"
+
+// ScheduledAction is a recurring change of the size of an autoscaling group
+type ScheduledAction struct {
+	// Name is the name of the scheduled action, starting with ScheduledActionNamePrefix
+	Name *string
+	// Recurrence is the cron expression of the scheduled action
+	Recurrence *string
+	// TimeZone is the time zone of the recurrence, UTC if not set
+	TimeZone *string
+	// MinSize is the new minimum size of the autoscaling group, unchanged if not set
+	MinSize *int64
+	// MaxSize is the new maximum size of the autoscaling group, unchanged if not set
+	MaxSize *int64
+	// DesiredCapacity is the new desired capacity of the autoscaling group, unchanged if not set
+	DesiredCapacity *int64
+}
+
+// findScheduledActions returns the scheduled actions managed by kops of the autoscaling group, sorted by name
+func findScheduledActions(cloud awsup.AWSCloud, name string) ([]*ScheduledAction, error) {
+	request := &autoscaling.DescribeScheduledActionsInput{
+		AutoScalingGroupName: aws.String(name),
+	}
+
+	var actions []*ScheduledAction
+	err := cloud.Autoscaling().DescribeScheduledActionsPages(request, func(p *autoscaling.DescribeScheduledActionsOutput, lastPage bool) bool {
+		for _, a := range p.ScheduledUpdateGroupActions {
+			if !strings.HasPrefix(aws.StringValue(a.ScheduledActionName), ScheduledActionNamePrefix) {
+				continue
+			}
+			actions = append(actions, &ScheduledAction{
+				Name:            a.ScheduledActionName,
+				Recurrence:      a.Recurrence,
+				TimeZone:        a.TimeZone,
+				MinSize:         a.MinSize,
+				MaxSize:         a.MaxSize,
+				DesiredCapacity: a.DesiredCapacity,
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing scheduled actions of AutoscalingGroup %q: %v", name, err)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return aws.StringValue(actions[i].Name) < aws.StringValue(actions[j].Name)
+	})
+	return actions, nil
+}
+
+// scheduledSize returns the expected size if the actual size is the one set by one of the scheduled actions,
+// so that the changes made by the schedule are not reverted, and the actual size otherwise
+func scheduledSize(actual *int64, expected *int64, actions []*ScheduledAction, size func(*ScheduledAction) *int64) *int64 {
+	if actual == nil {
+		return actual
+	}
+	for _, action := range actions {
+		if v := size(action); v != nil && *v == *actual {
+			return expected
+		}
+	}
+	return actual
+}
+
+// renderScheduledActions creates, updates and deletes the scheduled actions of the autoscaling group
+// so that they match the expected ones
+func (e *AutoscalingGroup) renderScheduledActions(t *awsup.AWSAPITarget, actual []*ScheduledAction) error {
+	existing := make(map[string]*ScheduledAction)
+	for _, a := range actual {
+		existing[aws.StringValue(a.Name)] = a
+	}
+
+	for _, action := range e.ScheduledActions {
+		name := aws.StringValue(action.Name)
+		if reflect.DeepEqual(existing[name], action) {
+			delete(existing, name)
+			continue
+		}
+		delete(existing, name)
+
+		klog.V(2).Infof("Putting scheduled action %q of AutoscalingGroup %q", name, fi.StringValue(e.Name))
+		request := &autoscaling.PutScheduledUpdateGroupActionInput{
+			AutoScalingGroupName: e.Name,
+			ScheduledActionName:  action.Name,
+			Recurrence:           action.Recurrence,
+			TimeZone:             action.TimeZone,
+			MinSize:              action.MinSize,
+			MaxSize:              action.MaxSize,
+			DesiredCapacity:      action.DesiredCapacity,
+		}
+		if _, err := t.Cloud.Autoscaling().PutScheduledUpdateGroupAction(request); err != nil {
+			return fmt.Errorf("error putting scheduled action %q of AutoscalingGroup: %v", name, err)
+		}
+	}
+
+	for name := range existing {
+		klog.V(2).Infof("Deleting scheduled action %q of AutoscalingGroup %q", name, fi.StringValue(e.Name))
+		request := &autoscaling.DeleteScheduledActionInput{
+			AutoScalingGroupName: e.Name,
+			ScheduledActionName:  aws.String(name),
+		}
+		if _, err := t.Cloud.Autoscaling().DeleteScheduledAction(request); err != nil {
+			return fmt.Errorf("error deleting scheduled action %q of AutoscalingGroup: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+type terraformAutoscalingSchedule struct {
+	ScheduledActionName  *string                  `json:"scheduled_action_name" cty:"scheduled_action_name"`
+	AutoScalingGroupName *terraformWriter.Literal `json:"autoscaling_group_name" cty:"autoscaling_group_name"`
+	Recurrence           *string                  `json:"recurrence,omitempty" cty:"recurrence"`
+	TimeZone             *string                  `json:"time_zone,omitempty" cty:"time_zone"`
+	MinSize              *int64                   `json:"min_size" cty:"min_size"`
+	MaxSize              *int64                   `json:"max_size" cty:"max_size"`
+	DesiredCapacity      *int64                   `json:"desired_capacity" cty:"desired_capacity"`
+}
+
+// renderScheduledActionsTerraform renders the scheduled actions of the autoscaling group as aws_autoscaling_schedule resources
+func (e *AutoscalingGroup) renderScheduledActionsTerraform(t *terraform.TerraformTarget) error {
+	// The terraform provider uses -1 for the sizes which are left unchanged
+	unchanged := func(v *int64) *int64 {
+		if v == nil {
+			return fi.Int64(-1)
+		}
+		return v
+	}
+
+	for _, action := range e.ScheduledActions {
+		tf := &terraformAutoscalingSchedule{
+			ScheduledActionName:  action.Name,
+			AutoScalingGroupName: e.TerraformLink(),
+			Recurrence:           action.Recurrence,
+			TimeZone:             action.TimeZone,
+			MinSize:              unchanged(action.MinSize),
+			MaxSize:              unchanged(action.MaxSize),
+			DesiredCapacity:      unchanged(action.DesiredCapacity),
+		}
+		if err := t.RenderResource("aws_autoscaling_schedule", fi.StringValue(e.Name)+"-"+fi.StringValue(action.Name), tf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type cloudformationScheduledAction struct {
+	AutoScalingGroupName *cloudformation.Literal `json:"AutoScalingGroupName"`
+	Recurrence           *string                 `json:"Recurrence,omitempty"`
+	TimeZone             *string                 `json:"TimeZone,omitempty"`
+	MinSize              *int64                  `json:"MinSize,omitempty"`
+	MaxSize              *int64                  `json:"MaxSize,omitempty"`
+	DesiredCapacity      *int64                  `json:"DesiredCapacity,omitempty"`
+}
+
+// renderScheduledActionsCloudformation renders the scheduled actions of the autoscaling group as
+// AWS::AutoScaling::ScheduledAction resources. CloudFormation names the scheduled actions itself.
+func (e *AutoscalingGroup) renderScheduledActionsCloudformation(t *cloudformation.CloudformationTarget) error {
+	for _, action := range e.ScheduledActions {
+		cf := &cloudformationScheduledAction{
+			AutoScalingGroupName: e.CloudformationLink(),
+			Recurrence:           action.Recurrence,
+			TimeZone:             action.TimeZone,
+			MinSize:              action.MinSize,
+			MaxSize:              action.MaxSize,
+			DesiredCapacity:      action.DesiredCapacity,
+		}
+		if err := t.RenderResource("AWS::AutoScaling::ScheduledAction", fi.StringValue(e.Name)+"-"+fi.StringValue(action.Name), cf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package awstasks
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -283,7 +284,7 @@ func subnetSlicesEqualIgnoreOrder(l, r []*Subnet) bool {
 type terraformSubnet struct {
 	VPCID            *terraformWriter.Literal `json:"vpc_id" cty:"vpc_id"`
 	CIDR             *string                  `json:"cidr_block" cty:"cidr_block"`
-	IPv6CIDR         *string                  `json:"ipv6_cidr_block" cty:"ipv6_cidr_block"`
+	IPv6CIDR         *terraformWriter.Literal `json:"ipv6_cidr_block" cty:"ipv6_cidr_block"`
 	AvailabilityZone *string                  `json:"availability_zone" cty:"availability_zone"`
 	Tags             map[string]string        `json:"tags,omitempty" cty:"tags"`
 }
@@ -307,20 +308,38 @@ func (_ *Subnet) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Su
 		return t.AddOutputVariableArray("subnet_ids", terraformWriter.LiteralFromStringValue(*e.ID))
 	}
 
-	if strings.HasPrefix(aws.StringValue(e.IPv6CIDR), "/") {
-		// TODO: Implement using "cidrsubnet"
-		// https://www.terraform.io/docs/language/functions/cidrsubnet.html
-		return fmt.Errorf("<cidrsubnet> in not supported with Terraform target: %q", aws.StringValue(e.IPv6CIDR))
-	}
-
 	tf := &terraformSubnet{
 		VPCID:            e.VPC.TerraformLink(),
 		CIDR:             e.CIDR,
-		IPv6CIDR:         e.IPv6CIDR,
 		AvailabilityZone: e.AvailabilityZone,
 		Tags:             e.Tags,
 	}
 
+	if strings.HasPrefix(aws.StringValue(e.IPv6CIDR), "/") {
+		// The VPC IPv6 CIDR is not known yet, so let terraform calculate the subnet CIDR
+		// https://www.terraform.io/docs/language/functions/cidrsubnet.html
+		if fi.BoolValue(e.VPC.Shared) {
+			return fmt.Errorf("IPv6 CIDR block of shared VPC not found, cannot calculate subnet CIDR %q", aws.StringValue(e.IPv6CIDR))
+		}
+		newSize, netNum, err := utils.ParseCIDRNotation(aws.StringValue(e.IPv6CIDR))
+		if err != nil {
+			return fmt.Errorf("error parsing CIDR subnet: %v", err)
+		}
+		// Amazon provided IPv6 CIDR blocks are always /56
+		newBits := newSize - 56
+		if newBits <= 0 {
+			return fmt.Errorf("subnet CIDR %q must be smaller than the VPC IPv6 CIDR block (/56)", aws.StringValue(e.IPv6CIDR))
+		}
+		vpcIPv6CIDR := terraformWriter.LiteralProperty("aws_vpc", *e.VPC.Name, "ipv6_cidr_block")
+		tf.IPv6CIDR = terraformWriter.LiteralFunctionExpression("cidrsubnet", []string{
+			vpcIPv6CIDR.ResourceType + "." + vpcIPv6CIDR.ResourceName + "." + vpcIPv6CIDR.ResourceProp,
+			strconv.Itoa(newBits),
+			strconv.FormatInt(netNum, 10),
+		})
+	} else if e.IPv6CIDR != nil {
+		tf.IPv6CIDR = terraformWriter.LiteralFromStringValue(*e.IPv6CIDR)
+	}
+
 	return t.RenderResource("aws_subnet", *e.Name, tf)
 }
 
@@ -341,7 +360,7 @@ func (e *Subnet) TerraformLink() *terraformWriter.Literal {
 type cloudformationSubnet struct {
 	VPCID            *cloudformation.Literal `json:"VpcId,omitempty"`
 	CIDR             *string                 `json:"CidrBlock,omitempty"`
-	IPv6CIDR         *string                 `json:"Ipv6CidrBlock,omitempty"`
+	IPv6CIDR         *cloudformation.Literal `json:"Ipv6CidrBlock,omitempty"`
 	AvailabilityZone *string                 `json:"AvailabilityZone,omitempty"`
 	Tags             []cloudformationTag     `json:"Tags,omitempty"`
 }
@@ -354,21 +373,42 @@ func (_ *Subnet) RenderCloudformation(t *cloudformation.CloudformationTarget, a,
 		return nil
 	}
 
-	if strings.HasPrefix(aws.StringValue(e.IPv6CIDR), "/") {
-		// TODO: Implement using "Fn::Cidr"
-		// https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/intrinsic-function-reference-cidr.html
-		return fmt.Errorf("<cidrsubnet> in not supported with CloudFormation target: %q", aws.StringValue(e.IPv6CIDR))
-	}
-
 	cf := &cloudformationSubnet{
 		VPCID:            e.VPC.CloudformationLink(),
 		CIDR:             e.CIDR,
-		IPv6CIDR:         e.IPv6CIDR,
 		AvailabilityZone: e.AvailabilityZone,
 		Tags:             buildCloudformationTags(e.Tags),
 	}
 
-	return t.RenderResource("AWS::EC2::Subnet", *e.Name, cf)
+	var dependsOn []*cloudformation.Literal
+	if strings.HasPrefix(aws.StringValue(e.IPv6CIDR), "/") {
+		// The VPC IPv6 CIDR is not known yet, so let CloudFormation calculate the subnet CIDR
+		// https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/intrinsic-function-reference-cidr.html
+		if fi.BoolValue(e.VPC.Shared) || e.AmazonIPv6CIDR == nil {
+			return fmt.Errorf("IPv6 CIDR block of shared VPC not found, cannot calculate subnet CIDR %q", aws.StringValue(e.IPv6CIDR))
+		}
+		newSize, netNum, err := utils.ParseCIDRNotation(aws.StringValue(e.IPv6CIDR))
+		if err != nil {
+			return fmt.Errorf("error parsing CIDR subnet: %v", err)
+		}
+		// Amazon provided IPv6 CIDR blocks are always /56
+		if newSize <= 56 {
+			return fmt.Errorf("subnet CIDR %q must be smaller than the VPC IPv6 CIDR block (/56)", aws.StringValue(e.IPv6CIDR))
+		}
+		// Fn::Cidr can only generate up to 256 CIDRs
+		if netNum >= 256 {
+			return fmt.Errorf("subnet CIDR %q is not supported with CloudFormation, as its number must be lower than 256", aws.StringValue(e.IPv6CIDR))
+		}
+		vpcIPv6CIDR := cloudformation.Select(0, cloudformation.GetAtt("AWS::EC2::VPC", *e.VPC.Name, "Ipv6CidrBlocks"))
+		cf.IPv6CIDR = cloudformation.Select(int(netNum), cloudformation.Cidr(vpcIPv6CIDR, int(netNum)+1, 128-newSize))
+
+		// The Ipv6CidrBlocks of the VPC are only set once the Amazon IPv6 CIDR block is associated
+		dependsOn = append(dependsOn, e.AmazonIPv6CIDR.CloudformationLink())
+	} else if e.IPv6CIDR != nil {
+		cf.IPv6CIDR = cloudformation.LiteralString(*e.IPv6CIDR)
+	}
+
+	return t.RenderResourceWithDependencies("AWS::EC2::Subnet", *e.Name, cf, dependsOn)
 }
 
 func (e *Subnet) CloudformationLink() *cloudformation.Literal {
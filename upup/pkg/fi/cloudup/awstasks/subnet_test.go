@@ -447,3 +447,115 @@ func TestSharedSubnetCreateDoesNotCreateNew(t *testing.T) {
 		checkNoChanges(t, cloud, allTasks)
 	}
 }
+
+func TestSubnetIPv6NetNumTerraformRender(t *testing.T) {
+	cases := []*renderTest{
+		{
+			Resource: &Subnet{
+				Name: fi.String("test"),
+				VPC: &VPC{
+					Name: fi.String("test"),
+				},
+				AvailabilityZone: fi.String("eu-west-2a"),
+				CIDR:             fi.String("172.20.32.0/19"),
+				IPv6CIDR:         fi.String("/64#1"),
+				Tags: map[string]string{
+					"KubernetesCluster": "test",
+				},
+			},
+			Expected: `provider "aws" {
+  region = "eu-west-2"
+}
+
+resource "aws_subnet" "test" {
+  availability_zone = "eu-west-2a"
+  cidr_block        = "172.20.32.0/19"
+  ipv6_cidr_block   = cidrsubnet(aws_vpc.test.ipv6_cidr_block, 8, 1)
+  tags = {
+    "KubernetesCluster" = "test"
+  }
+  vpc_id = aws_vpc.test.id
+}
+
+terraform {
+  required_version = ">= 0.12.26"
+  required_providers {
+    aws = {
+      "source"  = "hashicorp/aws"
+      "version" = ">= 3.34.0"
+    }
+  }
+}
+`,
+		},
+	}
+	doRenderTests(t, "RenderTerraform", cases)
+}
+
+func TestSubnetIPv6NetNumCloudformationRender(t *testing.T) {
+	cases := []*renderTest{
+		{
+			Resource: &Subnet{
+				Name: fi.String("test"),
+				VPC: &VPC{
+					Name: fi.String("test"),
+				},
+				AmazonIPv6CIDR: &VPCAmazonIPv6CIDRBlock{
+					Name: fi.String("AmazonIPv6"),
+				},
+				AvailabilityZone: fi.String("eu-west-2a"),
+				CIDR:             fi.String("172.20.32.0/19"),
+				IPv6CIDR:         fi.String("/64#1"),
+				Tags: map[string]string{
+					"KubernetesCluster": "test",
+				},
+			},
+			Expected: `{
+  "Resources": {
+    "AWSEC2Subnettest": {
+      "Type": "AWS::EC2::Subnet",
+      "Properties": {
+        "VpcId": {
+          "Ref": "AWSEC2VPCtest"
+        },
+        "CidrBlock": "172.20.32.0/19",
+        "Ipv6CidrBlock": {
+          "Fn::Select": [
+            "1",
+            {
+              "Fn::Cidr": [
+                {
+                  "Fn::Select": [
+                    "0",
+                    {
+                      "Fn::GetAtt": [
+                        "AWSEC2VPCtest",
+                        "Ipv6CidrBlocks"
+                      ]
+                    }
+                  ]
+                },
+                "2",
+                "64"
+              ]
+            }
+          ]
+        },
+        "AvailabilityZone": "eu-west-2a",
+        "Tags": [
+          {
+            "Key": "KubernetesCluster",
+            "Value": "test"
+          }
+        ]
+      },
+      "DependsOn": [
+        "AWSEC2VPCCidrBlockAmazonIPv6"
+      ]
+    }
+  }
+}`,
+		},
+	}
+	doRenderTests(t, "RenderCloudformation", cases)
+}
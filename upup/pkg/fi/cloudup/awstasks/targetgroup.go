@@ -18,6 +18,7 @@ package awstasks
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -47,8 +48,18 @@ type TargetGroup struct {
 
 	HealthyThreshold   *int64
 	UnhealthyThreshold *int64
+
+	HealthCheckProtocol *string
+	HealthCheckPort     *string
+	HealthCheckPath     *string
+	HealthCheckInterval *int64
+
+	// DeregistrationDelay is the time, in seconds, the load balancer waits before removing a deregistered target
+	DeregistrationDelay *int64
 }
 
+const targetGroupAttributeDeregistrationDelay = "deregistration_delay.timeout_seconds"
+
 var _ fi.CompareWithID = &TargetGroup{}
 
 func (e *TargetGroup) CompareWithID() *string {
@@ -92,9 +103,32 @@ func (e *TargetGroup) Find(c *fi.Context) (*TargetGroup, error) {
 		HealthyThreshold:   tg.HealthyThresholdCount,
 		UnhealthyThreshold: tg.UnhealthyThresholdCount,
 		VPC:                &VPC{ID: tg.VpcId},
+
+		HealthCheckProtocol: tg.HealthCheckProtocol,
+		HealthCheckPort:     tg.HealthCheckPort,
+		HealthCheckPath:     tg.HealthCheckPath,
+		HealthCheckInterval: tg.HealthCheckIntervalSeconds,
 	}
 	e.ARN = tg.TargetGroupArn
 
+	if e.DeregistrationDelay != nil {
+		attributesResp, err := cloud.ELBV2().DescribeTargetGroupAttributes(&elbv2.DescribeTargetGroupAttributesInput{
+			TargetGroupArn: tg.TargetGroupArn,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing attributes of target group %q: %v", fi.StringValue(tg.TargetGroupName), err)
+		}
+		for _, attribute := range attributesResp.Attributes {
+			if fi.StringValue(attribute.Key) == targetGroupAttributeDeregistrationDelay {
+				delay, err := strconv.ParseInt(fi.StringValue(attribute.Value), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing deregistration delay %q of target group %q: %v", fi.StringValue(attribute.Value), fi.StringValue(tg.TargetGroupName), err)
+				}
+				actual.DeregistrationDelay = fi.Int64(delay)
+			}
+		}
+	}
+
 	tagsResp, err := cloud.ELBV2().DescribeTags(&elbv2.DescribeTagsInput{
 		ResourceArns: []*string{tg.TargetGroupArn},
 	})
@@ -171,13 +205,17 @@ func (_ *TargetGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *TargetGrou
 
 	if a == nil {
 		request := &elbv2.CreateTargetGroupInput{
-			Name:                    e.Name,
-			Port:                    e.Port,
-			Protocol:                e.Protocol,
-			VpcId:                   e.VPC.ID,
-			HealthyThresholdCount:   e.HealthyThreshold,
-			UnhealthyThresholdCount: e.UnhealthyThreshold,
-			Tags:                    awsup.ELBv2Tags(e.Tags),
+			Name:                       e.Name,
+			Port:                       e.Port,
+			Protocol:                   e.Protocol,
+			VpcId:                      e.VPC.ID,
+			HealthyThresholdCount:      e.HealthyThreshold,
+			UnhealthyThresholdCount:    e.UnhealthyThreshold,
+			HealthCheckProtocol:        e.HealthCheckProtocol,
+			HealthCheckPort:            e.HealthCheckPort,
+			HealthCheckPath:            e.HealthCheckPath,
+			HealthCheckIntervalSeconds: e.HealthCheckInterval,
+			Tags:                       awsup.ELBv2Tags(e.Tags),
 		}
 
 		klog.V(2).Infof("Creating Target Group for NLB")
@@ -188,12 +226,61 @@ func (_ *TargetGroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *TargetGrou
 
 		targetGroupArn := *response.TargetGroups[0].TargetGroupArn
 		e.ARN = fi.String(targetGroupArn)
+
+		if e.DeregistrationDelay != nil {
+			if err := modifyTargetGroupDeregistrationDelay(t.Cloud, e); err != nil {
+				return err
+			}
+		}
 	} else {
 		if a.ARN != nil {
 			if err := t.AddELBV2Tags(fi.StringValue(a.ARN), e.Tags); err != nil {
 				return err
 			}
 		}
+
+		if changes.HealthyThreshold != nil || changes.UnhealthyThreshold != nil || changes.HealthCheckProtocol != nil ||
+			changes.HealthCheckPort != nil || changes.HealthCheckPath != nil || changes.HealthCheckInterval != nil {
+			request := &elbv2.ModifyTargetGroupInput{
+				TargetGroupArn:             a.ARN,
+				HealthyThresholdCount:      e.HealthyThreshold,
+				UnhealthyThresholdCount:    e.UnhealthyThreshold,
+				HealthCheckProtocol:        e.HealthCheckProtocol,
+				HealthCheckPort:            e.HealthCheckPort,
+				HealthCheckPath:            e.HealthCheckPath,
+				HealthCheckIntervalSeconds: e.HealthCheckInterval,
+			}
+
+			klog.V(2).Infof("Modifying health check of target group %q", fi.StringValue(e.Name))
+			if _, err := t.Cloud.ELBV2().ModifyTargetGroup(request); err != nil {
+				return fmt.Errorf("error modifying target group %q: %v", fi.StringValue(e.Name), err)
+			}
+		}
+
+		if changes.DeregistrationDelay != nil {
+			e.ARN = a.ARN
+			if err := modifyTargetGroupDeregistrationDelay(t.Cloud, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func modifyTargetGroupDeregistrationDelay(cloud awsup.AWSCloud, e *TargetGroup) error {
+	request := &elbv2.ModifyTargetGroupAttributesInput{
+		TargetGroupArn: e.ARN,
+		Attributes: []*elbv2.TargetGroupAttribute{
+			{
+				Key:   aws.String(targetGroupAttributeDeregistrationDelay),
+				Value: aws.String(strconv.FormatInt(fi.Int64Value(e.DeregistrationDelay), 10)),
+			},
+		},
+	}
+
+	klog.V(2).Infof("Setting deregistration delay of target group %q to %ds", fi.StringValue(e.Name), fi.Int64Value(e.DeregistrationDelay))
+	if _, err := cloud.ELBV2().ModifyTargetGroupAttributes(request); err != nil {
+		return fmt.Errorf("error modifying attributes of target group %q: %v", fi.StringValue(e.Name), err)
 	}
 	return nil
 }
@@ -214,12 +301,17 @@ type terraformTargetGroup struct {
 	VPCID       terraformWriter.Literal         `json:"vpc_id" cty:"vpc_id"`
 	Tags        map[string]string               `json:"tags,omitempty" cty:"tags"`
 	HealthCheck terraformTargetGroupHealthCheck `json:"health_check" cty:"health_check"`
+
+	DeregistrationDelay *int64 `json:"deregistration_delay,omitempty" cty:"deregistration_delay"`
 }
 
 type terraformTargetGroupHealthCheck struct {
-	HealthyThreshold   int64  `json:"healthy_threshold" cty:"healthy_threshold"`
-	UnhealthyThreshold int64  `json:"unhealthy_threshold" cty:"unhealthy_threshold"`
-	Protocol           string `json:"protocol" cty:"protocol"`
+	HealthyThreshold   int64   `json:"healthy_threshold" cty:"healthy_threshold"`
+	UnhealthyThreshold int64   `json:"unhealthy_threshold" cty:"unhealthy_threshold"`
+	Protocol           string  `json:"protocol" cty:"protocol"`
+	Port               *string `json:"port,omitempty" cty:"port"`
+	Path               *string `json:"path,omitempty" cty:"path"`
+	Interval           *int64  `json:"interval,omitempty" cty:"interval"`
 }
 
 func (_ *TargetGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *TargetGroup) error {
@@ -232,6 +324,11 @@ func (_ *TargetGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, change
 		return fmt.Errorf("Missing VPC task from target group:\n%v\n%v", e, e.VPC)
 	}
 
+	healthCheckProtocol := elbv2.ProtocolEnumTcp
+	if e.HealthCheckProtocol != nil {
+		healthCheckProtocol = *e.HealthCheckProtocol
+	}
+
 	tf := &terraformTargetGroup{
 		Name:     *e.Name,
 		Port:     *e.Port,
@@ -241,8 +338,12 @@ func (_ *TargetGroup) RenderTerraform(t *terraform.TerraformTarget, a, e, change
 		HealthCheck: terraformTargetGroupHealthCheck{
 			HealthyThreshold:   *e.HealthyThreshold,
 			UnhealthyThreshold: *e.UnhealthyThreshold,
-			Protocol:           elbv2.ProtocolEnumTcp,
+			Protocol:           healthCheckProtocol,
+			Port:               e.HealthCheckPort,
+			Path:               e.HealthCheckPath,
+			Interval:           e.HealthCheckInterval,
 		},
+		DeregistrationDelay: e.DeregistrationDelay,
 	}
 
 	return t.RenderResource("aws_lb_target_group", *e.Name, tf)
@@ -267,9 +368,19 @@ type cloudformationTargetGroup struct {
 	VPCID    *cloudformation.Literal `json:"VpcId"`
 	Tags     []cloudformationTag     `json:"Tags"`
 
-	HealthCheckProtocol string `json:"HealthCheckProtocol"`
-	HealthyThreshold    int64  `json:"HealthyThresholdCount"`
-	UnhealthyThreshold  int64  `json:"UnhealthyThresholdCount"`
+	HealthCheckProtocol        string  `json:"HealthCheckProtocol"`
+	HealthCheckPort            *string `json:"HealthCheckPort,omitempty"`
+	HealthCheckPath            *string `json:"HealthCheckPath,omitempty"`
+	HealthCheckIntervalSeconds *int64  `json:"HealthCheckIntervalSeconds,omitempty"`
+	HealthyThreshold           int64   `json:"HealthyThresholdCount"`
+	UnhealthyThreshold         int64   `json:"UnhealthyThresholdCount"`
+
+	TargetGroupAttributes []cloudformationTargetGroupAttribute `json:"TargetGroupAttributes,omitempty"`
+}
+
+type cloudformationTargetGroupAttribute struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
 }
 
 func (_ *TargetGroup) RenderCloudformation(t *cloudformation.CloudformationTarget, a, e, changes *TargetGroup) error {
@@ -278,15 +389,29 @@ func (_ *TargetGroup) RenderCloudformation(t *cloudformation.CloudformationTarge
 		return nil
 	}
 
+	healthCheckProtocol := *e.Protocol
+	if e.HealthCheckProtocol != nil {
+		healthCheckProtocol = *e.HealthCheckProtocol
+	}
+
 	cf := &cloudformationTargetGroup{
-		Name:                *e.Name,
-		Port:                *e.Port,
-		Protocol:            *e.Protocol,
-		VPCID:               e.VPC.CloudformationLink(),
-		Tags:                buildCloudformationTags(e.Tags),
-		HealthCheckProtocol: *e.Protocol,
-		HealthyThreshold:    *e.HealthyThreshold,
-		UnhealthyThreshold:  *e.UnhealthyThreshold,
+		Name:                       *e.Name,
+		Port:                       *e.Port,
+		Protocol:                   *e.Protocol,
+		VPCID:                      e.VPC.CloudformationLink(),
+		Tags:                       buildCloudformationTags(e.Tags),
+		HealthCheckProtocol:        healthCheckProtocol,
+		HealthCheckPort:            e.HealthCheckPort,
+		HealthCheckPath:            e.HealthCheckPath,
+		HealthCheckIntervalSeconds: e.HealthCheckInterval,
+		HealthyThreshold:           *e.HealthyThreshold,
+		UnhealthyThreshold:         *e.UnhealthyThreshold,
+	}
+	if e.DeregistrationDelay != nil {
+		cf.TargetGroupAttributes = append(cf.TargetGroupAttributes, cloudformationTargetGroupAttribute{
+			Key:   targetGroupAttributeDeregistrationDelay,
+			Value: strconv.FormatInt(*e.DeregistrationDelay, 10),
+		})
 	}
 	return t.RenderResource("AWS::ElasticLoadBalancingV2::TargetGroup", *e.Name, cf)
 }
@@ -132,6 +132,10 @@ func (_ *VPCAmazonIPv6CIDRBlock) RenderCloudformation(t *cloudformation.Cloudfor
 	return t.RenderResource("AWS::EC2::VPCCidrBlock", *e.Name, cf)
 }
 
+func (e *VPCAmazonIPv6CIDRBlock) CloudformationLink() *cloudformation.Literal {
+	return cloudformation.Ref("AWS::EC2::VPCCidrBlock", *e.Name)
+}
+
 func findVPCIPv6CIDR(cloud awsup.AWSCloud, vpcID *string) (*string, error) {
 	vpc, err := cloud.DescribeVPC(aws.StringValue(vpcID))
 	if err != nil {
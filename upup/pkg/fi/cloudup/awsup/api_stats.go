@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+)
+
+// APICallStats are the statistics of the calls to an operation of an AWS API
+type APICallStats struct {
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+	// Calls is the number of calls, each of which can be made of several attempts
+	Calls int64 `json:"calls"`
+	// Errors is the number of calls which failed after all their attempts
+	Errors int64 `json:"errors"`
+	// Retries is the number of attempts retried
+	Retries int64 `json:"retries"`
+	// Throttles is the number of attempts rejected because of the API rate limits
+	Throttles int64 `json:"throttles"`
+	// TotalLatency is the total duration of the calls, including the retries and the waits for the rate budget
+	TotalLatency time.Duration `json:"totalLatency"`
+	// MaxLatency is the duration of the longest call
+	MaxLatency time.Duration `json:"maxLatency"`
+}
+
+// apiStats records the calls made by the AWS clients, and enforces the client-side rate budget
+type apiStats struct {
+	mutex      sync.Mutex
+	operations map[string]*APICallStats
+
+	// rateLimiter is the optional budget of requests shared by all the clients
+	rateLimiter flowcontrol.RateLimiter
+	// rateLimitWait is the total time the requests waited for the rate budget
+	rateLimitWait time.Duration
+}
+
+// defaultAPIStats is shared by all the AWS clients, so concurrent tasks share the rate budget
+var defaultAPIStats = newAPIStats()
+
+func newAPIStats() *apiStats {
+	return &apiStats{
+		operations: make(map[string]*APICallStats),
+	}
+}
+
+// SetAPIRateLimit sets the budget of AWS API requests per second shared by all the AWS clients.
+// Retries count against the budget. A qps of 0 removes the budget.
+func SetAPIRateLimit(qps float32, burst int) {
+	defaultAPIStats.setRateLimit(qps, burst)
+}
+
+// APIStats returns the statistics of the AWS API calls made by the AWS clients, ordered by service and operation
+func APIStats() []APICallStats {
+	return defaultAPIStats.list()
+}
+
+// APIRateLimitWait returns the total time the AWS API requests waited for the rate budget
+func APIRateLimitWait() time.Duration {
+	defaultAPIStats.mutex.Lock()
+	defer defaultAPIStats.mutex.Unlock()
+
+	return defaultAPIStats.rateLimitWait
+}
+
+func (s *apiStats) setRateLimit(qps float32, burst int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if qps <= 0 {
+		s.rateLimiter = nil
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	s.rateLimiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+}
+
+func (s *apiStats) list() []APICallStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var stats []APICallStats
+	for _, o := range s.operations {
+		stats = append(stats, *o)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Service != stats[j].Service {
+			return stats[i].Service < stats[j].Service
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
+
+// operation returns the stats of the operation of the request; the mutex must be held
+func (s *apiStats) operation(r *request.Request) *APICallStats {
+	service := r.ClientInfo.ServiceName
+	name := operationName(r)
+
+	key := service + "/" + name
+	o := s.operations[key]
+	if o == nil {
+		o = &APICallStats{Service: service, Operation: name}
+		s.operations[key] = o
+	}
+	return o
+}
+
+// addHandlers adds the handlers recording the calls, and waiting for the rate budget before each attempt
+func (s *apiStats) addHandlers(h *request.Handlers) {
+	h.Sign.PushFrontNamed(request.NamedHandler{
+		Name: "kops/api-rate-limit",
+		Fn:   s.waitForBudget,
+	})
+	h.AfterRetry.PushFrontNamed(request.NamedHandler{
+		Name: "kops/api-stats-afterretry",
+		Fn:   s.afterRetry,
+	})
+	h.Complete.PushBackNamed(request.NamedHandler{
+		Name: "kops/api-stats-complete",
+		Fn:   s.complete,
+	})
+}
+
+func (s *apiStats) waitForBudget(r *request.Request) {
+	s.mutex.Lock()
+	rateLimiter := s.rateLimiter
+	s.mutex.Unlock()
+
+	if rateLimiter == nil {
+		return
+	}
+
+	start := time.Now()
+	if err := rateLimiter.Wait(r.Context()); err != nil {
+		r.Error = err
+		return
+	}
+	waited := time.Since(start)
+	if waited > time.Second {
+		klog.V(4).Infof("AWS request %s/%s waited %v for the API rate budget", r.ClientInfo.ServiceName, operationName(r), waited)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rateLimitWait += waited
+}
+
+func (s *apiStats) afterRetry(r *request.Request) {
+	if r.Error == nil || !request.IsErrorThrottle(r.Error) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.operation(r).Throttles++
+}
+
+func (s *apiStats) complete(r *request.Request) {
+	latency := time.Since(r.Time)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	o := s.operation(r)
+	o.Calls++
+	if r.Error != nil {
+		o.Errors++
+	}
+	o.Retries += int64(r.RetryCount)
+	o.TotalLatency += latency
+	if latency > o.MaxLatency {
+		o.MaxLatency = latency
+	}
+}
+
+func operationName(r *request.Request) string {
+	if r.Operation == nil {
+		return "?"
+	}
+	return r.Operation.Name
+}
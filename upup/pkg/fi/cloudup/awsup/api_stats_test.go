@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// newTestEC2 builds an EC2 client for a server which throttles the first request of each operation
+func newTestEC2(t *testing.T, stats *apiStats) *ec2.EC2 {
+	var mutex sync.Mutex
+	throttled := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("error parsing request: %v", err)
+		}
+		action := r.Form.Get("Action")
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if action == "DescribeVpcs" || !throttled[action] {
+			throttled[action] = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>1</RequestID></Response>`))
+			return
+		}
+		w.Write([]byte(`<` + action + `Response xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"></` + action + `Response>`))
+	}))
+	t.Cleanup(server.Close)
+
+	config := aws.NewConfig().
+		WithRegion("us-test-1").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", ""))
+	config = request.WithRetryer(config, client.DefaultRetryer{
+		NumMaxRetries:    2,
+		MinRetryDelay:    time.Millisecond,
+		MaxRetryDelay:    time.Millisecond,
+		MinThrottleDelay: time.Millisecond,
+		MaxThrottleDelay: time.Millisecond,
+	})
+	sess, err := session.NewSession(config)
+	if err != nil {
+		t.Fatalf("error building session: %v", err)
+	}
+	c := ec2.New(sess)
+	stats.addHandlers(&c.Handlers)
+	return c
+}
+
+func TestAPIStats(t *testing.T) {
+	stats := newAPIStats()
+	c := newTestEC2(t, stats)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.DescribeRegions(&ec2.DescribeRegionsInput{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := c.DescribeVpcs(&ec2.DescribeVpcsInput{}); err == nil {
+		t.Fatalf("expected an error when all the attempts are throttled")
+	}
+
+	actual := stats.list()
+	if len(actual) != 2 {
+		t.Fatalf("expected stats for 2 operations, got %v", actual)
+	}
+
+	regions := actual[0]
+	if regions.Service != "ec2" || regions.Operation != "DescribeRegions" {
+		t.Errorf("unexpected operation %s/%s", regions.Service, regions.Operation)
+	}
+	if regions.Calls != 2 || regions.Errors != 0 || regions.Retries != 1 || regions.Throttles != 1 {
+		t.Errorf("unexpected DescribeRegions stats %+v", regions)
+	}
+	if regions.TotalLatency <= 0 || regions.MaxLatency <= 0 || regions.MaxLatency > regions.TotalLatency {
+		t.Errorf("unexpected DescribeRegions latencies %+v", regions)
+	}
+
+	vpcs := actual[1]
+	if vpcs.Operation != "DescribeVpcs" || vpcs.Calls != 1 || vpcs.Errors != 1 || vpcs.Retries != 2 || vpcs.Throttles != 3 {
+		t.Errorf("unexpected DescribeVpcs stats %+v", vpcs)
+	}
+}
+
+func TestAPIRateLimit(t *testing.T) {
+	stats := newAPIStats()
+	stats.setRateLimit(20, 1)
+	c := newTestEC2(t, stats)
+
+	// The first call is throttled once, so 6 requests are made, 5 of which wait for 50ms
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.DescribeRegions(&ec2.DescribeRegionsInput{}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected the requests to be limited by the rate budget, took %v", elapsed)
+	}
+	if stats.rateLimitWait <= 0 {
+		t.Errorf("expected the requests to wait for the rate budget")
+	}
+
+	stats.setRateLimit(0, 0)
+	if stats.rateLimiter != nil {
+		t.Errorf("expected the rate budget to be removed")
+	}
+}
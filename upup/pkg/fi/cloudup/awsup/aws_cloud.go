@@ -95,6 +95,15 @@ const TagNameClusterOwnershipPrefix = "kubernetes.io/cluster/"
 
 const tagNameDetachedInstance = "kops.k8s.io/detached-from-asg"
 
+// TagNameProvisionedSubnetPrefix is the prefix of the tag that selects the subnets of a provisioned instance group
+const TagNameProvisionedSubnetPrefix = "kops.k8s.io/instance-group/"
+
+// EC2 tags the instances launched from a launch template with the template and its version
+const (
+	tagNameLaunchTemplateID      = "aws:ec2launchtemplate:id"
+	tagNameLaunchTemplateVersion = "aws:ec2launchtemplate:version"
+)
+
 const (
 	WellKnownAccountAmazonLinux2 = "137112412989"
 	WellKnownAccountCentOS       = "125523088429"
@@ -244,6 +253,7 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 		// e.g. https://github.com/kubernetes/kops/issues/605
 		config = config.WithCredentialsChainVerboseErrors(true)
 		config = request.WithRetryer(config, newLoggingRetryer(ClientMaxRetries))
+		config = withEndpointFromEnv(config)
 
 		// We have the updated aws sdk from 1.9, but don't have https://github.com/kubernetes/kubernetes/pull/55307
 		// Set the SleepDelay function to work around this
@@ -382,6 +392,7 @@ func NewAWSCloud(region string, tags map[string]string) (AWSCloud, error) {
 }
 
 func (c *awsCloudImplementation) addHandlers(regionName string, h *request.Handlers) {
+	defaultAPIStats.addHandlers(h)
 
 	delayer := c.getCrossRequestRetryDelay(regionName)
 	if delayer != nil {
@@ -446,6 +457,10 @@ func (c *awsCloudImplementation) DeleteGroup(g *cloudinstances.CloudInstanceGrou
 }
 
 func deleteGroup(c AWSCloud, g *cloudinstances.CloudInstanceGroup) error {
+	if lt, ok := g.Raw.(*ec2.LaunchTemplate); ok {
+		return deleteProvisionedGroup(c, g, lt)
+	}
+
 	asg := g.Raw.(*autoscaling.Group)
 
 	name := aws.StringValue(asg.AutoScalingGroupName)
@@ -517,6 +532,39 @@ func deleteGroup(c AWSCloud, g *cloudinstances.CloudInstanceGroup) error {
 	return nil
 }
 
+// deleteProvisionedGroup deletes the instances of a provisioned group, then its launch template
+func deleteProvisionedGroup(c AWSCloud, g *cloudinstances.CloudInstanceGroup, lt *ec2.LaunchTemplate) error {
+	name := aws.StringValue(lt.LaunchTemplateName)
+
+	var ids []*string
+	for _, members := range [][]*cloudinstances.CloudInstance{g.Ready, g.NeedUpdate} {
+		for _, i := range members {
+			ids = append(ids, aws.String(i.ID))
+		}
+	}
+	if len(ids) > 0 {
+		klog.V(2).Infof("Deleting instances launched from launch template %q", name)
+		req := &ec2.TerminateInstancesInput{
+			InstanceIds: ids,
+		}
+		if _, err := c.EC2().TerminateInstances(req); err != nil {
+			return fmt.Errorf("error deleting instances launched from launch template %q: %v", name, err)
+		}
+	}
+
+	klog.V(2).Infof("Deleting launch template %q", name)
+	req := &ec2.DeleteLaunchTemplateInput{
+		LaunchTemplateName: aws.String(name),
+	}
+	if _, err := c.EC2().DeleteLaunchTemplate(req); err != nil {
+		return fmt.Errorf("error deleting launch template %q: %v", name, err)
+	}
+
+	klog.V(8).Infof("deleted provisioned group: %q", name)
+
+	return nil
+}
+
 // DeleteInstance deletes an aws instance
 func (c *awsCloudImplementation) DeleteInstance(i *cloudinstances.CloudInstance) error {
 	if c.spotinst != nil {
@@ -573,7 +621,10 @@ func detachInstance(c AWSCloud, i *cloudinstances.CloudInstance) error {
 		return fmt.Errorf("id was not set on CloudInstance: %v", i)
 	}
 
-	asg := i.CloudInstanceGroup.Raw.(*autoscaling.Group)
+	asg, ok := i.CloudInstanceGroup.Raw.(*autoscaling.Group)
+	if !ok {
+		return fmt.Errorf("instance %q does not belong to an autoscaling group", id)
+	}
 	if err := c.CreateTags(id, map[string]string{tagNameDetachedInstance: *asg.AutoScalingGroupName}); err != nil {
 		return fmt.Errorf("error tagging instance %q: %v", id, err)
 	}
@@ -649,6 +700,19 @@ func getCloudGroups(c AWSCloud, cluster *kops.Cluster, instancegroups []*kops.In
 		}
 	}
 
+	for _, ig := range instancegroups {
+		if !ig.IsProvisioned() {
+			continue
+		}
+		group, err := awsBuildProvisionedCloudInstanceGroup(c, cluster, ig, nodeMap)
+		if err != nil {
+			return nil, fmt.Errorf("error getting cloud instance group %q: %v", ig.ObjectMeta.Name, err)
+		}
+		if group != nil {
+			groups[ig.ObjectMeta.Name] = group
+		}
+	}
+
 	return groups, nil
 
 }
@@ -856,6 +920,12 @@ func awsBuildCloudInstanceGroup(c AWSCloud, cluster *kops.Cluster, ig *kops.Inst
 			return nil, err
 		}
 	}
+	if model.UseInPlaceUpdates(cluster, ig) {
+		if err := findInPlaceUpdates(c, cg, g, newConfigName); err != nil {
+			return nil, fmt.Errorf("error finding in-place updates for autoscaling group %q: %v", cg.HumanName, err)
+		}
+	}
+
 	var detached []*string
 	for id, instance := range instances {
 		for _, tag := range instance.Tags {
@@ -881,6 +951,64 @@ func awsBuildCloudInstanceGroup(c AWSCloud, cluster *kops.Cluster, ig *kops.Inst
 	return cg, nil
 }
 
+// awsBuildProvisionedCloudInstanceGroup builds the group of instances that the provisioner launched from the launch template of the instance group.
+// Instances launched from an older version of the launch template need updating.
+func awsBuildProvisionedCloudInstanceGroup(c AWSCloud, cluster *kops.Cluster, ig *kops.InstanceGroup, nodeMap map[string]*v1.Node) (*cloudinstances.CloudInstanceGroup, error) {
+	name := ig.ObjectMeta.Name + "." + cluster.ObjectMeta.Name
+
+	output, err := c.EC2().DescribeLaunchTemplates(&ec2.DescribeLaunchTemplatesInput{
+		LaunchTemplateNames: []*string{aws.String(name)},
+	})
+	if err != nil {
+		if AWSErrorCode(err) == "InvalidLaunchTemplateName.NotFoundException" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error describing launch template %q: %v", name, err)
+	}
+	if len(output.LaunchTemplates) == 0 {
+		return nil, nil
+	}
+	lt := output.LaunchTemplates[0]
+	newConfigName := fmt.Sprintf("%s:%d", aws.StringValue(lt.LaunchTemplateId), aws.Int64Value(lt.LatestVersionNumber))
+
+	instances, err := findInstances(c, ig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch instances: %v", err)
+	}
+
+	cg := &cloudinstances.CloudInstanceGroup{
+		HumanName:     name,
+		InstanceGroup: ig,
+		MinSize:       int(fi.Int32Value(ig.Spec.MinSize)),
+		TargetSize:    len(instances),
+		MaxSize:       int(fi.Int32Value(ig.Spec.MaxSize)),
+		Raw:           lt,
+	}
+
+	for id, instance := range instances {
+		var ltID, ltVersion string
+		for _, tag := range instance.Tags {
+			switch aws.StringValue(tag.Key) {
+			case tagNameLaunchTemplateID:
+				ltID = aws.StringValue(tag.Value)
+			case tagNameLaunchTemplateVersion:
+				ltVersion = aws.StringValue(tag.Value)
+			}
+		}
+		status := cloudinstances.CloudInstanceStatusUpToDate
+		if ltID+":"+ltVersion != newConfigName {
+			status = cloudinstances.CloudInstanceStatusNeedsUpdate
+		}
+		cm, err := cg.NewCloudInstance(id, status, nodeMap[id])
+		if err != nil {
+			return nil, fmt.Errorf("error creating cloud instance group member: %v", err)
+		}
+		addCloudInstanceData(cm, instance)
+	}
+
+	return cg, nil
+}
+
 func buildCloudInstance(i *autoscaling.Instance, instances map[string]*ec2.Instance, instanceSeen map[string]bool, nodeMap map[string]*v1.Node, cg *cloudinstances.CloudInstanceGroup, newConfigName string) error {
 	id := aws.StringValue(i.InstanceId)
 	if id == "" {
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/kops/cloudmock/aws/mockautoscaling"
+	"k8s.io/kops/cloudmock/aws/mockec2"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestProvisionedCloudGroups(t *testing.T) {
+	cloud := BuildMockAWSCloud("us-east-1", "a").WithTags(map[string]string{TagClusterName: "test.k8s.local"}).(*MockAWSCloud)
+	ec2Mock := &mockec2.MockEC2{}
+	cloud.MockEC2 = ec2Mock
+	cloud.MockAutoscaling = &mockautoscaling.MockAutoscaling{}
+
+	vpc, err := ec2Mock.CreateVpc(&ec2.CreateVpcInput{CidrBlock: aws.String("172.20.0.0/16")})
+	if err != nil {
+		t.Fatalf("error creating vpc: %v", err)
+	}
+	subnet, err := ec2Mock.CreateSubnet(&ec2.CreateSubnetInput{
+		AvailabilityZone: aws.String("us-east-1a"),
+		CidrBlock:        aws.String("172.20.32.0/19"),
+		VpcId:            vpc.Vpc.VpcId,
+	})
+	if err != nil {
+		t.Fatalf("error creating subnet: %v", err)
+	}
+
+	name := "nodes.test.k8s.local"
+	data := &ec2.RequestLaunchTemplateData{
+		ImageId:      aws.String("ami-1"),
+		InstanceType: aws.String("t3.medium"),
+		TagSpecifications: []*ec2.LaunchTemplateTagSpecificationRequest{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeInstance),
+				Tags: []*ec2.Tag{
+					{Key: aws.String(TagClusterName), Value: aws.String("test.k8s.local")},
+					{Key: aws.String("kops.k8s.io/instancegroup"), Value: aws.String("nodes")},
+				},
+			},
+		},
+	}
+	if _, err := ec2Mock.CreateLaunchTemplate(&ec2.CreateLaunchTemplateInput{LaunchTemplateName: aws.String(name), LaunchTemplateData: data}); err != nil {
+		t.Fatalf("error creating launch template: %v", err)
+	}
+	launch := func() {
+		_, err := ec2Mock.RunInstances(&ec2.RunInstancesInput{
+			LaunchTemplate: &ec2.LaunchTemplateSpecification{LaunchTemplateName: aws.String(name)},
+			SubnetId:       subnet.Subnet.SubnetId,
+		})
+		if err != nil {
+			t.Fatalf("error running instance: %v", err)
+		}
+	}
+	launch()
+	data.ImageId = aws.String("ami-2")
+	if _, err := ec2Mock.CreateLaunchTemplateVersion(&ec2.CreateLaunchTemplateVersionInput{LaunchTemplateName: aws.String(name), LaunchTemplateData: data}); err != nil {
+		t.Fatalf("error creating launch template version: %v", err)
+	}
+	launch()
+
+	cluster := &kops.Cluster{}
+	cluster.Name = "test.k8s.local"
+	ig := &kops.InstanceGroup{}
+	ig.Name = "nodes"
+	ig.Spec.Role = kops.InstanceGroupRoleNode
+	ig.Spec.Manager = kops.InstanceManagerProvisioner
+
+	groups, err := getCloudGroups(cloud, cluster, []*kops.InstanceGroup{ig}, false, nil)
+	if err != nil {
+		t.Fatalf("error getting cloud groups: %v", err)
+	}
+	group := groups["nodes"]
+	if group == nil {
+		t.Fatalf("expected a cloud group for the provisioned instance group, got %v", groups)
+	}
+	if group.HumanName != name {
+		t.Errorf("expected group name %q, got %q", name, group.HumanName)
+	}
+	if group.TargetSize != 2 {
+		t.Errorf("expected target size 2, got %d", group.TargetSize)
+	}
+	if len(group.Ready) != 1 || len(group.NeedUpdate) != 1 {
+		t.Errorf("expected 1 ready and 1 outdated instance, got %d and %d", len(group.Ready), len(group.NeedUpdate))
+	}
+
+	if err := deleteGroup(cloud, group); err != nil {
+		t.Fatalf("error deleting group: %v", err)
+	}
+	if len(ec2Mock.Instances) != 0 {
+		t.Errorf("expected the instances to be terminated, got %d", len(ec2Mock.Instances))
+	}
+	if len(ec2Mock.LaunchTemplates) != 0 {
+		t.Errorf("expected the launch template to be deleted, got %d", len(ec2Mock.LaunchTemplates))
+	}
+}
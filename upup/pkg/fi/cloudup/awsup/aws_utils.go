@@ -37,6 +37,19 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 )
 
+// EnvEndpointURL is the environment variable overriding the endpoint of all the AWS APIs,
+// for example to use the APIs served by "kops toolbox fake-cloud"
+const EnvEndpointURL = "AWS_ENDPOINT_URL"
+
+// withEndpointFromEnv sets the endpoint from EnvEndpointURL on the config, if set
+func withEndpointFromEnv(config *aws.Config) *aws.Config {
+	if endpoint := os.Getenv(EnvEndpointURL); endpoint != "" {
+		klog.V(2).Infof("Using AWS endpoint %q from %s", endpoint, EnvEndpointURL)
+		config = config.WithEndpoint(endpoint)
+	}
+	return config
+}
+
 // allRegions is the list of all regions; tests will set the values
 var allRegions []*ec2.Region
 var allRegionsMutex sync.Mutex
@@ -74,6 +87,7 @@ func ValidateRegion(region string) error {
 		}
 		config := aws.NewConfig().WithRegion(awsRegion)
 		config = config.WithCredentialsChainVerboseErrors(true)
+		config = withEndpointFromEnv(config)
 
 		sess, err := session.NewSessionWithOptions(session.Options{
 			Config:            *config,
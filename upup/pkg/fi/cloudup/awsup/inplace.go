@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/model/resources"
+)
+
+// findInPlaceUpdates marks the instances needing an update whose changes can be applied without replacing them.
+// Instances that already applied the changes in place are moved to the ready instances.
+func findInPlaceUpdates(c AWSCloud, cg *cloudinstances.CloudInstanceGroup, g *autoscaling.Group, newConfigName string) error {
+	if aws.StringValue(g.LaunchConfigurationName) != "" {
+		// Only launch templates keep the previous versions around for comparison
+		return nil
+	}
+	if len(cg.NeedUpdate) == 0 {
+		return nil
+	}
+
+	newData, err := findLaunchTemplateVersionData(c, newConfigName)
+	if err != nil {
+		return err
+	}
+
+	instanceConfigNames := make(map[string]string)
+	for _, i := range g.Instances {
+		instanceConfigNames[aws.StringValue(i.InstanceId)] = findInstanceLaunchConfiguration(i)
+	}
+
+	type comparison struct {
+		inPlace bool
+		hash    string
+	}
+	comparisons := make(map[string]comparison)
+
+	var needUpdate []*cloudinstances.CloudInstance
+	for _, cm := range cg.NeedUpdate {
+		configName := instanceConfigNames[cm.ID]
+		if cm.Status != cloudinstances.CloudInstanceStatusNeedsUpdate || cm.State == cloudinstances.WarmPool || configName == "" {
+			needUpdate = append(needUpdate, cm)
+			continue
+		}
+
+		result, found := comparisons[configName]
+		if !found {
+			oldData, err := findLaunchTemplateVersionData(c, configName)
+			if err != nil {
+				return err
+			}
+			result.inPlace, result.hash, err = inPlaceLaunchTemplateChange(oldData, newData)
+			if err != nil {
+				return fmt.Errorf("error comparing launch template %q with %q: %v", configName, newConfigName, err)
+			}
+			klog.V(4).Infof("Changes from launch template %q to %q can be applied in place: %v", configName, newConfigName, result.inPlace)
+			comparisons[configName] = result
+		}
+
+		if !result.inPlace {
+			needUpdate = append(needUpdate, cm)
+			continue
+		}
+
+		cg.NodeupConfigHash = result.hash
+		if cm.Node != nil && cm.Node.Annotations[nodeup.AnnotationAppliedConfigHash] == result.hash {
+			cm.Status = cloudinstances.CloudInstanceStatusUpToDate
+			cg.Ready = append(cg.Ready, cm)
+			continue
+		}
+		cm.InPlaceUpdate = true
+		needUpdate = append(needUpdate, cm)
+	}
+	cg.NeedUpdate = needUpdate
+
+	return nil
+}
+
+// findLaunchTemplateVersionData returns the data of a launch template version, identified as "<id>:<version>"
+func findLaunchTemplateVersionData(c AWSCloud, configName string) (*ec2.ResponseLaunchTemplateData, error) {
+	tokens := strings.SplitN(configName, ":", 2)
+	if len(tokens) != 2 {
+		return nil, fmt.Errorf("unexpected launch template version %q", configName)
+	}
+
+	output, err := c.EC2().DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: aws.String(tokens[0]),
+		Versions:         []*string{aws.String(tokens[1])},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing launch template version %q: %v", configName, err)
+	}
+	if len(output.LaunchTemplateVersions) == 0 || output.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return nil, fmt.Errorf("launch template version %q not found", configName)
+	}
+	return output.LaunchTemplateVersions[0].LaunchTemplateData, nil
+}
+
+// inPlaceLaunchTemplateChange returns true if the two launch template versions only differ in userdata changes that
+// nodeup can apply on a running instance, together with the hash of the nodeup config of the new version
+func inPlaceLaunchTemplateChange(oldData, newData *ec2.ResponseLaunchTemplateData) (bool, string, error) {
+	oldCopy := *oldData
+	newCopy := *newData
+	oldCopy.UserData = nil
+	newCopy.UserData = nil
+	if !reflect.DeepEqual(oldCopy, newCopy) {
+		return false, "", nil
+	}
+
+	oldUserData, err := base64.StdEncoding.DecodeString(aws.StringValue(oldData.UserData))
+	if err != nil {
+		return false, "", fmt.Errorf("error decoding userdata: %v", err)
+	}
+	newUserData, err := base64.StdEncoding.DecodeString(aws.StringValue(newData.UserData))
+	if err != nil {
+		return false, "", fmt.Errorf("error decoding userdata: %v", err)
+	}
+
+	return resources.InPlaceUserDataChange(string(oldUserData), string(newUserData))
+}
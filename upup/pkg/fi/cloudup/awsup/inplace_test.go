@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func buildTestLaunchTemplateData(imageID string, hash string, replacementHash string) *ec2.ResponseLaunchTemplateData {
+	userData := "#!/bin/bash\n" +
+		"cat > conf/cluster_spec.yaml << '__EOF_CLUSTER_SPEC'\ncontainerRuntime: containerd\n__EOF_CLUSTER_SPEC\n" +
+		"cat > conf/kube_env.yaml << '__EOF_KUBE_ENV'\nInstanceGroupName: nodes\nNodeupConfigHash: " + hash + "\nNodeupConfigReplacementHash: " + replacementHash + "\n__EOF_KUBE_ENV\n"
+
+	return &ec2.ResponseLaunchTemplateData{
+		ImageId:      aws.String(imageID),
+		InstanceType: aws.String("t3.medium"),
+		UserData:     aws.String(base64.StdEncoding.EncodeToString([]byte(userData))),
+	}
+}
+
+func TestInPlaceLaunchTemplateChange(t *testing.T) {
+	oldData := buildTestLaunchTemplateData("ami-1", "oldhash", "replacementhash")
+
+	for _, test := range []struct {
+		name    string
+		newData *ec2.ResponseLaunchTemplateData
+		inPlace bool
+	}{
+		{
+			name:    "nodeup config changed",
+			newData: buildTestLaunchTemplateData("ami-1", "newhash", "replacementhash"),
+			inPlace: true,
+		},
+		{
+			name:    "nodeup config changed requiring replacement",
+			newData: buildTestLaunchTemplateData("ami-1", "newhash", "newreplacementhash"),
+		},
+		{
+			name:    "image changed",
+			newData: buildTestLaunchTemplateData("ami-2", "newhash", "replacementhash"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			inPlace, hash, err := inPlaceLaunchTemplateChange(oldData, test.newData)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inPlace != test.inPlace {
+				t.Errorf("expected in-place %v, got %v", test.inPlace, inPlace)
+			}
+			if inPlace && hash != "newhash" {
+				t.Errorf("expected hash %q, got %q", "newhash", hash)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// defaultDeregistrationDelay is the deregistration delay AWS uses when the target group does not set one
+const defaultDeregistrationDelay = 300 * time.Second
+
+var (
+	// targetDrainGracePeriod is added to the deregistration delay when waiting for a target to drain
+	targetDrainGracePeriod = 60 * time.Second
+
+	// targetDrainPollInterval is the time between checks that a deregistered target has drained
+	targetDrainPollInterval = 10 * time.Second
+)
+
+// ManagedTargetGroupName returns the name of a target group that kOps creates and owns for an instance group
+func ManagedTargetGroupName(clusterName string, igName string, spec *kops.TargetGroupSpec) string {
+	if name := aws.StringValue(spec.Name); name != "" {
+		return name
+	}
+	return GetResourceName32(clusterName, strings.ReplaceAll(igName, ".", "-")+"-"+strconv.FormatInt(spec.Port, 10))
+}
+
+// TargetGroupNameFromARN returns the name of a target group from its ARN,
+// which has the form arn:aws:elasticloadbalancing:<region>:<account>:targetgroup/<name>/<id>
+func TargetGroupNameFromARN(targetGroupARN string) string {
+	i := strings.Index(targetGroupARN, ":targetgroup/")
+	if i < 0 {
+		return ""
+	}
+	name := targetGroupARN[i+len(":targetgroup/"):]
+	if j := strings.Index(name, "/"); j >= 0 {
+		name = name[:j]
+	}
+	return name
+}
+
+// DeregisterInstanceFromTargetGroups deregisters an instance from the target groups and waits
+// until the load balancers have finished draining connections to it.
+// Target groups that no longer exist are ignored.
+func DeregisterInstanceFromTargetGroups(c AWSCloud, instanceID string, targetGroupARNs []string) error {
+	target := []*elbv2.TargetDescription{{Id: aws.String(instanceID)}}
+
+	timeout := time.Duration(0)
+	var registered []string
+	for _, targetGroupARN := range targetGroupARNs {
+		klog.V(2).Infof("deregistering instance %q from target group %q", instanceID, targetGroupARN)
+		_, err := c.ELBV2().DeregisterTargets(&elbv2.DeregisterTargetsInput{
+			TargetGroupArn: aws.String(targetGroupARN),
+			Targets:        target,
+		})
+		if err != nil {
+			if isTargetGroupNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("error deregistering instance %q from target group %q: %v", instanceID, targetGroupARN, err)
+		}
+		registered = append(registered, targetGroupARN)
+
+		delay, err := targetGroupDeregistrationDelay(c, targetGroupARN)
+		if err != nil {
+			return err
+		}
+		if delay > timeout {
+			timeout = delay
+		}
+	}
+
+	deadline := time.Now().Add(timeout + targetDrainGracePeriod)
+	for {
+		var draining []string
+		for _, targetGroupARN := range registered {
+			response, err := c.ELBV2().DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+				TargetGroupArn: aws.String(targetGroupARN),
+				Targets:        target,
+			})
+			if err != nil {
+				if isTargetGroupNotFound(err) {
+					continue
+				}
+				return fmt.Errorf("error describing health of instance %q in target group %q: %v", instanceID, targetGroupARN, err)
+			}
+			for _, description := range response.TargetHealthDescriptions {
+				if description.TargetHealth != nil && aws.StringValue(description.TargetHealth.State) == elbv2.TargetHealthStateEnumDraining {
+					draining = append(draining, targetGroupARN)
+				}
+			}
+		}
+
+		if len(draining) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for instance %q to drain from target groups %v", instanceID, draining)
+		}
+
+		klog.Infof("waiting for instance %q to drain from %d target group(s)", instanceID, len(draining))
+		time.Sleep(targetDrainPollInterval)
+	}
+}
+
+// targetGroupDeregistrationDelay returns how long the target group keeps deregistered targets draining
+func targetGroupDeregistrationDelay(c AWSCloud, targetGroupARN string) (time.Duration, error) {
+	response, err := c.ELBV2().DescribeTargetGroupAttributes(&elbv2.DescribeTargetGroupAttributesInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error describing attributes of target group %q: %v", targetGroupARN, err)
+	}
+
+	for _, attribute := range response.Attributes {
+		if aws.StringValue(attribute.Key) != "deregistration_delay.timeout_seconds" {
+			continue
+		}
+		seconds, err := strconv.Atoi(aws.StringValue(attribute.Value))
+		if err != nil {
+			return 0, fmt.Errorf("error parsing deregistration delay %q of target group %q: %v", aws.StringValue(attribute.Value), targetGroupARN, err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return defaultDeregistrationDelay, nil
+}
+
+func isTargetGroupNotFound(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == elbv2.ErrCodeTargetGroupNotFoundException
+	}
+	return false
+}
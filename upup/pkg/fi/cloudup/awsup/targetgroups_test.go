@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"k8s.io/kops/cloudmock/aws/mockelbv2"
+)
+
+// drainingELBV2 reports deregistered targets as draining for a number of health checks
+type drainingELBV2 struct {
+	*mockelbv2.MockELBV2
+	drainingChecks int
+	checks         int
+}
+
+func (m *drainingELBV2) DescribeTargetHealth(request *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+	m.checks++
+	if m.checks > m.drainingChecks {
+		return m.MockELBV2.DescribeTargetHealth(request)
+	}
+	return &elbv2.DescribeTargetHealthOutput{
+		TargetHealthDescriptions: []*elbv2.TargetHealthDescription{
+			{
+				Target:       request.Targets[0],
+				TargetHealth: &elbv2.TargetHealth{State: aws.String(elbv2.TargetHealthStateEnumDraining)},
+			},
+		},
+	}, nil
+}
+
+func setupDrainTest(t *testing.T, drainingChecks int, deregistrationDelay string) (*MockAWSCloud, *drainingELBV2, string) {
+	targetDrainPollInterval = time.Millisecond
+
+	mock := &drainingELBV2{MockELBV2: &mockelbv2.MockELBV2{}, drainingChecks: drainingChecks}
+	cloud := BuildMockAWSCloud("us-test-1", "a")
+	cloud.MockELBV2 = mock
+
+	tg, err := mock.CreateTargetGroup(&elbv2.CreateTargetGroupInput{
+		Name:     aws.String("ingress"),
+		Port:     aws.Int64(30080),
+		Protocol: aws.String(elbv2.ProtocolEnumTcp),
+	})
+	if err != nil {
+		t.Fatalf("error creating target group: %v", err)
+	}
+	arn := aws.StringValue(tg.TargetGroups[0].TargetGroupArn)
+
+	if _, err := mock.ModifyTargetGroupAttributes(&elbv2.ModifyTargetGroupAttributesInput{
+		TargetGroupArn: aws.String(arn),
+		Attributes: []*elbv2.TargetGroupAttribute{
+			{Key: aws.String("deregistration_delay.timeout_seconds"), Value: aws.String(deregistrationDelay)},
+		},
+	}); err != nil {
+		t.Fatalf("error setting deregistration delay: %v", err)
+	}
+
+	if _, err := mock.RegisterTargets(&elbv2.RegisterTargetsInput{
+		TargetGroupArn: aws.String(arn),
+		Targets:        []*elbv2.TargetDescription{{Id: aws.String("i-1")}, {Id: aws.String("i-2")}},
+	}); err != nil {
+		t.Fatalf("error registering targets: %v", err)
+	}
+
+	return cloud, mock, arn
+}
+
+func TestDeregisterInstanceFromTargetGroups(t *testing.T) {
+	cloud, mock, arn := setupDrainTest(t, 2, "30")
+
+	missing := "arn:aws:elasticloadbalancing:us-test-1:000000000000:targetgroup/deleted/99"
+	if err := DeregisterInstanceFromTargetGroups(cloud, "i-1", []string{arn, missing}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mock.checks != 3 {
+		t.Errorf("expected to wait for the target to drain, got %d health checks", mock.checks)
+	}
+
+	health, err := mock.MockELBV2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{TargetGroupArn: aws.String(arn)})
+	if err != nil {
+		t.Fatalf("error describing target health: %v", err)
+	}
+	if len(health.TargetHealthDescriptions) != 1 || aws.StringValue(health.TargetHealthDescriptions[0].Target.Id) != "i-2" {
+		t.Errorf("unexpected registered targets: %v", health.TargetHealthDescriptions)
+	}
+}
+
+func TestDeregisterInstanceFromTargetGroupsTimeout(t *testing.T) {
+	cloud, _, arn := setupDrainTest(t, 1000000, "0")
+
+	gracePeriod := targetDrainGracePeriod
+	targetDrainGracePeriod = 0
+	defer func() { targetDrainGracePeriod = gracePeriod }()
+
+	err := DeregisterInstanceFromTargetGroups(cloud, "i-1", []string{arn})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout, got %v", err)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"k8s.io/kops/pkg/model/components/addonmanifests/awsloadbalancercontroller"
 	"k8s.io/kops/pkg/model/components/addonmanifests/clusterautoscaler"
 	"k8s.io/kops/pkg/model/components/addonmanifests/dnscontroller"
+	"k8s.io/kops/pkg/model/components/addonmanifests/karpenter"
 	"k8s.io/kops/pkg/model/components/addonmanifests/nodeterminationhandler"
 	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/pkg/templates"
@@ -507,6 +508,28 @@ func (b *BootstrapChannelBuilder) buildAddons(c *fi.ModelBuilderContext) (*chann
 
 	}
 
+	if b.Cluster.Spec.Karpenter != nil && fi.BoolValue(b.Cluster.Spec.Karpenter.Enabled) {
+		{
+			key := "karpenter.sh"
+
+			{
+				location := key + "/k8s-1.19.yaml"
+				id := "k8s-1.19"
+
+				addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+					Name:     fi.String(key),
+					Selector: map[string]string{"k8s-addon": key},
+					Manifest: fi.String(location),
+					Id:       id,
+				})
+			}
+		}
+
+		if b.UseServiceAccountIAM() {
+			serviceAccountRoles = append(serviceAccountRoles, &karpenter.ServiceAccount{})
+		}
+	}
+
 	if b.Cluster.Spec.MetricsServer != nil && fi.BoolValue(b.Cluster.Spec.MetricsServer.Enabled) {
 		{
 			key := "metrics-server.addons.k8s.io"
@@ -943,6 +966,25 @@ func (b *BootstrapChannelBuilder) buildAddons(c *fi.ModelBuilderContext) (*chann
 		}
 	}
 
+	if len(b.ContainerdRuntimeHandlers()) > 0 {
+		key := "runtimeclasses.addons.k8s.io"
+
+		{
+			// RuntimeClass graduated to node.k8s.io/v1 in kubernetes 1.20
+			id := "k8s-1.14"
+			if b.IsKubernetesGTE("1.20") {
+				id = "k8s-1.20"
+			}
+			location := key + "/" + id + ".yaml"
+			addons.Spec.Addons = append(addons.Spec.Addons, &channelsapi.AddonSpec{
+				Name:     fi.String(key),
+				Manifest: fi.String(location),
+				Selector: map[string]string{"k8s-addon": key},
+				Id:       id,
+			})
+		}
+	}
+
 	if b.Cluster.Spec.KubeScheduler.UsePolicyConfigMap != nil {
 		key := "scheduler.addons.k8s.io"
 		version := "1.7.0"
@@ -21,6 +21,8 @@ import (
 	"path"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	kopsapi "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/client/simple/vfsclientset"
@@ -51,6 +53,7 @@ func TestBootstrapChannelBuilder_BuildTasks(t *testing.T) {
 	runChannelBuilderTest(t, "amazonvpc", []string{"networking.amazon-vpc-routed-eni-k8s-1.16"})
 	runChannelBuilderTest(t, "amazonvpc-containerd", []string{"networking.amazon-vpc-routed-eni-k8s-1.16"})
 	runChannelBuilderTest(t, "awsiamauthenticator", []string{"authentication.aws-k8s-1.12"})
+	runChannelBuilderTest(t, "containerd-runtimes", []string{"runtimeclasses.addons.k8s.io-k8s-1.20"})
 }
 
 func TestBootstrapChannelBuilder_ServiceAccountIAM(t *testing.T) {
@@ -76,6 +79,15 @@ func TestBootstrapChannelBuilder_AWSCloudController(t *testing.T) {
 	runChannelBuilderTest(t, "awscloudcontroller", []string{"aws-cloud-controller.addons.k8s.io-k8s-1.18"})
 }
 
+func TestBootstrapChannelBuilder_Karpenter(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+
+	h.SetupMockAWS()
+
+	runChannelBuilderTest(t, "karpenter", []string{"karpenter.sh-k8s-1.19"})
+}
+
 func runChannelBuilderTest(t *testing.T, key string, addonManifests []string) {
 	basedir := path.Join("tests/bootstrapchannelbuilder/", key)
 
@@ -144,6 +156,23 @@ func runChannelBuilderTest(t *testing.T, key string, addonManifests []string) {
 					Role: kopsapi.InstanceGroupRoleNode,
 				},
 			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "spot",
+				},
+				Spec: kopsapi.InstanceGroupSpec{
+					Role:        kopsapi.InstanceGroupRoleNode,
+					Manager:     kopsapi.InstanceManagerProvisioner,
+					MachineType: "m5.large",
+					MixedInstancesPolicy: &kopsapi.MixedInstancesPolicySpec{
+						Instances:         []string{"m5.large", "m5a.large"},
+						OnDemandAboveBase: fi.Int64(0),
+					},
+					NodeLabels: map[string]string{"workload": "batch"},
+					Taints:     []string{"dedicated=batch:NoSchedule"},
+					Subnets:    []string{"us-test-1a"},
+				},
+			},
 		},
 	}
 
@@ -18,6 +18,7 @@ package cloudformation
 
 import (
 	"encoding/json"
+	"strconv"
 )
 
 type Literal struct {
@@ -66,6 +67,20 @@ func GetAtt(resourceType, resourceName string, attribute string) *Literal {
 	return &Literal{json: j}
 }
 
+// Select returns the element at index of a list, using "Fn::Select"
+func Select(index int, list interface{}) *Literal {
+	j := make(map[string]interface{})
+	j["Fn::Select"] = []interface{}{strconv.Itoa(index), list}
+	return &Literal{json: j}
+}
+
+// Cidr splits an address block into count blocks with cidrBits host bits each, using "Fn::Cidr"
+func Cidr(ipBlock interface{}, count int, cidrBits int) *Literal {
+	j := make(map[string]interface{})
+	j["Fn::Cidr"] = []interface{}{ipBlock, strconv.Itoa(count), strconv.Itoa(cidrBits)}
+	return &Literal{json: j}
+}
+
 func LiteralString(v string) *Literal {
 	j := &v
 	return &Literal{json: j}
@@ -54,6 +54,7 @@ var _ fi.Target = &CloudformationTarget{}
 type cloudformationResource struct {
 	Type       string
 	Properties interface{}
+	DependsOn  []string `json:",omitempty"`
 }
 
 // A cloudformation resource name must be alphanumeric
@@ -71,10 +72,23 @@ func (t *CloudformationTarget) ProcessDeletions() bool {
 }
 
 func (t *CloudformationTarget) RenderResource(resourceType string, resourceName string, e interface{}) error {
+	return t.RenderResourceWithDependencies(resourceType, resourceName, e, nil)
+}
+
+// RenderResourceWithDependencies renders a resource that is only created after the referenced resources,
+// for dependencies that CloudFormation can't infer from the properties of the resource
+func (t *CloudformationTarget) RenderResourceWithDependencies(resourceType string, resourceName string, e interface{}, dependsOn []*Literal) error {
 	res := &cloudformationResource{
 		Type:       resourceType,
 		Properties: e,
 	}
+	for _, ref := range dependsOn {
+		key := ref.extractRef()
+		if key == "" {
+			return fmt.Errorf("unable to extract ref from %v", ref)
+		}
+		res.DependsOn = append(res.DependsOn, key)
+	}
 
 	name := resourceType + "::" + resourceName
 	name = sanitizeCloudformationResourceName(name)
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"fmt"
+	"net/url"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/mirrors"
+)
+
+const (
+	// gVisor release binaries URL, the hashes are published next to the binaries as .sha512 files
+	gvisorVersionUrl = "https://storage.googleapis.com/gvisor/releases/release/%s/%s/%s"
+)
+
+// gvisorBinaries are the binaries needed by containerd to run gVisor sandboxes
+var gvisorBinaries = []string{"runsc", "containerd-shim-runsc-v1"}
+
+// buildContainerdRuntimes resolves the packages of the containerd runtime handlers.
+// It returns the runtimes with their package URLs remapped to the assets location,
+// together with the assets that must be downloaded by nodeup for each architecture.
+func buildContainerdRuntimes(assetBuilder *assets.AssetBuilder, runtimes []kops.ContainerdRuntimeSpec) ([]kops.ContainerdRuntimeSpec, map[architectures.Architecture][]*mirrors.MirroredAsset, error) {
+	var resolved []kops.ContainerdRuntimeSpec
+	runtimeAssets := make(map[architectures.Architecture][]*mirrors.MirroredAsset)
+
+	for _, runtime := range runtimes {
+		switch runtime.Type {
+		case kops.ContainerdRuntimeTypeGVisor:
+			for _, arch := range architectures.GetSupported() {
+				a, err := findGVisorAssets(runtime, assetBuilder, arch)
+				if err != nil {
+					return nil, nil, fmt.Errorf("error finding assets for runtime %q: %v", runtime.Name, err)
+				}
+				runtimeAssets[arch] = append(runtimeAssets[arch], a...)
+			}
+			// The packages are archives of the binaries, which nodeup unpacks like the Kata packages
+			packages, err := remapContainerdRuntimePackages(runtime.Packages, assetBuilder)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error remapping packages for runtime %q: %v", runtime.Name, err)
+			}
+			runtime.Packages = packages
+
+		case kops.ContainerdRuntimeTypeKata:
+			packages, err := remapContainerdRuntimePackages(runtime.Packages, assetBuilder)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error remapping packages for runtime %q: %v", runtime.Name, err)
+			}
+			runtime.Packages = packages
+
+		default:
+			return nil, nil, fmt.Errorf("unknown type %q for runtime %q", runtime.Type, runtime.Name)
+		}
+
+		resolved = append(resolved, runtime)
+	}
+
+	return resolved, runtimeAssets, nil
+}
+
+// findGVisorAssets returns the gVisor binaries for the given architecture from the official gVisor releases.
+// No assets are returned for the architectures with a package, which nodeup installs instead.
+func findGVisorAssets(runtime kops.ContainerdRuntimeSpec, assetBuilder *assets.AssetBuilder, arch architectures.Architecture) ([]*mirrors.MirroredAsset, error) {
+	if runtime.Packages != nil {
+		var packageUrl string
+		switch arch {
+		case architectures.ArchitectureAmd64:
+			packageUrl = fi.StringValue(runtime.Packages.UrlAmd64)
+		case architectures.ArchitectureArm64:
+			packageUrl = fi.StringValue(runtime.Packages.UrlArm64)
+		}
+		if packageUrl != "" {
+			return nil, nil
+		}
+	}
+
+	version := fi.StringValue(runtime.Version)
+	if version == "" {
+		// Only the packages for some of the architectures were provided
+		return nil, nil
+	}
+
+	var platform string
+	switch arch {
+	case architectures.ArchitectureAmd64:
+		platform = "x86_64"
+	case architectures.ArchitectureArm64:
+		platform = "aarch64"
+	default:
+		return nil, fmt.Errorf("unknown arch: %q", arch)
+	}
+
+	var result []*mirrors.MirroredAsset
+	for _, binary := range gvisorBinaries {
+		u, err := url.Parse(fmt.Sprintf(gvisorVersionUrl, version, platform, binary))
+		if err != nil {
+			return nil, err
+		}
+		u, h, err := assetBuilder.RemapFileAndSHA(u)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, mirrors.BuildMirroredAsset(u, h))
+	}
+
+	return result, nil
+}
+
+// remapContainerdRuntimePackages returns a copy of the packages with the URLs remapped to the assets location.
+func remapContainerdRuntimePackages(packages *kops.PackagesConfig, assetBuilder *assets.AssetBuilder) (*kops.PackagesConfig, error) {
+	if packages == nil {
+		return nil, nil
+	}
+
+	remapped := *packages
+	if packages.UrlAmd64 != nil && packages.HashAmd64 != nil {
+		u, _, err := findAssetsUrlHash(assetBuilder, *packages.UrlAmd64, *packages.HashAmd64)
+		if err != nil {
+			return nil, err
+		}
+		remapped.UrlAmd64 = fi.String(u.String())
+	}
+	if packages.UrlArm64 != nil && packages.HashArm64 != nil {
+		u, _, err := findAssetsUrlHash(assetBuilder, *packages.UrlArm64, *packages.HashArm64)
+		if err != nil {
+			return nil, err
+		}
+		remapped.UrlArm64 = fi.String(u.String())
+	}
+
+	return &remapped, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
+)
+
+func TestBuildContainerdRuntimes(t *testing.T) {
+	const hash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	cluster := &kops.Cluster{}
+	cluster.Spec.KubernetesVersion = "v1.21.0"
+	cluster.Spec.Assets = &kops.Assets{
+		FileRepository: fi.String("https://mirror.example.com/files"),
+	}
+	assetBuilder := assets.NewAssetBuilder(cluster, false)
+
+	runtimes := []kops.ContainerdRuntimeSpec{
+		{
+			Name: "gvisor",
+			Type: kops.ContainerdRuntimeTypeGVisor,
+			Packages: &kops.PackagesConfig{
+				UrlAmd64:  fi.String("https://example.com/gvisor/runsc-amd64.tar.gz"),
+				HashAmd64: fi.String(hash),
+			},
+		},
+		{
+			Name: "kata",
+			Type: kops.ContainerdRuntimeTypeKata,
+			Packages: &kops.PackagesConfig{
+				UrlAmd64:  fi.String("https://example.com/kata/kata-static-2.1.1-x86_64.tar.xz"),
+				HashAmd64: fi.String(hash),
+				UrlArm64:  fi.String("https://example.com/kata/kata-static-2.1.1-aarch64.tar.xz"),
+				HashArm64: fi.String(hash),
+			},
+		},
+	}
+
+	resolved, runtimeAssets, err := buildContainerdRuntimes(assetBuilder, runtimes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resolved) != 2 {
+		t.Fatalf("expected 2 runtimes, got %d", len(resolved))
+	}
+	expectedKata := &kops.PackagesConfig{
+		UrlAmd64:  fi.String("https://mirror.example.com/files/kata/kata-static-2.1.1-x86_64.tar.xz"),
+		HashAmd64: fi.String(hash),
+		UrlArm64:  fi.String("https://mirror.example.com/files/kata/kata-static-2.1.1-aarch64.tar.xz"),
+		HashArm64: fi.String(hash),
+	}
+	expectedGVisor := &kops.PackagesConfig{
+		UrlAmd64:  fi.String("https://mirror.example.com/files/gvisor/runsc-amd64.tar.gz"),
+		HashAmd64: fi.String(hash),
+	}
+	if !reflect.DeepEqual(resolved[0].Packages, expectedGVisor) {
+		t.Errorf("unexpected gvisor packages: %v", resolved[0].Packages)
+	}
+	if !reflect.DeepEqual(resolved[1].Packages, expectedKata) {
+		t.Errorf("unexpected kata packages: %v", resolved[1].Packages)
+	}
+	if fi.StringValue(runtimes[1].Packages.UrlAmd64) != "https://example.com/kata/kata-static-2.1.1-x86_64.tar.xz" {
+		t.Errorf("the runtimes spec should not be modified")
+	}
+
+	// The gVisor package is installed by nodeup rather than as assets
+	if len(runtimeAssets[architectures.ArchitectureAmd64]) != 0 {
+		t.Errorf("unexpected amd64 assets: %v", runtimeAssets[architectures.ArchitectureAmd64])
+	}
+	if len(runtimeAssets[architectures.ArchitectureArm64]) != 0 {
+		t.Errorf("unexpected arm64 assets: %v", runtimeAssets[architectures.ArchitectureArm64])
+	}
+}
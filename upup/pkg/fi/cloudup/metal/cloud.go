@@ -0,0 +1,230 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/dnsprovider/pkg/dnsprovider"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// MetalCloud is the cloud of pre-provisioned hosts, which are configured over SSH
+type MetalCloud interface {
+	fi.Cloud
+
+	// Connect opens an SSH connection to the host
+	Connect(host *kops.StaticHostSpec) (*SSHHost, error)
+}
+
+// static compile time check to validate MetalCloud's fi.Cloud Interface.
+var _ fi.Cloud = &metalCloudImplementation{}
+
+type metalCloudImplementation struct {
+	// mutex protects credentials, which are loaded on the first connection
+	mutex       sync.Mutex
+	credentials *SSHCredentials
+}
+
+// NewMetalCloud returns the cloud of the hosts of a cluster.
+// If credentials is nil, they are loaded with LoadSSHCredentials on the first connection to a host.
+func NewMetalCloud(credentials *SSHCredentials) MetalCloud {
+	return &metalCloudImplementation{
+		credentials: credentials,
+	}
+}
+
+func (c *metalCloudImplementation) ProviderID() kops.CloudProviderID {
+	return kops.CloudProviderMetal
+}
+
+func (c *metalCloudImplementation) Region() string {
+	return ""
+}
+
+func (c *metalCloudImplementation) DNS() (dnsprovider.Interface, error) {
+	return nil, fmt.Errorf("DNS is not supported on metal, use gossip DNS (a cluster name ending in .k8s.local)")
+}
+
+func (c *metalCloudImplementation) FindVPCInfo(id string) (*fi.VPCInfo, error) {
+	return nil, fmt.Errorf("VPCs are not supported on metal")
+}
+
+func (c *metalCloudImplementation) Connect(host *kops.StaticHostSpec) (*SSHHost, error) {
+	c.mutex.Lock()
+	if c.credentials == nil {
+		credentials, err := LoadSSHCredentials()
+		if err != nil {
+			c.mutex.Unlock()
+			return nil, err
+		}
+		c.credentials = credentials
+	}
+	credentials := c.credentials
+	c.mutex.Unlock()
+
+	return credentials.Connect(host)
+}
+
+// DeleteInstance reprovisions the host in place, as hosts cannot be replaced.
+// The last bootstrap script written to the host is run again, and nodeup reconfigures the host.
+func (c *metalCloudImplementation) DeleteInstance(i *cloudinstances.CloudInstance) error {
+	host, err := findHost(i)
+	if err != nil {
+		return err
+	}
+
+	h, err := c.Connect(host)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	klog.Infof("Reprovisioning host %q", host.Name)
+	return h.Provision()
+}
+
+// DeleteGroup leaves the hosts as they are: they are not owned by kOps
+func (c *metalCloudImplementation) DeleteGroup(g *cloudinstances.CloudInstanceGroup) error {
+	klog.Warningf("The hosts of instance group %q are not deprovisioned, they must be reset manually", g.HumanName)
+	return nil
+}
+
+func (c *metalCloudImplementation) DetachInstance(i *cloudinstances.CloudInstance) error {
+	return fmt.Errorf("hosts cannot be detached on metal, set maxSurge to 0")
+}
+
+// GetCloudGroups returns a group for each instance group, with an instance for each of its hosts.
+// A host needs an update when the last bootstrap script written to it has not been run yet, or when it can't be reached.
+func (c *metalCloudImplementation) GetCloudGroups(cluster *kops.Cluster, instancegroups []*kops.InstanceGroup, warnUnmatched bool, nodes []v1.Node) (map[string]*cloudinstances.CloudInstanceGroup, error) {
+	nodeMap := cloudinstances.GetNodeMap(nodes, cluster)
+
+	groups := make(map[string]*cloudinstances.CloudInstanceGroup)
+	for _, ig := range instancegroups {
+		size := len(ig.Spec.Hosts)
+		group := &cloudinstances.CloudInstanceGroup{
+			HumanName:     ig.ObjectMeta.Name,
+			InstanceGroup: ig,
+			MinSize:       size,
+			TargetSize:    size,
+			MaxSize:       size,
+			Raw:           ig,
+		}
+
+		for i := range ig.Spec.Hosts {
+			host := &ig.Spec.Hosts[i]
+
+			status, err := c.hostStatus(host)
+			if err != nil {
+				klog.Warningf("unable to determine the status of host %q: %v", host.Name, err)
+				status = cloudinstances.CloudInstanceStatusNeedsUpdate
+			}
+
+			node := nodeMap[host.Name]
+			if node == nil {
+				node = nodeMap[host.Address]
+			}
+
+			cm, err := group.NewCloudInstance(host.Name, status, node)
+			if err != nil {
+				return nil, fmt.Errorf("error creating cloud instance for host %q: %v", host.Name, err)
+			}
+			cm.PrivateIP = host.Address
+			cm.Roles = []string{string(ig.Spec.Role)}
+		}
+
+		groups[ig.ObjectMeta.Name] = group
+	}
+
+	return groups, nil
+}
+
+// hostStatus compares the bootstrap script last written to the host with the one last run
+func (c *metalCloudImplementation) hostStatus(host *kops.StaticHostSpec) (string, error) {
+	h, err := c.Connect(host)
+	if err != nil {
+		return "", err
+	}
+	defer h.Close()
+
+	written, err := h.ReadFile(PathBootstrapScript)
+	if err != nil {
+		return "", err
+	}
+	applied, err := h.ReadFile(PathAppliedBootstrapScript)
+	if err != nil {
+		return "", err
+	}
+
+	if written != nil && !bytes.Equal(written, applied) {
+		return cloudinstances.CloudInstanceStatusNeedsUpdate, nil
+	}
+	return cloudinstances.CloudInstanceStatusUpToDate, nil
+}
+
+// FindClusterStatus returns an empty status: etcd is stored on the disks of the hosts, not on cloud volumes
+func (c *metalCloudImplementation) FindClusterStatus(cluster *kops.Cluster) (*kops.ClusterStatus, error) {
+	return &kops.ClusterStatus{}, nil
+}
+
+// GetApiIngressStatus returns the addresses of the control plane hosts, read from the inventory in the config base
+func (c *metalCloudImplementation) GetApiIngressStatus(cluster *kops.Cluster) ([]fi.ApiIngressStatus, error) {
+	configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
+	}
+
+	inventory, err := ReadInventory(configBase)
+	if err != nil {
+		return nil, err
+	}
+	if inventory == nil {
+		klog.Warningf("No inventory of the hosts found; run kops update cluster")
+		return nil, nil
+	}
+
+	var ingresses []fi.ApiIngressStatus
+	for _, host := range inventory.Hosts {
+		if host.Role == kops.InstanceGroupRoleMaster || host.Role == kops.InstanceGroupRoleAPIServer {
+			if net.ParseIP(host.Address) != nil {
+				ingresses = append(ingresses, fi.ApiIngressStatus{IP: host.Address})
+			} else {
+				ingresses = append(ingresses, fi.ApiIngressStatus{Hostname: host.Address})
+			}
+		}
+	}
+	return ingresses, nil
+}
+
+// findHost returns the host of a cloud instance
+func findHost(i *cloudinstances.CloudInstance) (*kops.StaticHostSpec, error) {
+	ig := i.CloudInstanceGroup.InstanceGroup
+	for j := range ig.Spec.Hosts {
+		if ig.Spec.Hosts[j].Name == i.ID {
+			return &ig.Spec.Hosts[j], nil
+		}
+	}
+	return nil, fmt.Errorf("host %q not found in instance group %q", i.ID, ig.ObjectMeta.Name)
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestSSHHostFiles(t *testing.T) {
+	grid := []struct {
+		user string
+		sudo bool
+	}{
+		{user: "", sudo: false},
+		{user: "root", sudo: false},
+		{user: "ubuntu", sudo: true},
+	}
+	for _, g := range grid {
+		t.Run("user="+g.user, func(t *testing.T) {
+			servers, credentials := newTestSSHServers(t, 1)
+			server := servers[0]
+			spec := server.host("host1", g.user)
+
+			h, err := credentials.Connect(&spec)
+			if err != nil {
+				t.Fatalf("error connecting: %v", err)
+			}
+			defer h.Close()
+
+			data, err := h.ReadFile(PathBootstrapScript)
+			if err != nil {
+				t.Fatalf("error reading missing file: %v", err)
+			}
+			if data != nil {
+				t.Errorf("expected nil for a missing file, got %q", data)
+			}
+
+			if err := h.WriteFile(PathBootstrapScript, []byte("#!/bin/bash\necho hello\n"), 0700); err != nil {
+				t.Fatalf("error writing file: %v", err)
+			}
+			data, err = h.ReadFile(PathBootstrapScript)
+			if err != nil {
+				t.Fatalf("error reading file: %v", err)
+			}
+			if string(data) != "#!/bin/bash\necho hello\n" {
+				t.Errorf("unexpected contents %q", data)
+			}
+
+			arch, err := h.Architecture()
+			if err != nil {
+				t.Fatalf("error getting architecture: %v", err)
+			}
+			if arch != architectures.ArchitectureAmd64 {
+				t.Errorf("expected architecture %s, got %s", architectures.ArchitectureAmd64, arch)
+			}
+
+			for _, cmd := range server.getCommands() {
+				if strings.HasPrefix(cmd, "sudo ") != g.sudo {
+					t.Errorf("command %q: expected sudo=%v", cmd, g.sudo)
+				}
+			}
+		})
+	}
+}
+
+func TestSSHHostProvision(t *testing.T) {
+	servers, credentials := newTestSSHServers(t, 1)
+	server := servers[0]
+	spec := server.host("host1", "")
+	server.writeFile(PathBootstrapScript, "script-v1")
+
+	h, err := credentials.Connect(&spec)
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	defer h.Close()
+
+	if err := h.Provision(); err != nil {
+		t.Fatalf("error provisioning: %v", err)
+	}
+
+	if scripts := server.getScripts(); !reflect.DeepEqual(scripts, []string{"script-v1"}) {
+		t.Errorf("unexpected scripts run: %v", scripts)
+	}
+	if applied := server.readFile(PathAppliedBootstrapScript); applied != "script-v1" {
+		t.Errorf("unexpected applied script %q", applied)
+	}
+}
+
+func TestConnectFailsWithUnknownHostKey(t *testing.T) {
+	servers, _ := newTestSSHServers(t, 1)
+	_, otherCredentials := newTestSSHServers(t, 1)
+
+	spec := servers[0].host("host1", "")
+	if _, err := otherCredentials.Connect(&spec); err == nil {
+		t.Errorf("expected an error connecting with unknown keys")
+	}
+}
+
+func TestLoadSSHCredentialsKnownHosts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metal")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	privateKeyPath := filepath.Join(dir, "id_rsa")
+	if err := ioutil.WriteFile(privateKeyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600); err != nil {
+		t.Fatalf("error writing private key: %v", err)
+	}
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+
+	for _, name := range []string{"SSH_AUTH_SOCK", EnvPrivateKey, EnvKnownHosts, EnvInsecureIgnoreHostKey} {
+		defer os.Setenv(name, os.Getenv(name))
+	}
+	os.Unsetenv("SSH_AUTH_SOCK")
+	os.Setenv(EnvPrivateKey, privateKeyPath)
+	os.Setenv(EnvKnownHosts, knownHostsPath)
+	os.Unsetenv(EnvInsecureIgnoreHostKey)
+
+	if _, err := LoadSSHCredentials(); err == nil {
+		t.Errorf("expected an error loading credentials without known hosts")
+	}
+
+	os.Setenv(EnvInsecureIgnoreHostKey, "true")
+	if _, err := LoadSSHCredentials(); err != nil {
+		t.Errorf("error loading credentials ignoring the host keys: %v", err)
+	}
+
+	os.Unsetenv(EnvInsecureIgnoreHostKey)
+	if err := ioutil.WriteFile(knownHostsPath, nil, 0600); err != nil {
+		t.Fatalf("error writing known hosts: %v", err)
+	}
+	if _, err := LoadSSHCredentials(); err != nil {
+		t.Errorf("error loading credentials with known hosts: %v", err)
+	}
+}
+
+func TestGetCloudGroups(t *testing.T) {
+	servers, credentials := newTestSSHServers(t, 3)
+
+	// host1 runs its last bootstrap script, host2 has a newer one, host3 was never provisioned
+	servers[0].writeFile(PathBootstrapScript, "script-v1")
+	servers[0].writeFile(PathAppliedBootstrapScript, "script-v1")
+	servers[1].writeFile(PathBootstrapScript, "script-v2")
+	servers[1].writeFile(PathAppliedBootstrapScript, "script-v1")
+
+	ig := &kops.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: kops.InstanceGroupSpec{
+			Role: kops.InstanceGroupRoleNode,
+			Hosts: []kops.StaticHostSpec{
+				servers[0].host("host1", ""),
+				servers[1].host("host2", ""),
+				servers[2].host("host3", ""),
+			},
+		},
+	}
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test.k8s.local"},
+		Spec:       kops.ClusterSpec{CloudProvider: string(kops.CloudProviderMetal)},
+	}
+	nodes := []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "host1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "host2"}},
+	}
+
+	cloud := NewMetalCloud(credentials)
+	groups, err := cloud.GetCloudGroups(cluster, []*kops.InstanceGroup{ig}, false, nodes)
+	if err != nil {
+		t.Fatalf("error getting cloud groups: %v", err)
+	}
+
+	group := groups["nodes"]
+	if group == nil {
+		t.Fatalf("group nodes not found in %v", groups)
+	}
+	if group.MinSize != 3 || group.TargetSize != 3 || group.MaxSize != 3 {
+		t.Errorf("unexpected sizes min=%d target=%d max=%d", group.MinSize, group.TargetSize, group.MaxSize)
+	}
+
+	statuses := make(map[string]string)
+	for _, i := range append(group.Ready, group.NeedUpdate...) {
+		statuses[i.ID] = i.Status
+		if i.PrivateIP != "127.0.0.1" {
+			t.Errorf("instance %s: unexpected private IP %q", i.ID, i.PrivateIP)
+		}
+		if (i.Node != nil) != (i.ID != "host3") {
+			t.Errorf("instance %s: unexpected node %v", i.ID, i.Node)
+		}
+	}
+	expected := map[string]string{
+		"host1": cloudinstances.CloudInstanceStatusUpToDate,
+		"host2": cloudinstances.CloudInstanceStatusNeedsUpdate,
+		"host3": cloudinstances.CloudInstanceStatusUpToDate,
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("unexpected statuses %v, expected %v", statuses, expected)
+	}
+
+	// Deleting the instance reprovisions the host in place
+	for _, i := range group.NeedUpdate {
+		if err := cloud.DeleteInstance(i); err != nil {
+			t.Fatalf("error deleting instance %s: %v", i.ID, err)
+		}
+	}
+	if scripts := servers[1].getScripts(); !reflect.DeepEqual(scripts, []string{"script-v2"}) {
+		t.Errorf("unexpected scripts run on host2: %v", scripts)
+	}
+	if scripts := servers[0].getScripts(); len(scripts) != 0 {
+		t.Errorf("unexpected scripts run on host1: %v", scripts)
+	}
+
+	groups, err = cloud.GetCloudGroups(cluster, []*kops.InstanceGroup{ig}, false, nodes)
+	if err != nil {
+		t.Fatalf("error getting cloud groups: %v", err)
+	}
+	if needUpdate := groups["nodes"].NeedUpdate; len(needUpdate) != 0 {
+		t.Errorf("expected all hosts to be up to date after the rolling update, got %v", needUpdate)
+	}
+}
+
+func TestGetCloudGroupsUnreachableHost(t *testing.T) {
+	_, credentials := newTestSSHServers(t, 0)
+
+	// Find a port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	port := int32(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	ig := &kops.InstanceGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: kops.InstanceGroupSpec{
+			Role: kops.InstanceGroupRoleNode,
+			Hosts: []kops.StaticHostSpec{
+				{Name: "host1", Address: "127.0.0.1", SSHPort: &port},
+			},
+		},
+	}
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test.k8s.local"},
+		Spec:       kops.ClusterSpec{CloudProvider: string(kops.CloudProviderMetal)},
+	}
+
+	groups, err := NewMetalCloud(credentials).GetCloudGroups(cluster, []*kops.InstanceGroup{ig}, false, nil)
+	if err != nil {
+		t.Fatalf("error getting cloud groups: %v", err)
+	}
+	needUpdate := groups["nodes"].NeedUpdate
+	if len(needUpdate) != 1 || needUpdate[0].ID != "host1" || needUpdate[0].Status != cloudinstances.CloudInstanceStatusNeedsUpdate {
+		t.Errorf("expected the unreachable host to need an update, got %v", needUpdate)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	igs := []*kops.InstanceGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+			Spec: kops.InstanceGroupSpec{
+				Role: kops.InstanceGroupRoleNode,
+				Hosts: []kops.StaticHostSpec{
+					{Name: "node-b", Address: "10.0.0.12"},
+					{Name: "node-a", Address: "10.0.0.11"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "master"},
+			Spec: kops.InstanceGroupSpec{
+				Role: kops.InstanceGroupRoleMaster,
+				Hosts: []kops.StaticHostSpec{
+					{Name: "master-a", Address: "10.0.0.1"},
+				},
+			},
+		},
+	}
+
+	inventory := BuildInventory(igs)
+	expected := &Inventory{
+		Hosts: []InventoryHost{
+			{Name: "master-a", Address: "10.0.0.1", InstanceGroup: "master", Role: kops.InstanceGroupRoleMaster},
+			{Name: "node-a", Address: "10.0.0.11", InstanceGroup: "nodes", Role: kops.InstanceGroupRoleNode},
+			{Name: "node-b", Address: "10.0.0.12", InstanceGroup: "nodes", Role: kops.InstanceGroupRoleNode},
+		},
+	}
+	if !reflect.DeepEqual(inventory, expected) {
+		t.Errorf("unexpected inventory %v, expected %v", inventory, expected)
+	}
+
+	if addresses := ControlPlaneAddresses(igs); !reflect.DeepEqual(addresses, []string{"10.0.0.1"}) {
+		t.Errorf("unexpected control plane addresses %v", addresses)
+	}
+
+	vfs.Context.ResetMemfsContext(true)
+	configBase, err := vfs.Context.BuildVfsPath("memfs://tests/test.k8s.local")
+	if err != nil {
+		t.Fatalf("error building config base: %v", err)
+	}
+	cluster := &kops.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test.k8s.local"},
+		Spec: kops.ClusterSpec{
+			CloudProvider: string(kops.CloudProviderMetal),
+			ConfigBase:    configBase.Path(),
+		},
+	}
+	cloud := NewMetalCloud(&SSHCredentials{})
+
+	missing, err := ReadInventory(configBase)
+	if err != nil {
+		t.Fatalf("error reading missing inventory: %v", err)
+	}
+	if missing != nil {
+		t.Errorf("expected no inventory, got %v", missing)
+	}
+
+	data := "hosts:\n" +
+		"- name: master-a\n  address: 10.0.0.1\n  instanceGroup: master\n  role: Master\n" +
+		"- name: node-a\n  address: 10.0.0.11\n  instanceGroup: nodes\n  role: Node\n" +
+		"- name: node-b\n  address: 10.0.0.12\n  instanceGroup: nodes\n  role: Node\n"
+	if err := configBase.Join(PathInventory).WriteFile(strings.NewReader(data), nil); err != nil {
+		t.Fatalf("error writing inventory: %v", err)
+	}
+
+	actual, err := ReadInventory(configBase)
+	if err != nil {
+		t.Fatalf("error reading inventory: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected inventory %v, expected %v", actual, expected)
+	}
+
+	ingresses, err := cloud.GetApiIngressStatus(cluster)
+	if err != nil {
+		t.Fatalf("error getting API ingress status: %v", err)
+	}
+	if !reflect.DeepEqual(ingresses, []fi.ApiIngressStatus{{IP: "10.0.0.1"}}) {
+		t.Errorf("unexpected API ingress status %v", ingresses)
+	}
+}
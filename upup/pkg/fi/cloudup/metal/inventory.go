@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/util/pkg/vfs"
+	"sigs.k8s.io/yaml"
+)
+
+// PathInventory is the path, relative to the config base, of the inventory of the hosts
+const PathInventory = "metal/inventory.yaml"
+
+// Inventory lists the hosts of a cluster, so that they can be found from the cluster alone,
+// as the instances of other clouds are found through the cloud APIs
+type Inventory struct {
+	Hosts []InventoryHost `json:"hosts,omitempty"`
+}
+
+// InventoryHost is a host of the inventory
+type InventoryHost struct {
+	Name          string                 `json:"name"`
+	Address       string                 `json:"address"`
+	InstanceGroup string                 `json:"instanceGroup"`
+	Role          kops.InstanceGroupRole `json:"role"`
+}
+
+// BuildInventory builds the inventory of the hosts of the instance groups
+func BuildInventory(instanceGroups []*kops.InstanceGroup) *Inventory {
+	inventory := &Inventory{}
+	for _, ig := range instanceGroups {
+		for _, host := range ig.Spec.Hosts {
+			inventory.Hosts = append(inventory.Hosts, InventoryHost{
+				Name:          host.Name,
+				Address:       host.Address,
+				InstanceGroup: ig.ObjectMeta.Name,
+				Role:          ig.Spec.Role,
+			})
+		}
+	}
+	sort.Slice(inventory.Hosts, func(i, j int) bool {
+		return inventory.Hosts[i].Name < inventory.Hosts[j].Name
+	})
+	return inventory
+}
+
+// ReadInventory reads the inventory from the config base, returning nil if it does not exist
+func ReadInventory(configBase vfs.Path) (*Inventory, error) {
+	p := configBase.Join(PathInventory)
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading inventory %s: %v", p, err)
+	}
+
+	inventory := &Inventory{}
+	if err := yaml.Unmarshal(data, inventory); err != nil {
+		return nil, fmt.Errorf("error parsing inventory %s: %v", p, err)
+	}
+	return inventory, nil
+}
+
+// ControlPlaneAddresses returns the sorted addresses of the hosts running the API server
+func ControlPlaneAddresses(instanceGroups []*kops.InstanceGroup) []string {
+	var addresses []string
+	for _, ig := range instanceGroups {
+		if ig.Spec.Role != kops.InstanceGroupRoleMaster && ig.Spec.Role != kops.InstanceGroupRoleAPIServer {
+			continue
+		}
+		for _, host := range ig.Spec.Hosts {
+			addresses = append(addresses, host.Address)
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/sshcredentials"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// PathBootstrapScript is the path on the hosts of the bootstrap script of their current configuration
+	PathBootstrapScript = "/var/lib/kops/bootstrap.sh"
+	// PathAppliedBootstrapScript is the path on the hosts of the bootstrap script last run
+	PathAppliedBootstrapScript = "/var/lib/kops/bootstrap-applied.sh"
+	// PathNodeUp is the path on the hosts of the nodeup binary; the bootstrap script does not download it again if its hash matches
+	PathNodeUp = "/opt/kops/bin/nodeup"
+
+	// EnvPrivateKey is the environment variable naming the file of the private key used to log in to the hosts
+	EnvPrivateKey = "KOPS_METAL_SSH_PRIVATE_KEY"
+	// EnvKnownHosts is the environment variable naming the known_hosts file used to verify the keys of the hosts
+	EnvKnownHosts = "KOPS_METAL_SSH_KNOWN_HOSTS"
+	// EnvInsecureIgnoreHostKey is the environment variable that disables the verification of the keys of the hosts when set to true
+	EnvInsecureIgnoreHostKey = "KOPS_METAL_SSH_INSECURE_IGNORE_HOST_KEY"
+
+	defaultPrivateKey = "~/.ssh/id_rsa"
+	defaultKnownHosts = "~/.ssh/known_hosts"
+)
+
+// SSHCredentials are the credentials used to log in to the hosts
+type SSHCredentials struct {
+	Auth            []ssh.AuthMethod
+	HostKeyCallback ssh.HostKeyCallback
+	// Timeout is the timeout for establishing the SSH connections
+	Timeout time.Duration
+}
+
+// LoadSSHCredentials loads the keys of the SSH agent and the private key in KOPS_METAL_SSH_PRIVATE_KEY (~/.ssh/id_rsa by default).
+// The keys of the hosts are verified with the known_hosts file in KOPS_METAL_SSH_KNOWN_HOSTS (~/.ssh/known_hosts by default);
+// they are only left unverified if KOPS_METAL_SSH_INSECURE_IGNORE_HOST_KEY is true.
+func LoadSSHCredentials() (*SSHCredentials, error) {
+	c := &SSHCredentials{
+		Timeout: 30 * time.Second,
+	}
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			klog.Warningf("unable to connect to the SSH agent: %v", err)
+		} else {
+			c.Auth = append(c.Auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	{
+		privateKeyPath := os.Getenv(EnvPrivateKey)
+		explicit := privateKeyPath != ""
+		if !explicit {
+			privateKeyPath = defaultPrivateKey
+		}
+		privateKeyPath = expandHome(privateKeyPath)
+
+		key, err := ioutil.ReadFile(privateKeyPath)
+		if err != nil {
+			if explicit || !os.IsNotExist(err) {
+				return nil, fmt.Errorf("error reading private key %q: %v", privateKeyPath, err)
+			}
+		} else {
+			signer, err := ssh.ParsePrivateKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing private key %q: %v", privateKeyPath, err)
+			}
+			if fingerprint, err := sshcredentials.Fingerprint(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))); err == nil {
+				klog.V(2).Infof("Using SSH key %s from %q", fingerprint, privateKeyPath)
+			}
+			c.Auth = append(c.Auth, ssh.PublicKeys(signer))
+		}
+	}
+
+	if len(c.Auth) == 0 {
+		return nil, fmt.Errorf("no SSH credentials found: start an SSH agent, or set %s to the file of a private key", EnvPrivateKey)
+	}
+
+	if insecure, _ := strconv.ParseBool(os.Getenv(EnvInsecureIgnoreHostKey)); insecure {
+		klog.Warningf("%s is set, the keys of the hosts will not be verified", EnvInsecureIgnoreHostKey)
+		c.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	} else {
+		knownHostsPath := os.Getenv(EnvKnownHosts)
+		if knownHostsPath == "" {
+			knownHostsPath = defaultKnownHosts
+		}
+		knownHostsPath = expandHome(knownHostsPath)
+
+		callback, err := knownhosts.New(knownHostsPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading known hosts %q, set %s to true to skip the verification of the keys of the hosts: %v", knownHostsPath, EnvInsecureIgnoreHostKey, err)
+		}
+		c.HostKeyCallback = callback
+	}
+
+	return c, nil
+}
+
+// Connect opens an SSH connection to the host
+func (c *SSHCredentials) Connect(host *kops.StaticHostSpec) (*SSHHost, error) {
+	user := host.SSHUser
+	if user == "" {
+		user = "root"
+	}
+	port := 22
+	if host.SSHPort != nil {
+		port = int(*host.SSHPort)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            user,
+		Auth:            c.Auth,
+		HostKeyCallback: c.HostKeyCallback,
+		Timeout:         c.Timeout,
+	}
+
+	address := net.JoinHostPort(host.Address, strconv.Itoa(port))
+	klog.V(2).Infof("Connecting to host %q at %s as %s", host.Name, address, user)
+	client, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to host %q at %s: %v", host.Name, address, err)
+	}
+
+	return &SSHHost{
+		Spec:   *host,
+		client: client,
+		sudo:   user != "root",
+	}, nil
+}
+
+// SSHHost is an SSH connection to a host
+type SSHHost struct {
+	Spec kops.StaticHostSpec
+
+	client *ssh.Client
+	// sudo is true if the commands must be run with sudo, as the user is not root
+	sudo bool
+}
+
+// Close closes the connection
+func (h *SSHHost) Close() error {
+	return h.client.Close()
+}
+
+// Path returns the path of a file on the host
+func (h *SSHHost) Path(p string) vfs.Path {
+	return vfs.NewSSHPath(h.client, h.Spec.Address, p, h.sudo)
+}
+
+// ReadFile returns the contents of a file on the host, or nil if it does not exist
+func (h *SSHHost) ReadFile(p string) ([]byte, error) {
+	data, err := h.Path(p).ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s on host %q: %v", p, h.Spec.Name, err)
+	}
+	return data, nil
+}
+
+// WriteFile writes a file on the host
+func (h *SSHHost) WriteFile(p string, data []byte, mode os.FileMode) error {
+	if err := h.Path(p).WriteFile(bytes.NewReader(data), &vfs.SSHAcl{Mode: mode}); err != nil {
+		return fmt.Errorf("error writing %s on host %q: %v", p, h.Spec.Name, err)
+	}
+	return nil
+}
+
+// Run runs a command on the host as root, and returns its output
+func (h *SSHHost) Run(cmd string) ([]byte, error) {
+	session, err := h.client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SSH session on host %q: %v", h.Spec.Name, err)
+	}
+	defer session.Close()
+
+	if h.sudo {
+		cmd = "sudo " + cmd
+	}
+
+	klog.V(2).Infof("Running %q on host %q", cmd, h.Spec.Name)
+	output, err := session.CombinedOutput(cmd)
+	if err != nil {
+		return output, fmt.Errorf("error running %q on host %q: %v\n%s", cmd, h.Spec.Name, err, lastLines(output, 20))
+	}
+	return output, nil
+}
+
+// Architecture returns the architecture of the host
+func (h *SSHHost) Architecture() (architectures.Architecture, error) {
+	output, err := h.Run("uname -m")
+	if err != nil {
+		return "", err
+	}
+
+	machine := strings.TrimSpace(string(output))
+	switch machine {
+	case "x86_64", "amd64":
+		return architectures.ArchitectureAmd64, nil
+	case "aarch64", "arm64":
+		return architectures.ArchitectureArm64, nil
+	default:
+		return "", fmt.Errorf("unsupported architecture %q of host %q", machine, h.Spec.Name)
+	}
+}
+
+// Provision runs the bootstrap script last written to the host, and records it as applied
+func (h *SSHHost) Provision() error {
+	if _, err := h.Run("/bin/bash " + PathBootstrapScript); err != nil {
+		return err
+	}
+	if _, err := h.Run("cp " + PathBootstrapScript + " " + PathAppliedBootstrapScript); err != nil {
+		return err
+	}
+	return nil
+}
+
+func expandHome(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		return filepath.Join(os.Getenv("HOME"), p[2:])
+	}
+	return p
+}
+
+// lastLines returns the last lines of the output of a command, for error messages
+func lastLines(output []byte, n int) string {
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metal
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+// testSSHServer is an in-process SSH server, with an in-memory filesystem served over sftp.
+// It runs the few commands used to configure the hosts against that filesystem.
+type testSSHServer struct {
+	t        *testing.T
+	listener net.Listener
+	handlers sftp.Handlers
+
+	mutex sync.Mutex
+	// commands are the commands run on the server
+	commands []string
+	// scripts are the contents of the scripts run with /bin/bash, in order
+	scripts []string
+}
+
+// newTestSSHServers starts n servers, all accepting the returned credentials
+func newTestSSHServers(t *testing.T, n int) ([]*testSSHServer, *SSHCredentials) {
+	_, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating client key: %v", err)
+	}
+	clientSigner, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatalf("error building client signer: %v", err)
+	}
+	authorizedKey := clientSigner.PublicKey().Marshal()
+
+	var servers []*testSSHServer
+	var hostKeys [][]byte
+	for i := 0; i < n; i++ {
+		_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("error generating host key: %v", err)
+		}
+		hostSigner, err := ssh.NewSignerFromKey(hostKey)
+		if err != nil {
+			t.Fatalf("error building host signer: %v", err)
+		}
+		hostKeys = append(hostKeys, hostSigner.PublicKey().Marshal())
+
+		config := &ssh.ServerConfig{
+			PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+				if !bytes.Equal(key.Marshal(), authorizedKey) {
+					return nil, fmt.Errorf("unknown key for %q", conn.User())
+				}
+				return nil, nil
+			},
+		}
+		config.AddHostKey(hostSigner)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("error listening: %v", err)
+		}
+
+		s := &testSSHServer{
+			t:        t,
+			listener: listener,
+			handlers: sftp.InMemHandler(),
+		}
+		t.Cleanup(func() { listener.Close() })
+		go s.serve(config)
+		servers = append(servers, s)
+	}
+
+	credentials := &SSHCredentials{
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(clientSigner)},
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			for _, hostKey := range hostKeys {
+				if bytes.Equal(key.Marshal(), hostKey) {
+					return nil
+				}
+			}
+			return fmt.Errorf("unknown host key for %s", hostname)
+		},
+		Timeout: 10 * time.Second,
+	}
+	return servers, credentials
+}
+
+// host returns a host on the server, logging in as user
+func (s *testSSHServer) host(name string, user string) kops.StaticHostSpec {
+	port := int32(s.listener.Addr().(*net.TCPAddr).Port)
+	return kops.StaticHostSpec{
+		Name:    name,
+		Address: "127.0.0.1",
+		SSHUser: user,
+		SSHPort: &port,
+	}
+}
+
+func (s *testSSHServer) serve(config *ssh.ServerConfig) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, channels, requests, err := ssh.NewServerConn(conn, config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(requests)
+			for newChannel := range channels {
+				if newChannel.ChannelType() != "session" {
+					newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+					continue
+				}
+				channel, channelRequests, err := newChannel.Accept()
+				if err != nil {
+					continue
+				}
+				go s.serveSession(channel, channelRequests)
+			}
+		}()
+	}
+}
+
+func (s *testSSHServer) serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		switch req.Type {
+		case "subsystem":
+			if payloadString(req.Payload) != "sftp" {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			s.serveSFTP(channel)
+			return
+
+		case "exec":
+			req.Reply(true, nil)
+			raw := payloadString(req.Payload)
+			s.mutex.Lock()
+			s.commands = append(s.commands, raw)
+			s.mutex.Unlock()
+			cmd := strings.TrimPrefix(raw, "sudo ")
+			if cmd == "/usr/lib/openssh/sftp-server" {
+				s.serveSFTP(channel)
+				return
+			}
+			output, err := s.run(cmd)
+			channel.Write(output)
+			status := 0
+			if err != nil {
+				io.WriteString(channel.Stderr(), err.Error())
+				status = 1
+			}
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+			return
+
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *testSSHServer) serveSFTP(channel ssh.Channel) {
+	server := sftp.NewRequestServer(channel, s.handlers)
+	server.Serve()
+	server.Close()
+}
+
+// run runs a command against the in-memory filesystem
+func (s *testSSHServer) run(cmd string) ([]byte, error) {
+	fs, err := s.fs()
+	if err != nil {
+		return nil, err
+	}
+	defer fs.Close()
+
+	args := strings.Fields(cmd)
+	switch {
+	case cmd == "uname -m":
+		return []byte("x86_64\n"), nil
+
+	case len(args) == 3 && args[0] == "mv":
+		return nil, fs.Rename(args[1], args[2])
+
+	case len(args) == 3 && args[0] == "cp":
+		data, err := readFile(fs, args[1])
+		if err != nil {
+			return nil, err
+		}
+		return nil, writeFile(fs, args[2], data)
+
+	case len(args) == 2 && args[0] == "sha256sum":
+		data, err := readFile(fs, args[1])
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		return []byte(hex.EncodeToString(sum[:]) + "  " + args[1] + "\n"), nil
+
+	case len(args) == 2 && args[0] == "/bin/bash":
+		data, err := readFile(fs, args[1])
+		if err != nil {
+			return nil, err
+		}
+		s.mutex.Lock()
+		s.scripts = append(s.scripts, string(data))
+		s.mutex.Unlock()
+		return []byte("ran " + args[1] + "\n"), nil
+
+	default:
+		return nil, fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// fs returns a client of the in-memory filesystem
+func (s *testSSHServer) fs() (*sftp.Client, error) {
+	serverConn, clientConn := net.Pipe()
+	server := sftp.NewRequestServer(serverConn, s.handlers)
+	go func() {
+		server.Serve()
+		server.Close()
+	}()
+	return sftp.NewClientPipe(clientConn, clientConn)
+}
+
+// readFile reads a file on the server, for the tests
+func (s *testSSHServer) readFile(p string) string {
+	fs, err := s.fs()
+	if err != nil {
+		s.t.Fatalf("error connecting to the filesystem: %v", err)
+	}
+	defer fs.Close()
+
+	data, err := readFile(fs, p)
+	if err != nil {
+		s.t.Fatalf("error reading %s: %v", p, err)
+	}
+	return string(data)
+}
+
+// writeFile writes a file on the server, for the tests
+func (s *testSSHServer) writeFile(p string, data string) {
+	fs, err := s.fs()
+	if err != nil {
+		s.t.Fatalf("error connecting to the filesystem: %v", err)
+	}
+	defer fs.Close()
+
+	if err := fs.MkdirAll(p[:strings.LastIndex(p, "/")]); err != nil {
+		s.t.Fatalf("error creating the directory of %s: %v", p, err)
+	}
+	if err := writeFile(fs, p, []byte(data)); err != nil {
+		s.t.Fatalf("error writing %s: %v", p, err)
+	}
+}
+
+func (s *testSSHServer) getCommands() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *testSSHServer) getScripts() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.scripts...)
+}
+
+func readFile(fs *sftp.Client, p string) ([]byte, error) {
+	f, err := fs.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func writeFile(fs *sftp.Client, p string, data []byte) error {
+	f, err := fs.Create(p)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// payloadString decodes the string payload of the exec and subsystem requests
+func payloadString(payload []byte) string {
+	if len(payload) < 4 {
+		return ""
+	}
+	n := binary.BigEndian.Uint32(payload)
+	if int(n) > len(payload)-4 {
+		return ""
+	}
+	return string(payload[4 : 4+n])
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metaltasks
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
+	"k8s.io/kops/upup/pkg/fi/fitasks"
+	"k8s.io/kops/util/pkg/architectures"
+	"k8s.io/kops/util/pkg/mirrors"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// Host is a pre-provisioned host, configured by running the bootstrap script over SSH
+// +kops:fitask
+type Host struct {
+	Name      *string
+	Lifecycle fi.Lifecycle
+
+	Address *string
+	SSHUser *string
+	SSHPort *int32
+
+	// UserData is the bootstrap script of the host
+	UserData fi.Resource
+
+	// NodeUpAssets are the nodeup binaries, pushed to the host so it does not need to download them
+	NodeUpAssets map[architectures.Architecture]*mirrors.MirroredAsset
+}
+
+var _ fi.Task = &Host{}
+var _ fi.CompareWithID = &Host{}
+var _ fi.HasDependencies = &Host{}
+
+func (e *Host) CompareWithID() *string {
+	return e.Name
+}
+
+// GetDependencies makes the host wait for the files in the state store that nodeup reads, as the host is provisioned right away
+func (e *Host) GetDependencies(tasks map[string]fi.Task) []fi.Task {
+	var deps []fi.Task
+	if e.UserData != nil {
+		deps = fi.FindDependencies(tasks, e.UserData)
+	}
+	for _, task := range tasks {
+		switch task.(type) {
+		case *fitasks.ManagedFile, *fitasks.Keypair, *fitasks.Secret, *fitasks.MirrorKeystore, *fitasks.MirrorSecrets:
+			deps = append(deps, task)
+		}
+	}
+	return deps
+}
+
+func (e *Host) spec() *kops.StaticHostSpec {
+	return &kops.StaticHostSpec{
+		Name:    fi.StringValue(e.Name),
+		Address: fi.StringValue(e.Address),
+		SSHUser: fi.StringValue(e.SSHUser),
+		SSHPort: e.SSHPort,
+	}
+}
+
+// Find returns the host with the bootstrap script last written to it, or nil if none was written yet
+func (e *Host) Find(c *fi.Context) (*Host, error) {
+	cloud := c.Cloud.(metal.MetalCloud)
+
+	h, err := cloud.Connect(e.spec())
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	userData, err := h.ReadFile(metal.PathBootstrapScript)
+	if err != nil {
+		return nil, err
+	}
+	if userData == nil {
+		return nil, nil
+	}
+
+	return &Host{
+		Name:         e.Name,
+		Lifecycle:    e.Lifecycle,
+		Address:      e.Address,
+		SSHUser:      e.SSHUser,
+		SSHPort:      e.SSHPort,
+		UserData:     fi.NewBytesResource(userData),
+		NodeUpAssets: e.NodeUpAssets,
+	}, nil
+}
+
+func (e *Host) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *Host) CheckChanges(a, e, changes *Host) error {
+	if e.Name == nil {
+		return fi.RequiredField("Name")
+	}
+	if e.Address == nil {
+		return fi.RequiredField("Address")
+	}
+	if e.UserData == nil {
+		return fi.RequiredField("UserData")
+	}
+	return nil
+}
+
+// RenderMetal pushes nodeup and the bootstrap script to the host.
+// A new host is provisioned right away; a host that was already provisioned is reprovisioned by kops rolling-update.
+func (_ *Host) RenderMetal(t *metal.MetalAPITarget, a, e, changes *Host) error {
+	userData, err := fi.ResourceAsBytes(e.UserData)
+	if err != nil {
+		return err
+	}
+
+	h, err := t.Cloud.Connect(e.spec())
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	arch, err := h.Architecture()
+	if err != nil {
+		return err
+	}
+	if asset := e.NodeUpAssets[arch]; asset != nil {
+		if err := pushNodeUp(h, asset); err != nil {
+			return err
+		}
+	}
+
+	if err := h.WriteFile(metal.PathBootstrapScript, userData, 0700); err != nil {
+		return err
+	}
+
+	applied, err := h.ReadFile(metal.PathAppliedBootstrapScript)
+	if err != nil {
+		return err
+	}
+	if applied == nil {
+		klog.Infof("Provisioning host %q", fi.StringValue(e.Name))
+		return h.Provision()
+	}
+	if !bytes.Equal(applied, userData) {
+		klog.Infof("Host %q will be reprovisioned by kops rolling-update", fi.StringValue(e.Name))
+	}
+	return nil
+}
+
+// pushNodeUp copies nodeup to the host, unless the host already has it
+func pushNodeUp(h *metal.SSHHost, asset *mirrors.MirroredAsset) error {
+	if asset.Hash == nil {
+		return nil
+	}
+
+	if output, err := h.Run("sha256sum " + metal.PathNodeUp); err == nil {
+		fields := strings.Fields(string(output))
+		if len(fields) > 0 && fields[0] == asset.Hash.Hex() {
+			return nil
+		}
+	}
+
+	var lastErr error
+	for _, location := range asset.Locations {
+		data, err := vfs.Context.ReadFile(location)
+		if err != nil {
+			lastErr = fmt.Errorf("error downloading nodeup from %q: %v", location, err)
+			continue
+		}
+
+		hash, err := asset.Hash.Algorithm.Hash(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		if !hash.Equal(asset.Hash) {
+			lastErr = fmt.Errorf("nodeup downloaded from %q has hash %s, expected %s", location, hash.Hex(), asset.Hash.Hex())
+			continue
+		}
+
+		klog.V(2).Infof("Copying nodeup from %q to host %q", location, h.Spec.Name)
+		return h.WriteFile(metal.PathNodeUp, data, 0755)
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no location for nodeup")
+	}
+	return lastErr
+}
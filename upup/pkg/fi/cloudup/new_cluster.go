@@ -944,19 +944,6 @@ func setupTopology(opt *NewClusterOptions, cluster *api.Cluster, allZones sets.S
 			cluster.Spec.Subnets[i].Type = api.SubnetTypePublic
 		}
 
-		if opt.IPv6 {
-			if api.CloudProviderID(cluster.Spec.CloudProvider) == api.CloudProviderAWS {
-				klog.Warningf("IPv6 support is EXPERIMENTAL and can be changed or removed at any time in the future!!!")
-				for i := range cluster.Spec.Subnets {
-					// Start IPv6 CIDR numbering from "1" to reserve /64#0 for later use
-					// with NonMasqueradeCIDR, ClusterCIDR and ServiceClusterIPRange
-					cluster.Spec.Subnets[i].IPv6CIDR = fmt.Sprintf("/64#%x", i+1)
-				}
-			} else {
-				klog.Errorf("IPv6 support is available only on AWS")
-			}
-		}
-
 	case api.TopologyPrivate:
 		if cluster.Spec.Networking.Kubenet != nil {
 			return nil, fmt.Errorf("invalid networking option %s. Kubenet does not support private topology", opt.Networking)
@@ -1036,6 +1023,17 @@ func setupTopology(opt *NewClusterOptions, cluster *api.Cluster, allZones sets.S
 		return nil, fmt.Errorf("invalid topology %s", opt.Topology)
 	}
 
+	if opt.IPv6 {
+		if api.CloudProviderID(cluster.Spec.CloudProvider) != api.CloudProviderAWS {
+			return nil, fmt.Errorf("IPv6 is only supported on AWS")
+		}
+		for i := range cluster.Spec.Subnets {
+			// Start IPv6 CIDR numbering from "1" to reserve /64#0 for later use
+			// with NonMasqueradeCIDR, ClusterCIDR and ServiceClusterIPRange
+			cluster.Spec.Subnets[i].IPv6CIDR = fmt.Sprintf("/64#%x", i+1)
+		}
+	}
+
 	cluster.Spec.Topology.DNS = &api.DNSSpec{}
 	switch strings.ToLower(opt.DNSType) {
 	case "public", "":
@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	api "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/diff"
 	"k8s.io/kops/upup/pkg/fi"
@@ -347,3 +348,57 @@ func TestSetupNetworking(t *testing.T) {
 		}
 	}
 }
+
+func TestSetupTopologyIPv6(t *testing.T) {
+	tests := []struct {
+		topology string
+		expected map[string]string
+	}{
+		{
+			topology: api.TopologyPublic,
+			expected: map[string]string{
+				"us-test-1a": "/64#1",
+				"us-test-1b": "/64#2",
+			},
+		},
+		{
+			topology: api.TopologyPrivate,
+			expected: map[string]string{
+				"us-test-1a":         "/64#1",
+				"us-test-1b":         "/64#2",
+				"utility-us-test-1a": "/64#3",
+				"utility-us-test-1b": "/64#4",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cluster := &api.Cluster{
+			Spec: api.ClusterSpec{
+				CloudProvider: string(api.CloudProviderAWS),
+				Networking: &api.NetworkingSpec{
+					Calico: &api.CalicoNetworkingSpec{},
+				},
+				Subnets: []api.ClusterSubnetSpec{
+					{Name: "us-test-1a", Zone: "us-test-1a"},
+					{Name: "us-test-1b", Zone: "us-test-1b"},
+				},
+			},
+		}
+		opt := &NewClusterOptions{
+			Topology: test.topology,
+			IPv6:     true,
+		}
+		if _, err := setupTopology(opt, cluster, sets.NewString("us-test-1a", "us-test-1b")); err != nil {
+			t.Fatalf("error during topology setup: %v", err)
+		}
+
+		actual := make(map[string]string)
+		for _, subnet := range cluster.Spec.Subnets {
+			actual[subnet.Name] = subnet.IPv6CIDR
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("unexpected IPv6 CIDRs for %s topology: expected %v, got %v", test.topology, test.expected, actual)
+		}
+	}
+}
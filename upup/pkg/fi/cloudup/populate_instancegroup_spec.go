@@ -75,6 +75,17 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 	ig := &kops.InstanceGroup{}
 	reflectutils.JSONMergeStruct(ig, input)
 
+	isMetal := kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderMetal
+	if isMetal {
+		// The instances of the instance group are its hosts, which already run an operating system
+		if ig.Spec.MinSize == nil {
+			ig.Spec.MinSize = fi.Int32(int32(len(ig.Spec.Hosts)))
+		}
+		if ig.Spec.MaxSize == nil {
+			ig.Spec.MaxSize = fi.Int32(int32(len(ig.Spec.Hosts)))
+		}
+	}
+
 	// TODO: Clean up
 	if ig.IsMaster() {
 		if ig.Spec.MachineType == "" {
@@ -121,7 +132,7 @@ func PopulateInstanceGroupSpec(cluster *kops.Cluster, input *kops.InstanceGroup,
 		}
 	}
 
-	if ig.Spec.Image == "" {
+	if ig.Spec.Image == "" && !isMetal {
 		architecture, err := MachineArchitecture(cloud, ig.Spec.MachineType)
 		if err != nil {
 			return nil, fmt.Errorf("unable to determine machine architecture for InstanceGroup %q: %v", ig.ObjectMeta.Name, err)
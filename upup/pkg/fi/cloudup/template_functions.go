@@ -98,6 +98,7 @@ func (tf *TemplateFunctions) AddTo(dest template.FuncMap, secretStore fi.SecretS
 
 	dest["GetInstanceGroup"] = tf.GetInstanceGroup
 	dest["GetNodeInstanceGroups"] = tf.GetNodeInstanceGroups
+	dest["KarpenterProvisioners"] = tf.KarpenterProvisioners
 	dest["HasHighlyAvailableControlPlane"] = tf.HasHighlyAvailableControlPlane
 	dest["ControlPlaneControllerReplicas"] = tf.ControlPlaneControllerReplicas
 
@@ -235,6 +236,7 @@ func (tf *TemplateFunctions) AddTo(dest template.FuncMap, secretStore fi.SecretS
 	}
 
 	dest["IsIPv6Only"] = tf.IsIPv6Only
+	dest["ContainerdRuntimeHandlers"] = tf.ContainerdRuntimeHandlers
 	dest["UseServiceAccountIAM"] = tf.UseServiceAccountIAM
 	dest["GetVPCID"] = tf.GetVPCID
 
@@ -676,6 +678,99 @@ func (tf *TemplateFunctions) GetNodeInstanceGroups() map[string]kops.InstanceGro
 	return nodegroups
 }
 
+// KarpenterProvisioner holds the settings of the karpenter Provisioner for a provisioned instance group
+type KarpenterProvisioner struct {
+	// Name is the name of the instance group
+	Name string
+	// LaunchTemplate is the name of the launch template of the instance group
+	LaunchTemplate string
+	// InstanceTypes are the instance types the provisioner may launch
+	InstanceTypes []string
+	// CapacityTypes are the purchase options the provisioner may use, spot and/or on-demand
+	CapacityTypes []string
+	// SubnetTag is the tag of the subnets the provisioner may launch instances in
+	SubnetTag string
+	// Labels are the node labels of the instance group
+	Labels map[string]string
+	// Taints are the node taints of the instance group
+	Taints []corev1.Taint
+	// Tags are the cloud tags of the instances
+	Tags map[string]string
+}
+
+// KarpenterProvisioners returns the provisioner settings of the instance groups managed by karpenter
+func (tf *TemplateFunctions) KarpenterProvisioners() ([]*KarpenterProvisioner, error) {
+	var provisioners []*KarpenterProvisioner
+	for _, ig := range tf.KopsModelContext.InstanceGroups {
+		if !ig.IsProvisioned() {
+			continue
+		}
+
+		p := &KarpenterProvisioner{
+			Name:           ig.ObjectMeta.Name,
+			LaunchTemplate: tf.AutoscalingGroupName(ig),
+			InstanceTypes:  strings.Split(ig.Spec.MachineType, ","),
+			CapacityTypes:  []string{"on-demand"},
+			SubnetTag:      awsup.TagNameProvisionedSubnetPrefix + ig.ObjectMeta.Name,
+			Labels:         map[string]string{kops.NodeLabelInstanceGroup: ig.ObjectMeta.Name},
+		}
+		if mip := ig.Spec.MixedInstancesPolicy; mip != nil {
+			if len(mip.Instances) != 0 {
+				p.InstanceTypes = mip.Instances
+			}
+			if mip.OnDemandAboveBase != nil && *mip.OnDemandAboveBase < 100 {
+				p.CapacityTypes = []string{"on-demand", "spot"}
+			}
+		}
+		if ig.Spec.MaxPrice != nil {
+			p.CapacityTypes = []string{"spot"}
+		}
+		for k, v := range ig.Spec.NodeLabels {
+			p.Labels[k] = v
+		}
+		for _, spec := range ig.Spec.Taints {
+			taint, err := parseTaint(spec)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing taint %q of instance group %q: %v", spec, ig.ObjectMeta.Name, err)
+			}
+			p.Taints = append(p.Taints, taint)
+		}
+
+		tags, err := tf.CloudTagsForInstanceGroup(ig)
+		if err != nil {
+			return nil, err
+		}
+		p.Tags = make(map[string]string)
+		for k, v := range tags {
+			// The node templates only matter to cluster-autoscaler
+			if !strings.HasPrefix(k, "k8s.io/cluster-autoscaler/") {
+				p.Tags[k] = v
+			}
+		}
+
+		provisioners = append(provisioners, p)
+	}
+	return provisioners, nil
+}
+
+// parseTaint parses a taint in the key=value:Effect or key:Effect format
+func parseTaint(spec string) (corev1.Taint, error) {
+	var taint corev1.Taint
+
+	parts := strings.Split(spec, ":")
+	if len(parts) != 2 || parts[1] == "" {
+		return taint, fmt.Errorf("invalid taint spec")
+	}
+	taint.Effect = corev1.TaintEffect(parts[1])
+
+	kv := strings.SplitN(parts[0], "=", 2)
+	taint.Key = kv[0]
+	if len(kv) == 2 {
+		taint.Value = kv[1]
+	}
+	return taint, nil
+}
+
 func (tf *TemplateFunctions) GetVPCID() (string, error) {
 	vpcs, err := aws.DescribeVPCs(tf.cloud, tf.ClusterName())
 	if err != nil {
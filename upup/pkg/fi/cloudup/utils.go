@@ -32,6 +32,7 @@ import (
 	"k8s.io/kops/upup/pkg/fi/cloudup/azure"
 	"k8s.io/kops/upup/pkg/fi/cloudup/do"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/metal"
 	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
 )
 
@@ -164,6 +165,12 @@ func BuildCloud(cluster *kops.Cluster) (fi.Cloud, error) {
 
 			cloud = azureCloud
 		}
+
+	case kops.CloudProviderMetal:
+		{
+			// The SSH credentials are loaded when the first host is contacted
+			cloud = metal.NewMetalCloud(nil)
+		}
 	default:
 		return nil, fmt.Errorf("unknown CloudProvider %q", cluster.Spec.CloudProvider)
 	}
@@ -185,6 +192,9 @@ func SupportedClouds() []string {
 	if featureflag.AlphaAllowGCE.Enabled() {
 		clouds = append(clouds, string(kops.CloudProviderGCE))
 	}
+	if featureflag.Metal.Enabled() {
+		clouds = append(clouds, string(kops.CloudProviderMetal))
+	}
 
 	sort.Strings(clouds)
 	return clouds
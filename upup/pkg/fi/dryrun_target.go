@@ -414,6 +414,29 @@ func (t *DryRunTarget) Changes() (map[string]Task, map[string]Task) {
 	return creates, updates
 }
 
+// ChangedTasks returns the expected state of the tasks which are going to be created or updated
+func (t *DryRunTarget) ChangedTasks() []Task {
+	var tasks []Task
+	for _, r := range t.changes {
+		tasks = append(tasks, r.e)
+	}
+	return tasks
+}
+
+// ActualTasks returns the actual state of the tasks which are going to be created or updated,
+// keyed by the expected state of the task. The actual state is nil for tasks which are going to be created.
+func (t *DryRunTarget) ActualTasks() map[Task]Task {
+	actual := make(map[Task]Task)
+	for _, r := range t.changes {
+		if r.aIsNil {
+			actual[r.e] = nil
+		} else {
+			actual[r.e] = r.a
+		}
+	}
+	return actual
+}
+
 // HasChanges returns true iff any changes would have been made
 func (t *DryRunTarget) HasChanges() bool {
 	return len(t.changes)+len(t.deletions) != 0
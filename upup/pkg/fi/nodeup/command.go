@@ -67,38 +67,116 @@ type NodeUpCommand struct {
 	CacheDir       string
 	ConfigLocation string
 	Target         string
-	cluster        *api.Cluster
+	// ReconcileInterval is the interval at which the node configuration is checked for drift, when running as an agent
+	ReconcileInterval time.Duration
+	// Repair enables the repair of drifted files and services, when running as an agent
+	Repair  bool
+	cluster *api.Cluster
+}
+
+// nodeupTasks holds the tasks built from the nodeup configuration, with everything needed to run them
+type nodeupTasks struct {
+	taskMap      map[string]fi.Task
+	cloud        fi.Cloud
+	keyStore     fi.Keystore
+	secretStore  fi.SecretStore
+	configBase   vfs.Path
+	modelContext *model.NodeupModelContext
+	// configChanged is true if the nodeup config no longer matches the hash from the boot config
+	configChanged bool
+	// nodeupConfigHash is the hash of the nodeup config the tasks were built from
+	nodeupConfigHash string
 }
 
 // Run is responsible for perform the nodeup process
 func (c *NodeUpCommand) Run(out io.Writer) error {
 	ctx := context.Background()
 
+	tasks, err := c.buildTasks(ctx, false)
+	if err != nil {
+		return err
+	}
+	taskMap := tasks.taskMap
+	cloud := tasks.cloud
+	modelContext := tasks.modelContext
+
+	var target fi.Target
+	checkExisting := true
+
+	switch c.Target {
+	case "direct":
+		target = &local.LocalTarget{
+			CacheDir: c.CacheDir,
+		}
+	case "dryrun":
+		assetBuilder := assets.NewAssetBuilder(c.cluster, false)
+		target = fi.NewDryRunTarget(assetBuilder, out)
+	case "cloudinit":
+		checkExisting = false
+		target = cloudinit.NewCloudInitTarget(out)
+	default:
+		return fmt.Errorf("unsupported target type %q", c.Target)
+	}
+
+	context, err := fi.NewContext(target, c.cluster, cloud, tasks.keyStore, tasks.secretStore, tasks.configBase, checkExisting, taskMap)
+	if err != nil {
+		klog.Exitf("error building context: %v", err)
+	}
+	defer context.Close()
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+
+	err = context.RunTasks(options)
+	if err != nil {
+		klog.Exitf("error running tasks: %v", err)
+	}
+
+	err = target.Finish(taskMap)
+	if err != nil {
+		klog.Exitf("error closing target: %v", err)
+	}
+
+	if modelContext.NodeupConfig.EnableLifecycleHook {
+		if api.CloudProviderID(c.cluster.Spec.CloudProvider) == api.CloudProviderAWS {
+			err := completeWarmingLifecycleAction(cloud.(awsup.AWSCloud), modelContext)
+			if err != nil {
+				return fmt.Errorf("failed to complete lifecylce action: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// buildTasks loads the nodeup configuration and builds the tasks for the node.
+// If allowConfigChanges is true, a nodeup config that doesn't match the hash from the boot config
+// is accepted and reported through configChanged, otherwise it is an error.
+func (c *NodeUpCommand) buildTasks(ctx context.Context, allowConfigChanges bool) (*nodeupTasks, error) {
 	var bootConfig nodeup.BootConfig
 	if c.ConfigLocation != "" {
 		b, err := vfs.Context.ReadFile(c.ConfigLocation)
 		if err != nil {
-			return fmt.Errorf("error loading configuration %q: %v", c.ConfigLocation, err)
+			return nil, fmt.Errorf("error loading configuration %q: %v", c.ConfigLocation, err)
 		}
 
 		err = utils.YamlUnmarshal(b, &bootConfig)
 		if err != nil {
-			return fmt.Errorf("error parsing configuration %q: %v", c.ConfigLocation, err)
+			return nil, fmt.Errorf("error parsing configuration %q: %v", c.ConfigLocation, err)
 		}
 	} else {
-		return fmt.Errorf("ConfigLocation is required")
+		return nil, fmt.Errorf("ConfigLocation is required")
 	}
 
 	if c.CacheDir == "" {
-		return fmt.Errorf("CacheDir is required")
+		return nil, fmt.Errorf("CacheDir is required")
 	}
 
 	region, err := getRegion(ctx, &bootConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err = seedRNG(ctx, &bootConfig, region); err != nil {
-		return err
+		return nil, err
 	}
 
 	var configBase vfs.Path
@@ -109,17 +187,17 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	if bootConfig.ConfigServer != nil {
 		response, err := getNodeConfigFromServer(ctx, &bootConfig, region)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		nodeConfig = response.NodeConfig
 	} else if fi.StringValue(bootConfig.ConfigBase) != "" {
 		var err error
 		configBase, err = vfs.Context.BuildVfsPath(*bootConfig.ConfigBase)
 		if err != nil {
-			return fmt.Errorf("cannot parse ConfigBase %q: %v", *bootConfig.ConfigBase, err)
+			return nil, fmt.Errorf("cannot parse ConfigBase %q: %v", *bootConfig.ConfigBase, err)
 		}
 	} else {
-		return fmt.Errorf("ConfigBase or ConfigServer is required")
+		return nil, fmt.Errorf("ConfigBase or ConfigServer is required")
 	}
 
 	{
@@ -134,18 +212,18 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 			b, err = p.ReadFile()
 			if err != nil {
-				return fmt.Errorf("error loading Cluster %q: %v", p, err)
+				return nil, fmt.Errorf("error loading Cluster %q: %v", p, err)
 			}
 			clusterDescription = fmt.Sprintf("%q", p)
 		}
 
 		o, _, err := kopscodecs.Decode(b, nil)
 		if err != nil {
-			return fmt.Errorf("error parsing Cluster %s: %v", clusterDescription, err)
+			return nil, fmt.Errorf("error parsing Cluster %s: %v", clusterDescription, err)
 		}
 		var ok bool
 		if c.cluster, ok = o.(*api.Cluster); !ok {
-			return fmt.Errorf("unexpected object type for Cluster %s: %T", clusterDescription, o)
+			return nil, fmt.Errorf("unexpected object type for Cluster %s: %T", clusterDescription, o)
 		}
 	}
 
@@ -153,7 +231,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	var nodeupConfigHash [32]byte
 	if nodeConfig != nil {
 		if err := utils.YamlUnmarshal([]byte(nodeConfig.NodeupConfig), &nodeupConfig); err != nil {
-			return fmt.Errorf("error parsing BootConfig config response: %v", err)
+			return nil, fmt.Errorf("error parsing BootConfig config response: %v", err)
 		}
 		nodeupConfigHash = sha256.Sum256([]byte(nodeConfig.NodeupConfig))
 		nodeupConfig.CAs[fi.CertificateIDCA] = bootConfig.ConfigServer.CACertificates
@@ -162,34 +240,39 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 		b, err := nodeupConfigLocation.ReadFile()
 		if err != nil {
-			return fmt.Errorf("error loading NodeupConfig %q: %v", nodeupConfigLocation, err)
+			return nil, fmt.Errorf("error loading NodeupConfig %q: %v", nodeupConfigLocation, err)
 		}
 
 		if err = utils.YamlUnmarshal(b, &nodeupConfig); err != nil {
-			return fmt.Errorf("error parsing NodeupConfig %q: %v", nodeupConfigLocation, err)
+			return nil, fmt.Errorf("error parsing NodeupConfig %q: %v", nodeupConfigLocation, err)
 		}
 		nodeupConfigHash = sha256.Sum256(b)
 	} else {
-		return fmt.Errorf("no instance group defined in nodeup config")
+		return nil, fmt.Errorf("no instance group defined in nodeup config")
 	}
 
-	if bootConfig.NodeupConfigHash != base64.StdEncoding.EncodeToString(nodeupConfigHash[:]) {
-		return fmt.Errorf("nodeup config hash mismatch")
+	configChanged := false
+	encodedNodeupConfigHash := base64.StdEncoding.EncodeToString(nodeupConfigHash[:])
+	if bootConfig.NodeupConfigHash != encodedNodeupConfigHash {
+		if !allowConfigChanges {
+			return nil, fmt.Errorf("nodeup config hash mismatch")
+		}
+		configChanged = true
 	}
 
 	err = evaluateSpec(c, &nodeupConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	architecture, err := architectures.FindArchitecture()
 	if err != nil {
-		return fmt.Errorf("error determining OS architecture: %v", err)
+		return nil, fmt.Errorf("error determining OS architecture: %v", err)
 	}
 
 	distribution, err := distributions.FindDistribution("/")
 	if err != nil {
-		return fmt.Errorf("error determining OS distribution: %v", err)
+		return nil, fmt.Errorf("error determining OS distribution: %v", err)
 	}
 
 	configAssets := nodeupConfig.Assets[architecture]
@@ -197,7 +280,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	for _, asset := range configAssets {
 		err := assetStore.Add(asset)
 		if err != nil {
-			return fmt.Errorf("error adding asset %q: %v", asset, err)
+			return nil, fmt.Errorf("error adding asset %q: %v", asset, err)
 		}
 	}
 
@@ -206,7 +289,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	if api.CloudProviderID(c.cluster.Spec.CloudProvider) == api.CloudProviderAWS {
 		awsCloud, err := awsup.NewAWSCloud(region, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		cloud = awsCloud
 	}
@@ -220,6 +303,9 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		Distribution: distribution,
 		BootConfig:   &bootConfig,
 		NodeupConfig: &nodeupConfig,
+
+		ConfigLocation: c.ConfigLocation,
+		ImageDigests:   nodeupConfig.ImageDigests,
 	}
 
 	var secretStore fi.SecretStore
@@ -230,13 +316,13 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		klog.Infof("Building SecretStore at %q", c.cluster.Spec.SecretStore)
 		p, err := vfs.Context.BuildVfsPath(c.cluster.Spec.SecretStore)
 		if err != nil {
-			return fmt.Errorf("error building secret store path: %v", err)
+			return nil, fmt.Errorf("error building secret store path: %v", err)
 		}
 
 		secretStore = secrets.NewVFSSecretStore(c.cluster, p)
 		modelContext.SecretStore = secretStore
 	} else {
-		return fmt.Errorf("SecretStore not set")
+		return nil, fmt.Errorf("SecretStore not set")
 	}
 
 	if nodeConfig != nil {
@@ -245,34 +331,34 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		klog.Infof("Building KeyStore at %q", c.cluster.Spec.KeyStore)
 		p, err := vfs.Context.BuildVfsPath(c.cluster.Spec.KeyStore)
 		if err != nil {
-			return fmt.Errorf("error building key store path: %v", err)
+			return nil, fmt.Errorf("error building key store path: %v", err)
 		}
 
 		modelContext.KeyStore = fi.NewVFSCAStore(c.cluster, p)
 		keyStore = modelContext.KeyStore
 	} else {
-		return fmt.Errorf("KeyStore not set")
+		return nil, fmt.Errorf("KeyStore not set")
 	}
 
 	if err := modelContext.Init(); err != nil {
-		return err
+		return nil, err
 	}
 
 	if api.CloudProviderID(c.cluster.Spec.CloudProvider) == api.CloudProviderAWS {
 		instanceIDBytes, err := vfs.Context.ReadFile("metadata://aws/meta-data/instance-id")
 		if err != nil {
-			return fmt.Errorf("error reading instance-id from AWS metadata: %v", err)
+			return nil, fmt.Errorf("error reading instance-id from AWS metadata: %v", err)
 		}
 		modelContext.InstanceID = string(instanceIDBytes)
 
 		modelContext.ConfigurationMode, err = getAWSConfigurationMode(modelContext)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if err := loadKernelModules(modelContext); err != nil {
-		return err
+		return nil, err
 	}
 
 	loader := &Loader{}
@@ -281,6 +367,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.DirectoryBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.UpdateServiceBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.VolumesBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.MetalVolumesBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.ContainerdBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.DockerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.ProtokubeBuilder{NodeupModelContext: modelContext})
@@ -303,6 +390,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.KubeProxyBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KopsControllerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.WarmPoolBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.NodeupAgentBuilder{NodeupModelContext: modelContext})
 
 	loader.Builders = append(loader.Builders, &networking.CommonBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &networking.CalicoBuilder{NodeupModelContext: modelContext})
@@ -313,7 +401,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.BootstrapClientBuilder{NodeupModelContext: modelContext})
 	taskMap, err := loader.Build()
 	if err != nil {
-		return fmt.Errorf("error building loader: %v", err)
+		return nil, fmt.Errorf("error building loader: %v", err)
 	}
 
 	for i, image := range nodeupConfig.Images[architecture] {
@@ -325,52 +413,16 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	}
 	// Protokube load image task is in ProtokubeBuilder
 
-	var target fi.Target
-	checkExisting := true
-
-	switch c.Target {
-	case "direct":
-		target = &local.LocalTarget{
-			CacheDir: c.CacheDir,
-		}
-	case "dryrun":
-		assetBuilder := assets.NewAssetBuilder(c.cluster, false)
-		target = fi.NewDryRunTarget(assetBuilder, out)
-	case "cloudinit":
-		checkExisting = false
-		target = cloudinit.NewCloudInitTarget(out)
-	default:
-		return fmt.Errorf("unsupported target type %q", c.Target)
-	}
-
-	context, err := fi.NewContext(target, c.cluster, cloud, keyStore, secretStore, configBase, checkExisting, taskMap)
-	if err != nil {
-		klog.Exitf("error building context: %v", err)
-	}
-	defer context.Close()
-
-	var options fi.RunTasksOptions
-	options.InitDefaults()
-
-	err = context.RunTasks(options)
-	if err != nil {
-		klog.Exitf("error running tasks: %v", err)
-	}
-
-	err = target.Finish(taskMap)
-	if err != nil {
-		klog.Exitf("error closing target: %v", err)
-	}
-
-	if nodeupConfig.EnableLifecycleHook {
-		if api.CloudProviderID(c.cluster.Spec.CloudProvider) == api.CloudProviderAWS {
-			err := completeWarmingLifecycleAction(cloud.(awsup.AWSCloud), modelContext)
-			if err != nil {
-				return fmt.Errorf("failed to complete lifecylce action: %w", err)
-			}
-		}
-	}
-	return nil
+	return &nodeupTasks{
+		taskMap:          taskMap,
+		cloud:            cloud,
+		keyStore:         keyStore,
+		secretStore:      secretStore,
+		configBase:       configBase,
+		modelContext:     modelContext,
+		configChanged:    configChanged,
+		nodeupConfigHash: encodedNodeupConfigHash,
+	}, nil
 }
 
 func completeWarmingLifecycleAction(cloud awsup.AWSCloud, modelContext *model.NodeupModelContext) error {
@@ -0,0 +1,473 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/local"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+const (
+	// NodeConditionConfigurationDrift is the node condition reporting whether the node configuration drifted from the nodeup config
+	NodeConditionConfigurationDrift v1.NodeConditionType = "KopsConfigurationDrift"
+
+	// DriftReasonNoDrift is the reason used when the node matches its configuration
+	DriftReasonNoDrift = "NoDrift"
+	// DriftReasonDrifted is the reason used when some of the node configuration drifted
+	DriftReasonDrifted = "ConfigurationDrifted"
+	// DriftReasonRepaired is the reason used when the drifted configuration was repaired
+	DriftReasonRepaired = "ConfigurationRepaired"
+	// DriftReasonConfigChanged is the reason used when the nodeup config changed since the node booted
+	DriftReasonConfigChanged = "NodeupConfigChanged"
+
+	// maxDriftMessageTasks is the maximum number of tasks listed in the condition message
+	maxDriftMessageTasks = 10
+)
+
+// Drift is the result of comparing the node with its nodeup configuration
+type Drift struct {
+	// Tasks are the names of the tasks that don't match the node state
+	Tasks []string
+	// ConfigChanged is true if the nodeup config changed since the node booted
+	ConfigChanged bool
+	// Repaired is true if the drifted files and services were repaired
+	Repaired bool
+}
+
+// HasDrift returns true if any part of the node configuration drifted
+func (d *Drift) HasDrift() bool {
+	return len(d.Tasks) != 0 || d.ConfigChanged
+}
+
+// DriftReporter publishes the drift found on the node
+type DriftReporter interface {
+	ReportDrift(ctx context.Context, drift *Drift) error
+}
+
+// Reconcile runs nodeup as an agent, periodically checking the node for drift from its nodeup config.
+// Drift is reported as a node condition and, if Repair is set, the drifted files and services are repaired.
+func (c *NodeUpCommand) Reconcile(ctx context.Context, out io.Writer) error {
+	if c.ReconcileInterval <= 0 {
+		return fmt.Errorf("ReconcileInterval must be positive")
+	}
+
+	var reporter *nodeDriftReporter
+	ticker := time.NewTicker(c.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		tasks, err := c.buildTasks(ctx, true)
+		if err != nil {
+			klog.Warningf("error building nodeup tasks: %v", err)
+		} else {
+			if reporter == nil {
+				reporter, err = newNodeDriftReporter(tasks)
+				if err != nil {
+					klog.Warningf("unable to report drift to kubernetes: %v", err)
+				}
+			}
+
+			if tasks.configChanged && reporter != nil {
+				if err := c.applyRequestedUpdate(ctx, tasks, reporter); err != nil {
+					klog.Warningf("error applying nodeup config update: %v", err)
+				}
+			}
+
+			drift, err := c.reconcileOnce(tasks)
+			if err != nil {
+				klog.Warningf("error reconciling node configuration: %v", err)
+			} else {
+				printDrift(out, drift)
+				if reporter != nil {
+					if err := reporter.ReportDrift(ctx, drift); err != nil {
+						klog.Warningf("error reporting drift: %v", err)
+					}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// UpdateRequester tells the agent which nodeup config the node was requested to apply in place,
+// and records the nodeup config applied by the agent
+type UpdateRequester interface {
+	RequestedConfigHash(ctx context.Context) (string, error)
+	ReportConfigApplied(ctx context.Context, hash string) error
+}
+
+// applyRequestedUpdate applies the changed nodeup config in place, if a rolling update requested it
+func (c *NodeUpCommand) applyRequestedUpdate(ctx context.Context, tasks *nodeupTasks, requester UpdateRequester) error {
+	requested, err := requester.RequestedConfigHash(ctx)
+	if err != nil {
+		return err
+	}
+	if requested != tasks.nodeupConfigHash {
+		return nil
+	}
+
+	klog.Infof("applying nodeup config %q in place", requested)
+	if err := c.applyConfigUpdate(tasks); err != nil {
+		return err
+	}
+
+	return requester.ReportConfigApplied(ctx, requested)
+}
+
+// applyConfigUpdate runs all the tasks of the changed nodeup config on the node,
+// then records the hash of the applied config in the boot config so the node can still reboot
+func (c *NodeUpCommand) applyConfigUpdate(tasks *nodeupTasks) error {
+	target := &local.LocalTarget{
+		CacheDir: c.CacheDir,
+	}
+	if err := c.runTasks(tasks, target, tasks.taskMap); err != nil {
+		return fmt.Errorf("error applying nodeup config: %v", err)
+	}
+	if err := target.Finish(tasks.taskMap); err != nil {
+		return fmt.Errorf("error applying nodeup config: %v", err)
+	}
+
+	bootConfig := *tasks.modelContext.BootConfig
+	bootConfig.NodeupConfigHash = tasks.nodeupConfigHash
+	b, err := utils.YamlMarshal(&bootConfig)
+	if err != nil {
+		return fmt.Errorf("error converting boot config to yaml: %v", err)
+	}
+	p, err := vfs.Context.BuildVfsPath(c.ConfigLocation)
+	if err != nil {
+		return fmt.Errorf("error parsing boot config location %q: %v", c.ConfigLocation, err)
+	}
+	if err := p.WriteFile(bytes.NewReader(b), nil); err != nil {
+		return fmt.Errorf("error writing boot config %q: %v", c.ConfigLocation, err)
+	}
+
+	*tasks.modelContext.BootConfig = bootConfig
+	tasks.configChanged = false
+	return nil
+}
+
+// reconcileOnce finds the drift of the node and repairs it if requested.
+// While the nodeup config differs from the one the node booted with, the tasks describe a configuration that was not
+// applied yet, so drift is neither checked nor repaired: the change is applied by the requested update or by replacing the instance.
+func (c *NodeUpCommand) reconcileOnce(tasks *nodeupTasks) (*Drift, error) {
+	drift := &Drift{
+		ConfigChanged: tasks.configChanged,
+	}
+	if tasks.configChanged {
+		return drift, nil
+	}
+
+	var err error
+	drift.Tasks, err = c.findDrift(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Repair && len(drift.Tasks) != 0 {
+		repairTasks := filterRepairableTasks(filterDriftTasks(tasks.taskMap))
+		klog.Infof("repairing %d drifted tasks", len(drift.Tasks))
+
+		target := &local.LocalTarget{
+			CacheDir: c.CacheDir,
+		}
+		if err := c.runTasks(tasks, target, repairTasks); err != nil {
+			return nil, fmt.Errorf("error repairing node configuration: %v", err)
+		}
+
+		// Tasks that can't be repaired in place are still drifted
+		drift.Tasks, err = c.findDrift(tasks)
+		if err != nil {
+			return nil, err
+		}
+		drift.Repaired = true
+	}
+
+	return drift, nil
+}
+
+// findDrift runs the drift-checked tasks against a dry-run target, returning the sorted names of the tasks that would change the node
+func (c *NodeUpCommand) findDrift(tasks *nodeupTasks) ([]string, error) {
+	driftTasks := filterDriftTasks(tasks.taskMap)
+
+	target := fi.NewDryRunTarget(assets.NewAssetBuilder(c.cluster, false), ioutil.Discard)
+	if err := c.runTasks(tasks, target, driftTasks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[fi.Task]string)
+	for key, task := range driftTasks {
+		keys[task] = key
+	}
+	var names []string
+	for _, task := range target.ChangedTasks() {
+		names = append(names, keys[task])
+	}
+	names = append(names, target.Deletions()...)
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *NodeUpCommand) runTasks(tasks *nodeupTasks, target fi.Target, taskMap map[string]fi.Task) error {
+	context, err := fi.NewContext(target, c.cluster, tasks.cloud, tasks.keyStore, tasks.secretStore, tasks.configBase, true, taskMap)
+	if err != nil {
+		return fmt.Errorf("error building context: %v", err)
+	}
+	defer context.Close()
+
+	var options fi.RunTasksOptions
+	options.InitDefaults()
+
+	return context.RunTasks(options)
+}
+
+// filterDriftTasks returns the tasks whose drift can be checked on every run: the files, services and packages,
+// which find the actual state of the node and only change it when it differs. Tasks that run every time,
+// such as issuing certificates or loading images, are left out, together with the files whose contents they produce.
+func filterDriftTasks(taskMap map[string]fi.Task) map[string]fi.Task {
+	driftTasks := make(map[string]fi.Task)
+	for name, task := range taskMap {
+		switch task.(type) {
+		case *nodetasks.File, *nodetasks.Service, *nodetasks.Package:
+			driftTasks[name] = task
+		}
+	}
+
+	// Drop the tasks depending on a task that is not checked, until only checked dependencies remain
+	for {
+		checked := make(map[fi.Task]bool)
+		for _, task := range driftTasks {
+			checked[task] = true
+		}
+
+		removed := false
+		for name, task := range driftTasks {
+			hasDependencies, ok := task.(fi.HasDependencies)
+			if !ok {
+				continue
+			}
+			for _, dep := range hasDependencies.GetDependencies(driftTasks) {
+				if !checked[dep] {
+					delete(driftTasks, name)
+					removed = true
+					break
+				}
+			}
+		}
+		if !removed {
+			return driftTasks
+		}
+	}
+}
+
+// filterRepairableTasks returns the tasks that can be safely applied on a running node.
+// All the files and services are included, so services are restarted when their files are repaired.
+func filterRepairableTasks(taskMap map[string]fi.Task) map[string]fi.Task {
+	repairable := make(map[string]fi.Task)
+	for name, task := range taskMap {
+		switch task.(type) {
+		case *nodetasks.File, *nodetasks.Service:
+			repairable[name] = task
+		}
+	}
+	return repairable
+}
+
+func printDrift(out io.Writer, drift *Drift) {
+	if drift.ConfigChanged {
+		fmt.Fprintf(out, "nodeup config changed since the node booted\n")
+	}
+	if len(drift.Tasks) == 0 {
+		fmt.Fprintf(out, "no configuration drift found\n")
+		return
+	}
+	fmt.Fprintf(out, "configuration drift found in %d tasks:\n", len(drift.Tasks))
+	for _, name := range drift.Tasks {
+		fmt.Fprintf(out, "  %s\n", name)
+	}
+}
+
+// buildDriftCondition returns the node condition reporting the drift
+func buildDriftCondition(drift *Drift, now metav1.Time) v1.NodeCondition {
+	condition := v1.NodeCondition{
+		Type:               NodeConditionConfigurationDrift,
+		Status:             v1.ConditionFalse,
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+		Reason:             DriftReasonNoDrift,
+		Message:            "node configuration matches the nodeup config",
+	}
+
+	if len(drift.Tasks) != 0 {
+		tasks := drift.Tasks
+		suffix := ""
+		if len(tasks) > maxDriftMessageTasks {
+			suffix = fmt.Sprintf(" and %d more", len(tasks)-maxDriftMessageTasks)
+			tasks = tasks[:maxDriftMessageTasks]
+		}
+		condition.Status = v1.ConditionTrue
+		condition.Reason = DriftReasonDrifted
+		condition.Message = fmt.Sprintf("configuration drifted: %s%s", strings.Join(tasks, ", "), suffix)
+	} else if drift.ConfigChanged {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = DriftReasonConfigChanged
+		condition.Message = "nodeup config changed since the node booted, the instance needs to be updated"
+	} else if drift.Repaired {
+		condition.Reason = DriftReasonRepaired
+		condition.Message = "drifted configuration was repaired"
+	}
+
+	return condition
+}
+
+// nodeDriftReporter reports drift as a condition on the kubernetes node, with an event for each change
+type nodeDriftReporter struct {
+	client   kubernetes.Interface
+	nodeName string
+
+	last *v1.NodeCondition
+}
+
+var _ DriftReporter = &nodeDriftReporter{}
+var _ UpdateRequester = &nodeDriftReporter{}
+
+func newNodeDriftReporter(tasks *nodeupTasks) (*nodeDriftReporter, error) {
+	nodeName, err := tasks.modelContext.NodeName()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", tasks.modelContext.KubeletKubeConfig())
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubelet kubeconfig: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubernetes client: %v", err)
+	}
+
+	return &nodeDriftReporter{
+		client:   client,
+		nodeName: nodeName,
+	}, nil
+}
+
+// ReportDrift implements DriftReporter
+func (r *nodeDriftReporter) ReportDrift(ctx context.Context, drift *Drift) error {
+	now := metav1.Now()
+	condition := buildDriftCondition(drift, now)
+
+	changed := r.last == nil || r.last.Status != condition.Status || r.last.Reason != condition.Reason || r.last.Message != condition.Message
+	if r.last != nil && r.last.Status == condition.Status {
+		condition.LastTransitionTime = r.last.LastTransitionTime
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []v1.NodeCondition{condition},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error building node patch: %v", err)
+	}
+	if _, err := r.client.CoreV1().Nodes().PatchStatus(ctx, r.nodeName, patch); err != nil {
+		return fmt.Errorf("error patching status of node %q: %v", r.nodeName, err)
+	}
+	r.last = &condition
+
+	if changed && (drift.HasDrift() || drift.Repaired) {
+		eventType := v1.EventTypeWarning
+		if condition.Status == v1.ConditionFalse {
+			eventType = v1.EventTypeNormal
+		}
+		event := &v1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				// Same naming as the client-go event recorder
+				Name:      fmt.Sprintf("%v.%x", r.nodeName, now.UnixNano()),
+				Namespace: metav1.NamespaceDefault,
+			},
+			InvolvedObject: v1.ObjectReference{
+				Kind: "Node",
+				Name: r.nodeName,
+			},
+			Reason:         condition.Reason,
+			Message:        condition.Message,
+			Type:           eventType,
+			Source:         v1.EventSource{Component: "nodeup", Host: r.nodeName},
+			FirstTimestamp: now,
+			LastTimestamp:  now,
+			Count:          1,
+		}
+		if _, err := r.client.CoreV1().Events(metav1.NamespaceDefault).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating event for node %q: %v", r.nodeName, err)
+		}
+	}
+
+	return nil
+}
+
+// RequestedConfigHash implements UpdateRequester
+func (r *nodeDriftReporter) RequestedConfigHash(ctx context.Context) (string, error) {
+	node, err := r.client.CoreV1().Nodes().Get(ctx, r.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting node %q: %v", r.nodeName, err)
+	}
+	return node.Annotations[nodeup.AnnotationRequestedConfigHash], nil
+}
+
+// ReportConfigApplied implements UpdateRequester
+func (r *nodeDriftReporter) ReportConfigApplied(ctx context.Context, hash string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				nodeup.AnnotationAppliedConfigHash: hash,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error building node patch: %v", err)
+	}
+	if _, err := r.client.CoreV1().Nodes().Patch(ctx, r.nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("error patching node %q: %v", r.nodeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/nodeup/pkg/model"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/upup/pkg/fi/utils"
+)
+
+func TestReconcileOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reconcile")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	unchanged := filepath.Join(dir, "unchanged")
+	edited := filepath.Join(dir, "edited")
+	for _, p := range []string{unchanged, edited} {
+		if err := ioutil.WriteFile(p, []byte("expected"), 0644); err != nil {
+			t.Fatalf("error writing %q: %v", p, err)
+		}
+	}
+	if err := ioutil.WriteFile(edited, []byte("edited"), 0644); err != nil {
+		t.Fatalf("error writing %q: %v", edited, err)
+	}
+
+	tasks := &nodeupTasks{
+		taskMap: map[string]fi.Task{
+			"File/" + unchanged: &nodetasks.File{
+				Path:     unchanged,
+				Contents: fi.NewStringResource("expected"),
+				Type:     nodetasks.FileType_File,
+				Mode:     fi.String("0644"),
+			},
+			"File/" + edited: &nodetasks.File{
+				Path:     edited,
+				Contents: fi.NewStringResource("expected"),
+				Type:     nodetasks.FileType_File,
+				Mode:     fi.String("0644"),
+			},
+		},
+	}
+
+	c := &NodeUpCommand{
+		CacheDir: dir,
+		cluster: &api.Cluster{
+			Spec: api.ClusterSpec{
+				KubernetesVersion: "1.21.0",
+			},
+		},
+	}
+
+	drift, err := c.reconcileOnce(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &Drift{Tasks: []string{"File/" + edited}}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("unexpected drift, expected %+v, got %+v", expected, drift)
+	}
+	if b, _ := ioutil.ReadFile(edited); string(b) != "edited" {
+		t.Errorf("file should not be repaired, got %q", string(b))
+	}
+
+	c.Repair = true
+	drift, err = c.reconcileOnce(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = &Drift{Repaired: true}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("unexpected drift after repair, expected %+v, got %+v", expected, drift)
+	}
+	if b, _ := ioutil.ReadFile(edited); string(b) != "expected" {
+		t.Errorf("file should be repaired, got %q", string(b))
+	}
+
+	// A changed nodeup config is not applied by the repair
+	if err := ioutil.WriteFile(edited, []byte("edited"), 0644); err != nil {
+		t.Fatalf("error writing %q: %v", edited, err)
+	}
+	tasks.configChanged = true
+	drift, err = c.reconcileOnce(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = &Drift{ConfigChanged: true}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("unexpected drift with a changed config, expected %+v, got %+v", expected, drift)
+	}
+	if b, _ := ioutil.ReadFile(edited); string(b) != "edited" {
+		t.Errorf("file should not be repaired with a changed config, got %q", string(b))
+	}
+}
+
+func TestFilterDriftTasks(t *testing.T) {
+	issueCert := &nodetasks.IssueCert{Name: "kubelet"}
+	cert, _, _ := issueCert.GetResources()
+
+	taskMap := map[string]fi.Task{
+		"IssueCert/kubelet": issueCert,
+		"File/kubelet.crt": &nodetasks.File{
+			Path:     "/srv/kubernetes/kubelet.crt",
+			Contents: cert,
+			Type:     nodetasks.FileType_File,
+		},
+		"File/config": &nodetasks.File{
+			Path:     "/etc/config",
+			Contents: fi.NewStringResource("config"),
+			Type:     nodetasks.FileType_File,
+		},
+		"Service/kubelet.service": &nodetasks.Service{
+			Name:       "kubelet.service",
+			Definition: fi.String("[Unit]"),
+		},
+		"LoadImageTask/protokube": &nodetasks.LoadImageTask{Name: "protokube"},
+		"UpdatePackages":          &nodetasks.UpdatePackages{},
+	}
+
+	var actual []string
+	for name := range filterDriftTasks(taskMap) {
+		actual = append(actual, name)
+	}
+	sort.Strings(actual)
+	expected := []string{"File/config", "Service/kubelet.service"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected drift tasks, expected %v, got %v", expected, actual)
+	}
+}
+
+type fakeUpdateRequester struct {
+	requested string
+	applied   string
+}
+
+func (r *fakeUpdateRequester) RequestedConfigHash(ctx context.Context) (string, error) {
+	return r.requested, nil
+}
+
+func (r *fakeUpdateRequester) ReportConfigApplied(ctx context.Context, hash string) error {
+	r.applied = hash
+	return nil
+}
+
+func TestApplyRequestedUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "reconcile")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "file")
+	configLocation := filepath.Join(dir, "kube_env.yaml")
+	if err := ioutil.WriteFile(configLocation, []byte("NodeupConfigHash: oldhash\n"), 0644); err != nil {
+		t.Fatalf("error writing boot config: %v", err)
+	}
+
+	tasks := &nodeupTasks{
+		taskMap: map[string]fi.Task{
+			"File/" + file: &nodetasks.File{
+				Path:     file,
+				Contents: fi.NewStringResource("updated"),
+				Type:     nodetasks.FileType_File,
+				Mode:     fi.String("0644"),
+			},
+		},
+		modelContext: &model.NodeupModelContext{
+			BootConfig: &nodeup.BootConfig{
+				InstanceGroupName: "nodes",
+				NodeupConfigHash:  "oldhash",
+			},
+		},
+		configChanged:    true,
+		nodeupConfigHash: "newhash",
+	}
+
+	c := &NodeUpCommand{
+		CacheDir:       dir,
+		ConfigLocation: configLocation,
+		cluster: &api.Cluster{
+			Spec: api.ClusterSpec{
+				KubernetesVersion: "1.21.0",
+			},
+		},
+	}
+
+	requester := &fakeUpdateRequester{requested: "otherhash"}
+	if err := c.applyRequestedUpdate(ctx, tasks, requester); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("config should not be applied unless requested")
+	}
+	if requester.applied != "" {
+		t.Errorf("unexpected applied hash %q", requester.applied)
+	}
+
+	requester.requested = "newhash"
+	if err := c.applyRequestedUpdate(ctx, tasks, requester); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, _ := ioutil.ReadFile(file); string(b) != "updated" {
+		t.Errorf("file should be updated, got %q", string(b))
+	}
+	if requester.applied != "newhash" {
+		t.Errorf("expected applied hash %q, got %q", "newhash", requester.applied)
+	}
+	if tasks.configChanged {
+		t.Errorf("config should no longer be reported as changed")
+	}
+
+	b, err := ioutil.ReadFile(configLocation)
+	if err != nil {
+		t.Fatalf("error reading boot config: %v", err)
+	}
+	var bootConfig nodeup.BootConfig
+	if err := utils.YamlUnmarshal(b, &bootConfig); err != nil {
+		t.Fatalf("error parsing boot config: %v", err)
+	}
+	if bootConfig.NodeupConfigHash != "newhash" || bootConfig.InstanceGroupName != "nodes" {
+		t.Errorf("unexpected boot config %+v", bootConfig)
+	}
+}
+
+func TestBuildDriftCondition(t *testing.T) {
+	now := metav1.Now()
+
+	for _, test := range []struct {
+		name            string
+		drift           Drift
+		expectedStatus  v1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "no drift",
+			expectedStatus:  v1.ConditionFalse,
+			expectedReason:  DriftReasonNoDrift,
+			expectedMessage: "node configuration matches the nodeup config",
+		},
+		{
+			name:            "drifted",
+			drift:           Drift{Tasks: []string{"File//etc/a", "Service/b"}},
+			expectedStatus:  v1.ConditionTrue,
+			expectedReason:  DriftReasonDrifted,
+			expectedMessage: "configuration drifted: File//etc/a, Service/b",
+		},
+		{
+			name:            "many drifted",
+			drift:           Drift{Tasks: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}},
+			expectedStatus:  v1.ConditionTrue,
+			expectedReason:  DriftReasonDrifted,
+			expectedMessage: "configuration drifted: 1, 2, 3, 4, 5, 6, 7, 8, 9, 10 and 2 more",
+		},
+		{
+			name:            "config changed",
+			drift:           Drift{ConfigChanged: true},
+			expectedStatus:  v1.ConditionTrue,
+			expectedReason:  DriftReasonConfigChanged,
+			expectedMessage: "nodeup config changed since the node booted, the instance needs to be updated",
+		},
+		{
+			name:            "repaired",
+			drift:           Drift{Repaired: true},
+			expectedStatus:  v1.ConditionFalse,
+			expectedReason:  DriftReasonRepaired,
+			expectedMessage: "drifted configuration was repaired",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			condition := buildDriftCondition(&test.drift, now)
+			if condition.Type != NodeConditionConfigurationDrift {
+				t.Errorf("unexpected type %q", condition.Type)
+			}
+			if condition.Status != test.expectedStatus {
+				t.Errorf("expected status %q, got %q", test.expectedStatus, condition.Status)
+			}
+			if condition.Reason != test.expectedReason {
+				t.Errorf("expected reason %q, got %q", test.expectedReason, condition.Reason)
+			}
+			if condition.Message != test.expectedMessage {
+				t.Errorf("expected message %q, got %q", test.expectedMessage, condition.Message)
+			}
+		})
+	}
+}
+
+func TestNodeDriftReporter(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+	})
+	r := &nodeDriftReporter{
+		client:   client,
+		nodeName: "node-1",
+	}
+
+	report := func(drift *Drift) *v1.NodeCondition {
+		if err := r.ReportDrift(ctx, drift); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		node, err := client.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("error getting node: %v", err)
+		}
+		for i := range node.Status.Conditions {
+			if node.Status.Conditions[i].Type == NodeConditionConfigurationDrift {
+				return &node.Status.Conditions[i]
+			}
+		}
+		t.Fatalf("drift condition not found on node")
+		return nil
+	}
+	countEvents := func() int {
+		events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("error listing events: %v", err)
+		}
+		return len(events.Items)
+	}
+
+	condition := report(&Drift{})
+	if condition.Status != v1.ConditionFalse {
+		t.Errorf("expected no drift, got %+v", condition)
+	}
+	if n := countEvents(); n != 0 {
+		t.Errorf("expected no events, got %d", n)
+	}
+
+	condition = report(&Drift{Tasks: []string{"File//etc/a"}})
+	if condition.Status != v1.ConditionTrue || condition.Reason != DriftReasonDrifted {
+		t.Errorf("expected drift, got %+v", condition)
+	}
+	if n := countEvents(); n != 1 {
+		t.Errorf("expected 1 event, got %d", n)
+	}
+
+	// The same drift is only reported once
+	report(&Drift{Tasks: []string{"File//etc/a"}})
+	if n := countEvents(); n != 1 {
+		t.Errorf("expected 1 event, got %d", n)
+	}
+
+	requested, err := r.RequestedConfigHash(ctx)
+	if err != nil || requested != "" {
+		t.Errorf("expected no requested config, got %q: %v", requested, err)
+	}
+	if err := r.ReportConfigApplied(ctx, "newhash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	node, err := client.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting node: %v", err)
+	}
+	if hash := node.Annotations[nodeup.AnnotationAppliedConfigHash]; hash != "newhash" {
+		t.Errorf("expected applied hash annotation %q, got %q", "newhash", hash)
+	}
+
+	condition = report(&Drift{Repaired: true})
+	if condition.Status != v1.ConditionFalse || condition.Reason != DriftReasonRepaired {
+		t.Errorf("expected repaired, got %+v", condition)
+	}
+	if n := countEvents(); n != 2 {
+		t.Errorf("expected 2 events, got %d", n)
+	}
+}
@@ -24,6 +24,11 @@ func TestNewHasher(t *testing.T) {
 		HA          HashAlgorithm
 		expectedNil bool
 	}{
+		{
+			name:        "sha512",
+			HA:          "sha512",
+			expectedNil: false,
+		},
 		{
 			name:        "sha256",
 			HA:          "sha256",
@@ -146,6 +151,11 @@ func TestFromString(t *testing.T) {
 		parm     string
 		expected string
 	}{
+		{
+			name:     "sha512",
+			parm:     "465dd3d44124e7e54c46f02f230cb29e2547449e081fc1d82b2a9c020940ae6d39e068c6345d7d80e436540731cb7d6e62cf1008c1d61272e9d34d51dbade738",
+			expected: "sha512:465dd3d44124e7e54c46f02f230cb29e2547449e081fc1d82b2a9c020940ae6d39e068c6345d7d80e436540731cb7d6e62cf1008c1d61272e9d34d51dbade738",
+		},
 		{
 			name:     "sha256",
 			parm:     "5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5",
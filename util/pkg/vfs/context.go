@@ -336,12 +336,18 @@ func (c *VFSContext) buildKubernetesPath(p string) (*KubernetesPath, error) {
 		return nil, fmt.Errorf("invalid kubernetes vfs path: %q", p)
 	}
 
-	bucket := strings.TrimSuffix(u.Host, "/")
-	if bucket == "" {
-		return nil, fmt.Errorf("invalid kubernetes vfs path: %q", p)
+	// k8s://<kubeconfig context>/<namespace>/<path>, the context is empty in a pod
+	tokens := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	namespace := tokens[0]
+	if namespace == "" {
+		return nil, fmt.Errorf("invalid kubernetes vfs path %q, expected k8s://<context>/<namespace>/<path>", p)
+	}
+	key := ""
+	if len(tokens) == 2 {
+		key = tokens[1]
 	}
 
-	k8sPath := newKubernetesPath(c.k8sContext, bucket, u.Path)
+	k8sPath := newKubernetesPath(c.k8sContext, u.Host, namespace, key)
 	return k8sPath, nil
 }
 
@@ -1,5 +1,5 @@
 /*
-Copyright 2019 The Kubernetes Authors.
+Copyright 2017 The Kubernetes Authors.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -14,30 +14,93 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-/*
-Copyright 2017 The Kubernetes Authors.
-
-Licensed under the Apache License, Version 2.0 (the "License");
-you may not use this file except in compliance with the License.
-You may obtain a copy of the License at
+package vfs
 
-    http://www.apache.org/licenses/LICENSE-2.0
+import (
+	"fmt"
+	"sync"
 
-Unless required by applicable law or agreed to in writing, software
-distributed under the License is distributed on an "AS IS" BASIS,
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
 
-package vfs
+// DefaultKubernetesMaxObjectSize is the size above which a file is split over multiple objects.
+// Objects are limited to 1MiB, and the data is base64 encoded in JSON requests.
+const DefaultKubernetesMaxObjectSize = 512 * 1024
 
 // KubernetesContext is the context for a Kubernetes VFS implementation
 type KubernetesContext struct {
+	mutex sync.Mutex
+	// clients are the clients by kubeconfig context name
+	clients map[string]kubernetes.Interface
+	// client, if set, is used for all the kubeconfig contexts
+	client kubernetes.Interface
+
+	// maxObjectSize is the size above which a file is split over multiple objects
+	maxObjectSize int
 }
 
 // NewKubernetesContext builds a KubernetesContext
-// This will likely take a kubernetes rest client object (or similar) once the implementation is more complete
+// The clients are built on first use, from the kubeconfig context named in the path,
+// or from the in-cluster config if the path has no context.
 func NewKubernetesContext() *KubernetesContext {
-	return &KubernetesContext{}
+	return &KubernetesContext{
+		clients:       make(map[string]kubernetes.Interface),
+		maxObjectSize: DefaultKubernetesMaxObjectSize,
+	}
+}
+
+// NewKubernetesContextForClient builds a KubernetesContext using the specified client
+func NewKubernetesContextForClient(client kubernetes.Interface) *KubernetesContext {
+	return &KubernetesContext{
+		client:        client,
+		maxObjectSize: DefaultKubernetesMaxObjectSize,
+	}
+}
+
+// KubernetesRESTConfig returns the client config for the kubeconfig context of a k8s:// URL.
+// The current context of the kubeconfig is never used, as kops changes it when exporting the kubeconfig of a cluster:
+// an empty context selects the in-cluster config of the pod instead.
+func KubernetesRESTConfig(contextName string) (*rest.Config, error) {
+	if contextName == "" {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("k8s:// URLs without a kubeconfig context can only be used in a pod: %v", err)
+		}
+		return config, nil
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: contextName})
+	config, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig context %q: %v", contextName, err)
+	}
+	return config, nil
+}
+
+func (c *KubernetesContext) getClient(contextName string) (kubernetes.Interface, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.client != nil {
+		return c.client, nil
+	}
+	if client := c.clients[contextName]; client != nil {
+		return client, nil
+	}
+
+	config, err := KubernetesRESTConfig(contextName)
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig for kubernetes vfs: %v", err)
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubernetes client for kubernetes vfs: %v", err)
+	}
+	c.clients[contextName] = client
+
+	return client, nil
 }
@@ -18,42 +18,87 @@ package vfs
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"path"
+	"strconv"
 	"strings"
+	"sync"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
 	"k8s.io/kops/util/pkg/hashing"
 )
 
-// KubernetesPath is a path for a VFS backed by the kubernetes API
-// Currently all operations are no-ops
+const (
+	// k8sLabelFile marks the objects holding a file, as opposed to the shards of a file
+	k8sLabelFile = "vfs.kops.k8s.io/file"
+	// k8sLabelDir is the hash of the directory of the file, for ReadDir
+	k8sLabelDir = "vfs.kops.k8s.io/dir"
+	// k8sLabelLevelPrefix prefixes the labels holding the hash of each ancestor directory of the file, for ReadTree
+	k8sLabelLevelPrefix = "vfs.kops.k8s.io/level-"
+	// k8sLabelShardOf is the name of the object holding the file a shard belongs to
+	k8sLabelShardOf = "vfs.kops.k8s.io/shard-of"
+
+	k8sAnnotationPath   = "vfs.kops.k8s.io/path"
+	k8sAnnotationShards = "vfs.kops.k8s.io/shards"
+	k8sAnnotationMD5    = "vfs.kops.k8s.io/md5"
+	k8sAnnotationSHA256 = "vfs.kops.k8s.io/sha256"
+
+	k8sDataKey = "data"
+)
+
+// KubernetesPath is a path for a VFS backed by the kubernetes API, k8s://<kubeconfig context>/<namespace>/<path>.
+// The files are held in the namespace of the cluster of the kubeconfig context, or of the cluster of the pod
+// if the context is empty. Files in a "pki" or "secrets" directory are stored in Secrets,
+// the other files in ConfigMaps. Objects are named after a hash of the path, which is recorded in an annotation,
+// and labelled with hashes of the directories of the path, so that directories can be listed by label selector.
+// Files larger than the maximum object size are split over shards, named after the hash of the content.
 type KubernetesPath struct {
-	k8sContext *KubernetesContext
-	host       string
-	key        string
+	k8sContext  *KubernetesContext
+	contextName string
+	namespace   string
+	key         string
 }
 
 var _ Path = &KubernetesPath{}
 var _ HasHash = &KubernetesPath{}
 
-func newKubernetesPath(k8sContext *KubernetesContext, host string, key string) *KubernetesPath {
-	host = strings.TrimSuffix(host, "/")
-	key = strings.TrimPrefix(key, "/")
+// createFileLockKubernetes prevents concurrent creates on the same
+// file while maintaining atomicity of writes.
+var createFileLockKubernetes sync.Mutex
+
+func newKubernetesPath(k8sContext *KubernetesContext, contextName string, namespace string, key string) *KubernetesPath {
+	key = strings.Trim(key, "/")
 
 	return &KubernetesPath{
-		k8sContext: k8sContext,
-		host:       host,
-		key:        key,
+		k8sContext:  k8sContext,
+		contextName: contextName,
+		namespace:   namespace,
+		key:         key,
 	}
 }
 
 func (p *KubernetesPath) Path() string {
-	return "k8s://" + p.host + "/" + p.key
+	return "k8s://" + p.contextName + "/" + p.namespace + "/" + p.key
 }
 
-func (p *KubernetesPath) Host() string {
-	return p.host
+// ContextName is the kubeconfig context of the cluster holding the files, empty for the cluster of the pod
+func (p *KubernetesPath) ContextName() string {
+	return p.contextName
+}
+
+// Namespace is the namespace holding the files
+func (p *KubernetesPath) Namespace() string {
+	return p.namespace
 }
 
 func (p *KubernetesPath) Key() string {
@@ -65,7 +110,27 @@ func (p *KubernetesPath) String() string {
 }
 
 func (p *KubernetesPath) Remove() error {
-	return fmt.Errorf("KubernetesPath::Remove not supported")
+	store, err := p.store()
+	if err != nil {
+		return err
+	}
+
+	name := k8sObjectName(p.key)
+	if err := store.delete(name); err != nil {
+		return fmt.Errorf("error deleting %s: %v", p, err)
+	}
+
+	shards, err := store.list(k8sLabelShardOf + "=" + name)
+	if err != nil {
+		return fmt.Errorf("error listing shards of %s: %v", p, err)
+	}
+	for _, shard := range shards {
+		if err := store.delete(shard.Name); err != nil {
+			return fmt.Errorf("error deleting shard of %s: %v", p, err)
+		}
+	}
+
+	return nil
 }
 
 func (p *KubernetesPath) RemoveAllVersions() error {
@@ -77,18 +142,114 @@ func (p *KubernetesPath) Join(relativePath ...string) Path {
 	args = append(args, relativePath...)
 	joined := path.Join(args...)
 	return &KubernetesPath{
-		k8sContext: p.k8sContext,
-		host:       p.host,
-		key:        joined,
+		k8sContext:  p.k8sContext,
+		contextName: p.contextName,
+		namespace:   p.namespace,
+		key:         joined,
 	}
 }
 
 func (p *KubernetesPath) WriteFile(data io.ReadSeeker, acl ACL) error {
-	return fmt.Errorf("KubernetesPath::WriteFile not supported")
+	return p.writeFile(data, false)
 }
 
 func (p *KubernetesPath) CreateFile(data io.ReadSeeker, acl ACL) error {
-	return fmt.Errorf("KubernetesPath::CreateFile not supported")
+	createFileLockKubernetes.Lock()
+	defer createFileLockKubernetes.Unlock()
+
+	return p.writeFile(data, true)
+}
+
+func (p *KubernetesPath) writeFile(data io.ReadSeeker, createOnly bool) error {
+	b, err := ioutil.ReadAll(data)
+	if err != nil {
+		return fmt.Errorf("error reading data for %s: %v", p, err)
+	}
+
+	store, err := p.store()
+	if err != nil {
+		return err
+	}
+
+	name := k8sObjectName(p.key)
+	existing, err := store.get(name)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", p, err)
+	}
+	if existing != nil && createOnly {
+		return os.ErrExist
+	}
+
+	md5Hash, err := hashing.HashAlgorithmMD5.Hash(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	sha256Hash, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	chunks := splitChunks(b, p.k8sContext.maxObjectSize)
+
+	// The shards are named after the content, and written before the object holding the file,
+	// so readers never see a file referencing shards of another version.
+	for i := 1; i < len(chunks); i++ {
+		shard := &k8sObject{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      k8sShardName(name, sha256Hash.Hex(), i),
+				Namespace: p.namespace,
+				Labels:    map[string]string{k8sLabelShardOf: name},
+			},
+			data: chunks[i],
+		}
+		if err := store.apply(shard); err != nil {
+			return fmt.Errorf("error writing shard of %s: %v", p, err)
+		}
+	}
+
+	obj := &k8sObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: p.namespace,
+			Labels:    k8sDirLabels(p.key),
+			Annotations: map[string]string{
+				k8sAnnotationPath:   p.key,
+				k8sAnnotationShards: strconv.Itoa(len(chunks)),
+				k8sAnnotationMD5:    md5Hash.Hex(),
+				k8sAnnotationSHA256: sha256Hash.Hex(),
+			},
+		},
+		data: chunks[0],
+	}
+	if existing == nil {
+		if err := store.create(obj); err != nil {
+			if apierrors.IsAlreadyExists(err) && createOnly {
+				return os.ErrExist
+			}
+			return fmt.Errorf("error creating %s: %v", p, err)
+		}
+	} else {
+		obj.ResourceVersion = existing.ResourceVersion
+		if err := store.update(obj); err != nil {
+			return fmt.Errorf("error updating %s: %v", p, err)
+		}
+	}
+
+	// Remove the shards of the previous versions
+	shards, err := store.list(k8sLabelShardOf + "=" + name)
+	if err != nil {
+		return fmt.Errorf("error listing shards of %s: %v", p, err)
+	}
+	for _, shard := range shards {
+		if strings.HasPrefix(shard.Name, k8sShardPrefix(name, sha256Hash.Hex())) {
+			continue
+		}
+		if err := store.delete(shard.Name); err != nil {
+			klog.Warningf("error deleting outdated shard %q of %s: %v", shard.Name, p, err)
+		}
+	}
+
+	return nil
 }
 
 // ReadFile implements Path::ReadFile
@@ -103,15 +264,97 @@ func (p *KubernetesPath) ReadFile() ([]byte, error) {
 
 // WriteTo implements io.WriterTo
 func (p *KubernetesPath) WriteTo(out io.Writer) (int64, error) {
-	return 0, fmt.Errorf("KubernetesPath::WriteTo not supported")
+	store, err := p.store()
+	if err != nil {
+		return 0, err
+	}
+
+	name := k8sObjectName(p.key)
+	obj, err := store.get(name)
+	if err != nil {
+		return 0, fmt.Errorf("error reading %s: %v", p, err)
+	}
+	if obj == nil {
+		return 0, os.ErrNotExist
+	}
+
+	shards := 1
+	if s := obj.Annotations[k8sAnnotationShards]; s != "" {
+		shards, err = strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid shard count %q for %s", s, p)
+		}
+	}
+	sha256Hex := obj.Annotations[k8sAnnotationSHA256]
+
+	data := obj.data
+	for i := 1; i < shards; i++ {
+		shard, err := store.get(k8sShardName(name, sha256Hex, i))
+		if err != nil {
+			return 0, fmt.Errorf("error reading shard of %s: %v", p, err)
+		}
+		if shard == nil {
+			return 0, fmt.Errorf("shard %d of %s not found", i, p)
+		}
+		data = append(data, shard.data...)
+	}
+
+	if sha256Hex != "" {
+		actual, err := hashing.HashAlgorithmSHA256.Hash(bytes.NewReader(data))
+		if err != nil {
+			return 0, err
+		}
+		if actual.Hex() != sha256Hex {
+			return 0, fmt.Errorf("content of %s does not match its hash, it may have been modified concurrently", p)
+		}
+	}
+
+	n, err := out.Write(data)
+	return int64(n), err
 }
 
 func (p *KubernetesPath) ReadDir() ([]Path, error) {
-	return nil, fmt.Errorf("KubernetesPath::ReadDir not supported")
+	return p.listFiles(k8sLabelDir + "=" + k8sLabelHash(p.key))
 }
 
 func (p *KubernetesPath) ReadTree() ([]Path, error) {
-	return nil, fmt.Errorf("KubernetesPath::ReadTree not supported")
+	if p.key == "" {
+		return p.listFiles(k8sLabelFile + "=true")
+	}
+	depth := len(strings.Split(p.key, "/"))
+	return p.listFiles(fmt.Sprintf("%s%d=%s", k8sLabelLevelPrefix, depth, k8sLabelHash(p.key)))
+}
+
+// listFiles returns the files stored in ConfigMaps or Secrets matching the label selector
+func (p *KubernetesPath) listFiles(selector string) ([]Path, error) {
+	client, err := p.k8sContext.getClient(p.contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []Path
+	for _, secret := range []bool{false, true} {
+		store := &k8sStore{client: client, namespace: p.namespace, secret: secret}
+		objects, err := store.list(selector)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s: %v", p, err)
+		}
+		for _, obj := range objects {
+			key, found := obj.Annotations[k8sAnnotationPath]
+			if !found {
+				klog.Warningf("ignoring %s/%s without %s annotation", p.namespace, obj.Name, k8sAnnotationPath)
+				continue
+			}
+			paths = append(paths, &KubernetesPath{
+				k8sContext:  p.k8sContext,
+				contextName: p.contextName,
+				namespace:   p.namespace,
+				key:         key,
+			})
+		}
+	}
+	klog.V(8).Infof("Listed files in %v: %v", p, paths)
+	return paths, nil
 }
 
 func (p *KubernetesPath) Base() string {
@@ -123,5 +366,265 @@ func (p *KubernetesPath) PreferredHash() (*hashing.Hash, error) {
 }
 
 func (p *KubernetesPath) Hash(a hashing.HashAlgorithm) (*hashing.Hash, error) {
-	return nil, fmt.Errorf("KubernetesPath::Hash not supported")
+	store, err := p.store()
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := store.get(k8sObjectName(p.key))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", p, err)
+	}
+	if obj == nil {
+		return nil, os.ErrNotExist
+	}
+
+	var annotation string
+	switch a {
+	case hashing.HashAlgorithmMD5:
+		annotation = obj.Annotations[k8sAnnotationMD5]
+	case hashing.HashAlgorithmSHA256:
+		annotation = obj.Annotations[k8sAnnotationSHA256]
+	}
+	if annotation != "" {
+		hashValue, err := hex.DecodeString(annotation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s hash %q for %s", a, annotation, p)
+		}
+		return &hashing.Hash{Algorithm: a, HashValue: hashValue}, nil
+	}
+
+	data, err := p.ReadFile()
+	if err != nil {
+		return nil, err
+	}
+	return a.Hash(bytes.NewReader(data))
+}
+
+// isSecret returns true if the file is stored in a Secret
+func (p *KubernetesPath) isSecret() bool {
+	for _, s := range strings.Split(p.key, "/") {
+		if s == "pki" || s == "secrets" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *KubernetesPath) store() (*k8sStore, error) {
+	if p.key == "" {
+		return nil, fmt.Errorf("%s is a directory", p)
+	}
+	client, err := p.k8sContext.getClient(p.contextName)
+	if err != nil {
+		return nil, err
+	}
+	return &k8sStore{
+		client:    client,
+		namespace: p.namespace,
+		secret:    p.isSecret(),
+	}, nil
+}
+
+// k8sObjectName returns the name of the object holding the file, which is a valid DNS label
+func k8sObjectName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(path.Base(key)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	base := b.String()
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	base = strings.Trim(base, "-")
+	if base == "" {
+		base = "kops-vfs"
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	return base + "-" + hex.EncodeToString(hash[:])[:20]
+}
+
+// k8sShardPrefix is the prefix of the names of the shards of a version of the file
+func k8sShardPrefix(name string, sha256Hex string) string {
+	if len(sha256Hex) > 8 {
+		sha256Hex = sha256Hex[:8]
+	}
+	return name + "-" + sha256Hex + "-"
+}
+
+func k8sShardName(name string, sha256Hex string, i int) string {
+	return k8sShardPrefix(name, sha256Hex) + strconv.Itoa(i)
+}
+
+// k8sLabelHash hashes a directory to a valid label value
+func k8sLabelHash(dir string) string {
+	hash := sha256.Sum256([]byte(dir))
+	return hex.EncodeToString(hash[:])[:32]
+}
+
+// k8sDirLabels returns the labels of the object holding the file, identifying its directory and all its ancestors
+func k8sDirLabels(key string) map[string]string {
+	dir := path.Dir(key)
+	if dir == "." {
+		dir = ""
+	}
+
+	labels := map[string]string{
+		k8sLabelFile: "true",
+		k8sLabelDir:  k8sLabelHash(dir),
+	}
+	if dir != "" {
+		parts := strings.Split(dir, "/")
+		for i := range parts {
+			labels[fmt.Sprintf("%s%d", k8sLabelLevelPrefix, i+1)] = k8sLabelHash(strings.Join(parts[:i+1], "/"))
+		}
+	}
+	return labels
+}
+
+// splitChunks splits the data in chunks of at most maxSize bytes, always returning at least one chunk
+func splitChunks(data []byte, maxSize int) [][]byte {
+	if maxSize <= 0 || len(data) <= maxSize {
+		return [][]byte{data}
+	}
+	var chunks [][]byte
+	for len(data) > maxSize {
+		chunks = append(chunks, data[:maxSize])
+		data = data[maxSize:]
+	}
+	if len(data) > 0 {
+		chunks = append(chunks, data)
+	}
+	return chunks
+}
+
+// k8sObject is a file, or a shard of a file, held by a Secret or a ConfigMap
+type k8sObject struct {
+	metav1.ObjectMeta
+	data []byte
+}
+
+// k8sStore reads and writes the objects holding files, either as Secrets or as ConfigMaps
+type k8sStore struct {
+	client    kubernetes.Interface
+	namespace string
+	secret    bool
+}
+
+// get returns the object, or nil if it does not exist
+func (s *k8sStore) get(name string) (*k8sObject, error) {
+	ctx := context.TODO()
+	if s.secret {
+		secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &k8sObject{ObjectMeta: secret.ObjectMeta, data: secret.Data[k8sDataKey]}, nil
+	}
+
+	configMap, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &k8sObject{ObjectMeta: configMap.ObjectMeta, data: configMap.BinaryData[k8sDataKey]}, nil
+}
+
+func (s *k8sStore) list(selector string) ([]*k8sObject, error) {
+	ctx := context.TODO()
+	options := metav1.ListOptions{LabelSelector: selector}
+
+	var objects []*k8sObject
+	if s.secret {
+		secrets, err := s.client.CoreV1().Secrets(s.namespace).List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range secrets.Items {
+			objects = append(objects, &k8sObject{ObjectMeta: secrets.Items[i].ObjectMeta})
+		}
+		return objects, nil
+	}
+
+	configMaps, err := s.client.CoreV1().ConfigMaps(s.namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range configMaps.Items {
+		objects = append(objects, &k8sObject{ObjectMeta: configMaps.Items[i].ObjectMeta})
+	}
+	return objects, nil
+}
+
+func (s *k8sStore) create(obj *k8sObject) error {
+	ctx := context.TODO()
+	if s.secret {
+		_, err := s.client.CoreV1().Secrets(s.namespace).Create(ctx, obj.toSecret(), metav1.CreateOptions{})
+		return err
+	}
+	_, err := s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, obj.toConfigMap(), metav1.CreateOptions{})
+	return err
+}
+
+func (s *k8sStore) update(obj *k8sObject) error {
+	ctx := context.TODO()
+	if s.secret {
+		_, err := s.client.CoreV1().Secrets(s.namespace).Update(ctx, obj.toSecret(), metav1.UpdateOptions{})
+		return err
+	}
+	_, err := s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, obj.toConfigMap(), metav1.UpdateOptions{})
+	return err
+}
+
+// apply creates the object, or updates it if it exists
+func (s *k8sStore) apply(obj *k8sObject) error {
+	existing, err := s.get(obj.Name)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return s.create(obj)
+	}
+	obj.ResourceVersion = existing.ResourceVersion
+	return s.update(obj)
+}
+
+// delete deletes the object, it is not an error if the object does not exist
+func (s *k8sStore) delete(name string) error {
+	ctx := context.TODO()
+	var err error
+	if s.secret {
+		err = s.client.CoreV1().Secrets(s.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	} else {
+		err = s.client.CoreV1().ConfigMaps(s.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (o *k8sObject) toSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: o.ObjectMeta,
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{k8sDataKey: o.data},
+	}
+}
+
+func (o *k8sObject) toConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: o.ObjectMeta,
+		BinaryData: map[string][]byte{k8sDataKey: o.data},
+	}
 }
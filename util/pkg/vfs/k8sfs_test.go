@@ -0,0 +1,301 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vfs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func newTestKubernetesPath(t *testing.T) (*KubernetesPath, *fake.Clientset) {
+	client := fake.NewSimpleClientset()
+	k8sContext := NewKubernetesContextForClient(client)
+	p, err := (&VFSContext{k8sContext: k8sContext}).BuildVfsPath("k8s://management/kops-state/")
+	if err != nil {
+		t.Fatalf("error building path: %v", err)
+	}
+	return p.(*KubernetesPath), client
+}
+
+func keys(paths []Path) []string {
+	var l []string
+	for _, p := range paths {
+		l = append(l, p.(*KubernetesPath).Key())
+	}
+	sort.Strings(l)
+	return l
+}
+
+func TestKubernetesPathReadWrite(t *testing.T) {
+	base, client := newTestKubernetesPath(t)
+	ctx := context.TODO()
+
+	config := base.Join("cluster.example.com", "config")
+	if _, err := config.ReadFile(); !os.IsNotExist(err) {
+		t.Fatalf("expected not exist error reading missing file, got %v", err)
+	}
+
+	if err := config.WriteFile(bytes.NewReader([]byte("spec: {}")), nil); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	keyset := base.Join("cluster.example.com", "pki", "private", "ca", "keyset.yaml")
+	if err := keyset.CreateFile(bytes.NewReader([]byte("secret")), nil); err != nil {
+		t.Fatalf("error creating file: %v", err)
+	}
+	if err := keyset.CreateFile(bytes.NewReader([]byte("other")), nil); err != os.ErrExist {
+		t.Fatalf("expected exist error creating existing file, got %v", err)
+	}
+
+	configMaps, err := client.CoreV1().ConfigMaps("kops-state").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing configmaps: %v", err)
+	}
+	if len(configMaps.Items) != 1 || configMaps.Items[0].Annotations[k8sAnnotationPath] != "cluster.example.com/config" {
+		t.Errorf("expected the config to be stored in a configmap, got %v", configMaps.Items)
+	}
+	secrets, err := client.CoreV1().Secrets("kops-state").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing secrets: %v", err)
+	}
+	if len(secrets.Items) != 1 || string(secrets.Items[0].Data[k8sDataKey]) != "secret" {
+		t.Errorf("expected the keyset to be stored in a secret, got %v", secrets.Items)
+	}
+
+	if err := config.WriteFile(bytes.NewReader([]byte("spec: {kubernetesVersion: 1.21.0}")), nil); err != nil {
+		t.Fatalf("error overwriting file: %v", err)
+	}
+	data, err := config.ReadFile()
+	if err != nil {
+		t.Fatalf("error reading file: %v", err)
+	}
+	if string(data) != "spec: {kubernetesVersion: 1.21.0}" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	if err := keyset.Remove(); err != nil {
+		t.Fatalf("error removing file: %v", err)
+	}
+	if _, err := keyset.ReadFile(); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error reading removed file, got %v", err)
+	}
+}
+
+func TestKubernetesPathReadDirAndTree(t *testing.T) {
+	base, _ := newTestKubernetesPath(t)
+
+	files := []string{
+		"a.example.com/config",
+		"a.example.com/instancegroup/nodes",
+		"a.example.com/instancegroup/master",
+		"a.example.com/pki/issued/ca/keyset.yaml",
+		"b.example.com/config",
+	}
+	for _, f := range files {
+		if err := base.Join(f).WriteFile(bytes.NewReader([]byte(f)), nil); err != nil {
+			t.Fatalf("error writing %s: %v", f, err)
+		}
+	}
+
+	grid := []struct {
+		dir  string
+		dirs []string
+		tree []string
+	}{
+		{
+			dir:  "",
+			tree: []string{"a.example.com/config", "a.example.com/instancegroup/master", "a.example.com/instancegroup/nodes", "a.example.com/pki/issued/ca/keyset.yaml", "b.example.com/config"},
+		},
+		{
+			dir:  "a.example.com",
+			dirs: []string{"a.example.com/config"},
+			tree: []string{"a.example.com/config", "a.example.com/instancegroup/master", "a.example.com/instancegroup/nodes", "a.example.com/pki/issued/ca/keyset.yaml"},
+		},
+		{
+			dir:  "a.example.com/instancegroup",
+			dirs: []string{"a.example.com/instancegroup/master", "a.example.com/instancegroup/nodes"},
+			tree: []string{"a.example.com/instancegroup/master", "a.example.com/instancegroup/nodes"},
+		},
+		{
+			dir:  "a.example.com/pki",
+			tree: []string{"a.example.com/pki/issued/ca/keyset.yaml"},
+		},
+	}
+	for _, g := range grid {
+		dir := base.Join(g.dir)
+		paths, err := dir.ReadDir()
+		if err != nil {
+			t.Fatalf("error reading dir %s: %v", dir, err)
+		}
+		if actual := keys(paths); !reflect.DeepEqual(actual, g.dirs) {
+			t.Errorf("unexpected files in dir %q, expected %v, got %v", g.dir, g.dirs, actual)
+		}
+
+		paths, err = dir.ReadTree()
+		if err != nil {
+			t.Fatalf("error reading tree %s: %v", dir, err)
+		}
+		if actual := keys(paths); !reflect.DeepEqual(actual, g.tree) {
+			t.Errorf("unexpected files in tree %q, expected %v, got %v", g.dir, g.tree, actual)
+		}
+	}
+
+	data, err := base.Join("a.example.com", "instancegroup").(*KubernetesPath).Join("nodes").ReadFile()
+	if err != nil || string(data) != "a.example.com/instancegroup/nodes" {
+		t.Errorf("unexpected content %q, err %v", data, err)
+	}
+}
+
+func TestKubernetesPathSharding(t *testing.T) {
+	base, client := newTestKubernetesPath(t)
+	base.k8sContext.maxObjectSize = 4
+	ctx := context.TODO()
+
+	p := base.Join("cluster.example.com", "cluster-completed.spec")
+	countConfigMaps := func() int {
+		configMaps, err := client.CoreV1().ConfigMaps("kops-state").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("error listing configmaps: %v", err)
+		}
+		return len(configMaps.Items)
+	}
+
+	if err := p.WriteFile(bytes.NewReader([]byte("0123456789")), nil); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	if n := countConfigMaps(); n != 3 {
+		t.Errorf("expected the file to be split in 3 objects, got %d", n)
+	}
+	data, err := p.ReadFile()
+	if err != nil || string(data) != "0123456789" {
+		t.Errorf("unexpected content %q, err %v", data, err)
+	}
+
+	// Shards are not listed as files
+	paths, err := base.ReadTree()
+	if err != nil {
+		t.Fatalf("error reading tree: %v", err)
+	}
+	if actual := keys(paths); !reflect.DeepEqual(actual, []string{"cluster.example.com/cluster-completed.spec"}) {
+		t.Errorf("unexpected files %v", actual)
+	}
+
+	// Shards of the previous version are removed
+	if err := p.WriteFile(bytes.NewReader([]byte("abcdef")), nil); err != nil {
+		t.Fatalf("error overwriting file: %v", err)
+	}
+	if n := countConfigMaps(); n != 2 {
+		t.Errorf("expected the file to be split in 2 objects, got %d", n)
+	}
+	data, err = p.ReadFile()
+	if err != nil || string(data) != "abcdef" {
+		t.Errorf("unexpected content %q, err %v", data, err)
+	}
+
+	if err := p.Remove(); err != nil {
+		t.Fatalf("error removing file: %v", err)
+	}
+	if n := countConfigMaps(); n != 0 {
+		t.Errorf("expected all objects to be removed, got %d", n)
+	}
+}
+
+func TestKubernetesPathHash(t *testing.T) {
+	base, _ := newTestKubernetesPath(t)
+
+	p := base.Join("cluster.example.com", "secrets", "admin")
+	if err := p.WriteFile(bytes.NewReader([]byte("hello")), nil); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	for _, a := range []hashing.HashAlgorithm{hashing.HashAlgorithmMD5, hashing.HashAlgorithmSHA1, hashing.HashAlgorithmSHA256} {
+		expected, err := a.Hash(bytes.NewReader([]byte("hello")))
+		if err != nil {
+			t.Fatalf("error hashing: %v", err)
+		}
+		actual, err := p.(*KubernetesPath).Hash(a)
+		if err != nil {
+			t.Fatalf("error getting %s hash: %v", a, err)
+		}
+		if !actual.Equal(expected) {
+			t.Errorf("unexpected %s hash, expected %v, got %v", a, expected, actual)
+		}
+	}
+}
+
+func TestKubernetesObjectName(t *testing.T) {
+	for _, key := range []string{
+		"cluster.example.com/pki/private/ca/keyset.yaml",
+		"cluster.example.com/instancegroup/Nodes_US",
+		"cluster.example.com/" + string(bytes.Repeat([]byte("x"), 100)),
+	} {
+		name := k8sObjectName(key)
+		if len(name) > 63 {
+			t.Errorf("name %q for %q is too long", name, key)
+		}
+		for _, r := range name {
+			if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-') {
+				t.Errorf("name %q for %q is not a valid object name", name, key)
+				break
+			}
+		}
+	}
+	if k8sObjectName("a/b") == k8sObjectName("c/b") {
+		t.Errorf("expected distinct names for distinct paths")
+	}
+}
+
+func TestBuildKubernetesPath(t *testing.T) {
+	vfsContext := &VFSContext{k8sContext: NewKubernetesContext()}
+
+	for _, test := range []struct {
+		path        string
+		contextName string
+		namespace   string
+		key         string
+	}{
+		{path: "k8s://management/kops-state/cluster.example.com/pki", contextName: "management", namespace: "kops-state", key: "cluster.example.com/pki"},
+		{path: "k8s://management/kops-state", contextName: "management", namespace: "kops-state"},
+		{path: "k8s:///kops-state/cluster.example.com", namespace: "kops-state", key: "cluster.example.com"},
+	} {
+		p, err := vfsContext.BuildVfsPath(test.path)
+		if err != nil {
+			t.Errorf("error building path %q: %v", test.path, err)
+			continue
+		}
+		k8sPath := p.(*KubernetesPath)
+		if k8sPath.ContextName() != test.contextName || k8sPath.Namespace() != test.namespace || k8sPath.Key() != test.key {
+			t.Errorf("unexpected path for %q: context %q, namespace %q, key %q", test.path, k8sPath.ContextName(), k8sPath.Namespace(), k8sPath.Key())
+		}
+		if IsClusterReadable(p) {
+			t.Errorf("kubernetes path %q should not be cluster readable", test.path)
+		}
+	}
+
+	for _, path := range []string{"k8s://management/", "k8s://management"} {
+		if _, err := vfsContext.BuildVfsPath(path); err == nil {
+			t.Errorf("expected error building path %q without namespace", path)
+		}
+	}
+}
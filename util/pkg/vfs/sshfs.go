@@ -267,6 +267,9 @@ func (p *SSHPath) WriteTo(out io.Writer) (int64, error) {
 
 	f, err := sftpClient.Open(p.path)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, err
+		}
 		return 0, fmt.Errorf("error opening file %s over sftp: %v", p, err)
 	}
 	defer f.Close()
@@ -117,7 +117,8 @@ func IsClusterReadable(p Path) bool {
 		return true
 
 	case *KubernetesPath:
-		return true
+		// Nodes have no credentials for the management cluster
+		return false
 
 	case *SSHPath:
 		return false